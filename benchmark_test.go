@@ -77,6 +77,42 @@ func BenchmarkToolExecution_WithContext(b *testing.B) {
 	}
 }
 
+// BenchmarkToolExecution_WithValidation measures tool execution with input
+// validation, which uses the compiled input schema.
+func BenchmarkToolExecution_WithValidation(b *testing.B) {
+	type AddInput struct {
+		A    int    `json:"a" jsonschema:"required,minimum=0"`
+		B    int    `json:"b" jsonschema:"required,maximum=100"`
+		Mode string `json:"mode" jsonschema:"enum=sum|diff,pattern=^[a-z]+$"`
+	}
+
+	srv := mcp.NewServer(mcp.ServerInfo{
+		Name:    "benchmark-test",
+		Version: "1.0.0",
+		Capabilities: mcp.Capabilities{
+			Tools: true,
+		},
+	}, mcp.WithInputValidation())
+
+	srv.Tool("add").
+		Description("Add two numbers").
+		Handler(func(input AddInput) (int, error) {
+			return input.A + input.B, nil
+		})
+
+	tool, _ := srv.GetTool("add")
+	input := json.RawMessage(`{"a":2,"b":3,"mode":"sum"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := tool.Execute(context.Background(), input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // BenchmarkMiddlewareChain measures middleware chain overhead.
 func BenchmarkMiddlewareChain(b *testing.B) {
 	baseHandler := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
@@ -3,6 +3,7 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -20,6 +21,20 @@ type Transport interface {
 	Close() error
 }
 
+// Notifier is optionally implemented by a Transport that can send
+// notifications, which expect no response.
+type Notifier interface {
+	// Notify sends a notification without waiting for a response.
+	Notify(ctx context.Context, req *protocol.Request) error
+}
+
+// NotificationReceiver is optionally implemented by a Transport that
+// delivers the notifications sent by the server.
+type NotificationReceiver interface {
+	// OnNotification sets the function called with each notification.
+	OnNotification(fn func(n *protocol.Request))
+}
+
 // Client is an MCP client that communicates with an MCP server.
 type Client struct {
 	transport Transport
@@ -53,16 +68,69 @@ type Tool struct {
 }
 
 // ToolResult is the result of calling a tool.
+// Use Err to obtain a structured *ToolError when IsError is set.
 type ToolResult struct {
 	Content []ContentItem `json:"content"`
 	IsError bool          `json:"isError,omitempty"`
+
+	// StructuredContent is the structured result, if the server sent one
+	StructuredContent any `json:"structuredContent,omitempty"`
+
+	// Meta is the _meta of the result, if the server sent one
+	Meta map[string]any `json:"_meta,omitempty"`
+
+	name string
+}
+
+// CallToolResult converts the result back to its protocol form, for
+// example to relay it from a proxy.
+func (r *ToolResult) CallToolResult() protocol.CallToolResult {
+	result := protocol.CallToolResult{
+		Content:           make([]protocol.Content, len(r.Content)),
+		IsError:           r.IsError,
+		StructuredContent: r.StructuredContent,
+		Meta:              r.Meta,
+	}
+	for i, item := range r.Content {
+		result.Content[i] = protocol.Content{
+			Type:        item.Type,
+			Text:        item.Text,
+			MimeType:    item.MimeType,
+			Data:        item.Data,
+			URI:         item.URI,
+			Name:        item.Name,
+			Description: item.Description,
+		}
+		if item.Resource != nil {
+			result.Content[i].Resource = &protocol.ResourceContents{
+				URI:      item.Resource.URI,
+				MimeType: item.Resource.MimeType,
+				Text:     item.Resource.Text,
+				Blob:     item.Resource.Blob,
+			}
+		}
+	}
+	return result
 }
 
 // ContentItem represents a content item in a tool result.
+// Image and audio items carry base64 Data and a MimeType. Resource links
+// ("resource_link") carry a URI and Name, and embedded resources
+// ("resource") carry the resource contents in Resource.
 type ContentItem struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
-	Data string `json:"data,omitempty"`
+	Type        string           `json:"type"`
+	Text        string           `json:"text,omitempty"`
+	Data        string           `json:"data,omitempty"`
+	MimeType    string           `json:"mimeType,omitempty"`
+	URI         string           `json:"uri,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Resource    *ResourceContent `json:"resource,omitempty"`
+}
+
+// Bytes decodes the base64 data of an image or audio item.
+func (c ContentItem) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(c.Data)
 }
 
 // Resource represents a resource exposed by the server.
@@ -73,12 +141,24 @@ type Resource struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplate describes a parameterized resource exposed by the server.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Annotations any    `json:"annotations,omitempty"`
+}
+
 // ResourceContent is the content of a resource.
 type ResourceContent struct {
 	URI      string `json:"uri"`
 	MimeType string `json:"mimeType"`
 	Text     string `json:"text,omitempty"`
 	Blob     string `json:"blob,omitempty"`
+
+	// Meta is the _meta of the resources/read result, if the server sent one
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // Prompt represents a prompt exposed by the server.
@@ -99,6 +179,9 @@ type PromptArgument struct {
 type PromptResult struct {
 	Description string          `json:"description,omitempty"`
 	Messages    []PromptMessage `json:"messages"`
+
+	// Meta is the _meta of the result, if the server sent one
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // PromptMessage is a message in a prompt result.
@@ -107,6 +190,41 @@ type PromptMessage struct {
 	Content any    `json:"content"`
 }
 
+// ContentItem returns the message content as a ContentItem, for text,
+// image, audio, resource link and embedded resource content. It reports
+// false if the content has another form.
+func (m PromptMessage) ContentItem() (ContentItem, bool) {
+	cm, ok := m.Content.(map[string]any)
+	if !ok {
+		return ContentItem{}, false
+	}
+
+	item := parseContentItem(cm)
+	return item, item.Type != ""
+}
+
+// parseContentItem converts a decoded content block to a ContentItem.
+func parseContentItem(cm map[string]any) ContentItem {
+	var item ContentItem
+	item.Type, _ = cm["type"].(string)
+	item.Text, _ = cm["text"].(string)
+	item.Data, _ = cm["data"].(string)
+	item.MimeType, _ = cm["mimeType"].(string)
+	item.URI, _ = cm["uri"].(string)
+	item.Name, _ = cm["name"].(string)
+	item.Description, _ = cm["description"].(string)
+
+	if rm, ok := cm["resource"].(map[string]any); ok {
+		res := &ResourceContent{}
+		res.URI, _ = rm["uri"].(string)
+		res.MimeType, _ = rm["mimeType"].(string)
+		res.Text, _ = rm["text"].(string)
+		res.Blob, _ = rm["blob"].(string)
+		item.Resource = res
+	}
+	return item
+}
+
 // Option configures a Client.
 type Option func(*clientOptions)
 
@@ -115,6 +233,10 @@ type clientOptions struct {
 	clientName  string
 	clientVer   string
 	protocolVer string
+
+	propagateDeadline bool
+
+	onNotification func(method string, params json.RawMessage)
 }
 
 // WithTimeout sets the default timeout for requests.
@@ -124,6 +246,17 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithDeadlinePropagation sends the time left until the deadline of each
+// request's context to the server as a timeout hint, in the _meta of the
+// params under protocol.MetaTimeout, so servers that honor it can stop
+// work the client no longer waits for. The deadline includes the timeout
+// of WithTimeout.
+func WithDeadlinePropagation() Option {
+	return func(o *clientOptions) {
+		o.propagateDeadline = true
+	}
+}
+
 // WithClientInfo sets the client name and version for initialization.
 func WithClientInfo(name, version string) Option {
 	return func(o *clientOptions) {
@@ -132,6 +265,16 @@ func WithClientInfo(name, version string) Option {
 	}
 }
 
+// WithNotificationHandler calls fn with each notification the server
+// sends, such as notifications/tools/list_changed, if the transport
+// implements NotificationReceiver. fn runs on the goroutine reading from
+// the transport, so it must hand off any request it makes to the server.
+func WithNotificationHandler(fn func(method string, params json.RawMessage)) Option {
+	return func(o *clientOptions) {
+		o.onNotification = fn
+	}
+}
+
 // WithProtocolVersion sets the protocol version to use.
 func WithProtocolVersion(version string) Option {
 	return func(o *clientOptions) {
@@ -152,6 +295,14 @@ func New(transport Transport, opts ...Option) *Client {
 		opt(&options)
 	}
 
+	if fn := options.onNotification; fn != nil {
+		if r, ok := transport.(NotificationReceiver); ok {
+			r.OnNotification(func(n *protocol.Request) {
+				fn(n.Method, n.Params)
+			})
+		}
+	}
+
 	return &Client{
 		transport: transport,
 		opts:      options,
@@ -171,12 +322,12 @@ func (c *Client) Initialize(ctx context.Context) (*ServerInfo, error) {
 
 	resp, err := c.call(ctx, protocol.MethodInitialize, params)
 	if err != nil {
-		return nil, fmt.Errorf("initialize: %w", err)
+		return nil, c.Errorf("initialize: %w", err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("initialize: invalid result type")
+		return nil, c.Errorf("initialize: invalid result type")
 	}
 
 	info := &ServerInfo{}
@@ -210,24 +361,37 @@ func (c *Client) Initialize(ctx context.Context) (*ServerInfo, error) {
 	c.serverInfo = info
 	c.mu.Unlock()
 
+	// Complete the handshake so the server accepts further requests
+	if err := c.notify(ctx, protocol.MethodInitialized, nil); err != nil {
+		return nil, c.Errorf("initialize: %w", err)
+	}
+
 	return info, nil
 }
 
 // ListTools returns the list of tools available on the server.
+// It follows nextCursor until all pages have been fetched.
 func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
-	resp, err := c.call(ctx, protocol.MethodToolsList, nil)
+	return listAll(ctx, c, "list tools", c.ListToolsPage)
+}
+
+// ListToolsPage returns a single page of tools starting at cursor.
+// Pass an empty cursor for the first page. The returned next cursor is
+// empty when there are no more pages.
+func (c *Client) ListToolsPage(ctx context.Context, cursor string) ([]Tool, string, error) {
+	resp, err := c.call(ctx, protocol.MethodToolsList, cursorParams(cursor))
 	if err != nil {
-		return nil, fmt.Errorf("list tools: %w", err)
+		return nil, "", c.Errorf("list tools: %w", err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("list tools: invalid result type")
+		return nil, "", c.Errorf("list tools: invalid result type")
 	}
 
 	toolsRaw, ok := result["tools"].([]any)
 	if !ok {
-		return nil, fmt.Errorf("list tools: invalid tools type")
+		return nil, "", c.Errorf("list tools: invalid tools type")
 	}
 
 	tools := make([]Tool, 0, len(toolsRaw))
@@ -250,7 +414,7 @@ func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
 		tools = append(tools, tool)
 	}
 
-	return tools, nil
+	return tools, nextCursor(result), nil
 }
 
 // CallTool calls a tool on the server with the given arguments.
@@ -264,19 +428,21 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments any) (*Too
 
 	resp, err := c.call(ctx, protocol.MethodToolsCall, params)
 	if err != nil {
-		return nil, fmt.Errorf("call tool %q: %w", name, err)
+		return nil, c.Errorf("call tool %q: %w", name, err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("call tool %q: invalid result type", name)
+		return nil, c.Errorf("call tool %q: invalid result type", name)
 	}
 
-	toolResult := &ToolResult{}
+	toolResult := &ToolResult{name: name}
 
 	if isErr, ok := result["isError"].(bool); ok {
 		toolResult.IsError = isErr
 	}
+	toolResult.Meta = resultMeta(result)
+	toolResult.StructuredContent = result["structuredContent"]
 
 	if content, ok := result["content"].([]any); ok {
 		for _, cr := range content {
@@ -285,17 +451,7 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments any) (*Too
 				continue
 			}
 
-			item := ContentItem{}
-			if t, ok := cm["type"].(string); ok {
-				item.Type = t
-			}
-			if text, ok := cm["text"].(string); ok {
-				item.Text = text
-			}
-			if data, ok := cm["data"].(string); ok {
-				item.Data = data
-			}
-			toolResult.Content = append(toolResult.Content, item)
+			toolResult.Content = append(toolResult.Content, parseContentItem(cm))
 		}
 	}
 
@@ -303,20 +459,26 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments any) (*Too
 }
 
 // ListResources returns the list of resources available on the server.
+// It follows nextCursor until all pages have been fetched.
 func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
-	resp, err := c.call(ctx, protocol.MethodResourcesList, nil)
+	return listAll(ctx, c, "list resources", c.ListResourcesPage)
+}
+
+// ListResourcesPage returns a single page of resources starting at cursor.
+func (c *Client) ListResourcesPage(ctx context.Context, cursor string) ([]Resource, string, error) {
+	resp, err := c.call(ctx, protocol.MethodResourcesList, cursorParams(cursor))
 	if err != nil {
-		return nil, fmt.Errorf("list resources: %w", err)
+		return nil, "", c.Errorf("list resources: %w", err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("list resources: invalid result type")
+		return nil, "", c.Errorf("list resources: invalid result type")
 	}
 
 	resourcesRaw, ok := result["resources"].([]any)
 	if !ok {
-		return nil, fmt.Errorf("list resources: invalid resources type")
+		return nil, "", c.Errorf("list resources: invalid resources type")
 	}
 
 	resources := make([]Resource, 0, len(resourcesRaw))
@@ -342,7 +504,50 @@ func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
 		resources = append(resources, resource)
 	}
 
-	return resources, nil
+	return resources, nextCursor(result), nil
+}
+
+// ListResourceTemplates returns the list of resource templates available
+// on the server. It follows nextCursor until all pages have been fetched.
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	return listAll(ctx, c, "list resource templates", c.ListResourceTemplatesPage)
+}
+
+// ListResourceTemplatesPage returns a single page of resource templates
+// starting at cursor.
+func (c *Client) ListResourceTemplatesPage(ctx context.Context, cursor string) ([]ResourceTemplate, string, error) {
+	resp, err := c.call(ctx, protocol.MethodResourcesTemplatesList, cursorParams(cursor))
+	if err != nil {
+		return nil, "", c.Errorf("list resource templates: %w", err)
+	}
+
+	result, ok := resp.Result.(map[string]any)
+	if !ok {
+		return nil, "", c.Errorf("list resource templates: invalid result type")
+	}
+
+	templatesRaw, ok := result["resourceTemplates"].([]any)
+	if !ok {
+		return nil, "", c.Errorf("list resource templates: invalid resourceTemplates type")
+	}
+
+	templates := make([]ResourceTemplate, 0, len(templatesRaw))
+	for _, tr := range templatesRaw {
+		tm, ok := tr.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		template := ResourceTemplate{}
+		template.URITemplate, _ = tm["uriTemplate"].(string)
+		template.Name, _ = tm["name"].(string)
+		template.Description, _ = tm["description"].(string)
+		template.MimeType, _ = tm["mimeType"].(string)
+		template.Annotations = tm["annotations"]
+		templates = append(templates, template)
+	}
+
+	return templates, nextCursor(result), nil
 }
 
 // ReadResource reads a resource from the server.
@@ -353,22 +558,22 @@ func (c *Client) ReadResource(ctx context.Context, uri string) (*ResourceContent
 
 	resp, err := c.call(ctx, protocol.MethodResourcesRead, params)
 	if err != nil {
-		return nil, fmt.Errorf("read resource %q: %w", uri, err)
+		return nil, c.Errorf("read resource %q: %w", uri, err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("read resource %q: invalid result type", uri)
+		return nil, c.Errorf("read resource %q: invalid result type", uri)
 	}
 
 	contents, ok := result["contents"].([]any)
 	if !ok || len(contents) == 0 {
-		return nil, fmt.Errorf("read resource %q: no content", uri)
+		return nil, c.Errorf("read resource %q: no content", uri)
 	}
 
 	cm, ok := contents[0].(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("read resource %q: invalid content type", uri)
+		return nil, c.Errorf("read resource %q: invalid content type", uri)
 	}
 
 	content := &ResourceContent{}
@@ -384,25 +589,32 @@ func (c *Client) ReadResource(ctx context.Context, uri string) (*ResourceContent
 	if blob, ok := cm["blob"].(string); ok {
 		content.Blob = blob
 	}
+	content.Meta = resultMeta(result)
 
 	return content, nil
 }
 
 // ListPrompts returns the list of prompts available on the server.
+// It follows nextCursor until all pages have been fetched.
 func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
-	resp, err := c.call(ctx, protocol.MethodPromptsList, nil)
+	return listAll(ctx, c, "list prompts", c.ListPromptsPage)
+}
+
+// ListPromptsPage returns a single page of prompts starting at cursor.
+func (c *Client) ListPromptsPage(ctx context.Context, cursor string) ([]Prompt, string, error) {
+	resp, err := c.call(ctx, protocol.MethodPromptsList, cursorParams(cursor))
 	if err != nil {
-		return nil, fmt.Errorf("list prompts: %w", err)
+		return nil, "", c.Errorf("list prompts: %w", err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("list prompts: invalid result type")
+		return nil, "", c.Errorf("list prompts: invalid result type")
 	}
 
 	promptsRaw, ok := result["prompts"].([]any)
 	if !ok {
-		return nil, fmt.Errorf("list prompts: invalid prompts type")
+		return nil, "", c.Errorf("list prompts: invalid prompts type")
 	}
 
 	prompts := make([]Prompt, 0, len(promptsRaw))
@@ -441,7 +653,7 @@ func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
 		prompts = append(prompts, prompt)
 	}
 
-	return prompts, nil
+	return prompts, nextCursor(result), nil
 }
 
 // GetPrompt gets a prompt with the given arguments.
@@ -455,12 +667,12 @@ func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[strin
 
 	resp, err := c.call(ctx, protocol.MethodPromptsGet, params)
 	if err != nil {
-		return nil, fmt.Errorf("get prompt %q: %w", name, err)
+		return nil, c.Errorf("get prompt %q: %w", name, err)
 	}
 
 	result, ok := resp.Result.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("get prompt %q: invalid result type", name)
+		return nil, c.Errorf("get prompt %q: invalid result type", name)
 	}
 
 	promptResult := &PromptResult{}
@@ -468,6 +680,7 @@ func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[strin
 	if desc, ok := result["description"].(string); ok {
 		promptResult.Description = desc
 	}
+	promptResult.Meta = resultMeta(result)
 
 	if messages, ok := result["messages"].([]any); ok {
 		for _, mr := range messages {
@@ -489,11 +702,28 @@ func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[strin
 	return promptResult, nil
 }
 
+// Subscribe asks the server to send notifications/resources/updated when
+// the resource at uri changes. Receive them with WithNotificationHandler.
+func (c *Client) Subscribe(ctx context.Context, uri string) error {
+	if _, err := c.call(ctx, protocol.MethodResourcesSubscribe, map[string]any{"uri": uri}); err != nil {
+		return c.Errorf("subscribe %q: %w", uri, err)
+	}
+	return nil
+}
+
+// Unsubscribe cancels a subscription made with Subscribe.
+func (c *Client) Unsubscribe(ctx context.Context, uri string) error {
+	if _, err := c.call(ctx, protocol.MethodResourcesUnsubscribe, map[string]any{"uri": uri}); err != nil {
+		return c.Errorf("unsubscribe %q: %w", uri, err)
+	}
+	return nil
+}
+
 // Ping sends a ping to the server.
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.call(ctx, protocol.MethodPing, nil)
 	if err != nil {
-		return fmt.Errorf("ping: %w", err)
+		return c.Errorf("ping: %w", err)
 	}
 	return nil
 }
@@ -510,6 +740,75 @@ func (c *Client) Close() error {
 	return c.transport.Close()
 }
 
+// listAll fetches every page of a list with page, following the next
+// cursors. A server returning a cursor it already returned would make the
+// client loop forever, so it is reported as an error.
+func listAll[T any](ctx context.Context, c *Client, op string, page func(ctx context.Context, cursor string) ([]T, string, error)) ([]T, error) {
+	var all []T
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		items, next, err := page(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if next == "" {
+			return all, nil
+		}
+		if seen[next] {
+			return nil, c.Errorf("%s: server repeated cursor %q", op, next)
+		}
+		seen[next] = true
+		cursor = next
+	}
+}
+
+// cursorParams builds list request params for the given cursor.
+// Returns nil for the first page so no params are sent.
+func cursorParams(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]any{"cursor": cursor}
+}
+
+// nextCursor extracts the pagination cursor from a list result.
+func nextCursor(result map[string]any) string {
+	next, _ := result["nextCursor"].(string)
+	return next
+}
+
+// resultMeta returns the _meta object of a result, or nil if there is none.
+func resultMeta(result map[string]any) map[string]any {
+	meta, _ := result["_meta"].(map[string]any)
+	return meta
+}
+
+// notify sends a JSON-RPC notification to the server.
+// It is a no-op if the transport does not implement Notifier.
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	notifier, ok := c.transport.(Notifier)
+	if !ok {
+		return nil
+	}
+
+	var paramsRaw json.RawMessage
+	if params != nil {
+		var err error
+		paramsRaw, err = json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("marshal params: %w", err)
+		}
+	}
+
+	return notifier.Notify(ctx, &protocol.Request{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  paramsRaw,
+	})
+}
+
 // call makes a JSON-RPC call to the server.
 func (c *Client) call(ctx context.Context, method string, params any) (*protocol.Response, error) {
 	id := c.requestID.Add(1)
@@ -540,6 +839,12 @@ func (c *Client) call(ctx context.Context, method string, params any) (*protocol
 		defer cancel()
 	}
 
+	if deadline, ok := ctx.Deadline(); ok && c.opts.propagateDeadline {
+		if err := req.SetTimeoutHint(time.Until(deadline)); err != nil {
+			return nil, fmt.Errorf("set timeout hint: %w", err)
+		}
+	}
+
 	resp, err := c.transport.Send(ctx, req)
 	if err != nil {
 		return nil, err
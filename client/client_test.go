@@ -3,6 +3,8 @@ package client_test
 import (
 	"context"
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -174,6 +176,68 @@ func TestClient_CallTool(t *testing.T) {
 		}
 	})
 
+	t.Run("returns image content", func(t *testing.T) {
+		transport := &mockTransport{
+			responses: []protocol.Response{
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Result: map[string]any{
+						"content": []any{
+							map[string]any{
+								"type":     "image",
+								"data":     "iVBORw==",
+								"mimeType": "image/png",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		c := client.New(transport)
+		result, err := c.CallTool(context.Background(), "screenshot", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		item := result.Content[0]
+		if item.Type != "image" || item.MimeType != "image/png" {
+			t.Errorf("item = %+v", item)
+		}
+		data, err := item.Bytes()
+		if err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+		if string(data) != "\x89PNG" {
+			t.Errorf("Bytes() = %q, want PNG header", data)
+		}
+	})
+
+	t.Run("exposes result meta", func(t *testing.T) {
+		transport := &mockTransport{
+			responses: []protocol.Response{
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Result: map[string]any{
+						"content": []any{},
+						"_meta":   map[string]any{"traceId": "abc"},
+					},
+				},
+			},
+		}
+
+		c := client.New(transport)
+		result, err := c.CallTool(context.Background(), "greet", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Meta["traceId"] != "abc" {
+			t.Errorf("Meta = %v, want traceId abc", result.Meta)
+		}
+	})
+
 	t.Run("returns error for unknown tool", func(t *testing.T) {
 		transport := &mockTransport{
 			responses: []protocol.Response{
@@ -235,6 +299,61 @@ func TestClient_ListResources(t *testing.T) {
 	})
 }
 
+func TestClient_ListResourceTemplates(t *testing.T) {
+	transport := &mockTransport{
+		responses: []protocol.Response{
+			{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Result: map[string]any{
+					"resourceTemplates": []any{
+						map[string]any{
+							"uriTemplate": "users://{id}",
+							"name":        "User",
+							"description": "User by ID",
+							"mimeType":    "application/json",
+							"annotations": map[string]any{"priority": 0.5},
+						},
+					},
+					"nextCursor": "page-2",
+				},
+			},
+			{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`2`),
+				Result: map[string]any{
+					"resourceTemplates": []any{
+						map[string]any{"uriTemplate": "files://{path}", "name": "File"},
+					},
+				},
+			},
+		},
+	}
+
+	c := client.New(transport)
+	templates, err := c.ListResourceTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(templates) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(templates))
+	}
+	got := templates[0]
+	if got.URITemplate != "users://{id}" || got.Name != "User" || got.Description != "User by ID" || got.MimeType != "application/json" {
+		t.Errorf("template = %+v", got)
+	}
+	if got.Annotations == nil {
+		t.Error("expected annotations")
+	}
+	if templates[1].URITemplate != "files://{path}" {
+		t.Errorf("second template = %+v", templates[1])
+	}
+	if transport.requests[0].Method != protocol.MethodResourcesTemplatesList {
+		t.Errorf("method = %q, want %q", transport.requests[0].Method, protocol.MethodResourcesTemplatesList)
+	}
+}
+
 func TestClient_ReadResource(t *testing.T) {
 	t.Run("reads resource content", func(t *testing.T) {
 		transport := &mockTransport{
@@ -265,6 +384,35 @@ func TestClient_ReadResource(t *testing.T) {
 		if content.Text != "Hello, World!" {
 			t.Errorf("text = %q, want %q", content.Text, "Hello, World!")
 		}
+		if content.Meta != nil {
+			t.Errorf("Meta = %v, want nil", content.Meta)
+		}
+	})
+
+	t.Run("exposes result meta", func(t *testing.T) {
+		transport := &mockTransport{
+			responses: []protocol.Response{
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Result: map[string]any{
+						"contents": []any{
+							map[string]any{"uri": "file://test.txt", "text": "cached"},
+						},
+						"_meta": map[string]any{"maxAge": float64(60)},
+					},
+				},
+			},
+		}
+
+		c := client.New(transport)
+		content, err := c.ReadResource(context.Background(), "file://test.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if content.Meta["maxAge"] != float64(60) {
+			t.Errorf("Meta = %v, want maxAge 60", content.Meta)
+		}
 	})
 }
 
@@ -366,7 +514,165 @@ func TestClient_Ping(t *testing.T) {
 	})
 }
 
+func TestClient_ListToolsPagination(t *testing.T) {
+	transport := &mockTransport{
+		responses: []protocol.Response{
+			{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Result: map[string]any{
+					"tools":      []any{map[string]any{"name": "a"}},
+					"nextCursor": "page-2",
+				},
+			},
+			{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`2`),
+				Result: map[string]any{
+					"tools": []any{map[string]any{"name": "b"}},
+				},
+			},
+		},
+	}
+
+	c := client.New(transport)
+	tools, err := c.ListTools(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tools) != 2 || tools[0].Name != "a" || tools[1].Name != "b" {
+		t.Errorf("tools = %+v", tools)
+	}
+	if transport.requests[0].Params != nil {
+		t.Errorf("first request params = %s, want none", transport.requests[0].Params)
+	}
+	var params struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := json.Unmarshal(transport.requests[1].Params, &params); err != nil {
+		t.Fatalf("unmarshal params: %v", err)
+	}
+	if params.Cursor != "page-2" {
+		t.Errorf("cursor = %q, want %q", params.Cursor, "page-2")
+	}
+}
+
+func TestClient_ListToolsRepeatedCursor(t *testing.T) {
+	page := func(id, cursor string) protocol.Response {
+		return protocol.Response{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(id),
+			Result: map[string]any{
+				"tools":      []any{map[string]any{"name": "t" + id}},
+				"nextCursor": cursor,
+			},
+		}
+	}
+	transport := &mockTransport{
+		responses: []protocol.Response{page("1", "page-2"), page("2", "page-3"), page("3", "page-2")},
+	}
+
+	c := client.New(transport)
+	tools, err := c.ListTools(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `repeated cursor "page-2"`) {
+		t.Fatalf("error = %v, want repeated cursor error", err)
+	}
+	if tools != nil {
+		t.Errorf("tools = %+v, want none", tools)
+	}
+	if len(transport.requests) != 3 {
+		t.Errorf("requests = %d, want 3", len(transport.requests))
+	}
+}
+
+func TestClient_InitializeSendsInitialized(t *testing.T) {
+	transport := &notifyingTransport{
+		mockTransport: mockTransport{
+			responses: []protocol.Response{
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Result: map[string]any{
+						"protocolVersion": "2024-11-05",
+						"serverInfo":      map[string]any{"name": "test-server", "version": "1.0.0"},
+					},
+				},
+			},
+		},
+	}
+
+	c := client.New(transport)
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(transport.notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(transport.notifications))
+	}
+	notif := transport.notifications[0]
+	if notif.Method != protocol.MethodInitialized {
+		t.Errorf("method = %q, want %q", notif.Method, protocol.MethodInitialized)
+	}
+	if !notif.IsNotification() {
+		t.Error("expected notification without ID")
+	}
+}
+
+// notifyingTransport is a mockTransport that also implements client.Notifier.
+type notifyingTransport struct {
+	mockTransport
+	notifications []protocol.Request
+}
+
+func (n *notifyingTransport) Notify(ctx context.Context, req *protocol.Request) error {
+	n.notifications = append(n.notifications, *req)
+	return nil
+}
+
 // mockTransport implements client.Transport for testing.
+func TestClient_DeadlinePropagation(t *testing.T) {
+	okResult := protocol.Response{JSONRPC: "2.0", ID: json.RawMessage(`1`), Result: map[string]any{"content": []any{}}}
+
+	tests := []struct {
+		name    string
+		opts    []client.Option
+		timeout time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{"context deadline", []client.Option{client.WithDeadlinePropagation()}, 2 * time.Second, time.Second, 2 * time.Second},
+		{"client timeout", []client.Option{client.WithDeadlinePropagation(), client.WithTimeout(500 * time.Millisecond)}, time.Minute, 0, 500 * time.Millisecond},
+		{"disabled", nil, 2 * time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &mockTransport{responses: []protocol.Response{okResult}}
+			c := client.New(transport, tt.opts...)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			if _, err := c.CallTool(ctx, "search", map[string]any{"q": "x"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			hint, ok := transport.requests[0].TimeoutHint()
+			if tt.wantMax == 0 {
+				if ok {
+					t.Errorf("unexpected timeout hint %v", hint)
+				}
+				return
+			}
+			if !ok || hint <= tt.wantMin || hint > tt.wantMax {
+				t.Errorf("timeout hint = %v, %v, want between %v and %v", hint, ok, tt.wantMin, tt.wantMax)
+			}
+			var params map[string]any
+			if err := json.Unmarshal(transport.requests[0].Params, &params); err != nil || params["name"] != "search" {
+				t.Errorf("params = %s, want the call params kept", transport.requests[0].Params)
+			}
+		})
+	}
+}
+
 type mockTransport struct {
 	responses []protocol.Response
 	requests  []protocol.Request
@@ -386,3 +692,161 @@ func (m *mockTransport) Send(ctx context.Context, req *protocol.Request) (*proto
 func (m *mockTransport) Close() error {
 	return nil
 }
+
+func TestPromptMessage_ContentItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		content any
+		want    client.ContentItem
+		wantOK  bool
+	}{
+		{
+			name:    "text",
+			content: map[string]any{"type": "text", "text": "hi"},
+			want:    client.ContentItem{Type: "text", Text: "hi"},
+			wantOK:  true,
+		},
+		{
+			name:    "audio",
+			content: map[string]any{"type": "audio", "data": "UklGRg==", "mimeType": "audio/wav"},
+			want:    client.ContentItem{Type: "audio", Data: "UklGRg==", MimeType: "audio/wav"},
+			wantOK:  true,
+		},
+		{
+			name: "resource link",
+			content: map[string]any{
+				"type": "resource_link", "uri": "file:///main.go", "name": "main.go",
+				"description": "Entry point", "mimeType": "text/x-go",
+			},
+			want: client.ContentItem{
+				Type: "resource_link", URI: "file:///main.go", Name: "main.go",
+				Description: "Entry point", MimeType: "text/x-go",
+			},
+			wantOK: true,
+		},
+		{
+			name: "embedded resource",
+			content: map[string]any{
+				"type": "resource",
+				"resource": map[string]any{
+					"uri": "file:///main.go", "mimeType": "text/x-go", "text": "package main",
+				},
+			},
+			want: client.ContentItem{
+				Type: "resource",
+				Resource: &client.ResourceContent{
+					URI: "file:///main.go", MimeType: "text/x-go", Text: "package main",
+				},
+			},
+			wantOK: true,
+		},
+		{
+			name:    "unsupported",
+			content: "plain string",
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := client.PromptMessage{Role: "user", Content: tt.content}.ContentItem()
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContentItem() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	transport := &mockTransport{
+		responses: []protocol.Response{
+			{JSONRPC: "2.0", ID: json.RawMessage(`1`), Result: map[string]any{}},
+			{JSONRPC: "2.0", ID: json.RawMessage(`2`), Result: map[string]any{}},
+		},
+	}
+	c := client.New(transport)
+
+	if err := c.Subscribe(context.Background(), "file:///a"); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := c.Unsubscribe(context.Background(), "file:///a"); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	for i, want := range []string{protocol.MethodResourcesSubscribe, protocol.MethodResourcesUnsubscribe} {
+		req := transport.requests[i]
+		if req.Method != want || string(req.Params) != `{"uri":"file:///a"}` {
+			t.Errorf("request %d = %s %s, want %s", i, req.Method, req.Params, want)
+		}
+	}
+}
+
+// receivingTransport is a mockTransport that delivers notifications.
+type receivingTransport struct {
+	mockTransport
+	onNotification func(n *protocol.Request)
+}
+
+func (m *receivingTransport) OnNotification(fn func(n *protocol.Request)) {
+	m.onNotification = fn
+}
+
+func TestWithNotificationHandler(t *testing.T) {
+	transport := &receivingTransport{}
+	var got []string
+	client.New(transport, client.WithNotificationHandler(func(method string, params json.RawMessage) {
+		got = append(got, method+" "+string(params))
+	}))
+
+	if transport.onNotification == nil {
+		t.Fatal("notification handler not passed to transport")
+	}
+	transport.onNotification(&protocol.Request{
+		JSONRPC: "2.0",
+		Method:  protocol.MethodResourceUpdated,
+		Params:  json.RawMessage(`{"uri":"file:///a"}`),
+	})
+	if want := []string{`notifications/resources/updated {"uri":"file:///a"}`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("notifications = %v, want %v", got, want)
+	}
+}
+
+func TestToolResult_CallToolResult(t *testing.T) {
+	transport := &mockTransport{
+		responses: []protocol.Response{
+			{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Result: map[string]any{
+					"content": []any{
+						map[string]any{"type": "text", "text": "42"},
+						map[string]any{"type": "resource", "resource": map[string]any{"uri": "file:///a", "text": "a"}},
+					},
+					"structuredContent": map[string]any{"answer": float64(42)},
+					"isError":           true,
+					"_meta":             map[string]any{"trace": "t1"},
+				},
+			},
+		},
+	}
+	c := client.New(transport)
+	result, err := c.CallTool(context.Background(), "answer", nil)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	want := protocol.CallToolResult{
+		Content: []protocol.Content{
+			{Type: "text", Text: "42"},
+			{Type: "resource", Resource: &protocol.ResourceContents{URI: "file:///a", Text: "a"}},
+		},
+		IsError:           true,
+		StructuredContent: map[string]any{"answer": float64(42)},
+		Meta:              map[string]any{"trace": "t1"},
+	}
+	if got := result.CallToolResult(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CallToolResult() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is returned by Client methods. It wraps the underlying error with
+// the connection context, so applications talking to several servers can
+// tell which one failed. Use errors.As to inspect it and errors.Is or
+// errors.As on the wrapped error as usual.
+type Error struct {
+	// ServerName and ServerVersion identify the server, once known.
+	ServerName    string
+	ServerVersion string
+	// ProtocolVersion is the negotiated protocol version, once known.
+	ProtocolVersion string
+	// Transport names the transport, if it implements TransportName.
+	Transport string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	var parts []string
+	if e.ServerName != "" {
+		server := "server " + e.ServerName
+		if e.ServerVersion != "" {
+			server += " " + e.ServerVersion
+		}
+		parts = append(parts, server)
+	}
+	if e.ProtocolVersion != "" {
+		parts = append(parts, "protocol "+e.ProtocolVersion)
+	}
+	if e.Transport != "" {
+		parts = append(parts, "transport "+e.Transport)
+	}
+
+	if len(parts) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v [%s]", e.Err, strings.Join(parts, ", "))
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf formats an error like fmt.Errorf and wraps it in an *Error
+// carrying this client's connection context. Host applications can use it
+// to attribute their own failures to a server.
+//
+// Example:
+//
+//	if err := process(result); err != nil {
+//	    return c.Errorf("process %s: %w", name, err)
+//	}
+func (c *Client) Errorf(format string, args ...any) error {
+	e := &Error{
+		Err:       fmt.Errorf(format, args...),
+		Transport: transportName(c.transport),
+	}
+
+	if info := c.ServerInfo(); info != nil {
+		e.ServerName = info.Name
+		e.ServerVersion = info.Version
+		e.ProtocolVersion = info.ProtocolVersion
+	}
+	return e
+}
+
+// transportName returns the name reported by t, if it implements
+// TransportName() string.
+func transportName(t Transport) string {
+	if named, ok := t.(interface{ TransportName() string }); ok {
+		return named.TransportName()
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/client"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestError_Error(t *testing.T) {
+	base := errors.New("list tools: boom")
+
+	tests := []struct {
+		name string
+		err  *client.Error
+		want string
+	}{
+		{
+			name: "no context",
+			err:  &client.Error{Err: base},
+			want: "list tools: boom",
+		},
+		{
+			name: "full context",
+			err: &client.Error{
+				ServerName:      "files",
+				ServerVersion:   "1.2.0",
+				ProtocolVersion: "2024-11-05",
+				Transport:       "stdio",
+				Err:             base,
+			},
+			want: "list tools: boom [server files 1.2.0, protocol 2024-11-05, transport stdio]",
+		},
+		{
+			name: "transport only",
+			err:  &client.Error{Transport: "stdio", Err: base},
+			want: "list tools: boom [transport stdio]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ErrorsCarryConnectionContext(t *testing.T) {
+	transport := &namedTransport{
+		mockTransport: mockTransport{
+			responses: []protocol.Response{
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Result: map[string]any{
+						"protocolVersion": "2024-11-05",
+						"serverInfo":      map[string]any{"name": "files", "version": "1.2.0"},
+					},
+				},
+				{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`2`),
+					Error:   &protocol.Error{Code: protocol.CodeMethodNotFound, Message: "method not found"},
+				},
+			},
+		},
+	}
+
+	c := client.New(transport)
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	_, err := c.ListTools(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var clientErr *client.Error
+	if !errors.As(err, &clientErr) {
+		t.Fatalf("expected *client.Error, got %T", err)
+	}
+	if clientErr.ServerName != "files" || clientErr.ServerVersion != "1.2.0" {
+		t.Errorf("server = %q %q, want files 1.2.0", clientErr.ServerName, clientErr.ServerVersion)
+	}
+	if clientErr.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocol version = %q, want 2024-11-05", clientErr.ProtocolVersion)
+	}
+	if clientErr.Transport != "mock" {
+		t.Errorf("transport = %q, want mock", clientErr.Transport)
+	}
+
+	var protoErr *protocol.Error
+	if !errors.As(err, &protoErr) {
+		t.Fatal("expected wrapped *protocol.Error")
+	}
+	if protoErr.Code != protocol.CodeMethodNotFound {
+		t.Errorf("code = %d, want %d", protoErr.Code, protocol.CodeMethodNotFound)
+	}
+}
+
+func TestClient_Errorf(t *testing.T) {
+	c := client.New(&mockTransport{})
+
+	err := c.Errorf("process %s: %w", "search", context.Canceled)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected errors.Is to find the wrapped error")
+	}
+	// Before Initialize nothing is known about the server
+	if got, want := err.Error(), "process search: context canceled"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// namedTransport is a mockTransport that reports a transport name.
+type namedTransport struct {
+	mockTransport
+}
+
+func (n *namedTransport) TransportName() string {
+	return "mock"
+}
@@ -25,6 +25,8 @@ type StdioTransport struct {
 	scanner  *bufio.Scanner
 	closed   bool
 
+	onNotification func(n *protocol.Request)
+
 	readWG sync.WaitGroup
 }
 
@@ -119,6 +121,37 @@ func (t *StdioTransport) Send(ctx context.Context, req *protocol.Request) (*prot
 	}
 }
 
+// TransportName returns "stdio". It is reported in *Error values.
+func (t *StdioTransport) TransportName() string {
+	return "stdio"
+}
+
+// Notify sends a notification without waiting for a response.
+func (t *StdioTransport) Notify(ctx context.Context, req *protocol.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return fmt.Errorf("transport closed")
+	}
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write notification: %w", err)
+	}
+	return nil
+}
+
+// OnNotification sets the function called with each notification the
+// subprocess sends. It implements NotificationReceiver.
+func (t *StdioTransport) OnNotification(fn func(n *protocol.Request)) {
+	t.mu.Lock()
+	t.onNotification = fn
+	t.mu.Unlock()
+}
+
 // Close closes the transport and terminates the subprocess.
 func (t *StdioTransport) Close() error {
 	t.mu.Lock()
@@ -149,6 +182,21 @@ func (t *StdioTransport) readResponses() {
 	for t.scanner.Scan() {
 		line := t.scanner.Text()
 
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue // Skip malformed messages
+		}
+		if msg.Method != "" {
+			// Requests from the server are not supported
+			if len(msg.ID) == 0 {
+				t.dispatchNotification(line)
+			}
+			continue
+		}
+
 		var resp protocol.Response
 		if err := json.Unmarshal([]byte(line), &resp); err != nil {
 			continue // Skip malformed responses
@@ -169,6 +217,21 @@ func (t *StdioTransport) readResponses() {
 	}
 }
 
+// dispatchNotification passes a notification to the notification handler.
+func (t *StdioTransport) dispatchNotification(line string) {
+	t.mu.Lock()
+	fn := t.onNotification
+	t.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	var n protocol.Request
+	if err := json.Unmarshal([]byte(line), &n); err != nil {
+		return
+	}
+	fn(&n)
+}
+
 // Stderr returns the stderr reader for the subprocess.
 func (t *StdioTransport) Stderr() io.Reader {
 	return t.stderr
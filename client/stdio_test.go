@@ -2,6 +2,7 @@ package client_test
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"testing"
@@ -69,6 +70,33 @@ func TestStdioTransport_Close(t *testing.T) {
 	}
 }
 
+func TestStdioTransport_Notifications(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	transport, err := client.NewStdioTransport("sh", "-c",
+		`echo '{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}'; cat >/dev/null`)
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	defer transport.Close()
+
+	got := make(chan string, 1)
+	client.New(transport, client.WithNotificationHandler(func(method string, params json.RawMessage) {
+		got <- method
+	}))
+
+	select {
+	case method := <-got:
+		if method != "notifications/tools/list_changed" {
+			t.Errorf("method = %q, want notifications/tools/list_changed", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not delivered")
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Create test server directory
 	os.MkdirAll("testdata/echoserver", 0755)
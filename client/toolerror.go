@@ -0,0 +1,88 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToolError is a structured error reported by a tool result with isError set.
+type ToolError struct {
+	// Tool is the name of the tool that produced the error.
+	Tool string
+	// Message is the human-readable error message.
+	Message string
+	// Code is an optional application-defined error code.
+	Code int
+	// Data holds optional additional error details.
+	Data any
+}
+
+// Error implements the error interface.
+func (e *ToolError) Error() string {
+	prefix := "tool error"
+	if e.Tool != "" {
+		prefix = fmt.Sprintf("tool %q error", e.Tool)
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("%s %d: %s", prefix, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", prefix, e.Message)
+}
+
+// Err returns the tool error carried by the result, or nil if the
+// result is not an error.
+//
+// Text content that is a JSON object with a "message" field is decoded
+// into Message, Code and Data. Any other text content is joined to form
+// the message.
+//
+// Example:
+//
+//	result, err := c.CallTool(ctx, "deploy", args)
+//	if err != nil {
+//	    return err // transport or protocol failure
+//	}
+//	if err := result.Err(); err != nil {
+//	    var toolErr *client.ToolError
+//	    errors.As(err, &toolErr)
+//	    log.Printf("deploy failed (%d): %s", toolErr.Code, toolErr.Message)
+//	}
+func (r *ToolResult) Err() error {
+	if r == nil || !r.IsError {
+		return nil
+	}
+	return parseToolError(r.name, r.Content)
+}
+
+// parseToolError builds a ToolError from isError result content.
+func parseToolError(name string, content []ContentItem) *ToolError {
+	toolErr := &ToolError{Tool: name}
+
+	var texts []string
+	for _, item := range content {
+		if item.Type != "text" || item.Text == "" {
+			continue
+		}
+
+		var structured struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+			Data    any    `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(item.Text), &structured); err == nil && structured.Message != "" {
+			toolErr.Message = structured.Message
+			toolErr.Code = structured.Code
+			toolErr.Data = structured.Data
+			return toolErr
+		}
+
+		texts = append(texts, item.Text)
+	}
+
+	toolErr.Message = strings.Join(texts, "\n")
+	if toolErr.Message == "" {
+		toolErr.Message = "unknown error"
+	}
+	return toolErr
+}
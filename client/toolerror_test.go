@@ -0,0 +1,126 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/client"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestToolResult_Err(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      map[string]any
+		wantErr     bool
+		wantMessage string
+		wantCode    int
+		wantData    any
+	}{
+		{
+			name: "nil for successful result",
+			result: map[string]any{
+				"content": []any{map[string]any{"type": "text", "text": "ok"}},
+			},
+		},
+		{
+			name: "plain text message",
+			result: map[string]any{
+				"isError": true,
+				"content": []any{
+					map[string]any{"type": "text", "text": "disk full"},
+					map[string]any{"type": "text", "text": "retry later"},
+				},
+			},
+			wantErr:     true,
+			wantMessage: "disk full\nretry later",
+		},
+		{
+			name: "structured JSON message",
+			result: map[string]any{
+				"isError": true,
+				"content": []any{
+					map[string]any{"type": "text", "text": `{"message":"quota exceeded","code":429,"data":{"limit":10}}`},
+				},
+			},
+			wantErr:     true,
+			wantMessage: "quota exceeded",
+			wantCode:    429,
+			wantData:    map[string]any{"limit": float64(10)},
+		},
+		{
+			name: "empty content",
+			result: map[string]any{
+				"isError": true,
+			},
+			wantErr:     true,
+			wantMessage: "unknown error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &mockTransport{
+				responses: []protocol.Response{
+					{JSONRPC: "2.0", ID: json.RawMessage(`1`), Result: tt.result},
+				},
+			}
+
+			c := client.New(transport)
+			result, err := c.CallTool(context.Background(), "deploy", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = result.Err()
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Err() = %v, want nil", err)
+				}
+				return
+			}
+
+			var toolErr *client.ToolError
+			if !errors.As(err, &toolErr) {
+				t.Fatalf("Err() = %v, want *ToolError", err)
+			}
+			if toolErr.Tool != "deploy" {
+				t.Errorf("Tool = %q, want %q", toolErr.Tool, "deploy")
+			}
+			if toolErr.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", toolErr.Message, tt.wantMessage)
+			}
+			if toolErr.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", toolErr.Code, tt.wantCode)
+			}
+			if tt.wantData != nil {
+				got, _ := json.Marshal(toolErr.Data)
+				want, _ := json.Marshal(tt.wantData)
+				if string(got) != string(want) {
+					t.Errorf("Data = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolError_Error(t *testing.T) {
+	err := &client.ToolError{Tool: "deploy", Message: "boom", Code: 7}
+	if got, want := err.Error(), `tool "deploy" error 7: boom`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	err = &client.ToolError{Message: "boom"}
+	if got, want := err.Error(), "tool error: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestToolResult_ErrNil(t *testing.T) {
+	var result *client.ToolResult
+	if err := result.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
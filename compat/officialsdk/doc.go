@@ -0,0 +1,44 @@
+// Package officialsdk eases migration between mcp-go and the official Go
+// SDK (github.com/modelcontextprotocol/go-sdk) in codebases that use both.
+//
+// Both libraries speak the same MCP wire format, so conversions go through
+// JSON: a value of one library is encoded and decoded into the matching
+// type of the other. The package therefore does not import the official
+// SDK, and adds nothing to the dependencies of programs that only use
+// mcp-go. The type parameters name the official SDK types at the call site.
+//
+// # Converting Types
+//
+//	tool, err := officialsdk.Tool[*mcp.Tool](info)         // mcp-go -> official
+//	content, err := officialsdk.FromContent(textContent)   // official -> mcp-go
+//	result, err := officialsdk.FromCallToolResult(res)     // official -> *server.ToolResult
+//
+// Official content values are interfaces, so convert to a concrete type
+// such as *mcp.TextContent, or convert a whole *mcp.CallToolResult, whose
+// content the SDK decodes itself.
+//
+// # Mounting Official SDK Tools
+//
+// Mount registers the tools of an official SDK server on an mcp-go server,
+// forwarding calls through a client session. With the official SDK's
+// in-memory transports:
+//
+//	st, ct := mcp.NewInMemoryTransports()
+//	if _, err := legacy.Connect(ctx, st, nil); err != nil {
+//	    log.Fatal(err)
+//	}
+//	cs, err := mcp.NewClient(&mcp.Implementation{Name: "bridge"}, nil).Connect(ctx, ct, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = officialsdk.Mount(ctx, srv, officialsdk.Backend{
+//	    ListTools: func(ctx context.Context) (any, error) {
+//	        return cs.ListTools(ctx, nil)
+//	    },
+//	    CallTool: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+//	        return cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+//	    },
+//	})
+//
+// Tools can then move from the official server to mcp-go one at a time.
+package officialsdk
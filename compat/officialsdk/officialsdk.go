@@ -0,0 +1,240 @@
+package officialsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Convert re-encodes v as T through its JSON form. It converts between
+// any two types that describe the same MCP object.
+func Convert[T any](v any) (T, error) {
+	var out T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return out, fmt.Errorf("officialsdk: encode %T: %w", v, err)
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("officialsdk: decode %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// Tool converts an mcp-go tool to T, such as *mcp.Tool.
+func Tool[T any](info server.ToolInfo) (T, error) {
+	return Convert[T](toolWire(info))
+}
+
+// Tools converts mcp-go tools, such as those returned by Server.Tools, to
+// a slice of T.
+func Tools[T any](infos []server.ToolInfo) ([]T, error) {
+	out := make([]T, 0, len(infos))
+	for _, info := range infos {
+		t, err := Tool[T](info)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Resource converts an mcp-go resource to T, such as *mcp.Resource.
+func Resource[T any](info server.ResourceInfo) (T, error) {
+	r := protocol.Resource{
+		URI:         info.URITemplate,
+		Name:        info.Name,
+		Description: info.Description,
+		MimeType:    info.MimeType,
+	}
+	if info.Annotations != nil {
+		r.Annotations = info.Annotations
+	}
+	return Convert[T](r)
+}
+
+// ResourceTemplate converts an mcp-go resource template to T, such as
+// *mcp.ResourceTemplate.
+func ResourceTemplate[T any](info server.ResourceTemplateInfo) (T, error) {
+	r := protocol.ResourceTemplate{
+		URITemplate: info.URITemplate,
+		Name:        info.Name,
+		Description: info.Description,
+		MimeType:    info.MimeType,
+	}
+	if info.Annotations != nil {
+		r.Annotations = info.Annotations
+	}
+	return Convert[T](r)
+}
+
+// Prompt converts an mcp-go prompt to T, such as *mcp.Prompt.
+func Prompt[T any](info server.PromptInfo) (T, error) {
+	p := protocol.Prompt{
+		Name:        info.Name,
+		Description: info.Description,
+	}
+	for _, arg := range info.Arguments {
+		p.Arguments = append(p.Arguments, protocol.PromptArgument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	if info.Annotations != nil {
+		p.Annotations = info.Annotations
+	}
+	return Convert[T](p)
+}
+
+// Content converts a content block to T, a concrete content type such as
+// *mcp.TextContent or *mcp.ImageContent.
+func Content[T any](c protocol.Content) (T, error) {
+	return Convert[T](c)
+}
+
+// CallToolResult converts an mcp-go tool result to T, such as
+// *mcp.CallToolResult.
+func CallToolResult[T any](r *server.ToolResult) (T, error) {
+	if err := r.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return Convert[T](r.CallToolResult())
+}
+
+// FromTool converts a tool of another library, such as *mcp.Tool, to its
+// mcp-go wire form.
+func FromTool(v any) (protocol.Tool, error) {
+	return Convert[protocol.Tool](v)
+}
+
+// FromContent converts a content block of another library, such as
+// *mcp.TextContent, to its mcp-go wire form.
+func FromContent(v any) (protocol.Content, error) {
+	c, err := Convert[protocol.Content](v)
+	if err != nil {
+		return c, err
+	}
+	if c.Type == "" {
+		return c, fmt.Errorf("officialsdk: %T is not a content block", v)
+	}
+	return c, nil
+}
+
+// FromCallToolResult converts a tool result of another library, such as
+// *mcp.CallToolResult, to an mcp-go tool result that a handler can return.
+func FromCallToolResult(v any) (*server.ToolResult, error) {
+	wire, err := Convert[protocol.CallToolResult](v)
+	if err != nil {
+		return nil, err
+	}
+	result := server.NewToolResult().Append(wire.Content...)
+	if wire.StructuredContent != nil {
+		result.Structured(wire.StructuredContent)
+	}
+	if wire.IsError {
+		result.WithError()
+	}
+	for key, value := range wire.Meta {
+		result.WithMeta(key, value)
+	}
+	return result, nil
+}
+
+// Backend is the tools of another MCP implementation, typically an official
+// SDK server reached through a client session.
+type Backend struct {
+	// ListTools returns all tools, as a tools/list result such as
+	// *mcp.ListToolsResult or as a slice of tools.
+	ListTools func(ctx context.Context) (any, error)
+
+	// CallTool calls the tool name with its JSON arguments and returns a
+	// tools/call result such as *mcp.CallToolResult.
+	CallTool func(ctx context.Context, name string, arguments json.RawMessage) (any, error)
+}
+
+// Mount registers every tool listed by backend on srv. Each keeps its name,
+// description, input and output schemas and annotations, and calls are
+// forwarded to backend.CallTool. Tools are listed once; call Mount again
+// to pick up tools added to the backend later. A tool already registered
+// on srv is replaced.
+func Mount(ctx context.Context, srv *server.Server, backend Backend) error {
+	if backend.ListTools == nil || backend.CallTool == nil {
+		return errors.New("officialsdk: backend needs ListTools and CallTool")
+	}
+
+	listed, err := backend.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("officialsdk: list tools: %w", err)
+	}
+	tools, err := decodeTools(listed)
+	if err != nil {
+		return err
+	}
+
+	for _, tool := range tools {
+		name := tool.Name
+		b := srv.Tool(name).Description(tool.Description)
+		if tool.InputSchema != nil {
+			b.InputSchema(tool.InputSchema)
+		}
+		if tool.OutputSchema != nil {
+			b.OutputSchema(tool.OutputSchema)
+		}
+		if tool.Annotations != nil {
+			annotations, err := Convert[server.ToolAnnotations](tool.Annotations)
+			if err != nil {
+				return err
+			}
+			b.Annotations(annotations)
+		}
+		b.Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+			res, err := backend.CallTool(ctx, name, args)
+			if err != nil {
+				return nil, err
+			}
+			return FromCallToolResult(res)
+		})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("officialsdk: mount tool %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// decodeTools reads the tools of a tools/list result or a slice of tools.
+func decodeTools(listed any) ([]protocol.Tool, error) {
+	data, err := json.Marshal(listed)
+	if err != nil {
+		return nil, fmt.Errorf("officialsdk: encode tools: %w", err)
+	}
+
+	var tools []protocol.Tool
+	if err := json.Unmarshal(data, &tools); err == nil {
+		return tools, nil
+	}
+	var result protocol.ToolsListResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("officialsdk: decode tools: %w", err)
+	}
+	return result.Tools, nil
+}
+
+// toolWire returns the tools/list entry of an mcp-go tool.
+func toolWire(info server.ToolInfo) protocol.Tool {
+	t := protocol.Tool{
+		Name:         info.Name,
+		Description:  info.Description,
+		InputSchema:  info.InputSchema,
+		OutputSchema: info.OutputSchema,
+	}
+	if info.Annotations != nil {
+		t.Annotations = info.Annotations
+	}
+	return t
+}
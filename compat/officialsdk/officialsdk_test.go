@@ -0,0 +1,319 @@
+package officialsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/testutil"
+)
+
+// The sdk types mirror the JSON shape of the official SDK types, whose
+// content values encode their own "type" field.
+
+type sdkTool struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	InputSchema any                 `json:"inputSchema"`
+	Annotations *sdkToolAnnotations `json:"annotations,omitempty"`
+}
+
+type sdkToolAnnotations struct {
+	Title        string `json:"title,omitempty"`
+	ReadOnlyHint bool   `json:"readOnlyHint,omitempty"`
+}
+
+type sdkListToolsResult struct {
+	Tools      []*sdkTool `json:"tools"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+type sdkTextContent struct {
+	Text string
+}
+
+func (c *sdkTextContent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"type": "text", "text": c.Text})
+}
+
+func (c *sdkTextContent) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	if wire.Type != "text" {
+		return errors.New("not text content")
+	}
+	c.Text = wire.Text
+	return nil
+}
+
+type sdkCallToolResult struct {
+	Content []any          `json:"content"`
+	IsError bool           `json:"isError,omitempty"`
+	Meta    map[string]any `json:"_meta,omitempty"`
+}
+
+type sdkPrompt struct {
+	Name      string `json:"name"`
+	Arguments []struct {
+		Name     string `json:"name"`
+		Required bool   `json:"required,omitempty"`
+	} `json:"arguments,omitempty"`
+}
+
+type sdkResource struct {
+	URI      string `json:"uri"`
+	Name     string `json:"name"`
+	MIMEType string `json:"mimeType,omitempty"`
+}
+
+type sdkResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+}
+
+func TestToSDK(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	srv.Tool("search").Description("Search docs").ReadOnly().
+		Handler(func(in struct {
+			Query string `json:"query"`
+		}) (string, error) {
+			return in.Query, nil
+		})
+	srv.Resource("file:///{path}").Name("files").MimeType("text/plain").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return nil, nil
+		})
+	srv.Prompt("review").Argument("code", "Code to review", true).
+		Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+			return nil, nil
+		})
+
+	tools, err := Tools[*sdkTool](srv.Tools())
+	if err != nil {
+		t.Fatalf("Tools() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "search" || tools[0].Description != "Search docs" {
+		t.Fatalf("Tools() = %+v", tools)
+	}
+	if tools[0].Annotations == nil || !tools[0].Annotations.ReadOnlyHint {
+		t.Errorf("Annotations = %+v, want read-only", tools[0].Annotations)
+	}
+	schema, _ := tools[0].InputSchema.(map[string]any)
+	if _, ok := schema["properties"].(map[string]any)["query"]; !ok {
+		t.Errorf("InputSchema = %v, want a query property", tools[0].InputSchema)
+	}
+
+	resource, err := Resource[*sdkResource](srv.Resources()[0])
+	if err != nil {
+		t.Fatalf("Resource() error = %v", err)
+	}
+	if resource.URI != "file:///{path}" || resource.Name != "files" || resource.MIMEType != "text/plain" {
+		t.Errorf("Resource() = %+v", resource)
+	}
+
+	template, err := ResourceTemplate[sdkResourceTemplate](server.ResourceTemplateInfo{URITemplate: "db://{table}", Name: "tables"})
+	if err != nil {
+		t.Fatalf("ResourceTemplate() error = %v", err)
+	}
+	if template.URITemplate != "db://{table}" || template.Name != "tables" {
+		t.Errorf("ResourceTemplate() = %+v", template)
+	}
+
+	prompt, err := Prompt[*sdkPrompt](srv.Prompts()[0])
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if prompt.Name != "review" || len(prompt.Arguments) != 1 || !prompt.Arguments[0].Required {
+		t.Errorf("Prompt() = %+v", prompt)
+	}
+
+	text, err := Content[*sdkTextContent](protocol.Content{Type: server.ContentTypeText, Text: "hi"})
+	if err != nil || text.Text != "hi" {
+		t.Errorf("Content() = %+v, %v", text, err)
+	}
+	if _, err := Content[*sdkTextContent](protocol.Content{Type: server.ContentTypeImage, Data: "AA=="}); err == nil {
+		t.Error("Content() of an image as text succeeded, want error")
+	}
+}
+
+func TestCallToolResult(t *testing.T) {
+	t.Run("converts content and error flag", func(t *testing.T) {
+		result := server.NewToolResult().Text("failed").WithError().WithMeta("traceId", "abc")
+
+		got, err := CallToolResult[sdkCallToolResult](result)
+		if err != nil {
+			t.Fatalf("CallToolResult() error = %v", err)
+		}
+		if !got.IsError || got.Meta["traceId"] != "abc" || len(got.Content) != 1 {
+			t.Errorf("CallToolResult() = %+v", got)
+		}
+	})
+
+	t.Run("reports build errors", func(t *testing.T) {
+		result := server.NewToolResult().Image([]byte{1}, "text/plain")
+
+		if _, err := CallToolResult[sdkCallToolResult](result); err == nil {
+			t.Error("CallToolResult() error = nil, want invalid MIME type")
+		}
+	})
+}
+
+func TestFromSDK(t *testing.T) {
+	t.Run("tool", func(t *testing.T) {
+		got, err := FromTool(&sdkTool{Name: "echo", InputSchema: map[string]any{"type": "object"}})
+		if err != nil {
+			t.Fatalf("FromTool() error = %v", err)
+		}
+		if got.Name != "echo" || !reflect.DeepEqual(got.InputSchema, map[string]any{"type": "object"}) {
+			t.Errorf("FromTool() = %+v", got)
+		}
+	})
+
+	t.Run("content", func(t *testing.T) {
+		got, err := FromContent(&sdkTextContent{Text: "hello"})
+		if err != nil {
+			t.Fatalf("FromContent() error = %v", err)
+		}
+		if got.Type != server.ContentTypeText || got.Text != "hello" {
+			t.Errorf("FromContent() = %+v", got)
+		}
+	})
+
+	t.Run("not content", func(t *testing.T) {
+		if _, err := FromContent(struct{ Name string }{"x"}); err == nil {
+			t.Error("FromContent() error = nil, want error")
+		}
+	})
+
+	t.Run("tool result", func(t *testing.T) {
+		got, err := FromCallToolResult(&sdkCallToolResult{
+			Content: []any{&sdkTextContent{Text: "a"}, &sdkTextContent{Text: "b"}},
+			IsError: true,
+			Meta:    map[string]any{"cost": 2.0},
+		})
+		if err != nil {
+			t.Fatalf("FromCallToolResult() error = %v", err)
+		}
+		if len(got.Content()) != 2 || got.Content()[1].Text != "b" {
+			t.Errorf("Content() = %+v", got.Content())
+		}
+		if !got.IsError() || got.Meta()["cost"] != 2.0 {
+			t.Errorf("IsError() = %v, Meta() = %v", got.IsError(), got.Meta())
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		if _, err := FromCallToolResult(make(chan int)); err == nil {
+			t.Error("FromCallToolResult() error = nil, want encode error")
+		}
+	})
+}
+
+func TestMount(t *testing.T) {
+	inputSchema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"text": map[string]any{"type": "string"}},
+	}
+	tools := []*sdkTool{
+		{Name: "upper", Description: "Upper-case text", InputSchema: inputSchema, Annotations: &sdkToolAnnotations{Title: "Upper", ReadOnlyHint: true}},
+		{Name: "fail", InputSchema: map[string]any{"type": "object"}},
+	}
+
+	tests := []struct {
+		name   string
+		listed any
+	}{
+		{name: "list result", listed: &sdkListToolsResult{Tools: tools}},
+		{name: "tool slice", listed: tools},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			backend := Backend{
+				ListTools: func(ctx context.Context) (any, error) {
+					return tt.listed, nil
+				},
+				CallTool: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+					calls = append(calls, name+" "+string(args))
+					if name == "fail" {
+						return nil, errors.New("backend down")
+					}
+					var in struct {
+						Text string `json:"text"`
+					}
+					if err := json.Unmarshal(args, &in); err != nil {
+						return nil, err
+					}
+					return &sdkCallToolResult{Content: []any{&sdkTextContent{Text: strings.ToUpper(in.Text)}}}, nil
+				},
+			}
+
+			srv := server.New(server.Info{Name: "bridge", Version: "1.0.0", Capabilities: server.Capabilities{Tools: true}})
+			if err := Mount(context.Background(), srv, backend); err != nil {
+				t.Fatalf("Mount() error = %v", err)
+			}
+
+			infos := srv.Tools()
+			if len(infos) != 2 {
+				t.Fatalf("got %d tools, want 2", len(infos))
+			}
+			upper := infos[slices.IndexFunc(infos, func(i server.ToolInfo) bool { return i.Name == "upper" })]
+			if upper.Description != "Upper-case text" {
+				t.Errorf("tool = %+v", upper)
+			}
+			if !reflect.DeepEqual(upper.InputSchema, inputSchema) {
+				t.Errorf("InputSchema = %v, want %v", upper.InputSchema, inputSchema)
+			}
+			if a := upper.Annotations; a == nil || a.Title != "Upper" || a.ReadOnlyHint == nil || !*a.ReadOnlyHint {
+				t.Errorf("Annotations = %+v", a)
+			}
+
+			tc := testutil.NewTestClient(t, srv)
+			got, err := tc.CallTool("upper", map[string]any{"text": "hi"})
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if got != "HI" {
+				t.Errorf("CallTool() = %q, want %q", got, "HI")
+			}
+			if _, err := tc.CallTool("fail", map[string]any{}); err == nil {
+				t.Error("CallTool(fail) error = nil, want backend error")
+			}
+			if len(calls) != 2 || calls[0] != `upper {"text":"hi"}` {
+				t.Errorf("calls = %q", calls)
+			}
+		})
+	}
+
+	t.Run("incomplete backend", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "bridge", Version: "1.0.0"})
+		if err := Mount(context.Background(), srv, Backend{}); err == nil {
+			t.Error("Mount() error = nil, want error")
+		}
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "bridge", Version: "1.0.0"})
+		err := Mount(context.Background(), srv, Backend{
+			ListTools: func(ctx context.Context) (any, error) { return nil, errors.New("closed") },
+			CallTool: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+				return nil, nil
+			},
+		})
+		if err == nil || !strings.Contains(err.Error(), "closed") {
+			t.Errorf("Mount() error = %v, want list error", err)
+		}
+	})
+}
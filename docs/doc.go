@@ -0,0 +1,20 @@
+// Package docs generates human-readable documentation for the tools,
+// resources and prompts registered with a server, so reference docs are
+// rebuilt from the code instead of maintained by hand.
+//
+// # Generating Markdown
+//
+//	data := docs.GenerateMarkdown(srv)
+//	if err := os.WriteFile("TOOLS.md", data, 0o644); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Each tool gets a section with its description, behavior hints and a
+// table per input and output schema listing every property, including
+// nested ones as dotted paths. Resources and resource templates are
+// listed in tables, and prompts with their arguments. Output is
+// deterministic, so a test can compare the generated document with the
+// checked-in file and fail when the two drift apart.
+//
+// WithSchemaJSON additionally embeds the raw JSON Schemas.
+package docs
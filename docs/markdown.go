@@ -0,0 +1,392 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Option configures GenerateMarkdown.
+type Option func(*config)
+
+type config struct {
+	title      string
+	schemaJSON bool
+}
+
+// WithTitle sets the top-level heading. Defaults to the server name and
+// version.
+func WithTitle(title string) Option {
+	return func(c *config) {
+		c.title = title
+	}
+}
+
+// WithSchemaJSON embeds the input and output schemas of each tool as JSON
+// in a collapsible block below its tables.
+func WithSchemaJSON() Option {
+	return func(c *config) {
+		c.schemaJSON = true
+	}
+}
+
+// GenerateMarkdown renders the tools, resources, resource templates and
+// prompts registered with srv as a Markdown document.
+func GenerateMarkdown(srv *server.Server, opts ...Option) []byte {
+	info := srv.Info()
+	cfg := &config{title: strings.TrimSpace(info.Name + " " + info.Version)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n", cfg.title)
+	if instructions := srv.Instructions(); instructions != "" {
+		fmt.Fprintf(&b, "\n%s\n", instructions)
+	}
+
+	if tools := srv.Tools(); len(tools) > 0 {
+		b.WriteString("\n## Tools\n")
+		for _, t := range tools {
+			writeTool(&b, cfg, t)
+		}
+	}
+
+	if resources := staticResources(srv); len(resources) > 0 {
+		b.WriteString("\n## Resources\n\n")
+		b.WriteString("| URI | Name | MIME type | Description |\n|---|---|---|---|\n")
+		for _, r := range resources {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				r.URITemplate, cell(displayName(r.Name, r.Title)), cell(r.MimeType), cell(r.Description))
+		}
+	}
+
+	if templates := srv.ResourceTemplates(); len(templates) > 0 {
+		b.WriteString("\n## Resource Templates\n\n")
+		b.WriteString("| URI template | Name | MIME type | Description |\n|---|---|---|---|\n")
+		for _, t := range templates {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				t.URITemplate, cell(displayName(t.Name, t.Title)), cell(t.MimeType), cell(t.Description))
+		}
+	}
+
+	if prompts := srv.Prompts(); len(prompts) > 0 {
+		b.WriteString("\n## Prompts\n")
+		for _, p := range prompts {
+			writePrompt(&b, p)
+		}
+	}
+	return b.Bytes()
+}
+
+// staticResources returns the registered resources that are not
+// templates, which are listed separately.
+func staticResources(srv *server.Server) []server.ResourceInfo {
+	templates := make(map[string]bool)
+	for _, t := range srv.ResourceTemplates() {
+		templates[t.URITemplate] = true
+	}
+	var resources []server.ResourceInfo
+	for _, r := range srv.Resources() {
+		if !templates[r.URITemplate] {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+func writeTool(b *bytes.Buffer, cfg *config, t server.ToolInfo) {
+	fmt.Fprintf(b, "\n### `%s`\n\n", t.Name)
+	if t.Title != "" {
+		fmt.Fprintf(b, "**%s**\n\n", t.Title)
+	}
+	if t.Deprecated != "" {
+		fmt.Fprintf(b, "> **Deprecated:** %s\n\n", t.Deprecated)
+	}
+	if t.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", t.Description)
+	}
+	if hints := toolHints(t.Annotations); len(hints) > 0 {
+		fmt.Fprintf(b, "_%s_\n\n", strings.Join(hints, " · "))
+	}
+
+	input := normalize(t.InputSchema)
+	b.WriteString("#### Input\n\n")
+	writeSchemaTable(b, input)
+	if t.OutputSchema != nil {
+		b.WriteString("\n#### Output\n\n")
+		writeSchemaTable(b, normalize(t.OutputSchema))
+	}
+
+	if cfg.schemaJSON {
+		writeSchemaJSON(b, "Input schema", input)
+		if t.OutputSchema != nil {
+			writeSchemaJSON(b, "Output schema", normalize(t.OutputSchema))
+		}
+	}
+}
+
+// toolHints describes the behavior hints of a tool that differ from the
+// defaults clients assume.
+func toolHints(a *server.ToolAnnotations) []string {
+	if a == nil {
+		return nil
+	}
+	var hints []string
+	if a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+		hints = append(hints, "Read-only")
+	}
+	if a.DestructiveHint != nil && !*a.DestructiveHint {
+		hints = append(hints, "Non-destructive")
+	}
+	if a.IdempotentHint != nil && *a.IdempotentHint {
+		hints = append(hints, "Idempotent")
+	}
+	if a.OpenWorldHint != nil && !*a.OpenWorldHint {
+		hints = append(hints, "Closed world")
+	}
+	return hints
+}
+
+func writePrompt(b *bytes.Buffer, p server.PromptInfo) {
+	fmt.Fprintf(b, "\n### `%s`\n\n", p.Name)
+	if p.Title != "" {
+		fmt.Fprintf(b, "**%s**\n\n", p.Title)
+	}
+	if p.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", p.Description)
+	}
+	if len(p.Arguments) == 0 {
+		b.WriteString("No arguments.\n")
+		return
+	}
+	b.WriteString("| Argument | Required | Description |\n|---|---|---|\n")
+	for _, arg := range p.Arguments {
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", arg.Name, yesNo(arg.Required), cell(arg.Description))
+	}
+}
+
+// property is a row of a schema table.
+type property struct {
+	path     string
+	schema   map[string]any
+	required bool
+}
+
+func writeSchemaTable(b *bytes.Buffer, schema map[string]any) {
+	var props []property
+	collectProperties(&props, "", schema)
+	if len(props) == 0 {
+		if t := typeName(schema); t != "object" && t != "" {
+			fmt.Fprintf(b, "Type: %s\n", cell(t))
+			return
+		}
+		b.WriteString("No parameters.\n")
+		return
+	}
+	b.WriteString("| Name | Type | Required | Description |\n|---|---|---|---|\n")
+	for _, p := range props {
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n",
+			p.path, cell(typeName(p.schema)), yesNo(p.required), cell(describe(p.schema)))
+	}
+}
+
+// collectProperties appends the properties of an object schema, then
+// those of nested objects and arrays of objects, in name order.
+func collectProperties(props *[]property, prefix string, schema map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]any); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := properties[name].(map[string]any)
+		path := prefix + name
+		*props = append(*props, property{path: path, schema: prop, required: required[name]})
+		if _, ok := prop["properties"]; ok {
+			collectProperties(props, path+".", prop)
+		} else if items, ok := prop["items"].(map[string]any); ok {
+			if _, ok := items["properties"]; ok {
+				collectProperties(props, path+"[].", items)
+			}
+		}
+	}
+}
+
+// typeName describes the type of a schema, such as "string (date-time)"
+// or "array of integer".
+func typeName(schema map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	var name string
+	switch t := schema["type"].(type) {
+	case string:
+		name = t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+		name = strings.Join(types, " | ")
+	default:
+		if _, ok := schema["properties"]; ok {
+			name = "object"
+		} else if variants, ok := schema["oneOf"].([]any); ok {
+			name = "one of " + variantNames(schema, variants)
+		}
+	}
+	switch name {
+	case "array":
+		if item := elementType(schema, "items"); item != "" {
+			name = "array of " + item
+		}
+	case "object":
+		if value := elementType(schema, "additionalProperties"); value != "" {
+			name = "map of " + value
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		name += " (" + format + ")"
+	}
+	return name
+}
+
+// elementType returns the type name of the element schema under key,
+// such as the items of an array.
+func elementType(schema map[string]any, key string) string {
+	elem, ok := schema[key].(map[string]any)
+	if !ok {
+		return ""
+	}
+	return typeName(elem)
+}
+
+// variantNames lists the variants of a oneOf schema by their
+// discriminator values, or by type if it has no discriminator.
+func variantNames(schema map[string]any, variants []any) string {
+	disc, _ := schema["discriminator"].(map[string]any)
+	prop, _ := disc["propertyName"].(string)
+	names := make([]string, 0, len(variants))
+	for _, v := range variants {
+		variant, _ := v.(map[string]any)
+		props, _ := variant["properties"].(map[string]any)
+		if p, ok := props[prop].(map[string]any); ok && p["const"] != nil {
+			names = append(names, fmt.Sprint(p["const"]))
+		} else {
+			names = append(names, typeName(variant))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// describe returns the annotations and description of a schema followed
+// by its allowed values, default and examples.
+func describe(schema map[string]any) string {
+	parts := make([]string, 0, 5)
+	if notes := annotations(schema); notes != "" {
+		parts = append(parts, notes)
+	}
+	if desc, ok := schema["description"].(string); ok && desc != "" {
+		parts = append(parts, desc)
+	}
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = "`" + jsonValue(v) + "`"
+		}
+		parts = append(parts, "One of: "+strings.Join(quoted, ", ")+".")
+	}
+	if def, ok := schema["default"]; ok {
+		parts = append(parts, "Default: `"+jsonValue(def)+"`.")
+	}
+	if examples, ok := schema["examples"].([]any); ok && len(examples) > 0 {
+		quoted := make([]string, len(examples))
+		for i, v := range examples {
+			quoted[i] = "`" + jsonValue(v) + "`"
+		}
+		parts = append(parts, "Examples: "+strings.Join(quoted, ", ")+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// annotations returns the deprecated, readOnly and writeOnly annotations
+// of a schema, such as "**Deprecated.** Read-only."
+func annotations(schema map[string]any) string {
+	var notes []string
+	if schema["deprecated"] == true {
+		notes = append(notes, "**Deprecated.**")
+	}
+	if schema["readOnly"] == true {
+		notes = append(notes, "Read-only.")
+	}
+	if schema["writeOnly"] == true {
+		notes = append(notes, "Write-only.")
+	}
+	return strings.Join(notes, " ")
+}
+
+func writeSchemaJSON(b *bytes.Buffer, summary string, schema map[string]any) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(b, "\n<details>\n<summary>%s</summary>\n\n```json\n%s\n```\n\n</details>\n", summary, data)
+}
+
+// normalize converts a schema of any Go representation, such as a struct
+// or json.RawMessage, to its generic JSON form.
+func normalize(schema any) map[string]any {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+func jsonValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func displayName(name, title string) string {
+	if title == "" || title == name {
+		return name
+	}
+	return title + " (" + name + ")"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// cell escapes s for use in a Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
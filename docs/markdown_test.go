@@ -0,0 +1,128 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+type address struct {
+	City    string `json:"city" jsonschema:"required,description=City name"`
+	Country string `json:"country"`
+}
+
+type createInput struct {
+	Name    string    `json:"name" jsonschema:"required,description=Customer name"`
+	Address address   `json:"address"`
+	Items   []address `json:"items"`
+	Tags    []string  `json:"tags" jsonschema:"description=Labels | notes"`
+	Limit   int       `json:"limit" jsonschema:"default=10,example=25,example=50"`
+	Fax     string    `json:"fax" jsonschema:"deprecated,description=No longer used"`
+}
+
+func newServer() *server.Server {
+	srv := server.New(server.Info{Name: "crm", Version: "1.0.0"}, server.WithInstructions("Manage customers."))
+	srv.Tool("create").Title("Create customer").Description("Create a customer").
+		Idempotent().
+		Handler(func(in createInput) (string, error) { return "", nil })
+	srv.Tool("upgrade").InputSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"plan": map[string]any{"type": "string", "enum": []any{"free", "pro"}, "default": "free"},
+		},
+	}).Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+		return server.NewToolResult(), nil
+	})
+	srv.Tool("ping").Deprecated("use health").Handler(func(in struct{}) (string, error) { return "", nil })
+	srv.Resource("crm://customers").Name("customers").MimeType("application/json").Description("All customers").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri}, nil
+		})
+	srv.Resource("crm://customers/{id}").Name("customer").Title("Customer").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri}, nil
+		})
+	srv.Prompt("welcome").Description("Welcome a customer").Argument("name", "Customer name", true).
+		Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+			return &server.PromptResult{}, nil
+		})
+	return srv
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	got := string(GenerateMarkdown(newServer()))
+
+	wants := []string{
+		"# crm 1.0.0\n\nManage customers.\n",
+		"### `create`\n\n**Create customer**\n\nCreate a customer\n\n_Idempotent_\n\n#### Input\n\n",
+		"| `name` | string | yes | Customer name |",
+		"| `plan` | string | no | One of: `\"free\"`, `\"pro\"`. Default: `\"free\"`. |",
+		"| `address` | object | no |  |",
+		"| `address.city` | string | yes | City name |",
+		"| `items[].city` | string | yes | City name |",
+		"| `tags` | array of string | no | Labels \\| notes |",
+		"| `limit` | integer | no | Default: `10`. Examples: `25`, `50`. |",
+		"| `fax` | string | no | **Deprecated.** No longer used |",
+		"### `ping`\n\n> **Deprecated:** use health\n\n#### Input\n\nNo parameters.\n",
+		"## Resources\n\n| URI | Name | MIME type | Description |\n|---|---|---|---|\n| `crm://customers` | customers | application/json | All customers |\n",
+		"## Resource Templates\n\n| URI template | Name | MIME type | Description |\n|---|---|---|---|\n| `crm://customers/{id}` | Customer (customer) |  |  |\n",
+		"### `welcome`\n\nWelcome a customer\n\n| Argument | Required | Description |\n|---|---|---|\n| `name` | yes | Customer name |\n",
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<details>") {
+		t.Error("schema JSON included without WithSchemaJSON")
+	}
+	if again := string(GenerateMarkdown(newServer())); again != got {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestGenerateMarkdown_Options(t *testing.T) {
+	got := string(GenerateMarkdown(newServer(), WithTitle("CRM tools"), WithSchemaJSON()))
+	if !strings.HasPrefix(got, "# CRM tools\n") {
+		t.Errorf("title not applied:\n%s", got)
+	}
+	if !strings.Contains(got, "<summary>Input schema</summary>\n\n```json\n{") {
+		t.Errorf("schema JSON missing:\n%s", got)
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]any
+		want   string
+	}{
+		{"plain", map[string]any{"type": "integer"}, "integer"},
+		{"format", map[string]any{"type": "string", "format": "date-time"}, "string (date-time)"},
+		{"union", map[string]any{"type": []any{"string", "null"}}, "string | null"},
+		{"array", map[string]any{"type": "array", "items": map[string]any{"type": "number"}}, "array of number"},
+		{"ref", map[string]any{"$ref": "#/$defs/Address"}, "Address"},
+		{"map", map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}}, "map of integer"},
+		{"closed object", map[string]any{"type": "object", "additionalProperties": false}, "object"},
+		{"untyped object", map[string]any{"properties": map[string]any{}}, "object"},
+		{"union", map[string]any{
+			"oneOf": []any{
+				map[string]any{"properties": map[string]any{"kind": map[string]any{"const": "circle"}}},
+				map[string]any{"properties": map[string]any{"kind": map[string]any{"const": "square"}}},
+			},
+			"discriminator": map[string]any{"propertyName": "kind"},
+		}, "one of circle, square"},
+		{"oneOf", map[string]any{"oneOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}}}, "one of string, integer"},
+		{"any", map[string]any{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := typeName(tt.schema); got != tt.want {
+				t.Errorf("typeName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package errors defines domain errors that handlers return to report why a
+// request failed. The request handler sends each kind with its own JSON-RPC
+// error code instead of an internal error, so clients can tell a missing
+// record from a denied request or an outage and react accordingly.
+//
+// The package name shadows the standard library, so import it under
+// another name:
+//
+//	import mcperrors "github.com/felixgeelhaar/mcp-go/errors"
+//
+//	srv.Resource("users://{id}").Handler(func(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceContent, error) {
+//	    user, err := db.User(ctx, params["id"])
+//	    if errors.Is(err, sql.ErrNoRows) {
+//	        return nil, mcperrors.NotFound("user %s: %w", params["id"], err)
+//	    }
+//	    ...
+//	})
+//
+// An *Error is also a *protocol.Error for errors.As, so it keeps its code
+// when wrapped and when it passes through middleware. The client receives
+// the message and data but not the wrapped cause; KindOf recovers the kind
+// from the *protocol.Error it returns.
+//
+// Tools that fail in a way the model should see and react to return a
+// server.ToolError instead.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Kind classifies a domain error.
+type Kind int
+
+// Kinds of domain errors and the JSON-RPC codes they are sent with.
+const (
+	// KindUnknown is reported by KindOf for errors that are not domain errors.
+	KindUnknown Kind = iota
+	// KindNotFound: the requested entity does not exist (-32001).
+	KindNotFound
+	// KindPermissionDenied: the caller may not perform the request (-32004).
+	KindPermissionDenied
+	// KindRateLimited: the caller sent too many requests (-32003).
+	KindRateLimited
+	// KindUnavailable: a dependency is temporarily down (-32005).
+	KindUnavailable
+	// KindInvalid: the request arguments are invalid (-32602).
+	KindInvalid
+)
+
+var kindCodes = map[Kind]int{
+	KindNotFound:         protocol.CodeNotFound,
+	KindPermissionDenied: protocol.CodeForbidden,
+	KindRateLimited:      protocol.CodeRateLimited,
+	KindUnavailable:      protocol.CodeUnavailable,
+	KindInvalid:          protocol.CodeInvalidParams,
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindPermissionDenied:
+		return "permission denied"
+	case KindRateLimited:
+		return "rate limited"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// Code returns the JSON-RPC error code the kind is sent with.
+func (k Kind) Code() int {
+	if code, ok := kindCodes[k]; ok {
+		return code
+	}
+	return protocol.CodeInternalError
+}
+
+// Error is a domain error returned by a handler.
+type Error struct {
+	// Kind selects the JSON-RPC error code.
+	Kind Kind
+	// Message is sent to the client.
+	Message string
+	// Data is sent to the client as the error data.
+	Data any
+	// RetryAfter tells clients of rate limited and unavailable requests
+	// when to retry. It is sent as "retryAfter" in seconds in the data.
+	RetryAfter time.Duration
+	// Err is the underlying cause, if any. It is not sent to the client.
+	Err error
+}
+
+// newError creates an error of kind with a formatted message. If the
+// format contains a %w verb, the wrapped error is available through Unwrap.
+func newError(kind Kind, format string, args []any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Kind: kind, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// NotFound creates an error for a missing entity.
+func NotFound(format string, args ...any) *Error {
+	return newError(KindNotFound, format, args)
+}
+
+// PermissionDenied creates an error for a request the caller may not make.
+func PermissionDenied(format string, args ...any) *Error {
+	return newError(KindPermissionDenied, format, args)
+}
+
+// RateLimited creates an error for a caller that exceeded its rate limit.
+func RateLimited(format string, args ...any) *Error {
+	return newError(KindRateLimited, format, args)
+}
+
+// Unavailable creates an error for a temporary outage.
+func Unavailable(format string, args ...any) *Error {
+	return newError(KindUnavailable, format, args)
+}
+
+// Invalid creates an error for invalid request arguments.
+func Invalid(format string, args ...any) *Error {
+	return newError(KindInvalid, format, args)
+}
+
+// WithData returns a copy of the error with data sent to the client.
+func (e *Error) WithData(data any) *Error {
+	c := *e
+	c.Data = data
+	return &c
+}
+
+// WithRetryAfter returns a copy of the error telling clients to retry
+// after d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := *e
+	c.RetryAfter = d
+	return &c
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Err != nil && e.Err.Error() != e.Message {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// As makes errors.As find the error as a *protocol.Error.
+func (e *Error) As(target any) bool {
+	t, ok := target.(**protocol.Error)
+	if !ok {
+		return false
+	}
+	*t = e.ProtocolError()
+	return true
+}
+
+// ProtocolError returns the JSON-RPC error sent to the client. When
+// RetryAfter is set, it is added to Data if Data is nil or a map.
+func (e *Error) ProtocolError() *protocol.Error {
+	data := e.Data
+	if e.RetryAfter > 0 {
+		seconds := int(e.RetryAfter.Round(time.Second) / time.Second)
+		switch d := data.(type) {
+		case nil:
+			data = map[string]any{"retryAfter": seconds}
+		case map[string]any:
+			merged := make(map[string]any, len(d)+1)
+			for k, v := range d {
+				merged[k] = v
+			}
+			merged["retryAfter"] = seconds
+			data = merged
+		}
+	}
+	return &protocol.Error{Code: e.Kind.Code(), Message: e.Message, Data: data}
+}
+
+// KindOf returns the kind of the first domain error in err's tree. For a
+// *protocol.Error, as returned by the client, it returns the kind sent with
+// its code. Other errors are KindUnknown.
+func KindOf(err error) Kind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	var protoErr *protocol.Error
+	if errors.As(err, &protoErr) {
+		for kind, code := range kindCodes {
+			if code == protoErr.Code {
+				return kind
+			}
+		}
+	}
+	return KindUnknown
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *Error
+		wantKind Kind
+		wantCode int
+	}{
+		{"not found", NotFound("user %s", "42"), KindNotFound, protocol.CodeNotFound},
+		{"permission denied", PermissionDenied("read-only"), KindPermissionDenied, protocol.CodeForbidden},
+		{"rate limited", RateLimited("slow down"), KindRateLimited, protocol.CodeRateLimited},
+		{"unavailable", Unavailable("database down"), KindUnavailable, protocol.CodeUnavailable},
+		{"invalid", Invalid("bad id"), KindInvalid, protocol.CodeInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Kind != tt.wantKind {
+				t.Errorf("Kind = %v, want %v", tt.err.Kind, tt.wantKind)
+			}
+			if got := tt.err.ProtocolError().Code; got != tt.wantCode {
+				t.Errorf("code = %d, want %d", got, tt.wantCode)
+			}
+			if got := KindOf(tt.err.ProtocolError()); got != tt.wantKind {
+				t.Errorf("KindOf(protocol error) = %v, want %v", got, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestError_Wrap(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	err := NotFound("user %s: %w", "42", cause)
+
+	if err.Message != "user 42: sql: no rows in result set" {
+		t.Errorf("Message = %q", err.Message)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is(err, cause) = false")
+	}
+
+	wrapped := fmt.Errorf("load profile: %w", err)
+	var protoErr *protocol.Error
+	if !errors.As(wrapped, &protoErr) {
+		t.Fatal("errors.As(*protocol.Error) = false")
+	}
+	if protoErr.Code != protocol.CodeNotFound || protoErr.Message != err.Message {
+		t.Errorf("protocol error = %+v", protoErr)
+	}
+	if KindOf(wrapped) != KindNotFound {
+		t.Errorf("KindOf() = %v, want not found", KindOf(wrapped))
+	}
+	if KindOf(errors.New("boom")) != KindUnknown {
+		t.Error("KindOf(plain error) != KindUnknown")
+	}
+}
+
+func TestError_Data(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want any
+	}{
+		{"none", Invalid("bad"), nil},
+		{"data", Invalid("bad").WithData([]string{"id"}), []string{"id"}},
+		{"retry after", RateLimited("slow down").WithRetryAfter(1500 * time.Millisecond), map[string]any{"retryAfter": 2}},
+		{
+			"retry after merged",
+			Unavailable("down").WithData(map[string]any{"service": "db"}).WithRetryAfter(time.Minute),
+			map[string]any{"service": "db", "retryAfter": 60},
+		},
+		{"retry after with other data", Unavailable("down").WithData("db").WithRetryAfter(time.Minute), "db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.ProtocolError().Data; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Data = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_WithDataCopies(t *testing.T) {
+	base := Invalid("bad")
+	_ = base.WithData("x").WithRetryAfter(time.Second)
+	if base.Data != nil || base.RetryAfter != 0 {
+		t.Errorf("base modified: %+v", base)
+	}
+}
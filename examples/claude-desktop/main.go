@@ -0,0 +1,128 @@
+// Package main is a server ready to register with Claude Desktop. Its
+// summarize tool asks the host's model for a summary through sampling.
+//
+// Build it, register it and restart Claude Desktop:
+//
+//	go build -o summarizer ./examples/claude-desktop
+//	./summarizer -install
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/felixgeelhaar/mcp-go"
+	"github.com/felixgeelhaar/mcp-go/hostconfig"
+)
+
+// serverName is the name the server is registered under in the host.
+const serverName = "summarizer"
+
+// SummarizeInput is the input for the summarize tool.
+type SummarizeInput struct {
+	Text  string `json:"text" jsonschema:"required,description=Text to summarize"`
+	Words int    `json:"words,omitempty" jsonschema:"description=Maximum length of the summary in words"`
+}
+
+func main() {
+	install := flag.Bool("install", false, "register this binary with Claude Desktop and exit")
+	uninstall := flag.Bool("uninstall", false, "remove this binary from Claude Desktop and exit")
+	printConfig := flag.Bool("print-config", false, "print the Claude Desktop config entry and exit")
+	flag.Parse()
+
+	switch {
+	case *install, *printConfig:
+		if err := register(*install); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case *uninstall:
+		path, err := hostconfig.ClaudeDesktop.Uninstall(serverName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %q from %s. Restart Claude Desktop to unload it.\n", serverName, path)
+		return
+	}
+
+	srv := mcp.NewServer(mcp.ServerInfo{
+		Name:    serverName,
+		Version: "1.0.0",
+	})
+
+	srv.Tool("summarize").
+		Description("Summarize text using the model of the host").
+		Handler(func(ctx context.Context, input SummarizeInput) (string, error) {
+			session := mcp.SessionFromContext(ctx)
+			if session == nil || !session.SupportsFeature("sampling") {
+				return "", mcp.NewToolError("this host does not support sampling")
+			}
+
+			words := input.Words
+			if words <= 0 {
+				words = 50
+			}
+
+			result, err := session.CreateMessage(ctx, &mcp.CreateMessageRequest{
+				Messages: []mcp.SamplingMessage{
+					{
+						Role:    mcp.RoleUser,
+						Content: mcp.NewTextContent(fmt.Sprintf("Summarize in at most %d words:\n\n%s", words, input.Text)),
+					},
+				},
+				SystemPrompt: "You write short, factual summaries.",
+				MaxTokens:    words * 2,
+			})
+			if err != nil {
+				return "", fmt.Errorf("sampling failed: %w", err)
+			}
+			return result.Content.Text, nil
+		})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Stdout carries the protocol; diagnostics go to stderr, which Claude
+	// Desktop writes to its log files
+	fmt.Fprintln(os.Stderr, "summarizer ready on stdio")
+
+	if err := mcp.ServeStdio(ctx, srv); err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// register prints the config entry for this binary, or writes it to the
+// Claude Desktop config if install is set.
+func register(install bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	server, err := hostconfig.ForBinary(exe)
+	if err != nil {
+		return err
+	}
+
+	if !install {
+		snippet, err := hostconfig.Snippet(serverName, server)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(snippet)
+		return err
+	}
+
+	path, err := hostconfig.ClaudeDesktop.Install(serverName, server)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Registered %q in %s. Restart Claude Desktop to load it.\n", serverName, path)
+	return nil
+}
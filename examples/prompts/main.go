@@ -104,22 +104,10 @@ Here is the code to review:`, language, focus)
 
 			return &mcp.PromptResult{
 				Description: fmt.Sprintf("Explanation of %s for %s level", concept, level),
-				Messages: []mcp.PromptMessage{
-					{
-						Role: "system",
-						Content: mcp.TextContent{
-							Type: "text",
-							Text: fmt.Sprintf("You are an expert teacher who explains concepts clearly to %s.", levelDesc[level]),
-						},
-					},
-					{
-						Role: "user",
-						Content: mcp.TextContent{
-							Type: "text",
-							Text: fmt.Sprintf("Please explain %s to me.", strings.ToLower(concept)),
-						},
-					},
-				},
+				Messages: mcp.Messages().
+					System(fmt.Sprintf("You are an expert teacher who explains concepts clearly to %s.", levelDesc[level])).
+					User(fmt.Sprintf("Please explain %s to me.", strings.ToLower(concept))).
+					Build(),
 			}, nil
 		})
 
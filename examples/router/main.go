@@ -0,0 +1,78 @@
+// Package main demonstrates mounting an MCP server into an existing
+// net/http application.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go"
+	"github.com/felixgeelhaar/mcp-go/integration"
+)
+
+// GreetInput is the input for the greet tool.
+type GreetInput struct {
+	Name string `json:"name" jsonschema:"required,description=Name to greet"`
+}
+
+// userKey is the context key the application's auth middleware uses.
+type userKey struct{}
+
+// requireUser is the application's own authentication middleware.
+func requireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Header.Get("X-User")
+		if user == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+func main() {
+	srv := mcp.NewServer(mcp.ServerInfo{
+		Name:         "router-example",
+		Version:      "0.1.0",
+		Capabilities: mcp.Capabilities{Tools: true},
+	})
+
+	srv.Tool("greet").
+		Description("Greet someone on behalf of the caller").
+		Handler(func(ctx context.Context, input GreetInput) (string, error) {
+			from := "someone"
+			if identity := mcp.IdentityFromContext(ctx); identity != nil {
+				from = identity.Name
+			}
+			return fmt.Sprintf("Hello, %s! (from %s)", input.Name, from), nil
+		})
+
+	// The application's existing routes
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "my web app")
+	})
+
+	// Mount MCP under /ai behind the application's auth, passing the
+	// authenticated user on as the MCP identity
+	api := http.NewServeMux()
+	integration.Mount(api, "/ai", srv,
+		integration.WithIdentity(func(r *http.Request) *mcp.Identity {
+			user, _ := r.Context().Value(userKey{}).(string)
+			return &mcp.Identity{ID: user, Name: user}
+		}),
+	)
+	mux.Handle("/ai/", requireUser(api))
+
+	server := &http.Server{
+		Addr:              ":8080",
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Println("MCP endpoint at http://localhost:8080/ai/mcp")
+	log.Fatal(server.ListenAndServe())
+}
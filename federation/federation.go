@@ -0,0 +1,473 @@
+// Package federation serves several upstream MCP servers as one.
+//
+// A Hub connects to each upstream with the client package and registers
+// its tools, resources and prompts with a local server. Tool and prompt
+// names, and resource names, are prefixed with the name of the upstream;
+// resource URIs are kept, since they identify the resource. Calls, reads
+// and prompt requests are proxied to the upstream that listed them:
+//
+//	srv := mcp.NewServer(mcp.ServerInfo{Name: "hub", Version: "1.0.0"})
+//	hub := federation.NewHub(srv)
+//	defer hub.Close()
+//
+//	gh, err := client.NewStdioTransport("github-mcp-server", "stdio")
+//	...
+//	if err := hub.Add(ctx, "github", gh); err != nil {
+//	    log.Fatal(err)
+//	}
+//	// github.create_issue, github.search_code, ...
+//	mcp.ServeStdio(ctx, srv)
+//
+// Upstream notifications are forwarded when the transport delivers them
+// (see client.NotificationReceiver): list changes re-sync the upstream,
+// which notifies the clients of the hub in turn, resource updates are
+// passed on with NotifyResourceUpdated, and log messages are broadcast
+// with the upstream name in their logger.
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/felixgeelhaar/mcp-go/client"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Hub registers the features of upstream servers with a server.
+type Hub struct {
+	srv       *server.Server
+	separator string
+	subscribe bool
+	onError   func(name string, err error)
+
+	// opMu serializes changes to the registered features
+	opMu sync.Mutex
+
+	mu        sync.Mutex
+	upstreams map[string]*upstream
+}
+
+// upstream is a server added to a hub.
+type upstream struct {
+	name   string
+	client *client.Client
+	caps   client.Capabilities
+
+	tools     []string
+	resources []string
+	prompts   []string
+}
+
+// Option configures a Hub.
+type Option func(*Hub)
+
+// WithSeparator sets the separator between the upstream name and the
+// names of its features. Defaults to ".".
+func WithSeparator(sep string) Option {
+	return func(h *Hub) {
+		h.separator = sep
+	}
+}
+
+// WithResourceSubscriptions subscribes to every resource of the upstreams,
+// so their updates reach the clients of the hub. Upstreams that do not
+// support subscriptions are skipped.
+func WithResourceSubscriptions() Option {
+	return func(h *Hub) {
+		h.subscribe = true
+	}
+}
+
+// WithErrorHandler sets a function called with the errors of background
+// work, such as re-syncing an upstream after it reported a list change.
+// By default they are dropped.
+func WithErrorHandler(fn func(name string, err error)) Option {
+	return func(h *Hub) {
+		h.onError = fn
+	}
+}
+
+// NewHub creates a hub registering upstream features with srv.
+func NewHub(srv *server.Server, opts ...Option) *Hub {
+	h := &Hub{
+		srv:       srv,
+		separator: ".",
+		upstreams: make(map[string]*upstream),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Add connects to an upstream server over transport and registers its
+// features under name. The hub owns transport from then on and closes it
+// when the upstream is removed. Add fails if the name is taken or a
+// feature conflicts with one already registered with the server.
+func (h *Hub) Add(ctx context.Context, name string, transport client.Transport, opts ...client.Option) error {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	_, exists := h.upstreams[name]
+	h.mu.Unlock()
+	switch {
+	case name == "":
+		_ = transport.Close()
+		return errors.New("federation: empty upstream name")
+	case exists:
+		_ = transport.Close()
+		return fmt.Errorf("federation: upstream %s already added", name)
+	}
+
+	u := &upstream{name: name}
+	opts = append(opts, client.WithNotificationHandler(func(method string, params json.RawMessage) {
+		// Handle off the read loop, which delivers the responses of a re-sync
+		go h.handleNotification(u, method, params)
+	}))
+	u.client = client.New(transport, opts...)
+	info, err := u.client.Initialize(ctx)
+	if err == nil {
+		u.caps = info.Capabilities
+		err = h.sync(ctx, u)
+	}
+	if err != nil {
+		h.unregister(u)
+		_ = u.client.Close()
+		return fmt.Errorf("federation: upstream %s: %w", name, err)
+	}
+
+	h.mu.Lock()
+	h.upstreams[name] = u
+	h.mu.Unlock()
+	return nil
+}
+
+// Remove unregisters the features of the upstream added under name and
+// closes its connection. It reports whether the upstream was added.
+func (h *Hub) Remove(name string) bool {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	u, ok := h.upstreams[name]
+	delete(h.upstreams, name)
+	h.mu.Unlock()
+	if !ok {
+		return false
+	}
+	h.unregister(u)
+	_ = u.client.Close()
+	return true
+}
+
+// Upstreams returns the names of the upstreams, sorted.
+func (h *Hub) Upstreams() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, 0, len(h.upstreams))
+	for name := range h.upstreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close removes every upstream.
+func (h *Hub) Close() error {
+	for _, name := range h.Upstreams() {
+		h.Remove(name)
+	}
+	return nil
+}
+
+// handleNotification forwards a notification from an upstream.
+func (h *Hub) handleNotification(u *upstream, method string, params json.RawMessage) {
+	var err error
+	switch method {
+	case protocol.MethodToolListChanged, protocol.MethodResourceListChanged, protocol.MethodPromptListChanged:
+		h.opMu.Lock()
+		if h.added(u) {
+			ctx := context.Background()
+			switch method {
+			case protocol.MethodToolListChanged:
+				err = h.syncTools(ctx, u)
+			case protocol.MethodResourceListChanged:
+				err = h.syncResources(ctx, u)
+			default:
+				err = h.syncPrompts(ctx, u)
+			}
+		}
+		h.opMu.Unlock()
+	case protocol.MethodResourceUpdated:
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err = json.Unmarshal(params, &p); err == nil && p.URI != "" {
+			h.srv.NotifyResourceUpdated(p.URI)
+		}
+	case protocol.MethodLoggingMessage:
+		var p map[string]any
+		if err = json.Unmarshal(params, &p); err == nil {
+			if logger, _ := p["logger"].(string); logger != "" {
+				p["logger"] = u.name + h.separator + logger
+			} else {
+				p["logger"] = u.name
+			}
+			err = h.srv.Broadcast(method, p)
+		}
+	}
+	if err != nil && h.onError != nil {
+		h.onError(u.name, fmt.Errorf("%s: %w", method, err))
+	}
+}
+
+// added reports whether u is still added to the hub.
+func (h *Hub) added(u *upstream) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.upstreams[u.name] == u
+}
+
+// sync registers every feature of u. The caller must hold h.opMu.
+func (h *Hub) sync(ctx context.Context, u *upstream) error {
+	if err := h.syncTools(ctx, u); err != nil {
+		return err
+	}
+	if err := h.syncResources(ctx, u); err != nil {
+		return err
+	}
+	return h.syncPrompts(ctx, u)
+}
+
+// syncTools registers the tools of u and unregisters those it no longer
+// lists. The caller must hold h.opMu.
+func (h *Hub) syncTools(ctx context.Context, u *upstream) error {
+	if !u.caps.Tools {
+		return nil
+	}
+	tools, err := u.client.ListTools(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = u.name + h.separator + t.Name
+		if _, ok := h.srv.GetTool(names[i]); ok && !slices.Contains(u.tools, names[i]) {
+			return fmt.Errorf("tool %s is already registered", names[i])
+		}
+	}
+	for i, t := range tools {
+		inputSchema := t.InputSchema
+		if inputSchema == nil {
+			inputSchema = map[string]any{"type": "object"}
+		}
+		name := t.Name
+		b := h.srv.Tool(names[i]).
+			Description(t.Description).
+			InputSchema(inputSchema).
+			Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+				return callTool(ctx, u.client, name, args)
+			})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("register tool %s: %w", names[i], err)
+		}
+	}
+	for _, name := range u.tools {
+		if !slices.Contains(names, name) {
+			h.srv.RemoveTool(name)
+		}
+	}
+	u.tools = names
+	return nil
+}
+
+// syncResources registers the resources and resource templates of u and
+// unregisters those it no longer lists. The caller must hold h.opMu.
+func (h *Hub) syncResources(ctx context.Context, u *upstream) error {
+	if !u.caps.Resources {
+		return nil
+	}
+	resources, err := u.client.ListResources(ctx)
+	if err != nil {
+		return err
+	}
+	templates, err := u.client.ListResourceTemplates(ctx)
+	if err != nil {
+		var rpcErr *protocol.Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != protocol.CodeMethodNotFound {
+			return err
+		}
+		templates = nil
+	}
+
+	type entry struct{ uri, name, description, mimeType string }
+	entries := make([]entry, 0, len(resources)+len(templates))
+	for _, r := range resources {
+		entries = append(entries, entry{r.URI, r.Name, r.Description, r.MimeType})
+	}
+	for _, t := range templates {
+		entries = append(entries, entry{t.URITemplate, t.Name, t.Description, t.MimeType})
+	}
+
+	uris := make([]string, len(entries))
+	for i, e := range entries {
+		uris[i] = e.uri
+		if _, ok := h.srv.GetResource(e.uri); ok && !slices.Contains(u.resources, e.uri) {
+			return fmt.Errorf("resource %s is already registered", e.uri)
+		}
+	}
+	for _, e := range entries {
+		b := h.srv.Resource(e.uri).
+			Name(u.name + h.separator + e.name).
+			Description(e.description).
+			MimeType(e.mimeType).
+			Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+				return readResource(ctx, u.client, uri)
+			})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("register resource %s: %w", e.uri, err)
+		}
+	}
+	for _, uri := range u.resources {
+		if !slices.Contains(uris, uri) {
+			h.srv.RemoveResource(uri)
+			if h.subscribe && !strings.Contains(uri, "{") {
+				_ = u.client.Unsubscribe(ctx, uri)
+			}
+		}
+	}
+	if h.subscribe {
+		for _, r := range resources {
+			if !slices.Contains(u.resources, r.URI) {
+				// Upstreams without subscriptions reject the request
+				_ = u.client.Subscribe(ctx, r.URI)
+			}
+		}
+	}
+	u.resources = uris
+	return nil
+}
+
+// syncPrompts registers the prompts of u and unregisters those it no
+// longer lists. The caller must hold h.opMu.
+func (h *Hub) syncPrompts(ctx context.Context, u *upstream) error {
+	if !u.caps.Prompts {
+		return nil
+	}
+	prompts, err := u.client.ListPrompts(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(prompts))
+	for i, p := range prompts {
+		names[i] = u.name + h.separator + p.Name
+		if _, ok := h.srv.GetPrompt(names[i]); ok && !slices.Contains(u.prompts, names[i]) {
+			return fmt.Errorf("prompt %s is already registered", names[i])
+		}
+	}
+	for i, p := range prompts {
+		name := p.Name
+		b := h.srv.Prompt(names[i]).Description(p.Description)
+		for _, arg := range p.Arguments {
+			b.Argument(arg.Name, arg.Description, arg.Required)
+		}
+		b.Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+			return getPrompt(ctx, u.client, name, args)
+		})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("register prompt %s: %w", names[i], err)
+		}
+	}
+	for _, name := range u.prompts {
+		if !slices.Contains(names, name) {
+			h.srv.RemovePrompt(name)
+		}
+	}
+	u.prompts = names
+	return nil
+}
+
+// unregister removes the features of u from the server. The caller must
+// hold h.opMu.
+func (h *Hub) unregister(u *upstream) {
+	for _, name := range u.tools {
+		h.srv.RemoveTool(name)
+	}
+	for _, uri := range u.resources {
+		h.srv.RemoveResource(uri)
+	}
+	for _, name := range u.prompts {
+		h.srv.RemovePrompt(name)
+	}
+	u.tools, u.resources, u.prompts = nil, nil, nil
+}
+
+// callTool proxies a tool call.
+func callTool(ctx context.Context, c *client.Client, name string, args json.RawMessage) (*server.ToolResult, error) {
+	var arguments any
+	if len(args) > 0 {
+		arguments = args
+	}
+	res, err := c.CallTool(ctx, name, arguments)
+	if err != nil {
+		return nil, err
+	}
+	call := res.CallToolResult()
+	result := server.NewToolResult().Append(call.Content...)
+	if call.StructuredContent != nil {
+		result.Structured(call.StructuredContent)
+	}
+	if call.IsError {
+		result.WithError()
+	}
+	for k, v := range call.Meta {
+		result.WithMeta(k, v)
+	}
+	return result, nil
+}
+
+// readResource proxies a resource read.
+func readResource(ctx context.Context, c *client.Client, uri string) (*server.ResourceContent, error) {
+	content, err := c.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range content.Meta {
+		server.WithResultMeta(ctx, k, v)
+	}
+	return &server.ResourceContent{
+		URI:      content.URI,
+		MimeType: content.MimeType,
+		Text:     content.Text,
+		Blob:     content.Blob,
+	}, nil
+}
+
+// getPrompt proxies a prompt request.
+func getPrompt(ctx context.Context, c *client.Client, name string, args map[string]string) (*server.PromptResult, error) {
+	res, err := c.GetPrompt(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range res.Meta {
+		server.WithResultMeta(ctx, k, v)
+	}
+	result := &server.PromptResult{
+		Description: res.Description,
+		Messages:    make([]server.PromptMessage, len(res.Messages)),
+	}
+	for i, m := range res.Messages {
+		result.Messages[i] = server.PromptMessage{Role: m.Role, Content: m.Content}
+	}
+	return result, nil
+}
@@ -0,0 +1,267 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/server/handler"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+// memTransport is a client transport serving requests with an in-process
+// server.
+type memTransport struct {
+	h *handler.Handler
+
+	mu             sync.Mutex
+	onNotification func(n *protocol.Request)
+}
+
+func newMemTransport(srv *server.Server) *memTransport {
+	return &memTransport{h: handler.New(srv)}
+}
+
+func (t *memTransport) context(ctx context.Context) context.Context {
+	ctx = transport.ContextWithConnectionID(ctx, "upstream")
+	ctx = transport.ContextWithNotificationSender(ctx, t)
+	ctx = transport.ContextWithRequestSender(ctx, t)
+	return ctx
+}
+
+// SendRequest fails: the client does not serve requests from the server.
+func (t *memTransport) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return nil, protocol.NewMethodNotFound(req.Method)
+}
+
+func (t *memTransport) Send(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	resp, err := t.h.HandleRequest(t.context(ctx), req)
+	if err != nil {
+		var rpcErr *protocol.Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = protocol.NewInternalError(err.Error())
+		}
+		return &protocol.Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}, nil
+	}
+	// Round-trip through JSON like a network transport
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	var decoded protocol.Response
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+func (t *memTransport) Notify(ctx context.Context, req *protocol.Request) error {
+	_, err := t.h.HandleRequest(t.context(ctx), req)
+	return err
+}
+
+func (t *memTransport) OnNotification(fn func(n *protocol.Request)) {
+	t.mu.Lock()
+	t.onNotification = fn
+	t.mu.Unlock()
+}
+
+func (t *memTransport) SendNotification(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	fn := t.onNotification
+	t.mu.Unlock()
+	if fn != nil {
+		fn(&protocol.Request{JSONRPC: "2.0", Method: method, Params: data})
+	}
+	return nil
+}
+
+func (t *memTransport) Close() error {
+	t.h.ConnectionClosed("upstream")
+	return nil
+}
+
+// newUpstream creates a server with a tool, a resource, a resource
+// template and a prompt, each tagged with name.
+func newUpstream(name string) *server.Server {
+	srv := server.New(server.Info{Name: name, Version: "1.0.0"})
+	srv.Tool("echo").Description("Echo from " + name).Handler(func(in struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return name + ": " + in.Text, nil
+	})
+	srv.Resource(name + "://readme").Name("readme").MimeType("text/plain").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri, MimeType: "text/plain", Text: "readme of " + name}, nil
+		})
+	srv.Resource(name + "://files/{path}").Name("files").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri, Text: "file " + params["path"]}, nil
+		})
+	srv.Prompt("review").Description("Review").Argument("code", "Code to review", true).
+		Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+			return &server.PromptResult{Messages: []server.PromptMessage{
+				{Role: "user", Content: map[string]any{"type": "text", "text": name + " reviews " + args["code"]}},
+			}}, nil
+		})
+	return srv
+}
+
+func toolNames(srv *server.Server) []string {
+	var names []string
+	for _, t := range srv.Tools() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestHub(t *testing.T) {
+	hubSrv := server.New(server.Info{Name: "hub", Version: "1.0.0"})
+	hub := NewHub(hubSrv)
+	ctx := context.Background()
+
+	for _, name := range []string{"alpha", "beta"} {
+		if err := hub.Add(ctx, name, newMemTransport(newUpstream(name))); err != nil {
+			t.Fatalf("Add(%s) error = %v", name, err)
+		}
+	}
+	if got, want := hub.Upstreams(), []string{"alpha", "beta"}; !slices.Equal(got, want) {
+		t.Errorf("Upstreams() = %v, want %v", got, want)
+	}
+	if got, want := toolNames(hubSrv), []string{"alpha.echo", "beta.echo"}; !slices.Equal(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+
+	t.Run("tools", func(t *testing.T) {
+		tool, _ := hubSrv.GetTool("beta.echo")
+		res, err := tool.Execute(ctx, json.RawMessage(`{"text":"hi"}`))
+		if err != nil {
+			t.Fatalf("call error = %v", err)
+		}
+		content := res.(*server.ToolResult).Content()
+		if len(content) != 1 || content[0].Text != "beta: hi" {
+			t.Errorf("content = %+v, want beta: hi", content)
+		}
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		content, err := hubSrv.ReadResource(ctx, "alpha://readme")
+		if err != nil || content.Text != "readme of alpha" {
+			t.Errorf("read readme = %+v, %v", content, err)
+		}
+		content, err = hubSrv.ReadResource(ctx, "beta://files/a.txt")
+		if err != nil || content.Text != "file a.txt" {
+			t.Errorf("read template = %+v, %v", content, err)
+		}
+		var names []string
+		for _, r := range hubSrv.Resources() {
+			names = append(names, r.Name)
+		}
+		slices.Sort(names)
+		if want := []string{"alpha.files", "alpha.readme", "beta.files", "beta.readme"}; !slices.Equal(names, want) {
+			t.Errorf("resource names = %v, want %v", names, want)
+		}
+	})
+
+	t.Run("prompts", func(t *testing.T) {
+		prompt, ok := hubSrv.GetPrompt("alpha.review")
+		if !ok {
+			t.Fatal("alpha.review not registered")
+		}
+		res, err := prompt.Get(ctx, map[string]string{"code": "x := 1"})
+		if err != nil {
+			t.Fatalf("get error = %v", err)
+		}
+		msg, _ := res.Messages[0].Content.(map[string]any)
+		if msg["text"] != "alpha reviews x := 1" {
+			t.Errorf("message = %v", res.Messages[0])
+		}
+		if args := hubSrv.Prompts()[0].Arguments; len(args) != 1 || !args[0].Required {
+			t.Errorf("arguments = %+v, want required code", args)
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		err := hub.Add(ctx, "alpha", newMemTransport(newUpstream("gamma")))
+		if err == nil || !strings.Contains(err.Error(), "already added") {
+			t.Errorf("duplicate name error = %v", err)
+		}
+		err = hub.Add(ctx, "other", newMemTransport(newUpstream("alpha")))
+		if err == nil || !strings.Contains(err.Error(), "resource alpha://") {
+			t.Errorf("duplicate resource error = %v", err)
+		}
+		if got := toolNames(hubSrv); !slices.Equal(got, []string{"alpha.echo", "beta.echo"}) {
+			t.Errorf("tools after failed Add = %v", got)
+		}
+	})
+
+	if !hub.Remove("alpha") || hub.Remove("alpha") {
+		t.Error("Remove() did not report the upstream once")
+	}
+	if got := toolNames(hubSrv); !slices.Equal(got, []string{"beta.echo"}) {
+		t.Errorf("tools after Remove = %v", got)
+	}
+	if _, ok := hubSrv.GetResource("alpha://readme"); ok {
+		t.Error("resource of removed upstream still registered")
+	}
+	if err := hub.Close(); err != nil || len(hubSrv.Tools()) != 0 || len(hubSrv.Prompts()) != 0 {
+		t.Errorf("Close() = %v, left %v", err, toolNames(hubSrv))
+	}
+}
+
+func TestHub_Notifications(t *testing.T) {
+	hubSrv := server.New(server.Info{Name: "hub", Version: "1.0.0"})
+	var errs []error
+	var mu sync.Mutex
+	hub := NewHub(hubSrv, WithSeparator("__"), WithResourceSubscriptions(), WithErrorHandler(func(name string, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}))
+	defer hub.Close()
+	ctx := context.Background()
+
+	up := newUpstream("alpha")
+	if err := hub.Add(ctx, "alpha", newMemTransport(up)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	updated := make(chan string, 1)
+	hubSrv.OnResourceUpdated(func(uri string) { updated <- uri })
+	up.NotifyResourceUpdated("alpha://readme")
+	select {
+	case uri := <-updated:
+		if uri != "alpha://readme" {
+			t.Errorf("updated uri = %q", uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("resource update not forwarded")
+	}
+
+	up.Tool("sum").Handler(func(in struct{}) (int, error) { return 3, nil })
+	up.RemoveTool("echo")
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Equal(toolNames(hubSrv), []string{"alpha__sum"}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("tools = %v, want alpha__sum after list change", toolNames(hubSrv))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 0 {
+		t.Errorf("background errors = %v", errs)
+	}
+}
@@ -0,0 +1,406 @@
+package grpctools
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// This file decodes the parts of descriptor.proto that tools are generated
+// from, so descriptor sets can be read without depending on the protobuf
+// runtime.
+
+// Field numbers of descriptor.proto.
+const (
+	fileSetFile = 1
+
+	filePackage     = 2
+	fileMessageType = 4
+	fileEnumType    = 5
+	fileService     = 6
+	fileSourceInfo  = 9
+
+	messageName       = 1
+	messageField      = 2
+	messageNestedType = 3
+	messageEnumType   = 4
+	messageOptions    = 7
+	messageMapEntry   = 7
+
+	fieldName     = 1
+	fieldLabel    = 4
+	fieldType     = 5
+	fieldTypeName = 6
+	fieldJSONName = 10
+
+	enumName  = 1
+	enumValue = 2
+
+	enumValueName = 1
+
+	serviceName   = 1
+	serviceMethod = 2
+
+	methodName            = 1
+	methodInputType       = 2
+	methodOutputType      = 3
+	methodOptions         = 4
+	methodClientStreaming = 5
+	methodServerStreaming = 6
+	methodDeprecated      = 33
+	methodIdempotency     = 34
+
+	sourceInfoLocation      = 1
+	locationPath            = 1
+	locationLeadingComments = 3
+)
+
+// Field labels and types of FieldDescriptorProto.
+const (
+	labelRequired = 2
+	labelRepeated = 3
+
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+)
+
+// Idempotency levels of MethodOptions.
+const (
+	noSideEffects = 1
+	idempotent    = 2
+)
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+type fileDesc struct {
+	pkg      string
+	messages []*messageDesc
+	enums    []*enumDesc
+	services []*serviceDesc
+	comments map[string]string
+}
+
+type messageDesc struct {
+	name     string
+	path     []int
+	fields   []*fieldDesc
+	nested   []*messageDesc
+	enums    []*enumDesc
+	mapEntry bool
+}
+
+type fieldDesc struct {
+	name     string
+	jsonName string
+	path     []int
+	label    int
+	typ      int
+	typeName string
+}
+
+type enumDesc struct {
+	name   string
+	values []string
+}
+
+type serviceDesc struct {
+	name    string
+	path    []int
+	methods []*methodDesc
+}
+
+type methodDesc struct {
+	name            string
+	path            []int
+	inputType       string
+	outputType      string
+	clientStreaming bool
+	serverStreaming bool
+	deprecated      bool
+	idempotency     int
+}
+
+// parseFile decodes a serialized FileDescriptorProto.
+func parseFile(b []byte) (*fileDesc, error) {
+	f := &fileDesc{comments: map[string]string{}}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case filePackage:
+			f.pkg = string(data)
+		case fileMessageType:
+			m, err := parseMessage(data, []int{fileMessageType, len(f.messages)})
+			if err != nil {
+				return err
+			}
+			f.messages = append(f.messages, m)
+		case fileEnumType:
+			e, err := parseEnum(data)
+			if err != nil {
+				return err
+			}
+			f.enums = append(f.enums, e)
+		case fileService:
+			s, err := parseService(data, []int{fileService, len(f.services)})
+			if err != nil {
+				return err
+			}
+			f.services = append(f.services, s)
+		case fileSourceInfo:
+			return parseSourceInfo(data, f.comments)
+		}
+		return nil
+	})
+	return f, err
+}
+
+func parseMessage(b []byte, path []int) (*messageDesc, error) {
+	m := &messageDesc{path: path}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case messageName:
+			m.name = string(data)
+		case messageField:
+			f, err := parseField(data, appendPath(path, messageField, len(m.fields)))
+			if err != nil {
+				return err
+			}
+			m.fields = append(m.fields, f)
+		case messageNestedType:
+			n, err := parseMessage(data, appendPath(path, messageNestedType, len(m.nested)))
+			if err != nil {
+				return err
+			}
+			m.nested = append(m.nested, n)
+		case messageEnumType:
+			e, err := parseEnum(data)
+			if err != nil {
+				return err
+			}
+			m.enums = append(m.enums, e)
+		case messageOptions:
+			return walk(data, func(num, typ int, v uint64, data []byte) error {
+				if num == messageMapEntry {
+					m.mapEntry = v != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return m, err
+}
+
+func parseField(b []byte, path []int) (*fieldDesc, error) {
+	f := &fieldDesc{path: path}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case fieldName:
+			f.name = string(data)
+		case fieldLabel:
+			f.label = int(v)
+		case fieldType:
+			f.typ = int(v)
+		case fieldTypeName:
+			f.typeName = string(data)
+		case fieldJSONName:
+			f.jsonName = string(data)
+		}
+		return nil
+	})
+	return f, err
+}
+
+func parseEnum(b []byte) (*enumDesc, error) {
+	e := &enumDesc{}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case enumName:
+			e.name = string(data)
+		case enumValue:
+			return walk(data, func(num, typ int, v uint64, data []byte) error {
+				if num == enumValueName {
+					e.values = append(e.values, string(data))
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return e, err
+}
+
+func parseService(b []byte, path []int) (*serviceDesc, error) {
+	s := &serviceDesc{path: path}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case serviceName:
+			s.name = string(data)
+		case serviceMethod:
+			m, err := parseMethod(data, appendPath(path, serviceMethod, len(s.methods)))
+			if err != nil {
+				return err
+			}
+			s.methods = append(s.methods, m)
+		}
+		return nil
+	})
+	return s, err
+}
+
+func parseMethod(b []byte, path []int) (*methodDesc, error) {
+	m := &methodDesc{path: path}
+	err := walk(b, func(num, typ int, v uint64, data []byte) error {
+		switch num {
+		case methodName:
+			m.name = string(data)
+		case methodInputType:
+			m.inputType = string(data)
+		case methodOutputType:
+			m.outputType = string(data)
+		case methodClientStreaming:
+			m.clientStreaming = v != 0
+		case methodServerStreaming:
+			m.serverStreaming = v != 0
+		case methodOptions:
+			return walk(data, func(num, typ int, v uint64, data []byte) error {
+				switch num {
+				case methodDeprecated:
+					m.deprecated = v != 0
+				case methodIdempotency:
+					m.idempotency = int(v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return m, err
+}
+
+// parseSourceInfo collects the leading comments of SourceCodeInfo by
+// location path.
+func parseSourceInfo(b []byte, comments map[string]string) error {
+	return walk(b, func(num, typ int, v uint64, data []byte) error {
+		if num != sourceInfoLocation {
+			return nil
+		}
+		var path []int
+		var comment string
+		err := walk(data, func(num, typ int, v uint64, data []byte) error {
+			switch {
+			case num == locationPath && typ == wireVarint:
+				path = append(path, int(v))
+			case num == locationPath && typ == wireBytes:
+				// Packed encoding
+				for len(data) > 0 {
+					n, size := uvarint(data)
+					if size <= 0 {
+						return errors.New("invalid packed path")
+					}
+					path = append(path, int(n))
+					data = data[size:]
+				}
+			case num == locationLeadingComments:
+				comment = strings.TrimSpace(string(data))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if comment != "" {
+			comments[pathKey(path)] = comment
+		}
+		return nil
+	})
+}
+
+// walk calls fn for each field of an encoded message, with the value of
+// varint fields and the contents of length-delimited fields.
+func walk(b []byte, fn func(num, typ int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := uvarint(b)
+		if n <= 0 {
+			return errors.New("grpctools: invalid descriptor: bad tag")
+		}
+		b = b[n:]
+		num, typ := int(tag>>3), int(tag&7)
+
+		var v uint64
+		var data []byte
+		switch typ {
+		case wireVarint:
+			v, n = uvarint(b)
+			if n <= 0 {
+				return errors.New("grpctools: invalid descriptor: bad varint")
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errors.New("grpctools: invalid descriptor: truncated fixed64")
+			}
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errors.New("grpctools: invalid descriptor: truncated fixed32")
+			}
+			b = b[4:]
+		case wireBytes:
+			size, n := uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return errors.New("grpctools: invalid descriptor: truncated field")
+			}
+			data = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			return fmt.Errorf("grpctools: invalid descriptor: unsupported wire type %d", typ)
+		}
+		if err := fn(num, typ, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uvarint decodes a varint, returning the value and the number of bytes
+// read, or 0 bytes if b is not a valid varint.
+func uvarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func appendPath(path []int, elems ...int) []int {
+	return append(append([]int(nil), path...), elems...)
+}
+
+func pathKey(path []int) string {
+	return fmt.Sprint(path)
+}
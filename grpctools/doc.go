@@ -0,0 +1,61 @@
+// Package grpctools exposes the unary methods of gRPC services as MCP
+// tools, so internal services become available to models without writing
+// a tool per method.
+//
+// Services are read from protobuf descriptors: a descriptor set written by
+// protoc, or the file descriptors returned by the server reflection
+// service. Each unary method becomes a tool whose input schema follows the
+// protobuf JSON encoding of its request message, and whose handler calls
+// the method through an Invoker and returns the JSON response.
+//
+// # Registering Tools
+//
+//	// protoc --include_imports --include_source_info \
+//	//     --descriptor_set_out=greeter.pb greeter.proto
+//	data, err := os.ReadFile("greeter.pb")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	services, err := grpctools.ParseDescriptorSet(data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = grpctools.Register(srv, invoker, services, grpctools.WithTimeout(10*time.Second))
+//
+// Tools are named after the service and method, such as Greeter_SayHello,
+// and described by the method comments. Methods with the NO_SIDE_EFFECTS
+// idempotency level are marked read-only, IDEMPOTENT methods idempotent,
+// and deprecated methods are registered as deprecated tools.
+//
+// # Invokers
+//
+// The package does not depend on gRPC or the protobuf runtime. An Invoker
+// calls methods with JSON messages, which takes a few lines with
+// google.golang.org/grpc and the dynamicpb and protojson packages:
+//
+//	type invoker struct {
+//	    conn  *grpc.ClientConn
+//	    files *protoregistry.Files
+//	}
+//
+//	func (i invoker) Invoke(ctx context.Context, method string, in json.RawMessage) (json.RawMessage, error) {
+//	    name := strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")
+//	    d, err := i.files.FindDescriptorByName(protoreflect.FullName(name))
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    md := d.(protoreflect.MethodDescriptor)
+//	    req := dynamicpb.NewMessage(md.Input())
+//	    if err := protojson.Unmarshal(in, req); err != nil {
+//	        return nil, err
+//	    }
+//	    resp := dynamicpb.NewMessage(md.Output())
+//	    if err := i.conn.Invoke(ctx, method, req, resp); err != nil {
+//	        return nil, err
+//	    }
+//	    return protojson.Marshal(resp)
+//	}
+//
+// Errors returned by the invoker, such as gRPC status errors, become tool
+// errors the model can read and recover from.
+package grpctools
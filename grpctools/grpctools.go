@@ -0,0 +1,536 @@
+package grpctools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Kind is the type of a message field.
+type Kind string
+
+// Field kinds. Integer types of every encoding map to the kind of the same
+// size and sign, such as sint32 and sfixed32 to KindInt32.
+const (
+	KindDouble  Kind = "double"
+	KindFloat   Kind = "float"
+	KindInt32   Kind = "int32"
+	KindInt64   Kind = "int64"
+	KindUint32  Kind = "uint32"
+	KindUint64  Kind = "uint64"
+	KindBool    Kind = "bool"
+	KindString  Kind = "string"
+	KindBytes   Kind = "bytes"
+	KindEnum    Kind = "enum"
+	KindMessage Kind = "message"
+)
+
+// Service is a gRPC service.
+type Service struct {
+	// Name is the fully qualified name, such as "greeter.v1.Greeter".
+	Name        string
+	Description string
+	Methods     []Method
+}
+
+// Method is a method of a service.
+type Method struct {
+	Name        string
+	Description string
+	Input       *Message
+	Output      *Message
+
+	ClientStreaming bool
+	ServerStreaming bool
+
+	// Deprecated is set by the deprecated method option.
+	Deprecated bool
+	// ReadOnly and Idempotent are set by the idempotency_level method
+	// option, NO_SIDE_EFFECTS and IDEMPOTENT respectively.
+	ReadOnly   bool
+	Idempotent bool
+}
+
+// Message is a message type. Messages may refer to themselves through
+// their fields.
+type Message struct {
+	// Name is the fully qualified name, such as "greeter.v1.HelloRequest".
+	Name        string
+	Description string
+	Fields      []Field
+	// MapEntry is set for the entries of map fields, which have a key
+	// and a value field.
+	MapEntry bool
+}
+
+// Field is a field of a message.
+type Field struct {
+	// Name is the JSON name, such as "userId" for user_id.
+	Name        string
+	Description string
+	Kind        Kind
+	Repeated    bool
+	// Required is set for proto2 required fields.
+	Required bool
+	// Message is the type of message fields.
+	Message *Message
+	// Enum lists the value names of enum fields.
+	Enum []string
+}
+
+// Unary reports whether neither side of the method streams.
+func (m Method) Unary() bool {
+	return !m.ClientStreaming && !m.ServerStreaming
+}
+
+// ParseDescriptorSet reads the services of a serialized FileDescriptorSet,
+// as written by protoc --descriptor_set_out. Include imports with
+// --include_imports so message types of other files resolve, and
+// comments with --include_source_info to describe the tools.
+func ParseDescriptorSet(data []byte) ([]Service, error) {
+	var files [][]byte
+	err := walk(data, func(num, typ int, v uint64, data []byte) error {
+		if num == fileSetFile {
+			files = append(files, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseFiles(files...)
+}
+
+// ParseFiles reads the services of serialized FileDescriptorProtos, such
+// as those returned by the gRPC server reflection service. files must
+// include the files defining the message types the services use.
+func ParseFiles(files ...[]byte) ([]Service, error) {
+	var descs []*fileDesc
+	for _, data := range files {
+		f, err := parseFile(data)
+		if err != nil {
+			return nil, err
+		}
+		descs = append(descs, f)
+	}
+	return newResolver(descs).services()
+}
+
+// resolver builds the messages of a set of files from their descriptors.
+type resolver struct {
+	messages map[string]*messageDesc
+	enums    map[string]*enumDesc
+	comments map[string]map[string]string
+	built    map[string]*Message
+	files    []*fileDesc
+}
+
+func newResolver(files []*fileDesc) *resolver {
+	r := &resolver{
+		messages: map[string]*messageDesc{},
+		enums:    map[string]*enumDesc{},
+		comments: map[string]map[string]string{},
+		built:    map[string]*Message{},
+		files:    files,
+	}
+	for _, f := range files {
+		r.index(f, qualify(f.pkg), f.messages, f.enums)
+	}
+	return r
+}
+
+// qualify returns the prefix of type names in a package, as used in
+// descriptor type references: ".pkg." or ".".
+func qualify(pkg string) string {
+	if pkg == "" {
+		return "."
+	}
+	return "." + pkg + "."
+}
+
+func (r *resolver) index(f *fileDesc, prefix string, messages []*messageDesc, enums []*enumDesc) {
+	for _, e := range enums {
+		r.enums[prefix+e.name] = e
+	}
+	for _, m := range messages {
+		name := prefix + m.name
+		r.messages[name] = m
+		r.comments[name] = f.comments
+		r.index(f, name+".", m.nested, m.enums)
+	}
+}
+
+func (r *resolver) services() ([]Service, error) {
+	var services []Service
+	for _, f := range r.files {
+		for _, sd := range f.services {
+			svc := Service{
+				Name:        strings.TrimPrefix(qualify(f.pkg)+sd.name, "."),
+				Description: f.comments[pathKey(sd.path)],
+			}
+			for _, md := range sd.methods {
+				input, err := r.message(md.inputType)
+				if err != nil {
+					return nil, fmt.Errorf("grpctools: %s.%s: %w", svc.Name, md.name, err)
+				}
+				output, err := r.message(md.outputType)
+				if err != nil {
+					return nil, fmt.Errorf("grpctools: %s.%s: %w", svc.Name, md.name, err)
+				}
+				svc.Methods = append(svc.Methods, Method{
+					Name:            md.name,
+					Description:     f.comments[pathKey(md.path)],
+					Input:           input,
+					Output:          output,
+					ClientStreaming: md.clientStreaming,
+					ServerStreaming: md.serverStreaming,
+					Deprecated:      md.deprecated,
+					ReadOnly:        md.idempotency == noSideEffects,
+					Idempotent:      md.idempotency == noSideEffects || md.idempotency == idempotent,
+				})
+			}
+			services = append(services, svc)
+		}
+	}
+	return services, nil
+}
+
+// message returns the message of a type reference such as ".pkg.Msg".
+// Well-known types resolve without their descriptors.
+func (r *resolver) message(ref string) (*Message, error) {
+	if m, ok := r.built[ref]; ok {
+		return m, nil
+	}
+	name := strings.TrimPrefix(ref, ".")
+	md, ok := r.messages[ref]
+	if !ok {
+		if strings.HasPrefix(name, "google.protobuf.") {
+			m := &Message{Name: name}
+			r.built[ref] = m
+			return m, nil
+		}
+		return nil, fmt.Errorf("unresolved message type %s", name)
+	}
+
+	// Register the message before its fields, which may refer to it
+	m := &Message{Name: name, MapEntry: md.mapEntry}
+	r.built[ref] = m
+	comments := r.comments[ref]
+	m.Description = comments[pathKey(md.path)]
+
+	for _, fd := range md.fields {
+		f := Field{
+			Name:        fd.jsonName,
+			Description: comments[pathKey(fd.path)],
+			Kind:        fieldKind(fd.typ),
+			Repeated:    fd.label == labelRepeated,
+			Required:    fd.label == labelRequired,
+		}
+		if f.Name == "" {
+			f.Name = jsonName(fd.name)
+		}
+		switch f.Kind {
+		case "":
+			return nil, fmt.Errorf("field %s.%s: unsupported type %d", name, fd.name, fd.typ)
+		case KindMessage:
+			fm, err := r.message(fd.typeName)
+			if err != nil {
+				return nil, err
+			}
+			f.Message = fm
+		case KindEnum:
+			// Enums of files outside the set accept any name
+			if e, ok := r.enums[fd.typeName]; ok {
+				f.Enum = e.values
+			}
+		}
+		m.Fields = append(m.Fields, f)
+	}
+	return m, nil
+}
+
+func fieldKind(typ int) Kind {
+	switch typ {
+	case typeDouble:
+		return KindDouble
+	case typeFloat:
+		return KindFloat
+	case typeInt32, typeSint32, typeSfixed32:
+		return KindInt32
+	case typeInt64, typeSint64, typeSfixed64:
+		return KindInt64
+	case typeUint32, typeFixed32:
+		return KindUint32
+	case typeUint64, typeFixed64:
+		return KindUint64
+	case typeBool:
+		return KindBool
+	case typeString:
+		return KindString
+	case typeBytes:
+		return KindBytes
+	case typeEnum:
+		return KindEnum
+	case typeMessage, typeGroup:
+		return KindMessage
+	}
+	return ""
+}
+
+// jsonName returns the JSON name protoc derives from a field name:
+// user_id becomes userId.
+func jsonName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper && r >= 'a' && r <= 'z':
+			sb.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			sb.WriteRune(r)
+			upper = false
+		}
+	}
+	return sb.String()
+}
+
+// Invoker calls a unary gRPC method. method is the full method name, such
+// as "/greeter.v1.Greeter/SayHello", and the request and response are
+// messages in their protobuf JSON encoding. Errors, such as gRPC status
+// errors, are returned to the model as tool errors.
+type Invoker interface {
+	Invoke(ctx context.Context, method string, request json.RawMessage) (json.RawMessage, error)
+}
+
+// InvokerFunc adapts a function to an Invoker.
+type InvokerFunc func(ctx context.Context, method string, request json.RawMessage) (json.RawMessage, error)
+
+// Invoke calls f.
+func (f InvokerFunc) Invoke(ctx context.Context, method string, request json.RawMessage) (json.RawMessage, error) {
+	return f(ctx, method, request)
+}
+
+// Option configures Register.
+type Option func(*config)
+
+type config struct {
+	prefix  string
+	filter  func(service string, method Method) bool
+	timeout time.Duration
+}
+
+// WithPrefix prefixes the name of every tool, such as "billing_".
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithFilter registers only the methods for which fn returns true. service
+// is the fully qualified service name.
+func WithFilter(fn func(service string, method Method) bool) Option {
+	return func(c *config) {
+		c.filter = fn
+	}
+}
+
+// WithTimeout sets a deadline on every call. By default calls only end
+// with the tool call context.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// Register registers a tool for every unary method of services on srv,
+// calling the methods through inv. Tools are named after the service and
+// method, such as Greeter_SayHello, and described by the method comments,
+// if any. Streaming methods are skipped. It returns an error if no method
+// is registered or a tool cannot be registered.
+func Register(srv *server.Server, inv Invoker, services []Service, opts ...Option) error {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	registered := 0
+	for _, svc := range services {
+		short := svc.Name[strings.LastIndex(svc.Name, ".")+1:]
+		for _, m := range svc.Methods {
+			if !m.Unary() || (cfg.filter != nil && !cfg.filter(svc.Name, m)) {
+				continue
+			}
+
+			description := m.Description
+			if description == "" {
+				description = "Calls " + svc.Name + "." + m.Name
+			}
+			b := srv.Tool(cfg.prefix + short + "_" + m.Name).
+				Description(description).
+				InputSchema(InputSchema(m.Input))
+			if m.ReadOnly {
+				b.ReadOnly()
+			} else if m.Idempotent {
+				b.Idempotent()
+			}
+			if m.Deprecated {
+				b.Deprecated("deprecated by the service")
+			}
+
+			fullMethod := "/" + svc.Name + "/" + m.Name
+			b.Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+				return call(ctx, cfg, inv, fullMethod, args)
+			})
+			if err := b.Err(); err != nil {
+				return fmt.Errorf("grpctools: register %s: %w", fullMethod, err)
+			}
+			registered++
+		}
+	}
+	if registered == 0 {
+		return errors.New("grpctools: no unary methods to register")
+	}
+	return nil
+}
+
+// call invokes a method for a tool call.
+func call(ctx context.Context, cfg *config, inv Invoker, method string, args json.RawMessage) (*server.ToolResult, error) {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	resp, err := inv.Invoke(ctx, method, args)
+	if err != nil {
+		return server.NewToolResult().Text(err.Error()).WithError(), nil
+	}
+	return server.NewToolResult().Text(string(resp)), nil
+}
+
+// InputSchema returns the JSON Schema of the protobuf JSON encoding of a
+// message. 64-bit integers are strings, bytes are base64 strings, enums are
+// value names and maps are objects. Well-known types, such as Timestamp,
+// use their JSON forms. Recursive fields accept any value.
+func InputSchema(m *Message) map[string]any {
+	return messageSchema(m, nil)
+}
+
+func messageSchema(m *Message, visiting []*Message) map[string]any {
+	if s := wellKnownSchema(m.Name); s != nil {
+		return s
+	}
+	if slices.Contains(visiting, m) {
+		return map[string]any{}
+	}
+	visiting = append(visiting, m)
+
+	properties := map[string]any{}
+	var required []string
+	for _, f := range m.Fields {
+		properties[f.Name] = fieldSchema(f, visiting)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func fieldSchema(f Field, visiting []*Message) map[string]any {
+	var s map[string]any
+	switch {
+	case f.Kind == KindMessage && f.Message.MapEntry && len(f.Message.Fields) == 2:
+		s = map[string]any{
+			"type":                 "object",
+			"additionalProperties": fieldSchema(f.Message.Fields[1], visiting),
+		}
+	case f.Repeated:
+		f.Repeated = false
+		f.Description = ""
+		s = map[string]any{"type": "array", "items": fieldSchema(f, visiting)}
+	case f.Kind == KindMessage:
+		s = messageSchema(f.Message, visiting)
+	default:
+		s = kindSchema(f)
+	}
+	if f.Description != "" {
+		s["description"] = f.Description
+	}
+	return s
+}
+
+func kindSchema(f Field) map[string]any {
+	switch f.Kind {
+	case KindDouble, KindFloat:
+		return map[string]any{"type": "number"}
+	case KindInt32:
+		return map[string]any{"type": "integer"}
+	case KindUint32:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case KindInt64, KindUint64:
+		return map[string]any{"type": "string", "format": string(f.Kind)}
+	case KindBool:
+		return map[string]any{"type": "boolean"}
+	case KindBytes:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case KindEnum:
+		s := map[string]any{"type": "string"}
+		if len(f.Enum) > 0 {
+			s["enum"] = f.Enum
+		}
+		return s
+	}
+	return map[string]any{"type": "string"}
+}
+
+// wellKnownSchema returns the schema of the JSON form of a well-known
+// type, or nil.
+func wellKnownSchema(name string) map[string]any {
+	switch name {
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "description": "Duration in seconds with an s suffix, such as 1.5s"}
+	case "google.protobuf.FieldMask":
+		return map[string]any{"type": "string", "description": "Comma-separated field paths"}
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Empty":
+		return map[string]any{"type": "object"}
+	case "google.protobuf.ListValue":
+		return map[string]any{"type": "array"}
+	case "google.protobuf.Value":
+		return map[string]any{}
+	case "google.protobuf.StringValue":
+		return kindSchema(Field{Kind: KindString})
+	case "google.protobuf.BytesValue":
+		return kindSchema(Field{Kind: KindBytes})
+	case "google.protobuf.BoolValue":
+		return kindSchema(Field{Kind: KindBool})
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue":
+		return kindSchema(Field{Kind: KindDouble})
+	case "google.protobuf.Int32Value":
+		return kindSchema(Field{Kind: KindInt32})
+	case "google.protobuf.UInt32Value":
+		return kindSchema(Field{Kind: KindUint32})
+	case "google.protobuf.Int64Value":
+		return kindSchema(Field{Kind: KindInt64})
+	case "google.protobuf.UInt64Value":
+		return kindSchema(Field{Kind: KindUint64})
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package grpctools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/testutil"
+)
+
+// Protobuf encoding helpers for building descriptors.
+
+func tag(num, typ int) []byte {
+	return binary.AppendUvarint(nil, uint64(num<<3|typ))
+}
+
+func varintField(num int, v uint64) []byte {
+	return binary.AppendUvarint(tag(num, wireVarint), v)
+}
+
+func bytesField(num int, data []byte) []byte {
+	b := binary.AppendUvarint(tag(num, wireBytes), uint64(len(data)))
+	return append(b, data...)
+}
+
+func strField(num int, s string) []byte {
+	return bytesField(num, []byte(s))
+}
+
+func msg(fields ...[]byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func field(name string, number, label, typ int, typeName string) []byte {
+	f := msg(strField(fieldName, name), varintField(3, uint64(number)), varintField(fieldLabel, uint64(label)), varintField(fieldType, uint64(typ)))
+	if typeName != "" {
+		f = append(f, strField(fieldTypeName, typeName)...)
+	}
+	return bytesField(messageField, f)
+}
+
+func location(comment string, path ...int) []byte {
+	var packed []byte
+	for _, p := range path {
+		packed = binary.AppendUvarint(packed, uint64(p))
+	}
+	return bytesField(sourceInfoLocation, msg(bytesField(locationPath, packed), strField(locationLeadingComments, " "+comment+"\n")))
+}
+
+const optional = 1
+
+// greeterFile is greeter.proto:
+//
+//	package greeter.v1;
+//
+//	message HelloRequest {
+//	  enum Mood { UNKNOWN = 0; HAPPY = 1; }
+//	  // Who to greet.
+//	  string name = 1;
+//	  repeated string tags = 2;
+//	  int64 user_id = 3;
+//	  map<string, int32> counts = 4;
+//	  Mood mood = 5;
+//	  HelloRequest parent = 6;
+//	  google.protobuf.Timestamp at = 7;
+//	}
+//	message HelloReply { string message = 1; }
+//
+//	service Greeter {
+//	  // Says hello.
+//	  rpc SayHello(HelloRequest) returns (HelloReply) { option idempotency_level = NO_SIDE_EFFECTS; }
+//	  rpc StreamHellos(HelloRequest) returns (stream HelloReply);
+//	  rpc Forget(HelloRequest) returns (HelloReply) { option deprecated = true; option idempotency_level = IDEMPOTENT; }
+//	}
+func greeterFile() []byte {
+	countsEntry := msg(
+		strField(messageName, "CountsEntry"),
+		field("key", 1, optional, typeString, ""),
+		field("value", 2, optional, typeInt32, ""),
+		bytesField(messageOptions, varintField(messageMapEntry, 1)),
+	)
+	mood := msg(
+		strField(enumName, "Mood"),
+		bytesField(enumValue, strField(enumValueName, "UNKNOWN")),
+		bytesField(enumValue, strField(enumValueName, "HAPPY")),
+	)
+	request := msg(
+		strField(messageName, "HelloRequest"),
+		field("name", 1, optional, typeString, ""),
+		field("tags", 2, labelRepeated, typeString, ""),
+		field("user_id", 3, optional, typeInt64, ""),
+		field("counts", 4, labelRepeated, typeMessage, ".greeter.v1.HelloRequest.CountsEntry"),
+		field("mood", 5, optional, typeEnum, ".greeter.v1.HelloRequest.Mood"),
+		field("parent", 6, optional, typeMessage, ".greeter.v1.HelloRequest"),
+		field("at", 7, optional, typeMessage, ".google.protobuf.Timestamp"),
+		bytesField(messageNestedType, countsEntry),
+		bytesField(messageEnumType, mood),
+	)
+	reply := msg(
+		strField(messageName, "HelloReply"),
+		field("message", 1, optional, typeString, ""),
+	)
+	method := func(name string, streaming bool, options []byte) []byte {
+		m := msg(strField(methodName, name), strField(methodInputType, ".greeter.v1.HelloRequest"), strField(methodOutputType, ".greeter.v1.HelloReply"))
+		if streaming {
+			m = append(m, varintField(methodServerStreaming, 1)...)
+		}
+		if options != nil {
+			m = append(m, bytesField(methodOptions, options)...)
+		}
+		return bytesField(serviceMethod, m)
+	}
+	service := msg(
+		strField(serviceName, "Greeter"),
+		method("SayHello", false, varintField(methodIdempotency, noSideEffects)),
+		method("StreamHellos", true, nil),
+		method("Forget", false, msg(varintField(methodDeprecated, 1), varintField(methodIdempotency, idempotent))),
+	)
+	return msg(
+		strField(1, "greeter.proto"),
+		strField(filePackage, "greeter.v1"),
+		bytesField(fileMessageType, request),
+		bytesField(fileMessageType, reply),
+		bytesField(fileService, service),
+		bytesField(fileSourceInfo, msg(
+			location("Says hello.", fileService, 0, serviceMethod, 0),
+			location("Who to greet.", fileMessageType, 0, messageField, 0),
+		)),
+	)
+}
+
+func TestParseDescriptorSet(t *testing.T) {
+	services, err := ParseDescriptorSet(bytesField(fileSetFile, greeterFile()))
+	if err != nil {
+		t.Fatalf("ParseDescriptorSet() error = %v", err)
+	}
+	if len(services) != 1 || services[0].Name != "greeter.v1.Greeter" {
+		t.Fatalf("services = %+v", services)
+	}
+	methods := services[0].Methods
+	if len(methods) != 3 {
+		t.Fatalf("methods = %+v", methods)
+	}
+
+	say := methods[0]
+	if say.Name != "SayHello" || say.Description != "Says hello." || !say.ReadOnly || !say.Idempotent || !say.Unary() {
+		t.Errorf("SayHello = %+v", say)
+	}
+	if methods[1].Unary() {
+		t.Error("StreamHellos is unary")
+	}
+	if forget := methods[2]; !forget.Deprecated || forget.ReadOnly || !forget.Idempotent {
+		t.Errorf("Forget = %+v", forget)
+	}
+
+	in := say.Input
+	if in.Name != "greeter.v1.HelloRequest" || say.Output.Name != "greeter.v1.HelloReply" {
+		t.Errorf("types = %s, %s", in.Name, say.Output.Name)
+	}
+	var names []string
+	for _, f := range in.Fields {
+		names = append(names, f.Name)
+	}
+	if want := []string{"name", "tags", "userId", "counts", "mood", "parent", "at"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fields = %v, want %v", names, want)
+	}
+	if in.Fields[0].Description != "Who to greet." {
+		t.Errorf("name description = %q", in.Fields[0].Description)
+	}
+	if in.Fields[5].Message != in {
+		t.Error("recursive field does not refer to its message")
+	}
+	if !in.Fields[3].Message.MapEntry {
+		t.Error("counts is not a map")
+	}
+	if want := []string{"UNKNOWN", "HAPPY"}; !reflect.DeepEqual(in.Fields[4].Enum, want) {
+		t.Errorf("mood enum = %v, want %v", in.Fields[4].Enum, want)
+	}
+}
+
+func TestParseFiles_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{name: "truncated", file: greeterFile()[:20]},
+		{name: "bad wire type", file: tag(1, 3)},
+		{
+			name: "unresolved type",
+			file: msg(strField(filePackage, "p"), bytesField(fileService, msg(
+				strField(serviceName, "S"),
+				bytesField(serviceMethod, msg(strField(methodName, "M"), strField(methodInputType, ".p.Missing"), strField(methodOutputType, ".p.Missing"))),
+			))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFiles(tt.file); err == nil {
+				t.Error("ParseFiles() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestJSONName(t *testing.T) {
+	tests := map[string]string{
+		"name":        "name",
+		"user_id":     "userId",
+		"http_status": "httpStatus",
+		"a_b_c":       "aBC",
+		"v2_id":       "v2Id",
+	}
+	for in, want := range tests {
+		if got := jsonName(in); got != want {
+			t.Errorf("jsonName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInputSchema(t *testing.T) {
+	services, err := ParseFiles(greeterFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(InputSchema(services[0].Methods[0].Input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{
+		"name":{"type":"string","description":"Who to greet."},
+		"tags":{"type":"array","items":{"type":"string"}},
+		"userId":{"type":"string","format":"int64"},
+		"counts":{"type":"object","additionalProperties":{"type":"integer"}},
+		"mood":{"type":"string","enum":["UNKNOWN","HAPPY"]},
+		"parent":{},
+		"at":{"type":"string","format":"date-time"}
+	}}`
+	assertJSON(t, got, want)
+}
+
+func TestRegister(t *testing.T) {
+	services, err := ParseFiles(greeterFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotMethod, gotRequest string
+	var gotDeadline bool
+	inv := InvokerFunc(func(ctx context.Context, method string, req json.RawMessage) (json.RawMessage, error) {
+		gotMethod, gotRequest = method, string(req)
+		_, gotDeadline = ctx.Deadline()
+		if strings.Contains(string(req), "fail") {
+			return nil, errors.New("rpc error: code = NotFound desc = no such greeting")
+		}
+		return json.RawMessage(`{"message":"hello"}`), nil
+	})
+
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	if err := Register(srv, inv, services, WithPrefix("g_"), WithTimeout(time.Minute)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tools := map[string]server.ToolInfo{}
+	for _, info := range srv.Tools() {
+		tools[info.Name] = info
+	}
+	if len(tools) != 2 {
+		t.Fatalf("tools = %v, want g_Greeter_SayHello and g_Greeter_Forget", srv.Tools())
+	}
+	say := tools["g_Greeter_SayHello"]
+	if say.Description != "Says hello." || say.Annotations == nil || !*say.Annotations.ReadOnlyHint {
+		t.Errorf("SayHello = %+v", say)
+	}
+	forget := tools["g_Greeter_Forget"]
+	if forget.Description != "Calls greeter.v1.Greeter.Forget" || forget.Deprecated == "" || !*forget.Annotations.IdempotentHint {
+		t.Errorf("Forget = %+v", forget)
+	}
+
+	tc := testutil.NewTestClient(t, srv)
+	if _, err := tc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	text, err := tc.CallTool("g_Greeter_SayHello", map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text != `{"message":"hello"}` {
+		t.Errorf("CallTool() = %q", text)
+	}
+	if gotMethod != "/greeter.v1.Greeter/SayHello" || gotRequest != `{"name":"Ada"}` || !gotDeadline {
+		t.Errorf("Invoke(%q, %q), deadline %v", gotMethod, gotRequest, gotDeadline)
+	}
+
+	_, err = tc.CallTool("g_Greeter_SayHello", map[string]any{"name": "fail"})
+	var toolErr *server.ToolError
+	if !errors.As(err, &toolErr) || !strings.Contains(err.Error(), "NotFound") {
+		t.Errorf("CallTool() error = %v, want tool error", err)
+	}
+}
+
+func TestRegister_Errors(t *testing.T) {
+	services, err := ParseFiles(greeterFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := InvokerFunc(func(ctx context.Context, method string, req json.RawMessage) (json.RawMessage, error) {
+		return req, nil
+	})
+
+	tests := []struct {
+		name     string
+		services []Service
+		opts     []Option
+	}{
+		{name: "no services"},
+		{name: "all filtered", services: services, opts: []Option{WithFilter(func(string, Method) bool { return false })}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+			if err := Register(srv, inv, tt.services, tt.opts...); err == nil {
+				t.Error("Register() error = nil, want error")
+			}
+		})
+	}
+}
+
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotV, wantV any
+	if err := json.Unmarshal(got, &gotV); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantV); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotV, wantV) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
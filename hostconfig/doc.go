@@ -0,0 +1,43 @@
+// Package hostconfig registers MCP servers with desktop hosts such as
+// Claude Desktop, Cursor and Windsurf.
+//
+// These hosts launch stdio servers from a JSON config file with an
+// "mcpServers" object that maps a server name to the command to run. A
+// server that builds but is missing from that file, or is registered with
+// a relative path the host cannot resolve, never shows up in the host.
+// This package writes correct entries and leaves the rest of the file
+// untouched.
+//
+// # Registering a Server
+//
+//	server, err := hostconfig.ForBinary("./bin/weather", "--units", "metric")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	path, err := hostconfig.ClaudeDesktop.Install("weather", server.WithEnv("API_KEY", key))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println("Registered in", path, "- restart Claude Desktop to load it")
+//
+// # Printing a Config Snippet
+//
+// Snippet returns the JSON to paste into a config file by hand:
+//
+//	data, _ := hostconfig.Snippet("weather", server)
+//	os.Stdout.Write(data)
+//
+// Merge works on config file contents directly, for hosts without a
+// predefined Host or for files managed by other tools.
+//
+// # Packaging a Desktop Extension
+//
+// WriteExtension writes the manifest.json of a Desktop Extension bundle,
+// which hosts install with one click instead of a config edit:
+//
+//	path, err := hostconfig.WriteExtension("dist/weather", srv, hostconfig.Extension{
+//	    Description: "Forecasts for any city",
+//	    Author:      hostconfig.Author{Name: "Ada"},
+//	    EntryPoint:  "server/weather",
+//	})
+package hostconfig
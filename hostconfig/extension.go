@@ -0,0 +1,159 @@
+package hostconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// ExtensionManifestVersion is the version of the Desktop Extension
+// manifest format written by ExtensionManifest.
+const ExtensionManifestVersion = "0.2"
+
+// Extension describes a Desktop Extension (an .mcpb bundle, formerly
+// .dxt): a zip archive holding a server binary and a manifest.json, which
+// hosts such as Claude Desktop install with one click. The name, version
+// and tools of the extension are taken from the server.
+type Extension struct {
+	// DisplayName is the name shown in the host. Defaults to the server
+	// name.
+	DisplayName string
+	// Description is a short description of the extension. Required.
+	Description string
+	// Author is the author of the extension. Its name is required.
+	Author Author
+	// EntryPoint is the path of the server binary inside the bundle, using
+	// forward slashes, such as "server/weather". Required.
+	EntryPoint string
+	// Args are the command-line arguments of the server.
+	Args []string
+	// Env holds extra environment variables of the server.
+	Env map[string]string
+	// Homepage, License, Icon (a path inside the bundle) and Keywords are
+	// optional metadata shown in the host.
+	Homepage string
+	License  string
+	Icon     string
+	Keywords []string
+	// Platforms lists the supported platforms ("darwin", "win32",
+	// "linux"). Empty means all.
+	Platforms []string
+}
+
+// Author identifies the author of an extension.
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// extensionManifest is the manifest.json of a Desktop Extension.
+type extensionManifest struct {
+	ManifestVersion  string                  `json:"manifest_version"`
+	Name             string                  `json:"name"`
+	DisplayName      string                  `json:"display_name,omitempty"`
+	Version          string                  `json:"version"`
+	Description      string                  `json:"description"`
+	Author           Author                  `json:"author"`
+	Homepage         string                  `json:"homepage,omitempty"`
+	License          string                  `json:"license,omitempty"`
+	Icon             string                  `json:"icon,omitempty"`
+	Keywords         []string                `json:"keywords,omitempty"`
+	Server           extensionServer         `json:"server"`
+	Tools            []extensionTool         `json:"tools,omitempty"`
+	PromptsGenerated bool                    `json:"prompts_generated,omitempty"`
+	Compatibility    *extensionCompatibility `json:"compatibility,omitempty"`
+}
+
+type extensionServer struct {
+	Type       string `json:"type"`
+	EntryPoint string `json:"entry_point"`
+	MCPConfig  Server `json:"mcp_config"`
+}
+
+type extensionTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type extensionCompatibility struct {
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// ExtensionManifest returns the manifest.json of a Desktop Extension
+// packaging srv. Tools are listed as registered; prompts are marked as
+// provided at runtime, since the manifest format only describes static
+// prompt text.
+func ExtensionManifest(srv *server.Server, ext Extension) ([]byte, error) {
+	info := srv.Info()
+	switch {
+	case info.Name == "" || info.Version == "":
+		return nil, errors.New("hostconfig: extension requires a server name and version")
+	case ext.Description == "":
+		return nil, errors.New("hostconfig: extension description is required")
+	case ext.Author.Name == "":
+		return nil, errors.New("hostconfig: extension author name is required")
+	case ext.EntryPoint == "" || path.IsAbs(ext.EntryPoint) || filepath.IsAbs(ext.EntryPoint):
+		return nil, fmt.Errorf("hostconfig: extension entry point %q must be a path inside the bundle", ext.EntryPoint)
+	}
+
+	manifest := extensionManifest{
+		ManifestVersion: ExtensionManifestVersion,
+		Name:            info.Name,
+		DisplayName:     ext.DisplayName,
+		Version:         info.Version,
+		Description:     ext.Description,
+		Author:          ext.Author,
+		Homepage:        ext.Homepage,
+		License:         ext.License,
+		Icon:            ext.Icon,
+		Keywords:        ext.Keywords,
+		Server: extensionServer{
+			Type:       "binary",
+			EntryPoint: ext.EntryPoint,
+			MCPConfig: Server{
+				// The host expands ${__dirname} to the unpacked bundle
+				Command: "${__dirname}/" + path.Clean(ext.EntryPoint),
+				Args:    ext.Args,
+				Env:     ext.Env,
+			},
+		},
+		PromptsGenerated: len(srv.Prompts()) > 0,
+	}
+	for _, t := range srv.Tools() {
+		manifest.Tools = append(manifest.Tools, extensionTool{Name: t.Name, Description: t.ListDescription()})
+	}
+	if len(ext.Platforms) > 0 {
+		manifest.Compatibility = &extensionCompatibility{Platforms: ext.Platforms}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("hostconfig: encode extension manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteExtension writes the manifest.json of a Desktop Extension packaging
+// srv to dir, the root of the bundle, and returns its path. Zipping dir
+// with the server binary at the entry point produces the installable
+// bundle.
+func WriteExtension(dir string, srv *server.Server, ext Extension) (string, error) {
+	data, err := ExtensionManifest(srv, ext)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("hostconfig: create %s: %w", dir, err)
+	}
+	file := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return "", fmt.Errorf("hostconfig: write %s: %w", file, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,117 @@
+package hostconfig
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+func newWeatherServer() *server.Server {
+	srv := server.New(server.Info{Name: "weather", Version: "1.2.0"})
+	srv.Tool("forecast").Description("Get the forecast").Handler(func(in struct{}) (string, error) {
+		return "", nil
+	})
+	srv.Prompt("summary").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+		return &server.PromptResult{}, nil
+	})
+	return srv
+}
+
+func TestExtensionManifest(t *testing.T) {
+	ext := Extension{
+		DisplayName: "Weather",
+		Description: "Forecasts for any city",
+		Author:      Author{Name: "Ada", Email: "ada@example.com"},
+		EntryPoint:  "server/weather",
+		Args:        []string{"--units", "metric"},
+		Env:         map[string]string{"API_KEY": "${user_config.api_key}"},
+		License:     "MIT",
+		Platforms:   []string{"darwin", "linux"},
+	}
+	data, err := ExtensionManifest(newWeatherServer(), ext)
+	if err != nil {
+		t.Fatalf("ExtensionManifest() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	want := map[string]any{
+		"manifest_version": "0.2",
+		"name":             "weather",
+		"display_name":     "Weather",
+		"version":          "1.2.0",
+		"description":      "Forecasts for any city",
+		"author":           map[string]any{"name": "Ada", "email": "ada@example.com"},
+		"license":          "MIT",
+		"server": map[string]any{
+			"type":        "binary",
+			"entry_point": "server/weather",
+			"mcp_config": map[string]any{
+				"command": "${__dirname}/server/weather",
+				"args":    []any{"--units", "metric"},
+				"env":     map[string]any{"API_KEY": "${user_config.api_key}"},
+			},
+		},
+		"tools":             []any{map[string]any{"name": "forecast", "description": "Get the forecast"}},
+		"prompts_generated": true,
+		"compatibility":     map[string]any{"platforms": []any{"darwin", "linux"}},
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("manifest =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+func TestExtensionManifest_Invalid(t *testing.T) {
+	valid := Extension{Description: "d", Author: Author{Name: "a"}, EntryPoint: "server/weather"}
+
+	tests := []struct {
+		name    string
+		srv     *server.Server
+		change  func(e *Extension)
+		wantErr string
+	}{
+		{"missing version", server.New(server.Info{Name: "weather"}), func(e *Extension) {}, "name and version"},
+		{"missing description", newWeatherServer(), func(e *Extension) { e.Description = "" }, "description"},
+		{"missing author", newWeatherServer(), func(e *Extension) { e.Author = Author{} }, "author"},
+		{"missing entry point", newWeatherServer(), func(e *Extension) { e.EntryPoint = "" }, "entry point"},
+		{"absolute entry point", newWeatherServer(), func(e *Extension) { e.EntryPoint = "/usr/bin/weather" }, "entry point"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := valid
+			tt.change(&ext)
+			_, err := ExtensionManifest(tt.srv, ext)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ExtensionManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteExtension(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bundle")
+	ext := Extension{Description: "d", Author: Author{Name: "a"}, EntryPoint: "server/weather"}
+	path, err := WriteExtension(dir, newWeatherServer(), ext)
+	if err != nil {
+		t.Fatalf("WriteExtension() error = %v", err)
+	}
+	if path != filepath.Join(dir, "manifest.json") {
+		t.Errorf("path = %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	if !json.Valid(data) || !strings.Contains(string(data), `"entry_point": "server/weather"`) {
+		t.Errorf("manifest = %s", data)
+	}
+}
@@ -0,0 +1,206 @@
+package hostconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// serversKey is the config key that holds the servers of a host.
+const serversKey = "mcpServers"
+
+// Server is the config entry that tells a host how to launch a stdio MCP
+// server.
+type Server struct {
+	// Command is the executable to run. Hosts do not run it from the
+	// directory the config was written in, so it should be absolute.
+	Command string `json:"command"`
+	// Args are the command-line arguments.
+	Args []string `json:"args,omitempty"`
+	// Env holds extra environment variables, such as API keys.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// ForBinary returns the entry for the server binary at path, made absolute
+// so the host can find it. It returns an error if path does not exist or
+// is a directory.
+func ForBinary(path string, args ...string) (Server, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Server{}, fmt.Errorf("hostconfig: resolve %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return Server{}, fmt.Errorf("hostconfig: %w", err)
+	}
+	if info.IsDir() {
+		return Server{}, fmt.Errorf("hostconfig: %s is a directory", abs)
+	}
+	return Server{Command: abs, Args: args}, nil
+}
+
+// WithEnv returns a copy of s that sets the environment variable key to
+// value.
+func (s Server) WithEnv(key, value string) Server {
+	env := maps.Clone(s.Env)
+	if env == nil {
+		env = make(map[string]string, 1)
+	}
+	env[key] = value
+	s.Env = env
+	return s
+}
+
+// Host is a desktop application that launches MCP servers from a JSON
+// config file.
+type Host struct {
+	// Name is the display name of the host.
+	Name string
+
+	// path returns the config file path for an operating system, given the
+	// home and user config directories.
+	path func(goos, home, configDir string) string
+}
+
+// Predefined hosts.
+var (
+	// ClaudeDesktop is the Claude Desktop app.
+	ClaudeDesktop = Host{Name: "Claude Desktop", path: claudeDesktopPath}
+	// Cursor is the Cursor editor, configured for all projects.
+	Cursor = Host{Name: "Cursor", path: homePath(".cursor", "mcp.json")}
+	// Windsurf is the Windsurf editor.
+	Windsurf = Host{Name: "Windsurf", path: homePath(".codeium", "windsurf", "mcp_config.json")}
+)
+
+func claudeDesktopPath(goos, home, configDir string) string {
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json")
+	case "windows":
+		return filepath.Join(configDir, "Claude", "claude_desktop_config.json")
+	default:
+		return filepath.Join(home, ".config", "Claude", "claude_desktop_config.json")
+	}
+}
+
+func homePath(elem ...string) func(goos, home, configDir string) string {
+	return func(_, home, _ string) string {
+		return filepath.Join(append([]string{home}, elem...)...)
+	}
+}
+
+// ConfigPath returns the path of the config file of the host for the
+// current user.
+func (h Host) ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("hostconfig: %w", err)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = home
+	}
+	return h.path(runtime.GOOS, home, configDir), nil
+}
+
+// Install registers server under name in the config file of the host,
+// replacing an existing entry with that name and keeping everything else.
+// It creates the file if needed and returns its path. The host loads the
+// change when it restarts.
+func (h Host) Install(name string, server Server) (string, error) {
+	return h.update(func(config []byte) ([]byte, error) {
+		return Merge(config, name, server)
+	})
+}
+
+// Uninstall removes the server registered under name from the config file
+// of the host and returns its path. It does nothing if the file or entry
+// does not exist.
+func (h Host) Uninstall(name string) (string, error) {
+	return h.update(func(config []byte) ([]byte, error) {
+		return Remove(config, name)
+	})
+}
+
+func (h Host) update(change func(config []byte) ([]byte, error)) (string, error) {
+	path, err := h.ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	config, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("hostconfig: %w", err)
+	}
+	updated, err := change(config)
+	if err != nil {
+		return "", fmt.Errorf("hostconfig: %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("hostconfig: %w", err)
+	}
+	// Entries may hold API keys in their environment
+	if err := os.WriteFile(path, updated, 0o600); err != nil {
+		return "", fmt.Errorf("hostconfig: %w", err)
+	}
+	return path, nil
+}
+
+// Merge returns config with server registered under name, replacing an
+// existing entry with that name. Other servers and settings are kept. An
+// empty config is treated as an empty object.
+func Merge(config []byte, name string, server Server) ([]byte, error) {
+	entry, err := json.Marshal(server)
+	if err != nil {
+		return nil, err
+	}
+	return editServers(config, func(servers map[string]json.RawMessage) {
+		servers[name] = entry
+	})
+}
+
+// Remove returns config without the server registered under name.
+func Remove(config []byte, name string) ([]byte, error) {
+	return editServers(config, func(servers map[string]json.RawMessage) {
+		delete(servers, name)
+	})
+}
+
+// Snippet returns a config containing only server registered under name,
+// for pasting into a config file by hand.
+func Snippet(name string, server Server) ([]byte, error) {
+	return Merge(nil, name, server)
+}
+
+func editServers(config []byte, edit func(servers map[string]json.RawMessage)) ([]byte, error) {
+	root := make(map[string]json.RawMessage)
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &root); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	servers := make(map[string]json.RawMessage)
+	if raw, ok := root[serversKey]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &servers); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", serversKey, err)
+		}
+	}
+
+	edit(servers)
+
+	raw, err := json.Marshal(servers)
+	if err != nil {
+		return nil, err
+	}
+	root[serversKey] = raw
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
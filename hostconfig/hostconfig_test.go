@@ -0,0 +1,202 @@
+package hostconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	server := Server{Command: "/usr/local/bin/weather", Args: []string{"--units", "metric"}}
+
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "empty config",
+			config: ``,
+			want:   `{"mcpServers":{"weather":{"command":"/usr/local/bin/weather","args":["--units","metric"]}}}`,
+		},
+		{
+			name:   "keeps other servers and settings",
+			config: `{"theme":"dark","mcpServers":{"files":{"command":"/bin/files"}}}`,
+			want:   `{"mcpServers":{"files":{"command":"/bin/files"},"weather":{"command":"/usr/local/bin/weather","args":["--units","metric"]}},"theme":"dark"}`,
+		},
+		{
+			name:   "replaces an existing entry",
+			config: `{"mcpServers":{"weather":{"command":"old","env":{"A":"1"}}}}`,
+			want:   `{"mcpServers":{"weather":{"command":"/usr/local/bin/weather","args":["--units","metric"]}}}`,
+		},
+		{
+			name:   "null servers",
+			config: `{"mcpServers":null}`,
+			want:   `{"mcpServers":{"weather":{"command":"/usr/local/bin/weather","args":["--units","metric"]}}}`,
+		},
+		{name: "invalid JSON", config: `{`, wantErr: true},
+		{name: "servers not an object", config: `{"mcpServers":[]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge([]byte(tt.config), "weather", server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	got, err := Remove([]byte(`{"mcpServers":{"a":{"command":"a"},"b":{"command":"b"}}}`), "a")
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	assertJSON(t, got, `{"mcpServers":{"b":{"command":"b"}}}`)
+}
+
+func TestSnippet(t *testing.T) {
+	got, err := Snippet("weather", Server{Command: "/bin/weather"}.WithEnv("API_KEY", "secret"))
+	if err != nil {
+		t.Fatalf("Snippet() error = %v", err)
+	}
+	want := "{\n  \"mcpServers\": {\n    \"weather\": {\n      \"command\": \"/bin/weather\",\n      \"env\": {\n        \"API_KEY\": \"secret\"\n      }\n    }\n  }\n}\n"
+	if string(got) != want {
+		t.Errorf("Snippet() = %s, want %s", got, want)
+	}
+}
+
+func TestServer_WithEnv(t *testing.T) {
+	base := Server{Command: "srv", Env: map[string]string{"A": "1"}}
+	got := base.WithEnv("B", "2")
+
+	if !reflect.DeepEqual(got.Env, map[string]string{"A": "1", "B": "2"}) {
+		t.Errorf("Env = %v", got.Env)
+	}
+	if len(base.Env) != 1 {
+		t.Errorf("WithEnv modified the original: %v", base.Env)
+	}
+}
+
+func TestForBinary(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "server")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "relative path", path: "server", want: bin},
+		{name: "absolute path", path: bin, want: bin},
+		{name: "missing", path: "missing", wantErr: true},
+		{name: "directory", path: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForBinary(tt.path, "--stdio")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ForBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Command != tt.want || !reflect.DeepEqual(got.Args, []string{"--stdio"}) {
+				t.Errorf("ForBinary() = %+v, want command %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHost_ConfigPath(t *testing.T) {
+	home := "/home/user"
+	config := "/home/user/AppData"
+
+	tests := []struct {
+		host Host
+		goos string
+		want string
+	}{
+		{ClaudeDesktop, "darwin", filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json")},
+		{ClaudeDesktop, "windows", filepath.Join(config, "Claude", "claude_desktop_config.json")},
+		{ClaudeDesktop, "linux", filepath.Join(home, ".config", "Claude", "claude_desktop_config.json")},
+		{Cursor, "linux", filepath.Join(home, ".cursor", "mcp.json")},
+		{Windsurf, "darwin", filepath.Join(home, ".codeium", "windsurf", "mcp_config.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host.Name+"/"+tt.goos, func(t *testing.T) {
+			if got := tt.host.path(tt.goos, home, config); got != tt.want {
+				t.Errorf("path = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHost_Install(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	host := Cursor
+
+	path, err := host.Install("weather", Server{Command: "/bin/weather"})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if _, err := host.Install("files", Server{Command: "/bin/files"}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSON(t, data, `{"mcpServers":{"files":{"command":"/bin/files"},"weather":{"command":"/bin/weather"}}}`)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+
+	if _, err := host.Uninstall("weather"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	assertJSON(t, data, `{"mcpServers":{"files":{"command":"/bin/files"}}}`)
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := host.Install("weather", Server{Command: "/bin/weather"}); err == nil {
+		t.Error("Install() over an invalid config succeeded")
+	}
+}
+
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid want JSON: %v", err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("config = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Package integration mounts MCP servers into existing web applications.
+//
+// Handler returns an http.Handler for a server and Mount attaches it to a
+// router under a path prefix. Both work with any router built on net/http
+// handlers, including *http.ServeMux and chi, without this package
+// depending on the router.
+//
+// # net/http
+//
+//	mux := http.NewServeMux()
+//	integration.Mount(mux, "/ai", srv)
+//	// MCP endpoint: POST /ai/mcp, events: GET /ai/mcp/sse
+//
+// # chi
+//
+// chi.Router satisfies Router; Mount uses chi's own Mount method:
+//
+//	r := chi.NewRouter()
+//	r.Use(myAuth)
+//	integration.Mount(r, "/ai", srv)
+//
+// # echo
+//
+// Wrap the handler with echo.WrapHandler and strip the prefix:
+//
+//	h := http.StripPrefix("/ai", integration.Handler(srv))
+//	e.Any("/ai/*", echo.WrapHandler(h))
+//
+// # gin
+//
+// Wrap the handler with gin.WrapH and strip the prefix:
+//
+//	h := http.StripPrefix("/ai", integration.Handler(srv))
+//	r.Any("/ai/*path", gin.WrapH(h))
+//
+// # Bridging Framework Authentication
+//
+// Routers that authenticate requests before they reach MCP can pass the
+// result on with WithIdentity, so the identity is available to MCP
+// middleware and handlers through mcp.IdentityFromContext:
+//
+//	integration.Mount(r, "/ai", srv,
+//	    integration.WithIdentity(func(r *http.Request) *mcp.Identity {
+//	        user, ok := auth.UserFromContext(r.Context())
+//	        if !ok {
+//	            return nil
+//	        }
+//	        return &mcp.Identity{ID: user.ID, Name: user.Email}
+//	    }),
+//	)
+//
+// WithRequestMeta and WithHeaders copy request data, such as headers, into
+// the request metadata read by mcp.Auth and protocol.GetRequestMeta.
+//
+// Frameworks that keep values in their own context rather than the request
+// context, such as gin and echo, need to copy them to the request context
+// first, for example in gin:
+//
+//	r.Use(func(c *gin.Context) {
+//	    if user, ok := c.Get("user"); ok {
+//	        c.Request = c.Request.WithContext(mcp.ContextWithIdentity(c.Request.Context(), toIdentity(user)))
+//	    }
+//	})
+package integration
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/felixgeelhaar/mcp-go"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Router is a router that serves http.Handlers at a pattern, such as
+// *http.ServeMux and chi.Router.
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// mounter is implemented by routers with prefix mounting, such as chi.Router.
+type mounter interface {
+	Mount(pattern string, handler http.Handler)
+}
+
+// IdentityFunc returns the identity a framework authenticated for r, or
+// nil if the request is unauthenticated.
+type IdentityFunc func(r *http.Request) *mcp.Identity
+
+// RequestMetaFunc returns the request metadata to attach to r.
+type RequestMetaFunc func(r *http.Request) protocol.RequestMeta
+
+// Option configures Handler and Mount.
+type Option func(*config)
+
+type config struct {
+	httpOpts  []mcp.HTTPOption
+	serveOpts []mcp.ServeOption
+	identity  IdentityFunc
+	meta      []RequestMetaFunc
+}
+
+// WithHTTPOptions sets options of the underlying HTTP transport, such as
+// mcp.WithCORS.
+func WithHTTPOptions(opts ...mcp.HTTPOption) Option {
+	return func(c *config) {
+		c.httpOpts = append(c.httpOpts, opts...)
+	}
+}
+
+// WithServeOptions sets serve options, such as mcp.WithMiddleware.
+func WithServeOptions(opts ...mcp.ServeOption) Option {
+	return func(c *config) {
+		c.serveOpts = append(c.serveOpts, opts...)
+	}
+}
+
+// WithIdentity attaches the identity returned by fn to every request, so
+// authentication done by the router is visible to MCP middleware and
+// handlers.
+func WithIdentity(fn IdentityFunc) Option {
+	return func(c *config) {
+		c.identity = fn
+	}
+}
+
+// WithRequestMeta attaches the metadata returned by fn to every request.
+// Metadata from several options is merged, later options winning.
+func WithRequestMeta(fn RequestMetaFunc) Option {
+	return func(c *config) {
+		c.meta = append(c.meta, fn)
+	}
+}
+
+// WithHeaders copies the named HTTP headers into the request metadata,
+// for example "Authorization" for mcp.BearerTokenAuthenticator.
+func WithHeaders(names ...string) Option {
+	return WithRequestMeta(func(r *http.Request) protocol.RequestMeta {
+		meta := make(protocol.RequestMeta, len(names))
+		for _, name := range names {
+			if v := r.Header.Get(name); v != "" {
+				meta[name] = v
+			}
+		}
+		return meta
+	})
+}
+
+// Handler returns an http.Handler serving srv. It serves the MCP endpoint
+// at /mcp, server-sent events at /mcp/sse and a health check at /health.
+func Handler(srv *mcp.Server, opts ...Option) http.Handler {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	h := mcp.HTTPHandler(srv, cfg.httpOpts, cfg.serveOpts...)
+	if cfg.identity == nil && len(cfg.meta) == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := bridgeContext(r.Context(), r, cfg)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Mount serves srv under prefix on router, so the MCP endpoint is at
+// prefix+"/mcp". Routers with their own Mount method, such as chi, are
+// mounted with it.
+func Mount(router Router, prefix string, srv *mcp.Server, opts ...Option) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	h := Handler(srv, opts...)
+	if prefix != "" {
+		h = http.StripPrefix(prefix, h)
+	}
+
+	if m, ok := router.(mounter); ok {
+		m.Mount(prefixPattern(prefix), h)
+		return
+	}
+	router.Handle(prefix+"/", h)
+}
+
+// prefixPattern returns the pattern mounters expect for prefix.
+func prefixPattern(prefix string) string {
+	if prefix == "" {
+		return "/"
+	}
+	return prefix
+}
+
+// bridgeContext attaches the identity and request metadata for r to ctx.
+func bridgeContext(ctx context.Context, r *http.Request, cfg *config) context.Context {
+	if cfg.identity != nil {
+		if identity := cfg.identity(r); identity != nil {
+			ctx = mcp.ContextWithIdentity(ctx, identity)
+		}
+	}
+
+	if len(cfg.meta) > 0 {
+		meta := make(protocol.RequestMeta)
+		for k, v := range protocol.RequestMetaFromContext(ctx) {
+			meta[k] = v
+		}
+		for _, fn := range cfg.meta {
+			for k, v := range fn(r) {
+				meta[k] = v
+			}
+		}
+		ctx = protocol.ContextWithRequestMeta(ctx, meta)
+	}
+	return ctx
+}
@@ -0,0 +1,216 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go"
+	"github.com/felixgeelhaar/mcp-go/integration"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+type whoamiInput struct{}
+
+func newTestServer() *mcp.Server {
+	srv := mcp.NewServer(mcp.ServerInfo{
+		Name:         "integration-test",
+		Version:      "1.0.0",
+		Capabilities: mcp.Capabilities{Tools: true},
+	})
+	srv.Tool("whoami").
+		Description("Returns the caller").
+		Handler(func(ctx context.Context, _ whoamiInput) (string, error) {
+			name := "anonymous"
+			if identity := mcp.IdentityFromContext(ctx); identity != nil {
+				name = identity.Name
+			}
+			return name + " " + protocol.GetRequestMeta(ctx, "X-Tenant"), nil
+		})
+	return srv
+}
+
+// post sends a JSON-RPC message to url with the given headers.
+func post(t *testing.T, url string, header http.Header, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+// callWhoami initializes a session at url, posts a whoami tool call and
+// returns the result text.
+func callWhoami(t *testing.T, url string, header http.Header) string {
+	t.Helper()
+
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	resp := post(t, url, header, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`)
+	resp.Body.Close()
+	header.Set("Mcp-Session-Id", resp.Header.Get("Mcp-Session-Id"))
+	post(t, url, header, `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+
+	resp = post(t, url, header, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var rpc struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+		Error *protocol.Error `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpc); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if rpc.Error != nil {
+		t.Fatalf("unexpected error: %v", rpc.Error)
+	}
+	if len(rpc.Result.Content) != 1 {
+		t.Fatalf("expected 1 content block, got %d", len(rpc.Result.Content))
+	}
+	return rpc.Result.Content[0].Text
+}
+
+func TestMount_ServeMux(t *testing.T) {
+	mux := http.NewServeMux()
+	integration.Mount(mux, "/ai/", newTestServer())
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	if got := callWhoami(t, ts.URL+"/ai/mcp", nil); got != "anonymous " {
+		t.Errorf("whoami = %q, want %q", got, "anonymous ")
+	}
+
+	resp, err := http.Get(ts.URL + "/ai/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("health status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+// mountRouter records patterns like chi's Mount.
+type mountRouter struct {
+	*http.ServeMux
+	mounted []string
+}
+
+func (m *mountRouter) Mount(pattern string, h http.Handler) {
+	m.mounted = append(m.mounted, pattern)
+	m.Handle(pattern+"/", h)
+}
+
+func TestMount_UsesRouterMount(t *testing.T) {
+	router := &mountRouter{ServeMux: http.NewServeMux()}
+	integration.Mount(router, "/ai", newTestServer())
+
+	if len(router.mounted) != 1 || router.mounted[0] != "/ai" {
+		t.Fatalf("mounted = %v, want [/ai]", router.mounted)
+	}
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	if got := callWhoami(t, ts.URL+"/ai/mcp", nil); got != "anonymous " {
+		t.Errorf("whoami = %q, want %q", got, "anonymous ")
+	}
+}
+
+func TestHandler_Bridging(t *testing.T) {
+	// frameworkAuth stands in for a router's authentication middleware.
+	type userKey struct{}
+	frameworkAuth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := r.Header.Get("X-User"); user != "" {
+				r = r.WithContext(context.WithValue(r.Context(), userKey{}, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	h := integration.Handler(newTestServer(),
+		integration.WithIdentity(func(r *http.Request) *mcp.Identity {
+			user, ok := r.Context().Value(userKey{}).(string)
+			if !ok {
+				return nil
+			}
+			return &mcp.Identity{ID: user, Name: user}
+		}),
+		integration.WithHeaders("X-Tenant"),
+	)
+
+	ts := httptest.NewServer(frameworkAuth(h))
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{
+			name: "authenticated",
+			header: http.Header{
+				"X-User":   {"alice"},
+				"X-Tenant": {"acme"},
+			},
+			want: "alice acme",
+		},
+		{
+			name:   "unauthenticated",
+			header: http.Header{},
+			want:   "anonymous ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callWhoami(t, ts.URL+"/mcp", tt.header); got != tt.want {
+				t.Errorf("whoami = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_HeadersForAuthMiddleware(t *testing.T) {
+	h := integration.Handler(newTestServer(),
+		integration.WithHeaders("Authorization"),
+		integration.WithServeOptions(mcp.WithMiddleware(
+			mcp.Auth(mcp.BearerTokenAuthenticator(mcp.StaticTokens(map[string]*mcp.Identity{
+				"secret": {ID: "svc", Name: "service"},
+			}))),
+		)),
+	)
+
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	got := callWhoami(t, ts.URL+"/mcp", http.Header{"Authorization": {"Bearer secret"}})
+	if got != "service " {
+		t.Errorf("whoami = %q, want %q", got, "service ")
+	}
+}
@@ -29,13 +29,14 @@ package mcp
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/felixgeelhaar/mcp-go/middleware"
 	"github.com/felixgeelhaar/mcp-go/protocol"
 	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/server/handler"
 	"github.com/felixgeelhaar/mcp-go/transport"
 )
 
@@ -57,6 +58,69 @@ type Option = server.Option
 type ResourceContent = server.ResourceContent
 type ResourceInfo = server.ResourceInfo
 
+// Resource providers serve resource catalogs from external sources.
+// Register them with srv.AddResourceProvider.
+type ResourceProvider = server.ResourceProvider
+type ResourceEntry = server.ResourceEntry
+type ObjectStore = server.ObjectStore
+type ObjectInfo = server.ObjectInfo
+type HTTPProviderOption = server.HTTPProviderOption
+type FileSystemProvider = server.FileSystemProvider
+type FileSystemOption = server.FileSystemOption
+
+var (
+	NewFSProvider             = server.NewFSProvider
+	NewFileSystemProvider     = server.NewFileSystemProvider
+	WithFileSystemFS          = server.WithFileSystemFS
+	WithFileSystemMaxSize     = server.WithFileSystemMaxSize
+	WithFileSystemClientRoots = server.WithFileSystemClientRoots
+	WithFileSystemWatcher     = server.WithFileSystemWatcher
+	NewObjectStoreProvider    = server.NewObjectStoreProvider
+	NewHTTPProvider           = server.NewHTTPProvider
+	WithHTTPProviderClient    = server.WithHTTPProviderClient
+	WithHTTPProviderPaths     = server.WithHTTPProviderPaths
+	WithHTTPProviderMaxSize   = server.WithHTTPProviderMaxSize
+	ErrResourceNotFound       = server.ErrResourceNotFound
+)
+
+// Resource content helpers encode binary data and detect MIME types.
+var (
+	NewBlobContent      = server.NewBlobContent
+	NewReaderContent    = server.NewReaderContent
+	DetectMimeType      = server.DetectMimeType
+	ErrResourceTooLarge = server.ErrResourceTooLarge
+)
+
+// Resource read caching, see ResourceBuilder.Cache.
+type ResourceCache = server.ResourceCache
+type CachedResource = server.CachedResource
+type MemoryResourceCache = server.MemoryResourceCache
+
+var (
+	NewMemoryResourceCache = server.NewMemoryResourceCache
+	WithResourceCache      = server.WithResourceCache
+)
+
+// Streaming resources, see ResourceBuilder.StreamHandler.
+type ResourceStreamHandler = server.ResourceStreamHandler
+
+var WithStreamBufferLimit = server.WithStreamBufferLimit
+
+// Static resources, see Server.StaticResources.
+type StaticOption = server.StaticOption
+
+var WithMimeMap = server.WithMimeMap
+
+// File watching for resources, see ResourceBuilder.WatchFile.
+type FileWatcher = server.FileWatcher
+type FileWatcherFunc = server.FileWatcherFunc
+type PollingWatcher = server.PollingWatcher
+
+var (
+	NewPollingWatcher = server.NewPollingWatcher
+	WithFileWatcher   = server.WithFileWatcher
+)
+
 // Prompt types
 type PromptResult = server.PromptResult
 type PromptMessage = server.PromptMessage
@@ -64,6 +128,52 @@ type PromptArgument = server.PromptArgument
 type PromptInfo = server.PromptInfo
 type TextContent = server.TextContent
 type ImageContent = server.ImageContent
+type AudioContent = server.AudioContent
+type MessageBuilder = server.MessageBuilder
+
+// Messages creates a builder for the messages of a prompt result.
+//
+// Example:
+//
+//	msgs := mcp.Messages().
+//	    System("You are a code reviewer.").
+//	    User("Review this diff:\n" + args["diff"])
+//	return &mcp.PromptResult{Messages: msgs.Build()}, msgs.Err()
+func Messages() *MessageBuilder {
+	return server.Messages()
+}
+
+// Bulk tool registration, see Server.ToolsFromStruct.
+type ToolDescriber = server.ToolDescriber
+type StructOption = server.StructOption
+
+var (
+	WithToolDocs   = server.WithToolDocs
+	WithToolPrefix = server.WithToolPrefix
+)
+
+// PromptResultBuilder builds a prompt result fluently.
+type PromptResultBuilder = server.PromptResultBuilder
+
+// NewPromptResult creates a builder for a prompt result.
+//
+// Example:
+//
+//	return mcp.NewPromptResult().
+//	    System("You are a code reviewer.").
+//	    User("Review this diff:\n" + args["diff"]).
+//	    Build()
+func NewPromptResult() *PromptResultBuilder {
+	return server.NewPromptResult()
+}
+
+// Media helpers. ImageFromBytes and AudioFromBytes base64 encode raw data
+// for prompt messages and validate the MIME type.
+var (
+	ImageFromBytes   = server.ImageFromBytes
+	AudioFromBytes   = server.AudioFromBytes
+	ValidateMimeType = server.ValidateMimeType
+)
 
 // Progress types for streaming tool responses
 type ProgressToken = server.ProgressToken
@@ -75,6 +185,9 @@ type ToolAnnotations = server.ToolAnnotations
 type ResourceAnnotations = server.ResourceAnnotations
 type PromptAnnotations = server.PromptAnnotations
 
+// Icon is an image clients display next to a tool, resource or prompt
+type Icon = server.Icon
+
 // Helper functions for annotation values
 var (
 	Bool  = server.Bool
@@ -89,6 +202,8 @@ type CreateMessageRequest = server.CreateMessageRequest
 type CreateMessageResult = server.CreateMessageResult
 type ModelPreferences = server.ModelPreferences
 type ModelHint = server.ModelHint
+type SamplingTool = server.SamplingTool
+type ToolChoice = server.ToolChoice
 
 // Role constants
 const (
@@ -96,10 +211,30 @@ const (
 	RoleAssistant = server.RoleAssistant
 )
 
+// Sampling request and result values
+const (
+	IncludeContextNone       = server.IncludeContextNone
+	IncludeContextThisServer = server.IncludeContextThisServer
+	IncludeContextAllServers = server.IncludeContextAllServers
+
+	StopReasonEndTurn      = server.StopReasonEndTurn
+	StopReasonStopSequence = server.StopReasonStopSequence
+	StopReasonMaxTokens    = server.StopReasonMaxTokens
+	StopReasonToolUse      = server.StopReasonToolUse
+
+	ToolChoiceAuto     = server.ToolChoiceAuto
+	ToolChoiceRequired = server.ToolChoiceRequired
+	ToolChoiceNone     = server.ToolChoiceNone
+)
+
 // Content constructors
 var (
 	NewTextContent  = server.NewTextContent
 	NewImageContent = server.NewImageContent
+	NewAudioContent = server.NewAudioContent
+
+	NewToolUseContent    = server.NewToolUseContent
+	NewToolResultContent = server.NewToolResultContent
 )
 
 // Roots types for workspace awareness
@@ -138,6 +273,28 @@ var (
 	CancellationManagerFromContext = server.CancellationManagerFromContext
 )
 
+// Task types for long-running tool calls
+type TaskInfo = server.TaskInfo
+type TaskStatus = server.TaskStatus
+type TaskStore = server.TaskStore
+
+const (
+	TaskWorking   = server.TaskWorking
+	TaskCompleted = server.TaskCompleted
+	TaskFailed    = server.TaskFailed
+	TaskCancelled = server.TaskCancelled
+)
+
+// ErrTaskNotFound is returned by TaskStore.LoadTask for unknown tasks
+var ErrTaskNotFound = server.ErrTaskNotFound
+
+// Task options and context utilities
+var (
+	WithTaskStore     = server.WithTaskStore
+	WithTaskRetention = server.WithTaskRetention
+	TaskIDFromContext = server.TaskIDFromContext
+)
+
 // Subscription types for resource change notifications
 type SubscribeRequest = server.SubscribeRequest
 type UnsubscribeRequest = server.UnsubscribeRequest
@@ -146,6 +303,43 @@ type SubscriptionManager = server.SubscriptionManager
 
 var NewSubscriptionManager = server.NewSubscriptionManager
 
+// ListKind identifies the tools, resources or prompts list. Adding or
+// removing entries at runtime notifies connected sessions.
+type ListKind = server.ListKind
+
+const (
+	ListKindTools     = server.ListKindTools
+	ListKindResources = server.ListKindResources
+	ListKindPrompts   = server.ListKindPrompts
+)
+
+// Hook events. Register hooks with Server.OnToolCall, OnToolResult,
+// OnResourceRead, OnPromptGet and OnError to observe requests or reject
+// them without parsing JSON-RPC in middleware.
+type ToolCallEvent = server.ToolCallEvent
+type ToolResultEvent = server.ToolResultEvent
+type ResourceReadEvent = server.ResourceReadEvent
+type PromptGetEvent = server.PromptGetEvent
+type ErrorEvent = server.ErrorEvent
+
+// Subscription and session state storage
+type SubscriptionStore = server.SubscriptionStore
+type SessionStore = server.SessionStore
+type SessionState = server.SessionState
+type MemoryStore = server.MemoryStore
+type FileStore = server.FileStore
+
+// Storage constructors and options. The default stores keep state in
+// memory; use a FileStore or a custom implementation to keep state across
+// restarts or share it between instances.
+var (
+	NewMemoryStore        = server.NewMemoryStore
+	NewFileStore          = server.NewFileStore
+	WithSubscriptionStore = server.WithSubscriptionStore
+	WithSessionStore      = server.WithSessionStore
+	ErrSessionNotFound    = server.ErrSessionNotFound
+)
+
 // Completion types for autocomplete support
 type CompletionRef = server.CompletionRef
 type CompletionArgument = server.CompletionArgument
@@ -163,12 +357,19 @@ type NotificationSender = server.NotificationSender
 type ClientCapabilities = server.ClientCapabilities
 type RootsCapability = server.RootsCapability
 
+// ClientInfo describes the client of a connection, as negotiated in the
+// initialize request. Handlers read it with ClientInfoFromContext.
+type ClientInfo = server.ClientInfo
+
 var (
 	NewSession              = server.NewSession
 	WithClientCapabilities  = server.WithClientCapabilities
+	WithClientInfo          = server.WithClientInfo
 	WithRootsChangeCallback = server.WithRootsChangeCallback
 	ContextWithSession      = server.ContextWithSession
 	SessionFromContext      = server.SessionFromContext
+	ContextWithClientInfo   = server.ContextWithClientInfo
+	ClientInfoFromContext   = server.ClientInfoFromContext
 )
 
 // ExtractParams extracts URI template parameters into a typed struct.
@@ -208,20 +409,173 @@ func ExtractParams[T any](params map[string]string) (T, error) {
 //	})
 var ProgressFromContext = server.ProgressFromContext
 
+// WithResultMeta sets key to value in the _meta of the result a tool,
+// resource or prompt handler returns, such as a trace ID, a cache hint
+// or the cost of the request.
+//
+// Example:
+//
+//	srv.Tool("search").Handler(func(ctx context.Context, input SearchInput) (string, error) {
+//	    mcp.WithResultMeta(ctx, "traceId", traceID(ctx))
+//	    return search(ctx, input.Query)
+//	})
+func WithResultMeta(ctx context.Context, key string, value any) {
+	server.WithResultMeta(ctx, key, value)
+}
+
+// Transaction types for multi-step tool handlers
+type Transaction = server.Transaction
+type Compensation = server.Compensation
+
+// Tx returns the transaction for the current tool call.
+// Register compensation callbacks that undo partially-applied side effects;
+// they run automatically if the handler returns an error or the request
+// context is canceled.
+//
+// Example:
+//
+//	srv.Tool("transfer").Handler(func(ctx context.Context, input TransferInput) (string, error) {
+//	    tx := mcp.Tx(ctx)
+//	    if err := debit(ctx, input.From, input.Amount); err != nil {
+//	        return "", err
+//	    }
+//	    tx.OnRollback(func(ctx context.Context) error {
+//	        return credit(ctx, input.From, input.Amount)
+//	    })
+//	    return "ok", credit(ctx, input.To, input.Amount)
+//	})
+var Tx = server.TxFromContext
+
+// ToolError is a tool failure returned to the client as a result with
+// isError set, so the model can see it. Other errors returned by tool
+// handlers are sent as JSON-RPC errors.
+type ToolError = server.ToolError
+
+// ToolResult is a tool result with multiple content blocks, such as text,
+// images, audio and resources. Return it from a tool handler to send
+// non-text output.
+type ToolResult = server.ToolResult
+
+// NewToolResult creates an empty tool result.
+//
+// Example:
+//
+//	return mcp.NewToolResult().
+//	    Text("Here is the chart").
+//	    Image(png, "image/png"), nil
+var NewToolResult = server.NewToolResult
+
+// TruncateStrategy selects which part of oversized content TruncateContent
+// and TruncateText keep.
+type TruncateStrategy = server.TruncateStrategy
+
+const (
+	TruncateKeepStart = server.TruncateKeepStart
+	TruncateKeepEnd   = server.TruncateKeepEnd
+	TruncateKeepBoth  = server.TruncateKeepBoth
+)
+
+// Content size accounting and truncation, for keeping results within the
+// context budget of the host.
+var (
+	EstimateTokens  = server.EstimateTokens
+	ContentBytes    = server.ContentBytes
+	ContentTokens   = server.ContentTokens
+	TruncateText    = server.TruncateText
+	TruncateContent = server.TruncateContent
+)
+
+// Resource content for prompt messages and tool results.
+type EmbeddedResource = server.EmbeddedResource
+type ResourceLink = server.ResourceLink
+
+var (
+	NewEmbeddedResource = server.NewEmbeddedResource
+	NewResourceLink     = server.NewResourceLink
+)
+
+// Tool error constructors
+//
+// Example:
+//
+//	srv.Tool("read_file").Handler(func(input ReadInput) (string, error) {
+//	    data, err := os.ReadFile(input.Path)
+//	    if err != nil {
+//	        return "", mcp.ToolErrorf("cannot read %s: %w", input.Path, err)
+//	    }
+//	    return string(data), nil
+//	})
+var (
+	NewToolError = server.NewToolError
+	ToolErrorf   = server.ToolErrorf
+)
+
 // Middleware types
 type Middleware = middleware.Middleware
 type MiddlewareHandlerFunc = middleware.HandlerFunc
 type Logger = middleware.Logger
 type LogField = middleware.Field
 type RateLimitOption = middleware.RateLimitOption
+type RateLimitStore = middleware.RateLimitStore
+type RateLimitBucket = middleware.RateLimitBucket
+type RedisRateLimitStore = middleware.RedisRateLimitStore
+type RedisRateLimitOption = middleware.RedisRateLimitOption
+type RedisEvaler = middleware.RedisEvaler
+type RedisEvalFunc = middleware.RedisEvalFunc
 
 // RateLimit re-exports for convenience.
 var (
-	RateLimit            = middleware.RateLimit
-	RateLimitByMethod    = middleware.RateLimitByMethod
-	RateLimitByClient    = middleware.RateLimitByClient
-	WithRateLimitKeyFunc = middleware.WithRateLimitKeyFunc
-	WithRateLimitLogger  = middleware.WithRateLimitLogger
+	RateLimit               = middleware.RateLimit
+	RateLimitByMethod       = middleware.RateLimitByMethod
+	RateLimitByClient       = middleware.RateLimitByClient
+	RateLimitByIdentity     = middleware.RateLimitByIdentity
+	WithRateLimitKeyFunc    = middleware.WithRateLimitKeyFunc
+	WithRateLimitLogger     = middleware.WithRateLimitLogger
+	WithRateLimitStore      = middleware.WithRateLimitStore
+	WithRateLimitFailOpen   = middleware.WithRateLimitFailOpen
+	NewMemoryRateLimitStore = middleware.NewMemoryRateLimitStore
+	NewRedisRateLimitStore  = middleware.NewRedisRateLimitStore
+	WithRedisKeyPrefix      = middleware.WithRedisKeyPrefix
+	WithRedisTTL            = middleware.WithRedisTTL
+	WithRedisMaxRetries     = middleware.WithRedisMaxRetries
+)
+
+// Quota re-exports for convenience.
+type QuotaPolicy = middleware.QuotaPolicy
+type QuotaPeriod = middleware.QuotaPeriod
+type QuotaInfo = middleware.QuotaInfo
+type QuotaStore = middleware.QuotaStore
+type QuotaOption = middleware.QuotaOption
+type MemoryQuotaStore = middleware.MemoryQuotaStore
+type RedisQuotaStore = middleware.RedisQuotaStore
+type RedisQuotaOption = middleware.RedisQuotaOption
+
+const (
+	QuotaDaily   = middleware.QuotaDaily
+	QuotaMonthly = middleware.QuotaMonthly
+)
+
+var (
+	Quota                   = middleware.Quota
+	WithQuotaStore          = middleware.WithQuotaStore
+	WithQuotaMethods        = middleware.WithQuotaMethods
+	WithQuotaLocation       = middleware.WithQuotaLocation
+	WithQuotaLogger         = middleware.WithQuotaLogger
+	WithQuotaFailOpen       = middleware.WithQuotaFailOpen
+	NewMemoryQuotaStore     = middleware.NewMemoryQuotaStore
+	NewRedisQuotaStore      = middleware.NewRedisQuotaStore
+	WithRedisQuotaKeyPrefix = middleware.WithRedisQuotaKeyPrefix
+)
+
+// Timeout re-exports for convenience.
+type TimeoutOption = middleware.TimeoutOption
+
+var (
+	WithTimeoutAbandon = middleware.WithTimeoutAbandon
+	WithTimeoutLogger  = middleware.WithTimeoutLogger
+	WithClientTimeout  = middleware.WithClientTimeout
+	AbandonedHandlers  = middleware.AbandonedHandlers
+	Abandoned          = middleware.Abandoned
 )
 
 // SizeLimit re-exports for convenience.
@@ -232,6 +586,18 @@ var (
 	WithSizeLimitLogger = middleware.WithSizeLimitLogger
 )
 
+// DevLogger re-exports for convenience.
+type DevLoggerOption = middleware.DevLoggerOption
+
+var (
+	DevLogger              = middleware.DevLogger
+	WithDevLoggerOutput    = middleware.WithDevLoggerOutput
+	WithDevLoggerEnv       = middleware.WithDevLoggerEnv
+	WithDevLoggerEnabled   = middleware.WithDevLoggerEnabled
+	WithDevLoggerColor     = middleware.WithDevLoggerColor
+	WithDevLoggerMaxString = middleware.WithDevLoggerMaxString
+)
+
 // Size limit presets.
 const (
 	KB = middleware.KB
@@ -258,9 +624,87 @@ var (
 	ContextWithIdentity      = middleware.ContextWithIdentity
 )
 
+// Token introspection re-exports for convenience.
+type IntrospectionResponse = middleware.IntrospectionResponse
+type IntrospectionOption = middleware.IntrospectionOption
+
+var (
+	IntrospectionAuthenticator         = middleware.IntrospectionAuthenticator
+	WithIntrospectionClientCredentials = middleware.WithIntrospectionClientCredentials
+	WithIntrospectionClient            = middleware.WithIntrospectionClient
+	WithIntrospectionTimeout           = middleware.WithIntrospectionTimeout
+	WithIntrospectionCache             = middleware.WithIntrospectionCache
+	WithIntrospectionAudience          = middleware.WithIntrospectionAudience
+	WithIntrospectionIdentity          = middleware.WithIntrospectionIdentity
+)
+
+// RBAC re-exports for convenience.
+type RBACPolicy = middleware.RBACPolicy
+type RBACOption = middleware.RBACOption
+type Grants = middleware.Grants
+type Access = middleware.Access
+type AccessKind = middleware.AccessKind
+
+const (
+	AccessTool     = middleware.AccessTool
+	AccessResource = middleware.AccessResource
+	AccessPrompt   = middleware.AccessPrompt
+)
+
+var (
+	RBAC           = middleware.RBAC
+	WithRBACLogger = middleware.WithRBACLogger
+	IdentityRoles  = middleware.IdentityRoles
+	FilterList     = middleware.FilterList
+)
+
+// Authorize re-exports for convenience.
+type AuthorizePolicy = middleware.AuthorizePolicy
+type AuthorizeRule = middleware.AuthorizeRule
+type AuthorizeOption = middleware.AuthorizeOption
+type Effect = middleware.Effect
+
+const (
+	AccessMethod = middleware.AccessMethod
+	EffectNone   = middleware.EffectNone
+	EffectAllow  = middleware.EffectAllow
+	EffectDeny   = middleware.EffectDeny
+)
+
+var (
+	Authorize                = middleware.Authorize
+	WithAuthorizeLogger      = middleware.WithAuthorizeLogger
+	WithAuthorizeSkipMethods = middleware.WithAuthorizeSkipMethods
+)
+
+// Retry re-exports for convenience.
+type RetryPolicy = middleware.RetryPolicy
+type RetryOption = middleware.RetryOption
+
+var (
+	Retry             = middleware.Retry
+	WithRetryLogger   = middleware.WithRetryLogger
+	DefaultIdempotent = middleware.DefaultIdempotent
+	DefaultRetryable  = middleware.DefaultRetryable
+)
+
+// Dedup re-exports for convenience.
+type DedupOption = middleware.DedupOption
+
+var (
+	Dedup            = middleware.Dedup
+	WithDedupKeyFunc = middleware.WithDedupKeyFunc
+	WithDedupTools   = middleware.WithDedupTools
+	DedupKey         = middleware.DedupKey
+)
+
 // HTTPOption configures the HTTP transport.
 type HTTPOption = transport.HTTPOption
 
+// HTTPSessionStore records the sessions issued by the HTTP transport.
+// MemoryStore and FileStore implement it.
+type HTTPSessionStore = transport.SessionStore
+
 // CORS configuration for HTTP transports.
 type CORSConfig = transport.CORSConfig
 
@@ -287,6 +731,7 @@ type ServeOption func(*serveOptions)
 type serveOptions struct {
 	middleware []Middleware
 	logger     Logger
+	stdioOpts  []transport.StdioOption
 }
 
 // WithMiddleware adds middleware to the request handling chain.
@@ -303,6 +748,79 @@ func WithLogger(l Logger) ServeOption {
 	}
 }
 
+// WireLogOption configures wire-level frame logging.
+type WireLogOption = transport.WireLogOption
+
+// Wire logging options.
+var (
+	WithWireRedactKeys = transport.WithWireRedactKeys
+	WithWireRedactFunc = transport.WithWireRedactFunc
+	WithWireMaxBytes   = transport.WithWireMaxBytes
+)
+
+// WithWireLogger dumps every raw frame exchanged over stdio to w.
+// Pair it with os.Stderr or a file when debugging a host integration:
+//
+//	mcp.ServeStdio(ctx, srv, mcp.WithWireLogger(os.Stderr,
+//	    mcp.WithWireRedactKeys("token", "password"),
+//	    mcp.WithWireMaxBytes(4096),
+//	))
+func WithWireLogger(w io.Writer, opts ...WireLogOption) ServeOption {
+	return func(o *serveOptions) {
+		o.stdioOpts = append(o.stdioOpts, transport.WithWireLogger(w, opts...))
+	}
+}
+
+// ConnectionInfo describes the connection a request arrived on.
+type ConnectionInfo = transport.ConnectionInfo
+
+// ConnContextFunc derives the base context for a connection.
+type ConnContextFunc = transport.ConnContextFunc
+
+// Transport names returned by TransportFromContext.
+const (
+	TransportStdio     = transport.TransportStdio
+	TransportHTTP      = transport.TransportHTTP
+	TransportWebSocket = transport.TransportWebSocket
+)
+
+// TransportFromContext returns the name of the transport a request arrived
+// on. Use it in middleware for transport-specific behavior:
+//
+//	func(next mcp.MiddlewareHandlerFunc) mcp.MiddlewareHandlerFunc {
+//	    return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+//	        if mcp.TransportFromContext(ctx) == mcp.TransportHTTP {
+//	            // only authenticate remote clients
+//	        }
+//	        return next(ctx, req)
+//	    }
+//	}
+func TransportFromContext(ctx context.Context) string {
+	return transport.TransportFromContext(ctx)
+}
+
+// ConnectionInfoFromContext returns the connection info from context.
+func ConnectionInfoFromContext(ctx context.Context) (ConnectionInfo, bool) {
+	return transport.ConnectionInfoFromContext(ctx)
+}
+
+// WithConnContext seeds the base context of the stdio connection with fn.
+func WithConnContext(fn ConnContextFunc) ServeOption {
+	return func(o *serveOptions) {
+		o.stdioOpts = append(o.stdioOpts, transport.WithConnContext(fn))
+	}
+}
+
+// WithHTTPConnContext seeds the base context of each HTTP request with fn.
+func WithHTTPConnContext(fn ConnContextFunc) HTTPOption {
+	return transport.WithHTTPConnContext(fn)
+}
+
+// WithWebSocketConnContext seeds the base context of each WebSocket connection with fn.
+func WithWebSocketConnContext(fn ConnContextFunc) WebSocketOption {
+	return transport.WithWebSocketConnContext(fn)
+}
+
 // NewServer creates a new MCP server with the given info and options.
 func NewServer(info ServerInfo, opts ...Option) *Server {
 	return server.New(info, opts...)
@@ -312,10 +830,123 @@ func NewServer(info ServerInfo, opts ...Option) *Server {
 // about how to use this server effectively.
 var WithInstructions = server.WithInstructions
 
+// WithCapabilities pins the capabilities advertised to clients. By default
+// they are detected from the registered tools, resources, prompts and
+// completions; see Server.Capabilities.
+var WithCapabilities = server.WithCapabilities
+
+// WithStrictClientCompliance rejects clients that skip initialize, send
+// requests before the initialized notification, or send unknown
+// notifications. Use Server.ComplianceStats to inspect violation counts.
+var WithStrictClientCompliance = server.WithStrictClientCompliance
+
+// WithLifecycleEnforcement controls whether requests sent before the client
+// completes the initialize handshake are rejected. Enabled by default.
+var WithLifecycleEnforcement = server.WithLifecycleEnforcement
+
+// ComplianceStats counts client protocol violations by type.
+type ComplianceStats = server.ComplianceStats
+
+// WithMaxSessionsPerIdentity limits concurrent sessions per authenticated
+// identity on HTTP and WebSocket transports. Requires the Auth middleware.
+var WithMaxSessionsPerIdentity = server.WithMaxSessionsPerIdentity
+
+// WithSessionEviction evicts the oldest session of an identity at its limit
+// instead of rejecting the new one, calling the hook for each eviction.
+var WithSessionEviction = server.WithSessionEviction
+
+// SessionLimitStats counts rejected and evicted sessions.
+type SessionLimitStats = server.SessionLimitStats
+
+// SessionEvictedFunc is called when a session is evicted.
+type SessionEvictedFunc = server.SessionEvictedFunc
+
+// WithPingInterval pings HTTP and WebSocket sessions at the given interval
+// and closes those that miss the given number of pings in a row.
+// Use Server.SessionLiveness and Server.LivenessStats to inspect them.
+var WithPingInterval = server.WithPingInterval
+
+// WithIdleTimeout closes HTTP and WebSocket sessions whose client has sent
+// no request for the given duration, releasing their subscriptions and
+// stored state. Server.OnSessionExpired runs code as they close.
+var WithIdleTimeout = server.WithIdleTimeout
+
+// SessionExpiredEvent describes a session closed by the idle timeout.
+type SessionExpiredEvent = server.SessionExpiredEvent
+
+// LivenessStats counts pings sent and missed, and sessions closed as
+// unresponsive or idle.
+type LivenessStats = server.LivenessStats
+
+// SessionLiveness describes when a session was last heard from.
+type SessionLiveness = server.SessionLiveness
+
+// WithPageSize sets the maximum number of items per page for list methods.
+// Clients follow nextCursor to fetch subsequent pages.
+var WithPageSize = server.WithPageSize
+
+// ListOrder controls the order of items in list results.
+type ListOrder = server.ListOrder
+
+// List orders.
+const (
+	ListOrderName         = server.ListOrderName
+	ListOrderRegistration = server.ListOrderRegistration
+)
+
+// WithListOrder sets the order of tools, resources and prompts in list
+// results: sorted by name (the default) or in registration order.
+var WithListOrder = server.WithListOrder
+
+// WithDiagnosticsTools registers hidden mcp.echo, mcp.sleep and mcp.fail
+// tools for smoke testing deployments. They are listed only in debug mode.
+var WithDiagnosticsTools = server.WithDiagnosticsTools
+
+// WithDebug enables debug mode, which lists hidden tools in tools/list.
+var WithDebug = server.WithDebug
+
+// WithInputValidation validates the arguments of every tool call against
+// the tool's input schema, rejecting invalid ones with the path of each
+// invalid field.
+var WithInputValidation = server.WithInputValidation
+
+// WithSchemaOptions applies schema generation options, such as
+// schema.NoAdditionalProperties, to the input schemas of tool handlers.
+var WithSchemaOptions = server.WithSchemaOptions
+
+// WithOutputValidation checks the results of every tool that declares an
+// output schema before they are sent. Mismatches fail the call in debug
+// mode and are reported to the OnError hooks otherwise.
+var WithOutputValidation = server.WithOutputValidation
+
+// ValidationErrorData is the data of the error returned for tool arguments
+// that fail schema validation.
+type ValidationErrorData = server.ValidationErrorData
+
+// TrailingSlashPolicy controls how trailing slashes in resource URIs are matched.
+type TrailingSlashPolicy = server.TrailingSlashPolicy
+
+// Trailing slash policies.
+const (
+	TrailingSlashStrip = server.TrailingSlashStrip
+	TrailingSlashKeep  = server.TrailingSlashKeep
+)
+
+// WithTrailingSlashPolicy sets how trailing slashes in resource URIs are matched.
+var WithTrailingSlashPolicy = server.WithTrailingSlashPolicy
+
+// CanonicalURI normalizes a resource URI the way the server does before matching.
+var CanonicalURI = server.CanonicalURI
+
 // ServeStdio runs the server using stdio transport.
 // This blocks until the context is canceled or an error occurs.
 func ServeStdio(ctx context.Context, srv *Server, opts ...ServeOption) error {
-	t := transport.NewStdio()
+	options := &serveOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	t := transport.NewStdio(options.stdioOpts...)
 	handler := newRequestHandler(srv, opts...)
 	return t.Serve(ctx, handler)
 }
@@ -335,6 +966,16 @@ func ServeHTTPWithMiddleware(ctx context.Context, srv *Server, addr string, http
 	return t.Serve(ctx, handler)
 }
 
+// HTTPHandler returns an http.Handler serving srv, for mounting into an
+// existing HTTP server or router. It serves the MCP endpoint at /mcp,
+// server-sent events at /mcp/sse and a health check at /health; read and
+// write timeouts are left to the enclosing http.Server.
+func HTTPHandler(srv *Server, httpOpts []HTTPOption, serveOpts ...ServeOption) http.Handler {
+	t := transport.NewHTTP("", httpOpts...)
+	handler := newRequestHandler(srv, serveOpts...)
+	return t.Handler(handler)
+}
+
 // WithReadTimeout sets the read timeout for HTTP requests.
 func WithReadTimeout(d time.Duration) HTTPOption {
 	return transport.WithReadTimeout(d)
@@ -345,6 +986,27 @@ func WithWriteTimeout(d time.Duration) HTTPOption {
 	return transport.WithWriteTimeout(d)
 }
 
+// WithHTTPWireLogger dumps every raw HTTP request body, response body and
+// SSE message to w.
+func WithHTTPWireLogger(w io.Writer, opts ...WireLogOption) HTTPOption {
+	return transport.WithHTTPWireLogger(w, opts...)
+}
+
+// WithHTTPSessionStore sets the store recording the sessions issued by the
+// HTTP transport. Requests with an unknown session ID get 404 Not Found.
+// To run several instances behind a load balancer, pass the same shared
+// store to WithSessionStore, so an instance resumes sessions initialized
+// on another one.
+//
+// Example:
+//
+//	store := redisstore.New(client)
+//	srv := mcp.NewServer(info, mcp.WithSessionStore(store))
+//	mcp.ServeHTTP(ctx, srv, ":8080", mcp.WithHTTPSessionStore(store))
+func WithHTTPSessionStore(store HTTPSessionStore) HTTPOption {
+	return transport.WithHTTPSessionStore(store)
+}
+
 // WebSocketOption configures the WebSocket transport.
 type WebSocketOption = transport.WebSocketOption
 
@@ -373,6 +1035,11 @@ func WithWebSocketWriteTimeout(d time.Duration) WebSocketOption {
 	return transport.WithWebSocketWriteTimeout(d)
 }
 
+// WithWebSocketWireLogger dumps every raw WebSocket frame to w.
+func WithWebSocketWireLogger(w io.Writer, opts ...WireLogOption) WebSocketOption {
+	return transport.WithWebSocketWireLogger(w, opts...)
+}
+
 // Middleware re-exports
 
 // Chain composes multiple middleware into a single middleware.
@@ -380,19 +1047,66 @@ func Chain(middlewares ...Middleware) Middleware {
 	return middleware.Chain(middlewares...)
 }
 
+// Composition re-exports for convenience.
+type Stack = middleware.Stack
+
+const (
+	StageRecover       = middleware.StageRecover
+	StageObservability = middleware.StageObservability
+	StageAuth          = middleware.StageAuth
+	StageLimits        = middleware.StageLimits
+)
+
+var (
+	If         = middleware.If
+	ForMethods = middleware.ForMethods
+	NewStack   = middleware.NewStack
+)
+
+// Rewrite re-exports for convenience.
+type Rewriter = middleware.Rewriter
+
+var (
+	Rewrite              = middleware.Rewrite
+	RenameMethod         = middleware.RenameMethod
+	AliasTool            = middleware.AliasTool
+	DefaultToolArguments = middleware.DefaultToolArguments
+)
+
+// PatchToolArguments returns a Rewriter that decodes the arguments of calls
+// of the tools matching tool into a T and lets fn change them.
+func PatchToolArguments[T any](tool string, fn func(ctx context.Context, args *T) error) Rewriter {
+	return middleware.PatchToolArguments(tool, fn)
+}
+
 // Recover returns middleware that catches panics and converts them to internal errors.
-func Recover() Middleware {
-	return middleware.Recover()
+func Recover(opts ...RecoverOption) Middleware {
+	return middleware.Recover(opts...)
 }
 
 // RecoverWithHandler returns middleware that catches panics and calls the provided handler.
-func RecoverWithHandler(handler func(ctx context.Context, req *protocol.Request, panicVal any) (*protocol.Response, error)) Middleware {
-	return middleware.RecoverWithHandler(handler)
+func RecoverWithHandler(handler func(ctx context.Context, req *protocol.Request, panicVal any) (*protocol.Response, error), opts ...RecoverOption) Middleware {
+	return middleware.RecoverWithHandler(handler, opts...)
 }
 
+// Recover re-exports for convenience.
+type RecoverOption = middleware.RecoverOption
+type PanicReporter = middleware.PanicReporter
+
+var (
+	WithRecoverLogger = middleware.WithRecoverLogger
+	WithOnPanic       = middleware.WithOnPanic
+)
+
 // Timeout returns middleware that enforces a request deadline.
-func Timeout(d time.Duration) Middleware {
-	return middleware.Timeout(d)
+func Timeout(d time.Duration, opts ...TimeoutOption) Middleware {
+	return middleware.Timeout(d, opts...)
+}
+
+// JoinErrors combines a middleware error with the error of the handler it
+// wraps. The middleware error determines the protocol error code.
+func JoinErrors(middlewareErr, handlerErr error) error {
+	return middleware.JoinErrors(middlewareErr, handlerErr)
 }
 
 // RequestID returns middleware that injects a unique request ID into the context.
@@ -406,10 +1120,25 @@ func RequestIDFromContext(ctx context.Context) string {
 }
 
 // Logging returns middleware that logs request details.
-func Logging(logger Logger) Middleware {
-	return middleware.Logging(logger)
+func Logging(logger Logger, opts ...LoggingOption) Middleware {
+	return middleware.Logging(logger, opts...)
 }
 
+// Logging and redaction re-exports for convenience.
+type LoggingOption = middleware.LoggingOption
+type Redactor = middleware.Redactor
+
+var (
+	WithLogParams         = middleware.WithLogParams
+	WithLogRedactor       = middleware.WithLogRedactor
+	WithLogResponse       = middleware.WithLogResponse
+	WithLogResponseSize   = middleware.WithLogResponseSize
+	WithLogSampling       = middleware.WithLogSampling
+	WithLogSlowThreshold  = middleware.WithLogSlowThreshold
+	WithDevLoggerRedactor = middleware.WithDevLoggerRedactor
+	DefaultRedactor       = middleware.DefaultRedactor
+)
+
 // DefaultMiddleware returns the recommended production middleware stack.
 func DefaultMiddleware(logger Logger) []Middleware {
 	return middleware.DefaultStack(logger)
@@ -439,320 +1168,11 @@ var (
 	SetSpanAttribute    = middleware.SetSpanAttribute
 )
 
-// requestHandler adapts Server to transport.Handler
-type requestHandler struct {
-	srv        *Server
-	handleFunc middleware.HandlerFunc
-}
-
-func newRequestHandler(srv *Server, opts ...ServeOption) *requestHandler {
+// newRequestHandler returns the handler serving srv on a transport.
+func newRequestHandler(srv *Server, opts ...ServeOption) *handler.Handler {
 	options := &serveOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
-
-	h := &requestHandler{srv: srv}
-
-	// Build the handler function
-	baseHandler := middleware.HandlerFunc(h.handle)
-
-	// Apply middleware if any
-	if len(options.middleware) > 0 {
-		h.handleFunc = middleware.Chain(options.middleware...)(baseHandler)
-	} else {
-		h.handleFunc = baseHandler
-	}
-
-	return h
-}
-
-func (h *requestHandler) HandleRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	return h.handleFunc(ctx, req)
-}
-
-func (h *requestHandler) handle(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	switch req.Method {
-	case protocol.MethodInitialize:
-		return h.handleInitialize(req)
-	case protocol.MethodToolsList:
-		return h.handleToolsList(req)
-	case protocol.MethodToolsCall:
-		return h.handleToolsCall(ctx, req)
-	case protocol.MethodResourcesList:
-		return h.handleResourcesList(req)
-	case protocol.MethodResourcesRead:
-		return h.handleResourcesRead(ctx, req)
-	case protocol.MethodPromptsList:
-		return h.handlePromptsList(req)
-	case protocol.MethodPromptsGet:
-		return h.handlePromptsGet(ctx, req)
-	case protocol.MethodPing:
-		return h.handlePing(req)
-	default:
-		return nil, protocol.NewMethodNotFound(req.Method)
-	}
-}
-
-func (h *requestHandler) handleInitialize(req *protocol.Request) (*protocol.Response, error) {
-	manifest := h.srv.Manifest()
-
-	// Build capabilities based on what's registered
-	capabilities := make(map[string]any)
-
-	if manifest.Capabilities.Tools {
-		capabilities["tools"] = map[string]any{}
-	}
-	if manifest.Capabilities.Resources {
-		capabilities["resources"] = map[string]any{}
-	}
-	if manifest.Capabilities.Prompts {
-		capabilities["prompts"] = map[string]any{}
-	}
-
-	result := map[string]any{
-		"protocolVersion": manifest.ProtocolVersion,
-		"serverInfo": map[string]any{
-			"name":    manifest.Name,
-			"version": manifest.Version,
-		},
-		"capabilities": capabilities,
-	}
-
-	// Include instructions if set
-	if instructions := h.srv.Instructions(); instructions != "" {
-		result["instructions"] = instructions
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handleToolsList(req *protocol.Request) (*protocol.Response, error) {
-	tools := h.srv.Tools()
-
-	toolList := make([]map[string]any, 0, len(tools))
-	for _, t := range tools {
-		item := map[string]any{
-			"name":        t.Name,
-			"description": t.Description,
-			"inputSchema": t.InputSchema,
-		}
-		if t.Annotations != nil {
-			item["annotations"] = t.Annotations
-		}
-		toolList = append(toolList, item)
-	}
-
-	result := map[string]any{
-		"tools": toolList,
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handleToolsCall(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	// Parse params
-	var params struct {
-		Name      string          `json:"name"`
-		Arguments json.RawMessage `json:"arguments"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	// Get tool
-	tool, ok := h.srv.GetTool(params.Name)
-	if !ok {
-		return nil, protocol.NewNotFound("tool not found: " + params.Name)
-	}
-
-	// Set up progress reporting if token is present
-	progressToken := server.ExtractProgressToken(req.Params)
-	if progressToken != "" {
-		if sender := transport.NotificationSenderFromContext(ctx); sender != nil {
-			// Adapt transport.NotificationSender to server.NotificationSender
-			reporter := server.NewProgressReporter(progressToken, &notificationAdapter{sender})
-			ctx = server.ContextWithProgress(ctx, reporter)
-		}
-	}
-
-	// Execute tool
-	result, err := tool.Execute(ctx, params.Arguments)
-	if err != nil {
-		// Check if it's already an MCP error
-		var mcpErr *protocol.Error
-		if errors.As(err, &mcpErr) {
-			return nil, mcpErr
-		}
-		return nil, protocol.NewInternalError(err.Error())
-	}
-
-	// Format result
-	response := map[string]any{
-		"content": []map[string]any{
-			{
-				"type": "text",
-				"text": result,
-			},
-		},
-	}
-
-	return protocol.NewResponse(req.ID, response), nil
-}
-
-func (h *requestHandler) handleResourcesList(req *protocol.Request) (*protocol.Response, error) {
-	resources := h.srv.Resources()
-
-	resourceList := make([]map[string]any, 0, len(resources))
-	for _, r := range resources {
-		item := map[string]any{
-			"uri":  r.URITemplate,
-			"name": r.Name,
-		}
-		if r.Description != "" {
-			item["description"] = r.Description
-		}
-		if r.MimeType != "" {
-			item["mimeType"] = r.MimeType
-		}
-		if r.Annotations != nil {
-			item["annotations"] = r.Annotations
-		}
-		resourceList = append(resourceList, item)
-	}
-
-	result := map[string]any{
-		"resources": resourceList,
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handleResourcesRead(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	// Parse params
-	var params struct {
-		URI string `json:"uri"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	// Find resource that matches the URI
-	resource, ok := h.srv.FindResourceForURI(params.URI)
-	if !ok {
-		return nil, protocol.NewNotFound("resource not found: " + params.URI)
-	}
-
-	// Read resource
-	content, err := resource.Read(ctx, params.URI)
-	if err != nil {
-		var mcpErr *protocol.Error
-		if errors.As(err, &mcpErr) {
-			return nil, mcpErr
-		}
-		return nil, protocol.NewInternalError(err.Error())
-	}
-
-	result := map[string]any{
-		"contents": []map[string]any{
-			{
-				"uri":      content.URI,
-				"mimeType": content.MimeType,
-				"text":     content.Text,
-			},
-		},
-	}
-
-	// Include blob if present
-	if content.Blob != "" {
-		result["contents"].([]map[string]any)[0]["blob"] = content.Blob
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handlePromptsList(req *protocol.Request) (*protocol.Response, error) {
-	prompts := h.srv.Prompts()
-
-	promptList := make([]map[string]any, 0, len(prompts))
-	for _, p := range prompts {
-		item := map[string]any{
-			"name": p.Name,
-		}
-		if p.Description != "" {
-			item["description"] = p.Description
-		}
-		if len(p.Arguments) > 0 {
-			args := make([]map[string]any, 0, len(p.Arguments))
-			for _, arg := range p.Arguments {
-				argItem := map[string]any{
-					"name":     arg.Name,
-					"required": arg.Required,
-				}
-				if arg.Description != "" {
-					argItem["description"] = arg.Description
-				}
-				args = append(args, argItem)
-			}
-			item["arguments"] = args
-		}
-		if p.Annotations != nil {
-			item["annotations"] = p.Annotations
-		}
-		promptList = append(promptList, item)
-	}
-
-	result := map[string]any{
-		"prompts": promptList,
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handlePromptsGet(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	// Parse params
-	var params struct {
-		Name      string            `json:"name"`
-		Arguments map[string]string `json:"arguments"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	// Get prompt
-	prompt, ok := h.srv.GetPrompt(params.Name)
-	if !ok {
-		return nil, protocol.NewNotFound("prompt not found: " + params.Name)
-	}
-
-	// Execute prompt
-	result, err := prompt.Get(ctx, params.Arguments)
-	if err != nil {
-		var mcpErr *protocol.Error
-		if errors.As(err, &mcpErr) {
-			return nil, mcpErr
-		}
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	response := map[string]any{
-		"messages": result.Messages,
-	}
-	if result.Description != "" {
-		response["description"] = result.Description
-	}
-
-	return protocol.NewResponse(req.ID, response), nil
-}
-
-func (h *requestHandler) handlePing(req *protocol.Request) (*protocol.Response, error) {
-	return protocol.NewResponse(req.ID, map[string]any{}), nil
-}
-
-// notificationAdapter adapts transport.NotificationSender to server.NotificationSender.
-type notificationAdapter struct {
-	sender transport.NotificationSender
-}
-
-func (a *notificationAdapter) SendNotification(method string, params any) error {
-	return a.sender.SendNotification(method, params)
+	return handler.New(srv, handler.WithMiddleware(options.middleware...))
 }
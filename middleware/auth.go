@@ -167,29 +167,29 @@ func APIKeyAuthenticator(headerName string, keyValidator func(key string) *Ident
 // The tokenValidator function should return the identity for a valid token, or nil for invalid.
 func BearerTokenAuthenticator(tokenValidator func(token string) *Identity) Authenticator {
 	return func(ctx context.Context, req *protocol.Request) (*Identity, error) {
-		auth := protocol.GetRequestMeta(ctx, "Authorization")
-		if auth == "" {
-			auth = protocol.GetRequestMeta(ctx, "authorization")
-		}
-		if auth == "" {
-			return nil, nil
-		}
-
-		// Parse "Bearer <token>"
-		const prefix = "Bearer "
-		if !strings.HasPrefix(auth, prefix) {
-			return nil, nil
-		}
-
-		token := strings.TrimPrefix(auth, prefix)
+		token := bearerToken(ctx)
 		if token == "" {
 			return nil, nil
 		}
-
 		return tokenValidator(token), nil
 	}
 }
 
+// bearerToken returns the token of the "Bearer <token>" Authorization
+// header of the request of ctx, or "".
+func bearerToken(ctx context.Context) string {
+	auth := protocol.GetRequestMeta(ctx, "Authorization")
+	if auth == "" {
+		auth = protocol.GetRequestMeta(ctx, "authorization")
+	}
+
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(auth, prefix)
+}
+
 // StaticAPIKeys creates a simple key validator from a map of key -> identity.
 func StaticAPIKeys(keys map[string]*Identity) func(string) *Identity {
 	return func(key string) *Identity {
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Effect is the outcome of an authorization decision.
+type Effect int
+
+// Authorization effects.
+const (
+	// EffectNone leaves the decision to the next step of the policy.
+	EffectNone Effect = iota
+	// EffectAllow permits the access.
+	EffectAllow
+	// EffectDeny rejects the access, whatever else allows it.
+	EffectDeny
+)
+
+// AuthorizeRule allows or denies the methods, tools, resources and prompts
+// it lists to the identities with its roles. Entries may contain "*" to
+// match any sequence of characters, as in "resources/*" or "db_*".
+type AuthorizeRule struct {
+	// Roles are the roles the rule applies to. "*" applies it to every
+	// authenticated identity; no roles apply it to every request, including
+	// unauthenticated ones.
+	Roles []string
+
+	// Deny makes the rule reject what it matches instead of allowing it.
+	Deny bool
+
+	// Methods are the JSON-RPC methods the rule matches, such as
+	// "tools/call" or "resources/subscribe".
+	Methods []string
+
+	// Tools, Resources and Prompts are the names, URIs and URI templates
+	// the rule matches, in calls and reads as well as in list results.
+	Tools     []string
+	Resources []string
+	Prompts   []string
+}
+
+// appliesTo reports whether the rule applies to identity, which has roles.
+func (r *AuthorizeRule) appliesTo(identity *Identity, roles []string) bool {
+	if len(r.Roles) == 0 {
+		return true
+	}
+	if identity == nil {
+		return false
+	}
+	return slices.ContainsFunc(r.Roles, func(role string) bool {
+		return role == "*" || slices.Contains(roles, role)
+	})
+}
+
+// matches reports whether the rule lists access.
+func (r *AuthorizeRule) matches(access Access) bool {
+	var patterns []string
+	switch access.Kind {
+	case AccessMethod:
+		patterns = r.Methods
+	case AccessTool:
+		patterns = r.Tools
+	case AccessResource:
+		patterns = r.Resources
+	case AccessPrompt:
+		patterns = r.Prompts
+	}
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return matchGrant(pattern, access.Name)
+	})
+}
+
+// AuthorizePolicy decides which requests an identity may make. A request
+// must be allowed its method and, for tools/call, resources/read and
+// prompts/get, the tool, resource or prompt it uses. Deny rules win over
+// allow rules; an access no rule matches is decided by DefaultAllow.
+type AuthorizePolicy struct {
+	// Rules are the allow and deny rules of the policy.
+	Rules []AuthorizeRule
+
+	// DefaultAllow allows accesses that no rule matches. By default they
+	// are denied, so every method a client needs, such as "tools/list",
+	// and every tool, resource and prompt it uses must be allowed by a
+	// rule.
+	DefaultAllow bool
+
+	// RolesFunc returns the roles of an identity. It defaults to
+	// IdentityRoles.
+	RolesFunc func(identity *Identity) []string
+
+	// Check, if set, decides an access before the rules, for decisions
+	// that do not fit them, such as ones based on the arguments or on
+	// identity metadata. Returning EffectNone leaves the access to the
+	// rules. It receives a nil identity for unauthenticated requests.
+	Check func(ctx context.Context, identity *Identity, access Access) Effect
+}
+
+// Allowed reports whether identity may perform access under the policy.
+func (p *AuthorizePolicy) Allowed(ctx context.Context, identity *Identity, access Access) bool {
+	switch p.decide(ctx, identity, access) {
+	case EffectAllow:
+		return true
+	case EffectDeny:
+		return false
+	}
+	return p.DefaultAllow
+}
+
+// decide returns the effect of the policy on a single access.
+func (p *AuthorizePolicy) decide(ctx context.Context, identity *Identity, access Access) Effect {
+	if p.Check != nil {
+		if effect := p.Check(ctx, identity, access); effect != EffectNone {
+			return effect
+		}
+	}
+
+	rolesFunc := p.RolesFunc
+	if rolesFunc == nil {
+		rolesFunc = IdentityRoles
+	}
+	var roles []string
+	if identity != nil {
+		roles = rolesFunc(identity)
+	}
+
+	effect := EffectNone
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.appliesTo(identity, roles) || !rule.matches(access) {
+			continue
+		}
+		if rule.Deny {
+			return EffectDeny
+		}
+		effect = EffectAllow
+	}
+	return effect
+}
+
+// AuthorizeOption configures the Authorize middleware.
+type AuthorizeOption func(*authorizeConfig)
+
+type authorizeConfig struct {
+	logger      Logger
+	skipMethods map[string]bool
+}
+
+// WithAuthorizeLogger sets the logger for denied requests.
+func WithAuthorizeLogger(l Logger) AuthorizeOption {
+	return func(c *authorizeConfig) {
+		c.logger = l
+	}
+}
+
+// WithAuthorizeSkipMethods specifies methods that are not checked. By
+// default, "initialize" and "ping" are always skipped, as Auth does.
+func WithAuthorizeSkipMethods(methods ...string) AuthorizeOption {
+	return func(c *authorizeConfig) {
+		for _, m := range methods {
+			c.skipMethods[m] = true
+		}
+	}
+}
+
+// Authorize returns middleware that enforces policy on the identity set by
+// Auth, so it must come after Auth in the chain. Requests the policy does
+// not allow are rejected with a forbidden error (CodeForbidden), and list
+// results only include the tools, resources and prompts the identity may
+// use. Notifications are not checked.
+//
+// Example:
+//
+//	policy := &middleware.AuthorizePolicy{
+//	    Rules: []middleware.AuthorizeRule{
+//	        {Roles: []string{"*"}, Methods: []string{"*/list", "tools/call", "resources/read"}},
+//	        {Roles: []string{"*"}, Tools: []string{"search", "fetch_*"}, Resources: []string{"docs://*"}},
+//	        {Roles: []string{"admin"}, Methods: []string{"*"}, Tools: []string{"*"}},
+//	        {Roles: []string{"contractor"}, Deny: true, Tools: []string{"fetch_internal"}},
+//	    },
+//	}
+//	stack := []middleware.Middleware{middleware.Auth(auth), middleware.Authorize(policy)}
+func Authorize(policy *AuthorizePolicy, opts ...AuthorizeOption) Middleware {
+	cfg := &authorizeConfig{
+		skipMethods: map[string]bool{
+			protocol.MethodInitialize: true,
+			protocol.MethodPing:       true,
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if req.IsNotification() || cfg.skipMethods[req.Method] {
+				return next(ctx, req)
+			}
+			identity := IdentityFromContext(ctx)
+
+			method := Access{Kind: AccessMethod, Name: req.Method}
+			if !policy.Allowed(ctx, identity, method) {
+				return nil, accessDenied(cfg.logger, req, identity, method)
+			}
+			if access, ok := requestAccess(req); ok && !policy.Allowed(ctx, identity, access) {
+				return nil, accessDenied(cfg.logger, req, identity, access)
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+			return FilterList(req.Method, resp, func(access Access) bool {
+				return policy.Allowed(ctx, identity, access)
+			})
+		}
+	}
+}
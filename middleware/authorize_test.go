@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestAuthorizePolicy_Allowed(t *testing.T) {
+	policy := &AuthorizePolicy{
+		Rules: []AuthorizeRule{
+			{Methods: []string{"tools/list"}},
+			{Roles: []string{"*"}, Methods: []string{"tools/call", "resources/*"}},
+			{Roles: []string{"*"}, Tools: []string{"search", "db_*"}},
+			{Roles: []string{"admin"}, Methods: []string{"*"}, Tools: []string{"*"}},
+			{Roles: []string{"contractor"}, Deny: true, Tools: []string{"db_*"}, Methods: []string{"resources/subscribe"}},
+		},
+	}
+	user := &Identity{ID: "u"}
+	admin := &Identity{ID: "a", Metadata: map[string]any{"role": "admin"}}
+	contractor := &Identity{ID: "c", Metadata: map[string]any{"roles": []string{"admin", "contractor"}}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		access   Access
+		want     bool
+	}{
+		{"rule without roles", nil, Access{AccessMethod, "tools/list"}, true},
+		{"unauthenticated", nil, Access{AccessMethod, "tools/call"}, false},
+		{"any identity", user, Access{AccessMethod, "resources/read"}, true},
+		{"allowed tool", user, Access{AccessTool, "db_query"}, true},
+		{"unmatched tool", user, Access{AccessTool, "delete"}, false},
+		{"unmatched method", user, Access{AccessMethod, "prompts/get"}, false},
+		{"role", admin, Access{AccessTool, "delete"}, true},
+		{"deny wins", contractor, Access{AccessTool, "db_query"}, false},
+		{"deny method", contractor, Access{AccessMethod, "resources/subscribe"}, false},
+		{"other roles still apply", contractor, Access{AccessTool, "delete"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allowed(context.Background(), tt.identity, tt.access); got != tt.want {
+				t.Errorf("Allowed(%v) = %v, want %v", tt.access, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("default allow", func(t *testing.T) {
+		open := &AuthorizePolicy{
+			DefaultAllow: true,
+			Rules:        []AuthorizeRule{{Roles: []string{"*"}, Deny: true, Tools: []string{"shutdown"}}},
+		}
+		if !open.Allowed(context.Background(), user, Access{AccessTool, "search"}) {
+			t.Error("expected unmatched access to be allowed")
+		}
+		if open.Allowed(context.Background(), user, Access{AccessTool, "shutdown"}) {
+			t.Error("expected denied access to be rejected")
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		checked := &AuthorizePolicy{
+			Rules: policy.Rules,
+			Check: func(ctx context.Context, identity *Identity, access Access) Effect {
+				switch {
+				case access.Name == "audit":
+					return EffectAllow
+				case identity != nil && identity.Metadata["suspended"] == true:
+					return EffectDeny
+				}
+				return EffectNone
+			},
+		}
+		suspended := &Identity{ID: "s", Metadata: map[string]any{"role": "admin", "suspended": true}}
+		if !checked.Allowed(context.Background(), user, Access{AccessTool, "audit"}) {
+			t.Error("expected the check to allow")
+		}
+		if checked.Allowed(context.Background(), suspended, Access{AccessTool, "search"}) {
+			t.Error("expected the check to deny")
+		}
+		if !checked.Allowed(context.Background(), admin, Access{AccessTool, "delete"}) {
+			t.Error("expected the rules to decide")
+		}
+	})
+
+	t.Run("custom roles", func(t *testing.T) {
+		custom := &AuthorizePolicy{
+			Rules:     policy.Rules,
+			RolesFunc: func(identity *Identity) []string { return []string{identity.ID} },
+		}
+		if !custom.Allowed(context.Background(), &Identity{ID: "admin"}, Access{AccessTool, "delete"}) {
+			t.Error("expected roles from RolesFunc")
+		}
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	policy := &AuthorizePolicy{
+		Rules: []AuthorizeRule{
+			{Roles: []string{"*"}, Methods: []string{"*/list", "tools/call", "prompts/get"}},
+			{Roles: []string{"*"}, Tools: []string{"search"}, Prompts: []string{"summary"}},
+			{Roles: []string{"*"}, Deny: true, Tools: []string{"delete"}},
+		},
+	}
+	user := ContextWithIdentity(context.Background(), &Identity{ID: "u"})
+
+	called := false
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		called = true
+		if req.Method == protocol.MethodToolsList {
+			return protocol.NewResponse(req.ID, protocol.ToolsListResult{
+				Tools: []protocol.Tool{{Name: "search"}, {Name: "delete"}, {Name: "export"}},
+			}), nil
+		}
+		return protocol.NewResponse(req.ID, map[string]any{}), nil
+	})
+	logger := &mockLogger{}
+	wrapped := Authorize(policy, WithAuthorizeLogger(logger), WithAuthorizeSkipMethods("x-myorg/health"))(handler)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		params     string
+		wantDenied bool
+	}{
+		{"allowed tool", user, protocol.MethodToolsCall, `{"name":"search"}`, false},
+		{"denied tool", user, protocol.MethodToolsCall, `{"name":"delete"}`, true},
+		{"unmatched tool", user, protocol.MethodToolsCall, `{"name":"export"}`, true},
+		{"allowed prompt", user, protocol.MethodPromptsGet, `{"name":"summary"}`, false},
+		{"denied method", user, protocol.MethodResourcesRead, `{"uri":"docs://readme"}`, true},
+		{"unauthenticated", context.Background(), protocol.MethodToolsList, ``, true},
+		{"ping is skipped", context.Background(), protocol.MethodPing, ``, false},
+		{"skipped method", context.Background(), "x-myorg/health", ``, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			_, err := wrapped(tt.ctx, &protocol.Request{ID: json.RawMessage(`1`), Method: tt.method, Params: json.RawMessage(tt.params)})
+			if tt.wantDenied {
+				if !errors.Is(err, &protocol.Error{Code: protocol.CodeForbidden}) {
+					t.Errorf("error = %v, want forbidden", err)
+				}
+				if called {
+					t.Error("handler called for a denied request")
+				}
+				return
+			}
+			if err != nil || !called {
+				t.Errorf("error = %v, called = %v, want the handler to run", err, called)
+			}
+		})
+	}
+
+	if len(logger.entries) != 4 {
+		t.Errorf("logged %d denials, want 4", len(logger.entries))
+	}
+
+	t.Run("notifications pass", func(t *testing.T) {
+		called = false
+		if _, err := wrapped(context.Background(), &protocol.Request{Method: protocol.MethodInitialized}); err != nil || !called {
+			t.Errorf("error = %v, called = %v, want the handler to run", err, called)
+		}
+	})
+
+	t.Run("filters lists", func(t *testing.T) {
+		resp, err := wrapped(user, &protocol.Request{ID: json.RawMessage(`1`), Method: protocol.MethodToolsList})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := listNames(t, resp), []string{"search"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("items = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestFilterList(t *testing.T) {
+	resp := protocol.NewResponse(json.RawMessage(`1`), protocol.PromptsListResult{
+		Prompts: []protocol.Prompt{{Name: "summary"}, {Name: "review"}},
+	})
+	filtered, err := FilterList(protocol.MethodPromptsList, resp, func(access Access) bool {
+		return access.Kind == AccessPrompt && access.Name == "review"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := listNames(t, filtered), []string{"review"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+	if got := listNames(t, resp); len(got) != 2 {
+		t.Errorf("original result modified: %v", got)
+	}
+
+	other := protocol.NewResponse(json.RawMessage(`1`), map[string]any{"ok": true})
+	if got, err := FilterList(protocol.MethodToolsCall, other, func(Access) bool { return false }); err != nil || got != other {
+		t.Errorf("FilterList() = %v, %v, want the response unchanged", got, err)
+	}
+}
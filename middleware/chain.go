@@ -53,3 +53,36 @@ func (c *MiddlewareChain) Then(handler HandlerFunc) HandlerFunc {
 func (c *MiddlewareChain) ThenFunc(fn func(ctx context.Context, req *protocol.Request) (*protocol.Response, error)) HandlerFunc {
 	return c.Then(HandlerFunc(fn))
 }
+
+// If returns middleware that applies mw to the requests for which
+// predicate returns true and passes the others straight to the next
+// handler.
+func If(predicate func(ctx context.Context, req *protocol.Request) bool, mw Middleware) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := mw(next)
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if predicate(ctx, req) {
+				return wrapped(ctx, req)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// ForMethods returns middleware that applies mw only to requests for the
+// given methods. Methods may contain "*" to match any sequence of
+// characters, as in "resources/*".
+//
+// Example:
+//
+//	limit := middleware.ForMethods(middleware.RateLimit(5, 10), "tools/call")
+func ForMethods(mw Middleware, methods ...string) Middleware {
+	return If(func(_ context.Context, req *protocol.Request) bool {
+		for _, m := range methods {
+			if matchGrant(m, req.Method) {
+				return true
+			}
+		}
+		return false
+	}, mw)
+}
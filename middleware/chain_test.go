@@ -175,3 +175,51 @@ func TestUse(t *testing.T) {
 		}
 	})
 }
+
+func TestIf(t *testing.T) {
+	var order []string
+	final := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	isTool := func(_ context.Context, req *protocol.Request) bool { return req.Method == protocol.MethodToolsCall }
+	handler := If(isTool, tracing(&order, "tools"))(final)
+
+	for _, method := range []string{protocol.MethodToolsCall, protocol.MethodPing, protocol.MethodToolsCall} {
+		if _, err := handler(context.Background(), &protocol.Request{Method: method}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(order) != 2 {
+		t.Errorf("middleware ran %d times, want 2", len(order))
+	}
+}
+
+func TestForMethods(t *testing.T) {
+	var order []string
+	final := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	handler := ForMethods(tracing(&order, "mw"), protocol.MethodToolsCall, "resources/*")(final)
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{protocol.MethodToolsCall, true},
+		{protocol.MethodResourcesRead, true},
+		{protocol.MethodResourcesTemplatesList, true},
+		{protocol.MethodToolsList, false},
+		{protocol.MethodPing, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			order = nil
+			if _, err := handler(context.Background(), &protocol.Request{Method: tt.method}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(order) == 1; got != tt.want {
+				t.Errorf("applied = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
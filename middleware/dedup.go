@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// DedupOption configures the Dedup middleware.
+type DedupOption func(*dedupConfig)
+
+type dedupConfig struct {
+	keyFunc        func(ctx context.Context, req *protocol.Request) (string, bool)
+	idempotentTool func(name string) bool
+}
+
+// WithDedupKeyFunc sets the function that decides which requests are
+// identical. Requests with the same key share a handler execution, and
+// requests for which it reports false are never shared. It replaces the
+// default key, DedupKey, and the methods it covers.
+func WithDedupKeyFunc(fn func(ctx context.Context, req *protocol.Request) (string, bool)) DedupOption {
+	return func(c *dedupConfig) {
+		c.keyFunc = fn
+	}
+}
+
+// WithDedupTools shares the calls of the tools for which idempotentTool
+// reports true, such as those found by Server.IdempotentTool. Without it,
+// tool calls always run.
+func WithDedupTools(idempotentTool func(name string) bool) DedupOption {
+	return func(c *dedupConfig) {
+		c.idempotentTool = idempotentTool
+	}
+}
+
+// dedupSharedKey is the span attribute set on requests that received the
+// result of another request.
+const dedupSharedKey = "mcp.dedup.shared"
+
+// dedupCall is a handler execution shared by identical requests.
+type dedupCall struct {
+	done chan struct{}
+	resp *protocol.Response
+	err  error
+}
+
+// Dedup returns middleware that runs identical concurrent requests once.
+// While a request is being handled, requests with the same method,
+// params and identity wait for it and receive its result, with their own
+// ID, instead of running the handler again. This spares expensive reads
+// when several agent branches ask for the same resource at once. Results
+// are not cached: a request arriving after the first one completed runs
+// the handler again.
+//
+// By default, the idempotent methods of DefaultIdempotent are shared, and
+// tool calls only for the tools of WithDedupTools. The handler runs with
+// the context of the first request, so progress notifications go to its
+// client only. A waiting request gives up when its own context is done,
+// and runs the handler itself if the first request was canceled.
+func Dedup(opts ...DedupOption) Middleware {
+	var cfg dedupConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	keyFunc := cfg.keyFunc
+	if keyFunc == nil {
+		keyFunc = func(ctx context.Context, req *protocol.Request) (string, bool) {
+			if !DefaultIdempotent(req, cfg.idempotentTool) {
+				return "", false
+			}
+			return DedupKey(ctx, req)
+		}
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]*dedupCall)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if req.IsNotification() {
+				return next(ctx, req)
+			}
+			key, ok := keyFunc(ctx, req)
+			if !ok {
+				return next(ctx, req)
+			}
+
+			mu.Lock()
+			if call, running := calls[key]; running {
+				mu.Unlock()
+				return waitDedup(ctx, call, next, req)
+			}
+			// Waiting requests get an error if the handler panics
+			call := &dedupCall{
+				done: make(chan struct{}),
+				err:  protocol.NewInternalError("shared request failed"),
+			}
+			calls[key] = call
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				delete(calls, key)
+				mu.Unlock()
+				close(call.done)
+			}()
+			call.resp, call.err = next(ctx, req)
+			return call.resp, call.err
+		}
+	}
+}
+
+// waitDedup waits for the result of call and returns it for req.
+func waitDedup(ctx context.Context, call *dedupCall, next HandlerFunc, req *protocol.Request) (*protocol.Response, error) {
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if errors.Is(call.err, context.Canceled) && ctx.Err() == nil {
+		// The first request was canceled, not this one
+		return next(ctx, req)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool(dedupSharedKey, true))
+	if call.resp == nil {
+		return nil, call.err
+	}
+	resp := *call.resp
+	resp.ID = req.ID
+	return &resp, call.err
+}
+
+// DedupKey returns the key under which Dedup shares a request: its method,
+// its params normalized so that key order and whitespace do not matter,
+// and the ID of the identity of ctx, so results are never shared between
+// identities. It reports false for params that are not valid JSON.
+func DedupKey(ctx context.Context, req *protocol.Request) (string, bool) {
+	params := []byte("null")
+	if len(req.Params) > 0 {
+		// Decode numbers as written, so large integers stay distinct
+		dec := json.NewDecoder(bytes.NewReader(req.Params))
+		dec.UseNumber()
+		var decoded any
+		if err := dec.Decode(&decoded); err != nil {
+			return "", false
+		}
+		normalized, err := json.Marshal(decoded)
+		if err != nil {
+			return "", false
+		}
+		params = normalized
+	}
+
+	var identity string
+	if id := IdentityFromContext(ctx); id != nil {
+		identity = id.ID
+	}
+	key, err := json.Marshal([]string{req.Method, identity, string(params)})
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
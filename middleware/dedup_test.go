@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// runConcurrently sends reqs through handler while its first request is
+// being handled and returns their results in order.
+func runConcurrently(t *testing.T, opts []DedupOption, next HandlerFunc, release chan struct{}, reqs ...*protocol.Request) ([]*protocol.Response, []error) {
+	t.Helper()
+	var keyed sync.WaitGroup
+	keyed.Add(len(reqs))
+	keyFunc := func(ctx context.Context, req *protocol.Request) (string, bool) {
+		defer keyed.Done()
+		return DedupKey(ctx, req)
+	}
+	handler := Dedup(append([]DedupOption{WithDedupKeyFunc(keyFunc)}, opts...)...)(next)
+
+	resps := make([]*protocol.Response, len(reqs))
+	errs := make([]error, len(reqs))
+	var done sync.WaitGroup
+	for i, req := range reqs {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			resps[i], errs[i] = handler(context.Background(), req)
+		}()
+	}
+	keyed.Wait()
+	// Let the requests reach the shared call before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+	return resps, errs
+}
+
+func TestDedup(t *testing.T) {
+	t.Run("shares identical concurrent requests", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		next := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			calls.Add(1)
+			<-release
+			return protocol.NewResponse(req.ID, "contents"), nil
+		}
+
+		resps, errs := runConcurrently(t, nil, next, release,
+			&protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodResourcesRead, Params: json.RawMessage(`{"uri":"file:///big"}`)},
+			&protocol.Request{ID: json.RawMessage("2"), Method: protocol.MethodResourcesRead, Params: json.RawMessage(`{"uri":"file:///big"}`)},
+			&protocol.Request{ID: json.RawMessage("3"), Method: protocol.MethodResourcesRead, Params: json.RawMessage(`{ "uri": "file:///big" }`)},
+		)
+
+		if got := calls.Load(); got != 1 {
+			t.Errorf("handler called %d times, want 1", got)
+		}
+		for i, resp := range resps {
+			if errs[i] != nil {
+				t.Fatalf("request %d: unexpected error: %v", i, errs[i])
+			}
+			if want := json.RawMessage([]byte{byte('1' + i)}); string(resp.ID) != string(want) {
+				t.Errorf("request %d: response ID = %s, want %s", i, resp.ID, want)
+			}
+			if resp.Result != "contents" {
+				t.Errorf("request %d: result = %v", i, resp.Result)
+			}
+		}
+	})
+
+	t.Run("shares errors", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		next := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			calls.Add(1)
+			<-release
+			return nil, protocol.NewUnavailable("backend down")
+		}
+
+		_, errs := runConcurrently(t, nil, next, release,
+			&protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList},
+			&protocol.Request{ID: json.RawMessage("2"), Method: protocol.MethodToolsList},
+		)
+
+		if got := calls.Load(); got != 1 {
+			t.Errorf("handler called %d times, want 1", got)
+		}
+		for i, err := range errs {
+			var mcpErr *protocol.Error
+			if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeUnavailable {
+				t.Errorf("request %d: expected unavailable error, got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("runs different requests separately", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		next := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			calls.Add(1)
+			<-release
+			return protocol.NewResponse(req.ID, "ok"), nil
+		}
+
+		runConcurrently(t, nil, next, release,
+			&protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodResourcesRead, Params: json.RawMessage(`{"uri":"file:///a"}`)},
+			&protocol.Request{ID: json.RawMessage("2"), Method: protocol.MethodResourcesRead, Params: json.RawMessage(`{"uri":"file:///b"}`)},
+			&protocol.Request{ID: json.RawMessage("3"), Method: protocol.MethodPromptsGet, Params: json.RawMessage(`{"uri":"file:///a"}`)},
+		)
+
+		if got := calls.Load(); got != 3 {
+			t.Errorf("handler called %d times, want 3", got)
+		}
+	})
+
+	t.Run("runs requests again once completed", func(t *testing.T) {
+		calls := 0
+		handler := Dedup()(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			calls++
+			return protocol.NewResponse(req.ID, "ok"), nil
+		})
+
+		req := &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList}
+		_, _ = handler(context.Background(), req)
+		_, _ = handler(context.Background(), req)
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("does not share tool calls by default", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		var started sync.WaitGroup
+		started.Add(2)
+		handler := Dedup()(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			calls.Add(1)
+			started.Done()
+			<-release
+			return protocol.NewResponse(req.ID, "sent"), nil
+		})
+
+		var done sync.WaitGroup
+		for _, id := range []string{"1", "2"} {
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				_, _ = handler(context.Background(), &protocol.Request{
+					ID: json.RawMessage(id), Method: protocol.MethodToolsCall,
+					Params: json.RawMessage(`{"name":"send_email","arguments":{"to":"a@example.com"}}`),
+				})
+			}()
+		}
+		started.Wait()
+		close(release)
+		done.Wait()
+
+		if got := calls.Load(); got != 2 {
+			t.Errorf("handler called %d times, want 2", got)
+		}
+	})
+
+	t.Run("gives up when the waiting request is canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+		handler := Dedup()(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			close(started)
+			<-release
+			return protocol.NewResponse(req.ID, "ok"), nil
+		})
+
+		req := &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList}
+		go func() { _, _ = handler(context.Background(), req) }()
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := handler(ctx, req); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestDedup_Tools(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	next := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		calls.Add(1)
+		<-release
+		return protocol.NewResponse(req.ID, "report"), nil
+	}
+	keyed := make(chan struct{}, 2)
+	idempotent := func(name string) bool {
+		keyed <- struct{}{}
+		return name == "build_report"
+	}
+	handler := Dedup(WithDedupTools(idempotent))(next)
+
+	var done sync.WaitGroup
+	for _, id := range []string{"1", "2"} {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			_, _ = handler(context.Background(), &protocol.Request{
+				ID: json.RawMessage(id), Method: protocol.MethodToolsCall,
+				Params: json.RawMessage(`{"name":"build_report","arguments":{"year":2026}}`),
+			})
+		}()
+	}
+	<-keyed
+	<-keyed
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler called %d times, want 1", got)
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	alice := ContextWithIdentity(context.Background(), &Identity{ID: "alice"})
+	bob := ContextWithIdentity(context.Background(), &Identity{ID: "bob"})
+	key := func(ctx context.Context, method, params string) string {
+		t.Helper()
+		k, ok := DedupKey(ctx, &protocol.Request{Method: method, Params: json.RawMessage(params)})
+		if !ok {
+			t.Fatalf("DedupKey(%s, %s) reported false", method, params)
+		}
+		return k
+	}
+
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"key order", key(alice, "m", `{"a":1,"b":2}`), key(alice, "m", `{"b":2,"a":1}`), true},
+		{"whitespace", key(alice, "m", `{"a": [1, 2]}`), key(alice, "m", `{"a":[1,2]}`), true},
+		{"different params", key(alice, "m", `{"a":1}`), key(alice, "m", `{"a":2}`), false},
+		{"large integers", key(alice, "m", `{"a":12345678901234567890}`), key(alice, "m", `{"a":12345678901234567891}`), false},
+		{"different methods", key(alice, "m", `{}`), key(alice, "n", `{}`), false},
+		{"different identities", key(alice, "m", `{}`), key(bob, "m", `{}`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.a == tt.b) != tt.equal {
+				t.Errorf("keys %s and %s: equal = %v, want %v", tt.a, tt.b, tt.a == tt.b, tt.equal)
+			}
+		})
+	}
+
+	if _, ok := DedupKey(context.Background(), &protocol.Request{Method: "m", Params: json.RawMessage(`{`)}); ok {
+		t.Error("expected invalid params to report false")
+	}
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// DevLoggerEnv is the environment variable that enables DevLogger.
+const DevLoggerEnv = "MCP_DEBUG"
+
+// ANSI escape codes used by DevLogger.
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// schemaKeys are the keys whose values DevLogger replaces with a summary.
+var schemaKeys = map[string]struct{}{
+	"inputSchema":  {},
+	"outputSchema": {},
+}
+
+// DevLoggerOption configures the DevLogger middleware.
+type DevLoggerOption func(*devLoggerConfig)
+
+type devLoggerConfig struct {
+	out       io.Writer
+	env       string
+	enabled   *bool
+	color     bool
+	maxString int
+	redactor  *Redactor
+}
+
+// WithDevLoggerOutput sets the writer DevLogger prints to.
+// Defaults to os.Stderr, which keeps stdout free for the stdio transport.
+func WithDevLoggerOutput(w io.Writer) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.out = w
+	}
+}
+
+// WithDevLoggerEnv sets the environment variable that enables DevLogger.
+// Defaults to DevLoggerEnv.
+func WithDevLoggerEnv(name string) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.env = name
+	}
+}
+
+// WithDevLoggerEnabled enables or disables DevLogger regardless of the
+// environment.
+func WithDevLoggerEnabled(enabled bool) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.enabled = &enabled
+	}
+}
+
+// WithDevLoggerColor enables or disables ANSI colors. Colors are enabled by
+// default unless the NO_COLOR environment variable is set.
+func WithDevLoggerColor(enabled bool) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.color = enabled
+	}
+}
+
+// WithDevLoggerMaxString truncates string values longer than n characters.
+// Defaults to 200. A value of zero or less disables truncation.
+func WithDevLoggerMaxString(n int) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.maxString = n
+	}
+}
+
+// WithDevLoggerRedactor replaces sensitive values in the printed params
+// and results, for sessions whose output is shared or recorded. Long
+// strings are still truncated as WithDevLoggerMaxString sets.
+func WithDevLoggerRedactor(r *Redactor) DevLoggerOption {
+	return func(c *devLoggerConfig) {
+		c.redactor = r
+	}
+}
+
+// DevLogger returns middleware that pretty-prints every request and
+// response for local development:
+//
+//	→ tools/call #3
+//	  {
+//	    "name": "search"
+//	  }
+//	← tools/call #3 (12ms)
+//	  {
+//	    "content": [...]
+//	  }
+//
+// Tool schemas are summarized and long strings truncated to keep the
+// output readable. DevLogger only prints when the MCP_DEBUG environment
+// variable is set to a value other than "", "0" or "false"; otherwise it
+// passes requests through untouched, so it is safe to leave in production
+// builds.
+func DevLogger(opts ...DevLoggerOption) Middleware {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	cfg := &devLoggerConfig{
+		out:       os.Stderr,
+		env:       DevLoggerEnv,
+		color:     !noColor,
+		maxString: 200,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	enabled := envEnabled(cfg.env)
+	if cfg.enabled != nil {
+		enabled = *cfg.enabled
+	}
+	if !enabled {
+		return func(next HandlerFunc) HandlerFunc {
+			return next
+		}
+	}
+
+	p := &devPrinter{cfg: cfg}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			p.request(req)
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+			p.response(req, resp, err, time.Since(start))
+
+			return resp, err
+		}
+	}
+}
+
+// envEnabled reports whether the environment variable name is set to a
+// truthy value.
+func envEnabled(name string) bool {
+	switch strings.ToLower(os.Getenv(name)) {
+	case "", "0", "false", "off", "no":
+		return false
+	default:
+		return true
+	}
+}
+
+// devPrinter formats DevLogger output.
+type devPrinter struct {
+	mu  sync.Mutex
+	cfg *devLoggerConfig
+}
+
+func (p *devPrinter) request(req *protocol.Request) {
+	var params any
+	if len(req.Params) > 0 {
+		_ = json.Unmarshal(req.Params, &params)
+	}
+	p.print(ansiCyan, "→", req, "", params)
+}
+
+func (p *devPrinter) response(req *protocol.Request, resp *protocol.Response, err error, d time.Duration) {
+	elapsed := fmt.Sprintf(" (%s)", d.Round(time.Microsecond))
+
+	if err == nil && resp != nil && resp.Error != nil {
+		err = resp.Error
+	}
+	if err != nil {
+		p.print(ansiRed, "✗", req, elapsed+" "+err.Error(), nil)
+		return
+	}
+	if resp == nil {
+		// Notifications have no response
+		return
+	}
+
+	var result any
+	if data, mErr := json.Marshal(resp.Result); mErr == nil {
+		_ = json.Unmarshal(data, &result)
+	}
+	p.print(ansiGreen, "←", req, elapsed, result)
+}
+
+// print writes a header line followed by the indented body, if any.
+func (p *devPrinter) print(color, arrow string, req *protocol.Request, suffix string, body any) {
+	var b strings.Builder
+
+	header := arrow + " " + req.Method
+	if len(req.ID) > 0 {
+		header += " #" + string(req.ID)
+	}
+	b.WriteString(p.colorize(color, header))
+	b.WriteString(suffix)
+	b.WriteByte('\n')
+
+	if body != nil {
+		if p.cfg.redactor != nil {
+			body = p.cfg.redactor.redactValue(body)
+		}
+		var data bytes.Buffer
+		enc := json.NewEncoder(&data)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("  ", "  ")
+		if err := enc.Encode(p.sanitize(body)); err == nil {
+			b.WriteString(p.colorize(ansiDim, "  "+strings.TrimSuffix(data.String(), "\n")))
+			b.WriteByte('\n')
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.cfg.out, b.String())
+}
+
+func (p *devPrinter) colorize(color, s string) string {
+	if !p.cfg.color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// sanitize summarizes schemas and truncates long strings in v.
+func (p *devPrinter) sanitize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			if _, ok := schemaKeys[k]; ok {
+				out[k] = summarizeSchema(child)
+				continue
+			}
+			out[k] = p.sanitize(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = p.sanitize(child)
+		}
+		return out
+	case string:
+		if n := p.cfg.maxString; n > 0 && len(val) > n {
+			return fmt.Sprintf("%s…(%d more bytes)", val[:n], len(val)-n)
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// summarizeSchema describes a JSON Schema in a few words.
+func summarizeSchema(schema any) string {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return "<schema>"
+	}
+	typ, _ := m["type"].(string)
+	if typ == "" {
+		typ = "any"
+	}
+	props, _ := m["properties"].(map[string]any)
+	if len(props) == 0 {
+		return fmt.Sprintf("<schema: %s>", typ)
+	}
+	return fmt.Sprintf("<schema: %s, %d properties>", typ, len(props))
+}
@@ -0,0 +1,187 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/middleware"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestDevLogger(t *testing.T) {
+	okHandler := func(result any) middleware.HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return protocol.NewResponse(req.ID, result), nil
+		}
+	}
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`7`),
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"search"}`),
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		opts     []middleware.DevLoggerOption
+		handler  middleware.HandlerFunc
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "silent when env var is unset",
+			handler:  okHandler("ok"),
+			excludes: []string{"tools/call"},
+		},
+		{
+			name:     "silent when env var is false",
+			env:      "false",
+			handler:  okHandler("ok"),
+			excludes: []string{"tools/call"},
+		},
+		{
+			name:     "prints request and response when env var is set",
+			env:      "1",
+			handler:  okHandler("ok"),
+			contains: []string{"→ tools/call #7", `"name": "search"`, "← tools/call #7 (", `"ok"`},
+		},
+		{
+			name:     "enabled by option",
+			opts:     []middleware.DevLoggerOption{middleware.WithDevLoggerEnabled(true)},
+			handler:  okHandler("ok"),
+			contains: []string{"→ tools/call #7"},
+		},
+		{
+			name:     "disabled by option overrides env",
+			env:      "1",
+			opts:     []middleware.DevLoggerOption{middleware.WithDevLoggerEnabled(false)},
+			handler:  okHandler("ok"),
+			excludes: []string{"tools/call"},
+		},
+		{
+			name: "summarizes schemas",
+			env:  "1",
+			handler: okHandler(map[string]any{
+				"tools": []any{map[string]any{
+					"name": "search",
+					"inputSchema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"query": map[string]any{"type": "string"},
+							"limit": map[string]any{"type": "integer"},
+						},
+					},
+				}},
+			}),
+			contains: []string{`"inputSchema": "<schema: object, 2 properties>"`},
+			excludes: []string{`"limit"`},
+		},
+		{
+			name: "truncates long strings",
+			env:  "1",
+			opts: []middleware.DevLoggerOption{middleware.WithDevLoggerMaxString(5)},
+			handler: okHandler(map[string]any{
+				"text": "hello world",
+			}),
+			contains: []string{`"hello…(6 more bytes)"`},
+		},
+		{
+			name: "redacts sensitive values",
+			env:  "1",
+			opts: []middleware.DevLoggerOption{middleware.WithDevLoggerRedactor(middleware.DefaultRedactor())},
+			handler: okHandler(map[string]any{
+				"apiKey": "sk-live-123",
+				"text":   "mail bob@example.com",
+			}),
+			contains: []string{`"apiKey": "[REDACTED]"`, `"mail [REDACTED]"`},
+			excludes: []string{"sk-live-123", "bob@example.com"},
+		},
+		{
+			name: "prints errors",
+			env:  "1",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				return nil, errors.New("boom")
+			},
+			contains: []string{"✗ tools/call #7 (", "boom"},
+		},
+		{
+			name: "prints error responses",
+			env:  "1",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				return protocol.NewErrorResponse(req.ID, protocol.NewNotFound("tool not found")), nil
+			},
+			contains: []string{"✗ tools/call #7 ("},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(middleware.DevLoggerEnv, tt.env)
+
+			var buf bytes.Buffer
+			opts := append([]middleware.DevLoggerOption{
+				middleware.WithDevLoggerOutput(&buf),
+				middleware.WithDevLoggerColor(false),
+			}, tt.opts...)
+
+			handler := middleware.DevLogger(opts...)(tt.handler)
+			_, _ = handler(context.Background(), req)
+
+			out := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDevLogger_Color(t *testing.T) {
+	var buf bytes.Buffer
+	handler := middleware.DevLogger(
+		middleware.WithDevLoggerEnabled(true),
+		middleware.WithDevLoggerOutput(&buf),
+		middleware.WithDevLoggerColor(true),
+	)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+
+	_, _ = handler(context.Background(), &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "ping"})
+
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("expected ANSI escape codes, got %q", buf.String())
+	}
+}
+
+func TestDevLogger_CustomEnv(t *testing.T) {
+	t.Setenv("MY_DEBUG", "yes")
+
+	var buf bytes.Buffer
+	handler := middleware.DevLogger(
+		middleware.WithDevLoggerEnv("MY_DEBUG"),
+		middleware.WithDevLoggerOutput(&buf),
+	)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return nil, nil
+	})
+
+	_, _ = handler(context.Background(), &protocol.Request{JSONRPC: "2.0", Method: "notifications/initialized"})
+
+	out := buf.String()
+	if !strings.Contains(out, "notifications/initialized") {
+		t.Errorf("expected request to be printed, got %q", out)
+	}
+	if strings.Contains(out, "←") {
+		t.Errorf("expected no response line for notification, got %q", out)
+	}
+}
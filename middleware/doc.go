@@ -14,6 +14,39 @@
 //	)
 //	handler := chain(baseHandler)
 //
+// # Composition
+//
+// If and ForMethods apply middleware to some requests only:
+//
+//	limit := middleware.ForMethods(middleware.RateLimit(5, 10), "tools/call")
+//
+// A Stack groups middleware into named stages, such as StageAuth and
+// StageLimits, that can be inserted, replaced or removed by name, so that
+// the order of a large chain does not depend on slice positions:
+//
+//	stack := middleware.NewStack().
+//	    Add(middleware.StageRecover, middleware.Recover()).
+//	    Add(middleware.StageAuth, middleware.Auth(auth))
+//	stack.InsertAfter(middleware.StageAuth, "authorize", middleware.Authorize(policy))
+//	mws, err := stack.Build()
+//
+// # Request Rewriting
+//
+// Rewrite changes requests before they are dispatched. Besides renaming
+// methods and aliasing tools, it patches tool arguments, either untyped
+// with DefaultToolArguments or through a struct with PatchToolArguments,
+// which writes back only the fields the callback changed:
+//
+//	mw := middleware.Rewrite(
+//	    middleware.AliasTool("search_docs", "search"),
+//	    middleware.PatchToolArguments("search", func(ctx context.Context, args *struct {
+//	        Limit int `json:"limit,omitempty"`
+//	    }) error {
+//	        args.Limit = min(args.Limit, 50)
+//	        return nil
+//	    }),
+//	)
+//
 // # Available Middleware
 //
 // The package provides several built-in middleware:
@@ -22,6 +55,14 @@
 //   - RequestID: Injects unique request IDs into the context
 //   - Timeout: Enforces request deadlines
 //   - Logging: Logs request details and timing
+//   - Auth: Authenticates requests and attaches an Identity
+//   - RBAC: Limits identities to the tools, resources and prompts of their roles
+//   - Authorize: Applies allow and deny rules to methods, tools, resources and prompts
+//   - Retry: Retries idempotent requests that fail with transient errors
+//   - Dedup: Runs identical concurrent requests once and shares the result
+//   - RateLimit: Limits request rates with token buckets in memory or Redis
+//   - Quota: Limits requests per identity per day and per month
+//   - OTel: Traces requests and records request metrics with OpenTelemetry
 //
 // # Default Stacks
 //
@@ -33,6 +74,176 @@
 //	// Recover + RequestID + Timeout + Logging
 //	stack := middleware.DefaultStackWithTimeout(logger, 30*time.Second)
 //
+// # Error Precedence
+//
+// When a middleware and the handler it wraps both fail, the middleware
+// error wins: Timeout reports an internal "request timed out" error even
+// if the handler also returned one. Custom middleware should combine
+// errors with JoinErrors, which keeps the handler error for errors.Is
+// while giving transports a single protocol error code.
+//
+// # Abandoned Handlers
+//
+// Timeout waits for the handler to return after the deadline. A handler
+// that ignores its context then holds the request open; WithTimeoutAbandon
+// answers at the deadline instead and leaves the handler running:
+//
+//	mw := middleware.Timeout(5*time.Second,
+//	    middleware.WithTimeoutAbandon(),
+//	    middleware.WithTimeoutLogger(logger),
+//	)
+//
+// AbandonedHandlers reports how many of these goroutines are still running,
+// and handlers can check Abandoned to stop work nobody is waiting for.
+//
+// # Client Deadlines
+//
+// With WithClientTimeout, Timeout applies the timeout hint a client sets
+// in the _meta of the request params (protocol.MetaTimeout, in
+// milliseconds) when it is shorter than the server's, so interactive
+// clients get a quick failure while batch clients keep the full deadline:
+//
+//	mw := middleware.Timeout(2*time.Minute, middleware.WithClientTimeout())
+//
+// Clients built with client.WithDeadlinePropagation send the time left on
+// their context as the hint.
+//
+// # Redaction
+//
+// Logging omits request params unless WithLogParams is given, and then
+// passes them through a Redactor, so API keys and personal data in tool
+// arguments never reach the logs. DefaultRedactor covers common credential
+// keys, bearer tokens, email addresses and card numbers; a custom Redactor
+// adds keys, patterns and a size limit:
+//
+//	redactor := middleware.DefaultRedactor()
+//	redactor.Keys = append(redactor.Keys, "ssn", "*_pin")
+//	mw := middleware.Logging(logger, middleware.WithLogParams(), middleware.WithLogRedactor(redactor))
+//
+// WithLogResponse logs the size and the redacted body of each result. On
+// busy servers, WithLogSampling logs only a fraction of the successful
+// requests, while failed requests and those slower than the threshold of
+// WithLogSlowThreshold, logged as warnings, are always kept:
+//
+//	mw := middleware.Logging(logger,
+//	    middleware.WithLogParams(),
+//	    middleware.WithLogResponse(),
+//	    middleware.WithLogSampling(0.1),
+//	    middleware.WithLogSlowThreshold(2*time.Second),
+//	)
+//
+// WithDevLoggerRedactor applies a Redactor to DevLogger output.
+//
+// # Token Introspection
+//
+// IntrospectionAuthenticator validates opaque OAuth 2.0 access tokens by
+// asking the introspection endpoint (RFC 7662) of the authorization server.
+// Results are cached until they expire, so the endpoint is not called for
+// every request; the claims of the token, such as "roles", become the
+// identity metadata used by RBAC:
+//
+//	auth := middleware.IntrospectionAuthenticator(endpoint,
+//	    middleware.WithIntrospectionClientCredentials("mcp-server", secret),
+//	    middleware.WithIntrospectionCache(30*time.Second, 10000),
+//	)
+//
+// # Authorization
+//
+// RBAC grants each role a set of tools, resources and prompts. Authorize
+// takes rules instead, which also cover methods and may deny: a deny rule
+// wins over any allow rule, and Check decides what rules cannot express:
+//
+//	policy := &middleware.AuthorizePolicy{
+//	    Rules: []middleware.AuthorizeRule{
+//	        {Roles: []string{"*"}, Methods: []string{"*/list", "tools/call"}, Tools: []string{"*"}},
+//	        {Roles: []string{"guest"}, Deny: true, Tools: []string{"delete_*"}},
+//	    },
+//	}
+//
+// Both filter list results, and FilterList does the same for custom
+// middleware.
+//
+// # Retries
+//
+// Retry runs idempotent requests again when they fail with an internal or
+// unavailable error, with exponential backoff and jitter between attempts.
+// Tool calls are retried only for tools marked idempotent or read-only,
+// which Server.IdempotentTool reports:
+//
+//	mw := middleware.Retry(middleware.RetryPolicy{
+//	    MaxAttempts:    3,
+//	    IdempotentTool: srv.IdempotentTool,
+//	})
+//
+// # Deduplication
+//
+// Dedup lets identical requests that arrive while one is being handled
+// wait for its result instead of running the handler again, which helps
+// when several agent branches read the same expensive resource at once.
+// Requests are identical when their method, params and identity match;
+// tool calls are shared only for the tools of WithDedupTools:
+//
+//	mw := middleware.Dedup(middleware.WithDedupTools(srv.IdempotentTool))
+//
+// # Distributed Rate Limits
+//
+// RateLimit keeps its token buckets in process memory, so each server
+// instance behind a load balancer would allow the full rate. A
+// RedisRateLimitStore shares the buckets between instances. It runs Lua
+// scripts through a RedisEvaler, which adapts any Redis client:
+//
+//	eval := middleware.RedisEvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//	    return rdb.Eval(ctx, script, keys, args...).Result()
+//	})
+//	mw := middleware.RateLimitByClient(10, 20, clientID,
+//	    middleware.WithRateLimitStore(middleware.NewRedisRateLimitStore(eval)),
+//	)
+//
+// Requests are rejected while Redis is unreachable unless
+// WithRateLimitFailOpen is set. RateLimitByIdentity keeps a bucket per
+// identity set by Auth.
+//
+// # Quotas
+//
+// Quota counts the requests of each identity per day and per month and
+// rejects those over the limits with a rate limited error whose data is a
+// QuotaInfo, telling the client how many requests it has left and when the
+// quota resets. LimitsFunc sets limits per identity, such as by plan, and a
+// RedisQuotaStore shares the counters between instances:
+//
+//	mw := middleware.Quota(middleware.QuotaPolicy{Daily: 1000, Monthly: 20000},
+//	    middleware.WithQuotaMethods("tools/call"),
+//	    middleware.WithQuotaStore(middleware.NewRedisQuotaStore(eval)),
+//	)
+//
+// # Panics
+//
+// Recover turns panics into internal errors and records them, with their
+// stack traces, as exception events on the OpenTelemetry span of the
+// request. WithRecoverLogger logs them and WithOnPanic hands them to an
+// error tracker:
+//
+//	mw := middleware.Recover(
+//	    middleware.WithRecoverLogger(logger),
+//	    middleware.WithOnPanic(func(ctx context.Context, req *protocol.Request, v any, stack []byte) {
+//	        bugsnag.Notify(fmt.Errorf("panic in %s: %v", req.Method, v), ctx)
+//	    }),
+//	)
+//
+// # Metrics
+//
+// OTel records the request count, a latency histogram, errors by code,
+// the requests in flight and tool calls by tool and outcome with the
+// MeterProvider of WithMeterProvider. Measurements are taken in the context
+// of the request span, so the trace-based exemplar filter of the SDK
+// attaches trace IDs to them, linking a slow histogram bucket to a trace:
+//
+//	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+//	mw := middleware.OTel(middleware.WithTracerProvider(tp), middleware.WithMeterProvider(mp))
+//
+// Calls of unknown tools are not counted per tool, so clients cannot grow
+// the number of tool names in the metrics.
+//
 // # Custom Middleware
 //
 // Implement custom middleware using the Middleware type:
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+)
+
+// JoinErrors combines an error raised by a middleware with the error
+// returned by the handler it wraps.
+//
+// The middleware error takes precedence: it is placed first, so errors.As
+// finds its *protocol.Error before any in the handler error and transports
+// report a single, deterministic code. The handler error is kept, so
+// errors.Is still matches it. If either error is nil, the other is
+// returned unchanged.
+//
+// The handler error is dropped only when it adds nothing: when it is the
+// middleware error itself, or a bare context.Canceled or
+// context.DeadlineExceeded the middleware error already matches.
+// *protocol.Error values match by code alone, so a handler error with the
+// same code as the middleware error is still kept.
+func JoinErrors(middlewareErr, handlerErr error) error {
+	switch {
+	case middlewareErr == nil:
+		return handlerErr
+	case handlerErr == nil, handlerErr == middlewareErr, isContextErr(handlerErr) && errors.Is(middlewareErr, handlerErr):
+		return middlewareErr
+	default:
+		return errors.Join(middlewareErr, handlerErr)
+	}
+}
+
+// isContextErr reports whether err is one of the errors of a done context.
+func isContextErr(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestJoinErrors(t *testing.T) {
+	middlewareErr := protocol.NewUnauthorized("token expired")
+	handlerErr := protocol.NewNotFound("item not found")
+	wrapped := errors.Join(middlewareErr, context.Canceled)
+	timeoutErr := protocol.NewInternalError("request timed out")
+	internalErr := protocol.NewInternalError("database unavailable")
+
+	tests := []struct {
+		name          string
+		middlewareErr error
+		handlerErr    error
+		want          error
+		wantCode      int
+	}{
+		{name: "both nil"},
+		{name: "middleware only", middlewareErr: middlewareErr, want: middlewareErr, wantCode: protocol.CodeUnauthorized},
+		{name: "handler only", handlerErr: handlerErr, want: handlerErr, wantCode: protocol.CodeNotFound},
+		{name: "both", middlewareErr: middlewareErr, handlerErr: handlerErr, wantCode: protocol.CodeUnauthorized},
+		{name: "handler error already included", middlewareErr: wrapped, handlerErr: context.Canceled, want: wrapped, wantCode: protocol.CodeUnauthorized},
+		{name: "same error", middlewareErr: middlewareErr, handlerErr: middlewareErr, want: middlewareErr, wantCode: protocol.CodeUnauthorized},
+		{name: "handler error with the same code", middlewareErr: timeoutErr, handlerErr: internalErr, wantCode: protocol.CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JoinErrors(tt.middlewareErr, tt.handlerErr)
+			if tt.middlewareErr == nil && tt.handlerErr == nil {
+				if err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+				return
+			}
+			if tt.want != nil && err != tt.want {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+
+			var mcpErr *protocol.Error
+			if !errors.As(err, &mcpErr) || mcpErr.Code != tt.wantCode {
+				t.Errorf("code = %v, want %d", mcpErr, tt.wantCode)
+			}
+			for _, e := range []error{tt.middlewareErr, tt.handlerErr} {
+				if e != nil && !errors.Is(err, e) {
+					t.Errorf("errors.Is(%v, %v) = false", err, e)
+				}
+			}
+			if tt.want == nil && tt.middlewareErr != nil && tt.handlerErr != nil && !strings.Contains(err.Error(), tt.handlerErr.Error()) {
+				t.Errorf("error = %v, want the handler error kept", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Introspection defaults.
+const (
+	defaultIntrospectionTimeout   = 5 * time.Second
+	defaultIntrospectionCacheTTL  = time.Minute
+	defaultIntrospectionCacheSize = 10000
+	maxIntrospectionResponseSize  = 1 << 20
+)
+
+// IntrospectionResponse is the answer of an OAuth 2.0 token introspection
+// endpoint (RFC 7662) for an access token.
+type IntrospectionResponse struct {
+	// Active reports whether the token is valid. The other fields are only
+	// meaningful for active tokens.
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	// Aud lists the audiences of the token, given as a string or an array.
+	Aud []string `json:"-"`
+	// Claims holds every member of the response, including extensions
+	// such as "roles".
+	Claims map[string]any `json:"-"`
+}
+
+// Scopes returns the space-separated scopes of the token.
+func (r *IntrospectionResponse) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+// IntrospectionOption configures IntrospectionAuthenticator.
+type IntrospectionOption func(*introspector)
+
+// WithIntrospectionClientCredentials authenticates the server to the
+// introspection endpoint with HTTP Basic authentication, as most
+// authorization servers require.
+func WithIntrospectionClientCredentials(clientID, clientSecret string) IntrospectionOption {
+	return func(i *introspector) {
+		i.clientID = clientID
+		i.clientSecret = clientSecret
+	}
+}
+
+// WithIntrospectionClient sets the HTTP client used to call the endpoint.
+// Defaults to http.DefaultClient.
+func WithIntrospectionClient(client *http.Client) IntrospectionOption {
+	return func(i *introspector) {
+		i.client = client
+	}
+}
+
+// WithIntrospectionTimeout limits how long a call to the endpoint may
+// take. Defaults to 5 seconds.
+func WithIntrospectionTimeout(d time.Duration) IntrospectionOption {
+	return func(i *introspector) {
+		i.timeout = d
+	}
+}
+
+// WithIntrospectionCache sets how long introspection results are reused
+// and how many tokens are remembered. Active tokens are never cached past
+// their expiry. Defaults to one minute and 10000 tokens; a ttl of zero
+// disables caching, so revoked tokens are rejected at once.
+func WithIntrospectionCache(ttl time.Duration, size int) IntrospectionOption {
+	return func(i *introspector) {
+		i.cacheTTL = ttl
+		i.cacheSize = size
+	}
+}
+
+// WithIntrospectionAudience rejects tokens not issued for audience.
+func WithIntrospectionAudience(audience string) IntrospectionOption {
+	return func(i *introspector) {
+		i.audience = audience
+	}
+}
+
+// WithIntrospectionIdentity sets how an active token becomes an identity.
+// Returning nil rejects the token. The default uses the subject, or the
+// client ID for tokens without one, as the identity ID, the username as
+// its name, and the claims as its metadata, with the scopes under
+// "scopes".
+func WithIntrospectionIdentity(fn func(resp *IntrospectionResponse) *Identity) IntrospectionOption {
+	return func(i *introspector) {
+		i.identity = fn
+	}
+}
+
+// IntrospectionAuthenticator creates an authenticator that validates
+// opaque bearer tokens with the OAuth 2.0 token introspection endpoint
+// (RFC 7662) of an authorization server:
+//
+//	auth := middleware.IntrospectionAuthenticator(
+//	    "https://auth.example.com/oauth2/introspect",
+//	    middleware.WithIntrospectionClientCredentials("mcp-server", secret),
+//	    middleware.WithIntrospectionAudience("https://mcp.example.com"),
+//	)
+//	mw := middleware.Auth(auth)
+//
+// Inactive, expired and not yet valid tokens get no identity. Results are
+// cached, so a revoked token may still be accepted until its cache entry
+// expires; see WithIntrospectionCache. A failing endpoint fails the
+// request, and its errors are not cached.
+func IntrospectionAuthenticator(endpoint string, opts ...IntrospectionOption) Authenticator {
+	i := &introspector{
+		endpoint:  endpoint,
+		client:    http.DefaultClient,
+		timeout:   defaultIntrospectionTimeout,
+		cacheTTL:  defaultIntrospectionCacheTTL,
+		cacheSize: defaultIntrospectionCacheSize,
+		identity:  introspectionIdentity,
+		cache:     make(map[[sha256.Size]byte]introspectionEntry),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(ctx context.Context, req *protocol.Request) (*Identity, error) {
+		token := bearerToken(ctx)
+		if token == "" {
+			return nil, nil
+		}
+		return i.authenticate(ctx, token)
+	}
+}
+
+// introspector validates tokens with an introspection endpoint.
+type introspector struct {
+	endpoint     string
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	timeout      time.Duration
+	audience     string
+	identity     func(*IntrospectionResponse) *Identity
+
+	cacheTTL  time.Duration
+	cacheSize int
+	mu        sync.Mutex
+	// cache maps the hash of a token, so tokens are not kept in memory,
+	// to its identity
+	cache map[[sha256.Size]byte]introspectionEntry
+}
+
+// introspectionEntry is a cached introspection result. A nil identity
+// records a rejected token.
+type introspectionEntry struct {
+	identity *Identity
+	expires  time.Time
+}
+
+func (i *introspector) authenticate(ctx context.Context, token string) (*Identity, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	if identity, ok := i.cached(key, now); ok {
+		return identity, nil
+	}
+
+	resp, err := i.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var identity *Identity
+	expires := now.Add(i.cacheTTL)
+	if i.valid(resp, now) {
+		identity = i.identity(resp)
+		if exp := time.Unix(resp.Exp, 0); resp.Exp != 0 && exp.Before(expires) {
+			expires = exp
+		}
+	}
+	i.store(key, introspectionEntry{identity: identity, expires: expires})
+	return identity, nil
+}
+
+// valid reports whether resp describes a token usable now.
+func (i *introspector) valid(resp *IntrospectionResponse, now time.Time) bool {
+	switch {
+	case !resp.Active:
+		return false
+	case resp.Exp != 0 && !now.Before(time.Unix(resp.Exp, 0)):
+		return false
+	case resp.Nbf != 0 && now.Before(time.Unix(resp.Nbf, 0)):
+		return false
+	case i.audience != "" && !slices.Contains(resp.Aud, i.audience):
+		return false
+	}
+	return true
+}
+
+func (i *introspector) cached(key [sha256.Size]byte, now time.Time) (*Identity, bool) {
+	if i.cacheTTL <= 0 {
+		return nil, false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	entry, ok := i.cache[key]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.identity, true
+}
+
+func (i *introspector) store(key [sha256.Size]byte, entry introspectionEntry) {
+	if i.cacheTTL <= 0 || i.cacheSize <= 0 {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.cache) >= i.cacheSize {
+		i.evict(time.Now())
+	}
+	i.cache[key] = entry
+}
+
+// evict makes room in the full cache by removing the expired entries, or
+// an arbitrary one if none has expired.
+func (i *introspector) evict(now time.Time) {
+	for key, entry := range i.cache {
+		if !now.Before(entry.expires) {
+			delete(i.cache, key)
+		}
+	}
+	if len(i.cache) < i.cacheSize {
+		return
+	}
+	for key := range i.cache {
+		delete(i.cache, key)
+		return
+	}
+}
+
+// introspect asks the endpoint about token.
+func (i *introspector) introspect(ctx context.Context, token string) (*IntrospectionResponse, error) {
+	if i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection: endpoint returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIntrospectionResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	return parseIntrospection(data)
+}
+
+// parseIntrospection decodes an introspection response.
+func parseIntrospection(data []byte) (*IntrospectionResponse, error) {
+	var result IntrospectionResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("introspection: invalid response: %w", err)
+	}
+	if err := json.Unmarshal(data, &result.Claims); err != nil {
+		return nil, fmt.Errorf("introspection: invalid response: %w", err)
+	}
+
+	switch aud := result.Claims["aud"].(type) {
+	case string:
+		result.Aud = []string{aud}
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				result.Aud = append(result.Aud, s)
+			}
+		}
+	}
+	return &result, nil
+}
+
+// introspectionIdentity is the default identity of an active token.
+func introspectionIdentity(resp *IntrospectionResponse) *Identity {
+	id := resp.Sub
+	if id == "" {
+		id = resp.ClientID
+	}
+	metadata := make(map[string]any, len(resp.Claims)+1)
+	maps.Copy(metadata, resp.Claims)
+	metadata["scopes"] = resp.Scopes()
+	return &Identity{ID: id, Name: resp.Username, Metadata: metadata}
+}
@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// introspectionServer answers introspection requests with the response
+// registered for each token, counting the calls.
+func introspectionServer(t *testing.T, tokens map[string]map[string]any) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "mcp-server" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		resp, ok := tokens[r.PostForm.Get("token")]
+		if !ok {
+			resp = map[string]any{"active": false}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func bearerContext(token string) context.Context {
+	return protocol.SetRequestMeta(context.Background(), "Authorization", "Bearer "+token)
+}
+
+func TestIntrospectionAuthenticator(t *testing.T) {
+	hour := time.Now().Add(time.Hour).Unix()
+	srv, _ := introspectionServer(t, map[string]map[string]any{
+		"user-token": {
+			"active": true, "sub": "user-1", "username": "alice", "scope": "read write",
+			"aud": []any{"https://mcp.example.com", "other"}, "exp": hour, "roles": []any{"admin"},
+		},
+		"client-token": {"active": true, "client_id": "batch-job", "aud": "https://mcp.example.com"},
+		"expired":      {"active": true, "sub": "user-1", "exp": time.Now().Add(-time.Minute).Unix()},
+		"not-yet":      {"active": true, "sub": "user-1", "nbf": hour},
+		"other-aud":    {"active": true, "sub": "user-1", "aud": "https://other.example.com"},
+	})
+	auth := IntrospectionAuthenticator(srv.URL,
+		WithIntrospectionClientCredentials("mcp-server", "s3cret"),
+		WithIntrospectionAudience("https://mcp.example.com"),
+	)
+
+	tests := []struct {
+		name   string
+		token  string
+		wantID string
+	}{
+		{"active user token", "user-token", "user-1"},
+		{"client credentials token", "client-token", "batch-job"},
+		{"inactive token", "revoked", ""},
+		{"expired token", "expired", ""},
+		{"token not yet valid", "not-yet", ""},
+		{"token for another audience", "other-aud", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := auth(bearerContext(tt.token), &protocol.Request{Method: "tools/list"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantID == "" {
+				if identity != nil {
+					t.Errorf("expected no identity, got %+v", identity)
+				}
+				return
+			}
+			if identity == nil || identity.ID != tt.wantID {
+				t.Fatalf("identity = %+v, want ID %q", identity, tt.wantID)
+			}
+		})
+	}
+
+	t.Run("maps claims to metadata", func(t *testing.T) {
+		identity, err := auth(bearerContext("user-token"), &protocol.Request{Method: "tools/list"})
+		if err != nil || identity == nil {
+			t.Fatalf("identity = %v, err = %v", identity, err)
+		}
+		if identity.Name != "alice" {
+			t.Errorf("Name = %q, want alice", identity.Name)
+		}
+		scopes, _ := identity.Metadata["scopes"].([]string)
+		if len(scopes) != 2 || scopes[0] != "read" || scopes[1] != "write" {
+			t.Errorf("scopes = %v", identity.Metadata["scopes"])
+		}
+		if roles := IdentityRoles(identity); len(roles) != 1 || roles[0] != "admin" {
+			t.Errorf("roles = %v, want [admin]", roles)
+		}
+	})
+
+	t.Run("skips requests without a bearer token", func(t *testing.T) {
+		identity, err := auth(context.Background(), &protocol.Request{Method: "tools/list"})
+		if identity != nil || err != nil {
+			t.Errorf("identity = %v, err = %v", identity, err)
+		}
+	})
+}
+
+func TestIntrospectionAuthenticator_Cache(t *testing.T) {
+	srv, calls := introspectionServer(t, map[string]map[string]any{
+		"token": {"active": true, "sub": "user-1"},
+	})
+	req := &protocol.Request{Method: "tools/list"}
+
+	t.Run("reuses results", func(t *testing.T) {
+		calls.Store(0)
+		auth := IntrospectionAuthenticator(srv.URL, WithIntrospectionClientCredentials("mcp-server", "s3cret"))
+		for range 3 {
+			if identity, err := auth(bearerContext("token"), req); err != nil || identity == nil {
+				t.Fatalf("identity = %v, err = %v", identity, err)
+			}
+			if identity, err := auth(bearerContext("revoked"), req); err != nil || identity != nil {
+				t.Fatalf("identity = %v, err = %v", identity, err)
+			}
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("endpoint called %d times, want 2", got)
+		}
+	})
+
+	t.Run("expires results", func(t *testing.T) {
+		calls.Store(0)
+		auth := IntrospectionAuthenticator(srv.URL,
+			WithIntrospectionClientCredentials("mcp-server", "s3cret"),
+			WithIntrospectionCache(10*time.Millisecond, 10),
+		)
+		_, _ = auth(bearerContext("token"), req)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = auth(bearerContext("token"), req)
+		if got := calls.Load(); got != 2 {
+			t.Errorf("endpoint called %d times, want 2", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		calls.Store(0)
+		auth := IntrospectionAuthenticator(srv.URL,
+			WithIntrospectionClientCredentials("mcp-server", "s3cret"),
+			WithIntrospectionCache(0, 0),
+		)
+		_, _ = auth(bearerContext("token"), req)
+		_, _ = auth(bearerContext("token"), req)
+		if got := calls.Load(); got != 2 {
+			t.Errorf("endpoint called %d times, want 2", got)
+		}
+	})
+
+	t.Run("stays within its size", func(t *testing.T) {
+		auth := IntrospectionAuthenticator(srv.URL,
+			WithIntrospectionClientCredentials("mcp-server", "s3cret"),
+			WithIntrospectionCache(time.Minute, 2),
+		)
+		for _, token := range []string{"a", "b", "c", "d"} {
+			_, _ = auth(bearerContext(token), req)
+		}
+		calls.Store(0)
+		_, _ = auth(bearerContext("d"), req)
+		if got := calls.Load(); got != 0 {
+			t.Errorf("expected the latest token to be cached, endpoint called %d times", got)
+		}
+	})
+}
+
+func TestIntrospectionAuthenticator_Errors(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	}))
+	defer broken.Close()
+	srv, calls := introspectionServer(t, nil)
+
+	tests := []struct {
+		name string
+		auth Authenticator
+	}{
+		{"timeout", IntrospectionAuthenticator(slow.URL, WithIntrospectionTimeout(10*time.Millisecond))},
+		{"invalid response", IntrospectionAuthenticator(broken.URL)},
+		{"rejected credentials", IntrospectionAuthenticator(srv.URL, WithIntrospectionClientCredentials("mcp-server", "wrong"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.auth(bearerContext("token"), &protocol.Request{Method: "tools/list"}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		calls.Store(0)
+		auth := IntrospectionAuthenticator(srv.URL, WithIntrospectionClientCredentials("mcp-server", "wrong"))
+		_, _ = auth(bearerContext("token"), &protocol.Request{Method: "tools/list"})
+		_, _ = auth(bearerContext("token"), &protocol.Request{Method: "tools/list"})
+		if got := calls.Load(); got != 2 {
+			t.Errorf("endpoint called %d times, want 2", got)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"math/rand/v2"
 	"time"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
@@ -26,9 +28,89 @@ func F(key string, value any) Field {
 	return Field{Key: key, Value: value}
 }
 
+// LoggingOption configures the Logging middleware.
+type LoggingOption func(*loggingConfig)
+
+type loggingConfig struct {
+	params       bool
+	response     bool
+	responseSize bool
+	redactor     *Redactor
+	sampleRate   float64
+	slow         time.Duration
+	random       func() float64
+}
+
+// WithLogParams adds the request params to each entry, as the "params"
+// field. They pass through the redactor first, DefaultRedactor unless
+// WithLogRedactor sets another, so credentials and personal data in tool
+// arguments stay out of the logs.
+func WithLogParams() LoggingOption {
+	return func(c *loggingConfig) {
+		c.params = true
+	}
+}
+
+// WithLogRedactor sets the Redactor applied to the payloads Logging emits.
+func WithLogRedactor(r *Redactor) LoggingOption {
+	return func(c *loggingConfig) {
+		c.redactor = r
+	}
+}
+
+// WithLogResponse adds the size of the response result in bytes, as the
+// "response_size" field, and the result itself, as the "response" field,
+// to each entry. The result passes through the redactor, which also
+// truncates it to its MaxBytes.
+func WithLogResponse() LoggingOption {
+	return func(c *loggingConfig) {
+		c.response = true
+		c.responseSize = true
+	}
+}
+
+// WithLogResponseSize adds the size of the response result in bytes to
+// each entry, as the "response_size" field, without the result itself.
+func WithLogResponseSize() LoggingOption {
+	return func(c *loggingConfig) {
+		c.responseSize = true
+	}
+}
+
+// WithLogSampling logs only a fraction of the successful requests, such
+// as 0.1 for one in ten, to cut log volume on busy servers. Failed and slow
+// requests are always logged.
+func WithLogSampling(rate float64) LoggingOption {
+	return func(c *loggingConfig) {
+		c.sampleRate = rate
+	}
+}
+
+// WithLogSlowThreshold logs requests that take longer than d at warn level,
+// as "slow request" with a "slow" field set to true.
+func WithLogSlowThreshold(d time.Duration) LoggingOption {
+	return func(c *loggingConfig) {
+		c.slow = d
+	}
+}
+
 // Logging returns middleware that logs request details.
 // Successful requests are logged at info level, errors at error level.
-func Logging(logger Logger) Middleware {
+// By default an entry holds the method, duration and request ID; the
+// options add the params and the response and control which requests are
+// logged.
+func Logging(logger Logger, opts ...LoggingOption) Middleware {
+	cfg := &loggingConfig{
+		sampleRate: 1,
+		random:     rand.Float64, //nolint:gosec // Sampling needs no cryptographic randomness
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.redactor == nil {
+		cfg.redactor = DefaultRedactor()
+	}
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
 			start := time.Now()
@@ -36,22 +118,20 @@ func Logging(logger Logger) Middleware {
 			resp, err := next(ctx, req)
 
 			duration := time.Since(start)
-
-			// Build fields
-			fields := []Field{
-				F("method", req.Method),
-				F("duration", duration),
+			slow := cfg.slow > 0 && duration > cfg.slow
+			if err == nil && !slow && !cfg.sampled() {
+				return resp, err
 			}
 
-			// Add request ID if present
-			if requestID := RequestIDFromContext(ctx); requestID != "" {
-				fields = append(fields, F("request_id", requestID))
-			}
-
-			if err != nil {
+			fields := cfg.fields(ctx, req, resp, duration)
+			switch {
+			case err != nil:
 				fields = append(fields, F("error", err.Error()))
 				logger.Error("request failed", fields...)
-			} else {
+			case slow:
+				fields = append(fields, F("slow", true))
+				logger.Warn("slow request", fields...)
+			default:
 				logger.Info("request completed", fields...)
 			}
 
@@ -60,6 +140,40 @@ func Logging(logger Logger) Middleware {
 	}
 }
 
+// sampled reports whether a successful request is logged.
+func (c *loggingConfig) sampled() bool {
+	return c.sampleRate >= 1 || c.random() < c.sampleRate
+}
+
+// fields returns the fields of the entry for req.
+func (c *loggingConfig) fields(ctx context.Context, req *protocol.Request, resp *protocol.Response, duration time.Duration) []Field {
+	fields := []Field{
+		F("method", req.Method),
+		F("duration", duration),
+	}
+
+	// Add request ID if present
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, F("request_id", requestID))
+	}
+
+	if c.params && len(req.Params) > 0 {
+		fields = append(fields, F("params", c.redactor.Redact(req.Params)))
+	}
+
+	if c.responseSize && resp != nil && resp.Result != nil {
+		data, err := json.Marshal(resp.Result)
+		if err != nil {
+			return fields
+		}
+		fields = append(fields, F("response_size", len(data)))
+		if c.response {
+			fields = append(fields, F("response", c.redactor.Redact(data)))
+		}
+	}
+	return fields
+}
+
 // NopLogger is a logger that discards all log entries.
 type NopLogger struct{}
 
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -148,4 +149,159 @@ func TestField(t *testing.T) {
 			t.Errorf("Value = %v, want %q", f.Value, "value")
 		}
 	})
+
+	t.Run("logs redacted params", func(t *testing.T) {
+		logger := &mockLogger{}
+
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return protocol.NewResponse(req.ID, "ok"), nil
+		})
+
+		wrapped := Logging(logger, WithLogParams())(handler)
+		_, _ = wrapped(context.Background(), &protocol.Request{
+			Method: "tools/call",
+			Params: json.RawMessage(`{"name":"deploy","arguments":{"region":"eu","api_key":"sk-123"}}`),
+		})
+
+		if len(logger.entries) != 1 {
+			t.Fatalf("expected 1 log entry, got %d", len(logger.entries))
+		}
+		var params any
+		for _, f := range logger.entries[0].fields {
+			if f.Key == "params" {
+				params = f.Value
+			}
+		}
+		want := `{"arguments":{"api_key":"[REDACTED]","region":"eu"},"name":"deploy"}`
+		if params != want {
+			t.Errorf("params = %v, want %s", params, want)
+		}
+	})
+
+	t.Run("omits params by default", func(t *testing.T) {
+		logger := &mockLogger{}
+
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return protocol.NewResponse(req.ID, "ok"), nil
+		})
+
+		wrapped := Logging(logger)(handler)
+		_, _ = wrapped(context.Background(), &protocol.Request{Method: "tools/call", Params: json.RawMessage(`{"name":"deploy"}`)})
+
+		for _, f := range logger.entries[0].fields {
+			if f.Key == "params" {
+				t.Errorf("unexpected params field: %v", f.Value)
+			}
+		}
+	})
+}
+
+// field returns the value of the field of e with key, or nil.
+func (e logEntry) field(key string) any {
+	for _, f := range e.fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+func TestLogging_Response(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]any{"user": "alice", "token": "abc", "notes": "lorem ipsum dolor"}), nil
+	})
+	req := &protocol.Request{ID: json.RawMessage(`1`), Method: "tools/call"}
+	size := len(`{"notes":"lorem ipsum dolor","token":"abc","user":"alice"}`)
+
+	tests := []struct {
+		name         string
+		opts         []LoggingOption
+		wantSize     any
+		wantResponse any
+	}{
+		{"off by default", nil, nil, nil},
+		{"size only", []LoggingOption{WithLogResponseSize()}, size, nil},
+		{
+			"redacted body", []LoggingOption{WithLogResponse()},
+			size, `{"notes":"lorem ipsum dolor","token":"[REDACTED]","user":"alice"}`,
+		},
+		{
+			"truncated body", []LoggingOption{WithLogResponse(), WithLogRedactor(&Redactor{Keys: []string{"token"}, MaxBytes: 10})},
+			size, `{"notes":"...(55 bytes truncated)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &mockLogger{}
+			_, _ = Logging(logger, tt.opts...)(handler)(context.Background(), req)
+			if len(logger.entries) != 1 {
+				t.Fatalf("expected 1 log entry, got %d", len(logger.entries))
+			}
+			if got := logger.entries[0].field("response_size"); got != tt.wantSize {
+				t.Errorf("response_size = %v, want %v", got, tt.wantSize)
+			}
+			if got := logger.entries[0].field("response"); got != tt.wantResponse {
+				t.Errorf("response = %v, want %v", got, tt.wantResponse)
+			}
+		})
+	}
+}
+
+func TestLogging_Sampling(t *testing.T) {
+	var fail bool
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	draws := []float64{0.05, 0.5, 0.95, 0.2}
+	withDraws := func(c *loggingConfig) {
+		c.random = func() float64 {
+			v := draws[0]
+			draws = draws[1:]
+			return v
+		}
+	}
+	logger := &mockLogger{}
+	wrapped := Logging(logger, WithLogSampling(0.25), withDraws)(handler)
+	req := &protocol.Request{ID: json.RawMessage(`1`), Method: "tools/list"}
+
+	for range 4 {
+		_, _ = wrapped(context.Background(), req)
+	}
+	if len(logger.entries) != 2 {
+		t.Errorf("logged %d of 4 requests, want 2", len(logger.entries))
+	}
+
+	fail = true
+	_, _ = wrapped(context.Background(), req)
+	if len(logger.entries) != 3 || logger.entries[2].level != "error" {
+		t.Errorf("expected failed requests to be logged, got %+v", logger.entries)
+	}
+}
+
+func TestLogging_SlowThreshold(t *testing.T) {
+	delay := 20 * time.Millisecond
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		time.Sleep(delay)
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	logger := &mockLogger{}
+	wrapped := Logging(logger, WithLogSlowThreshold(10*time.Millisecond), WithLogSampling(0))(handler)
+	req := &protocol.Request{ID: json.RawMessage(`1`), Method: "tools/call"}
+
+	_, _ = wrapped(context.Background(), req)
+	if len(logger.entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(logger.entries))
+	}
+	if e := logger.entries[0]; e.level != "warn" || e.message != "slow request" || e.field("slow") != true {
+		t.Errorf("entry = %+v, want a slow request warning", e)
+	}
+
+	delay = 0
+	_, _ = wrapped(context.Background(), req)
+	if len(logger.entries) != 1 {
+		t.Errorf("expected fast requests to be sampled out, got %d entries", len(logger.entries))
+	}
 }
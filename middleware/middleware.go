@@ -4,9 +4,10 @@ import "time"
 
 // DefaultStack returns the recommended production middleware stack.
 // This includes panic recovery, request ID injection, and logging.
+// Recovered panics are logged to logger with their stack traces.
 func DefaultStack(logger Logger) []Middleware {
 	return []Middleware{
-		Recover(),
+		Recover(WithRecoverLogger(logger)),
 		RequestID(),
 		Logging(logger),
 	}
@@ -15,7 +16,7 @@ func DefaultStack(logger Logger) []Middleware {
 // DefaultStackWithTimeout returns the default stack with a timeout middleware.
 func DefaultStackWithTimeout(logger Logger, timeout time.Duration) []Middleware {
 	return []Middleware{
-		Recover(),
+		Recover(WithRecoverLogger(logger)),
 		RequestID(),
 		Timeout(timeout),
 		Logging(logger),
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -59,7 +60,19 @@ func WithOTelSkipMethods(methods ...string) OTelOption {
 }
 
 // OTel returns middleware that adds OpenTelemetry tracing and metrics.
-// It creates spans for each request and records request counts and latency.
+// It creates spans for each request and records, through the configured
+// MeterProvider:
+//
+//   - mcp.server.requests: requests received, by method
+//   - mcp.server.request.duration: a histogram of request latency in milliseconds
+//   - mcp.server.errors: failed requests, by method and error code
+//   - mcp.server.requests.in_flight: requests being handled
+//   - mcp.server.tool.calls: tools/call requests, by tool and whether they failed
+//
+// Measurements are recorded with the context of the request span, so a
+// meter provider whose exemplar filter samples traced measurements, as the
+// SDK's default trace-based filter does, links histogram buckets and
+// counters to the trace and span IDs of example requests.
 func OTel(opts ...OTelOption) Middleware {
 	cfg := &otelConfig{
 		tracerProvider: otel.GetTracerProvider(),
@@ -80,25 +93,7 @@ func OTel(opts ...OTelOption) Middleware {
 		instrumentationName,
 		metric.WithInstrumentationVersion("1.0.0"),
 	)
-
-	// Create metrics instruments
-	requestCounter, _ := meter.Int64Counter(
-		"mcp.server.requests",
-		metric.WithDescription("Total number of MCP requests"),
-		metric.WithUnit("{request}"),
-	)
-
-	requestDuration, _ := meter.Float64Histogram(
-		"mcp.server.request.duration",
-		metric.WithDescription("Duration of MCP requests"),
-		metric.WithUnit("ms"),
-	)
-
-	errorCounter, _ := meter.Int64Counter(
-		"mcp.server.errors",
-		metric.WithDescription("Total number of MCP errors"),
-		metric.WithUnit("{error}"),
-	)
+	m := newOTelMetrics(meter)
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
@@ -123,6 +118,12 @@ func OTel(opts ...OTelOption) Middleware {
 				span.SetAttributes(attribute.String("mcp.request_id", reqID))
 			}
 
+			tool := ""
+			if req.Method == protocol.MethodToolsCall {
+				tool = toolName(req.Params)
+				span.SetAttributes(attribute.String("mcp.tool.name", tool))
+			}
+
 			// Record start time for duration metric
 			startTime := time.Now()
 
@@ -132,46 +133,144 @@ func OTel(opts ...OTelOption) Middleware {
 				attribute.String("service.name", cfg.serviceName),
 			}
 
-			// Increment request counter
-			requestCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
+			// Increment request counter; the span context of ctx lets
+			// the meter provider attach exemplars
+			m.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+			m.inFlight.Add(ctx, 1, metric.WithAttributes(attrs...))
 
 			// Execute handler
 			resp, err := next(ctx, req)
 
-			// Record duration
-			duration := float64(time.Since(startTime).Milliseconds())
-			requestDuration.Record(ctx, duration, metric.WithAttributes(attrs...))
+			m.inFlight.Add(ctx, -1, metric.WithAttributes(attrs...))
+
+			// Record duration, keeping sub-millisecond precision
+			duration := float64(time.Since(startTime)) / float64(time.Millisecond)
+			m.duration.Record(ctx, duration, metric.WithAttributes(attrs...))
 
 			// Record result
+			code, failed := errorCode(resp, err)
 			switch {
 			case err != nil:
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
-
-				var mcpErr *protocol.Error
-				if errors.As(err, &mcpErr) {
-					span.SetAttributes(attribute.Int("mcp.error_code", mcpErr.Code))
-					errorCounter.Add(ctx, 1, metric.WithAttributes(
-						append(attrs, attribute.Int("mcp.error_code", mcpErr.Code))...,
-					))
-				} else {
-					errorCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
-				}
-			case resp != nil && resp.Error != nil:
+			case failed:
 				span.SetStatus(codes.Error, resp.Error.Message)
-				span.SetAttributes(attribute.Int("mcp.error_code", resp.Error.Code))
-				errorCounter.Add(ctx, 1, metric.WithAttributes(
-					append(attrs, attribute.Int("mcp.error_code", resp.Error.Code))...,
-				))
 			default:
 				span.SetStatus(codes.Ok, "")
 			}
+			if failed {
+				errAttrs := attrs
+				if code != 0 {
+					span.SetAttributes(attribute.Int("mcp.error_code", code))
+					errAttrs = append(errAttrs, attribute.Int("mcp.error_code", code))
+				}
+				m.errors.Add(ctx, 1, metric.WithAttributes(errAttrs...))
+			}
+
+			// Calls of tools that do not exist are left out, so clients
+			// cannot add tool names to the metrics
+			if req.Method == protocol.MethodToolsCall && code != protocol.CodeNotFound {
+				toolFailed := failed || isToolError(resp)
+				if toolFailed {
+					span.SetAttributes(attribute.Bool("mcp.tool.error", true))
+				}
+				m.toolCalls.Add(ctx, 1, metric.WithAttributes(
+					attribute.String("mcp.tool.name", tool),
+					attribute.Bool("mcp.tool.error", toolFailed),
+					attribute.String("service.name", cfg.serviceName),
+				))
+			}
 
 			return resp, err
 		}
 	}
 }
 
+// otelMetrics holds the instruments of the OTel middleware.
+type otelMetrics struct {
+	requests  metric.Int64Counter
+	duration  metric.Float64Histogram
+	errors    metric.Int64Counter
+	inFlight  metric.Int64UpDownCounter
+	toolCalls metric.Int64Counter
+}
+
+// newOTelMetrics creates the instruments of the OTel middleware. Meters
+// return a working no-op instrument along with any error, so errors are
+// ignored rather than failing the server.
+func newOTelMetrics(meter metric.Meter) *otelMetrics {
+	m := &otelMetrics{}
+	m.requests, _ = meter.Int64Counter(
+		"mcp.server.requests",
+		metric.WithDescription("Total number of MCP requests"),
+		metric.WithUnit("{request}"),
+	)
+	m.duration, _ = meter.Float64Histogram(
+		"mcp.server.request.duration",
+		metric.WithDescription("Duration of MCP requests"),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000),
+	)
+	m.errors, _ = meter.Int64Counter(
+		"mcp.server.errors",
+		metric.WithDescription("Total number of MCP errors"),
+		metric.WithUnit("{error}"),
+	)
+	m.inFlight, _ = meter.Int64UpDownCounter(
+		"mcp.server.requests.in_flight",
+		metric.WithDescription("Number of MCP requests being handled"),
+		metric.WithUnit("{request}"),
+	)
+	m.toolCalls, _ = meter.Int64Counter(
+		"mcp.server.tool.calls",
+		metric.WithDescription("Total number of MCP tool calls"),
+		metric.WithUnit("{call}"),
+	)
+	return m
+}
+
+// errorCode reports whether a request failed and the protocol error code
+// it failed with, or 0 if the error is not a protocol error.
+func errorCode(resp *protocol.Response, err error) (int, bool) {
+	if err != nil {
+		var mcpErr *protocol.Error
+		if errors.As(err, &mcpErr) {
+			return mcpErr.Code, true
+		}
+		return 0, true
+	}
+	if resp != nil && resp.Error != nil {
+		return resp.Error.Code, true
+	}
+	return 0, false
+}
+
+// isToolError reports whether resp is a tool result marked as an error.
+func isToolError(resp *protocol.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch result := resp.Result.(type) {
+	case protocol.CallToolResult:
+		return result.IsError
+	case *protocol.CallToolResult:
+		return result != nil && result.IsError
+	}
+	return false
+}
+
+// toolName returns the tool name of tools/call params, or "" if there is
+// none.
+func toolName(params json.RawMessage) string {
+	var call struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return ""
+	}
+	return call.Name
+}
+
 // SpanFromContext returns the current span from context.
 // Returns a no-op span if no span is present.
 func SpanFromContext(ctx context.Context) trace.Span {
@@ -9,8 +9,10 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
@@ -203,6 +205,96 @@ func TestOTelMiddleware(t *testing.T) {
 	})
 }
 
+func TestOTelMetrics(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	var inFlight int64
+	traceIDs := make(map[trace.TraceID]bool)
+	handler := OTel(WithTracerProvider(tp), WithMeterProvider(mp))(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		inFlight = collectMetrics(t, reader)["mcp.server.requests.in_flight"].(metricdata.Sum[int64]).DataPoints[0].Value
+		traceIDs[trace.SpanContextFromContext(ctx).TraceID()] = true
+		switch string(req.Params) {
+		case `{"name":"search"}`:
+			return protocol.NewResponse(req.ID, protocol.CallToolResult{IsError: true}), nil
+		case `{"name":"missing"}`:
+			return nil, protocol.NewNotFound("tool not found: missing")
+		}
+		return protocol.NewResponse(req.ID, protocol.CallToolResult{}), nil
+	})
+
+	for _, params := range []string{`{"name":"echo"}`, `{"name":"search"}`, `{"name":"missing"}`} {
+		req := &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsCall, Params: json.RawMessage(params)}
+		_, _ = handler(context.Background(), req)
+	}
+	if inFlight != 1 {
+		t.Errorf("in-flight during the request = %d, want 1", inFlight)
+	}
+
+	metrics := collectMetrics(t, reader)
+
+	if got := metrics["mcp.server.requests.in_flight"].(metricdata.Sum[int64]).DataPoints[0].Value; got != 0 {
+		t.Errorf("in-flight after the requests = %d, want 0", got)
+	}
+
+	duration := metrics["mcp.server.request.duration"].(metricdata.Histogram[float64]).DataPoints[0]
+	if duration.Count != 3 {
+		t.Errorf("duration count = %d, want 3", duration.Count)
+	}
+	if duration.Sum <= 0 {
+		t.Errorf("duration sum = %v, want sub-millisecond durations to be kept", duration.Sum)
+	}
+	if len(duration.Exemplars) == 0 {
+		t.Fatal("expected exemplars on the duration histogram")
+	}
+	for _, e := range duration.Exemplars {
+		if !traceIDs[trace.TraceID(e.TraceID)] {
+			t.Errorf("exemplar trace ID %x is not the trace of a request", e.TraceID)
+		}
+	}
+
+	errs := metrics["mcp.server.errors"].(metricdata.Sum[int64]).DataPoints
+	if len(errs) != 1 || errs[0].Value != 1 {
+		t.Fatalf("error data points = %+v, want one error", errs)
+	}
+	if code, _ := errs[0].Attributes.Value("mcp.error_code"); code.AsInt64() != protocol.CodeNotFound {
+		t.Errorf("error code = %d, want %d", code.AsInt64(), protocol.CodeNotFound)
+	}
+
+	calls := make(map[string]bool)
+	for _, dp := range metrics["mcp.server.tool.calls"].(metricdata.Sum[int64]).DataPoints {
+		name, _ := dp.Attributes.Value("mcp.tool.name")
+		failed, _ := dp.Attributes.Value("mcp.tool.error")
+		calls[name.AsString()] = failed.AsBool()
+		if dp.Value != 1 {
+			t.Errorf("calls of %s = %d, want 1", name.AsString(), dp.Value)
+		}
+	}
+	want := map[string]bool{"echo": false, "search": true}
+	if len(calls) != len(want) || calls["echo"] != want["echo"] || calls["search"] != want["search"] {
+		t.Errorf("tool calls = %v, want %v", calls, want)
+	}
+}
+
+// collectMetrics returns the metrics of reader by name.
+func collectMetrics(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
 func TestSpanHelpers(t *testing.T) {
 	t.Run("SpanFromContext returns span", func(t *testing.T) {
 		exporter := tracetest.NewInMemoryExporter()
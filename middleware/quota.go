@@ -0,0 +1,360 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// QuotaPeriod is the period over which a quota counts requests.
+type QuotaPeriod string
+
+// Quota periods.
+const (
+	// QuotaDaily counts requests from midnight to midnight.
+	QuotaDaily QuotaPeriod = "daily"
+	// QuotaMonthly counts requests from the first of the month.
+	QuotaMonthly QuotaPeriod = "monthly"
+)
+
+// QuotaPolicy sets how many requests an identity may make per day and per
+// month. Zero or less leaves a period unlimited.
+type QuotaPolicy struct {
+	Daily   int64
+	Monthly int64
+
+	// LimitsFunc, if set, returns the daily and monthly limits of an
+	// identity instead, such as the limits of its plan. It receives a nil
+	// identity for unauthenticated requests.
+	LimitsFunc func(identity *Identity) (daily, monthly int64)
+}
+
+// limits returns the daily and monthly limits of identity.
+func (p *QuotaPolicy) limits(identity *Identity) (daily, monthly int64) {
+	if p.LimitsFunc != nil {
+		return p.LimitsFunc(identity)
+	}
+	return p.Daily, p.Monthly
+}
+
+// QuotaInfo describes an exceeded quota. It is sent as the data of the
+// rate limited error Quota returns.
+type QuotaInfo struct {
+	Period    QuotaPeriod `json:"period"`
+	Limit     int64       `json:"limit"`
+	Used      int64       `json:"used"`
+	Remaining int64       `json:"remaining"`
+	ResetAt   time.Time   `json:"resetAt"`
+
+	// RetryAfter is the number of seconds until the quota resets.
+	RetryAfter int `json:"retryAfter"`
+}
+
+// QuotaStore holds the request counters of Quota.
+type QuotaStore interface {
+	// Increment adds n, which may be negative, to the counter of key and
+	// returns the new count. A counter that does not exist or has expired
+	// starts at zero and expires at expiresAt.
+	Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error)
+}
+
+// QuotaOption configures the Quota middleware.
+type QuotaOption func(*quotaConfig)
+
+type quotaConfig struct {
+	store    QuotaStore
+	methods  map[string]bool
+	location *time.Location
+	logger   Logger
+	failOpen bool
+	now      func() time.Time
+}
+
+// WithQuotaStore sets the store of the request counters. By default they
+// are kept in process memory; use a RedisQuotaStore to share them between
+// server instances.
+func WithQuotaStore(store QuotaStore) QuotaOption {
+	return func(c *quotaConfig) {
+		c.store = store
+	}
+}
+
+// WithQuotaMethods limits the quota to requests for methods, such as
+// "tools/call". By default every request except "initialize", "ping" and
+// notifications counts.
+func WithQuotaMethods(methods ...string) QuotaOption {
+	return func(c *quotaConfig) {
+		if c.methods == nil {
+			c.methods = make(map[string]bool, len(methods))
+		}
+		for _, m := range methods {
+			c.methods[m] = true
+		}
+	}
+}
+
+// WithQuotaLocation sets the time zone in which days and months start. It
+// defaults to UTC.
+func WithQuotaLocation(loc *time.Location) QuotaOption {
+	return func(c *quotaConfig) {
+		c.location = loc
+	}
+}
+
+// WithQuotaLogger sets the logger for exceeded quotas and store errors.
+func WithQuotaLogger(l Logger) QuotaOption {
+	return func(c *quotaConfig) {
+		c.logger = l
+	}
+}
+
+// WithQuotaFailOpen allows requests when the store fails. By default they
+// are rejected with an unavailable error.
+func WithQuotaFailOpen() QuotaOption {
+	return func(c *quotaConfig) {
+		c.failOpen = true
+	}
+}
+
+// Quota returns middleware that limits how many requests each identity may
+// make per day and per month, keyed on the ID of the Identity set by Auth,
+// so it must come after Auth in the chain. Unauthenticated requests share
+// one quota.
+//
+// Requests over a quota are rejected with a rate limited error
+// (CodeRateLimited) whose data is a QuotaInfo, telling the client the
+// limit and when it resets. Rejected requests do not count.
+//
+// Example:
+//
+//	mw := middleware.Quota(middleware.QuotaPolicy{Daily: 1000, Monthly: 20000},
+//	    middleware.WithQuotaMethods("tools/call"),
+//	)
+func Quota(policy QuotaPolicy, opts ...QuotaOption) Middleware {
+	cfg := &quotaConfig{
+		location: time.UTC,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.store == nil {
+		cfg.store = NewMemoryQuotaStore()
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if !cfg.counts(req) {
+				return next(ctx, req)
+			}
+			if err := cfg.charge(ctx, req, &policy); err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// counts reports whether req counts towards the quota.
+func (c *quotaConfig) counts(req *protocol.Request) bool {
+	if req.IsNotification() {
+		return false
+	}
+	if c.methods != nil {
+		return c.methods[req.Method]
+	}
+	return req.Method != protocol.MethodInitialize && req.Method != protocol.MethodPing
+}
+
+// quotaWindow is the current period of a quota.
+type quotaWindow struct {
+	period QuotaPeriod
+	limit  int64
+	label  string
+	end    time.Time
+}
+
+// windows returns the current periods of the limits, skipping unlimited
+// ones.
+func (c *quotaConfig) windows(daily, monthly int64) []quotaWindow {
+	now := c.now().In(c.location)
+	y, m, d := now.Date()
+	var windows []quotaWindow
+	if daily > 0 {
+		windows = append(windows, quotaWindow{
+			period: QuotaDaily, limit: daily, label: now.Format("2006-01-02"),
+			end: time.Date(y, m, d+1, 0, 0, 0, 0, c.location),
+		})
+	}
+	if monthly > 0 {
+		windows = append(windows, quotaWindow{
+			period: QuotaMonthly, limit: monthly, label: now.Format("2006-01"),
+			end: time.Date(y, m+1, 1, 0, 0, 0, 0, c.location),
+		})
+	}
+	return windows
+}
+
+// charge counts req against the quotas of its identity, returning an error
+// if one is exceeded. The counts are taken back when req is rejected.
+func (c *quotaConfig) charge(ctx context.Context, req *protocol.Request, policy *QuotaPolicy) error {
+	key := identityKey(ctx)
+	windows := c.windows(policy.limits(IdentityFromContext(ctx)))
+
+	charged := make([]string, 0, len(windows))
+	refund := func() {
+		for _, k := range charged {
+			_, _ = c.store.Increment(context.WithoutCancel(ctx), k, -1, time.Time{})
+		}
+	}
+	for _, w := range windows {
+		counterKey := key + ":" + string(w.period) + ":" + w.label
+		used, err := c.store.Increment(ctx, counterKey, 1, w.end)
+		if err != nil {
+			refund()
+			return c.storeFailed(req, err)
+		}
+		charged = append(charged, counterKey)
+		if used > w.limit {
+			refund()
+			return c.exceeded(req, key, w, used-1)
+		}
+	}
+	return nil
+}
+
+func (c *quotaConfig) storeFailed(req *protocol.Request, err error) error {
+	if c.logger != nil {
+		c.logger.Error("quota store failed",
+			Field{Key: "method", Value: req.Method},
+			Field{Key: "error", Value: err.Error()},
+		)
+	}
+	if c.failOpen {
+		return nil
+	}
+	return protocol.NewUnavailable("quota unavailable")
+}
+
+func (c *quotaConfig) exceeded(req *protocol.Request, key string, w quotaWindow, used int64) error {
+	if c.logger != nil {
+		c.logger.Warn("quota exceeded",
+			Field{Key: "method", Value: req.Method},
+			Field{Key: "key", Value: key},
+			Field{Key: "period", Value: string(w.period)},
+		)
+	}
+	retryAfter := int(w.end.Sub(c.now()).Round(time.Second) / time.Second)
+	return &protocol.Error{
+		Code:    protocol.CodeRateLimited,
+		Message: string(w.period) + " quota exceeded",
+		Data: QuotaInfo{
+			Period:     w.period,
+			Limit:      w.limit,
+			Used:       used,
+			Remaining:  max(w.limit-used, 0),
+			ResetAt:    w.end.UTC(),
+			RetryAfter: max(retryAfter, 1),
+		},
+	}
+}
+
+// MemoryQuotaStore is a QuotaStore that keeps the counters in process
+// memory. Expired counters are removed as new ones are added.
+type MemoryQuotaStore struct {
+	mu        sync.Mutex
+	counters  map[string]quotaCounter
+	lastSweep time.Time
+}
+
+type quotaCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewMemoryQuotaStore returns an empty MemoryQuotaStore.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: make(map[string]quotaCounter)}
+}
+
+// Increment adds n to the counter of key.
+func (s *MemoryQuotaStore) Increment(_ context.Context, key string, n int64, expiresAt time.Time) (int64, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, c := range s.counters {
+			if now.After(c.expiresAt) {
+				delete(s.counters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expiresAt) {
+		c = quotaCounter{expiresAt: expiresAt}
+	}
+	c.count += n
+	s.counters[key] = c
+	return c.count, nil
+}
+
+// redisIncrementScript adds ARGV[1] to the counter and sets it to expire
+// at ARGV[2], in Unix milliseconds, unless that is zero.
+const redisIncrementScript = `
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+if ARGV[2] ~= '0' then
+  redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+end
+return n`
+
+// RedisQuotaStore is a QuotaStore that keeps the counters in Redis, so that
+// server instances behind a load balancer share the quotas.
+type RedisQuotaStore struct {
+	client RedisEvaler
+	prefix string
+}
+
+// RedisQuotaOption configures a RedisQuotaStore.
+type RedisQuotaOption func(*RedisQuotaStore)
+
+// WithRedisQuotaKeyPrefix sets the prefix of the Redis keys of the
+// counters. It defaults to "mcp:quota:".
+func WithRedisQuotaKeyPrefix(prefix string) RedisQuotaOption {
+	return func(s *RedisQuotaStore) {
+		s.prefix = prefix
+	}
+}
+
+// NewRedisQuotaStore returns a QuotaStore backed by the Redis client.
+func NewRedisQuotaStore(client RedisEvaler, opts ...RedisQuotaOption) *RedisQuotaStore {
+	s := &RedisQuotaStore{client: client, prefix: "mcp:quota:"}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Increment adds n to the counter of key.
+func (s *RedisQuotaStore) Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error) {
+	var expires int64
+	if !expiresAt.IsZero() {
+		expires = expiresAt.UnixMilli()
+	}
+	reply, err := s.client.Eval(ctx, redisIncrementScript, []string{s.prefix + key},
+		strconv.FormatInt(n, 10), strconv.FormatInt(expires, 10))
+	if err != nil {
+		return 0, fmt.Errorf("redis quota store: %w", err)
+	}
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis quota store: unexpected reply %T", reply)
+	}
+	return count, nil
+}
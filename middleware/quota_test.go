@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// withQuotaClock makes Quota read the time from now.
+func withQuotaClock(now *time.Time) QuotaOption {
+	return func(c *quotaConfig) {
+		c.now = func() time.Time { return *now }
+	}
+}
+
+func quotaInfo(t *testing.T, err error) QuotaInfo {
+	t.Helper()
+	var perr *protocol.Error
+	if !errors.As(err, &perr) || perr.Code != protocol.CodeRateLimited {
+		t.Fatalf("error = %v, want rate limited", err)
+	}
+	info, ok := perr.Data.(QuotaInfo)
+	if !ok {
+		t.Fatalf("data = %#v, want QuotaInfo", perr.Data)
+	}
+	return info
+}
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	called := 0
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		called++
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	logger := &mockLogger{}
+	wrapped := Quota(QuotaPolicy{Daily: 2, Monthly: 3}, withQuotaClock(&now), WithQuotaLogger(logger))(handler)
+
+	alice := ContextWithIdentity(context.Background(), &Identity{ID: "alice"})
+	bob := ContextWithIdentity(context.Background(), &Identity{ID: "bob"})
+	req := &protocol.Request{ID: json.RawMessage(`1`), Method: protocol.MethodToolsCall}
+
+	for i := range 2 {
+		if _, err := wrapped(alice, req); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+
+	_, err := wrapped(alice, req)
+	info := quotaInfo(t, err)
+	want := QuotaInfo{
+		Period: QuotaDaily, Limit: 2, Used: 2, Remaining: 0,
+		ResetAt: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), RetryAfter: 3600,
+	}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+	if called != 2 {
+		t.Errorf("handler called %d times, want 2", called)
+	}
+	if len(logger.entries) != 1 || logger.entries[0].message != "quota exceeded" {
+		t.Errorf("log entries = %+v", logger.entries)
+	}
+
+	t.Run("identities have their own quota", func(t *testing.T) {
+		if _, err := wrapped(bob, req); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("rejected requests do not count", func(t *testing.T) {
+		// The daily rejection above must not have used up the third
+		// request of the month.
+		now = now.Add(2 * time.Hour)
+		if _, err := wrapped(alice, req); err != nil {
+			t.Fatalf("unexpected error on a new day: %v", err)
+		}
+		_, err := wrapped(alice, req)
+		if info := quotaInfo(t, err); info.Period != QuotaMonthly || info.Used != 3 {
+			t.Errorf("info = %+v, want the monthly quota used up", info)
+		}
+	})
+
+	t.Run("skips initialize, ping and notifications", func(t *testing.T) {
+		for _, r := range []*protocol.Request{
+			{ID: json.RawMessage(`1`), Method: protocol.MethodPing},
+			{ID: json.RawMessage(`1`), Method: protocol.MethodInitialize},
+			{Method: protocol.MethodInitialized},
+		} {
+			if _, err := wrapped(alice, r); err != nil {
+				t.Errorf("%s: unexpected error: %v", r.Method, err)
+			}
+		}
+	})
+}
+
+func TestQuota_Options(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	call := &protocol.Request{ID: json.RawMessage(`1`), Method: protocol.MethodToolsCall}
+	list := &protocol.Request{ID: json.RawMessage(`1`), Method: protocol.MethodToolsList}
+	ctx := ContextWithIdentity(context.Background(), &Identity{ID: "alice", Metadata: map[string]any{"plan": "pro"}})
+
+	t.Run("methods", func(t *testing.T) {
+		wrapped := Quota(QuotaPolicy{Daily: 1}, WithQuotaMethods(protocol.MethodToolsCall))(handler)
+		for range 3 {
+			if _, err := wrapped(ctx, list); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if _, err := wrapped(ctx, call); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := wrapped(ctx, call); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("error = %v, want rate limited", err)
+		}
+	})
+
+	t.Run("limits func", func(t *testing.T) {
+		policy := QuotaPolicy{LimitsFunc: func(identity *Identity) (int64, int64) {
+			if identity != nil && identity.Metadata["plan"] == "pro" {
+				return 0, 2
+			}
+			return 0, 1
+		}}
+		wrapped := Quota(policy)(handler)
+		for range 2 {
+			if _, err := wrapped(ctx, call); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if _, err := wrapped(context.Background(), call); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := wrapped(context.Background(), call); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("error = %v, want unauthenticated requests to share a quota", err)
+		}
+	})
+
+	t.Run("location", func(t *testing.T) {
+		now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+		tokyo := time.FixedZone("JST", 9*60*60)
+		wrapped := Quota(QuotaPolicy{Daily: 1}, WithQuotaLocation(tokyo), withQuotaClock(&now))(handler)
+		_, _ = wrapped(ctx, call)
+		_, err := wrapped(ctx, call)
+		if got, want := quotaInfo(t, err).ResetAt, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("ResetAt = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("store failure", func(t *testing.T) {
+		redis := newFakeRedis()
+		redis.err = errors.New("connection refused")
+		store := WithQuotaStore(NewRedisQuotaStore(redis))
+		if _, err := Quota(QuotaPolicy{Daily: 1}, store)(handler)(ctx, call); !errors.Is(err, protocol.NewUnavailable("")) {
+			t.Errorf("error = %v, want unavailable", err)
+		}
+		if _, err := Quota(QuotaPolicy{Daily: 1}, store, WithQuotaFailOpen())(handler)(ctx, call); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestQuotaStores(t *testing.T) {
+	ctx := context.Background()
+	redis := newFakeRedis()
+	expires := time.Now().Add(time.Hour)
+
+	stores := []struct {
+		name  string
+		store QuotaStore
+	}{
+		{"memory", NewMemoryQuotaStore()},
+		{"redis", NewRedisQuotaStore(redis, WithRedisQuotaKeyPrefix("test:"))},
+	}
+	for _, tt := range stores {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range []int64{1, 2, 3} {
+				if got, err := tt.store.Increment(ctx, "k", 1, expires); err != nil || got != want {
+					t.Fatalf("increment %d = %d, %v, want %d", i, got, err, want)
+				}
+			}
+			if got, err := tt.store.Increment(ctx, "k", -1, time.Time{}); err != nil || got != 2 {
+				t.Errorf("refund = %d, %v, want 2", got, err)
+			}
+			if got, _ := tt.store.Increment(ctx, "other", 1, expires); got != 1 {
+				t.Errorf("other key = %d, want 1", got)
+			}
+		})
+	}
+
+	if got, want := redis.ttls["test:k"], strconv.FormatInt(expires.UnixMilli(), 10); got != want {
+		t.Errorf("expiry = %s, want %s", got, want)
+	}
+
+	t.Run("memory counters expire", func(t *testing.T) {
+		store := NewMemoryQuotaStore()
+		_, _ = store.Increment(ctx, "k", 5, time.Now().Add(-time.Second))
+		if got, _ := store.Increment(ctx, "k", 1, expires); got != 1 {
+			t.Errorf("count = %d, want an expired counter to restart", got)
+		}
+	})
+}
@@ -9,19 +9,39 @@ import (
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
+// RateLimitStore holds the token buckets of the rate limiter. The default
+// store keeps them in process memory, so each server instance enforces its
+// own limits; a shared store such as RedisRateLimitStore enforces them
+// across all instances behind a load balancer.
+//
+// AtomicUpdate must apply its update function to the stored bucket (nil if
+// there is none) and store the result as one atomic step.
+type RateLimitStore = ratelimit.Store
+
+// RateLimitBucket is the token bucket state kept in a RateLimitStore.
+type RateLimitBucket = ratelimit.BucketState
+
+// NewMemoryRateLimitStore returns the in-process store RateLimit uses by
+// default, for sharing buckets between several RateLimit middleware.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return ratelimit.NewMemoryStore()
+}
+
 // RateLimitOption configures the rate limiter.
 type RateLimitOption func(*rateLimitConfig)
 
 type rateLimitConfig struct {
-	keyFunc func(*protocol.Request) string
-	logger  Logger
+	keyFunc  func(context.Context, *protocol.Request) string
+	logger   Logger
+	store    RateLimitStore
+	failOpen bool
 }
 
 // WithRateLimitKeyFunc sets a function to extract a rate limit key from requests.
 // This allows per-client or per-method rate limiting.
 func WithRateLimitKeyFunc(fn func(*protocol.Request) string) RateLimitOption {
 	return func(o *rateLimitConfig) {
-		o.keyFunc = fn
+		o.keyFunc = func(_ context.Context, req *protocol.Request) string { return fn(req) }
 	}
 }
 
@@ -32,12 +52,28 @@ func WithRateLimitLogger(l Logger) RateLimitOption {
 	}
 }
 
+// WithRateLimitStore sets the store of the token buckets. By default they
+// are kept in process memory.
+func WithRateLimitStore(store RateLimitStore) RateLimitOption {
+	return func(o *rateLimitConfig) {
+		o.store = store
+	}
+}
+
+// WithRateLimitFailOpen allows requests when the store fails, such as when
+// Redis is unreachable. By default they are rejected as rate limited.
+func WithRateLimitFailOpen() RateLimitOption {
+	return func(o *rateLimitConfig) {
+		o.failOpen = true
+	}
+}
+
 // RateLimit returns middleware that limits request rate using a token bucket algorithm.
 // The rate is specified as requests per second.
 // Burst allows short bursts above the rate limit.
 func RateLimit(rate int, burst int, opts ...RateLimitOption) Middleware {
 	cfg := &rateLimitConfig{
-		keyFunc: func(_ *protocol.Request) string { return "global" }, // Global by default
+		keyFunc: func(context.Context, *protocol.Request) string { return "global" }, // Global by default
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -48,11 +84,13 @@ func RateLimit(rate int, burst int, opts ...RateLimitOption) Middleware {
 		Rate:     rate,
 		Burst:    burst,
 		Interval: time.Second,
+		Store:    cfg.store,
+		FailOpen: cfg.failOpen,
 	})
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-			key := cfg.keyFunc(req)
+			key := cfg.keyFunc(ctx, req)
 
 			if !limiter.Allow(ctx, key) {
 				if cfg.logger != nil {
@@ -90,3 +128,27 @@ func RateLimitByClient(rate int, burst int, clientIDFunc func(*protocol.Request)
 	}, opts...)
 	return RateLimit(rate, burst, allOpts...)
 }
+
+// RateLimitByIdentity returns rate limiting middleware that applies
+// per-identity limits, keyed on the ID of the Identity set by Auth, so it
+// must come after Auth in the chain. Unauthenticated requests share one
+// bucket.
+func RateLimitByIdentity(rate int, burst int, opts ...RateLimitOption) Middleware {
+	allOpts := append([]RateLimitOption{
+		func(o *rateLimitConfig) {
+			o.keyFunc = func(ctx context.Context, _ *protocol.Request) string {
+				return identityKey(ctx)
+			}
+		},
+	}, opts...)
+	return RateLimit(rate, burst, allOpts...)
+}
+
+// identityKey returns the key of the identity of ctx, or "anonymous" for
+// unauthenticated requests.
+func identityKey(ctx context.Context) string {
+	if identity := IdentityFromContext(ctx); identity != nil {
+		return "identity:" + identity.ID
+	}
+	return "anonymous"
+}
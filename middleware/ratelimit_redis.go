@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisEvaler runs Lua scripts on Redis. It is the only part of a Redis
+// client RedisRateLimitStore needs, so any client library can be used.
+type RedisEvaler interface {
+	// Eval runs script with the given keys and arguments, as the Redis
+	// EVAL command does, and returns its reply: a string or []byte for bulk
+	// replies and an int64 for integer replies.
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// RedisEvalFunc adapts a function to RedisEvaler. With go-redis:
+//
+//	eval := middleware.RedisEvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//	    return rdb.Eval(ctx, script, keys, args...).Result()
+//	})
+type RedisEvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// Eval calls f.
+func (f RedisEvalFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// Lua scripts of RedisRateLimitStore. Missing buckets are returned as ""
+// rather than nil, which some clients report as an error.
+const (
+	redisGetScript = `return redis.call('GET', KEYS[1]) or ''`
+
+	redisCompareAndSetScript = `
+local cur = redis.call('GET', KEYS[1]) or ''
+if cur ~= ARGV[1] then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1`
+
+	redisDeleteScript = `return redis.call('DEL', KEYS[1])`
+)
+
+// errRateLimitContention is returned when a bucket keeps changing while it
+// is being updated.
+var errRateLimitContention = errors.New("rate limit bucket update contended")
+
+// RedisRateLimitOption configures a RedisRateLimitStore.
+type RedisRateLimitOption func(*RedisRateLimitStore)
+
+// WithRedisKeyPrefix sets the prefix of the Redis keys of the buckets. It
+// defaults to "mcp:ratelimit:".
+func WithRedisKeyPrefix(prefix string) RedisRateLimitOption {
+	return func(s *RedisRateLimitStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithRedisTTL sets how long idle buckets are kept. It defaults to an hour
+// and must be at least the time an empty bucket takes to refill, since a
+// bucket that expires is full again.
+func WithRedisTTL(ttl time.Duration) RedisRateLimitOption {
+	return func(s *RedisRateLimitStore) {
+		s.ttl = ttl
+	}
+}
+
+// WithRedisMaxRetries sets how many times an update is retried when another
+// server instance changes the bucket at the same time. It defaults to 10.
+func WithRedisMaxRetries(n int) RedisRateLimitOption {
+	return func(s *RedisRateLimitStore) {
+		s.maxRetries = n
+	}
+}
+
+// RedisRateLimitStore is a RateLimitStore that keeps the token buckets in
+// Redis, so that server instances behind a load balancer share their rate
+// limits. Buckets are updated with an optimistic compare-and-set script
+// that is retried when another instance changed the bucket in between.
+type RedisRateLimitStore struct {
+	client     RedisEvaler
+	prefix     string
+	ttl        time.Duration
+	maxRetries int
+}
+
+// NewRedisRateLimitStore returns a RateLimitStore backed by the Redis client.
+//
+// Example:
+//
+//	store := middleware.NewRedisRateLimitStore(eval)
+//	mw := middleware.RateLimitByClient(10, 20, clientID, middleware.WithRateLimitStore(store))
+func NewRedisRateLimitStore(client RedisEvaler, opts ...RedisRateLimitOption) *RedisRateLimitStore {
+	s := &RedisRateLimitStore{
+		client:     client,
+		prefix:     "mcp:ratelimit:",
+		ttl:        time.Hour,
+		maxRetries: 10,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AtomicUpdate applies updateFn to the bucket of key and stores the result.
+func (s *RedisRateLimitStore) AtomicUpdate(ctx context.Context, key string, updateFn func(*RateLimitBucket) *RateLimitBucket) (*RateLimitBucket, error) {
+	redisKey := s.prefix + key
+	ttl := strconv.FormatInt(max(s.ttl.Milliseconds(), 1), 10)
+	for range s.maxRetries + 1 {
+		raw, err := s.get(ctx, redisKey)
+		if err != nil {
+			return nil, err
+		}
+		current, err := decodeRateLimitBucket(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		next := updateFn(current)
+		if next == nil {
+			return current, nil
+		}
+		reply, err := s.client.Eval(ctx, redisCompareAndSetScript, []string{redisKey}, raw, encodeRateLimitBucket(next), ttl)
+		if err != nil {
+			return nil, fmt.Errorf("redis rate limit store: %w", err)
+		}
+		if n, ok := reply.(int64); ok && n == 1 {
+			return next, nil
+		}
+	}
+	return nil, fmt.Errorf("redis rate limit store: %s: %w", key, errRateLimitContention)
+}
+
+// Get returns the bucket of key, or nil if there is none.
+func (s *RedisRateLimitStore) Get(ctx context.Context, key string) (*RateLimitBucket, error) {
+	raw, err := s.get(ctx, s.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRateLimitBucket(raw)
+}
+
+// Delete removes the bucket of key.
+func (s *RedisRateLimitStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.Eval(ctx, redisDeleteScript, []string{s.prefix + key}); err != nil {
+		return fmt.Errorf("redis rate limit store: %w", err)
+	}
+	return nil
+}
+
+// Close does nothing; the Redis client belongs to the caller.
+func (s *RedisRateLimitStore) Close() error {
+	return nil
+}
+
+func (s *RedisRateLimitStore) get(ctx context.Context, redisKey string) (string, error) {
+	reply, err := s.client.Eval(ctx, redisGetScript, []string{redisKey})
+	if err != nil {
+		return "", fmt.Errorf("redis rate limit store: %w", err)
+	}
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("redis rate limit store: unexpected reply %T", reply)
+}
+
+// encodeRateLimitBucket encodes b as "tokens:lastRefillUnixNano".
+func encodeRateLimitBucket(b *RateLimitBucket) string {
+	return strconv.FormatFloat(b.Tokens, 'g', -1, 64) + ":" + strconv.FormatInt(b.LastRefill.UnixNano(), 10)
+}
+
+// decodeRateLimitBucket decodes a bucket encoded by encodeRateLimitBucket;
+// "" is no bucket.
+func decodeRateLimitBucket(raw string) (*RateLimitBucket, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	tokens, refill, ok := strings.Cut(raw, ":")
+	if !ok {
+		return nil, fmt.Errorf("redis rate limit store: invalid bucket %q", raw)
+	}
+	t, err := strconv.ParseFloat(tokens, 64)
+	if err != nil {
+		return nil, fmt.Errorf("redis rate limit store: invalid bucket %q: %w", raw, err)
+	}
+	nanos, err := strconv.ParseInt(refill, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("redis rate limit store: invalid bucket %q: %w", raw, err)
+	}
+	return &RateLimitBucket{Tokens: t, LastRefill: time.Unix(0, nanos)}, nil
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// fakeRedis runs the scripts of RedisRateLimitStore and RedisQuotaStore on
+// an in-memory map.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]string
+	// beforeSet, if set, runs before each compare-and-set, to simulate
+	// writes by other server instances.
+	beforeSet func(data map[string]string)
+	err       error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: map[string]string{}, ttls: map[string]string{}}
+}
+
+func (r *fakeRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	switch script {
+	case redisGetScript:
+		return r.data[keys[0]], nil
+	case redisCompareAndSetScript:
+		if r.beforeSet != nil {
+			r.beforeSet(r.data)
+		}
+		if r.data[keys[0]] != args[0].(string) {
+			return int64(0), nil
+		}
+		r.data[keys[0]] = args[1].(string)
+		r.ttls[keys[0]] = args[2].(string)
+		return int64(1), nil
+	case redisIncrementScript:
+		n, _ := strconv.ParseInt(args[0].(string), 10, 64)
+		count, _ := strconv.ParseInt(r.data[keys[0]], 10, 64)
+		r.data[keys[0]] = strconv.FormatInt(count+n, 10)
+		if args[1].(string) != "0" {
+			r.ttls[keys[0]] = args[1].(string)
+		}
+		return count + n, nil
+	case redisDeleteScript:
+		delete(r.data, keys[0])
+		return int64(1), nil
+	}
+	return nil, errors.New("unknown script")
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	redis := newFakeRedis()
+	store := NewRedisRateLimitStore(redis, WithRedisKeyPrefix("test:"), WithRedisTTL(time.Minute))
+	refill := time.Unix(1700000000, 123)
+
+	got, err := store.AtomicUpdate(ctx, "client", func(b *RateLimitBucket) *RateLimitBucket {
+		if b != nil {
+			t.Errorf("bucket = %+v, want nil", b)
+		}
+		return &RateLimitBucket{Tokens: 4.5, LastRefill: refill}
+	})
+	if err != nil || got.Tokens != 4.5 {
+		t.Fatalf("AtomicUpdate() = %+v, %v", got, err)
+	}
+	if redis.data["test:client"] == "" || redis.ttls["test:client"] != "60000" {
+		t.Errorf("stored %q with TTL %q", redis.data["test:client"], redis.ttls["test:client"])
+	}
+
+	bucket, err := store.Get(ctx, "client")
+	if err != nil || bucket == nil || bucket.Tokens != 4.5 || !bucket.LastRefill.Equal(refill) {
+		t.Fatalf("Get() = %+v, %v", bucket, err)
+	}
+
+	t.Run("no-op update", func(t *testing.T) {
+		got, err := store.AtomicUpdate(ctx, "client", func(*RateLimitBucket) *RateLimitBucket { return nil })
+		if err != nil || got == nil || got.Tokens != 4.5 {
+			t.Errorf("AtomicUpdate() = %+v, %v, want the current bucket", got, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := store.Delete(ctx, "client"); err != nil {
+			t.Fatal(err)
+		}
+		if bucket, err := store.Get(ctx, "client"); bucket != nil || err != nil {
+			t.Errorf("Get() = %+v, %v, want no bucket", bucket, err)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		redis.data["test:bad"] = "garbage"
+		if _, err := store.Get(ctx, "bad"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestRedisRateLimitStore_Contention(t *testing.T) {
+	ctx := context.Background()
+	increment := func(b *RateLimitBucket) *RateLimitBucket {
+		if b == nil {
+			return &RateLimitBucket{Tokens: 1}
+		}
+		return &RateLimitBucket{Tokens: b.Tokens + 1, LastRefill: b.LastRefill}
+	}
+
+	t.Run("retries after a concurrent write", func(t *testing.T) {
+		redis := newFakeRedis()
+		writes := 0
+		redis.beforeSet = func(data map[string]string) {
+			if writes < 2 {
+				writes++
+				data["mcp:ratelimit:k"] = encodeRateLimitBucket(&RateLimitBucket{Tokens: 10})
+			}
+		}
+		store := NewRedisRateLimitStore(redis)
+		got, err := store.AtomicUpdate(ctx, "k", increment)
+		if err != nil || got.Tokens != 11 {
+			t.Errorf("AtomicUpdate() = %+v, %v, want 11 tokens", got, err)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		redis := newFakeRedis()
+		n := 0.0
+		redis.beforeSet = func(data map[string]string) {
+			n++
+			data["mcp:ratelimit:k"] = encodeRateLimitBucket(&RateLimitBucket{Tokens: n})
+		}
+		store := NewRedisRateLimitStore(redis, WithRedisMaxRetries(2))
+		if _, err := store.AtomicUpdate(ctx, "k", increment); !errors.Is(err, errRateLimitContention) {
+			t.Errorf("error = %v, want contention", err)
+		}
+		if n != 3 {
+			t.Errorf("tried %v times, want 3", n)
+		}
+	})
+
+	t.Run("concurrent updates", func(t *testing.T) {
+		store := NewRedisRateLimitStore(newFakeRedis(), WithRedisMaxRetries(100))
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.AtomicUpdate(ctx, "k", increment); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if got, _ := store.Get(ctx, "k"); got == nil || got.Tokens != 20 {
+			t.Errorf("bucket = %+v, want 20 tokens", got)
+		}
+	})
+}
+
+func TestRateLimit_Store(t *testing.T) {
+	req := &protocol.Request{ID: json.RawMessage(`1`), Method: "tools/list"}
+	handler := func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, "ok"), nil
+	}
+
+	t.Run("instances share the store", func(t *testing.T) {
+		store := NewRedisRateLimitStore(newFakeRedis())
+		a := RateLimit(1, 2, WithRateLimitStore(store))(handler)
+		b := RateLimit(1, 2, WithRateLimitStore(store))(handler)
+		for i, h := range []HandlerFunc{a, b} {
+			if _, err := h(context.Background(), req); err != nil {
+				t.Fatalf("request %d: unexpected error: %v", i, err)
+			}
+		}
+		if _, err := a(context.Background(), req); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("error = %v, want rate limited", err)
+		}
+	})
+
+	t.Run("fails closed", func(t *testing.T) {
+		redis := newFakeRedis()
+		redis.err = errors.New("connection refused")
+		h := RateLimit(10, 10, WithRateLimitStore(NewRedisRateLimitStore(redis)))(handler)
+		if _, err := h(context.Background(), req); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("error = %v, want rate limited", err)
+		}
+	})
+
+	t.Run("fails open", func(t *testing.T) {
+		redis := newFakeRedis()
+		redis.err = errors.New("connection refused")
+		h := RateLimit(10, 10, WithRateLimitStore(NewRedisRateLimitStore(redis)), WithRateLimitFailOpen())(handler)
+		if _, err := h(context.Background(), req); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
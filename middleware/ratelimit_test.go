@@ -3,6 +3,7 @@ package middleware_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -195,6 +196,29 @@ func TestRateLimitByClient(t *testing.T) {
 	})
 }
 
+func TestRateLimitByIdentity(t *testing.T) {
+	t.Run("limits each identity separately", func(t *testing.T) {
+		handler := middleware.RateLimitByIdentity(1, 1)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return protocol.NewResponse(req.ID, "ok"), nil
+		})
+		req := &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "test"}
+		alice := middleware.ContextWithIdentity(context.Background(), &middleware.Identity{ID: "alice"})
+		bob := middleware.ContextWithIdentity(context.Background(), &middleware.Identity{ID: "bob"})
+
+		for name, ctx := range map[string]context.Context{"alice": alice, "bob": bob, "anonymous": context.Background()} {
+			if _, err := handler(ctx, req); err != nil {
+				t.Fatalf("%s first request failed: %v", name, err)
+			}
+		}
+		if _, err := handler(alice, req); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("expected alice to be rate limited, got %v", err)
+		}
+		if _, err := handler(context.Background(), req); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+			t.Errorf("expected unauthenticated requests to share a bucket, got %v", err)
+		}
+	})
+}
+
 func TestRateLimit_Concurrent(t *testing.T) {
 	t.Run("handles concurrent requests", func(t *testing.T) {
 		// 10 requests per second, burst of 10
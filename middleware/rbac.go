@@ -0,0 +1,311 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// AccessKind is the kind of item an identity accesses.
+type AccessKind string
+
+// Access kinds.
+const (
+	AccessTool     AccessKind = "tool"
+	AccessResource AccessKind = "resource"
+	AccessPrompt   AccessKind = "prompt"
+	// AccessMethod is a request to a JSON-RPC method, checked by
+	// Authorize.
+	AccessMethod AccessKind = "method"
+)
+
+// Access is a tool, resource or prompt an identity wants to use or see.
+type Access struct {
+	// Kind is what is accessed.
+	Kind AccessKind
+	// Name is the tool or prompt name, the resource URI or the method.
+	// When filtering resources/templates/list it is the URI template.
+	Name string
+}
+
+// Grants lists what a role may use. Entries are names or URIs, and may
+// contain "*" to match any sequence of characters, as in "db_*" or
+// "file:///docs/*". A single "*" grants everything of its kind.
+type Grants struct {
+	Tools     []string
+	Resources []string
+	Prompts   []string
+}
+
+// RBACPolicy maps the roles of an identity to the tools, resources and
+// prompts it may use.
+type RBACPolicy struct {
+	// Roles maps a role name to its grants. An identity may use what any of
+	// its roles grants.
+	Roles map[string]Grants
+
+	// RolesFunc returns the roles of an identity. It defaults to
+	// IdentityRoles.
+	RolesFunc func(identity *Identity) []string
+
+	// Allow, if set, decides every access instead of Roles, for policies
+	// that do not fit a static table. It receives a nil identity for
+	// unauthenticated requests.
+	Allow func(ctx context.Context, identity *Identity, access Access) bool
+}
+
+// Allowed reports whether identity may perform access under the policy.
+// Requests without an identity are allowed nothing, unless Allow decides
+// otherwise.
+func (p *RBACPolicy) Allowed(ctx context.Context, identity *Identity, access Access) bool {
+	if p.Allow != nil {
+		return p.Allow(ctx, identity, access)
+	}
+	if identity == nil {
+		return false
+	}
+
+	rolesFunc := p.RolesFunc
+	if rolesFunc == nil {
+		rolesFunc = IdentityRoles
+	}
+	for _, role := range rolesFunc(identity) {
+		grants, ok := p.Roles[role]
+		if !ok {
+			continue
+		}
+		var patterns []string
+		switch access.Kind {
+		case AccessTool:
+			patterns = grants.Tools
+		case AccessResource:
+			patterns = grants.Resources
+		case AccessPrompt:
+			patterns = grants.Prompts
+		}
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			return matchGrant(pattern, access.Name)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// IdentityRoles returns the roles stored in the "roles" metadata of an
+// identity, as a []string, a []any of strings or a comma-separated string,
+// together with a single "role" entry.
+func IdentityRoles(identity *Identity) []string {
+	if identity == nil {
+		return nil
+	}
+
+	var roles []string
+	switch v := identity.Metadata["roles"].(type) {
+	case []string:
+		roles = append(roles, v...)
+	case []any:
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	case string:
+		for _, r := range strings.Split(v, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				roles = append(roles, r)
+			}
+		}
+	}
+	if role, ok := identity.Metadata["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// RBACOption configures the RBAC middleware.
+type RBACOption func(*rbacConfig)
+
+type rbacConfig struct {
+	logger Logger
+}
+
+// WithRBACLogger sets the logger for denied requests.
+func WithRBACLogger(l Logger) RBACOption {
+	return func(c *rbacConfig) {
+		c.logger = l
+	}
+}
+
+// RBAC returns middleware that enforces policy on the identity set by Auth,
+// so it must come after Auth in the chain. Calls to tools, reads of
+// resources and prompt requests that the policy does not allow are
+// rejected with a forbidden error (CodeForbidden), and list results only
+// include what the identity may use.
+//
+// Example:
+//
+//	policy := &middleware.RBACPolicy{
+//	    Roles: map[string]middleware.Grants{
+//	        "viewer": {Tools: []string{"search"}, Resources: []string{"docs://*"}},
+//	        "admin":  {Tools: []string{"*"}, Resources: []string{"*"}, Prompts: []string{"*"}},
+//	    },
+//	}
+//	stack := []middleware.Middleware{middleware.Auth(auth), middleware.RBAC(policy)}
+func RBAC(policy *RBACPolicy, opts ...RBACOption) Middleware {
+	cfg := &rbacConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			identity := IdentityFromContext(ctx)
+
+			access, ok := requestAccess(req)
+			if ok && !policy.Allowed(ctx, identity, access) {
+				return nil, accessDenied(cfg.logger, req, identity, access)
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+			return FilterList(req.Method, resp, func(access Access) bool {
+				return policy.Allowed(ctx, identity, access)
+			})
+		}
+	}
+}
+
+// requestAccess returns what a tools/call, resources/read or prompts/get
+// request accesses. It reports false for other requests and for params it
+// cannot parse, which the handler rejects.
+func requestAccess(req *protocol.Request) (Access, bool) {
+	var params struct {
+		Name string `json:"name"`
+		URI  string `json:"uri"`
+	}
+	switch req.Method {
+	case protocol.MethodToolsCall, protocol.MethodResourcesRead, protocol.MethodPromptsGet:
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return Access{}, false
+		}
+	default:
+		return Access{}, false
+	}
+
+	switch req.Method {
+	case protocol.MethodToolsCall:
+		return Access{Kind: AccessTool, Name: params.Name}, true
+	case protocol.MethodResourcesRead:
+		return Access{Kind: AccessResource, Name: params.URI}, true
+	default:
+		return Access{Kind: AccessPrompt, Name: params.Name}, true
+	}
+}
+
+// listFields describes where a list result keeps its items and which item
+// field holds the name checked against the policy.
+var listFields = map[string]struct {
+	items string
+	name  string
+	kind  AccessKind
+}{
+	protocol.MethodToolsList:              {"tools", "name", AccessTool},
+	protocol.MethodResourcesList:          {"resources", "uri", AccessResource},
+	protocol.MethodResourcesTemplatesList: {"resourceTemplates", "uriTemplate", AccessResource},
+	protocol.MethodPromptsList:            {"prompts", "name", AccessPrompt},
+}
+
+// accessDenied logs a denied access and returns the forbidden error
+// reporting it.
+func accessDenied(logger Logger, req *protocol.Request, identity *Identity, access Access) error {
+	if logger != nil {
+		fields := []Field{F("method", req.Method), F(string(access.Kind), access.Name)}
+		if identity != nil {
+			fields = append(fields, F("identity", identity.ID))
+		}
+		logger.Warn("access denied", fields...)
+	}
+	return protocol.NewForbidden(string(access.Kind) + " not allowed: " + access.Name)
+}
+
+// FilterList removes the items of a tools/list, resources/list,
+// resources/templates/list or prompts/list result that allowed rejects,
+// for middleware that hides what an identity may not use. It returns
+// other responses unchanged.
+func FilterList(method string, resp *protocol.Response, allowed func(Access) bool) (*protocol.Response, error) {
+	fields, ok := listFields[method]
+	if !ok || resp.Result == nil {
+		return resp, nil
+	}
+
+	filtered := *resp
+	switch result := resp.Result.(type) {
+	case protocol.ToolsListResult:
+		result.Tools = slices.DeleteFunc(slices.Clone(result.Tools), func(t protocol.Tool) bool {
+			return !allowed(Access{Kind: AccessTool, Name: t.Name})
+		})
+		filtered.Result = result
+	case protocol.ResourcesListResult:
+		result.Resources = slices.DeleteFunc(slices.Clone(result.Resources), func(r protocol.Resource) bool {
+			return !allowed(Access{Kind: AccessResource, Name: r.URI})
+		})
+		filtered.Result = result
+	case protocol.ResourceTemplatesListResult:
+		result.ResourceTemplates = slices.DeleteFunc(slices.Clone(result.ResourceTemplates), func(t protocol.ResourceTemplate) bool {
+			return !allowed(Access{Kind: AccessResource, Name: t.URITemplate})
+		})
+		filtered.Result = result
+	case protocol.PromptsListResult:
+		result.Prompts = slices.DeleteFunc(slices.Clone(result.Prompts), func(p protocol.Prompt) bool {
+			return !allowed(Access{Kind: AccessPrompt, Name: p.Name})
+		})
+		filtered.Result = result
+	default:
+		// Results built by other handlers are filtered in their JSON form
+		data, err := json.Marshal(resp.Result)
+		if err != nil {
+			return nil, protocol.NewInternalError(err.Error())
+		}
+		var generic map[string]any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return resp, nil
+		}
+		items, ok := generic[fields.items].([]any)
+		if !ok {
+			return resp, nil
+		}
+		generic[fields.items] = slices.DeleteFunc(items, func(item any) bool {
+			m, _ := item.(map[string]any)
+			name, _ := m[fields.name].(string)
+			return !allowed(Access{Kind: fields.kind, Name: name})
+		})
+		filtered.Result = generic
+	}
+	return &filtered, nil
+}
+
+// matchGrant reports whether name matches pattern, where "*" matches any
+// sequence of characters, including none.
+func matchGrant(pattern, name string) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == name
+	}
+	prefix, rest := pattern[:star], pattern[star+1:]
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	name = name[len(prefix):]
+	for i := 0; i <= len(name); i++ {
+		if matchGrant(rest, name[i:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestMatchGrant(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"search", "search", true},
+		{"search", "search_v2", false},
+		{"*", "anything", true},
+		{"*", "", true},
+		{"db_*", "db_query", true},
+		{"db_*", "query_db", false},
+		{"file:///docs/*", "file:///docs/a/b.md", true},
+		{"file:///docs/*", "file:///src/a.go", false},
+		{"*_admin", "user_admin", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGrant(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGrant(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIdentityRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *Identity
+		want     []string
+	}{
+		{"nil identity", nil, nil},
+		{"no metadata", &Identity{ID: "u"}, nil},
+		{"string slice", &Identity{Metadata: map[string]any{"roles": []string{"a", "b"}}}, []string{"a", "b"}},
+		{"any slice", &Identity{Metadata: map[string]any{"roles": []any{"a", 1, "b"}}}, []string{"a", "b"}},
+		{"comma-separated", &Identity{Metadata: map[string]any{"roles": "a, b,,"}}, []string{"a", "b"}},
+		{"single role", &Identity{Metadata: map[string]any{"roles": []string{"a"}, "role": "c"}}, []string{"a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IdentityRoles(tt.identity); !slices.Equal(got, tt.want) {
+				t.Errorf("IdentityRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRBACPolicy_Allowed(t *testing.T) {
+	policy := &RBACPolicy{
+		Roles: map[string]Grants{
+			"viewer": {Tools: []string{"search"}, Resources: []string{"docs://*"}},
+			"admin":  {Tools: []string{"*"}, Resources: []string{"*"}, Prompts: []string{"*"}},
+		},
+	}
+	viewer := &Identity{ID: "v", Metadata: map[string]any{"roles": []string{"viewer"}}}
+	admin := &Identity{ID: "a", Metadata: map[string]any{"role": "admin"}}
+	unknown := &Identity{ID: "u", Metadata: map[string]any{"role": "guest"}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		access   Access
+		want     bool
+	}{
+		{"viewer tool", viewer, Access{AccessTool, "search"}, true},
+		{"viewer other tool", viewer, Access{AccessTool, "delete"}, false},
+		{"viewer resource", viewer, Access{AccessResource, "docs://readme"}, true},
+		{"viewer other resource", viewer, Access{AccessResource, "secret://key"}, false},
+		{"viewer prompt", viewer, Access{AccessPrompt, "review"}, false},
+		{"admin", admin, Access{AccessPrompt, "review"}, true},
+		{"unknown role", unknown, Access{AccessTool, "search"}, false},
+		{"no identity", nil, Access{AccessTool, "search"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allowed(context.Background(), tt.identity, tt.access); got != tt.want {
+				t.Errorf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("custom roles", func(t *testing.T) {
+		p := &RBACPolicy{
+			Roles:     policy.Roles,
+			RolesFunc: func(identity *Identity) []string { return []string{identity.Name} },
+		}
+		if !p.Allowed(context.Background(), &Identity{Name: "admin"}, Access{AccessTool, "delete"}) {
+			t.Error("Allowed() = false for the role returned by RolesFunc")
+		}
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		p := &RBACPolicy{
+			Roles: policy.Roles,
+			Allow: func(ctx context.Context, identity *Identity, access Access) bool {
+				return identity == nil && access.Name == "public"
+			},
+		}
+		if !p.Allowed(context.Background(), nil, Access{AccessTool, "public"}) {
+			t.Error("Allow callback not used")
+		}
+		if p.Allowed(context.Background(), admin, Access{AccessTool, "search"}) {
+			t.Error("Roles used despite Allow callback")
+		}
+	})
+}
+
+func TestRBAC(t *testing.T) {
+	policy := &RBACPolicy{
+		Roles: map[string]Grants{
+			"viewer": {Tools: []string{"search"}, Resources: []string{"docs://*"}, Prompts: []string{"summary"}},
+		},
+	}
+	viewer := ContextWithIdentity(context.Background(), &Identity{ID: "v", Metadata: map[string]any{"role": "viewer"}})
+
+	called := false
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		called = true
+		switch req.Method {
+		case protocol.MethodToolsList:
+			return protocol.NewResponse(req.ID, protocol.ToolsListResult{
+				Tools: []protocol.Tool{{Name: "search"}, {Name: "delete"}},
+			}), nil
+		case protocol.MethodResourcesList:
+			return protocol.NewResponse(req.ID, protocol.ResourcesListResult{
+				Resources: []protocol.Resource{{URI: "docs://readme"}, {URI: "secret://key"}},
+			}), nil
+		case protocol.MethodResourcesTemplatesList:
+			return protocol.NewResponse(req.ID, protocol.ResourceTemplatesListResult{
+				ResourceTemplates: []protocol.ResourceTemplate{{URITemplate: "docs://{page}"}, {URITemplate: "db://{table}"}},
+			}), nil
+		case protocol.MethodPromptsList:
+			return protocol.NewResponse(req.ID, map[string]any{
+				"prompts": []map[string]any{{"name": "summary"}, {"name": "review"}},
+			}), nil
+		}
+		return protocol.NewResponse(req.ID, map[string]any{}), nil
+	})
+	logger := &mockLogger{}
+	wrapped := RBAC(policy, WithRBACLogger(logger))(handler)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		params     string
+		wantDenied bool
+	}{
+		{"allowed tool", viewer, protocol.MethodToolsCall, `{"name":"search"}`, false},
+		{"denied tool", viewer, protocol.MethodToolsCall, `{"name":"delete"}`, true},
+		{"allowed resource", viewer, protocol.MethodResourcesRead, `{"uri":"docs://readme"}`, false},
+		{"denied resource", viewer, protocol.MethodResourcesRead, `{"uri":"secret://key"}`, true},
+		{"allowed prompt", viewer, protocol.MethodPromptsGet, `{"name":"summary"}`, false},
+		{"denied prompt", viewer, protocol.MethodPromptsGet, `{"name":"review"}`, true},
+		{"unauthenticated", context.Background(), protocol.MethodToolsCall, `{"name":"search"}`, true},
+		{"other methods pass", context.Background(), protocol.MethodPing, ``, false},
+		{"invalid params reach the handler", viewer, protocol.MethodToolsCall, `[`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			_, err := wrapped(tt.ctx, &protocol.Request{ID: json.RawMessage(`1`), Method: tt.method, Params: json.RawMessage(tt.params)})
+			if tt.wantDenied {
+				if !errors.Is(err, &protocol.Error{Code: protocol.CodeForbidden}) {
+					t.Errorf("error = %v, want forbidden", err)
+				}
+				if called {
+					t.Error("handler called for a denied request")
+				}
+				return
+			}
+			if err != nil || !called {
+				t.Errorf("error = %v, called = %v, want the handler to run", err, called)
+			}
+		})
+	}
+
+	if len(logger.entries) != 4 {
+		t.Errorf("logged %d denials, want 4", len(logger.entries))
+	}
+
+	lists := []struct {
+		method string
+		want   []string
+	}{
+		{protocol.MethodToolsList, []string{"search"}},
+		{protocol.MethodResourcesList, []string{"docs://readme"}},
+		{protocol.MethodResourcesTemplatesList, []string{"docs://{page}"}},
+		{protocol.MethodPromptsList, []string{"summary"}},
+	}
+
+	for _, tt := range lists {
+		t.Run("filters "+tt.method, func(t *testing.T) {
+			resp, err := wrapped(viewer, &protocol.Request{ID: json.RawMessage(`1`), Method: tt.method})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := listNames(t, resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// listNames returns the name, URI or URI template of each item of a list
+// result.
+func listNames(t *testing.T, resp *protocol.Response) []string {
+	t.Helper()
+	var result map[string][]map[string]any
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	var names []string
+	for _, items := range result {
+		for _, item := range items {
+			for _, key := range []string{"name", "uri", "uriTemplate"} {
+				if s, ok := item[key].(string); ok && s != "" {
+					names = append(names, s)
+					break
+				}
+			}
+		}
+	}
+	return names
+}
@@ -3,6 +3,11 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
@@ -10,19 +15,62 @@ import (
 // PanicHandler is called when a panic is recovered.
 type PanicHandler func(ctx context.Context, req *protocol.Request, panicVal any) (*protocol.Response, error)
 
+// PanicReporter is called with the value and the stack trace of a
+// recovered panic, to report it to an error tracker such as Sentry or
+// Bugsnag.
+type PanicReporter func(ctx context.Context, req *protocol.Request, panicVal any, stack []byte)
+
+// RecoverOption configures the Recover middleware.
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	logger  Logger
+	onPanic PanicReporter
+}
+
+// WithRecoverLogger logs recovered panics at error level, with the panic
+// value and the stack trace.
+func WithRecoverLogger(l Logger) RecoverOption {
+	return func(c *recoverConfig) {
+		c.logger = l
+	}
+}
+
+// WithOnPanic sets a function called with each recovered panic, before the
+// request is answered.
+//
+// Example:
+//
+//	mw := middleware.Recover(middleware.WithOnPanic(func(ctx context.Context, req *protocol.Request, v any, stack []byte) {
+//	    sentry.CurrentHub().Recover(v)
+//	}))
+func WithOnPanic(fn PanicReporter) RecoverOption {
+	return func(c *recoverConfig) {
+		c.onPanic = fn
+	}
+}
+
 // Recover returns middleware that catches panics and converts them to internal errors.
-// The panic value is included in the error message for debugging.
-func Recover() Middleware {
-	return RecoverWithHandler(defaultPanicHandler)
+// The panic value is included in the error message for debugging. The
+// panic and its stack trace are recorded on the OpenTelemetry span of the
+// request, if any, and the options log and report them.
+func Recover(opts ...RecoverOption) Middleware {
+	return RecoverWithHandler(defaultPanicHandler, opts...)
 }
 
 // RecoverWithHandler returns middleware that catches panics and calls the provided handler.
 // This allows for custom panic handling such as logging or alerting.
-func RecoverWithHandler(handler PanicHandler) Middleware {
+func RecoverWithHandler(handler PanicHandler, opts ...RecoverOption) Middleware {
+	cfg := &recoverConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, req *protocol.Request) (resp *protocol.Response, err error) {
 			defer func() {
 				if r := recover(); r != nil {
+					cfg.record(ctx, req, r, debug.Stack())
 					resp, err = handler(ctx, req, r)
 				}
 			}()
@@ -31,6 +79,33 @@ func RecoverWithHandler(handler PanicHandler) Middleware {
 	}
 }
 
+// record logs and reports a recovered panic and adds it to the span of ctx.
+func (c *recoverConfig) record(ctx context.Context, req *protocol.Request, panicVal any, stack []byte) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", panicVal)),
+		attribute.String("exception.message", fmt.Sprint(panicVal)),
+		attribute.String("exception.stacktrace", string(stack)),
+	))
+	span.SetStatus(codes.Error, "panic")
+
+	if c.logger != nil {
+		fields := []Field{
+			F("method", req.Method),
+			F("panic", fmt.Sprint(panicVal)),
+			F("stack", string(stack)),
+		}
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			fields = append(fields, F("request_id", requestID))
+		}
+		c.logger.Error("panic recovered", fields...)
+	}
+
+	if c.onPanic != nil {
+		c.onPanic(ctx, req, panicVal, stack)
+	}
+}
+
 // defaultPanicHandler converts a panic value to an internal error.
 func defaultPanicHandler(_ context.Context, _ *protocol.Request, panicVal any) (*protocol.Response, error) {
 	var msg string
@@ -3,8 +3,12 @@ package middleware
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
@@ -128,3 +132,64 @@ func TestRecoverWithHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestRecover_Reporting(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	logger := &mockLogger{}
+	var reported any
+	var reportedStack []byte
+	onPanic := func(ctx context.Context, req *protocol.Request, panicVal any, stack []byte) {
+		reported, reportedStack = panicVal, stack
+	}
+	handler := Chain(
+		OTel(WithTracerProvider(tp)),
+		Recover(WithRecoverLogger(logger), WithOnPanic(onPanic)),
+	)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		panic(errors.New("nil map"))
+	})
+
+	_, err := handler(context.Background(), &protocol.Request{Method: "tools/call"})
+	if !errors.Is(err, &protocol.Error{Code: protocol.CodeInternalError}) {
+		t.Errorf("error = %v, want internal error", err)
+	}
+
+	const frame = "TestRecover_Reporting"
+	if reported == nil || reported.(error).Error() != "nil map" {
+		t.Errorf("reported = %v, want the panic value", reported)
+	}
+	if !strings.Contains(string(reportedStack), frame) {
+		t.Errorf("reported stack does not show the panic site:\n%s", reportedStack)
+	}
+
+	if len(logger.entries) != 1 || logger.entries[0].level != "error" {
+		t.Fatalf("log entries = %+v, want one error", logger.entries)
+	}
+	if stack, _ := logger.entries[0].field("stack").(string); !strings.Contains(stack, frame) {
+		t.Errorf("logged stack does not show the panic site:\n%s", stack)
+	}
+	if got := logger.entries[0].field("panic"); got != "nil map" {
+		t.Errorf("logged panic = %v, want nil map", got)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	var stacktrace string
+	for _, event := range spans[0].Events {
+		if event.Name != "exception" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == "exception.stacktrace" {
+				stacktrace = attr.Value.AsString()
+			}
+		}
+	}
+	if !strings.Contains(stacktrace, frame) {
+		t.Errorf("span has no exception event with the stack trace: %+v", spans[0].Events)
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces redacted values in logs.
+const RedactedValue = "[REDACTED]"
+
+// Redactor removes sensitive data, such as API keys and personal data in
+// tool arguments, from payloads before they are logged.
+type Redactor struct {
+	// Keys are the object keys whose values are replaced, wherever they
+	// appear. They are matched case-insensitively and may contain "*" to
+	// match any sequence of characters, as in "*password*".
+	Keys []string
+
+	// Patterns are replaced wherever they match in string values, for
+	// secrets and personal data under keys that cannot be listed.
+	Patterns []*regexp.Regexp
+
+	// MaxBytes truncates redacted payloads to at most MaxBytes bytes. Zero
+	// or less keeps them whole.
+	MaxBytes int
+}
+
+// DefaultRedactor returns a Redactor for common credentials and personal
+// data: passwords, secrets, tokens, API keys, authorization headers and
+// cookies by key, and bearer tokens, email addresses and card numbers in
+// strings. Payloads are truncated to 4 KB.
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Keys: []string{
+			"*password*", "passwd", "*secret*", "token", "*_token", "*-token",
+			"*api_key*", "*apikey*", "*api-key*", "*private_key*",
+			"authorization", "cookie", "set-cookie", "credentials",
+		},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/=-]+`),
+			regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+			regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		},
+		MaxBytes: 4 << 10,
+	}
+}
+
+// Redact returns the JSON document data with sensitive values replaced by
+// RedactedValue, truncated to MaxBytes. Only the patterns apply to data
+// that is not valid JSON.
+func (r *Redactor) Redact(data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return r.truncate(r.redactString(string(data)))
+	}
+	return r.truncate(r.marshal(r.redactValue(v)))
+}
+
+// RedactValue returns v, encoded as JSON, with sensitive values replaced
+// by RedactedValue, truncated to MaxBytes. v is not modified.
+func (r *Redactor) RedactValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return r.truncate(fmt.Sprintf("<%T: %v>", v, err))
+	}
+	return r.Redact(data)
+}
+
+// redactValue returns a copy of the decoded JSON value v with sensitive
+// values replaced.
+func (r *Redactor) redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			if r.sensitiveKey(k) {
+				out[k] = RedactedValue
+				continue
+			}
+			out[k] = r.redactValue(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = r.redactValue(child)
+		}
+		return out
+	case string:
+		return r.redactString(val)
+	default:
+		return val
+	}
+}
+
+func (r *Redactor) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if matchGrant(strings.ToLower(pattern), key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactString(s string) string {
+	for _, re := range r.Patterns {
+		s = re.ReplaceAllString(s, RedactedValue)
+	}
+	return s
+}
+
+func (r *Redactor) marshal(v any) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("<%T: %v>", v, err)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// truncate cuts s to MaxBytes, noting how much was cut.
+func (r *Redactor) truncate(s string) string {
+	if r.MaxBytes <= 0 || len(s) <= r.MaxBytes {
+		return s
+	}
+	return fmt.Sprintf("%s...(%d bytes truncated)", s[:r.MaxBytes], len(s)-r.MaxBytes)
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		data     string
+		want     string
+	}{
+		{
+			name:     "keys",
+			redactor: &Redactor{Keys: []string{"password", "*token"}},
+			data:     `{"user":"bob","Password":"hunter2","auth":{"accessToken":"abc","tokens":3}}`,
+			want:     `{"Password":"[REDACTED]","auth":{"accessToken":"[REDACTED]","tokens":3},"user":"bob"}`,
+		},
+		{
+			name:     "keys in arrays",
+			redactor: &Redactor{Keys: []string{"secret"}},
+			data:     `[{"secret":{"nested":true}},{"public":1}]`,
+			want:     `[{"secret":"[REDACTED]"},{"public":1}]`,
+		},
+		{
+			name:     "patterns",
+			redactor: &Redactor{Patterns: []*regexp.Regexp{regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)}},
+			data:     `{"note":"ssn 123-45-6789 on file","count":12345678901234567890}`,
+			want:     `{"count":12345678901234567890,"note":"ssn [REDACTED] on file"}`,
+		},
+		{
+			name:     "invalid JSON",
+			redactor: &Redactor{Keys: []string{"password"}, Patterns: []*regexp.Regexp{regexp.MustCompile(`hunter\d`)}},
+			data:     `password=hunter2`,
+			want:     `password=[REDACTED]`,
+		},
+		{
+			name:     "truncation",
+			redactor: &Redactor{MaxBytes: 10},
+			data:     `{"text":"hello world"}`,
+			want:     `{"text":"h...(12 bytes truncated)`,
+		},
+		{
+			name:     "default keys",
+			redactor: DefaultRedactor(),
+			data:     `{"db_password":"x","client_secret":"x","refresh_token":"x","X-API-Key":"x","max_tokens":100,"Authorization":"x"}`,
+			want:     `{"Authorization":"[REDACTED]","X-API-Key":"[REDACTED]","client_secret":"[REDACTED]","db_password":"[REDACTED]","max_tokens":100,"refresh_token":"[REDACTED]"}`,
+		},
+		{
+			name:     "default patterns",
+			redactor: DefaultRedactor(),
+			data:     `{"text":"Bearer eyJhbGciOi.x-y and alice@example.com paid with 4111 1111 1111 1111 on 2026-10-16"}`,
+			want:     `{"text":"[REDACTED] and [REDACTED] paid with [REDACTED] on 2026-10-16"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redactor.Redact([]byte(tt.data)); got != tt.want {
+				t.Errorf("Redact() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_RedactValue(t *testing.T) {
+	r := &Redactor{Keys: []string{"token"}}
+	v := map[string]any{"token": "abc", "nested": map[string]any{"token": "def"}}
+
+	if got, want := r.RedactValue(v), `{"nested":{"token":"[REDACTED]"},"token":"[REDACTED]"}`; got != want {
+		t.Errorf("RedactValue() = %s, want %s", got, want)
+	}
+	if v["token"] != "abc" || v["nested"].(map[string]any)["token"] != "def" {
+		t.Errorf("RedactValue modified its argument: %v", v)
+	}
+}
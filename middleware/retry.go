@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/felixgeelhaar/fortify/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// RetryPolicy configures the Retry middleware. The zero value retries
+// idempotent methods up to three times in total, waiting 100ms, then
+// 200ms, with jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first. It
+	// defaults to 3.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. It defaults to
+	// 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts. It defaults to 5s.
+	MaxBackoff time.Duration
+
+	// Multiplier grows the wait after each retry. It defaults to 2.
+	Multiplier float64
+
+	// NoJitter disables the random variance added to each wait, which
+	// keeps clients that failed together from retrying together.
+	NoJitter bool
+
+	// Idempotent reports whether a request may be sent to the handler more
+	// than once. It defaults to DefaultIdempotent with IdempotentTool.
+	Idempotent func(req *protocol.Request) bool
+
+	// IdempotentTool reports whether the tool of a tools/call request is
+	// idempotent, for the default Idempotent. Pass Server.IdempotentTool to
+	// honor the idempotent and read-only hints of the tool annotations.
+	// Without it, tool calls are not retried.
+	IdempotentTool func(name string) bool
+
+	// Retryable reports whether a failed attempt is worth retrying. It
+	// defaults to DefaultRetryable.
+	Retryable func(err error) bool
+}
+
+// RetryOption configures the Retry middleware.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	logger Logger
+}
+
+// WithRetryLogger logs a warning before each retry, with the method, the
+// attempt about to run and the error of the previous one.
+func WithRetryLogger(l Logger) RetryOption {
+	return func(c *retryConfig) {
+		c.logger = l
+	}
+}
+
+// retryAttemptsKey is the span attribute holding the number of attempts.
+const retryAttemptsKey = "mcp.retry.attempts"
+
+// Retry returns middleware that retries requests failing with transient
+// errors, waiting with exponential backoff between attempts. Only requests
+// the policy deems idempotent are retried, since a retried request may run
+// its handler twice; notifications are never retried.
+//
+// The number of attempts is recorded as the mcp.retry.attempts attribute of
+// the current span, so place Retry after OTel in the chain:
+//
+//	mcp.ServeStdio(ctx, srv, mcp.WithMiddleware(
+//	    middleware.OTel(),
+//	    middleware.Retry(middleware.RetryPolicy{
+//	        MaxAttempts:    4,
+//	        IdempotentTool: srv.IdempotentTool,
+//	    }),
+//	))
+//
+// Place Timeout after Retry to bound each attempt, or before it to bound
+// all of them together. Once the context is done, Retry stops waiting and
+// returns the context error.
+func Retry(policy RetryPolicy, opts ...RetryOption) Middleware {
+	var cfg retryConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	idempotent := policy.Idempotent
+	if idempotent == nil {
+		idempotent = func(req *protocol.Request) bool {
+			return DefaultIdempotent(req, policy.IdempotentTool)
+		}
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = 5 * time.Second
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if req.IsNotification() || !idempotent(req) {
+				return next(ctx, req)
+			}
+
+			attempts := 0
+			r := retry.New[*protocol.Response](retry.Config{
+				MaxAttempts:  policy.MaxAttempts,
+				InitialDelay: policy.InitialBackoff,
+				MaxDelay:     maxBackoff,
+				Multiplier:   policy.Multiplier,
+				Jitter:       !policy.NoJitter,
+				IsRetryable:  retryable,
+				OnRetry: func(attempt int, err error) {
+					trace.SpanFromContext(ctx).AddEvent("mcp.retry", trace.WithAttributes(
+						attribute.Int("mcp.retry.attempt", attempt),
+						attribute.String("error", err.Error()),
+					))
+					if cfg.logger != nil {
+						cfg.logger.Warn("retrying request",
+							F("method", req.Method),
+							F("attempt", attempt),
+							F("error", err.Error()),
+						)
+					}
+				},
+			})
+
+			resp, err := r.Do(ctx, func(ctx context.Context) (*protocol.Response, error) {
+				attempts++
+				return next(ctx, req)
+			})
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Int(retryAttemptsKey, attempts))
+			return resp, err
+		}
+	}
+}
+
+// idempotentMethods are the MCP methods that only read server state.
+var idempotentMethods = map[string]bool{
+	protocol.MethodPing:                   true,
+	protocol.MethodToolsList:              true,
+	protocol.MethodResourcesList:          true,
+	protocol.MethodResourcesRead:          true,
+	protocol.MethodResourcesTemplatesList: true,
+	protocol.MethodPromptsList:            true,
+	protocol.MethodPromptsGet:             true,
+}
+
+// DefaultIdempotent reports whether req may be retried: the list methods,
+// ping, resources/read and prompts/get only read, and tools/call is
+// idempotent when idempotentTool reports so for its tool. A nil
+// idempotentTool makes no tool idempotent.
+func DefaultIdempotent(req *protocol.Request, idempotentTool func(name string) bool) bool {
+	if idempotentMethods[req.Method] {
+		return true
+	}
+	if req.Method != protocol.MethodToolsCall || idempotentTool == nil {
+		return false
+	}
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return false
+	}
+	return idempotentTool(params.Name)
+}
+
+// DefaultRetryable reports whether err may be transient: internal and
+// unavailable errors, and errors that are not protocol errors, are retried,
+// while errors in the request, such as invalid params or an unknown tool,
+// and canceled requests are not.
+func DefaultRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var mcpErr *protocol.Error
+	if !errors.As(err, &mcpErr) {
+		return true
+	}
+	return mcpErr.Code == protocol.CodeInternalError || mcpErr.Code == protocol.CodeUnavailable
+}
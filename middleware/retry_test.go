@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// fastRetry is a policy that does not wait between attempts in tests.
+var fastRetry = RetryPolicy{InitialBackoff: time.Microsecond, NoJitter: true}
+
+// failing returns a handler failing with errs in turn, then succeeding,
+// and counts its calls.
+func failing(calls *int, errs ...error) HandlerFunc {
+	return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		*calls++
+		if *calls <= len(errs) {
+			return nil, errs[*calls-1]
+		}
+		return protocol.NewResponse(req.ID, "ok"), nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	internal := protocol.NewInternalError("database unavailable")
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		req       *protocol.Request
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "retries idempotent methods",
+			policy:    fastRetry,
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodResourcesRead},
+			errs:      []error{internal, internal},
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max attempts",
+			policy:    fastRetry,
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList},
+			errs:      []error{internal, internal, internal},
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry request errors",
+			policy:    fastRetry,
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodPromptsGet},
+			errs:      []error{protocol.NewInvalidParams("missing name")},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry tool calls by default",
+			policy:    fastRetry,
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsCall, Params: json.RawMessage(`{"name":"put"}`)},
+			errs:      []error{internal},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "retries idempotent tools",
+			policy: RetryPolicy{
+				InitialBackoff: time.Microsecond,
+				IdempotentTool: func(name string) bool { return name == "put" },
+			},
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsCall, Params: json.RawMessage(`{"name":"put"}`)},
+			errs:      []error{internal},
+			wantCalls: 2,
+		},
+		{
+			name: "does not retry other tools",
+			policy: RetryPolicy{
+				InitialBackoff: time.Microsecond,
+				IdempotentTool: func(name string) bool { return name == "put" },
+			},
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsCall, Params: json.RawMessage(`{"name":"post"}`)},
+			errs:      []error{internal},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry notifications",
+			policy:    fastRetry,
+			req:       &protocol.Request{Method: protocol.MethodPing},
+			errs:      []error{internal},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "uses custom predicates",
+			policy: RetryPolicy{
+				MaxAttempts:    5,
+				InitialBackoff: time.Microsecond,
+				Idempotent:     func(*protocol.Request) bool { return true },
+				Retryable:      func(err error) bool { return errors.Is(err, errFlaky) },
+			},
+			req:       &protocol.Request{ID: json.RawMessage("1"), Method: "x-myorg/sync"},
+			errs:      []error{errFlaky, errFlaky, errFlaky, errFlaky},
+			wantCalls: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := Retry(tt.policy)(failing(&calls, tt.errs...))
+
+			resp, err := handler(context.Background(), tt.req)
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp == nil || resp.Result != "ok" {
+				t.Errorf("unexpected response: %+v", resp)
+			}
+		})
+	}
+}
+
+var errFlaky = errors.New("flaky")
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	handler := Retry(RetryPolicy{InitialBackoff: time.Hour})(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		calls++
+		cancel()
+		return nil, protocol.NewInternalError("down")
+	})
+
+	_, err := handler(ctx, &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestRetry_RecordsAttempts(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	calls := 0
+	logger := &mockLogger{}
+	handler := Chain(
+		OTel(WithTracerProvider(tp)),
+		Retry(fastRetry, WithRetryLogger(logger)),
+	)(failing(&calls, protocol.NewInternalError("down")))
+
+	if _, err := handler(context.Background(), &protocol.Request{ID: json.RawMessage("1"), Method: protocol.MethodToolsList}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	var attempts int64
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == retryAttemptsKey {
+			attempts = attr.Value.AsInt64()
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("%s = %d, want 2", retryAttemptsKey, attempts)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "mcp.retry" {
+		t.Errorf("expected one mcp.retry event, got %+v", spans[0].Events)
+	}
+	if len(logger.entries) != 1 || logger.entries[0].level != "warn" {
+		t.Errorf("expected 1 warning, got %+v", logger.entries)
+	}
+}
+
+func TestDefaultRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"plain error", errors.New("connection reset"), true},
+		{"internal error", protocol.NewInternalError("boom"), true},
+		{"unavailable", protocol.NewUnavailable("overloaded"), true},
+		{"timeout", timeoutError(time.Second), true},
+		{"invalid params", protocol.NewInvalidParams("bad"), false},
+		{"not found", protocol.NewNotFound("tool not found: x"), false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryable(tt.err); got != tt.want {
+				t.Errorf("DefaultRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
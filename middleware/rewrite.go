@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Rewriter changes a request before it is dispatched. It may set the
+// method and params of req, which is a copy owned by Rewrite. An error
+// rejects the request; it should be a *protocol.Error, such as one from
+// protocol.NewInvalidParams.
+type Rewriter func(ctx context.Context, req *protocol.Request) error
+
+// Rewrite returns middleware that passes each request through rewriters,
+// in order, before the next handler, for tool aliasing, argument defaults
+// or tenant injection. The rewriters work on a copy, so middleware before
+// Rewrite, such as Logging, keeps seeing the request the client sent.
+//
+// Example:
+//
+//	mw := middleware.Rewrite(
+//	    middleware.AliasTool("search_docs", "search"),
+//	    middleware.DefaultToolArguments("search", map[string]any{"limit": 10}),
+//	)
+func Rewrite(rewriters ...Rewriter) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			rewritten := *req
+			rewritten.Params = slices.Clone(req.Params)
+			for _, rewrite := range rewriters {
+				if err := rewrite(ctx, &rewritten); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, &rewritten)
+		}
+	}
+}
+
+// RenameMethod returns a Rewriter that dispatches requests for the method
+// from as requests for the method to.
+func RenameMethod(from, to string) Rewriter {
+	return func(_ context.Context, req *protocol.Request) error {
+		if req.Method == from {
+			req.Method = to
+		}
+		return nil
+	}
+}
+
+// AliasTool returns a Rewriter that dispatches calls of the tool alias to
+// the tool name, such as to keep an old tool name working after a rename.
+// The alias is not added to tools/list.
+func AliasTool(alias, name string) Rewriter {
+	return patchToolCall(alias, func(_ context.Context, params map[string]json.RawMessage) error {
+		raw, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		params["name"] = raw
+		return nil
+	})
+}
+
+// DefaultToolArguments returns a Rewriter that adds defaults to the
+// arguments of calls of the tools matching tool, which may contain "*" to
+// match any sequence of characters. Arguments the client sent are kept.
+func DefaultToolArguments(tool string, defaults map[string]any) Rewriter {
+	return patchToolArguments(tool, func(_ context.Context, args map[string]json.RawMessage) error {
+		for key, value := range defaults {
+			if _, ok := args[key]; ok {
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("default argument %q: %w", key, err)
+			}
+			args[key] = raw
+		}
+		return nil
+	})
+}
+
+// PatchToolArguments returns a Rewriter that decodes the arguments of calls
+// of the tools matching tool, which may contain "*", into a T and lets fn
+// change them. Only the fields fn changes are written back, so arguments T
+// does not declare and fields fn leaves alone reach the tool as the client
+// sent them. Arguments that do not decode into a T are rejected as invalid
+// params.
+//
+// Example, injecting the tenant of the caller into every tool call:
+//
+//	type tenantArgs struct {
+//	    TenantID string `json:"tenant_id"`
+//	}
+//	rw := middleware.PatchToolArguments("*", func(ctx context.Context, args *tenantArgs) error {
+//	    identity := middleware.IdentityFromContext(ctx)
+//	    if identity == nil {
+//	        return protocol.NewUnauthorized("authentication required")
+//	    }
+//	    args.TenantID, _ = identity.Metadata["tenant"].(string)
+//	    return nil
+//	})
+func PatchToolArguments[T any](tool string, fn func(ctx context.Context, args *T) error) Rewriter {
+	return patchToolArguments(tool, func(ctx context.Context, args map[string]json.RawMessage) error {
+		var v T
+		if len(args) > 0 {
+			raw, err := json.Marshal(args)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return protocol.NewInvalidParams(fmt.Sprintf("invalid arguments: %v", err))
+			}
+		}
+
+		before, err := encodeFields(&v)
+		if err != nil {
+			return err
+		}
+		if err := fn(ctx, &v); err != nil {
+			return err
+		}
+		after, err := encodeFields(&v)
+		if err != nil {
+			return err
+		}
+
+		for key, raw := range after {
+			if !bytes.Equal(before[key], raw) {
+				args[key] = raw
+			}
+		}
+		for key := range before {
+			if _, ok := after[key]; !ok {
+				delete(args, key)
+			}
+		}
+		return nil
+	})
+}
+
+// encodeFields returns the JSON fields of v, which must encode as an
+// object.
+func encodeFields(v any) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("tool arguments must encode as an object: %w", err)
+	}
+	return fields, nil
+}
+
+// patchToolArguments returns a Rewriter that calls fn with the arguments
+// of calls of the tools matching tool and stores what it leaves in them.
+func patchToolArguments(tool string, fn func(ctx context.Context, args map[string]json.RawMessage) error) Rewriter {
+	return patchToolCall(tool, func(ctx context.Context, params map[string]json.RawMessage) error {
+		var args map[string]json.RawMessage
+		if raw := params["arguments"]; len(raw) > 0 {
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return protocol.NewInvalidParams("tool arguments must be an object")
+			}
+		}
+		if args == nil {
+			args = make(map[string]json.RawMessage)
+		}
+		if err := fn(ctx, args); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		params["arguments"] = raw
+		return nil
+	})
+}
+
+// patchToolCall returns a Rewriter that calls fn with the params of
+// tools/call requests for the tools matching tool and stores what it
+// leaves in them. Params that are not a tools/call object are left for
+// the server to reject.
+func patchToolCall(tool string, fn func(ctx context.Context, params map[string]json.RawMessage) error) Rewriter {
+	return func(ctx context.Context, req *protocol.Request) error {
+		if req.Method != protocol.MethodToolsCall {
+			return nil
+		}
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+		var name string
+		if err := json.Unmarshal(params["name"], &name); err != nil || !matchGrant(tool, name) {
+			return nil
+		}
+		if err := fn(ctx, params); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+		return nil
+	}
+}
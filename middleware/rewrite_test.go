@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+type searchArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+	Scope string `json:"scope,omitempty"`
+}
+
+func TestRewrite(t *testing.T) {
+	capLimit := PatchToolArguments("search", func(_ context.Context, args *searchArgs) error {
+		args.Limit = min(args.Limit, 50)
+		return nil
+	})
+	dropScope := PatchToolArguments("search", func(_ context.Context, args *searchArgs) error {
+		args.Scope = ""
+		return nil
+	})
+	tenant := PatchToolArguments("*", func(ctx context.Context, args *struct {
+		Tenant string `json:"tenant"`
+	}) error {
+		identity := IdentityFromContext(ctx)
+		if identity == nil {
+			return protocol.NewUnauthorized("authentication required")
+		}
+		args.Tenant = identity.ID
+		return nil
+	})
+	user := ContextWithIdentity(context.Background(), &Identity{ID: "acme"})
+
+	tests := []struct {
+		name       string
+		rewriters  []Rewriter
+		ctx        context.Context
+		method     string
+		params     string
+		wantMethod string
+		wantParams string
+		wantErr    bool
+	}{
+		{
+			name:       "rename method",
+			rewriters:  []Rewriter{RenameMethod("x-legacy/ping", protocol.MethodPing)},
+			method:     "x-legacy/ping",
+			wantMethod: protocol.MethodPing,
+		},
+		{
+			name:       "alias tool",
+			rewriters:  []Rewriter{AliasTool("find", "search")},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"find","arguments":{"query":"go"}}`,
+			wantParams: `{"arguments":{"query":"go"},"name":"search"}`,
+		},
+		{
+			name:       "defaults",
+			rewriters:  []Rewriter{DefaultToolArguments("search", map[string]any{"limit": 10, "query": "ignored"})},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"search","arguments":{"query":"go"}}`,
+			wantParams: `{"arguments":{"limit":10,"query":"go"},"name":"search"}`,
+		},
+		{
+			name:       "defaults without arguments",
+			rewriters:  []Rewriter{DefaultToolArguments("*", map[string]any{"limit": 10})},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"other"}`,
+			wantParams: `{"arguments":{"limit":10},"name":"other"}`,
+		},
+		{
+			name:       "typed patch keeps undeclared and unchanged arguments",
+			rewriters:  []Rewriter{capLimit},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"search","arguments":{"query":"go","limit":500,"big":12345678901234567890}}`,
+			wantParams: `{"arguments":{"big":12345678901234567890,"limit":50,"query":"go"},"name":"search"}`,
+		},
+		{
+			name:       "typed patch leaves absent arguments absent",
+			rewriters:  []Rewriter{capLimit},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"search","arguments":{"query":"go"}}`,
+			wantParams: `{"arguments":{"query":"go"},"name":"search"}`,
+		},
+		{
+			name:       "typed patch removes omitted fields",
+			rewriters:  []Rewriter{dropScope},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"search","arguments":{"query":"go","scope":"all"}}`,
+			wantParams: `{"arguments":{"query":"go"},"name":"search"}`,
+		},
+		{
+			name:       "tenant injection overrides the client",
+			rewriters:  []Rewriter{tenant},
+			ctx:        user,
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"export","arguments":{"tenant":"other"}}`,
+			wantParams: `{"arguments":{"tenant":"acme"},"name":"export"}`,
+		},
+		{
+			name:      "rewriter error",
+			rewriters: []Rewriter{tenant},
+			method:    protocol.MethodToolsCall,
+			params:    `{"name":"export","arguments":{}}`,
+			wantErr:   true,
+		},
+		{
+			name:      "arguments of the wrong type",
+			rewriters: []Rewriter{capLimit},
+			method:    protocol.MethodToolsCall,
+			params:    `{"name":"search","arguments":{"limit":"many"}}`,
+			wantErr:   true,
+		},
+		{
+			name:       "other tools",
+			rewriters:  []Rewriter{capLimit, AliasTool("find", "search")},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"fetch","arguments":{"limit":500}}`,
+			wantParams: `{"name":"fetch","arguments":{"limit":500}}`,
+		},
+		{
+			name:       "invalid params are left to the server",
+			rewriters:  []Rewriter{capLimit},
+			method:     protocol.MethodToolsCall,
+			params:     `[1]`,
+			wantParams: `[1]`,
+		},
+		{
+			name:       "rewriters run in order",
+			rewriters:  []Rewriter{AliasTool("find", "search"), capLimit},
+			method:     protocol.MethodToolsCall,
+			params:     `{"name":"find","arguments":{"query":"go","limit":99}}`,
+			wantParams: `{"arguments":{"limit":50,"query":"go"},"name":"search"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *protocol.Request
+			handler := Rewrite(tt.rewriters...)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				got = req
+				return protocol.NewResponse(req.ID, "ok"), nil
+			})
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			req := &protocol.Request{ID: json.RawMessage(`1`), Method: tt.method, Params: json.RawMessage(tt.params)}
+
+			_, err := handler(ctx, req)
+			if tt.wantErr {
+				var perr *protocol.Error
+				if !errors.As(err, &perr) || got != nil {
+					t.Fatalf("error = %v, want a protocol error before dispatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantMethod := tt.wantMethod
+			if wantMethod == "" {
+				wantMethod = tt.method
+			}
+			if got.Method != wantMethod {
+				t.Errorf("method = %q, want %q", got.Method, wantMethod)
+			}
+			if tt.params != "" && string(got.Params) != tt.wantParams {
+				t.Errorf("params = %s, want %s", got.Params, tt.wantParams)
+			}
+			if req.Method != tt.method || string(req.Params) != tt.params {
+				t.Errorf("original request modified: %s %s", req.Method, req.Params)
+			}
+		})
+	}
+}
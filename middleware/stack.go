@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Conventional stage names for a Stack, in the order they usually run.
+const (
+	StageRecover       = "recover"
+	StageObservability = "observability"
+	StageAuth          = "auth"
+	StageLimits        = "limits"
+)
+
+// stackStage is a named group of middleware in a Stack.
+type stackStage struct {
+	name        string
+	middlewares []Middleware
+}
+
+// Stack builds a middleware chain out of named stages, so that code
+// setting up a large server can insert, replace or remove a stage by name
+// instead of by its position in a slice. Stages run in order, the first
+// one outermost, and the middleware of a stage in the order given.
+//
+// Like the server builders, a Stack records the first error, such as a
+// duplicate or unknown stage name, skips the calls after it and reports it
+// from Err and Build.
+//
+// Example:
+//
+//	stack := middleware.NewStack().
+//	    Add(middleware.StageRecover, middleware.Recover()).
+//	    Add(middleware.StageObservability, middleware.OTel(), middleware.Logging(logger)).
+//	    Add(middleware.StageAuth, middleware.Auth(auth)).
+//	    Add(middleware.StageLimits, middleware.RateLimitByIdentity(10, 20))
+//
+//	// Elsewhere, in a deployment that needs it:
+//	stack.InsertAfter(middleware.StageAuth, "authorize", middleware.Authorize(policy))
+//
+//	mws, err := stack.Build()
+type Stack struct {
+	stages []stackStage
+	err    error
+}
+
+// NewStack returns an empty Stack.
+func NewStack() *Stack {
+	return &Stack{}
+}
+
+// Add appends a stage named name with the middleware mws.
+func (s *Stack) Add(name string, mws ...Middleware) *Stack {
+	return s.insert(len(s.stages), name, mws)
+}
+
+// InsertBefore adds a stage named name with the middleware mws right
+// before the stage named before.
+func (s *Stack) InsertBefore(before, name string, mws ...Middleware) *Stack {
+	return s.insert(s.find(before), name, mws)
+}
+
+// InsertAfter adds a stage named name with the middleware mws right after
+// the stage named after.
+func (s *Stack) InsertAfter(after, name string, mws ...Middleware) *Stack {
+	i := s.find(after)
+	if i >= 0 {
+		i++
+	}
+	return s.insert(i, name, mws)
+}
+
+// Replace replaces the middleware of the stage named name with mws,
+// keeping its position.
+func (s *Stack) Replace(name string, mws ...Middleware) *Stack {
+	if i := s.find(name); i >= 0 {
+		s.stages[i].middlewares = slices.Clone(mws)
+	}
+	return s
+}
+
+// Remove removes the stage named name.
+func (s *Stack) Remove(name string) *Stack {
+	if i := s.find(name); i >= 0 {
+		s.stages = slices.Delete(s.stages, i, i+1)
+	}
+	return s
+}
+
+// Stages returns the names of the stages in order.
+func (s *Stack) Stages() []string {
+	names := make([]string, len(s.stages))
+	for i, st := range s.stages {
+		names[i] = st.name
+	}
+	return names
+}
+
+// Build returns the middleware of all stages in order, for Chain or a
+// server's middleware option, or the first error the Stack recorded.
+func (s *Stack) Build() ([]Middleware, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	n := 0
+	for _, st := range s.stages {
+		n += len(st.middlewares)
+	}
+	mws := make([]Middleware, 0, n)
+	for _, st := range s.stages {
+		mws = append(mws, st.middlewares...)
+	}
+	return mws, nil
+}
+
+// Err returns the first error the Stack recorded, or nil.
+func (s *Stack) Err() error {
+	return s.err
+}
+
+// find returns the index of the stage named name. If there is none, it
+// records an error and returns -1.
+func (s *Stack) find(name string) int {
+	if s.err != nil {
+		return -1
+	}
+	i := slices.IndexFunc(s.stages, func(st stackStage) bool { return st.name == name })
+	if i < 0 {
+		s.err = fmt.Errorf("middleware stack: no stage %q", name)
+	}
+	return i
+}
+
+// insert adds a stage at index i, unless i is negative or the name is
+// invalid.
+func (s *Stack) insert(i int, name string, mws []Middleware) *Stack {
+	if s.err != nil || i < 0 {
+		return s
+	}
+	switch {
+	case name == "":
+		s.err = errors.New("middleware stack: empty stage name")
+	case slices.ContainsFunc(s.stages, func(st stackStage) bool { return st.name == name }):
+		s.err = fmt.Errorf("middleware stack: duplicate stage %q", name)
+	default:
+		s.stages = slices.Insert(s.stages, i, stackStage{name: name, middlewares: slices.Clone(mws)})
+	}
+	return s
+}
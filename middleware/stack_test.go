@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// tracing returns middleware that appends name to order when it runs.
+func tracing(order *[]string, name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			*order = append(*order, name)
+			return next(ctx, req)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware { return tracing(&order, name) }
+
+	tests := []struct {
+		name      string
+		stack     func() *Stack
+		want      []string
+		wantErr   string
+		wantNames []string
+	}{
+		{
+			name: "stages in order",
+			stack: func() *Stack {
+				return NewStack().
+					Add(StageRecover, mw("recover")).
+					Add(StageObservability, mw("otel"), mw("logging")).
+					Add(StageAuth, mw("auth"))
+			},
+			want:      []string{"recover", "otel", "logging", "auth"},
+			wantNames: []string{StageRecover, StageObservability, StageAuth},
+		},
+		{
+			name: "insert, replace and remove",
+			stack: func() *Stack {
+				return NewStack().
+					Add(StageRecover, mw("recover")).
+					Add(StageAuth, mw("auth")).
+					Add(StageLimits, mw("ratelimit")).
+					InsertBefore(StageAuth, StageObservability, mw("logging")).
+					InsertAfter(StageAuth, "authorize", mw("authorize")).
+					Replace(StageLimits, mw("quota"), mw("ratelimit")).
+					Remove(StageRecover)
+			},
+			want:      []string{"logging", "auth", "authorize", "quota", "ratelimit"},
+			wantNames: []string{StageObservability, StageAuth, "authorize", StageLimits},
+		},
+		{
+			name: "insert after the last stage",
+			stack: func() *Stack {
+				return NewStack().Add(StageAuth, mw("auth")).InsertAfter(StageAuth, StageLimits, mw("limits"))
+			},
+			want:      []string{"auth", "limits"},
+			wantNames: []string{StageAuth, StageLimits},
+		},
+		{
+			name: "duplicate stage",
+			stack: func() *Stack {
+				return NewStack().Add(StageAuth, mw("auth")).Add(StageAuth, mw("other"))
+			},
+			wantErr: `duplicate stage "auth"`,
+		},
+		{
+			name: "unknown stage",
+			stack: func() *Stack {
+				return NewStack().Add(StageAuth, mw("auth")).InsertAfter("limitz", "quota", mw("quota"))
+			},
+			wantErr: `no stage "limitz"`,
+		},
+		{
+			name: "empty name",
+			stack: func() *Stack {
+				return NewStack().Add("", mw("auth"))
+			},
+			wantErr: "empty stage name",
+		},
+		{
+			name: "first error is kept",
+			stack: func() *Stack {
+				return NewStack().Remove("a").Replace("b").Add(StageAuth)
+			},
+			wantErr: `no stage "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := tt.stack()
+			mws, err := stack.Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || stack.Err() != err {
+					t.Fatalf("Build() error = %v, Err() = %v, want %q", err, stack.Err(), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := stack.Stages(); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("Stages() = %v, want %v", got, tt.wantNames)
+			}
+
+			order = nil
+			handler := Chain(mws...)(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				return protocol.NewResponse(req.ID, "ok"), nil
+			})
+			if _, err := handler(context.Background(), &protocol.Request{Method: "ping"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(order, tt.want) {
+				t.Errorf("order = %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
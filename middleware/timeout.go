@@ -2,20 +2,202 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
+// TimeoutOption configures the Timeout middleware.
+type TimeoutOption func(*timeoutConfig)
+
+type timeoutConfig struct {
+	abandon       bool
+	logger        Logger
+	clientTimeout bool
+}
+
+// WithTimeoutAbandon makes Timeout return as soon as the deadline passes
+// instead of waiting for the handler to give up. The handler keeps running
+// in its own goroutine until it returns; AbandonedHandlers counts these
+// goroutines and Abandoned reports to the handler that its result will be
+// discarded.
+func WithTimeoutAbandon() TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.abandon = true
+	}
+}
+
+// WithTimeoutLogger logs a warning when an abandoned handler finally
+// returns, with the method, how long it ran and its error, if any.
+func WithTimeoutLogger(l Logger) TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.logger = l
+	}
+}
+
+// WithClientTimeout lets clients ask for a shorter deadline with a timeout
+// hint, set in the _meta of the request params under protocol.MetaTimeout
+// (see protocol.Request.TimeoutHint). The duration given to Timeout then
+// is the maximum: longer hints are clamped to it, so interactive clients
+// can fail fast while batch clients keep the full deadline.
+func WithClientTimeout() TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.clientTimeout = true
+	}
+}
+
+// abandonedHandlers counts the handlers abandoned by Timeout that are still
+// running.
+var abandonedHandlers atomic.Int64
+
+// AbandonedHandlers returns the number of handlers abandoned by Timeout
+// that have not returned yet. A value that keeps growing means handlers
+// ignore context cancellation and goroutines are piling up.
+func AbandonedHandlers() int64 {
+	return abandonedHandlers.Load()
+}
+
+// States of a handler run by Timeout with WithTimeoutAbandon.
+const (
+	handlerRunning int32 = iota
+	handlerFinished
+	handlerAbandoned
+)
+
+// handlerStateKey is the context key for the state of a handler run by
+// Timeout.
+type handlerStateKey struct{}
+
+// Abandoned reports whether Timeout has given up waiting for the handler
+// of ctx and already answered the request. Long-running handlers can check
+// it between steps to stop work whose result will be discarded:
+//
+//	for _, item := range items {
+//	    if middleware.Abandoned(ctx) {
+//	        return nil, ctx.Err()
+//	    }
+//	    process(item)
+//	}
+//
+// Unlike ctx.Err, it stays false when the deadline passes while Timeout is
+// still waiting for the result.
+func Abandoned(ctx context.Context) bool {
+	state, ok := ctx.Value(handlerStateKey{}).(*atomic.Int32)
+	return ok && state.Load() == handlerAbandoned
+}
+
 // Timeout returns middleware that enforces a request deadline.
 // If the handler does not complete within the specified duration,
 // the context is canceled and context.DeadlineExceeded is returned.
-func Timeout(d time.Duration) Middleware {
+//
+// When the deadline passes and the handler returns an error, the timeout
+// takes precedence regardless of what the handler returned: the result is
+// an internal error reporting the timeout, joined with the handler error
+// using JoinErrors. A handler that completes successfully keeps its
+// response, and errors after the parent context is done are returned
+// unchanged.
+//
+// By default Timeout waits for the handler to return. With
+// WithTimeoutAbandon it answers at the deadline and leaves the handler
+// running; see AbandonedHandlers and Abandoned. With WithClientTimeout,
+// clients may ask for a shorter deadline than d.
+func Timeout(d time.Duration, opts ...TimeoutOption) Middleware {
+	var cfg timeoutConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-			ctx, cancel := context.WithTimeout(ctx, d)
+			timeout := d
+			if cfg.clientTimeout {
+				if hint, ok := req.TimeoutHint(); ok {
+					timeout = min(hint, d)
+				}
+			}
+
+			parent := ctx
+			ctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			return next(ctx, req)
+
+			if cfg.abandon {
+				return cfg.runAbandonable(parent, ctx, timeout, next, req)
+			}
+
+			resp, err := next(ctx, req)
+			return timeoutResult(parent, ctx, timeout, resp, err)
 		}
 	}
 }
+
+// handlerResult is what a handler run in its own goroutine returned.
+type handlerResult struct {
+	resp *protocol.Response
+	err  error
+}
+
+// runAbandonable runs next in its own goroutine and returns its result, or
+// a timeout error once ctx is done, leaving the handler running.
+func (c *timeoutConfig) runAbandonable(parent, ctx context.Context, d time.Duration, next HandlerFunc, req *protocol.Request) (*protocol.Response, error) {
+	state := new(atomic.Int32)
+	ctx = context.WithValue(ctx, handlerStateKey{}, state)
+	done := make(chan handlerResult, 1)
+	start := time.Now()
+
+	go func() {
+		var result handlerResult
+		defer func() {
+			if r := recover(); r != nil {
+				result.resp, result.err = defaultPanicHandler(ctx, req, r)
+			}
+			if state.CompareAndSwap(handlerRunning, handlerFinished) {
+				done <- result
+				return
+			}
+			if c.logger != nil {
+				fields := []Field{F("method", req.Method), F("elapsed", time.Since(start).String())}
+				if result.err != nil {
+					fields = append(fields, F("error", result.err.Error()))
+				}
+				c.logger.Warn("abandoned handler returned", fields...)
+			}
+			abandonedHandlers.Add(-1)
+		}()
+		result.resp, result.err = next(ctx, req)
+	}()
+
+	select {
+	case result := <-done:
+		return timeoutResult(parent, ctx, d, result.resp, result.err)
+	case <-ctx.Done():
+	}
+
+	abandonedHandlers.Add(1)
+	if !state.CompareAndSwap(handlerRunning, handlerAbandoned) {
+		// The handler returned just as the deadline passed.
+		abandonedHandlers.Add(-1)
+		result := <-done
+		return timeoutResult(parent, ctx, d, result.resp, result.err)
+	}
+	if parent.Err() != nil {
+		return nil, parent.Err()
+	}
+	return nil, timeoutError(d)
+}
+
+// timeoutResult applies the error precedence of Timeout to the result of
+// a handler run with ctx.
+func timeoutResult(parent, ctx context.Context, d time.Duration, resp *protocol.Response, err error) (*protocol.Response, error) {
+	if err == nil || ctx.Err() != context.DeadlineExceeded || parent.Err() != nil {
+		return resp, err
+	}
+	return nil, JoinErrors(timeoutError(d), err)
+}
+
+// timeoutError reports that a request exceeded its deadline. It matches
+// both its *protocol.Error and context.DeadlineExceeded.
+func timeoutError(d time.Duration) error {
+	return fmt.Errorf("%w: %w", protocol.NewInternalError(fmt.Sprintf("request timed out after %s", d)), context.DeadlineExceeded)
+}
@@ -111,3 +111,246 @@ func TestTimeout(t *testing.T) {
 		}
 	})
 }
+
+func TestTimeout_ClientTimeout(t *testing.T) {
+	var remaining time.Duration
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		deadline, _ := ctx.Deadline()
+		remaining = time.Until(deadline)
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+
+	tests := []struct {
+		name   string
+		opts   []TimeoutOption
+		params string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"shorter hint", []TimeoutOption{WithClientTimeout()}, `{"_meta":{"timeoutMs":500}}`, 400 * time.Millisecond, 500 * time.Millisecond},
+		{"longer hint is clamped", []TimeoutOption{WithClientTimeout()}, `{"_meta":{"timeoutMs":60000}}`, 9 * time.Second, 10 * time.Second},
+		{"no hint", []TimeoutOption{WithClientTimeout()}, `{"name":"search"}`, 9 * time.Second, 10 * time.Second},
+		{"invalid hint", []TimeoutOption{WithClientTimeout()}, `{"_meta":{"timeoutMs":"soon"}}`, 9 * time.Second, 10 * time.Second},
+		{"hint ignored by default", nil, `{"_meta":{"timeoutMs":500}}`, 9 * time.Second, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := Timeout(10*time.Second, tt.opts...)(handler)
+			if _, err := wrapped(context.Background(), &protocol.Request{Method: "tools/call", Params: []byte(tt.params)}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if remaining < tt.min || remaining > tt.max {
+				t.Errorf("deadline in %v, want between %v and %v", remaining, tt.min, tt.max)
+			}
+		})
+	}
+
+	t.Run("reports the client timeout", func(t *testing.T) {
+		slow := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		wrapped := Timeout(10*time.Second, WithClientTimeout())(slow)
+		_, err := wrapped(context.Background(), &protocol.Request{Method: "tools/call", Params: []byte(`{"_meta":{"timeoutMs":20}}`)})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("error = %v, want deadline exceeded", err)
+		}
+		var perr *protocol.Error
+		if !errors.As(err, &perr) || perr.Message != "request timed out after 20ms" {
+			t.Errorf("error = %v, want the client timeout reported", err)
+		}
+	})
+}
+
+func TestTimeout_ErrorPrecedence(t *testing.T) {
+	handlerErr := errors.New("query failed")
+
+	tests := []struct {
+		name        string
+		handler     HandlerFunc
+		wantCode    int
+		wantErrs    []error
+		wantResp    bool
+		wantTimeout bool
+	}{
+		{
+			name: "handler returns context error",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			wantCode:    protocol.CodeInternalError,
+			wantErrs:    []error{context.DeadlineExceeded},
+			wantTimeout: true,
+		},
+		{
+			name: "handler returns its own error",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				<-ctx.Done()
+				return nil, handlerErr
+			},
+			wantCode:    protocol.CodeInternalError,
+			wantErrs:    []error{context.DeadlineExceeded, handlerErr},
+			wantTimeout: true,
+		},
+		{
+			name: "handler returns a protocol error",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				<-ctx.Done()
+				return nil, protocol.NewNotFound("item not found")
+			},
+			wantCode:    protocol.CodeInternalError,
+			wantErrs:    []error{context.DeadlineExceeded, &protocol.Error{Code: protocol.CodeNotFound}},
+			wantTimeout: true,
+		},
+		{
+			name: "handler completes after deadline",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				<-ctx.Done()
+				return protocol.NewResponse(req.ID, "done"), nil
+			},
+			wantResp: true,
+		},
+		{
+			name: "handler fails before deadline",
+			handler: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				return nil, protocol.NewNotFound("item not found")
+			},
+			wantCode: protocol.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Timeout(20*time.Millisecond)(tt.handler)(context.Background(), &protocol.Request{Method: "test"})
+			if tt.wantResp {
+				if err != nil || resp == nil {
+					t.Fatalf("got (%v, %v), want response", resp, err)
+				}
+				return
+			}
+
+			var mcpErr *protocol.Error
+			if !errors.As(err, &mcpErr) || mcpErr.Code != tt.wantCode {
+				t.Fatalf("error = %v, want code %d", err, tt.wantCode)
+			}
+			if tt.wantTimeout && mcpErr.Message != "request timed out after 20ms" {
+				t.Errorf("message = %q", mcpErr.Message)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(%v, %v) = false", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeout_Abandon(t *testing.T) {
+	t.Run("answers at the deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		returned := make(chan struct{})
+		var abandoned bool
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			defer close(returned)
+			<-release
+			abandoned = Abandoned(ctx)
+			return protocol.NewResponse(req.ID, "late"), nil
+		})
+		logger := &mockLogger{}
+
+		before := AbandonedHandlers()
+		start := time.Now()
+		_, err := Timeout(20*time.Millisecond, WithTimeoutAbandon(), WithTimeoutLogger(logger))(handler)(context.Background(), &protocol.Request{Method: "tools/call"})
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Timeout waited %v for the handler", elapsed)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, &protocol.Error{Code: protocol.CodeInternalError}) {
+			t.Fatalf("error = %v, want timeout", err)
+		}
+		if got := AbandonedHandlers() - before; got != 1 {
+			t.Errorf("AbandonedHandlers() grew by %d, want 1", got)
+		}
+
+		close(release)
+		<-returned
+		waitFor(t, func() bool { return AbandonedHandlers() == before })
+		if !abandoned {
+			t.Error("Abandoned() = false in the abandoned handler")
+		}
+		if len(logger.entries) != 1 || logger.entries[0].level != "warn" {
+			t.Fatalf("logged %+v, want one warning", logger.entries)
+		}
+	})
+
+	t.Run("returns results before the deadline", func(t *testing.T) {
+		var abandoned bool
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			abandoned = Abandoned(ctx)
+			return protocol.NewResponse(req.ID, "fast"), nil
+		})
+
+		resp, err := Timeout(time.Second, WithTimeoutAbandon())(handler)(context.Background(), &protocol.Request{Method: "test"})
+		if err != nil || resp == nil {
+			t.Fatalf("got (%v, %v), want response", resp, err)
+		}
+		if abandoned {
+			t.Error("Abandoned() = true before the deadline")
+		}
+	})
+
+	t.Run("keeps handler errors before the deadline", func(t *testing.T) {
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return nil, protocol.NewNotFound("item not found")
+		})
+
+		_, err := Timeout(time.Second, WithTimeoutAbandon())(handler)(context.Background(), &protocol.Request{Method: "test"})
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeNotFound}) {
+			t.Errorf("error = %v, want not found", err)
+		}
+	})
+
+	t.Run("recovers handler panics", func(t *testing.T) {
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			panic("boom")
+		})
+
+		_, err := Timeout(time.Second, WithTimeoutAbandon())(handler)(context.Background(), &protocol.Request{Method: "test"})
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeInternalError}) {
+			t.Errorf("error = %v, want internal error", err)
+		}
+	})
+
+	t.Run("returns parent cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			<-release
+			return nil, nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := Timeout(time.Second, WithTimeoutAbandon())(handler)(ctx, &protocol.Request{Method: "test"})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("is false without Timeout", func(t *testing.T) {
+		if Abandoned(context.Background()) {
+			t.Error("Abandoned() = true")
+		}
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
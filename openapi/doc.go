@@ -0,0 +1,39 @@
+// Package openapi turns the operations of an OpenAPI 3 document into MCP
+// tools that call the REST API, so an existing HTTP API can be served to
+// models with a few lines of code.
+//
+// Each operation becomes one tool. Its input schema has a property per
+// path, query and header parameter, plus a "body" property holding the
+// JSON request body, and the handler sends the request and returns the
+// response body as text. Error responses become tool errors the model can
+// read and recover from.
+//
+// # Registering Tools
+//
+//	data, err := os.ReadFile("petstore.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	doc, err := openapi.Parse(data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = openapi.Register(srv, doc,
+//	    openapi.WithBaseURL("https://petstore.example.com/v1"),
+//	    openapi.WithBearerToken(os.Getenv("PETSTORE_TOKEN")),
+//	)
+//
+// Tools are named after operation IDs, or the method and path of
+// operations without one. GET and HEAD operations are marked read-only,
+// PUT idempotent and DELETE destructive; deprecated operations are
+// registered as deprecated tools.
+//
+// # Authentication
+//
+// WithBearerToken and WithHeader add static credentials. WithAuth runs for
+// every request, for credentials that must be fetched or refreshed, such
+// as OAuth2 tokens, or that depend on the calling session.
+//
+// Parse reads JSON documents. Convert YAML documents to JSON first, or
+// decode them into a Document with a YAML library.
+package openapi
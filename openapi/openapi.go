@@ -0,0 +1,548 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// defaultMaxResponseSize limits the response bodies read from the API.
+const defaultMaxResponseSize = 10 << 20
+
+// Document is the part of an OpenAPI 3 document used to generate tools.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components,omitempty"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL of the API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations on a path.
+type PathItem struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Trace      *Operation  `json:"trace,omitempty"`
+}
+
+// Operation is an API operation.
+type Operation struct {
+	OperationID string       `json:"operationId,omitempty"`
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Parameters  []Parameter  `json:"parameters,omitempty"`
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	In          string         `json:"in,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      map[string]any `json:"schema,omitempty"`
+}
+
+// RequestBody is the request body of an operation.
+type RequestBody struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes a request body encoding.
+type MediaType struct {
+	Schema map[string]any `json:"schema,omitempty"`
+}
+
+// Components holds the reusable parts of a document that $ref points to.
+type Components struct {
+	Schemas       map[string]map[string]any `json:"schemas,omitempty"`
+	Parameters    map[string]Parameter      `json:"parameters,omitempty"`
+	RequestBodies map[string]RequestBody    `json:"requestBodies,omitempty"`
+}
+
+// Parse parses an OpenAPI 3 document in JSON.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version %q, want 3.x", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// Endpoint is an operation with its method and path.
+type Endpoint struct {
+	Method    string
+	Path      string
+	Operation *Operation
+}
+
+// Endpoints returns the operations of the document, sorted by path and
+// then by method.
+func (d *Document) Endpoints() []Endpoint {
+	paths := make([]string, 0, len(d.Paths))
+	for p := range d.Paths {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	var endpoints []Endpoint
+	for _, p := range paths {
+		item := d.Paths[p]
+		for _, op := range []struct {
+			method string
+			op     *Operation
+		}{
+			{http.MethodGet, item.Get}, {http.MethodPut, item.Put}, {http.MethodPost, item.Post},
+			{http.MethodDelete, item.Delete}, {http.MethodOptions, item.Options}, {http.MethodHead, item.Head},
+			{http.MethodPatch, item.Patch}, {http.MethodTrace, item.Trace},
+		} {
+			if op.op != nil {
+				endpoints = append(endpoints, Endpoint{Method: op.method, Path: p, Operation: op.op})
+			}
+		}
+	}
+	return endpoints
+}
+
+// Option configures Register.
+type Option func(*config)
+
+type config struct {
+	baseURL  string
+	client   *http.Client
+	auth     []func(ctx context.Context, req *http.Request) error
+	filter   func(Endpoint) bool
+	prefix   string
+	maxBytes int64
+}
+
+// WithBaseURL sets the URL the API is called at. Defaults to the first
+// server of the document.
+func WithBaseURL(u string) Option {
+	return func(c *config) {
+		c.baseURL = u
+	}
+}
+
+// WithHTTPClient sets the client used to call the API. Defaults to a
+// client with a 30 second timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithAuth runs fn on every API request before it is sent, to add
+// credentials. An error fails the tool call.
+func WithAuth(fn func(ctx context.Context, req *http.Request) error) Option {
+	return func(c *config) {
+		c.auth = append(c.auth, fn)
+	}
+}
+
+// WithBearerToken sends token in the Authorization header.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHeader sends a header, such as an API key, with every request.
+func WithHeader(name, value string) Option {
+	return WithAuth(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// WithFilter registers only the endpoints for which fn returns true.
+func WithFilter(fn func(Endpoint) bool) Option {
+	return func(c *config) {
+		c.filter = fn
+	}
+}
+
+// WithPrefix prefixes the name of every tool, such as "petstore.".
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithMaxResponseSize limits the size of response bodies. Larger
+// responses fail the tool call. Defaults to 10 MB.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *config) {
+		c.maxBytes = n
+	}
+}
+
+// Register registers a tool for every operation of doc on srv. It returns
+// an error if no base URL is known or a tool cannot be registered.
+func Register(srv *server.Server, doc *Document, opts ...Option) error {
+	cfg := &config{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		maxBytes: defaultMaxResponseSize,
+	}
+	if len(doc.Servers) > 0 {
+		cfg.baseURL = doc.Servers[0].URL
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.baseURL == "" {
+		return errors.New("openapi: no base URL; set one with WithBaseURL")
+	}
+	cfg.baseURL = strings.TrimSuffix(cfg.baseURL, "/")
+
+	for _, ep := range doc.Endpoints() {
+		if cfg.filter != nil && !cfg.filter(ep) {
+			continue
+		}
+		t, err := newTool(doc, ep)
+		if err != nil {
+			return err
+		}
+
+		b := srv.Tool(cfg.prefix + t.name).
+			Description(t.description).
+			InputSchema(t.inputSchema)
+		switch ep.Method {
+		case http.MethodGet, http.MethodHead:
+			b.ReadOnly()
+		case http.MethodPut:
+			b.Idempotent()
+		case http.MethodDelete:
+			b.Destructive()
+		}
+		if ep.Operation.Deprecated {
+			b.Deprecated("deprecated by the API")
+		}
+		b.Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+			return t.call(ctx, cfg, args)
+		})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("openapi: register %s %s: %w", ep.Method, ep.Path, err)
+		}
+	}
+	return nil
+}
+
+// tool is the tool generated for an endpoint.
+type tool struct {
+	name        string
+	description string
+	inputSchema map[string]any
+	method      string
+	path        string
+	params      []Parameter
+	bodyType    string
+}
+
+// newTool generates the tool of an endpoint.
+func newTool(doc *Document, ep Endpoint) (*tool, error) {
+	op := ep.Operation
+	t := &tool{
+		name:        toolName(ep),
+		description: strings.TrimSpace(op.Summary + "\n\n" + op.Description),
+		method:      ep.Method,
+		path:        ep.Path,
+	}
+	if t.description == "" {
+		t.description = ep.Method + " " + ep.Path
+	}
+
+	properties := map[string]any{}
+	var required []string
+
+	// Operation parameters override path parameters of the same name
+	params := map[string]Parameter{}
+	var order []string
+	for _, p := range append(slices.Clone(doc.Paths[ep.Path].Parameters), op.Parameters...) {
+		p, err := doc.resolveParameter(p)
+		if err != nil {
+			return nil, err
+		}
+		if p.In == "cookie" {
+			continue
+		}
+		key := p.In + ":" + p.Name
+		if _, seen := params[key]; !seen {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+	for _, key := range order {
+		p := params[key]
+		t.params = append(t.params, p)
+		prop := doc.resolveSchema(p.Schema, nil)
+		if prop == nil {
+			prop = map[string]any{"type": "string"}
+		}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		properties[p.Name] = prop
+		if p.Required || p.In == "path" {
+			required = append(required, p.Name)
+		}
+	}
+
+	if op.RequestBody != nil {
+		body, err := doc.resolveRequestBody(*op.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		bodyType, media, ok := jsonMediaType(body.Content)
+		if ok {
+			t.bodyType = bodyType
+			prop := doc.resolveSchema(media.Schema, nil)
+			if prop == nil {
+				prop = map[string]any{}
+			}
+			if body.Description != "" {
+				prop["description"] = body.Description
+			}
+			properties["body"] = prop
+			if body.Required {
+				required = append(required, "body")
+			}
+		}
+	}
+
+	t.inputSchema = map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		t.inputSchema["required"] = required
+	}
+	return t, nil
+}
+
+// call sends the request for a tool call and returns the response body.
+func (t *tool) call(ctx context.Context, cfg *config, args json.RawMessage) (*server.ToolResult, error) {
+	var in map[string]json.RawMessage
+	if len(args) > 0 && string(args) != "null" {
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, protocol.NewInvalidParams("arguments must be an object: " + err.Error())
+		}
+	}
+
+	path := t.path
+	query := url.Values{}
+	header := http.Header{}
+	for _, p := range t.params {
+		raw, ok := in[p.Name]
+		if !ok {
+			if p.In == "path" {
+				return nil, protocol.NewInvalidParams("missing path parameter: " + p.Name)
+			}
+			continue
+		}
+		values := paramValues(raw)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(strings.Join(values, ",")))
+		case "query":
+			for _, v := range values {
+				query.Add(p.Name, v)
+			}
+		case "header":
+			header.Set(p.Name, strings.Join(values, ","))
+		}
+	}
+
+	u := cfg.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var body io.Reader
+	if raw, ok := in["body"]; ok && t.bodyType != "" {
+		body = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", t.bodyType)
+	}
+	for _, auth := range cfg.auth {
+		if err := auth(ctx, req); err != nil {
+			return nil, fmt.Errorf("authenticate request: %w", err)
+		}
+	}
+
+	resp, err := cfg.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, cfg.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > cfg.maxBytes {
+		return nil, fmt.Errorf("response exceeds %d bytes", cfg.maxBytes)
+	}
+
+	if resp.StatusCode >= 400 {
+		return server.NewToolResult().Text(fmt.Sprintf("HTTP %s: %s", resp.Status, data)).WithError(), nil
+	}
+	return server.NewToolResult().Text(string(data)), nil
+}
+
+// paramValues returns the values of a parameter argument: strings as is,
+// each element of arrays, and other values as JSON.
+func paramValues(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []string{s}
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		var values []string
+		for _, item := range list {
+			values = append(values, paramValues(item)...)
+		}
+		return values
+	}
+	return []string{string(raw)}
+}
+
+// jsonMediaType returns the JSON encoding of a request body, if any.
+func jsonMediaType(content map[string]MediaType) (string, MediaType, bool) {
+	if media, ok := content["application/json"]; ok {
+		return "application/json", media, true
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	for _, t := range types {
+		if strings.HasSuffix(t, "+json") {
+			return t, content[t], true
+		}
+	}
+	return "", MediaType{}, false
+}
+
+// toolName returns the tool name of an endpoint: its operation ID, or its
+// method and path, with characters tool names cannot hold replaced.
+func toolName(ep Endpoint) string {
+	name := ep.Operation.OperationID
+	if name == "" {
+		name = strings.ToLower(ep.Method) + "_" + strings.Trim(ep.Path, "/")
+	}
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			sb.WriteRune(r)
+		case r == '{' || r == '}':
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// resolveParameter follows a parameter $ref into the components.
+func (d *Document) resolveParameter(p Parameter) (Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	resolved, found := d.Components.Parameters[name]
+	if !ok || !found {
+		return Parameter{}, fmt.Errorf("openapi: unresolved parameter %s", p.Ref)
+	}
+	return resolved, nil
+}
+
+// resolveRequestBody follows a request body $ref into the components.
+func (d *Document) resolveRequestBody(b RequestBody) (RequestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, ok := strings.CutPrefix(b.Ref, "#/components/requestBodies/")
+	resolved, found := d.Components.RequestBodies[name]
+	if !ok || !found {
+		return RequestBody{}, fmt.Errorf("openapi: unresolved request body %s", b.Ref)
+	}
+	return resolved, nil
+}
+
+// resolveSchema returns a copy of schema with $refs to component schemas
+// inlined, so tool input schemas are self-contained. Recursive and
+// unresolvable references become schemas accepting any value. visiting
+// holds the references being inlined.
+func (d *Document) resolveSchema(schema map[string]any, visiting []string) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		target, found := d.Components.Schemas[name]
+		if !ok || !found || slices.Contains(visiting, name) {
+			return map[string]any{}
+		}
+		return d.resolveSchema(target, append(visiting, name))
+	}
+
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		out[k] = d.resolveValue(v, visiting)
+	}
+	return out
+}
+
+func (d *Document) resolveValue(v any, visiting []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return d.resolveSchema(v, visiting)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = d.resolveValue(item, visiting)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,412 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/testutil"
+)
+
+const petstore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Petstore", "version": "1.0.0"},
+  "servers": [{"url": "http://petstore.invalid/v1"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "summary": "List pets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+          {"name": "tag", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}}
+        ]
+      },
+      "post": {
+        "operationId": "createPet",
+        "summary": "Create a pet",
+        "requestBody": {"$ref": "#/components/requestBodies/NewPet"}
+      }
+    },
+    "/pets/{petId}": {
+      "parameters": [{"$ref": "#/components/parameters/PetID"}],
+      "get": {"operationId": "getPet", "description": "Fetch a pet by ID"},
+      "put": {
+        "operationId": "replacePet",
+        "requestBody": {"required": true, "content": {"application/merge-patch+json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+      },
+      "delete": {"deprecated": true, "parameters": [{"name": "X-Reason", "in": "header", "schema": {"type": "string"}}]}
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "parent": {"$ref": "#/components/schemas/Pet"}
+        }
+      }
+    },
+    "parameters": {
+      "PetID": {"name": "petId", "in": "path", "description": "Pet ID", "schema": {"type": "string"}}
+    },
+    "requestBodies": {
+      "NewPet": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "petstore", data: petstore},
+		{name: "swagger 2", data: `{"swagger": "2.0", "paths": {}}`, wantErr: true},
+		{name: "invalid JSON", data: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocument_Endpoints(t *testing.T) {
+	doc := mustParse(t, petstore)
+	var got []string
+	for _, ep := range doc.Endpoints() {
+		got = append(got, ep.Method+" "+ep.Path)
+	}
+	want := []string{"GET /pets", "POST /pets", "GET /pets/{petId}", "PUT /pets/{petId}", "DELETE /pets/{petId}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Endpoints() = %v, want %v", got, want)
+	}
+}
+
+func TestRegister_Tools(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	if err := Register(srv, mustParse(t, petstore)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tools := map[string]server.ToolInfo{}
+	for _, info := range srv.Tools() {
+		tools[info.Name] = info
+	}
+	for _, name := range []string{"listPets", "createPet", "getPet", "replacePet", "delete_pets_petId"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("tool %q not registered, have %v", name, srv.Tools())
+		}
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		schema      string
+		readOnly    bool
+		idempotent  bool
+		destructive bool
+		deprecated  bool
+	}{
+		{
+			name:        "listPets",
+			description: "List pets",
+			schema:      `{"type":"object","properties":{"limit":{"type":"integer"},"tag":{"type":"array","items":{"type":"string"}}}}`,
+			readOnly:    true,
+		},
+		{
+			name:        "createPet",
+			description: "Create a pet",
+			schema:      `{"type":"object","properties":{"body":{"type":"object","required":["name"],"properties":{"name":{"type":"string"},"parent":{}}}},"required":["body"]}`,
+		},
+		{
+			name:        "getPet",
+			description: "Fetch a pet by ID",
+			schema:      `{"type":"object","properties":{"petId":{"type":"string","description":"Pet ID"}},"required":["petId"]}`,
+			readOnly:    true,
+		},
+		{
+			name:        "replacePet",
+			description: "PUT /pets/{petId}",
+			schema:      `{"type":"object","properties":{"petId":{"type":"string","description":"Pet ID"},"body":{"type":"object","required":["name"],"properties":{"name":{"type":"string"},"parent":{}}}},"required":["petId","body"]}`,
+			idempotent:  true,
+		},
+		{
+			name:        "delete_pets_petId",
+			description: "DELETE /pets/{petId}",
+			schema:      `{"type":"object","properties":{"petId":{"type":"string","description":"Pet ID"},"X-Reason":{"type":"string"}},"required":["petId"]}`,
+			destructive: true,
+			deprecated:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tools[tt.name]
+			if info.Description != tt.description {
+				t.Errorf("Description = %q, want %q", info.Description, tt.description)
+			}
+			assertJSON(t, info.InputSchema, tt.schema)
+
+			a := info.Annotations
+			hint := func(p *bool) bool { return p != nil && *p }
+			if a == nil {
+				a = &server.ToolAnnotations{}
+			}
+			if hint(a.ReadOnlyHint) != tt.readOnly || hint(a.IdempotentHint) != tt.idempotent || hint(a.DestructiveHint) != tt.destructive {
+				t.Errorf("Annotations = %+v", a)
+			}
+			if (info.Deprecated != "") != tt.deprecated {
+				t.Errorf("Deprecated = %q, want deprecated %v", info.Deprecated, tt.deprecated)
+			}
+		})
+	}
+}
+
+func TestRegister_Calls(t *testing.T) {
+	type request struct {
+		method, path, query, body, contentType, auth, reason string
+	}
+	var got request
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = request{
+			method:      r.Method,
+			path:        r.URL.EscapedPath(),
+			query:       r.URL.RawQuery,
+			body:        string(body),
+			contentType: r.Header.Get("Content-Type"),
+			auth:        r.Header.Get("Authorization"),
+			reason:      r.Header.Get("X-Reason"),
+		}
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	err := Register(srv, mustParse(t, petstore),
+		WithBaseURL(api.URL+"/v1/"),
+		WithBearerToken("secret"),
+	)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	tc := testutil.NewTestClient(t, srv)
+	if _, err := tc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		want    request
+		wantErr string
+	}{
+		{
+			name: "query parameters",
+			tool: "listPets",
+			args: map[string]any{"limit": 10, "tag": []string{"cat", "dog"}},
+			want: request{method: "GET", path: "/v1/pets", query: "limit=10&tag=cat&tag=dog"},
+		},
+		{
+			name: "JSON body",
+			tool: "createPet",
+			args: map[string]any{"body": map[string]any{"name": "Rex"}},
+			want: request{method: "POST", path: "/v1/pets", body: `{"name":"Rex"}`, contentType: "application/json"},
+		},
+		{
+			name: "escaped path parameter",
+			tool: "getPet",
+			args: map[string]any{"petId": "a/b"},
+			want: request{method: "GET", path: "/v1/pets/a%2Fb"},
+		},
+		{
+			name: "vendor JSON body",
+			tool: "replacePet",
+			args: map[string]any{"petId": "7", "body": map[string]any{"name": "Rex"}},
+			want: request{method: "PUT", path: "/v1/pets/7", body: `{"name":"Rex"}`, contentType: "application/merge-patch+json"},
+		},
+		{
+			name: "header parameter",
+			tool: "delete_pets_petId",
+			args: map[string]any{"petId": "7", "X-Reason": "sold"},
+			want: request{method: "DELETE", path: "/v1/pets/7", reason: "sold"},
+		},
+		{
+			name:    "error status",
+			tool:    "getPet",
+			args:    map[string]any{"petId": "missing"},
+			want:    request{method: "GET", path: "/v1/pets/missing"},
+			wantErr: "HTTP 404 Not Found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = request{}
+			text, err := tc.CallTool(tt.tool, tt.args)
+			if tt.wantErr != "" {
+				var toolErr *server.ToolError
+				if !errors.As(err, &toolErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CallTool() error = %v, want tool error containing %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("CallTool() error = %v", err)
+				}
+				if text != `{"ok":true}` {
+					t.Errorf("CallTool() = %q", text)
+				}
+			}
+			tt.want.auth = "Bearer secret"
+			if got != tt.want {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing path parameter", func(t *testing.T) {
+		if _, err := tc.CallTool("getPet", map[string]any{}); err == nil {
+			t.Error("CallTool() error = nil, want error")
+		}
+	})
+}
+
+func TestRegister_Options(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-API-Key") + strings.Repeat(".", 100)))
+	}))
+	defer api.Close()
+
+	t.Run("prefix and filter", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+		err := Register(srv, mustParse(t, petstore),
+			WithPrefix("pets."),
+			WithFilter(func(ep Endpoint) bool { return ep.Method == http.MethodGet }),
+		)
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		var names []string
+		for _, info := range srv.Tools() {
+			names = append(names, info.Name)
+		}
+		want := []string{"pets.getPet", "pets.listPets"}
+		if len(names) != len(want) {
+			t.Fatalf("tools = %v, want %v", names, want)
+		}
+		for _, name := range want {
+			if _, ok := srv.GetTool(name); !ok {
+				t.Errorf("tool %q not registered", name)
+			}
+		}
+	})
+
+	t.Run("no base URL", func(t *testing.T) {
+		doc := mustParse(t, petstore)
+		doc.Servers = nil
+		srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+		if err := Register(srv, doc); err == nil {
+			t.Error("Register() error = nil, want error")
+		}
+	})
+
+	t.Run("unresolved reference", func(t *testing.T) {
+		doc := mustParse(t, petstore)
+		doc.Components.Parameters = nil
+		srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+		if err := Register(srv, doc); err == nil {
+			t.Error("Register() error = nil, want error")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{name: "header", opts: []Option{WithHeader("X-API-Key", "k1")}, want: "k1"},
+		{
+			name: "auth func",
+			opts: []Option{WithAuth(func(ctx context.Context, req *http.Request) error {
+				req.Header.Set("X-API-Key", "k2")
+				return nil
+			})},
+			want: "k2",
+		},
+		{
+			name: "auth error",
+			opts: []Option{WithAuth(func(ctx context.Context, req *http.Request) error {
+				return errors.New("token expired")
+			})},
+			wantErr: true,
+		},
+		{name: "response too large", opts: []Option{WithMaxResponseSize(10)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+			opts := append([]Option{WithBaseURL(api.URL), WithHTTPClient(api.Client())}, tt.opts...)
+			if err := Register(srv, mustParse(t, petstore), opts...); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			tool, _ := srv.GetTool("listPets")
+			result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			text := result.(*server.ToolResult).Content()[0].Text
+			if !strings.HasPrefix(text, tt.want+".") {
+				t.Errorf("Execute() = %q, want prefix %q", text, tt.want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, data string) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return doc
+}
+
+func assertJSON(t *testing.T, v any, want string) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotV, wantV any
+	if err := json.Unmarshal(data, &gotV); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantV); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotV, wantV) {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
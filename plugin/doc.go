@@ -0,0 +1,25 @@
+// Package plugin aggregates tools implemented outside the server process.
+//
+// A Provider serves a set of tools, typically from another binary. A Host
+// registers the tools of each provider with a server under a namespace,
+// forwards calls to the provider, and checks the health of providers in
+// the background:
+//
+//	host := plugin.NewHost(srv, plugin.WithHealthInterval(15*time.Second))
+//	defer host.Close()
+//
+//	// Tools of ./bin/git-tools are listed as git.status, git.diff, ...
+//	if err := host.AddProcess(ctx, "git", "./bin/git-tools", "--stdio"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	go host.Run(ctx)
+//
+// AddProcess runs the binary as an MCP server over stdio, so any MCP server
+// can be a plugin; mcp-go servers only need to call mcp.ServeStdio. When a
+// provider fails its health check, its tools are removed from tools/list
+// and calls to them fail with an unavailable error. Processes are restarted
+// on the next check and their tools listed again once they respond.
+//
+// Other backends, such as hashicorp/go-plugin over gRPC, implement Provider
+// and are registered with Add.
+package plugin
@@ -0,0 +1,376 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	mcperrors "github.com/felixgeelhaar/mcp-go/errors"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Provider serves tools implemented outside the server process.
+//
+// CallTool returns the result of a tool as the provider reported it;
+// failures the model should see are results with IsError set. Ping
+// returns an error when the provider cannot serve calls.
+type Provider interface {
+	ListTools(ctx context.Context) ([]protocol.Tool, error)
+	CallTool(ctx context.Context, name string, arguments json.RawMessage) (*protocol.CallToolResult, error)
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Status describes the health of a provider.
+type Status struct {
+	// Namespace is the namespace the provider was added under.
+	Namespace string
+	// Healthy reports whether the provider passed its last check.
+	Healthy bool
+	// Tools are the names the tools of the provider are registered under,
+	// or nil while it is unhealthy.
+	Tools []string
+	// Err is the error of the last check, or nil.
+	Err error
+	// CheckedAt is the time of the last check.
+	CheckedAt time.Time
+}
+
+// Host registers the tools of providers with a server and checks the
+// health of the providers.
+type Host struct {
+	srv       *server.Server
+	separator string
+	interval  time.Duration
+	timeout   time.Duration
+
+	// opMu serializes changes to the registered tools
+	opMu sync.Mutex
+
+	mu      sync.Mutex
+	plugins map[string]*entry
+}
+
+// entry is a provider added to a host.
+type entry struct {
+	namespace string
+	// start restarts the provider after a failed check, or is nil
+	start func(ctx context.Context) (Provider, error)
+
+	provider  Provider
+	healthy   bool
+	tools     []string
+	err       error
+	checkedAt time.Time
+}
+
+// Option configures a Host.
+type Option func(*Host)
+
+// WithSeparator sets the separator between the namespace and the tool
+// name. Defaults to ".".
+func WithSeparator(sep string) Option {
+	return func(h *Host) {
+		h.separator = sep
+	}
+}
+
+// WithHealthInterval sets how often Run checks the providers. Defaults to
+// 30 seconds.
+func WithHealthInterval(d time.Duration) Option {
+	return func(h *Host) {
+		h.interval = d
+	}
+}
+
+// WithHealthTimeout sets how long a provider may take to answer a health
+// check. Defaults to 5 seconds.
+func WithHealthTimeout(d time.Duration) Option {
+	return func(h *Host) {
+		h.timeout = d
+	}
+}
+
+// NewHost creates a host registering tools with srv.
+func NewHost(srv *server.Server, opts ...Option) *Host {
+	h := &Host{
+		srv:       srv,
+		separator: ".",
+		interval:  30 * time.Second,
+		timeout:   5 * time.Second,
+		plugins:   make(map[string]*entry),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Add registers the tools of p under namespace. The host owns p from then
+// on and closes it when it is removed. Add fails if the namespace is taken
+// or a tool name is already registered with the server.
+func (h *Host) Add(ctx context.Context, namespace string, p Provider) error {
+	return h.add(ctx, &entry{namespace: namespace, provider: p})
+}
+
+// AddProcess starts command as a subprocess serving MCP over stdio and
+// registers its tools under namespace. The process is restarted when it
+// fails a health check.
+func (h *Host) AddProcess(ctx context.Context, namespace, command string, args ...string) error {
+	start := func(ctx context.Context) (Provider, error) {
+		return StartProcess(ctx, command, args...)
+	}
+	p, err := start(ctx)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", namespace, err)
+	}
+	return h.add(ctx, &entry{namespace: namespace, start: start, provider: p})
+}
+
+func (h *Host) add(ctx context.Context, e *entry) error {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	_, exists := h.plugins[e.namespace]
+	h.mu.Unlock()
+	switch {
+	case e.namespace == "":
+		_ = e.provider.Close()
+		return errors.New("plugin: empty namespace")
+	case exists:
+		_ = e.provider.Close()
+		return fmt.Errorf("plugin %s: namespace already added", e.namespace)
+	}
+
+	if err := h.sync(ctx, e); err != nil {
+		_ = e.provider.Close()
+		return fmt.Errorf("plugin %s: %w", e.namespace, err)
+	}
+
+	h.mu.Lock()
+	e.healthy = true
+	e.checkedAt = time.Now()
+	h.plugins[e.namespace] = e
+	h.mu.Unlock()
+	return nil
+}
+
+// Remove unregisters the tools of the provider added under namespace and
+// closes it. It reports whether the namespace was added.
+func (h *Host) Remove(namespace string) bool {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	e, ok := h.plugins[namespace]
+	delete(h.plugins, namespace)
+	h.mu.Unlock()
+	if !ok {
+		return false
+	}
+	h.unregister(e)
+	if e.provider != nil {
+		_ = e.provider.Close()
+	}
+	return true
+}
+
+// Close removes every provider.
+func (h *Host) Close() error {
+	for _, s := range h.Statuses() {
+		h.Remove(s.Namespace)
+	}
+	return nil
+}
+
+// Statuses returns the status of every provider, sorted by namespace.
+func (h *Host) Statuses() []Status {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	statuses := make([]Status, 0, len(h.plugins))
+	for _, e := range h.plugins {
+		statuses = append(statuses, Status{
+			Namespace: e.namespace,
+			Healthy:   e.healthy,
+			Tools:     slices.Clone(e.tools),
+			Err:       e.err,
+			CheckedAt: e.checkedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Namespace < statuses[j].Namespace
+	})
+	return statuses
+}
+
+// Run checks the providers at the health interval until ctx is done.
+func (h *Host) Run(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		h.Check(ctx)
+	}
+}
+
+// Check pings every provider once. The tools of a provider that fails are
+// unregistered, and a process is restarted. The tools of a provider that
+// recovers are registered again.
+func (h *Host) Check(ctx context.Context) {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	entries := make([]*entry, 0, len(h.plugins))
+	for _, e := range h.plugins {
+		entries = append(entries, e)
+	}
+	h.mu.Unlock()
+
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		h.check(ctx, e)
+	}
+}
+
+// check pings the provider of e and updates its tools. The caller must
+// hold h.opMu.
+func (h *Host) check(ctx context.Context, e *entry) {
+	err := errors.New("not running")
+	if e.provider != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, h.timeout)
+		err = e.provider.Ping(pingCtx)
+		cancel()
+	}
+
+	restarted := false
+	if err != nil && e.start != nil {
+		if e.provider != nil {
+			_ = e.provider.Close()
+		}
+		p, startErr := e.start(ctx)
+		h.mu.Lock()
+		e.provider = p
+		h.mu.Unlock()
+		if startErr != nil {
+			err = fmt.Errorf("restart: %w", startErr)
+		} else {
+			err, restarted = nil, true
+		}
+	}
+
+	h.mu.Lock()
+	wasHealthy := e.healthy
+	h.mu.Unlock()
+	if err == nil && (!wasHealthy || restarted) {
+		err = h.sync(ctx, e)
+	}
+	if err != nil && wasHealthy {
+		h.unregister(e)
+	}
+
+	h.mu.Lock()
+	e.healthy = err == nil
+	e.err = err
+	e.checkedAt = time.Now()
+	h.mu.Unlock()
+}
+
+// sync registers the tools the provider of e lists and unregisters those
+// it no longer lists. The caller must hold h.opMu.
+func (h *Host) sync(ctx context.Context, e *entry) error {
+	tools, err := e.provider.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("list tools: %w", err)
+	}
+
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = e.namespace + h.separator + t.Name
+		if _, ok := h.srv.GetTool(names[i]); ok && !slices.Contains(e.tools, names[i]) {
+			return fmt.Errorf("tool %s is already registered", names[i])
+		}
+	}
+
+	for i, t := range tools {
+		inputSchema := t.InputSchema
+		if inputSchema == nil {
+			inputSchema = map[string]any{"type": "object"}
+		}
+		b := h.srv.Tool(names[i]).
+			Description(t.Description).
+			InputSchema(inputSchema)
+		if t.Title != "" {
+			b.Title(t.Title)
+		}
+		name := t.Name
+		b.Handler(func(ctx context.Context, args json.RawMessage) (*server.ToolResult, error) {
+			return h.call(ctx, e, name, args)
+		})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("register %s: %w", names[i], err)
+		}
+	}
+	for _, name := range e.tools {
+		if !slices.Contains(names, name) {
+			h.srv.RemoveTool(name)
+		}
+	}
+
+	h.mu.Lock()
+	e.tools = names
+	h.mu.Unlock()
+	return nil
+}
+
+// unregister removes the tools of e from the server. The caller must hold
+// h.opMu.
+func (h *Host) unregister(e *entry) {
+	h.mu.Lock()
+	names := e.tools
+	e.tools = nil
+	h.mu.Unlock()
+	for _, name := range names {
+		h.srv.RemoveTool(name)
+	}
+}
+
+// call forwards a tool call to the provider of e.
+func (h *Host) call(ctx context.Context, e *entry, name string, args json.RawMessage) (*server.ToolResult, error) {
+	h.mu.Lock()
+	p, healthy := e.provider, e.healthy
+	h.mu.Unlock()
+	if p == nil || !healthy {
+		return nil, mcperrors.Unavailable("plugin %s is unavailable", e.namespace)
+	}
+
+	res, err := p.CallTool(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	result := server.NewToolResult().Append(res.Content...)
+	if res.StructuredContent != nil {
+		result.Structured(res.StructuredContent)
+	}
+	if res.IsError {
+		result.WithError()
+	}
+	for k, v := range res.Meta {
+		result.WithMeta(k, v)
+	}
+	return result, nil
+}
@@ -0,0 +1,275 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go"
+	mcperrors "github.com/felixgeelhaar/mcp-go/errors"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// TestMain serves the plugin used by TestProcess when the test binary is
+// started as a subprocess.
+func TestMain(m *testing.M) {
+	if os.Getenv("MCP_PLUGIN_TEST_SERVER") == "1" {
+		srv := mcp.NewServer(mcp.ServerInfo{Name: "echo-plugin", Version: "1.0.0"})
+		srv.Tool("echo").Description("Echo text").Handler(func(in struct {
+			Text string `json:"text"`
+		}) (string, error) {
+			return in.Text, nil
+		})
+		if err := mcp.ServeStdio(context.Background(), srv); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeProvider is an in-memory Provider.
+type fakeProvider struct {
+	mu      sync.Mutex
+	tools   []protocol.Tool
+	pingErr error
+	closed  bool
+}
+
+func (p *fakeProvider) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.tools), nil
+}
+
+func (p *fakeProvider) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*protocol.CallToolResult, error) {
+	return &protocol.CallToolResult{
+		Content: []protocol.Content{{Type: "text", Text: name + " " + string(arguments)}},
+	}, nil
+}
+
+func (p *fakeProvider) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pingErr
+}
+
+func (p *fakeProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *fakeProvider) set(f func(p *fakeProvider)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f(p)
+}
+
+func toolNames(srv *server.Server) []string {
+	var names []string
+	for _, t := range srv.Tools() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func callText(t *testing.T, srv *server.Server, name, args string) (string, error) {
+	t.Helper()
+	tool, ok := srv.GetTool(name)
+	if !ok {
+		t.Fatalf("tool %s not registered", name)
+	}
+	res, err := tool.Execute(context.Background(), json.RawMessage(args))
+	if err != nil {
+		return "", err
+	}
+	content := res.(*server.ToolResult).Content()
+	if len(content) != 1 {
+		t.Fatalf("content = %+v, want one block", content)
+	}
+	return content[0].Text, nil
+}
+
+func TestHost_Add(t *testing.T) {
+	srv := server.New(server.Info{Name: "host", Version: "1.0.0"})
+	srv.Tool("git.local").Handler(func(in struct{}) (string, error) { return "", nil })
+	host := NewHost(srv)
+
+	git := &fakeProvider{tools: []protocol.Tool{
+		{Name: "status", Description: "Show status", InputSchema: map[string]any{"type": "object"}},
+		{Name: "diff"},
+	}}
+	if err := host.Add(context.Background(), "git", git); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if got, want := toolNames(srv), []string{"git.diff", "git.local", "git.status"}; !slices.Equal(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+	got, err := callText(t, srv, "git.status", `{"short":true}`)
+	if err != nil || got != `status {"short":true}` {
+		t.Errorf("call = %q, %v", got, err)
+	}
+
+	srv.Tool("docs.search").Handler(func(in struct{}) (string, error) { return "", nil })
+	tests := []struct {
+		name      string
+		namespace string
+		provider  *fakeProvider
+		wantErr   string
+	}{
+		{"empty namespace", "", &fakeProvider{}, "empty namespace"},
+		{"duplicate namespace", "git", &fakeProvider{}, "already added"},
+		{"tool conflict", "docs", &fakeProvider{tools: []protocol.Tool{{Name: "search"}}}, "docs.search is already registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := host.Add(context.Background(), tt.namespace, tt.provider)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Add() error = %v, want %q", err, tt.wantErr)
+			}
+			if !tt.provider.closed {
+				t.Error("rejected provider not closed")
+			}
+		})
+	}
+
+	if !host.Remove("git") || host.Remove("git") {
+		t.Error("Remove() did not report the namespace once")
+	}
+	if got := toolNames(srv); !slices.Equal(got, []string{"docs.search", "git.local"}) {
+		t.Errorf("tools after Remove = %v", got)
+	}
+	if !git.closed {
+		t.Error("removed provider not closed")
+	}
+}
+
+func TestHost_Check(t *testing.T) {
+	srv := server.New(server.Info{Name: "host", Version: "1.0.0"})
+	host := NewHost(srv, WithSeparator("_"))
+	ctx := context.Background()
+
+	p := &fakeProvider{tools: []protocol.Tool{{Name: "a"}, {Name: "b"}}}
+	if err := host.Add(ctx, "ns", p); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	tool, _ := srv.GetTool("ns_a")
+
+	// A failing provider is unlisted and its tools fail fast
+	p.set(func(p *fakeProvider) { p.pingErr = errors.New("connection reset") })
+	host.Check(ctx)
+	status := host.Statuses()[0]
+	if status.Healthy || status.Err == nil || status.Tools != nil {
+		t.Errorf("status = %+v, want unhealthy", status)
+	}
+	if got := toolNames(srv); len(got) != 0 {
+		t.Errorf("tools of unhealthy provider = %v", got)
+	}
+	_, err := tool.Execute(ctx, json.RawMessage(`{}`))
+	if mcperrors.KindOf(err) != mcperrors.KindUnavailable {
+		t.Errorf("call to unhealthy provider error = %v, want unavailable", err)
+	}
+
+	// A recovered provider is listed again with its current tools
+	p.set(func(p *fakeProvider) {
+		p.pingErr = nil
+		p.tools = []protocol.Tool{{Name: "b"}, {Name: "c"}}
+	})
+	host.Check(ctx)
+	status = host.Statuses()[0]
+	if !status.Healthy || status.Err != nil || status.CheckedAt.IsZero() {
+		t.Errorf("status = %+v, want healthy", status)
+	}
+	if got, want := toolNames(srv), []string{"ns_b", "ns_c"}; !slices.Equal(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+	if got, err := callText(t, srv, "ns_c", `{}`); err != nil || got != "c {}" {
+		t.Errorf("call = %q, %v", got, err)
+	}
+
+	if err := host.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(host.Statuses()) != 0 || len(toolNames(srv)) != 0 {
+		t.Error("Close() left providers or tools")
+	}
+}
+
+func TestHost_Run(t *testing.T) {
+	srv := server.New(server.Info{Name: "host", Version: "1.0.0"})
+	host := NewHost(srv, WithHealthInterval(5*time.Millisecond))
+	p := &fakeProvider{tools: []protocol.Tool{{Name: "a"}}}
+	if err := host.Add(context.Background(), "ns", p); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	p.set(func(p *fakeProvider) { p.pingErr = errors.New("down") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- host.Run(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for host.Statuses()[0].Healthy {
+		if time.Now().After(deadline) {
+			t.Fatal("provider not marked unhealthy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping subprocess test")
+	}
+	t.Setenv("MCP_PLUGIN_TEST_SERVER", "1")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("test binary path: %v", err)
+	}
+
+	srv := server.New(server.Info{Name: "host", Version: "1.0.0"})
+	host := NewHost(srv)
+	defer host.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := host.AddProcess(ctx, "echo", exe); err != nil {
+		t.Fatalf("AddProcess() error = %v", err)
+	}
+	tool, ok := srv.GetTool("echo.echo")
+	if !ok {
+		t.Fatalf("tools = %v, want echo.echo", toolNames(srv))
+	}
+	if got, err := callText(t, srv, "echo.echo", `{"text":"hello"}`); err != nil || got != "hello" {
+		t.Errorf("call = %q, %v", got, err)
+	}
+	if info := srv.Tools()[0]; info.Description != "Echo text" || info.InputSchema == nil {
+		t.Errorf("tool = %+v, want description and schema", info)
+	}
+
+	// A killed process is restarted by the next check
+	host.mu.Lock()
+	proc := host.plugins["echo"].provider.(*Process)
+	host.mu.Unlock()
+	_ = proc.Close()
+	host.Check(ctx)
+	if status := host.Statuses()[0]; !status.Healthy {
+		t.Fatalf("status after restart = %+v", status)
+	}
+	if _, err := tool.Execute(ctx, json.RawMessage(`{"text":"again"}`)); err != nil {
+		t.Errorf("call after restart error = %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixgeelhaar/mcp-go/client"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Process is a Provider running an MCP server as a subprocess over stdio.
+type Process struct {
+	transport *client.StdioTransport
+	client    *client.Client
+}
+
+// StartProcess starts command and initializes an MCP session with it.
+func StartProcess(ctx context.Context, command string, args ...string) (*Process, error) {
+	transport, err := client.NewStdioTransport(command, args...)
+	if err != nil {
+		return nil, err
+	}
+	c := client.New(transport, client.WithClientInfo("mcp-go-plugin-host", "1.0.0"))
+	if _, err := c.Initialize(ctx); err != nil {
+		_ = transport.Close()
+		return nil, err
+	}
+	return &Process{transport: transport, client: c}, nil
+}
+
+// ListTools returns the tools of the process.
+func (p *Process) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	tools, err := p.client.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]protocol.Tool, len(tools))
+	for i, t := range tools {
+		result[i] = protocol.Tool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: t.InputSchema,
+		}
+	}
+	return result, nil
+}
+
+// CallTool calls a tool of the process.
+func (p *Process) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*protocol.CallToolResult, error) {
+	var args any
+	if len(arguments) > 0 {
+		args = arguments
+	}
+	res, err := p.client.CallTool(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	result := res.CallToolResult()
+	return &result, nil
+}
+
+// Ping checks that the process answers requests.
+func (p *Process) Ping(ctx context.Context) error {
+	return p.client.Ping(ctx)
+}
+
+// Close stops the process.
+func (p *Process) Close() error {
+	if err := p.transport.Close(); err != nil {
+		return fmt.Errorf("stop plugin process: %w", err)
+	}
+	return nil
+}
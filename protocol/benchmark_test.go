@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// sink keeps benchmark results alive so the compiler can't elide them.
+var sink *Response
+
+func BenchmarkResponse(b *testing.B) {
+	id := json.RawMessage(`1`)
+	result := EmptyResult{}
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = NewResponse(id, result)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = AcquireResponse(id, result)
+			ReleaseResponse(sink)
+		}
+	})
+}
+
+func BenchmarkErrorResponse(b *testing.B) {
+	id := json.RawMessage(`1`)
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = NewErrorResponse(id, &Error{Code: CodeRateLimited, Message: "rate limit exceeded"})
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = AcquireErrorResponse(id, &Error{Code: CodeRateLimited, Message: "rate limit exceeded"})
+			ReleaseResponse(sink)
+		}
+	})
+}
+
+// BenchmarkErrorResponseWrite measures the full cost of writing an error
+// response, as a transport does for each rejected request.
+func BenchmarkErrorResponseWrite(b *testing.B) {
+	id := json.RawMessage(`1`)
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resp := NewErrorResponse(id, &Error{Code: CodeRateLimited, Message: "rate limit exceeded"})
+			if _, err := json.Marshal(resp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resp := AcquireErrorResponse(id, &Error{Code: CodeRateLimited, Message: "rate limit exceeded"})
+			if _, err := json.Marshal(resp); err != nil {
+				b.Fatal(err)
+			}
+			ReleaseResponse(resp)
+		}
+	})
+}
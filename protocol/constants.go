@@ -3,6 +3,10 @@ package protocol
 // MCP protocol version.
 const MCPVersion = "2024-11-05"
 
+// MetaTimeout is the _meta key of the timeout hint of a request: the
+// number of milliseconds the client is willing to wait for the result.
+const MetaTimeout = "timeoutMs"
+
 // MCP method names.
 const (
 	MethodInitialize             = "initialize"
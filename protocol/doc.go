@@ -31,11 +31,25 @@
 //	CodeInvalidParams  = -32602  // Invalid method parameters
 //	CodeInternalError  = -32603  // Internal server error
 //
+// MCP-specific codes include CodeNotFound, CodeUnauthorized for
+// missing credentials, CodeForbidden for an authenticated client that
+// is not allowed to perform a request, CodeRateLimited, and CodeUnavailable
+// for a request that failed because a dependency is temporarily down.
+//
 // Helper functions create properly formatted errors:
 //
 //	err := protocol.NewMethodNotFound("unknown/method")
 //	err := protocol.NewInvalidParams("missing required field: name")
 //
+// # Response Pooling
+//
+// Transports write a response for every request. AcquireResponse and
+// AcquireErrorResponse take responses from a pool, and ReleaseResponse
+// returns them once written. Only code that owns a response for its whole
+// lifetime should pool it; ReleaseResponse ignores responses created with
+// NewResponse, so it is safe to call on every response a transport writes.
+// See the benchmarks in benchmark_test.go for the measured savings.
+//
 // # MCP Method Constants
 //
 // Standard MCP method names are defined as constants:
@@ -17,6 +17,8 @@ const (
 	CodeNotFound     = -32001
 	CodeUnauthorized = -32002
 	CodeRateLimited  = -32003
+	CodeForbidden    = -32004
+	CodeUnavailable  = -32005
 )
 
 // Error represents a JSON-RPC 2.0 error.
@@ -83,3 +85,15 @@ func NewNotFound(msg string) *Error {
 func NewUnauthorized(msg string) *Error {
 	return &Error{Code: CodeUnauthorized, Message: msg}
 }
+
+// NewForbidden creates a forbidden error (-32004), for an authenticated
+// client that is not allowed to perform the request.
+func NewForbidden(msg string) *Error {
+	return &Error{Code: CodeForbidden, Message: msg}
+}
+
+// NewUnavailable creates an unavailable error (-32005), for a request that
+// failed because a dependency is temporarily down.
+func NewUnavailable(msg string) *Error {
+	return &Error{Code: CodeUnavailable, Message: msg}
+}
@@ -105,6 +105,22 @@ func TestNewUnauthorized(t *testing.T) {
 	}
 }
 
+func TestNewForbidden(t *testing.T) {
+	err := NewForbidden("tool not allowed")
+
+	if err.Code != CodeForbidden {
+		t.Errorf("Code = %d, want %d", err.Code, CodeForbidden)
+	}
+}
+
+func TestNewUnavailable(t *testing.T) {
+	err := NewUnavailable("database down")
+
+	if err.Code != CodeUnavailable {
+		t.Errorf("Code = %d, want %d", err.Code, CodeUnavailable)
+	}
+}
+
 func TestError_WithData(t *testing.T) {
 	data := map[string]string{"field": "query", "reason": "required"}
 	err := NewInvalidParams("validation failed").WithData(data)
@@ -1,6 +1,12 @@
 package protocol
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
 
 // JSONRPCVersion is the JSON-RPC protocol version.
 const JSONRPCVersion = "2.0"
@@ -18,12 +24,81 @@ func (r *Request) IsNotification() bool {
 	return len(r.ID) == 0
 }
 
+// TimeoutHint returns the timeout the client set in the MetaTimeout key of
+// the _meta of the params, if it set a positive whole number of
+// milliseconds.
+func (r *Request) TimeoutHint() (time.Duration, bool) {
+	if len(r.Params) == 0 {
+		return 0, false
+	}
+	var params struct {
+		Meta struct {
+			Timeout *int64 `json:"timeoutMs"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(r.Params, &params); err != nil {
+		return 0, false
+	}
+	ms := params.Meta.Timeout
+	if ms == nil || *ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(min(*ms, math.MaxInt64/int64(time.Millisecond))) * time.Millisecond, true
+}
+
+// SetTimeoutHint sets the MetaTimeout key of the _meta of the params to d,
+// rounded down to milliseconds but at least one, keeping the other params
+// and _meta keys. It fails if the params are not a JSON object.
+func (r *Request) SetTimeoutHint(d time.Duration) error {
+	var params map[string]json.RawMessage
+	if len(r.Params) > 0 {
+		if err := json.Unmarshal(r.Params, &params); err != nil {
+			return err
+		}
+	}
+	if params == nil {
+		params = make(map[string]json.RawMessage, 1)
+	}
+
+	var meta map[string]json.RawMessage
+	if raw, ok := params["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+	}
+	if meta == nil {
+		meta = make(map[string]json.RawMessage, 1)
+	}
+	meta[MetaTimeout] = json.RawMessage(strconv.FormatInt(max(d.Milliseconds(), 1), 10))
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	params["_meta"] = raw
+	r.Params, err = json.Marshal(params)
+	return err
+}
+
 // Response represents a JSON-RPC 2.0 response.
 type Response struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      json.RawMessage `json:"id,omitempty"`
 	Result  any             `json:"result,omitempty"`
 	Error   *Error          `json:"error,omitempty"`
+
+	// pooled marks responses owned by the response pool
+	pooled bool
+}
+
+// StreamingResult is a response result that can encode itself
+// incrementally, so a large result need not be held in memory. Transports
+// that can stream a response body, such as HTTP, call WriteJSON; others
+// encode it with MarshalJSON.
+type StreamingResult interface {
+	json.Marshaler
+	// WriteJSON writes the JSON encoding of the result to w.
+	WriteJSON(w io.Writer) error
 }
 
 // NewResponse creates a successful response.
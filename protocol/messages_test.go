@@ -3,6 +3,7 @@ package protocol
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestRequest_UnmarshalJSON(t *testing.T) {
@@ -105,6 +106,65 @@ func TestRequest_IsNotification(t *testing.T) {
 	}
 }
 
+func TestRequest_TimeoutHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"hint", `{"_meta":{"timeoutMs":1500}}`, 1500 * time.Millisecond, true},
+		{"no params", ``, 0, false},
+		{"no meta", `{"name":"search"}`, 0, false},
+		{"zero", `{"_meta":{"timeoutMs":0}}`, 0, false},
+		{"negative", `{"_meta":{"timeoutMs":-5}}`, 0, false},
+		{"fraction", `{"_meta":{"timeoutMs":1.5}}`, 0, false},
+		{"array params", `[1,2]`, 0, false},
+		{"huge", `{"_meta":{"timeoutMs":9223372036854775807}}`, 9223372036854 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Params: json.RawMessage(tt.params)}
+			got, ok := req.TimeoutHint()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TimeoutHint() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRequest_SetTimeoutHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		d      time.Duration
+		want   string
+	}{
+		{"no params", ``, 2 * time.Second, `{"_meta":{"timeoutMs":2000}}`},
+		{"null params", `null`, time.Second, `{"_meta":{"timeoutMs":1000}}`},
+		{"keeps params and meta", `{"name":"search","_meta":{"progressToken":"p1","timeoutMs":5}}`, time.Second, `{"_meta":{"progressToken":"p1","timeoutMs":1000},"name":"search"}`},
+		{"at least a millisecond", `{}`, time.Microsecond, `{"_meta":{"timeoutMs":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Params: json.RawMessage(tt.params)}
+			if err := req.SetTimeoutHint(tt.d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(req.Params) != tt.want {
+				t.Errorf("params = %s, want %s", req.Params, tt.want)
+			}
+		})
+	}
+
+	t.Run("rejects non-object params", func(t *testing.T) {
+		req := Request{Params: json.RawMessage(`[1]`)}
+		if err := req.SetTimeoutHint(time.Second); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
 func TestResponse_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,47 @@
+package protocol
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+var responsePool = sync.Pool{
+	New: func() any { return &Response{pooled: true} },
+}
+
+// AcquireResponse returns a successful response from a pool. Pass it to
+// ReleaseResponse once it has been written and is no longer referenced.
+//
+// Only code that owns the response for its whole lifetime, such as a
+// transport writing a response it built itself, should use the pool.
+// Responses returned from handlers may be retained by middleware and are
+// created with NewResponse instead.
+func AcquireResponse(id json.RawMessage, result any) *Response {
+	resp := responsePool.Get().(*Response)
+	resp.JSONRPC = JSONRPCVersion
+	resp.ID = id
+	resp.Result = result
+	return resp
+}
+
+// AcquireErrorResponse returns an error response from a pool. Pass it to
+// ReleaseResponse once it has been written.
+func AcquireErrorResponse(id json.RawMessage, err *Error) *Response {
+	resp := responsePool.Get().(*Response)
+	resp.JSONRPC = JSONRPCVersion
+	resp.ID = id
+	resp.Error = err
+	return resp
+}
+
+// ReleaseResponse returns a response obtained from AcquireResponse or
+// AcquireErrorResponse to the pool. Other responses are left alone, so
+// transports can release every response they write. resp must not be
+// used after it is released.
+func ReleaseResponse(resp *Response) {
+	if resp == nil || !resp.pooled {
+		return
+	}
+	*resp = Response{pooled: true}
+	responsePool.Put(resp)
+}
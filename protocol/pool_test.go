@@ -0,0 +1,50 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAcquireResponse(t *testing.T) {
+	resp := AcquireResponse(json.RawMessage(`1`), EmptyResult{})
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Errorf("response = %s", data)
+	}
+	ReleaseResponse(resp)
+
+	resp = AcquireErrorResponse(json.RawMessage(`2`), NewMethodNotFound("method not found"))
+	data, err = json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"method not found"}}` {
+		t.Errorf("error response = %s", data)
+	}
+	ReleaseResponse(resp)
+}
+
+func TestReleaseResponse(t *testing.T) {
+	t.Run("resets pooled responses", func(t *testing.T) {
+		resp := AcquireErrorResponse(json.RawMessage(`1`), NewInternalError("internal error"))
+		ReleaseResponse(resp)
+		if resp.ID != nil || resp.Error != nil || resp.Result != nil || resp.JSONRPC != "" {
+			t.Errorf("released response = %+v, want zero", resp)
+		}
+	})
+
+	t.Run("leaves other responses alone", func(t *testing.T) {
+		resp := NewResponse(json.RawMessage(`1`), "ok")
+		ReleaseResponse(resp)
+		if resp.Result != "ok" || string(resp.ID) != "1" {
+			t.Errorf("response = %+v, want unchanged", resp)
+		}
+	})
+
+	t.Run("accepts nil", func(t *testing.T) {
+		ReleaseResponse(nil)
+	})
+}
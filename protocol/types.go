@@ -0,0 +1,271 @@
+package protocol
+
+import "encoding/json"
+
+// Implementation identifies an MCP client or server.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities describes optional features supported by a client.
+type ClientCapabilities struct {
+	Roots    *RootsCapability    `json:"roots,omitempty"`
+	Sampling *SamplingCapability `json:"sampling,omitempty"`
+}
+
+// RootsCapability indicates the client can provide workspace roots.
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability indicates the client can sample from an LLM.
+type SamplingCapability struct {
+	Tools *SamplingToolsCapability `json:"tools,omitempty"`
+}
+
+// SamplingToolsCapability indicates the client accepts tools in sampling
+// requests.
+type SamplingToolsCapability struct{}
+
+// ServerCapabilities describes optional features supported by a server.
+// A nil field means the feature is not supported.
+type ServerCapabilities struct {
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Logging   *LoggingCapability   `json:"logging,omitempty"`
+}
+
+// ToolsCapability indicates the server offers tools.
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability indicates the server offers resources.
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability indicates the server offers prompts.
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// LoggingCapability indicates the server can send log messages.
+type LoggingCapability struct{}
+
+// InitializeParams are the params of an initialize request.
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
+}
+
+// InitializeResult is the result of an initialize request.
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// PaginatedParams are the params of a paginated list request.
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Icon is an image a client can display for a tool, resource or prompt.
+// Src is an https or data URI. Sizes lists dimensions such as "48x48" or
+// "any", and Theme is "light" or "dark" for icons drawn for one theme.
+type Icon struct {
+	Src      string   `json:"src"`
+	MimeType string   `json:"mimeType,omitempty"`
+	Sizes    []string `json:"sizes,omitempty"`
+	Theme    string   `json:"theme,omitempty"`
+}
+
+// Tool describes a tool in a tools/list result.
+type Tool struct {
+	Name         string `json:"name"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description"`
+	InputSchema  any    `json:"inputSchema"`
+	OutputSchema any    `json:"outputSchema,omitempty"`
+	Annotations  any    `json:"annotations,omitempty"`
+	Icons        []Icon `json:"icons,omitempty"`
+
+	// Meta is tool metadata, sent as _meta
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+// ToolsListResult is the result of a tools/list request.
+type ToolsListResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// CallToolParams are the params of a tools/call request.
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Content is a content block in a tool result.
+// Type is one of "text", "image", "audio", "resource_link" or "resource";
+// the other fields are set according to the type.
+type Content struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Data     string `json:"data,omitempty"`
+
+	// Resource links
+	URI         string `json:"uri,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Embedded resources
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// CallToolResult is the result of a tools/call request.
+type CallToolResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+
+	// StructuredContent is the result as JSON, sent by tools that declare
+	// an output schema
+	StructuredContent any `json:"structuredContent,omitempty"`
+
+	// Meta is result metadata, sent as _meta
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+// Resource describes a resource in a resources/list result.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Annotations any    `json:"annotations,omitempty"`
+	Icons       []Icon `json:"icons,omitempty"`
+}
+
+// ResourcesListResult is the result of a resources/list request.
+type ResourcesListResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized resource in a
+// resources/templates/list result.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Annotations any    `json:"annotations,omitempty"`
+	Icons       []Icon `json:"icons,omitempty"`
+}
+
+// ResourceTemplatesListResult is the result of a resources/templates/list request.
+type ResourceTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
+}
+
+// ReadResourceParams are the params of a resources/read request.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceContents is the content of a resource.
+// Exactly one of Text or Blob (base64 encoded) is set.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ReadResourceResult is the result of a resources/read request.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+
+	// Meta is result metadata, sent as _meta
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// Prompt describes a prompt in a prompts/list result.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Annotations any              `json:"annotations,omitempty"`
+	Icons       []Icon           `json:"icons,omitempty"`
+}
+
+// PromptsListResult is the result of a prompts/list request.
+type PromptsListResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// GetPromptParams are the params of a prompts/get request.
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptMessage is a message in a prompts/get result.
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// GetPromptResult is the result of a prompts/get request.
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+
+	// Meta is result metadata, sent as _meta
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+// EmptyResult is the result of requests that return no data, such as ping.
+type EmptyResult struct{}
+
+// DecodeResult decodes the result of resp into v.
+// It accepts results that are raw JSON as well as already-decoded values,
+// so it works both with responses read off the wire and those built in memory.
+func DecodeResult(resp *Response, v any) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	var data []byte
+	switch r := resp.Result.(type) {
+	case json.RawMessage:
+		data = r
+	case []byte:
+		data = r
+	default:
+		var err error
+		if data, err = json.Marshal(r); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
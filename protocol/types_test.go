@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestResultTypes_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name: "initialize result",
+			value: InitializeResult{
+				ProtocolVersion: MCPVersion,
+				Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+				ServerInfo:      Implementation{Name: "srv", Version: "1.0.0"},
+			},
+			want: `{"protocolVersion":"2024-11-05","capabilities":{"tools":{}},"serverInfo":{"name":"srv","version":"1.0.0"}}`,
+		},
+		{
+			name:  "tools list without cursor",
+			value: ToolsListResult{Tools: []Tool{{Name: "add", InputSchema: map[string]any{"type": "object"}}}},
+			want:  `{"tools":[{"name":"add","description":"","inputSchema":{"type":"object"}}]}`,
+		},
+		{
+			name:  "empty tools list",
+			value: ToolsListResult{Tools: []Tool{}, NextCursor: "abc"},
+			want:  `{"tools":[],"nextCursor":"abc"}`,
+		},
+		{
+			name:  "call tool error result",
+			value: CallToolResult{Content: []Content{{Type: "text", Text: "boom"}}, IsError: true},
+			want:  `{"content":[{"type":"text","text":"boom"}],"isError":true}`,
+		},
+		{
+			name:  "read resource result",
+			value: ReadResourceResult{Contents: []ResourceContents{{URI: "file://a", MimeType: "text/plain", Text: "hi"}}},
+			want:  `{"contents":[{"uri":"file://a","mimeType":"text/plain","text":"hi"}]}`,
+		},
+		{
+			name:  "prompt with required argument",
+			value: Prompt{Name: "greet", Arguments: []PromptArgument{{Name: "name", Required: true}}},
+			want:  `{"name":"greet","arguments":[{"name":"name","required":true}]}`,
+		},
+		{
+			name:  "empty result",
+			value: EmptyResult{},
+			want:  `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	t.Run("decodes in-memory result", func(t *testing.T) {
+		resp := NewResponse(json.RawMessage(`1`), map[string]any{
+			"tools":      []any{map[string]any{"name": "add"}},
+			"nextCursor": "next",
+		})
+
+		var result ToolsListResult
+		if err := DecodeResult(resp, &result); err != nil {
+			t.Fatalf("DecodeResult() error = %v", err)
+		}
+		if len(result.Tools) != 1 || result.Tools[0].Name != "add" || result.NextCursor != "next" {
+			t.Errorf("result = %+v", result)
+		}
+	})
+
+	t.Run("decodes raw JSON result", func(t *testing.T) {
+		resp := NewResponse(json.RawMessage(`1`), json.RawMessage(`{"serverInfo":{"name":"srv","version":"2"}}`))
+
+		var result InitializeResult
+		if err := DecodeResult(resp, &result); err != nil {
+			t.Fatalf("DecodeResult() error = %v", err)
+		}
+		if result.ServerInfo.Name != "srv" {
+			t.Errorf("ServerInfo.Name = %q, want %q", result.ServerInfo.Name, "srv")
+		}
+	})
+
+	t.Run("returns response error", func(t *testing.T) {
+		resp := NewErrorResponse(json.RawMessage(`1`), NewMethodNotFound("nope"))
+
+		var result EmptyResult
+		err := DecodeResult(resp, &result)
+		var mcpErr *Error
+		if !errors.As(err, &mcpErr) || mcpErr.Code != CodeMethodNotFound {
+			t.Errorf("DecodeResult() error = %v, want MethodNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Package redisstore keeps server state in Redis, so several MCP server
+// instances behind a load balancer share sessions, subscriptions and tasks.
+//
+// A Store implements server.SessionStore, server.SubscriptionStore,
+// server.TaskStore and transport.SessionStore. Pass it to the server and
+// to the HTTP transport:
+//
+//	store := redisstore.New(client, redisstore.WithTTL(24*time.Hour))
+//	srv := mcp.NewServer(info,
+//	    mcp.WithSessionStore(store),
+//	    mcp.WithSubscriptionStore(store),
+//	    mcp.WithTaskStore(store),
+//	)
+//	mcp.ServeHTTP(ctx, srv, ":8080", mcp.WithHTTPSessionStore(store))
+//
+// The package does not depend on a Redis driver. Client is the handful of
+// commands the store uses; with github.com/redis/go-redis/v9 it takes a
+// few lines:
+//
+//	type goRedis struct{ *redis.Client }
+//
+//	func (c goRedis) Get(ctx context.Context, key string) (string, bool, error) {
+//	    v, err := c.Client.Get(ctx, key).Result()
+//	    if errors.Is(err, redis.Nil) {
+//	        return "", false, nil
+//	    }
+//	    return v, err == nil, err
+//	}
+//
+//	func (c goRedis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+//	    return c.Client.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (c goRedis) Del(ctx context.Context, keys ...string) error {
+//	    return c.Client.Del(ctx, keys...).Err()
+//	}
+//
+//	func (c goRedis) SAdd(ctx context.Context, key string, members ...string) error {
+//	    return c.Client.SAdd(ctx, key, members).Err()
+//	}
+//
+//	func (c goRedis) SRem(ctx context.Context, key string, members ...string) error {
+//	    return c.Client.SRem(ctx, key, members).Err()
+//	}
+//
+//	func (c goRedis) SMembers(ctx context.Context, key string) ([]string, error) {
+//	    return c.Client.SMembers(ctx, key).Result()
+//	}
+//
+//	store := redisstore.New(goRedis{redis.NewClient(&redis.Options{Addr: "localhost:6379"})})
+package redisstore
@@ -0,0 +1,251 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// Client is the subset of Redis commands used by Store. Get reports a
+// missing key with ok false and a nil error.
+type Client interface {
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	SAdd(ctx context.Context, key string, members ...string) error
+	SRem(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
+
+// Store keeps sessions, subscriptions and tasks in Redis.
+type Store struct {
+	client Client
+	prefix string
+	ttl    time.Duration
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithPrefix sets the prefix of every key written by the store.
+// Defaults to "mcp:".
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithTTL expires session state ttl after it was last saved, so sessions
+// of clients that disappear without closing them are removed. Choose a TTL
+// longer than the longest expected session. By default session state does
+// not expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Store) {
+		s.ttl = ttl
+	}
+}
+
+// New creates a store using client.
+func New(client Client, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "mcp:",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Store) sessionKey(id string) string       { return s.prefix + "session:" + id }
+func (s *Store) taskKey(id string) string          { return s.prefix + "task:" + id }
+func (s *Store) subscribersKey(uri string) string  { return s.prefix + "subscribers:" + uri }
+func (s *Store) subscriptionsKey(id string) string { return s.prefix + "subscriptions:" + id }
+func (s *Store) subscribedKey() string             { return s.prefix + "subscribed" }
+
+// SaveSession stores the state of a session.
+func (s *Store) SaveSession(ctx context.Context, state server.SessionState) error {
+	return s.setJSON(ctx, s.sessionKey(state.ID), state, s.ttl)
+}
+
+// LoadSession returns the state of a session, or server.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (server.SessionState, error) {
+	var state server.SessionState
+	ok, err := s.getJSON(ctx, s.sessionKey(id), &state)
+	if err != nil {
+		return server.SessionState{}, err
+	}
+	if !ok {
+		return server.SessionState{}, server.ErrSessionNotFound
+	}
+	state.ID = id
+	return state, nil
+}
+
+// DeleteSession removes the state of a session.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.sessionKey(id)); err != nil {
+		return fmt.Errorf("delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// CreateSession records a new session with empty state, keeping the state
+// of an existing session.
+func (s *Store) CreateSession(ctx context.Context, id string) error {
+	exists, err := s.SessionExists(ctx, id)
+	if err != nil || exists {
+		return err
+	}
+	return s.SaveSession(ctx, server.SessionState{ID: id})
+}
+
+// SessionExists reports whether state is stored for a session.
+func (s *Store) SessionExists(ctx context.Context, id string) (bool, error) {
+	_, ok, err := s.client.Get(ctx, s.sessionKey(id))
+	if err != nil {
+		return false, fmt.Errorf("load session %s: %w", id, err)
+	}
+	return ok, nil
+}
+
+// Subscribe adds a subscription.
+func (s *Store) Subscribe(ctx context.Context, sessionID, uri string) error {
+	if err := s.client.SAdd(ctx, s.subscribersKey(uri), sessionID); err != nil {
+		return fmt.Errorf("subscribe %s: %w", uri, err)
+	}
+	if err := s.client.SAdd(ctx, s.subscriptionsKey(sessionID), uri); err != nil {
+		return fmt.Errorf("subscribe %s: %w", uri, err)
+	}
+	if err := s.client.SAdd(ctx, s.subscribedKey(), uri); err != nil {
+		return fmt.Errorf("subscribe %s: %w", uri, err)
+	}
+	return nil
+}
+
+// Unsubscribe removes a subscription.
+func (s *Store) Unsubscribe(ctx context.Context, sessionID, uri string) error {
+	if err := s.client.SRem(ctx, s.subscribersKey(uri), sessionID); err != nil {
+		return fmt.Errorf("unsubscribe %s: %w", uri, err)
+	}
+	if err := s.client.SRem(ctx, s.subscriptionsKey(sessionID), uri); err != nil {
+		return fmt.Errorf("unsubscribe %s: %w", uri, err)
+	}
+	return nil
+}
+
+// UnsubscribeAll removes all subscriptions of a session.
+func (s *Store) UnsubscribeAll(ctx context.Context, sessionID string) error {
+	uris, err := s.client.SMembers(ctx, s.subscriptionsKey(sessionID))
+	if err != nil {
+		return fmt.Errorf("unsubscribe session %s: %w", sessionID, err)
+	}
+	var errs []error
+	for _, uri := range uris {
+		if err := s.client.SRem(ctx, s.subscribersKey(uri), sessionID); err != nil {
+			errs = append(errs, fmt.Errorf("unsubscribe %s: %w", uri, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if err := s.client.Del(ctx, s.subscriptionsKey(sessionID)); err != nil {
+		return fmt.Errorf("unsubscribe session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// Subscribers returns the sessions subscribed to uri, sorted.
+func (s *Store) Subscribers(ctx context.Context, uri string) ([]string, error) {
+	ids, err := s.client.SMembers(ctx, s.subscribersKey(uri))
+	if err != nil {
+		return nil, fmt.Errorf("load subscribers of %s: %w", uri, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Count returns the total number of subscriptions. It reads the
+// subscribers of every URI ever subscribed to, so it is meant for
+// occasional use such as metrics, not for every request.
+func (s *Store) Count(ctx context.Context) (int, error) {
+	uris, err := s.client.SMembers(ctx, s.subscribedKey())
+	if err != nil {
+		return 0, fmt.Errorf("count subscriptions: %w", err)
+	}
+	count := 0
+	for _, uri := range uris {
+		ids, err := s.client.SMembers(ctx, s.subscribersKey(uri))
+		if err != nil {
+			return 0, fmt.Errorf("count subscriptions: %w", err)
+		}
+		if len(ids) == 0 {
+			_ = s.client.SRem(ctx, s.subscribedKey(), uri)
+		}
+		count += len(ids)
+	}
+	return count, nil
+}
+
+// SaveTask stores the state of a task. Tasks do not expire; the server
+// deletes finished tasks after its task retention period.
+func (s *Store) SaveTask(ctx context.Context, task server.TaskInfo) error {
+	return s.setJSON(ctx, s.taskKey(task.ID), task, 0)
+}
+
+// LoadTask returns the state of a task, or server.ErrTaskNotFound.
+func (s *Store) LoadTask(ctx context.Context, id string) (server.TaskInfo, error) {
+	var task server.TaskInfo
+	ok, err := s.getJSON(ctx, s.taskKey(id), &task)
+	if err != nil {
+		return server.TaskInfo{}, err
+	}
+	if !ok {
+		return server.TaskInfo{}, server.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// DeleteTask removes a task.
+func (s *Store) DeleteTask(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.taskKey(id)); err != nil {
+		return fmt.Errorf("delete task %s: %w", id, err)
+	}
+	return nil
+}
+
+// setJSON stores v encoded as JSON at key.
+func (s *Store) setJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	if err := s.client.Set(ctx, key, string(data), ttl); err != nil {
+		return fmt.Errorf("save %s: %w", key, err)
+	}
+	return nil
+}
+
+// getJSON decodes the JSON value at key into v. It reports false if the
+// key does not exist.
+func (s *Store) getJSON(ctx context.Context, key string, v any) (bool, error) {
+	data, ok, err := s.client.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("load %s: %w", key, err)
+	}
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return false, fmt.Errorf("decode %s: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,189 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/testutil"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+// fakeClient is an in-memory Client.
+type fakeClient struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	sets   map[string]map[string]struct{}
+	err    error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		values: make(map[string]string),
+		ttls:   make(map[string]time.Duration),
+		sets:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *fakeClient) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return "", false, c.err
+	}
+	v, ok := c.values[key]
+	return v, ok, nil
+}
+
+func (c *fakeClient) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *fakeClient) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for _, key := range keys {
+		delete(c.values, key)
+		delete(c.sets, key)
+	}
+	return nil
+}
+
+func (c *fakeClient) SAdd(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if c.sets[key] == nil {
+		c.sets[key] = make(map[string]struct{})
+	}
+	for _, m := range members {
+		c.sets[key][m] = struct{}{}
+	}
+	return nil
+}
+
+func (c *fakeClient) SRem(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for _, m := range members {
+		delete(c.sets[key], m)
+	}
+	if len(c.sets[key]) == 0 {
+		delete(c.sets, key)
+	}
+	return nil
+}
+
+func (c *fakeClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	members := make([]string, 0, len(c.sets[key]))
+	for m := range c.sets[key] {
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// keys returns the keys holding a value or a set, sorted.
+func (c *fakeClient) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var keys []string
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	for k := range c.sets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestStoreContract(t *testing.T) {
+	testutil.RunSubscriptionStoreContract(t, func(t *testing.T) server.SubscriptionStore {
+		return New(newFakeClient())
+	})
+	testutil.RunSessionStoreContract(t, func(t *testing.T) server.SessionStore {
+		return New(newFakeClient())
+	})
+	testutil.RunTaskStoreContract(t, func(t *testing.T) server.TaskStore {
+		return New(newFakeClient())
+	})
+	testutil.RunHTTPSessionStoreContract(t, func(t *testing.T) transport.SessionStore {
+		return New(newFakeClient())
+	})
+}
+
+func TestStore_Options(t *testing.T) {
+	client := newFakeClient()
+	store := New(client, WithPrefix("app:"), WithTTL(time.Hour))
+	ctx := context.Background()
+
+	if err := store.SaveSession(ctx, server.SessionState{ID: "s1"}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if err := store.SaveTask(ctx, server.TaskInfo{ID: "t1"}); err != nil {
+		t.Fatalf("SaveTask() error = %v", err)
+	}
+	if err := store.Subscribe(ctx, "s1", "file:///a"); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	want := []string{"app:session:s1", "app:subscribed", "app:subscribers:file:///a", "app:subscriptions:s1", "app:task:t1"}
+	if got := client.keys(); !slices.Equal(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	if ttl := client.ttls["app:session:s1"]; ttl != time.Hour {
+		t.Errorf("session TTL = %v, want 1h", ttl)
+	}
+	if ttl := client.ttls["app:task:t1"]; ttl != 0 {
+		t.Errorf("task TTL = %v, want none", ttl)
+	}
+}
+
+func TestStore_ClientErrors(t *testing.T) {
+	client := newFakeClient()
+	client.err = errors.New("connection refused")
+	store := New(client)
+	ctx := context.Background()
+
+	if _, err := store.LoadSession(ctx, "s1"); err == nil || errors.Is(err, server.ErrSessionNotFound) {
+		t.Errorf("LoadSession() error = %v, want client error", err)
+	}
+	if _, err := store.SessionExists(ctx, "s1"); !errors.Is(err, client.err) {
+		t.Errorf("SessionExists() error = %v, want client error", err)
+	}
+	if err := store.Subscribe(ctx, "s1", "file:///a"); !errors.Is(err, client.err) {
+		t.Errorf("Subscribe() error = %v, want client error", err)
+	}
+	if _, err := store.Count(ctx); !errors.Is(err, client.err) {
+		t.Errorf("Count() error = %v, want client error", err)
+	}
+	if _, err := store.LoadTask(ctx, "t1"); err == nil || errors.Is(err, server.ErrTaskNotFound) {
+		t.Errorf("LoadTask() error = %v, want client error", err)
+	}
+}
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// orderSchema describes a nested order document used by the benchmarks.
+func orderSchema() *Schema {
+	minQty, maxQty := 1.0, 100.0
+	item := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"sku":      {Type: "string"},
+			"quantity": {Type: "integer", Minimum: &minQty, Maximum: &maxQty},
+			"price":    {Type: "number"},
+			"gift":     {Type: "boolean"},
+			"tags":     {Type: "array", Items: &Schema{Type: "string"}},
+			"status":   {Type: "string", Enum: []any{"pending", "shipped", "delivered"}},
+		},
+		Required: []string{"sku", "quantity", "price"},
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id": {Type: "string"},
+			"customer": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name":  {Type: "string"},
+					"email": {Type: "string"},
+					"address": {
+						Type: "object",
+						Properties: map[string]*Schema{
+							"street": {Type: "string"},
+							"city":   {Type: "string"},
+							"zip":    {Type: "string"},
+						},
+						Required: []string{"street", "city"},
+					},
+				},
+				Required: []string{"name"},
+			},
+			"items": {Type: "array", Items: item},
+		},
+		Required: []string{"id", "customer", "items"},
+	}
+}
+
+// orderDocument returns an order with n items. If invalid is set, every
+// item has a quantity out of range and a missing price.
+func orderDocument(n int, invalid bool) json.RawMessage {
+	var sb strings.Builder
+	sb.WriteString(`{"id":"order-1","customer":{"name":"Ada","email":"ada@example.com",`)
+	sb.WriteString(`"address":{"street":"1 Main St","city":"London","zip":"N1"}},"items":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		if invalid {
+			fmt.Fprintf(&sb, `{"sku":"sku-%d","quantity":500,"gift":false,"tags":["a","b"],"status":"lost"}`, i)
+			continue
+		}
+		fmt.Fprintf(&sb, `{"sku":"sku-%d","quantity":%d,"price":9.99,"gift":false,"tags":["a","b"],"status":"pending"}`, i, i%100+1)
+	}
+	sb.WriteString(`]}`)
+	return json.RawMessage(sb.String())
+}
+
+func BenchmarkValidate(b *testing.B) {
+	s := orderSchema()
+
+	benchmarks := []struct {
+		name string
+		data json.RawMessage
+		opts []ValidateOption
+	}{
+		{name: "small", data: orderDocument(1, false)},
+		{name: "large", data: orderDocument(1000, false)},
+		{name: "large_invalid", data: orderDocument(1000, true)},
+		{name: "large_invalid_fail_fast", data: orderDocument(1000, true), opts: []ValidateOption{FailFast()}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bm.data)))
+			for i := 0; i < b.N; i++ {
+				_ = s.Validate(bm.data, bm.opts...)
+			}
+		})
+	}
+}
+
+func BenchmarkValidator(b *testing.B) {
+	compiled, err := orderSchema().Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarks := []struct {
+		name string
+		data json.RawMessage
+		opts []ValidateOption
+	}{
+		{name: "small", data: orderDocument(1, false)},
+		{name: "large", data: orderDocument(1000, false)},
+		{name: "large_invalid", data: orderDocument(1000, true)},
+		{name: "large_invalid_fail_fast", data: orderDocument(1000, true), opts: []ValidateOption{FailFast()}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bm.data)))
+			for i := 0; i < b.N; i++ {
+				_ = compiled.Validate(bm.data, bm.opts...)
+			}
+		})
+	}
+}
+
+func BenchmarkValidateValue(b *testing.B) {
+	s := orderSchema()
+	compiled, err := s.Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarks := []struct {
+		name string
+		data json.RawMessage
+	}{
+		{name: "large", data: orderDocument(1000, false)},
+		{name: "large_invalid", data: orderDocument(1000, true)},
+	}
+
+	for _, bm := range benchmarks {
+		var value any
+		if err := json.Unmarshal(bm.data, &value); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = s.ValidateValue(value)
+			}
+		})
+		b.Run(bm.name+"_compiled", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = compiled.ValidateValue(value)
+			}
+		})
+	}
+}
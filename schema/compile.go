@@ -0,0 +1,375 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Validator validates values against a schema compiled with Compile. It is
+// safe for concurrent use.
+type Validator struct {
+	root  *Schema
+	check checkFunc
+}
+
+// checkFunc validates a value against a compiled schema, recording errors
+// at the current path of v.
+type checkFunc func(v *validator, value any)
+
+// Compile prepares s for repeated validation. The returned Validator
+// checks values as Validate does, but resolves references, compiles
+// patterns and indexes properties once, so each call only walks the value.
+// Problems that Validate reports per call, such as an invalid pattern or
+// an unresolved reference, are returned by Compile instead. The Validator
+// does not see later changes to s.
+func (s *Schema) Compile() (*Validator, error) {
+	c := &compiler{root: s, slots: make(map[*Schema]*checkFunc)}
+	check, err := c.compile(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{root: s, check: check}, nil
+}
+
+// Validate validates JSON data against the compiled schema.
+// Returns nil if valid, or ValidationErrors if invalid.
+func (c *Validator) Validate(data json.RawMessage, opts ...ValidateOption) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %s", err)}
+	}
+	return c.ValidateValue(value, opts...)
+}
+
+// ValidateValue validates a Go value against the compiled schema.
+func (c *Validator) ValidateValue(value any, opts ...ValidateOption) error {
+	return run(c.root, c.check, value, opts)
+}
+
+// compiler holds the state of a compilation.
+type compiler struct {
+	root *Schema
+	// slots holds the check of each schema compiled or being compiled, so
+	// shared and recursive schemas are compiled once
+	slots map[*Schema]*checkFunc
+}
+
+// compile returns the check for s.
+func (c *compiler) compile(s *Schema) (checkFunc, error) {
+	if s == nil {
+		return func(*validator, any) {}, nil
+	}
+	if slot, ok := c.slots[s]; ok {
+		// The check may still be being built, as for a recursive schema
+		return func(v *validator, value any) { (*slot)(v, value) }, nil
+	}
+
+	slot := new(checkFunc)
+	c.slots[s] = slot
+	check, err := c.build(s)
+	if err != nil {
+		return nil, err
+	}
+	*slot = check
+	return check, nil
+}
+
+func (c *compiler) build(s *Schema) (checkFunc, error) {
+	if s.IsFalse() {
+		return func(v *validator, _ any) { v.fail("value is not allowed") }, nil
+	}
+	if s.Ref != "" {
+		target, err := resolveRef(c.root, s)
+		if err != nil {
+			return nil, err
+		}
+		check, err := c.compile(target)
+		if err != nil {
+			return nil, err
+		}
+		return func(v *validator, value any) {
+			if value != nil {
+				check(v, value)
+			}
+		}, nil
+	}
+
+	oneOf, err := c.compileOneOf(s)
+	if err != nil {
+		return nil, err
+	}
+	typed, err := c.compileType(s)
+	if err != nil {
+		return nil, err
+	}
+	return func(v *validator, value any) {
+		// null is valid for any type unless required is enforced elsewhere
+		if value == nil {
+			return
+		}
+		if s.Const != nil && !equalValues(s.Const, value) {
+			v.fail(fmt.Sprintf("value must be %v", s.Const))
+			return
+		}
+		if oneOf != nil {
+			oneOf(v, value)
+		}
+		if typed != nil {
+			typed(v, value)
+		}
+	}, nil
+}
+
+func (c *compiler) compileType(s *Schema) (checkFunc, error) {
+	switch s.Type {
+	case typeObject:
+		return c.compileObject(s)
+	case typeArray:
+		return c.compileArray(s)
+	case typeString:
+		return compileString(s)
+	case typeInteger:
+		return func(v *validator, value any) { v.validateInteger(s, value) }, nil
+	case typeNumber:
+		return func(v *validator, value any) { v.validateNumber(s, value) }, nil
+	case typeBoolean:
+		return func(v *validator, value any) { v.validateBoolean(s, value) }, nil
+	}
+	return nil, nil
+}
+
+func (c *compiler) compileObject(s *Schema) (checkFunc, error) {
+	properties := make(map[string]checkFunc, len(s.Properties))
+	for name, prop := range s.Properties {
+		check, err := c.compile(prop)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = check
+	}
+	closed := s.AdditionalProperties.IsFalse()
+	var additional checkFunc
+	if s.AdditionalProperties != nil && !closed {
+		check, err := c.compile(s.AdditionalProperties)
+		if err != nil {
+			return nil, err
+		}
+		additional = check
+	}
+
+	return func(v *validator, value any) {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.failType(typeObject, value)
+			return
+		}
+		v.validateRequired(s, obj)
+		if closed || additional != nil {
+			v.checkAdditional(obj, properties, closed, additional)
+		}
+
+		// Look properties up from the smaller map
+		if len(obj) < len(properties) {
+			for name, val := range obj {
+				if v.done() {
+					return
+				}
+				if check, exists := properties[name]; exists {
+					v.checkField(check, name, val)
+				}
+			}
+			return
+		}
+		for name, check := range properties {
+			if v.done() {
+				return
+			}
+			if val, exists := obj[name]; exists {
+				v.checkField(check, name, val)
+			}
+		}
+	}, nil
+}
+
+// checkAdditional checks the properties of obj that are not listed in
+// properties: closed rejects them, and otherwise additional checks them.
+func (v *validator) checkAdditional(obj map[string]any, properties map[string]checkFunc, closed bool, additional checkFunc) {
+	for name, val := range obj {
+		if v.done() {
+			return
+		}
+		if _, listed := properties[name]; listed {
+			continue
+		}
+		if closed {
+			v.failField(name, msgAdditional)
+			continue
+		}
+		v.checkField(additional, name, val)
+	}
+}
+
+func (v *validator) checkField(check checkFunc, name string, value any) {
+	n := v.pushField(name)
+	check(v, value)
+	v.pop(n)
+}
+
+func (c *compiler) compileArray(s *Schema) (checkFunc, error) {
+	var items checkFunc
+	if s.Items != nil {
+		check, err := c.compile(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		items = check
+	}
+	prefix := make([]checkFunc, len(s.PrefixItems))
+	for i, item := range s.PrefixItems {
+		check, err := c.compile(item)
+		if err != nil {
+			return nil, err
+		}
+		prefix[i] = check
+	}
+
+	return func(v *validator, value any) {
+		list, ok := value.([]any)
+		if !ok {
+			// Other slices are checked the uncompiled way
+			v.validateArray(s, value)
+			return
+		}
+		v.validateItemCount(s, len(list))
+		for i, item := range list {
+			if v.done() {
+				return
+			}
+			check := items
+			if i < len(prefix) {
+				check = prefix[i]
+			}
+			if check == nil {
+				continue
+			}
+			n := v.pushIndex(i)
+			check(v, item)
+			v.pop(n)
+		}
+	}, nil
+}
+
+func compileString(s *Schema) (checkFunc, error) {
+	var re *regexp.Regexp
+	if s.Pattern != "" {
+		compiled, err := compilePattern(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		re = compiled
+	}
+	valid := formats[s.Format]
+
+	return func(v *validator, value any) {
+		str, ok := value.(string)
+		if !ok {
+			v.failType(typeString, value)
+			return
+		}
+		v.validateLength(s, str)
+		if re != nil && !re.MatchString(str) {
+			v.fail(fmt.Sprintf("value does not match pattern %q", s.Pattern))
+		}
+		if valid != nil && !valid(str) {
+			v.fail(fmt.Sprintf("value is not a valid %s", s.Format))
+		}
+		v.validateEnum(s, str)
+	}, nil
+}
+
+// compiledVariant is a variant of a discriminated union.
+type compiledVariant struct {
+	name  any
+	check checkFunc
+}
+
+func (c *compiler) compileOneOf(s *Schema) (checkFunc, error) {
+	if len(s.OneOf) == 0 {
+		return nil, nil
+	}
+	if s.Discriminator != nil {
+		return c.compileDiscriminated(s)
+	}
+
+	variants := make([]checkFunc, len(s.OneOf))
+	for i, variant := range s.OneOf {
+		check, err := c.compile(variant)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = check
+	}
+	return func(v *validator, value any) {
+		matched := 0
+		for _, check := range variants {
+			sub := validatorPool.Get().(*validator)
+			sub.maxErrors = 1
+			check(sub, value)
+			if len(sub.errs) == 0 {
+				matched++
+			}
+			sub.reset()
+			validatorPool.Put(sub)
+		}
+		if matched != 1 {
+			v.fail(fmt.Sprintf("value must match exactly one schema of oneOf, matched %d", matched))
+		}
+	}, nil
+}
+
+func (c *compiler) compileDiscriminated(s *Schema) (checkFunc, error) {
+	prop := s.Discriminator.PropertyName
+	variants := make([]compiledVariant, 0, len(s.OneOf))
+	names := make([]any, 0, len(s.OneOf))
+	for _, variant := range s.OneOf {
+		resolved, err := resolveRef(c.root, variant)
+		if err != nil {
+			continue
+		}
+		constant := resolved.Properties[prop]
+		if constant == nil || constant.Const == nil {
+			continue
+		}
+		check, err := c.compile(resolved)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, compiledVariant{name: constant.Const, check: check})
+		names = append(names, constant.Const)
+	}
+
+	return func(v *validator, value any) {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.failType(typeObject, value)
+			return
+		}
+		n := v.pushField(prop)
+		name, exists := obj[prop]
+		if !exists {
+			v.fail(msgRequired)
+			v.pop(n)
+			return
+		}
+		for _, variant := range variants {
+			if equalValues(variant.name, name) {
+				v.pop(n)
+				variant.check(v, value)
+				return
+			}
+		}
+		v.failNotOneOf(names)
+		v.pop(n)
+	}, nil
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// errorList returns the sorted messages of a validation error.
+func errorList(err error) []string {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		if err == nil {
+			return nil
+		}
+		return []string{err.Error()}
+	}
+	list := make([]string, len(errs))
+	for i, e := range errs {
+		list[i] = e.Error()
+	}
+	slices.Sort(list)
+	return list
+}
+
+func TestCompile_MatchesValidate(t *testing.T) {
+	type Input struct {
+		Shape  shape             `json:"shape"`
+		Expr   expr              `json:"expr"`
+		Tree   *treeNode         `json:"tree"`
+		Limits map[string]int    `json:"limits"`
+		Code   string            `json:"code" jsonschema:"minLength=2,maxLength=3,pattern=^[a-z]+$"`
+		Email  string            `json:"email" jsonschema:"format=email"`
+		Ratio  float64           `json:"ratio" jsonschema:"exclusiveMinimum=0,multipleOf=0.5"`
+		Point  [2]int            `json:"point"`
+		Closed struct{ A int }   `json:"closed"`
+		Level  string            `json:"level" jsonschema:"required,enum=low|high"`
+		Extra  map[string][]bool `json:"extra"`
+	}
+	generated, err := Generate(Input{}, NoAdditionalProperties())
+	if err != nil {
+		t.Fatal(err)
+	}
+	two := 2
+	schemas := map[string]*Schema{
+		"generated": generated,
+		"order":     orderSchema(),
+		"tuple":     {Type: "array", PrefixItems: []*Schema{{Type: "number"}, {Type: "string"}}, Items: False(), MinItems: &two},
+		"oneOf":     {OneOf: []*Schema{{Type: "string"}, {Type: "integer"}, {Type: "number"}}},
+		"const":     {Const: "v1"},
+		"false":     False(),
+	}
+	inputs := []string{
+		`{"level": "low"}`,
+		`{"level": "mid", "code": "ABCD", "email": "nope", "ratio": 0.7, "point": [1, 2, 3], "closed": {"A": 1, "B": 2}}`,
+		`{"level": "high", "shape": {"kind": "circle", "radius": -1}, "limits": {"a": 1, "b": "x"}, "extra": {"k": [true, 1]}}`,
+		`{"expr": {"type": "binary", "op": "+", "left": {"type": "literal", "value": 1}, "right": {"type": "binary", "op": "-"}}}`,
+		`{"tree": {"name": "a", "children": [{"name": "b", "children": [{}]}]}, "unknown": 1}`,
+		`{"shape": {"kind": "triangle"}, "level": 1}`,
+		string(orderDocument(3, false)),
+		string(orderDocument(3, true)),
+		`[1.5, "a"]`, `[1.5, 2, true]`, `[1]`,
+		`"text"`, `3`, `2.5`, `"v1"`, `null`, `true`,
+	}
+
+	for name, s := range schemas {
+		compiled, err := s.Compile()
+		if err != nil {
+			t.Fatalf("%s: Compile() error = %v", name, err)
+		}
+		for _, input := range inputs {
+			want := errorList(s.Validate(json.RawMessage(input)))
+			got := errorList(compiled.Validate(json.RawMessage(input)))
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: Validate(%s)\ncompiled: %q\nschema:   %q", name, input, got, want)
+			}
+			if n := len(errorList(compiled.Validate(json.RawMessage(input), FailFast()))); n > 1 || (n == 0) != (len(want) == 0) {
+				t.Errorf("%s: Validate(%s, FailFast()) reported %d errors", name, input, n)
+			}
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *Schema
+		wantErr string
+	}{
+		{"invalid pattern", &Schema{Type: "object", Properties: map[string]*Schema{"a": {Type: "string", Pattern: "("}}}, `invalid pattern "("`},
+		{"unresolved reference", &Schema{Type: "array", Items: &Schema{Ref: "#/$defs/missing"}}, `unresolved reference "#/$defs/missing"`},
+		{"reference cycle", &Schema{Ref: "#/$defs/a", Defs: map[string]*Schema{"a": {Ref: "#/$defs/a"}}}, `reference cycle at "#/$defs/a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.schema.Compile()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_Concurrent(t *testing.T) {
+	compiled, err := orderSchema().Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, invalid := orderDocument(10, false), orderDocument(10, true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := compiled.Validate(valid); err != nil {
+					t.Errorf("Validate(valid) error = %v", err)
+					return
+				}
+				if err := compiled.Validate(invalid); len(errorList(err)) != 30 {
+					t.Errorf("Validate(invalid) reported %d errors, want 30", len(errorList(err)))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -24,8 +24,12 @@
 //   - Floats: Converted to JSON number type
 //   - Booleans: Converted to JSON boolean type
 //   - Slices/Arrays: Converted to JSON array type
-//   - Maps: Converted to JSON object type
+//   - Maps: Converted to JSON objects whose additionalProperties is the
+//     schema of the map values; maps of any values accept any properties
 //   - Pointers: Dereferenced and converted based on element type
+//   - Text types: Types implementing encoding.TextMarshaler or
+//     encoding.TextUnmarshaler, such as netip.Addr, are strings; uuid.UUID
+//     has the uuid format
 //
 // # Struct Tags
 //
@@ -41,19 +45,142 @@
 //	    // jsonschema:"description=..." adds description
 //	    Desc string `json:"desc" jsonschema:"description=Field description"`
 //
+//	    // jsonschema:"enum=a|b|c" restricts the allowed values; the values
+//	    // of integer, number and boolean fields are parsed as such, and
+//	    // those of slices constrain the items
+//	    Op string `json:"op" jsonschema:"enum=add|subtract|multiply|divide"`
+//
+//	    // minimum, maximum, exclusiveMinimum, exclusiveMaximum and
+//	    // multipleOf bound numeric fields
+//	    Limit int `json:"limit" jsonschema:"minimum=1,maximum=100"`
+//
+//	    // minLength, maxLength, pattern and format constrain strings; the
+//	    // email, uri, date-time and uuid formats are validated. A pattern
+//	    // may contain commas, so it must come last in the tag
+//	    Slug string `json:"slug" jsonschema:"maxLength=64,pattern=^[a-z0-9-]+$"`
+//
+//	    // default and example values are typed after the field; example
+//	    // may be repeated, and the values of slices list items with "|"
+//	    Page int `json:"page" jsonschema:"default=1,example=3"`
+//
+//	    // time.Time is a date-time string; time.Duration is a string
+//	    // such as "1m30s", which tool handlers receive decoded
+//	    Timeout time.Duration `json:"timeout"`
+//
+//	    // Recursive types are emitted once in $defs and referenced with
+//	    // $ref; the validator follows the references
+//	    Children []*Node `json:"children"`
+//
 //	    // json:"-" excludes field
 //	    Ignored string `json:"-"`
+//
+//	    // nullable allows null, encoding the type as ["string", "null"]
+//	    Note *string `json:"note" jsonschema:"nullable"`
+//
+//	    // readOnly, writeOnly and deprecated annotate fields for clients
+//	    // without affecting validation
+//	    Fax string `json:"fax" jsonschema:"deprecated"`
+//
+//	    // Fields of embedded structs are promoted, following the rules of
+//	    // encoding/json; give the embedded struct a json name to nest it
+//	    Paging
+//	}
+//
+// # Generation Options
+//
+// NullablePointers makes every pointer field nullable, and
+// NoAdditionalProperties forbids unknown properties on every object. A
+// struct sets or overrides the latter with a blank field:
+//
+//	type Filter struct {
+//	    _     struct{} `jsonschema:"additionalProperties=false"`
+//	    Query string   `json:"query"`
 //	}
 //
+// Generated schemas use the keywords of JSON Schema draft 2020-12, such as
+// $defs. WithDialect(Draft202012) declares the dialect with $schema, and
+// WithID sets $id, for hosts that validate tool schemas strictly. Go
+// arrays of fixed length bound the number of items with minItems and
+// maxItems; tuples, described with PrefixItems, come from custom schemas.
+//
+// # Custom Schemas
+//
+// Types whose JSON form differs from their Go structure, such as types
+// with custom marshaling, implement Provider to declare their own schema:
+//
+//	func (Money) JSONSchema() *schema.Schema {
+//	    return &schema.Schema{Type: "string", Pattern: `^-?[0-9]+\.[0-9]{2}$`}
+//	}
+//
+// # Union Types
+//
+// Interface fields generate a oneOf schema once their variants are
+// registered, typically in an init function. The discriminator property
+// names the variant, and tool inputs decode into it:
+//
+//	err := schema.RegisterUnion[Shape]("kind", map[string]Shape{
+//	    "circle": Circle{},
+//	    "square": Square{},
+//	})
+//
+// Register unions before the tools that take them.
+//
 // # Generated Schema
 //
 // The Schema type represents a JSON Schema:
 //
 //	type Schema struct {
+//	    Dialect     Dialect            `json:"$schema,omitempty"`
+//	    ID          string             `json:"$id,omitempty"`
+//	    Ref         string             `json:"$ref,omitempty"`
+//	    Defs        map[string]*Schema `json:"$defs,omitempty"`
 //	    Type        string             `json:"type,omitempty"`
 //	    Properties  map[string]*Schema `json:"properties,omitempty"`
 //	    Required    []string           `json:"required,omitempty"`
 //	    Description string             `json:"description,omitempty"`
+//	    Default     any                `json:"default,omitempty"`
+//	    Examples    []any              `json:"examples,omitempty"`
+//	    Enum        []any              `json:"enum,omitempty"`
+//	    Minimum     *float64           `json:"minimum,omitempty"`
+//	    Maximum     *float64           `json:"maximum,omitempty"`
+//	    MultipleOf  *float64           `json:"multipleOf,omitempty"`
+//	    MinLength   *int               `json:"minLength,omitempty"`
+//	    MaxLength   *int               `json:"maxLength,omitempty"`
+//	    Pattern     string             `json:"pattern,omitempty"`
+//	    Format      string             `json:"format,omitempty"`
+//	    Const       any                `json:"const,omitempty"`
+//	    OneOf       []*Schema          `json:"oneOf,omitempty"`
+//	    AdditionalProperties *Schema    `json:"additionalProperties,omitempty"`
+//	    Nullable    bool               `json:"-"` // type: [Type, "null"]
 //	    Items       *Schema            `json:"items,omitempty"`
+//	    PrefixItems []*Schema          `json:"prefixItems,omitempty"`
+//	    MinItems    *int               `json:"minItems,omitempty"`
+//	    MaxItems    *int               `json:"maxItems,omitempty"`
 //	}
+//
+// # Validation
+//
+// Validate checks JSON data against a schema and returns ValidationErrors
+// with the path of each invalid field. Use FailFast or MaxErrors to stop
+// early on large documents:
+//
+//	if err := s.Validate(data, schema.FailFast()); err != nil {
+//	    return err
+//	}
+//
+// Schemas validated on a hot path can be compiled once. The Validator
+// returned by Compile reports the same errors without resolving references
+// or looking up patterns per call; tools compile their input schemas:
+//
+//	v, err := s.Compile()
+//	...
+//	err = v.Validate(data)
+//
+// # Go Source
+//
+// GenerateGo goes the other way, writing Go structs with json and
+// jsonschema tags for a JSON Schema document, for servers defined
+// spec-first:
+//
+//	src, err := schema.GenerateGo(&s, "tools", "SearchInput")
 package schema
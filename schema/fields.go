@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// jsonField is a field of a struct as encoding/json sees it.
+type jsonField struct {
+	name  string
+	field reflect.StructField
+	// depth is the embedding depth, zero for fields of the struct itself
+	depth  int
+	tagged bool
+}
+
+// jsonFields returns the fields encoding/json encodes for struct type t, in
+// field order. Fields of embedded structs without a JSON name are promoted
+// into t. When several fields have the same name, the shallowest one wins,
+// then a tagged one; names that remain ambiguous are dropped, as
+// encoding/json does.
+func jsonFields(t reflect.Type) []jsonField {
+	var all []jsonField
+	collectFields(&all, t, 0, map[reflect.Type]bool{t: true})
+
+	byName := make(map[string][]int)
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+
+	fields := make([]jsonField, 0, len(all))
+	for i, f := range all {
+		if winner, ok := dominantField(all, byName[f.name]); ok && winner == i {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// collectFields appends the fields of struct type t at the given depth,
+// descending into embedded structs. visited holds the embedded types on
+// the current path, which stops types that embed themselves.
+func collectFields(fields *[]jsonField, t reflect.Type, depth int, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			// Exported fields of unexported embedded structs are still
+			// promoted, but not through unexported pointers
+			if !field.IsExported() && (ft.Kind() != reflect.Struct || field.Type.Kind() == reflect.Ptr) {
+				continue
+			}
+			if name == "" && ft.Kind() == reflect.Struct {
+				if !visited[ft] {
+					visited[ft] = true
+					collectFields(fields, ft, depth+1, visited)
+					delete(visited, ft)
+				}
+				continue
+			}
+		} else if !field.IsExported() {
+			continue
+		}
+
+		f := jsonField{name: name, field: field, depth: depth, tagged: name != ""}
+		if name == "" {
+			f.name = field.Name
+		}
+		*fields = append(*fields, f)
+	}
+}
+
+// dominantField returns the index of the field that wins among the fields
+// with the same name, or false if none does.
+func dominantField(all []jsonField, candidates []int) (int, bool) {
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	depth := all[candidates[0]].depth
+	for _, i := range candidates[1:] {
+		depth = min(depth, all[i].depth)
+	}
+
+	winner, count, tagged := -1, 0, 0
+	for _, i := range candidates {
+		if all[i].depth != depth {
+			continue
+		}
+		count++
+		if all[i].tagged {
+			tagged++
+			winner = i
+		} else if winner < 0 || !all[winner].tagged {
+			winner = i
+		}
+	}
+	switch {
+	case count == 1, tagged == 1:
+		return winner, true
+	default:
+		return 0, false
+	}
+}
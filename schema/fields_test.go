@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type Audit struct {
+	CreatedBy string `json:"createdBy" jsonschema:"required"`
+	Note      string `json:"note"`
+}
+
+type paging struct {
+	Limit int `json:"limit" jsonschema:"maximum=100"`
+}
+
+type Named struct {
+	Name string
+}
+
+type Labeled struct {
+	Name  string
+	Label string
+}
+
+type Contact struct {
+	Email string `json:"email"`
+}
+
+type captioned struct {
+	Caption string `json:"Label"`
+}
+
+type Recursive struct {
+	*Recursive
+	ID string `json:"id"`
+}
+
+func TestGenerate_Embedded(t *testing.T) {
+	type Input struct {
+		Audit
+		paging
+		Contact `json:"contact"`
+		Named
+		Labeled        // name conflicts with Named at the same depth and is dropped
+		Note    string `json:"note" jsonschema:"description=Overrides Audit.Note"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	want := map[string]bool{"createdBy": true, "limit": true, "contact": true, "Label": true, "note": true}
+	if len(names) != len(want) {
+		t.Fatalf("properties = %v, want %v", names, want)
+	}
+	for _, name := range names {
+		if !want[name] {
+			t.Errorf("unexpected property %q", name)
+		}
+	}
+	if got := schema.Properties["note"].Description; got != "Overrides Audit.Note" {
+		t.Errorf("note description = %q, want the shallower field", got)
+	}
+	if got := schema.Properties["contact"].Properties["email"]; got == nil {
+		t.Error("tagged embedded struct not kept as a nested object")
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"createdBy"}) {
+		t.Errorf("required = %v, want promoted required field", schema.Required)
+	}
+
+	// The schema matches how encoding/json decodes the payload
+	var in Input
+	data := []byte(`{"createdBy": "ada", "limit": 5, "contact": {"email": "x"}, "note": "hi"}`)
+	if err := schema.Validate(data); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := json.Unmarshal(data, &in); err != nil || in.CreatedBy != "ada" || in.Limit != 5 || in.Email != "x" || in.Note != "hi" {
+		t.Errorf("decoded = %+v, %v", in, err)
+	}
+	if err := schema.Validate([]byte(`{"createdBy": "ada", "limit": 500}`)); err == nil {
+		t.Error("promoted constraint not validated")
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{"self embedding", reflect.TypeOf(Recursive{}), []string{"id"}},
+		{"tagged wins at same depth", reflect.TypeOf(struct {
+			Labeled
+			captioned
+		}{}), []string{"Name", "Label"}},
+		{"unexported embedded pointer", reflect.TypeOf(struct {
+			*paging
+			Audit
+		}{}), []string{"createdBy", "note"}},
+		{"skipped fields", reflect.TypeOf(struct {
+			Hidden  string `json:"-"`
+			private string
+			Shown   string `json:",omitempty"`
+		}{}), []string{"Shown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range jsonFields(tt.typ) {
+				got = append(got, f.name)
+				if f.name == "Label" && f.field.Name != "Caption" {
+					t.Errorf("Label = field %s, want the tagged Caption", f.field.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("jsonFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
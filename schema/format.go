@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// patterns caches compiled patterns, so a schema is not recompiled on
+// every validation.
+var patterns sync.Map // pattern -> *regexp.Regexp
+
+// compilePattern returns the compiled form of a pattern.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// formats checks the string formats the validator enforces. Other formats
+// are annotations only and accept any string.
+var formats = map[string]func(string) bool{
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+}
@@ -0,0 +1,396 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo returns Go source for package pkg declaring a struct named
+// typeName for object schema s, the reverse of Generate. It eases moving
+// servers defined spec-first to typed tool handlers:
+//
+//	var s schema.Schema
+//	if err := json.Unmarshal(doc, &s); err != nil { ... }
+//	src, err := schema.GenerateGo(&s, "tools", "SearchInput")
+//
+// Properties become fields with json and jsonschema tags, so Generate
+// produces an equivalent schema for the result. Nested objects become
+// struct types named after their field, $defs become types of their own,
+// and $refs to objects become pointers so recursive types compile. Schemas
+// Go cannot express, such as oneOf, become any. Tag values that would not
+// survive the jsonschema tag syntax, such as descriptions containing
+// commas, are dropped from the tag; descriptions are kept as comments.
+func GenerateGo(s *Schema, pkg, typeName string) ([]byte, error) {
+	if s == nil || s.Type != typeObject {
+		return nil, fmt.Errorf("root schema must be an object")
+	}
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(typeName) || !token.IsExported(typeName) {
+		return nil, fmt.Errorf("invalid type name %q", typeName)
+	}
+
+	g := &goGenerator{
+		root:    s,
+		names:   map[string]bool{typeName: true},
+		defs:    make(map[string]string),
+		imports: make(map[string]bool),
+	}
+	g.queue = append(g.queue, goType{name: typeName, schema: s})
+	defNames := make([]string, 0, len(s.Defs))
+	for name := range s.Defs {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		g.defs[name] = g.typeName(goName(name))
+		g.queue = append(g.queue, goType{name: g.defs[name], schema: s.Defs[name]})
+	}
+
+	var body bytes.Buffer
+	for i := 0; i < len(g.queue); i++ {
+		if err := g.writeType(&body, g.queue[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by schema.GenerateGo. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n", pkg)
+	if g.imports["time"] {
+		src.WriteString("\nimport \"time\"\n")
+	}
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// goGenerator holds the state of GenerateGo.
+type goGenerator struct {
+	root *Schema
+	// names holds the type names taken
+	names map[string]bool
+	// defs maps the names of $defs to their Go type names
+	defs    map[string]string
+	queue   []goType
+	imports map[string]bool
+}
+
+// goType is a type declaration to write.
+type goType struct {
+	name   string
+	schema *Schema
+}
+
+// typeName returns name, or name with a number appended if it is taken,
+// and marks the result as taken.
+func (g *goGenerator) typeName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// writeType writes the declaration of t: a struct for object schemas with
+// properties, and otherwise a defined type such as a map or slice.
+func (g *goGenerator) writeType(b *bytes.Buffer, t goType) error {
+	b.WriteString("\n")
+	writeComment(b, "", t.schema.Description)
+	if t.schema.Type != typeObject || len(t.schema.Properties) == 0 && t.schema != g.root {
+		typ, err := g.goTypeOf(t.schema, t.name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		fmt.Fprintf(b, "type %s %s\n", t.name, typ)
+		return nil
+	}
+
+	fmt.Fprintf(b, "type %s struct {\n", t.name)
+	if t.schema.AdditionalProperties.IsFalse() {
+		b.WriteString("\t_ struct{} `jsonschema:\"additionalProperties=false\"`\n")
+	}
+	props := make([]string, 0, len(t.schema.Properties))
+	for name := range t.schema.Properties {
+		props = append(props, name)
+	}
+	sort.Strings(props)
+	fields := make(map[string]bool)
+	for _, prop := range props {
+		if err := g.writeField(b, t, prop, fields); err != nil {
+			return err
+		}
+	}
+	b.WriteString("}\n")
+	return nil
+}
+
+// writeField writes the field for property prop of struct type t. fields
+// holds the field names taken.
+func (g *goGenerator) writeField(b *bytes.Buffer, t goType, prop string, fields map[string]bool) error {
+	s := t.schema.Properties[prop]
+	name := goName(prop)
+	unique := name
+	for i := 2; fields[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	fields[unique] = true
+
+	typ, err := g.goTypeOf(s, t.name+name)
+	if err != nil {
+		return fmt.Errorf("%s.%s: %w", t.name, prop, err)
+	}
+	if g.isStructRef(s) {
+		typ = "*" + typ
+	}
+
+	tag := fmt.Sprintf("json:%q", prop)
+	if opts := tagOptions(s, slices.Contains(t.schema.Required, prop), typ); opts != "" {
+		tag += fmt.Sprintf(" jsonschema:%q", opts)
+	}
+	writeComment(b, "\t", s.Description)
+	fmt.Fprintf(b, "\t%s %s `%s`\n", unique, typ, tag)
+	return nil
+}
+
+// goTypeOf returns the Go type for schema s. Nested object types are named
+// after hint.
+func (g *goGenerator) goTypeOf(s *Schema, hint string) (string, error) {
+	if s == nil || s.IsFalse() || len(s.OneOf) > 0 {
+		return "any", nil
+	}
+	if s.Ref != "" {
+		return g.refType(s.Ref)
+	}
+
+	var typ string
+	switch s.Type {
+	case typeString:
+		typ = "string"
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			typ = "time.Time"
+		}
+	case typeInteger:
+		typ = "int"
+	case typeNumber:
+		typ = "float64"
+	case typeBoolean:
+		typ = "bool"
+	case typeArray:
+		item, err := g.goTypeOf(s.Items, hint+"Item")
+		return "[]" + item, err
+	case typeObject:
+		return g.objectType(s, hint)
+	default:
+		return "any", nil
+	}
+	if s.Nullable {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// objectType returns the Go type for object schema s: a new struct type
+// named after hint if it lists properties, or else a map.
+func (g *goGenerator) objectType(s *Schema, hint string) (string, error) {
+	if len(s.Properties) > 0 {
+		name := g.typeName(hint)
+		g.queue = append(g.queue, goType{name: name, schema: s})
+		if s.Nullable {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+	if s.AdditionalProperties == nil || s.AdditionalProperties.IsFalse() {
+		return "map[string]any", nil
+	}
+	value, err := g.goTypeOf(s.AdditionalProperties, hint+"Value")
+	return "map[string]" + value, err
+}
+
+// refType returns the name of the type a $ref points to.
+func (g *goGenerator) refType(ref string) (string, error) {
+	if ref == "#" {
+		return g.queue[0].name, nil
+	}
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if name, ok := g.defs[strings.TrimPrefix(ref, prefix)]; ok && strings.HasPrefix(ref, prefix) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unresolved reference %q", ref)
+}
+
+// isStructRef reports whether s refers to a struct type, which fields hold
+// by pointer so that recursive types compile.
+func (g *goGenerator) isStructRef(s *Schema) bool {
+	if s == nil || s.Ref == "" {
+		return false
+	}
+	target := g.root
+	if s.Ref != "#" {
+		target = g.root.Defs[strings.TrimPrefix(strings.TrimPrefix(s.Ref, "#/$defs/"), "#/definitions/")]
+	}
+	return target != nil && target.Type == typeObject && len(target.Properties) > 0
+}
+
+// tagOptions returns the jsonschema tag of a field with schema s and Go
+// type typ. Constraints of array items are taken from the items, as the
+// tag applies them there.
+func tagOptions(s *Schema, required bool, typ string) string {
+	var opts []string
+	if required {
+		opts = append(opts, "required")
+	}
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{s.Nullable && s.Type != "", "nullable"},
+		{s.ReadOnly, "readOnly"},
+		{s.WriteOnly, "writeOnly"},
+		{s.Deprecated, "deprecated"},
+	}
+	for _, f := range flags {
+		if f.set {
+			opts = append(opts, f.name)
+		}
+	}
+	if tagSafe(s.Description) && s.Description != "" {
+		opts = append(opts, "description="+s.Description)
+	}
+
+	c := s
+	if s.Type == typeArray && s.Items != nil && s.Items.Ref == "" {
+		c = s.Items
+	}
+	opts = append(opts, constraintOptions(c, typ)...)
+	if v, ok := tagValue(s.Default); ok {
+		opts = append(opts, "default="+v)
+	}
+	for _, example := range s.Examples {
+		if v, ok := tagValue(example); ok {
+			opts = append(opts, "example="+v)
+		}
+	}
+	if c.Pattern != "" && !strings.Contains(c.Pattern, "`") {
+		// A pattern takes the rest of the tag, so it comes last
+		opts = append(opts, "pattern="+c.Pattern)
+	}
+	return strings.Join(opts, ",")
+}
+
+// constraintOptions returns the tag options for the format, enum and
+// numeric and length constraints of s.
+func constraintOptions(s *Schema, typ string) []string {
+	var opts []string
+	if s.Format != "" && tagSafe(s.Format) && !strings.HasSuffix(typ, "time.Time") {
+		opts = append(opts, "format="+s.Format)
+	}
+	if v, ok := tagValue(s.Enum); ok && len(s.Enum) > 0 {
+		opts = append(opts, "enum="+v)
+	}
+	numbers := []struct {
+		value *float64
+		name  string
+	}{
+		{s.Minimum, "minimum"},
+		{s.Maximum, "maximum"},
+		{s.ExclusiveMinimum, "exclusiveMinimum"},
+		{s.ExclusiveMaximum, "exclusiveMaximum"},
+		{s.MultipleOf, "multipleOf"},
+	}
+	for _, n := range numbers {
+		if n.value != nil {
+			opts = append(opts, n.name+"="+strconv.FormatFloat(*n.value, 'g', -1, 64))
+		}
+	}
+	if s.MinLength != nil {
+		opts = append(opts, "minLength="+strconv.Itoa(*s.MinLength))
+	}
+	if s.MaxLength != nil {
+		opts = append(opts, "maxLength="+strconv.Itoa(*s.MaxLength))
+	}
+	return opts
+}
+
+// tagValue formats v for a jsonschema tag, listing the values of a slice
+// with "|". It reports false if v is unset or cannot be written in a tag.
+func tagValue(v any) (string, bool) {
+	values, isList := v.([]any)
+	if !isList {
+		if v == nil {
+			return "", false
+		}
+		values = []any{v}
+	}
+	parts := make([]string, len(values))
+	for i, value := range values {
+		switch value := value.(type) {
+		case map[string]any, []any, nil:
+			return "", false
+		case float64:
+			parts[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			parts[i] = fmt.Sprint(value)
+		}
+		if !tagSafe(parts[i]) || strings.Contains(parts[i], "|") {
+			return "", false
+		}
+	}
+	return strings.Join(parts, "|"), true
+}
+
+// tagSafe reports whether s can be a jsonschema tag value: one without
+// the option separator or characters that end the struct tag.
+func tagSafe(s string) bool {
+	return !strings.ContainsAny(s, ",`\n")
+}
+
+// writeComment writes text as a Go comment, one line per line of text.
+func writeComment(b *bytes.Buffer, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+}
+
+// initialisms are the words goName writes in upper case.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true,
+}
+
+// goName converts a JSON name such as "user_id" or "created-at" to an
+// exported Go identifier such as UserID or CreatedAt.
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	result := b.String()
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		result = "Field" + result
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	doc := `{
+		"type": "object",
+		"description": "Search the catalog.",
+		"properties": {
+			"query": {"type": "string", "description": "Search terms", "minLength": 1},
+			"user_id": {"type": "string", "format": "uuid"},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 100, "default": 10},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["bug", "ui"]}},
+			"since": {"type": "string", "format": "date-time"},
+			"note": {"type": ["string", "null"], "deprecated": true},
+			"address": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"], "additionalProperties": false},
+			"owner": {"$ref": "#/$defs/person"},
+			"counts": {"type": "object", "additionalProperties": {"type": "integer"}},
+			"extra": {},
+			"code": {"type": "string", "pattern": "^[a-z]{2,3}$", "description": "ISO code, lower case"}
+		},
+		"required": ["query"],
+		"$defs": {
+			"person": {"type": "object", "properties": {
+				"name": {"type": "string"},
+				"manager": {"$ref": "#/$defs/person"},
+				"reports": {"type": "array", "items": {"$ref": "#/$defs/person"}}
+			}}
+		}
+	}`
+	want := "// Code generated by schema.GenerateGo. DO NOT EDIT.\n\n" +
+		"package tools\n\n" +
+		"import \"time\"\n\n" +
+		"// Search the catalog.\n" +
+		"type SearchInput struct {\n" +
+		"\tAddress SearchInputAddress `json:\"address\"`\n" +
+		"\t// ISO code, lower case\n" +
+		"\tCode   string         `json:\"code\" jsonschema:\"pattern=^[a-z]{2,3}$\"`\n" +
+		"\tCounts map[string]int `json:\"counts\"`\n" +
+		"\tExtra  any            `json:\"extra\"`\n" +
+		"\tLimit  int            `json:\"limit\" jsonschema:\"minimum=1,maximum=100,default=10\"`\n" +
+		"\tNote   *string        `json:\"note\" jsonschema:\"nullable,deprecated\"`\n" +
+		"\tOwner  *Person        `json:\"owner\"`\n" +
+		"\t// Search terms\n" +
+		"\tQuery  string    `json:\"query\" jsonschema:\"required,description=Search terms,minLength=1\"`\n" +
+		"\tSince  time.Time `json:\"since\"`\n" +
+		"\tTags   []string  `json:\"tags\" jsonschema:\"enum=bug|ui\"`\n" +
+		"\tUserID string    `json:\"user_id\" jsonschema:\"format=uuid\"`\n" +
+		"}\n\n" +
+		"type Person struct {\n" +
+		"\tManager *Person  `json:\"manager\"`\n" +
+		"\tName    string   `json:\"name\"`\n" +
+		"\tReports []Person `json:\"reports\"`\n" +
+		"}\n\n" +
+		"type SearchInputAddress struct {\n" +
+		"\t_    struct{} `jsonschema:\"additionalProperties=false\"`\n" +
+		"\tCity string   `json:\"city\" jsonschema:\"required\"`\n" +
+		"}\n"
+
+	var s Schema
+	if err := json.Unmarshal([]byte(doc), &s); err != nil {
+		t.Fatal(err)
+	}
+	src, err := GenerateGo(&s, "tools", "SearchInput")
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+	if string(src) != want {
+		t.Errorf("GenerateGo() =\n%s\nwant\n%s", src, want)
+	}
+}
+
+func TestGenerateGo_Errors(t *testing.T) {
+	object := &Schema{Type: "object", Properties: map[string]*Schema{"a": {Type: "string"}}}
+	tests := []struct {
+		name     string
+		schema   *Schema
+		pkg      string
+		typeName string
+		wantErr  string
+	}{
+		{"not an object", &Schema{Type: "string"}, "tools", "Input", "root schema must be an object"},
+		{"bad package", object, "my-tools", "Input", `invalid package name "my-tools"`},
+		{"unexported type", object, "tools", "input", `invalid type name "input"`},
+		{"unresolved ref", &Schema{Type: "object", Properties: map[string]*Schema{"a": {Ref: "#/$defs/missing"}}},
+			"tools", "Input", `Input.a: unresolved reference "#/$defs/missing"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateGo(tt.schema, tt.pkg, tt.typeName)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateGo() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"name":       "Name",
+		"user_id":    "UserID",
+		"created-at": "CreatedAt",
+		"api.url":    "APIURL",
+		"firstName":  "FirstName",
+		"2fa":        "Field2fa",
+		"$":          "Field",
+		"élan vital": "ÉlanVital",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// plainSchema has the fields of Schema without its JSON methods.
+type plainSchema Schema
+
+// MarshalJSON encodes the false schema as false and the type of a nullable
+// schema as [Type, "null"].
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal((*plainSchema)(s))
+	}
+	return json.Marshal(struct {
+		Type []string `json:"type"`
+		*plainSchema
+	}{[]string{s.Type, "null"}, (*plainSchema)(s)})
+}
+
+// UnmarshalJSON decodes a schema, including the boolean schemas true and
+// false and a type given as a list. A list containing "null" makes the
+// schema nullable; of its other types, only the first is kept. The
+// definitions of older drafts are decoded into Defs.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+
+	aux := struct {
+		Type        any                `json:"type"`
+		Definitions map[string]*Schema `json:"definitions"`
+		*plainSchema
+	}{plainSchema: (*plainSchema)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if s.Defs == nil {
+		s.Defs = aux.Definitions
+	}
+
+	switch t := aux.Type.(type) {
+	case nil:
+	case string:
+		s.Type = t
+	case []any:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("invalid type %v", v)
+			}
+			if name == "null" {
+				s.Nullable = true
+			} else if s.Type == "" {
+				s.Type = name
+			}
+		}
+	default:
+		return fmt.Errorf("invalid type %v", t)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		// want is the encoding of the decoded schema, if it differs
+		want string
+	}{
+		{"plain", `{"type":"string","minLength":1}`, ""},
+		{"nullable", `{"type":["integer","null"],"minimum":0}`, ""},
+		{"null first", `{"type":["null","string"]}`, `{"type":["string","null"]}`},
+		{"false schema", `{"type":"object","additionalProperties":false}`, ""},
+		{"true schema", `{"type":"object","additionalProperties":true}`, `{"type":"object","additionalProperties":{}}`},
+		{"typed additional properties", `{"type":"object","additionalProperties":{"type":"number"}}`, ""},
+		{"nested", `{"type":"object","properties":{"tags":{"type":["array","null"],"items":{"type":"string"}}}}`, ""},
+		{"definitions", `{"type":"object","definitions":{"id":{"type":"string"}}}`, `{"$defs":{"id":{"type":"string"}},"type":"object"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schema
+			if err := json.Unmarshal([]byte(tt.json), &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			data, err := json.Marshal(&s)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.json
+			}
+			if string(data) != want {
+				t.Errorf("round trip = %s, want %s", data, want)
+			}
+		})
+	}
+
+	var s Schema
+	if err := json.Unmarshal([]byte(`{"type":5}`), &s); err == nil {
+		t.Error("Unmarshal() accepted a numeric type")
+	}
+	if data, _ := json.Marshal(False()); string(data) != "false" {
+		t.Errorf("False() = %s", data)
+	}
+}
@@ -2,43 +2,209 @@
 package schema
 
 import (
+	"encoding"
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Schema represents a JSON Schema.
 type Schema struct {
+	// Dialect and ID are set on root schemas only; see WithDialect and
+	// WithID.
+	Dialect     Dialect            `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
 	Type        string             `json:"type,omitempty"`
 	Properties  map[string]*Schema `json:"properties,omitempty"`
 	Required    []string           `json:"required,omitempty"`
 	Description string             `json:"description,omitempty"`
 	Default     any                `json:"default,omitempty"`
+	Examples    []any              `json:"examples,omitempty"`
 	Enum        []any              `json:"enum,omitempty"`
 	Minimum     *float64           `json:"minimum,omitempty"`
 	Maximum     *float64           `json:"maximum,omitempty"`
 	Items       *Schema            `json:"items,omitempty"`
+
+	// PrefixItems describes the leading items of a tuple; Items then
+	// applies to the items after them.
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+	MinItems    *int      `json:"minItems,omitempty"`
+	MaxItems    *int      `json:"maxItems,omitempty"`
+
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Format    string `json:"format,omitempty"`
+
+	Const         any            `json:"const,omitempty"`
+	OneOf         []*Schema      `json:"oneOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+
+	// ReadOnly, WriteOnly and Deprecated annotate properties for clients;
+	// they do not affect validation.
+	ReadOnly   bool `json:"readOnly,omitempty"`
+	WriteOnly  bool `json:"writeOnly,omitempty"`
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// AdditionalProperties constrains the properties of an object that are
+	// not listed in Properties; False() forbids them.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+	// Nullable also allows null, encoding the type as [Type, "null"].
+	Nullable bool `json:"-"`
+
+	// never marks the false schema, which no value matches
+	never bool
+}
+
+// False returns the schema no value matches, encoded as false. Use it as
+// AdditionalProperties to forbid properties that are not listed.
+func False() *Schema {
+	return &Schema{never: true}
+}
+
+// IsFalse reports whether s is the false schema.
+func (s *Schema) IsFalse() bool {
+	return s != nil && s.never
+}
+
+// GenerateOption configures schema generation.
+type GenerateOption func(*generator)
+
+// NullablePointers marks the schemas of pointer fields as nullable, so
+// their type is [T, "null"]. The nullable tag does so for a single field.
+func NullablePointers() GenerateOption {
+	return func(g *generator) {
+		g.nullablePointers = true
+	}
+}
+
+// NoAdditionalProperties sets additionalProperties to false on the schema
+// of every struct, so objects with unknown properties fail validation. A
+// struct opts out with a blank field tagged
+// jsonschema:"additionalProperties=true".
+func NoAdditionalProperties() GenerateOption {
+	return func(g *generator) {
+		g.noAdditional = true
+	}
+}
+
+// Dialect is the URI of a JSON Schema dialect, emitted as $schema.
+type Dialect string
+
+// Draft202012 is JSON Schema draft 2020-12, the dialect MCP assumes for
+// schemas that do not declare one. Generated schemas use its keywords,
+// such as $defs and prefixItems.
+const Draft202012 Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// WithDialect declares the dialect of the root schema with $schema, for
+// hosts that validate tool schemas strictly against it.
+func WithDialect(d Dialect) GenerateOption {
+	return func(g *generator) {
+		g.dialect = d
+	}
+}
+
+// WithID sets the $id of the root schema.
+func WithID(id string) GenerateOption {
+	return func(g *generator) {
+		g.id = id
+	}
 }
 
 // Generate creates a JSON Schema from a Go value.
-func Generate(v any) (*Schema, error) {
+func Generate(v any, opts ...GenerateOption) (*Schema, error) {
 	t := reflect.TypeOf(v)
-	return generateFromType(t)
+	return GenerateFromType(t, opts...)
 }
 
 // GenerateFromType creates a JSON Schema from a reflect.Type.
-func GenerateFromType(t reflect.Type) (*Schema, error) {
-	return generateFromType(t)
+//
+// Recursive types are generated once: a struct type that refers to itself,
+// directly or through other types, is emitted in the $defs of the root
+// schema and referenced with $ref, or with "#" if it is the root type.
+func GenerateFromType(t reflect.Type, opts ...GenerateOption) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g := &generator{
+		root:      t,
+		visiting:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	schema, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+	schema.Dialect = g.dialect
+	schema.ID = g.id
+	return schema, nil
 }
 
-func generateFromType(t reflect.Type) (*Schema, error) {
+// generator holds the state of a schema generation.
+type generator struct {
+	root reflect.Type
+	// visiting holds the struct types being generated, so a type that
+	// refers to itself is detected instead of inlined forever
+	visiting  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	names     map[reflect.Type]string
+	defs      map[string]*Schema
+
+	nullablePointers bool
+	noAdditional     bool
+	dialect          Dialect
+	id               string
+}
+
+func (g *generator) generate(t reflect.Type) (*Schema, error) {
 	// Handle pointers
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
+	if reflect.PointerTo(t).Implements(providerType) {
+		if provided := reflect.New(t).Interface().(Provider).JSONSchema(); provided != nil {
+			return provided.Clone(), nil
+		}
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case durationType:
+		return &Schema{Type: "string", Description: durationDescription, Pattern: durationPattern}, nil
+	}
+	if isText(t) {
+		return &Schema{Type: "string", Format: textFormats[t.String()]}, nil
+	}
+
 	switch t.Kind() {
+	case reflect.Interface:
+		if u, ok := LookupUnion(t); ok {
+			return g.definition(t, func(t reflect.Type) (*Schema, error) {
+				return g.generateUnion(u)
+			})
+		}
+		return &Schema{}, nil
 	case reflect.Struct:
-		return generateStructSchema(t)
+		return g.generateStruct(t)
 	case reflect.String:
 		return &Schema{Type: "string"}, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -49,50 +215,174 @@ func generateFromType(t reflect.Type) (*Schema, error) {
 	case reflect.Bool:
 		return &Schema{Type: "boolean"}, nil
 	case reflect.Slice, reflect.Array:
-		return generateArraySchema(t)
+		return g.generateArray(t)
 	case reflect.Map:
-		return &Schema{Type: "object"}, nil
+		return g.generateMap(t)
 	default:
 		return &Schema{}, nil
 	}
 }
 
-func generateStructSchema(t reflect.Type) (*Schema, error) {
-	schema := &Schema{
-		Type:       "object",
-		Properties: make(map[string]*Schema),
+// generateStruct returns the schema of struct type t, or a reference to it
+// if t is recursive.
+func (g *generator) generateStruct(t reflect.Type) (*Schema, error) {
+	return g.definition(t, g.generateStructSchema)
+}
+
+// definition returns the schema of type t built by build, or a reference
+// to it if t is recursive.
+func (g *generator) definition(t reflect.Type, build func(reflect.Type) (*Schema, error)) (*Schema, error) {
+	if g.visiting[t] {
+		g.recursive[t] = true
+		return g.ref(t), nil
+	}
+	if name, ok := g.names[t]; ok && g.defs[name] != nil {
+		return g.ref(t), nil
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	g.visiting[t] = true
+	schema, err := build(t)
+	delete(g.visiting, t)
+	if err != nil {
+		return nil, err
+	}
+	if !g.recursive[t] || t == g.root {
+		return schema, nil
+	}
 
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
+	if g.defs == nil {
+		g.defs = make(map[string]*Schema)
+	}
+	g.defs[g.names[t]] = schema
+	return g.ref(t), nil
+}
 
-		// Get JSON field name
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "-" {
-			continue
+// ref returns a reference to the schema of recursive type t.
+func (g *generator) ref(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+	return &Schema{Ref: "#/$defs/" + g.defName(t)}
+}
+
+// defName returns the name of type t in $defs, unique among the types of
+// the generation.
+func (g *generator) defName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
 		}
+		return '_'
+	}, t.Name())
+	name := base
+	for i := 2; g.nameTaken(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	return name
+}
 
-		fieldName := field.Name
-		if jsonTag != "" {
-			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" {
-				fieldName = parts[0]
-			}
+func (g *generator) nameTaken(name string) bool {
+	for _, n := range g.names {
+		if n == name {
+			return true
 		}
+	}
+	return false
+}
+
+// Provider is implemented by types that declare their own schema, such as
+// types with custom JSON marshaling whose reflected schema would be wrong:
+//
+//	// Money marshals as a decimal string such as "12.50".
+//	func (Money) JSONSchema() *schema.Schema {
+//	    return &schema.Schema{Type: "string", Pattern: `^-?[0-9]+\.[0-9]{2}$`}
+//	}
+//
+// The generator calls JSONSchema on the zero value of the type, with a
+// pointer receiver if the method has one, and uses a copy of the result.
+// Struct tags on a field of the type still apply. A nil result falls back
+// to the reflected schema.
+type Provider interface {
+	JSONSchema() *Schema
+}
+
+var (
+	providerType = reflect.TypeOf((*Provider)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// textFormats maps the text types with a standard string format to it.
+var textFormats = map[string]string{
+	"uuid.UUID": "uuid",
+}
+
+// isText reports whether encoding/json represents values of type t as
+// strings through encoding.TextMarshaler or encoding.TextUnmarshaler, as
+// for netip.Addr, uuid.UUID or enums with names. Types with their own JSON
+// methods are not text types.
+func isText(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(jsonMarshalerType) || ptr.Implements(jsonUnmarshalerType) {
+		return false
+	}
+	return ptr.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+// durationPattern is the pattern of time.Duration fields, which are
+// represented as strings accepted by time.ParseDuration, such as "1m30s".
+const durationPattern = `^[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+$|^[-+]?0$`
+
+// durationDescription describes time.Duration fields that have no
+// description tag.
+const durationDescription = `Duration such as "30s", "1m30s" or "2h"`
+
+func (g *generator) generateStructSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	if g.noAdditional {
+		schema.AdditionalProperties = False()
+	}
+	if err := parseStructTag(t, schema); err != nil {
+		return nil, err
+	}
+
+	for _, f := range jsonFields(t) {
+		field, fieldName := f.field, f.name
 
 		// Generate field schema
-		fieldSchema, err := generateFromType(field.Type)
+		fieldSchema, err := g.generate(field.Type)
 		if err != nil {
 			return nil, err
 		}
 
+		if g.nullablePointers && field.Type.Kind() == reflect.Ptr {
+			fieldSchema.Nullable = true
+		}
+
 		// Parse jsonschema tag
-		parseJSONSchemaTag(field.Tag.Get("jsonschema"), fieldSchema, &schema.Required, fieldName)
+		if err := parseJSONSchemaTag(field.Tag.Get("jsonschema"), fieldSchema, &schema.Required, fieldName); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if fieldSchema.Type == "" {
+			// References and unions carry no type to add null to
+			fieldSchema.Nullable = false
+		}
 
 		schema.Properties[fieldName] = fieldSchema
 	}
@@ -100,37 +390,319 @@ func generateStructSchema(t reflect.Type) (*Schema, error) {
 	return schema, nil
 }
 
-func generateArraySchema(t reflect.Type) (*Schema, error) {
-	itemSchema, err := generateFromType(t.Elem())
+func (g *generator) generateArray(t reflect.Type) (*Schema, error) {
+	itemSchema, err := g.generate(t.Elem())
 	if err != nil {
 		return nil, err
 	}
 
-	return &Schema{
+	schema := &Schema{
 		Type:  "array",
 		Items: itemSchema,
-	}, nil
+	}
+	if t.Kind() == reflect.Array {
+		minItems, maxItems := t.Len(), t.Len()
+		schema.MinItems, schema.MaxItems = &minItems, &maxItems
+	}
+	return schema, nil
 }
 
-func parseJSONSchemaTag(tag string, schema *Schema, required *[]string, fieldName string) {
+// generateMap returns an object schema whose additionalProperties is the
+// schema of the map values. Maps of interface values other than unions
+// accept any values, so they leave additionalProperties unset.
+func (g *generator) generateMap(t reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: "object"}
+	if t.Elem().Kind() == reflect.Interface {
+		if _, ok := LookupUnion(t.Elem()); !ok {
+			return schema, nil
+		}
+	}
+	valueSchema, err := g.generate(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	schema.AdditionalProperties = valueSchema
+	return schema, nil
+}
+
+func parseJSONSchemaTag(tag string, schema *Schema, required *[]string, fieldName string) error {
 	if tag == "" {
-		return
+		return nil
 	}
 
 	parts := strings.Split(tag, ",")
-	for _, part := range parts {
+	for i, part := range parts {
 		part = strings.TrimSpace(part)
 
 		if part == "required" {
 			*required = append(*required, fieldName)
 			continue
 		}
+		if annotation := tagFlag(schema, part); annotation != nil {
+			*annotation = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		if key == "pattern" {
+			// A pattern may contain commas, so it takes the rest of the tag
+			pattern := strings.TrimPrefix(strings.TrimLeft(strings.Join(parts[i:], ","), " "), "pattern=")
+			if _, err := compilePattern(pattern); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			itemsOf(schema).Pattern = pattern
+			return nil
+		}
+		if err := applyTagOption(schema, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		if strings.HasPrefix(part, "description=") {
-			schema.Description = strings.TrimPrefix(part, "description=")
+// tagFlag returns the field of schema set by a flag of a jsonschema tag,
+// or nil if part is not one.
+func tagFlag(schema *Schema, part string) *bool {
+	switch part {
+	case "nullable":
+		return &schema.Nullable
+	case "readOnly":
+		return &schema.ReadOnly
+	case "writeOnly":
+		return &schema.WriteOnly
+	case "deprecated":
+		return &schema.Deprecated
+	}
+	return nil
+}
+
+// parseStructTag applies the struct-level options of struct type t, given
+// as the jsonschema tag of a blank field:
+//
+//	_ struct{} `jsonschema:"additionalProperties=false"`
+func parseStructTag(t reflect.Type, schema *Schema) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name != "_" {
 			continue
 		}
+		for _, part := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if key != "additionalProperties" {
+				continue
+			}
+			allowed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid additionalProperties %q", value)
+			}
+			schema.AdditionalProperties = nil
+			if !allowed {
+				schema.AdditionalProperties = False()
+			}
+		}
+	}
+	return nil
+}
+
+// applyTagOption applies a key=value option of a jsonschema tag to schema.
+// Unknown keys are ignored.
+func applyTagOption(schema *Schema, key, value string) error {
+	switch key {
+	case "description":
+		schema.Description = value
+	case "format":
+		itemsOf(schema).Format = value
+	case "default":
+		v, err := parseTagValue(value, schema)
+		if err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+		schema.Default = v
+	case "example":
+		v, err := parseTagValue(value, schema)
+		if err != nil {
+			return fmt.Errorf("invalid example: %w", err)
+		}
+		schema.Examples = append(schema.Examples, v)
+	case "enum":
+		target := itemsOf(schema)
+		values, err := parseEnum(value, target.Type)
+		if err != nil {
+			return err
+		}
+		target.Enum = values
+	default:
+		if target := numericConstraint(itemsOf(schema), key); target != nil {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", key, value)
+			}
+			if key == "multipleOf" && n <= 0 {
+				return fmt.Errorf("multipleOf must be greater than 0, got %v", n)
+			}
+			*target = &n
+		} else if target := lengthConstraint(itemsOf(schema), key); target != nil {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = &n
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of s, so it can be modified without affecting
+// the original.
+func (s *Schema) Clone() *Schema {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.Properties = cloneSchemas(s.Properties)
+	c.Defs = cloneSchemas(s.Defs)
+	c.Items = s.Items.Clone()
+	if s.PrefixItems != nil {
+		c.PrefixItems = make([]*Schema, len(s.PrefixItems))
+		for i, item := range s.PrefixItems {
+			c.PrefixItems[i] = item.Clone()
+		}
+	}
+	c.MinItems = clonePtr(s.MinItems)
+	c.MaxItems = clonePtr(s.MaxItems)
+	c.Required = slices.Clone(s.Required)
+	c.Enum = slices.Clone(s.Enum)
+	c.Examples = slices.Clone(s.Examples)
+	c.Minimum = clonePtr(s.Minimum)
+	c.Maximum = clonePtr(s.Maximum)
+	c.ExclusiveMinimum = clonePtr(s.ExclusiveMinimum)
+	c.ExclusiveMaximum = clonePtr(s.ExclusiveMaximum)
+	c.MultipleOf = clonePtr(s.MultipleOf)
+	c.MinLength = clonePtr(s.MinLength)
+	c.MaxLength = clonePtr(s.MaxLength)
+	if s.OneOf != nil {
+		c.OneOf = make([]*Schema, len(s.OneOf))
+		for i, variant := range s.OneOf {
+			c.OneOf[i] = variant.Clone()
+		}
+	}
+	c.Discriminator = clonePtr(s.Discriminator)
+	c.AdditionalProperties = s.AdditionalProperties.Clone()
+	return &c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneSchemas(m map[string]*Schema) map[string]*Schema {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]*Schema, len(m))
+	for name, s := range m {
+		c[name] = s.Clone()
+	}
+	return c
+}
+
+// itemsOf returns the item schema of an array schema, or schema itself.
+// Value constraints on a slice field constrain its items.
+func itemsOf(schema *Schema) *Schema {
+	if schema.Type == typeArray && schema.Items != nil {
+		return schema.Items
+	}
+	return schema
+}
+
+// numericConstraint returns the field of schema set by a numeric
+// constraint tag key, or nil if key is not one.
+func numericConstraint(schema *Schema, key string) **float64 {
+	switch key {
+	case "minimum":
+		return &schema.Minimum
+	case "maximum":
+		return &schema.Maximum
+	case "exclusiveMinimum":
+		return &schema.ExclusiveMinimum
+	case "exclusiveMaximum":
+		return &schema.ExclusiveMaximum
+	case "multipleOf":
+		return &schema.MultipleOf
+	}
+	return nil
+}
+
+// lengthConstraint returns the field of schema set by a string length
+// tag key, or nil if key is not one.
+func lengthConstraint(schema *Schema, key string) **int {
+	switch key {
+	case "minLength":
+		return &schema.MinLength
+	case "maxLength":
+		return &schema.MaxLength
+	}
+	return nil
+}
+
+// parseEnum parses the "|"-separated values of an enum tag as values of
+// the given schema type.
+func parseEnum(list, typ string) ([]any, error) {
+	parts := strings.Split(list, "|")
+	values := make([]any, 0, len(parts))
+	for _, part := range parts {
+		value, err := parseScalar(part, typ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s enum value %q", typ, part)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// parseTagValue parses the value of a default or example tag as a value of
+// schema. The value of a slice field lists its items separated by "|".
+func parseTagValue(s string, schema *Schema) (any, error) {
+	if schema.Type != typeArray || schema.Items == nil {
+		value, err := parseScalar(s, schema.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", s, schema.Type)
+		}
+		return value, nil
+	}
+	values := []any{}
+	if s == "" {
+		return values, nil
+	}
+	for _, part := range strings.Split(s, "|") {
+		value, err := parseScalar(part, schema.Items.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", part, schema.Items.Type)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
 
-		// Add more tag parsing as needed (minimum, maximum, enum, etc.)
+// parseScalar parses s as a value of the given schema type. Strings and
+// untyped values are kept as is.
+func parseScalar(s, typ string) (any, error) {
+	switch typ {
+	case typeInteger:
+		return strconv.ParseInt(s, 10, 64)
+	case typeNumber:
+		return strconv.ParseFloat(s, 64)
+	case typeBoolean:
+		return strconv.ParseBool(s)
+	case typeObject, typeArray:
+		return nil, fmt.Errorf("cannot parse %s from a tag", typ)
 	}
+	return s, nil
 }
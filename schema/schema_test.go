@@ -2,7 +2,12 @@ package schema
 
 import (
 	"encoding/json"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerate(t *testing.T) {
@@ -178,6 +183,694 @@ func TestGenerate(t *testing.T) {
 			t.Errorf("value.Type = %q, want %q", valueProp.Type, "string")
 		}
 	})
+
+	t.Run("handles enum", func(t *testing.T) {
+		type Input struct {
+			Operation string   `json:"operation" jsonschema:"required,enum=add|subtract|multiply|divide"`
+			Level     int      `json:"level" jsonschema:"enum=1|2|3"`
+			Ratio     float64  `json:"ratio" jsonschema:"enum=0.5|1.5"`
+			Tags      []string `json:"tags" jsonschema:"enum=red|green"`
+		}
+
+		schema, err := Generate(Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			name string
+			got  []any
+			want []any
+		}{
+			{"string", schema.Properties["operation"].Enum, []any{"add", "subtract", "multiply", "divide"}},
+			{"integer", schema.Properties["level"].Enum, []any{int64(1), int64(2), int64(3)}},
+			{"number", schema.Properties["ratio"].Enum, []any{0.5, 1.5}},
+			{"slice items", schema.Properties["tags"].Items.Enum, []any{"red", "green"}},
+		}
+		for _, tt := range tests {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("%s enum = %#v, want %#v", tt.name, tt.got, tt.want)
+			}
+		}
+		if len(schema.Required) != 1 || schema.Required[0] != "operation" {
+			t.Errorf("Required = %v, want [operation]", schema.Required)
+		}
+
+		data, _ := json.Marshal(schema.Properties["level"])
+		if string(data) != `{"type":"integer","enum":[1,2,3]}` {
+			t.Errorf("level schema = %s", data)
+		}
+	})
+
+	t.Run("rejects enum values of the wrong type", func(t *testing.T) {
+		type Input struct {
+			Level int `json:"level" jsonschema:"enum=low|high"`
+		}
+
+		_, err := Generate(Input{})
+		if err == nil || !strings.Contains(err.Error(), `field Level: invalid integer enum value "low"`) {
+			t.Errorf("error = %v, want invalid integer enum value", err)
+		}
+	})
+}
+
+func TestGenerate_NumericConstraints(t *testing.T) {
+	type Input struct {
+		Limit  int       `json:"limit" jsonschema:"minimum=1,maximum=100"`
+		Ratio  float64   `json:"ratio" jsonschema:"exclusiveMinimum=0,exclusiveMaximum=1"`
+		Step   float64   `json:"step" jsonschema:"multipleOf=0.25"`
+		Scores []float64 `json:"scores" jsonschema:"minimum=0"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"limit", `{"type":"integer","minimum":1,"maximum":100}`},
+		{"ratio", `{"type":"number","exclusiveMinimum":0,"exclusiveMaximum":1}`},
+		{"step", `{"type":"number","multipleOf":0.25}`},
+		{"scores", `{"type":"array","items":{"type":"number","minimum":0}}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+}
+
+func TestGenerate_InvalidNumericConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   any
+		wantErr string
+	}{
+		{"not a number", struct {
+			Limit int `jsonschema:"maximum=ten"`
+		}{}, `field Limit: invalid maximum "ten"`},
+		{"zero multipleOf", struct {
+			Step float64 `jsonschema:"multipleOf=0"`
+		}{}, "field Step: multipleOf must be greater than 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate_StringConstraints(t *testing.T) {
+	type Input struct {
+		Code  string   `json:"code" jsonschema:"minLength=2,maxLength=8"`
+		Email string   `json:"email" jsonschema:"required,format=email"`
+		Slug  string   `json:"slug" jsonschema:"description=URL slug,pattern=^[a-z]{1,3}(-[a-z]+)*$"`
+		IDs   []string `json:"ids" jsonschema:"format=uuid"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"code", `{"type":"string","minLength":2,"maxLength":8}`},
+		{"email", `{"type":"string","format":"email"}`},
+		{"slug", `{"type":"string","description":"URL slug","pattern":"^[a-z]{1,3}(-[a-z]+)*$"}`},
+		{"ids", `{"type":"array","items":{"type":"string","format":"uuid"}}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+}
+
+func TestGenerate_InvalidStringConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   any
+		wantErr string
+	}{
+		{"negative length", struct {
+			Code string `jsonschema:"minLength=-1"`
+		}{}, `field Code: invalid minLength "-1"`},
+		{"bad pattern", struct {
+			Code string `jsonschema:"pattern=[a-"`
+		}{}, `field Code: invalid pattern "[a-"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate_DefaultsAndExamples(t *testing.T) {
+	type Input struct {
+		Limit  int      `json:"limit" jsonschema:"default=10,example=25,example=50"`
+		Ratio  float64  `json:"ratio" jsonschema:"default=0.5"`
+		Strict bool     `json:"strict" jsonschema:"default=false"`
+		Query  string   `json:"query" jsonschema:"example=status:open"`
+		Tags   []string `json:"tags" jsonschema:"default=,example=bug|ui"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"limit", `{"type":"integer","default":10,"examples":[25,50]}`},
+		{"ratio", `{"type":"number","default":0.5}`},
+		{"strict", `{"type":"boolean","default":false}`},
+		{"query", `{"type":"string","examples":["status:open"]}`},
+		{"tags", `{"type":"array","default":[],"examples":[["bug","ui"]],"items":{"type":"string"}}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+
+	_, err = Generate(struct {
+		Limit int `jsonschema:"default=ten"`
+	}{})
+	if err == nil || err.Error() != `field Limit: invalid default: "ten" is not a valid integer` {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestGenerate_Annotations(t *testing.T) {
+	type Input struct {
+		ID       string  `json:"id" jsonschema:"readOnly,description=Assigned by the server"`
+		Password string  `json:"password" jsonschema:"required,writeOnly"`
+		Fax      *string `json:"fax" jsonschema:"deprecated,nullable"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"id", `{"type":"string","description":"Assigned by the server","readOnly":true}`},
+		{"password", `{"type":"string","writeOnly":true}`},
+		{"fax", `{"type":["string","null"],"deprecated":true}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"password"}) {
+		t.Errorf("required = %v", schema.Required)
+	}
+
+	// Annotations do not restrict values
+	if err := schema.Validate(json.RawMessage(`{"id": "c1", "password": "x", "fax": null}`)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+// priority is an enum encoded as its name.
+type priority int
+
+func (p priority) MarshalText() ([]byte, error) { return []byte(strconv.Itoa(int(p))), nil }
+
+// version has both JSON and text methods; encoding/json uses the former.
+type version struct{ Major, Minor int }
+
+func (v version) MarshalText() ([]byte, error)  { return []byte("v1"), nil }
+func (v version) MarshalJSON() ([]byte, error)  { return []byte(`{"Major":1,"Minor":0}`), nil }
+func (v *version) UnmarshalText(b []byte) error { return nil }
+
+func TestGenerate_TextTypes(t *testing.T) {
+	type Input struct {
+		Addr     netip.Addr         `json:"addr" jsonschema:"description=Host address"`
+		Prefix   *netip.Prefix      `json:"prefix"`
+		Priority priority           `json:"priority" jsonschema:"enum=low|high"`
+		Peers    []netip.AddrPort   `json:"peers"`
+		Version  version            `json:"version"`
+		Hosts    map[netip.Addr]int `json:"hosts"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"addr", `{"type":"string","description":"Host address"}`},
+		{"prefix", `{"type":"string"}`},
+		{"priority", `{"type":"string","enum":["low","high"]}`},
+		{"peers", `{"type":"array","items":{"type":"string"}}`},
+		{"version", `{"type":"object","properties":{"Major":{"type":"integer"},"Minor":{"type":"integer"}}}`},
+		{"hosts", `{"type":"object","additionalProperties":{"type":"integer"}}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+}
+
+func TestGenerate_Dialect(t *testing.T) {
+	type Input struct {
+		Point  [2]float64 `json:"point"`
+		Parent *treeNode  `json:"parent"`
+	}
+
+	schema, err := Generate(Input{}, WithDialect(Draft202012), WithID("https://example.com/schemas/locate"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(schema)
+	prefix := `{"$schema":"https://json-schema.org/draft/2020-12/schema","$id":"https://example.com/schemas/locate",` +
+		`"$defs":{"treeNode":{"type":"object",`
+	if !strings.HasPrefix(string(data), prefix) {
+		t.Errorf("schema = %s, want prefix %s", data, prefix)
+	}
+	if def := schema.Defs["treeNode"]; def.Dialect != "" || def.ID != "" {
+		t.Errorf("$defs entry has $schema %q and $id %q, want them on the root only", def.Dialect, def.ID)
+	}
+	point, _ := json.Marshal(schema.Properties["point"])
+	if want := `{"type":"array","items":{"type":"number"},"minItems":2,"maxItems":2}`; string(point) != want {
+		t.Errorf("point schema = %s, want %s", point, want)
+	}
+
+	plain, _ := Generate(Input{})
+	if plain.Dialect != "" || plain.ID != "" {
+		t.Errorf("default schema has $schema %q and $id %q", plain.Dialect, plain.ID)
+	}
+}
+
+func TestValidate_Tuples(t *testing.T) {
+	two := 2
+	tuple := &Schema{
+		Type:        "array",
+		PrefixItems: []*Schema{{Type: "number"}, {Type: "string"}},
+		Items:       False(),
+		MinItems:    &two,
+	}
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`[1.5, "a"]`, ""},
+		{`[1.5, 2]`, "[1]: expected string, got float64"},
+		{`[1.5, "a", true]`, "[2]: value is not allowed"},
+		{`[1.5]`, "item count 1 is less than minimum 2"},
+	}
+	for _, tt := range tests {
+		err := tuple.Validate(json.RawMessage(tt.input))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) error = %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
+
+	bounded := &Schema{Type: "array", Items: &Schema{Type: "integer"}, MaxItems: &two}
+	if err := bounded.Validate(json.RawMessage(`[1, 2, 3]`)); err == nil || err.Error() != "item count 3 is greater than maximum 2" {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestGenerate_Time(t *testing.T) {
+	type Input struct {
+		Since   time.Time       `json:"since"`
+		Until   *time.Time      `json:"until"`
+		Timeout time.Duration   `json:"timeout"`
+		Backoff time.Duration   `json:"backoff" jsonschema:"description=Delay between retries"`
+		Steps   []time.Duration `json:"steps"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"since", "until"} {
+		if got := schema.Properties[name]; got.Type != "string" || got.Format != "date-time" || got.Properties != nil {
+			t.Errorf("%s schema = %+v, want date-time string", name, got)
+		}
+	}
+	if got := schema.Properties["timeout"]; got.Type != "string" || got.Pattern == "" || got.Description == "" {
+		t.Errorf("timeout schema = %+v, want described duration string", got)
+	}
+	if got := schema.Properties["backoff"].Description; got != "Delay between retries" {
+		t.Errorf("backoff description = %q, want tag description", got)
+	}
+
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"1m30s", true},
+		{"1.5h", true},
+		{"-250ms", true},
+		{"0", true},
+		{"s", false},
+		{"10", false},
+		{"1 minute", false},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(map[string]any{"timeout": tt.value, "steps": []string{tt.value}})
+		err := schema.Validate(data)
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid %v", tt.value, err, tt.valid)
+		}
+	}
+}
+
+type treeNode struct {
+	Name     string      `json:"name" jsonschema:"required"`
+	Children []*treeNode `json:"children"`
+}
+
+type comment struct {
+	Text    string     `json:"text"`
+	Replies []comment  `json:"replies"`
+	Author  *commenter `json:"author"`
+}
+
+type commenter struct {
+	Name   string   `json:"name"`
+	Pinned *comment `json:"pinned"`
+}
+
+type thread struct {
+	Title  string    `json:"title"`
+	First  comment   `json:"first"`
+	Latest *comment  `json:"latest" jsonschema:"description=Most recent comment"`
+	Tree   *treeNode `json:"tree"`
+}
+
+func TestGenerate_Recursive(t *testing.T) {
+	t.Run("root type refers to itself", func(t *testing.T) {
+		schema, err := Generate(treeNode{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := json.Marshal(schema)
+		want := `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#"}},"name":{"type":"string"}},"required":["name"]}`
+		if string(data) != want {
+			t.Errorf("schema = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("recursive types are emitted in $defs once", func(t *testing.T) {
+		schema, err := Generate(&thread{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := schema.Properties["first"].Ref; got != "#/$defs/comment" {
+			t.Errorf("first $ref = %q", got)
+		}
+		latest := schema.Properties["latest"]
+		if latest.Ref != "#/$defs/comment" || latest.Description != "Most recent comment" {
+			t.Errorf("latest = %+v", latest)
+		}
+		if got := schema.Properties["tree"].Ref; got != "#/$defs/treeNode" {
+			t.Errorf("tree $ref = %q", got)
+		}
+		if len(schema.Defs) != 2 {
+			t.Fatalf("$defs = %v, want comment and treeNode", schema.Defs)
+		}
+		comment := schema.Defs["comment"]
+		if got := comment.Properties["replies"].Items.Ref; got != "#/$defs/comment" {
+			t.Errorf("replies items $ref = %q", got)
+		}
+		// commenter only recurses through comment, so it stays inline
+		if got := comment.Properties["author"].Properties["pinned"].Ref; got != "#/$defs/comment" {
+			t.Errorf("pinned $ref = %q", got)
+		}
+	})
+
+	t.Run("non-recursive types stay inline", func(t *testing.T) {
+		schema, err := Generate(struct{ Tags []string }{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema.Defs != nil {
+			t.Errorf("$defs = %v, want none", schema.Defs)
+		}
+	})
+}
+
+// money marshals as a decimal string, so its reflected schema is wrong.
+type money int64
+
+var moneySchema = &Schema{Type: "string", Pattern: `^-?[0-9]+\.[0-9]{2}$`}
+
+func (money) JSONSchema() *Schema { return moneySchema }
+
+// set marshals as an array of its keys.
+type set map[string]struct{}
+
+func (*set) JSONSchema() *Schema {
+	return &Schema{Type: "array", Items: &Schema{Type: "string"}}
+}
+
+// fallback declares no schema of its own.
+type fallback struct {
+	Name string `json:"name"`
+}
+
+func (fallback) JSONSchema() *Schema { return nil }
+
+func TestGenerate_Provider(t *testing.T) {
+	type Input struct {
+		Price    money    `json:"price" jsonschema:"required,description=Unit price"`
+		Discount *money   `json:"discount"`
+		Tags     set      `json:"tags" jsonschema:"maxLength=20"`
+		Prices   []money  `json:"prices"`
+		Other    fallback `json:"other"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"price", `{"type":"string","description":"Unit price","pattern":"^-?[0-9]+\\.[0-9]{2}$"}`},
+		{"discount", `{"type":"string","pattern":"^-?[0-9]+\\.[0-9]{2}$"}`},
+		{"tags", `{"type":"array","items":{"type":"string","maxLength":20}}`},
+		{"prices", `{"type":"array","items":{"type":"string","pattern":"^-?[0-9]+\\.[0-9]{2}$"}}`},
+		{"other", `{"type":"object","properties":{"name":{"type":"string"}}}`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(schema.Properties[tt.field])
+		if string(data) != tt.want {
+			t.Errorf("%s schema = %s, want %s", tt.field, data, tt.want)
+		}
+	}
+	if moneySchema.Description != "" {
+		t.Errorf("provided schema modified: %+v", moneySchema)
+	}
+
+	root, err := Generate(money(0))
+	if err != nil || root.Type != "string" {
+		t.Errorf("Generate(money) = %+v, %v", root, err)
+	}
+}
+
+func TestSchema_Clone(t *testing.T) {
+	minimum := 1.0
+	original := &Schema{
+		Type:       "object",
+		Required:   []string{"a"},
+		Properties: map[string]*Schema{"a": {Type: "integer", Minimum: &minimum, Enum: []any{1, 2}}},
+		Items:      &Schema{Type: "string"},
+	}
+	c := original.Clone()
+	c.Required[0] = "b"
+	c.Properties["a"].Enum[0] = 3
+	*c.Properties["a"].Minimum = 5
+	c.Items.Type = "number"
+	c.Properties["c"] = &Schema{}
+
+	if original.Required[0] != "a" || original.Properties["a"].Enum[0] != 1 || minimum != 1 ||
+		original.Items.Type != "string" || len(original.Properties) != 1 {
+		t.Errorf("original modified through clone: %+v", original)
+	}
+	if (*Schema)(nil).Clone() != nil {
+		t.Error("Clone() of nil schema is not nil")
+	}
+}
+
+func TestGenerate_NullableAndAdditionalProperties(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Loose struct {
+		_     struct{} `jsonschema:"additionalProperties=true"`
+		Extra string   `json:"extra"`
+	}
+	type Input struct {
+		Name    string   `json:"name"`
+		Note    *string  `json:"note"`
+		Address *Address `json:"address"`
+		Tags    []string `json:"tags" jsonschema:"nullable"`
+		Loose   Loose    `json:"loose"`
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		schema, err := Generate(Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := json.Marshal(schema.Properties["note"])
+		if string(data) != `{"type":"string"}` {
+			t.Errorf("note schema = %s, want non-nullable", data)
+		}
+		data, _ = json.Marshal(schema.Properties["tags"])
+		if string(data) != `{"type":["array","null"],"items":{"type":"string"}}` {
+			t.Errorf("tags schema = %s, want nullable from tag", data)
+		}
+		if schema.AdditionalProperties != nil {
+			t.Errorf("additionalProperties = %+v, want unset", schema.AdditionalProperties)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		schema, err := Generate(Input{}, NullablePointers(), NoAdditionalProperties())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := json.Marshal(schema.Properties["note"])
+		if string(data) != `{"type":["string","null"]}` {
+			t.Errorf("note schema = %s, want nullable", data)
+		}
+		data, _ = json.Marshal(schema.Properties["address"])
+		if string(data) != `{"type":["object","null"],"properties":{"city":{"type":"string"}},"additionalProperties":false}` {
+			t.Errorf("address schema = %s", data)
+		}
+		if !schema.AdditionalProperties.IsFalse() {
+			t.Errorf("root additionalProperties = %+v, want false", schema.AdditionalProperties)
+		}
+		if got := schema.Properties["loose"].AdditionalProperties; got != nil {
+			t.Errorf("loose additionalProperties = %+v, want the struct tag to allow them", got)
+		}
+		if _, ok := schema.Properties["_"]; ok {
+			t.Error("blank field generated a property")
+		}
+	})
+
+	t.Run("struct tag", func(t *testing.T) {
+		type Strict struct {
+			_    struct{} `jsonschema:"additionalProperties=false"`
+			Name string   `json:"name"`
+		}
+		schema, err := Generate(Strict{})
+		if err != nil || !schema.AdditionalProperties.IsFalse() {
+			t.Errorf("Generate() = %+v, %v, want additionalProperties false", schema, err)
+		}
+		_, err = Generate(struct {
+			_ struct{} `jsonschema:"additionalProperties=never"`
+		}{})
+		if err == nil || err.Error() != `invalid additionalProperties "never"` {
+			t.Errorf("error = %v", err)
+		}
+	})
+}
+
+// registry maps names to registries, a recursive map.
+type registry struct {
+	Entries map[string]registry `json:"entries"`
+}
+
+func TestGenerate_Maps(t *testing.T) {
+	type Limit struct {
+		Max int `json:"max" jsonschema:"required,minimum=1"`
+	}
+	type Input struct {
+		Counts   map[string]int      `json:"counts"`
+		Limits   map[string]*Limit   `json:"limits"`
+		Groups   map[string][]int    `json:"groups"`
+		Metadata map[string]any      `json:"metadata"`
+		Shapes   map[string]shape    `json:"shapes"`
+		Nested   map[string]registry `json:"nested"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		property string
+		want     string
+	}{
+		{"counts", `{"type":"object","additionalProperties":{"type":"integer"}}`},
+		{"limits", `{"type":"object","additionalProperties":{"type":"object","properties":{"max":{"type":"integer","minimum":1}},"required":["max"]}}`},
+		{"groups", `{"type":"object","additionalProperties":{"type":"array","items":{"type":"integer"}}}`},
+		{"metadata", `{"type":"object"}`},
+		{"nested", `{"type":"object","additionalProperties":{"$ref":"#/$defs/registry"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			data, _ := json.Marshal(schema.Properties[tt.property])
+			if string(data) != tt.want {
+				t.Errorf("schema = %s, want %s", data, tt.want)
+			}
+		})
+	}
+	if got := schema.Properties["shapes"].AdditionalProperties; got == nil || len(got.OneOf) != 2 {
+		t.Errorf("shapes additionalProperties = %+v", got)
+	}
+
+	validation := []struct {
+		input   string
+		wantErr string
+	}{
+		{`{"counts": {"a": 1}, "limits": {"x": {"max": 2}}, "metadata": {"k": [true]}, "nested": {"a": {"entries": {}}}}`, ""},
+		{`{"counts": {"a": "one"}}`, "counts.a: expected integer, got string"},
+		{`{"limits": {"x": {"max": 0}}}`, "limits.x.max: value 0 is less than minimum 1"},
+		{`{"limits": {"x": {}}}`, "limits.x.max: required field is missing"},
+		{`{"groups": {"g": [1, "2"]}}`, "groups.g[1]: expected integer, got string"},
+		{`{"shapes": {"s": {"kind": "circle", "radius": -1}}}`, "shapes.s.radius: value -1 is less than minimum 0"},
+		{`{"nested": {"a": {"entries": {"b": {"entries": "none"}}}}}`, "nested.a.entries.b.entries: expected object, got string"},
+	}
+	for _, tt := range validation {
+		err := schema.Validate(json.RawMessage(tt.input))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) error = %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
 }
 
 func TestSchema_MarshalJSON(t *testing.T) {
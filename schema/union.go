@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Discriminator names the property that selects the variant of a oneOf
+// schema, as in OpenAPI.
+type Discriminator struct {
+	PropertyName string `json:"propertyName"`
+}
+
+// Union describes the variants of an interface type registered with
+// RegisterUnion.
+type Union struct {
+	// Discriminator is the JSON property holding the variant name.
+	Discriminator string
+	// Variants maps each variant name to its concrete type, a struct or a
+	// pointer to a struct.
+	Variants map[string]reflect.Type
+}
+
+// unions holds the registered unions.
+var unions sync.Map // reflect.Type -> Union
+
+// RegisterUnion declares the variants of interface type T, so fields of
+// type T generate a oneOf schema and tool inputs decode into the variant
+// named by the discriminator property:
+//
+//	type Shape interface{ Area() float64 }
+//
+//	err := schema.RegisterUnion[Shape]("kind", map[string]Shape{
+//	    "circle": Circle{},
+//	    "square": Square{},
+//	})
+//
+// An input of {"kind": "circle", "radius": 2} then decodes into a Circle.
+// Each variant is given as its zero value; a pointer registers a pointer
+// variant. Registering T again replaces its variants.
+func RegisterUnion[T any](discriminator string, variants map[string]T) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		return fmt.Errorf("union %s: not an interface type", t)
+	}
+	if discriminator == "" {
+		return fmt.Errorf("union %s: discriminator is required", t)
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("union %s: no variants", t)
+	}
+
+	u := Union{Discriminator: discriminator, Variants: make(map[string]reflect.Type, len(variants))}
+	for name, v := range variants {
+		vt := reflect.TypeOf(v)
+		if vt == nil {
+			return fmt.Errorf("union %s: variant %q is nil", t, name)
+		}
+		st := vt
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if st.Kind() != reflect.Struct {
+			return fmt.Errorf("union %s: variant %q is %s, not a struct", t, name, vt)
+		}
+		u.Variants[name] = vt
+	}
+	unions.Store(t, u)
+	return nil
+}
+
+// LookupUnion returns the union registered for interface type t.
+func LookupUnion(t reflect.Type) (Union, bool) {
+	u, ok := unions.Load(t)
+	if !ok {
+		return Union{}, false
+	}
+	return u.(Union), true
+}
+
+// Names returns the variant names in sorted order.
+func (u Union) Names() []string {
+	names := make([]string, 0, len(u.Variants))
+	for name := range u.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateUnion returns a oneOf schema with an object schema per variant,
+// each requiring the discriminator property to hold the variant name.
+func (g *generator) generateUnion(u Union) (*Schema, error) {
+	schema := &Schema{Discriminator: &Discriminator{PropertyName: u.Discriminator}}
+	for _, name := range u.Names() {
+		vt := u.Variants[name]
+		if vt.Kind() == reflect.Ptr {
+			vt = vt.Elem()
+		}
+
+		g.visiting[vt] = true
+		variant, err := g.generateStructSchema(vt)
+		delete(g.visiting, vt)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", name, err)
+		}
+		if g.recursive[vt] && vt != g.root {
+			// The variant refers to itself as a plain struct, without the
+			// discriminator
+			if g.defs == nil {
+				g.defs = make(map[string]*Schema)
+			}
+			g.defs[g.defName(vt)] = variant.Clone()
+		}
+
+		variant.Properties[u.Discriminator] = &Schema{Type: typeString, Const: name}
+		if !slices.Contains(variant.Required, u.Discriminator) {
+			variant.Required = append([]string{u.Discriminator}, variant.Required...)
+		}
+		schema.OneOf = append(schema.OneOf, variant)
+	}
+	return schema, nil
+}
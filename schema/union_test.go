@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Radius float64 `json:"radius" jsonschema:"required,minimum=0"`
+}
+
+func (c circle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Side float64 `json:"side" jsonschema:"required"`
+}
+
+func (s *square) area() float64 { return s.Side * s.Side }
+
+// expr is a recursive union: binary expressions hold expressions.
+type expr interface{ isExpr() }
+
+type literal struct {
+	Value float64 `json:"value"`
+}
+
+type binary struct {
+	Op    string `json:"op" jsonschema:"enum=+|*"`
+	Left  expr   `json:"left" jsonschema:"required"`
+	Right expr   `json:"right" jsonschema:"required"`
+}
+
+func (literal) isExpr() {}
+func (binary) isExpr()  {}
+
+func init() {
+	if err := RegisterUnion("kind", map[string]shape{"circle": circle{}, "square": &square{}}); err != nil {
+		panic(err)
+	}
+	if err := RegisterUnion("type", map[string]expr{"literal": literal{}, "binary": binary{}}); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterUnion(t *testing.T) {
+	type stringer interface{ String() string }
+	type named string
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{"not an interface", RegisterUnion("kind", map[string]circle{"circle": {}}), "not an interface type"},
+		{"no discriminator", RegisterUnion("", map[string]shape{"circle": circle{}}), "discriminator is required"},
+		{"no variants", RegisterUnion[shape]("kind", nil), "no variants"},
+		{"nil variant", RegisterUnion("kind", map[string]stringer{"none": nil}), `variant "none" is nil`},
+		{"not a struct", RegisterUnion("kind", map[string]any{"name": named("")}), `variant "name" is schema.named, not a struct`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil || !strings.Contains(tt.err.Error(), tt.wantErr) {
+				t.Errorf("RegisterUnion() error = %v, want %q", tt.err, tt.wantErr)
+			}
+		})
+	}
+
+	u, ok := LookupUnion(reflect.TypeOf((*shape)(nil)).Elem())
+	if !ok || u.Discriminator != "kind" || !reflect.DeepEqual(u.Names(), []string{"circle", "square"}) {
+		t.Errorf("LookupUnion() = %+v, %v", u, ok)
+	}
+	if u.Variants["square"] != reflect.TypeOf(&square{}) {
+		t.Errorf("square variant = %v, want pointer type", u.Variants["square"])
+	}
+}
+
+func TestGenerate_Union(t *testing.T) {
+	type Input struct {
+		Shape  shape   `json:"shape" jsonschema:"required,description=Shape to measure"`
+		Others []shape `json:"others"`
+	}
+
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(schema.Properties["shape"])
+	want := `{"description":"Shape to measure",` +
+		`"oneOf":[{"type":"object","properties":{"kind":{"type":"string","const":"circle"},"radius":{"type":"number","minimum":0}},"required":["kind","radius"]},` +
+		`{"type":"object","properties":{"kind":{"type":"string","const":"square"},"side":{"type":"number"}},"required":["kind","side"]}],` +
+		`"discriminator":{"propertyName":"kind"}}`
+	if string(data) != want {
+		t.Errorf("shape schema = %s\nwant %s", data, want)
+	}
+	if got := schema.Properties["others"].Items.Discriminator; got == nil || got.PropertyName != "kind" {
+		t.Errorf("others items discriminator = %+v", got)
+	}
+
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`{"shape": {"kind": "circle", "radius": 2}, "others": [{"kind": "square", "side": 1}]}`, ""},
+		{`{"shape": {"radius": 2}}`, "shape.kind: required field is missing"},
+		{`{"shape": {"kind": "triangle"}}`, "shape.kind: value must be one of: [circle square]"},
+		{`{"shape": {"kind": "circle", "radius": -1}}`, "shape.radius: value -1 is less than minimum 0"},
+		{`{"shape": {"kind": "circle"}}`, "shape.radius: required field is missing"},
+		{`{"shape": "circle"}`, "shape: expected object, got string"},
+		{`{"shape": {"kind": "square", "side": 1}, "others": [{"kind": "square"}]}`, "others[0].side: required field is missing"},
+	}
+	for _, tt := range tests {
+		err := schema.Validate(json.RawMessage(tt.input))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) error = %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGenerate_RecursiveUnion(t *testing.T) {
+	type Input struct {
+		Expr expr `json:"expr"`
+	}
+	schema, err := Generate(Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := schema.Properties["expr"].Ref; got != "#/$defs/expr" {
+		t.Fatalf("expr $ref = %q", got)
+	}
+	if def := schema.Defs["expr"]; def == nil || len(def.OneOf) != 2 {
+		t.Fatalf("$defs = %v", schema.Defs)
+	}
+
+	valid := `{"expr": {"type": "binary", "op": "+", "left": {"type": "literal", "value": 1},
+		"right": {"type": "binary", "op": "*", "left": {"type": "literal", "value": 2}, "right": {"type": "literal", "value": 3}}}}`
+	if err := schema.Validate(json.RawMessage(valid)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	invalid := `{"expr": {"type": "binary", "op": "+", "left": {"type": "literal", "value": 1}, "right": {"type": "binary", "op": "-"}}}`
+	err = schema.Validate(json.RawMessage(invalid))
+	if err == nil || !strings.Contains(err.Error(), "expr.right.op: value must be one of: [+ *]") ||
+		!strings.Contains(err.Error(), "expr.right.left: required field is missing") {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_OneOf(t *testing.T) {
+	minimum := 10.0
+	schema := &Schema{OneOf: []*Schema{
+		{Type: "string"},
+		{Type: "number", Minimum: &minimum},
+		{Type: "integer"},
+	}}
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`"text"`, ""},
+		{`2.5`, "value must match exactly one schema of oneOf, matched 0"},
+		{`3`, ""},
+		{`12`, "value must match exactly one schema of oneOf, matched 2"},
+	}
+	for _, tt := range tests {
+		err := schema.Validate(json.RawMessage(tt.input))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) error = %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
+
+	constant := &Schema{Const: "v1"}
+	if err := constant.Validate(json.RawMessage(`"v2"`)); err == nil || err.Error() != "value must be v1" {
+		t.Errorf("const error = %v", err)
+	}
+}
@@ -3,8 +3,12 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // Schema type constants.
@@ -19,8 +23,8 @@ const (
 
 // ValidationError represents a schema validation error.
 type ValidationError struct {
-	Path    string // JSON path to the invalid field (e.g., "user.email")
-	Message string // Human-readable error message
+	Path    string `json:"path"`    // JSON path to the invalid field (e.g., "user.email")
+	Message string `json:"message"` // Human-readable error message
 }
 
 func (e *ValidationError) Error() string {
@@ -53,213 +57,571 @@ func (e ValidationErrors) Error() string {
 	return sb.String()
 }
 
+// ValidateOption configures a validation run.
+type ValidateOption func(*validateConfig)
+
+// validateConfig holds the options of a validation run.
+type validateConfig struct {
+	maxErrors int
+}
+
+// FailFast stops validation at the first error.
+func FailFast() ValidateOption {
+	return MaxErrors(1)
+}
+
+// MaxErrors stops validation once n errors have been found.
+// A value of zero or less reports every error, which is the default.
+func MaxErrors(n int) ValidateOption {
+	return func(c *validateConfig) {
+		c.maxErrors = n
+	}
+}
+
 // Validate validates JSON data against a schema.
 // Returns nil if valid, or ValidationErrors if invalid.
-func (s *Schema) Validate(data json.RawMessage) error {
+func (s *Schema) Validate(data json.RawMessage, opts ...ValidateOption) error {
 	var value any
 	if err := json.Unmarshal(data, &value); err != nil {
 		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %s", err)}
 	}
+	return s.ValidateValue(value, opts...)
+}
+
+// ValidateValue validates a Go value against a schema.
+func (s *Schema) ValidateValue(value any, opts ...ValidateOption) error {
+	return run(s, validateRoot, value, opts)
+}
+
+// validateRoot validates value against the root schema of v.
+func validateRoot(v *validator, value any) {
+	v.validate(v.root, value)
+}
+
+// run validates value with check, using a pooled validator whose $ref
+// pointers resolve against root.
+func run(root *Schema, check checkFunc, value any, opts []ValidateOption) error {
+	var cfg validateConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-	var errs ValidationErrors
-	s.validate("", value, &errs)
+	v := validatorPool.Get().(*validator)
+	v.maxErrors = cfg.maxErrors
+	v.root = root
+	check(v, value)
 
-	if len(errs) > 0 {
-		return errs
+	var err error
+	if len(v.errs) > 0 {
+		err = append(ValidationErrors(nil), v.errs...)
 	}
-	return nil
+
+	v.reset()
+	validatorPool.Put(v)
+	return err
 }
 
-// ValidateValue validates a Go value against a schema.
-func (s *Schema) ValidateValue(value any) error {
-	var errs ValidationErrors
-	s.validate("", value, &errs)
+// validatorPool reuses validators, so the path buffer and error slice of a
+// validation run do not allocate for every call.
+var validatorPool = sync.Pool{
+	New: func() any {
+		return &validator{path: make([]byte, 0, 64)}
+	},
+}
+
+// validator holds the state of a validation run. The path of the value
+// being validated is kept in a buffer and only converted to a string when
+// an error is reported.
+type validator struct {
+	path      []byte
+	errs      ValidationErrors
+	maxErrors int
+	// root is the schema $ref pointers are resolved against
+	root *Schema
+}
+
+// reset prepares the validator for reuse.
+func (v *validator) reset() {
+	clear(v.errs)
+	v.errs = v.errs[:0]
+	v.path = v.path[:0]
+	v.maxErrors = 0
+	v.root = nil
+}
+
+// done reports whether the error limit has been reached.
+func (v *validator) done() bool {
+	return v.maxErrors > 0 && len(v.errs) >= v.maxErrors
+}
 
-	if len(errs) > 0 {
-		return errs
+// fail records an error at the current path.
+func (v *validator) fail(message string) {
+	if v.done() {
+		return
 	}
-	return nil
+	v.errs = append(v.errs, &ValidationError{Path: string(v.path), Message: message})
+}
+
+// pushField appends an object field to the path and returns the previous
+// path length for pop.
+func (v *validator) pushField(name string) int {
+	n := len(v.path)
+	if n > 0 {
+		v.path = append(v.path, '.')
+	}
+	v.path = append(v.path, name...)
+	return n
+}
+
+// pushIndex appends an array index to the path and returns the previous
+// path length for pop.
+func (v *validator) pushIndex(i int) int {
+	n := len(v.path)
+	v.path = append(v.path, '[')
+	v.path = strconv.AppendInt(v.path, int64(i), 10)
+	v.path = append(v.path, ']')
+	return n
+}
+
+// pop truncates the path to length n.
+func (v *validator) pop(n int) {
+	v.path = v.path[:n]
+}
+
+// Messages reported from several places.
+const (
+	msgRequired   = "required field is missing"
+	msgAdditional = "additional property is not allowed"
+)
+
+// failField records an error at the given field of the current path.
+func (v *validator) failField(name, message string) {
+	n := v.pushField(name)
+	v.fail(message)
+	v.pop(n)
+}
+
+// failType records that value is not of the schema type want.
+func (v *validator) failType(want string, value any) {
+	v.fail(fmt.Sprintf("expected %s, got %T", want, value))
 }
 
-func (s *Schema) validate(path string, value any, errs *ValidationErrors) {
+// failNotOneOf records that a value is none of the allowed values.
+func (v *validator) failNotOneOf(values []any) {
+	v.fail(fmt.Sprintf("value must be one of: %v", values))
+}
+
+func (v *validator) validate(s *Schema, value any) {
+	if s.IsFalse() {
+		v.fail("value is not allowed")
+		return
+	}
+
 	// Handle nil values
-	if value == nil {
+	if s == nil || value == nil {
 		// null is valid for any type unless required is enforced elsewhere
 		return
 	}
 
+	if s.Ref != "" {
+		resolved, err := v.resolve(s)
+		if err != nil {
+			v.fail(err.Error())
+			return
+		}
+		s = resolved
+	}
+
+	if s.Const != nil && !equalValues(s.Const, value) {
+		v.fail(fmt.Sprintf("value must be %v", s.Const))
+		return
+	}
+	if len(s.OneOf) > 0 {
+		v.validateOneOf(s, value)
+	}
+
 	switch s.Type {
 	case typeObject:
-		s.validateObject(path, value, errs)
+		v.validateObject(s, value)
 	case typeArray:
-		s.validateArray(path, value, errs)
+		v.validateArray(s, value)
 	case typeString:
-		s.validateString(path, value, errs)
+		v.validateString(s, value)
 	case typeInteger:
-		s.validateInteger(path, value, errs)
+		v.validateInteger(s, value)
 	case typeNumber:
-		s.validateNumber(path, value, errs)
+		v.validateNumber(s, value)
 	case typeBoolean:
-		s.validateBoolean(path, value, errs)
+		v.validateBoolean(s, value)
 	}
 }
 
-func (s *Schema) validateObject(path string, value any, errs *ValidationErrors) {
+// maxRefHops bounds the chain of references followed for one value, so a
+// reference cycle that consumes no data fails instead of looping forever.
+const maxRefHops = 32
+
+// resolve follows the $ref of s to the schema it points to.
+func (v *validator) resolve(s *Schema) (*Schema, error) {
+	return resolveRef(v.root, s)
+}
+
+// resolveRef follows the $ref of s to the schema it points to: "#" for the
+// root schema, or "#/$defs/Name" for a schema in the root's $defs, which
+// also holds the "#/definitions/Name" of older drafts.
+func resolveRef(root, s *Schema) (*Schema, error) {
+	for hops := 0; s.Ref != ""; hops++ {
+		if hops == maxRefHops {
+			return nil, fmt.Errorf("reference cycle at %q", s.Ref)
+		}
+		var target *Schema
+		switch {
+		case s.Ref == "#":
+			target = root
+		case strings.HasPrefix(s.Ref, "#/$defs/") && root != nil:
+			target = root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		case strings.HasPrefix(s.Ref, "#/definitions/") && root != nil:
+			target = root.Defs[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		}
+		if target == nil {
+			return nil, fmt.Errorf("unresolved reference %q", s.Ref)
+		}
+		s = target
+	}
+	return s, nil
+}
+
+func (v *validator) validateObject(s *Schema, value any) {
 	obj, ok := value.(map[string]any)
 	if !ok {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected object, got %T", value),
-		})
+		v.failType(typeObject, value)
 		return
 	}
 
-	// Check required fields
+	v.validateRequired(s, obj)
+
+	if s.AdditionalProperties != nil {
+		v.validateAdditional(s, obj)
+	}
+
+	// Validate properties, looking them up from the smaller map
+	if len(obj) < len(s.Properties) {
+		for name, val := range obj {
+			if v.done() {
+				return
+			}
+			if propSchema, exists := s.Properties[name]; exists {
+				v.validateField(propSchema, name, val)
+			}
+		}
+		return
+	}
+	for name, propSchema := range s.Properties {
+		if v.done() {
+			return
+		}
+		if val, exists := obj[name]; exists {
+			v.validateField(propSchema, name, val)
+		}
+	}
+}
+
+// validateRequired checks that obj has the required properties of s.
+func (v *validator) validateRequired(s *Schema, obj map[string]any) {
 	for _, req := range s.Required {
 		if _, exists := obj[req]; !exists {
-			fieldPath := joinPath(path, req)
-			*errs = append(*errs, &ValidationError{
-				Path:    fieldPath,
-				Message: "required field is missing",
-			})
+			v.failField(req, msgRequired)
+		}
+	}
+}
+
+// validateAdditional validates the properties of obj that s does not
+// list against s.AdditionalProperties.
+func (v *validator) validateAdditional(s *Schema, obj map[string]any) {
+	for name, val := range obj {
+		if v.done() {
+			return
+		}
+		if _, listed := s.Properties[name]; listed {
+			continue
 		}
+		if s.AdditionalProperties.IsFalse() {
+			v.failField(name, msgAdditional)
+			continue
+		}
+		v.validateField(s.AdditionalProperties, name, val)
 	}
+}
 
-	// Validate properties
-	for name, propSchema := range s.Properties {
-		if val, exists := obj[name]; exists {
-			fieldPath := joinPath(path, name)
-			propSchema.validate(fieldPath, val, errs)
+func (v *validator) validateField(s *Schema, name string, value any) {
+	n := v.pushField(name)
+	v.validate(s, value)
+	v.pop(n)
+}
+
+func (v *validator) validateArray(s *Schema, value any) {
+	// Decoded JSON arrays are []any; other slices need reflection
+	items, ok := value.([]any)
+	if !ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			v.failType(typeArray, value)
+			return
+		}
+		items = make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+	}
+
+	v.validateItemCount(s, len(items))
+	for i, item := range items {
+		if v.done() {
+			return
+		}
+		itemSchema := s.Items
+		if i < len(s.PrefixItems) {
+			itemSchema = s.PrefixItems[i]
+		}
+		if itemSchema == nil {
+			continue
 		}
+		n := v.pushIndex(i)
+		v.validate(itemSchema, item)
+		v.pop(n)
 	}
 }
 
-func (s *Schema) validateArray(path string, value any, errs *ValidationErrors) {
-	rv := reflect.ValueOf(value)
-	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected array, got %T", value),
-		})
+// validateItemCount checks the number of items of an array against the
+// minItems and maxItems of s.
+func (v *validator) validateItemCount(s *Schema, count int) {
+	if s.MinItems != nil && count < *s.MinItems {
+		v.fail(fmt.Sprintf("item count %d is less than minimum %d", count, *s.MinItems))
+	}
+	if s.MaxItems != nil && count > *s.MaxItems {
+		v.fail(fmt.Sprintf("item count %d is greater than maximum %d", count, *s.MaxItems))
+	}
+}
+
+func (v *validator) validateString(s *Schema, value any) {
+	str, ok := value.(string)
+	if !ok {
+		v.failType(typeString, value)
 		return
 	}
 
-	if s.Items == nil {
+	v.validateStringConstraints(s, str)
+	v.validateEnum(s, str)
+}
+
+func (v *validator) validateStringConstraints(s *Schema, str string) {
+	v.validateLength(s, str)
+
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			v.fail(fmt.Sprintf("invalid pattern %q: %s", s.Pattern, err))
+		} else if !re.MatchString(str) {
+			v.fail(fmt.Sprintf("value does not match pattern %q", s.Pattern))
+		}
+	}
+
+	if valid, ok := formats[s.Format]; ok && !valid(str) {
+		v.fail(fmt.Sprintf("value is not a valid %s", s.Format))
+	}
+}
+
+// validateLength checks the length of str, in characters, against the
+// minLength and maxLength of s.
+func (v *validator) validateLength(s *Schema, str string) {
+	if s.MinLength == nil && s.MaxLength == nil {
 		return
 	}
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(fmt.Sprintf("length %d is less than minimum length %d", length, *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(fmt.Sprintf("length %d is greater than maximum length %d", length, *s.MaxLength))
+	}
+}
 
-	for i := 0; i < rv.Len(); i++ {
-		itemPath := fmt.Sprintf("%s[%d]", path, i)
-		s.Items.validate(itemPath, rv.Index(i).Interface(), errs)
+// validateEnum checks that value is one of the enum values of s, if any.
+// Numbers are compared by value, so 2 matches an enum value of 2.0.
+func (v *validator) validateEnum(s *Schema, value any) {
+	if len(s.Enum) == 0 {
+		return
 	}
+	for _, e := range s.Enum {
+		if equalValues(e, value) {
+			return
+		}
+	}
+	v.failNotOneOf(s.Enum)
 }
 
-func (s *Schema) validateString(path string, value any, errs *ValidationErrors) {
-	str, ok := value.(string)
+// equalValues reports whether two scalar values are equal, comparing
+// numbers by value.
+func equalValues(a, b any) bool {
+	if a == b {
+		return true
+	}
+	x, ok := toFloat(a)
 	if !ok {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected string, got %T", value),
-		})
+		return false
+	}
+	y, ok := toFloat(b)
+	return ok && x == y
+}
+
+// validateOneOf checks that value matches exactly one schema of s.OneOf.
+// With a discriminator, the variant is selected by the discriminator
+// property and its errors are reported.
+func (v *validator) validateOneOf(s *Schema, value any) {
+	if s.Discriminator != nil {
+		v.validateDiscriminated(s, value)
 		return
 	}
 
-	// Validate enum
-	if len(s.Enum) > 0 {
-		found := false
-		for _, e := range s.Enum {
-			if e == str {
-				found = true
-				break
-			}
+	matched := 0
+	for _, variant := range s.OneOf {
+		sub := &validator{root: v.root, maxErrors: 1}
+		sub.validate(variant, value)
+		if len(sub.errs) == 0 {
+			matched++
+		}
+	}
+	if matched != 1 {
+		v.fail(fmt.Sprintf("value must match exactly one schema of oneOf, matched %d", matched))
+	}
+}
+
+func (v *validator) validateDiscriminated(s *Schema, value any) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		v.failType(typeObject, value)
+		return
+	}
+	prop := s.Discriminator.PropertyName
+	n := v.pushField(prop)
+	name, exists := obj[prop]
+	if !exists {
+		v.fail(msgRequired)
+		v.pop(n)
+		return
+	}
+
+	names := make([]any, 0, len(s.OneOf))
+	for _, variant := range s.OneOf {
+		resolved, err := v.resolve(variant)
+		if err != nil {
+			continue
 		}
-		if !found {
-			*errs = append(*errs, &ValidationError{
-				Path:    path,
-				Message: fmt.Sprintf("value must be one of: %v", s.Enum),
-			})
+		c := resolved.Properties[prop]
+		if c == nil || c.Const == nil {
+			continue
 		}
+		if equalValues(c.Const, name) {
+			v.pop(n)
+			v.validate(resolved, value)
+			return
+		}
+		names = append(names, c.Const)
+	}
+	v.failNotOneOf(names)
+	v.pop(n)
+}
+
+// toFloat converts a numeric value to float64.
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
 	}
+	return 0, false
 }
 
-func (s *Schema) validateInteger(path string, value any, errs *ValidationErrors) {
+func (v *validator) validateInteger(s *Schema, value any) {
 	var num float64
-	switch v := value.(type) {
+	switch n := value.(type) {
 	case float64:
-		num = v
+		num = n
 		// Check if it's actually an integer
 		if num != float64(int64(num)) {
-			*errs = append(*errs, &ValidationError{
-				Path:    path,
-				Message: "expected integer, got decimal number",
-			})
+			v.fail("expected integer, got decimal number")
 			return
 		}
 	case int:
-		num = float64(v)
+		num = float64(n)
 	case int64:
-		num = float64(v)
+		num = float64(n)
 	default:
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected integer, got %T", value),
-		})
+		v.failType(typeInteger, value)
 		return
 	}
 
-	s.validateNumericConstraints(path, num, errs)
+	v.validateNumericConstraints(s, num)
+	v.validateEnum(s, num)
 }
 
-func (s *Schema) validateNumber(path string, value any, errs *ValidationErrors) {
+func (v *validator) validateNumber(s *Schema, value any) {
 	var num float64
-	switch v := value.(type) {
+	switch n := value.(type) {
 	case float64:
-		num = v
+		num = n
 	case float32:
-		num = float64(v)
+		num = float64(n)
 	case int:
-		num = float64(v)
+		num = float64(n)
 	case int64:
-		num = float64(v)
+		num = float64(n)
 	default:
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected number, got %T", value),
-		})
+		v.failType(typeNumber, value)
 		return
 	}
 
-	s.validateNumericConstraints(path, num, errs)
+	v.validateNumericConstraints(s, num)
+	v.validateEnum(s, num)
 }
 
-func (s *Schema) validateNumericConstraints(path string, num float64, errs *ValidationErrors) {
+func (v *validator) validateNumericConstraints(s *Schema, num float64) {
 	if s.Minimum != nil && num < *s.Minimum {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("value %v is less than minimum %v", num, *s.Minimum),
-		})
+		v.fail(fmt.Sprintf("value %v is less than minimum %v", num, *s.Minimum))
 	}
 
 	if s.Maximum != nil && num > *s.Maximum {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("value %v is greater than maximum %v", num, *s.Maximum),
-		})
+		v.fail(fmt.Sprintf("value %v is greater than maximum %v", num, *s.Maximum))
 	}
-}
 
-func (s *Schema) validateBoolean(path string, value any, errs *ValidationErrors) {
-	if _, ok := value.(bool); !ok {
-		*errs = append(*errs, &ValidationError{
-			Path:    path,
-			Message: fmt.Sprintf("expected boolean, got %T", value),
-		})
+	if s.ExclusiveMinimum != nil && num <= *s.ExclusiveMinimum {
+		v.fail(fmt.Sprintf("value %v must be greater than %v", num, *s.ExclusiveMinimum))
+	}
+
+	if s.ExclusiveMaximum != nil && num >= *s.ExclusiveMaximum {
+		v.fail(fmt.Sprintf("value %v must be less than %v", num, *s.ExclusiveMaximum))
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 && !isMultiple(num, *s.MultipleOf) {
+		v.fail(fmt.Sprintf("value %v is not a multiple of %v", num, *s.MultipleOf))
 	}
 }
 
-func joinPath(base, field string) string {
-	if base == "" {
-		return field
+// isMultiple reports whether num is a multiple of step, allowing for the
+// rounding error of decimal steps such as 0.1.
+func isMultiple(num, step float64) bool {
+	q := num / step
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func (v *validator) validateBoolean(s *Schema, value any) {
+	if _, ok := value.(bool); !ok {
+		v.failType(typeBoolean, value)
+		return
 	}
-	return base + "." + field
+	v.validateEnum(s, value)
 }
@@ -2,6 +2,8 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -231,6 +233,163 @@ func TestSchema_Validate(t *testing.T) {
 		}
 	})
 
+	t.Run("validates exclusive bounds and multipleOf", func(t *testing.T) {
+		type Input struct {
+			Limit int     `json:"limit" jsonschema:"minimum=1,maximum=100"`
+			Ratio float64 `json:"ratio" jsonschema:"exclusiveMinimum=0,exclusiveMaximum=1"`
+			Step  float64 `json:"step" jsonschema:"multipleOf=0.1"`
+		}
+		schema, err := Generate(Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			input   string
+			wantErr string
+		}{
+			{`{"limit": 100, "ratio": 0.5, "step": 0.3}`, ""},
+			{`{"limit": 0}`, "limit: value 0 is less than minimum 1"},
+			{`{"limit": 101}`, "limit: value 101 is greater than maximum 100"},
+			{`{"ratio": 0}`, "ratio: value 0 must be greater than 0"},
+			{`{"ratio": 1}`, "ratio: value 1 must be less than 1"},
+			{`{"step": 0.35}`, "step: value 0.35 is not a multiple of 0.1"},
+		}
+		for _, tt := range tests {
+			err := schema.Validate(json.RawMessage(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) error = %v", tt.input, err)
+				}
+				continue
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("validates string constraints", func(t *testing.T) {
+		type Input struct {
+			Code    string `json:"code" jsonschema:"minLength=2,maxLength=4"`
+			Slug    string `json:"slug" jsonschema:"pattern=^[a-z]+(-[a-z]+)*$"`
+			Email   string `json:"email" jsonschema:"format=email"`
+			Site    string `json:"site" jsonschema:"format=uri"`
+			At      string `json:"at" jsonschema:"format=date-time"`
+			ID      string `json:"id" jsonschema:"format=uuid"`
+			Comment string `json:"comment" jsonschema:"format=markdown"`
+		}
+		schema, err := Generate(Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			input   string
+			wantErr string
+		}{
+			{`{"code": "äöü", "slug": "a-b", "email": "ada@example.com", "site": "https://example.com/x",
+			  "at": "2024-05-01T10:00:00Z", "id": "123e4567-e89b-12d3-a456-426614174000", "comment": "*hi*"}`, ""},
+			{`{"code": "a"}`, "code: length 1 is less than minimum length 2"},
+			{`{"code": "abcde"}`, "code: length 5 is greater than maximum length 4"},
+			{`{"slug": "A-b"}`, `slug: value does not match pattern "^[a-z]+(-[a-z]+)*$"`},
+			{`{"email": "Ada <ada@example.com>"}`, "email: value is not a valid email"},
+			{`{"site": "/relative"}`, "site: value is not a valid uri"},
+			{`{"at": "2024-05-01"}`, "at: value is not a valid date-time"},
+			{`{"id": "123e4567"}`, "id: value is not a valid uuid"},
+		}
+		for _, tt := range tests {
+			err := schema.Validate(json.RawMessage(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) error = %v", tt.input, err)
+				}
+				continue
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("validates references", func(t *testing.T) {
+		type node struct {
+			Name     string  `json:"name" jsonschema:"required"`
+			Children []*node `json:"children"`
+		}
+		type input struct {
+			Root *node `json:"root"`
+		}
+		schema, err := Generate(input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			input   string
+			wantErr string
+		}{
+			{`{"root": {"name": "a", "children": [{"name": "b", "children": [{"name": "c"}]}]}}`, ""},
+			{`{"root": {"name": "a", "children": [{"name": "b", "children": [{"name": 3}]}]}}`,
+				"root.children[0].children[0].name: expected string, got float64"},
+			{`{"root": {"name": "a", "children": [{}]}}`, "root.children[0].name: required field is missing"},
+		}
+		for _, tt := range tests {
+			err := schema.Validate(json.RawMessage(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) error = %v", tt.input, err)
+				}
+				continue
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("reports unresolved references", func(t *testing.T) {
+		schema := &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"a": {Ref: "#/$defs/missing"},
+				"b": {Ref: "#/$defs/loop"},
+			},
+			Defs: map[string]*Schema{"loop": {Ref: "#/$defs/loop"}},
+		}
+		err := schema.Validate(json.RawMessage(`{"a": 1}`))
+		if err == nil || err.Error() != `a: unresolved reference "#/$defs/missing"` {
+			t.Errorf("error = %v", err)
+		}
+		err = schema.Validate(json.RawMessage(`{"b": 1}`))
+		if err == nil || err.Error() != `b: reference cycle at "#/$defs/loop"` {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	t.Run("validates additional properties", func(t *testing.T) {
+		type Input struct {
+			Name string `json:"name"`
+		}
+		schema, err := Generate(Input{}, NoAdditionalProperties())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := schema.Validate(json.RawMessage(`{"name": "a"}`)); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+		err = schema.Validate(json.RawMessage(`{"name": "a", "nmae": "b"}`))
+		if err == nil || err.Error() != "nmae: additional property is not allowed" {
+			t.Errorf("Validate() error = %v", err)
+		}
+
+		typed := &Schema{Type: "object", AdditionalProperties: &Schema{Type: "number"}}
+		err = typed.Validate(json.RawMessage(`{"a": 1, "b": "x"}`))
+		if err == nil || err.Error() != "b: expected number, got string" {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+
 	t.Run("validates enum", func(t *testing.T) {
 		schema := &Schema{
 			Type: "object",
@@ -259,6 +418,38 @@ func TestSchema_Validate(t *testing.T) {
 		}
 	})
 
+	t.Run("validates numeric and array enums", func(t *testing.T) {
+		type Input struct {
+			Level int      `json:"level" jsonschema:"enum=1|2|3"`
+			Tags  []string `json:"tags" jsonschema:"enum=red|green"`
+		}
+		schema, err := Generate(Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			input   string
+			wantErr string
+		}{
+			{`{"level": 2, "tags": ["red", "green"]}`, ""},
+			{`{"level": 4}`, "level: value must be one of: [1 2 3]"},
+			{`{"tags": ["red", "blue"]}`, "tags[1]: value must be one of: [red green]"},
+		}
+		for _, tt := range tests {
+			err := schema.Validate(json.RawMessage(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) error = %v", tt.input, err)
+				}
+				continue
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		}
+	})
+
 	t.Run("handles invalid JSON", func(t *testing.T) {
 		schema := &Schema{Type: "object"}
 
@@ -288,6 +479,86 @@ func TestSchema_Validate(t *testing.T) {
 	})
 }
 
+func TestSchema_ValidatePaths(t *testing.T) {
+	s := orderSchema()
+
+	tests := []struct {
+		name      string
+		value     any
+		wantPaths []string
+	}{
+		{
+			name:      "decoded array item",
+			value:     map[string]any{"id": "o", "customer": map[string]any{"name": "Ada"}, "items": []any{map[string]any{"sku": "a", "quantity": 1.0}}},
+			wantPaths: []string{"items[0].price"},
+		},
+		{
+			name:      "typed slice item",
+			value:     map[string]any{"id": "o", "customer": map[string]any{"name": "Ada"}, "items": []map[string]any{{"sku": "a", "quantity": 1.0, "price": 1.0, "tags": []string{"x"}}, {"sku": 2, "quantity": 1.0, "price": 1.0}}},
+			wantPaths: []string{"items[1].sku"},
+		},
+		{
+			name:      "nested object",
+			value:     map[string]any{"id": "o", "customer": map[string]any{"name": "Ada", "address": map[string]any{"street": "Main"}}, "items": []any{}},
+			wantPaths: []string{"customer.address.city"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateValue(tt.value)
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			var paths []string
+			for _, e := range errs {
+				paths = append(paths, e.Path)
+			}
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateOptions(t *testing.T) {
+	s := orderSchema()
+	data := orderDocument(10, true)
+
+	tests := []struct {
+		name string
+		opts []ValidateOption
+		want int
+	}{
+		// Each invalid item has a missing price, a quantity out of range
+		// and an unknown status
+		{name: "all errors", want: 30},
+		{name: "fail fast", opts: []ValidateOption{FailFast()}, want: 1},
+		{name: "max errors", opts: []ValidateOption{MaxErrors(4)}, want: 4},
+		{name: "unlimited", opts: []ValidateOption{MaxErrors(0)}, want: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(data, tt.opts...)
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			if len(errs) != tt.want {
+				t.Errorf("got %d errors, want %d", len(errs), tt.want)
+			}
+		})
+	}
+
+	t.Run("reused validators start clean", func(t *testing.T) {
+		if err := s.Validate(orderDocument(10, false)); err != nil {
+			t.Errorf("expected valid, got error: %v", err)
+		}
+	})
+}
+
 func TestValidationErrors(t *testing.T) {
 	t.Run("single error format", func(t *testing.T) {
 		errs := ValidationErrors{
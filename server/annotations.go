@@ -116,11 +116,13 @@ func (b *ToolBuilder) ClosedWorld() *ToolBuilder {
 	return b
 }
 
-// Title sets a human-readable title for the tool.
+// Title sets a human-readable title for the tool. It is listed both as the
+// tool title and as the title annotation read by older clients.
 func (b *ToolBuilder) Title(title string) *ToolBuilder {
 	if b.err != nil {
 		return b
 	}
+	b.tool.title = title
 	if b.tool.annotations == nil {
 		b.tool.annotations = &ToolAnnotations{}
 	}
@@ -203,3 +205,16 @@ func (b *PromptBuilder) Annotate(annotations PromptAnnotations) *PromptBuilder {
 	b.prompt.annotations = &annotations
 	return b
 }
+
+// IdempotentTool reports whether the named tool is safe to call more than
+// once with the same input: its annotations mark it idempotent or
+// read-only. It reports false for unknown tools. Pass it to
+// middleware.RetryPolicy to retry failed calls of these tools.
+func (s *Server) IdempotentTool(name string) bool {
+	t, ok := s.getTool(name)
+	if !ok || t.annotations == nil {
+		return false
+	}
+	a := t.annotations
+	return (a.IdempotentHint != nil && *a.IdempotentHint) || (a.ReadOnlyHint != nil && *a.ReadOnlyHint)
+}
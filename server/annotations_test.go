@@ -418,3 +418,30 @@ func TestHelperFunctions(t *testing.T) {
 		}
 	})
 }
+
+func TestServer_IdempotentTool(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	handler := func(input struct{}) (string, error) { return "", nil }
+	srv.Tool("put").Idempotent().Handler(handler)
+	srv.Tool("get").ReadOnly().Handler(handler)
+	srv.Tool("delete").Destructive().Handler(handler)
+	srv.Tool("plain").Handler(handler)
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"put", true},
+		{"get", true},
+		{"delete", false},
+		{"plain", false},
+		{"missing", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := srv.IdempotentTool(tt.name); got != tt.want {
+				t.Errorf("IdempotentTool(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedResource is a resource read kept by a ResourceCache.
+type CachedResource struct {
+	// Content is the content returned by the resource handler.
+	Content ResourceContent `json:"content"`
+	// ETag is the version of the content reported by the resource's
+	// CacheETag function, or "" if it has none.
+	ETag string `json:"etag,omitempty"`
+	// Expires is when the entry must be revalidated.
+	Expires time.Time `json:"expires"`
+}
+
+// ResourceCache stores the reads of cached resources, keyed by canonical
+// URI. The default cache keeps entries in memory. Implement it on top of a
+// shared store, such as Redis, so several server instances share reads.
+//
+// Implementations must be safe for concurrent use. Entries past their
+// expiry should be kept until deleted or evicted, since an entry with an
+// ETag can be revalidated without running the handler.
+type ResourceCache interface {
+	// Get returns the entry for uri and whether one was found.
+	Get(ctx context.Context, uri string) (CachedResource, bool, error)
+	// Set creates or replaces the entry for uri.
+	Set(ctx context.Context, uri string, entry CachedResource) error
+	// Delete removes the entry for uri. Deleting a missing entry is not an
+	// error.
+	Delete(ctx context.Context, uri string) error
+}
+
+// WithResourceCache sets the cache used by resources registered with
+// ResourceBuilder.Cache. Defaults to an in-memory cache.
+func WithResourceCache(cache ResourceCache) Option {
+	return func(s *Server) {
+		s.resourceCache = cache
+	}
+}
+
+// ResourceCache returns the cache used for resource reads.
+func (s *Server) ResourceCache() ResourceCache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resourceCache
+}
+
+// Cache keeps the reads of the resource for ttl, so expensive handlers,
+// such as those building config or database snapshots, do not run for
+// every read. Entries are keyed by the canonical URI read, so each
+// instance of a template is cached on its own, and are dropped when
+// NotifyResourceUpdated is called for their URI.
+//
+// Example:
+//
+//	srv.Resource("config://app").
+//	    Cache(time.Minute).
+//	    Handler(loadConfig)
+func (b *ResourceBuilder) Cache(ttl time.Duration) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.resource.cacheTTL = max(ttl, 0)
+	b.resource.cached = true
+	return b
+}
+
+// CacheETag revalidates expired cache entries with fn, which returns the
+// current version of the resource, such as a file's modification time or
+// a row version, more cheaply than reading it. If the version matches the
+// one stored with the entry, the entry is kept for another TTL without
+// running the handler. With a TTL of zero, every read is revalidated.
+//
+// Example:
+//
+//	srv.Resource("db://snapshot/{table}").
+//	    Cache(30 * time.Second).
+//	    CacheETag(func(ctx context.Context, uri string, params map[string]string) (string, error) {
+//	        return db.TableVersion(ctx, params["table"])
+//	    }).
+//	    Handler(snapshot)
+func (b *ResourceBuilder) CacheETag(fn func(ctx context.Context, uri string, params map[string]string) (string, error)) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.resource.etag = fn
+	b.resource.cached = true
+	return b
+}
+
+// readCached reads a cached resource, running its handler only when no
+// current entry is cached. Cache errors are treated as misses, so a failing
+// cache does not fail reads.
+func (s *Server) readCached(ctx context.Context, r *Resource, uri string) (*ResourceContent, error) {
+	canonical, params, ok := r.match(uri)
+	if !ok {
+		return r.Read(ctx, uri)
+	}
+
+	cache := s.ResourceCache()
+	entry, found, err := cache.Get(ctx, canonical)
+	found = found && err == nil
+	now := time.Now()
+	if found && now.Before(entry.Expires) {
+		content := entry.Content
+		return &content, nil
+	}
+
+	var etag string
+	if r.etag != nil {
+		etag, err = r.etag(ctx, canonical, params)
+		if err != nil {
+			return nil, err
+		}
+		if found && etag != "" && etag == entry.ETag {
+			entry.Expires = now.Add(r.cacheTTL)
+			_ = cache.Set(ctx, canonical, entry)
+			content := entry.Content
+			return &content, nil
+		}
+	}
+
+	content, err := r.handler(ctx, canonical, params)
+	if err != nil || content == nil {
+		return content, err
+	}
+	_ = cache.Set(ctx, canonical, CachedResource{Content: *content, ETag: etag, Expires: now.Add(r.cacheTTL)})
+	return content, nil
+}
+
+// MemoryResourceCache is an in-memory ResourceCache.
+type MemoryResourceCache struct {
+	mu      sync.RWMutex
+	entries map[string]CachedResource
+}
+
+// NewMemoryResourceCache creates an empty in-memory resource cache.
+func NewMemoryResourceCache() *MemoryResourceCache {
+	return &MemoryResourceCache{entries: make(map[string]CachedResource)}
+}
+
+// Get returns the entry for uri.
+func (c *MemoryResourceCache) Get(ctx context.Context, uri string) (CachedResource, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[uri]
+	return entry, ok, nil
+}
+
+// Set creates or replaces the entry for uri.
+func (c *MemoryResourceCache) Set(ctx context.Context, uri string, entry CachedResource) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[uri] = entry
+	return nil
+}
+
+// Delete removes the entry for uri.
+func (c *MemoryResourceCache) Delete(ctx context.Context, uri string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, uri)
+	return nil
+}
+
+// Len returns the number of cached entries.
+func (c *MemoryResourceCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingCache is a ResourceCache whose every operation fails.
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, uri string) (CachedResource, bool, error) {
+	return CachedResource{}, false, errors.New("cache down")
+}
+
+func (failingCache) Set(ctx context.Context, uri string, entry CachedResource) error {
+	return errors.New("cache down")
+}
+
+func (failingCache) Delete(ctx context.Context, uri string) error {
+	return errors.New("cache down")
+}
+
+func TestResourceBuilder_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	newServer := func(ttl time.Duration, opts ...Option) (*Server, *atomic.Int32) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, opts...)
+		var calls atomic.Int32
+		srv.Resource("config://{env}").
+			Cache(ttl).
+			Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+				n := calls.Add(1)
+				return &ResourceContent{URI: uri, Text: fmt.Sprintf("%s #%d", params["env"], n)}, nil
+			})
+		return srv, &calls
+	}
+
+	t.Run("hits within the ttl", func(t *testing.T) {
+		srv, calls := newServer(time.Hour)
+		for range 3 {
+			content, err := srv.ReadResource(ctx, "config://prod")
+			if err != nil {
+				t.Fatalf("ReadResource() error = %v", err)
+			}
+			if content.Text != "prod #1" {
+				t.Errorf("Text = %q, want the first read", content.Text)
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("handler called %d times, want 1", calls.Load())
+		}
+	})
+
+	t.Run("keyed by canonical URI", func(t *testing.T) {
+		srv, calls := newServer(time.Hour)
+		for _, uri := range []string{"config://prod", "config://prod/", "config://dev"} {
+			if _, err := srv.ReadResource(ctx, uri); err != nil {
+				t.Fatalf("ReadResource(%q) error = %v", uri, err)
+			}
+		}
+		if calls.Load() != 2 {
+			t.Errorf("handler called %d times, want once per canonical URI", calls.Load())
+		}
+	})
+
+	t.Run("expires after the ttl", func(t *testing.T) {
+		srv, calls := newServer(0)
+		srv.ReadResource(ctx, "config://prod")
+		content, _ := srv.ReadResource(ctx, "config://prod")
+		if content.Text != "prod #2" || calls.Load() != 2 {
+			t.Errorf("Text = %q after %d calls, want a fresh read", content.Text, calls.Load())
+		}
+	})
+
+	t.Run("invalidated by NotifyResourceUpdated", func(t *testing.T) {
+		srv, calls := newServer(time.Hour)
+		srv.ReadResource(ctx, "config://prod")
+		srv.NotifyResourceUpdated("config://prod/")
+
+		content, _ := srv.ReadResource(ctx, "config://prod")
+		if content.Text != "prod #2" || calls.Load() != 2 {
+			t.Errorf("Text = %q after %d calls, want a fresh read", content.Text, calls.Load())
+		}
+	})
+
+	t.Run("cached content is not shared", func(t *testing.T) {
+		srv, _ := newServer(time.Hour)
+		first, _ := srv.ReadResource(ctx, "config://prod")
+		first.Text = "changed"
+
+		content, _ := srv.ReadResource(ctx, "config://prod")
+		if content.Text != "prod #1" {
+			t.Errorf("Text = %q, want the cached read unchanged", content.Text)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		var calls atomic.Int32
+		srv.Resource("config://app").
+			Cache(time.Hour).
+			Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+				if calls.Add(1) == 1 {
+					return nil, errors.New("unavailable")
+				}
+				return &ResourceContent{URI: uri, Text: "ok"}, nil
+			})
+
+		if _, err := srv.ReadResource(ctx, "config://app"); err == nil {
+			t.Fatal("ReadResource() error = nil, want the handler error")
+		}
+		content, err := srv.ReadResource(ctx, "config://app")
+		if err != nil || content.Text != "ok" {
+			t.Errorf("ReadResource() = %v, %v, want a fresh read", content, err)
+		}
+	})
+
+	t.Run("failing cache falls back to the handler", func(t *testing.T) {
+		srv, calls := newServer(time.Hour, WithResourceCache(failingCache{}))
+		for range 2 {
+			if _, err := srv.ReadResource(ctx, "config://prod"); err != nil {
+				t.Fatalf("ReadResource() error = %v", err)
+			}
+		}
+		srv.NotifyResourceUpdated("config://prod")
+		if calls.Load() != 2 {
+			t.Errorf("handler called %d times, want every read", calls.Load())
+		}
+	})
+
+	t.Run("uncached resources", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Resource("config://app").Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri, Text: "ok"}, nil
+		})
+		srv.ReadResource(ctx, "config://app")
+
+		if n := srv.ResourceCache().(*MemoryResourceCache).Len(); n != 0 {
+			t.Errorf("Len() = %d, want nothing cached", n)
+		}
+	})
+}
+
+func TestResourceBuilder_CacheETag(t *testing.T) {
+	ctx := context.Background()
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	version := "v1"
+	var reads, checks int
+	srv.Resource("db://snapshot/{table}").
+		Cache(0).
+		CacheETag(func(ctx context.Context, uri string, params map[string]string) (string, error) {
+			checks++
+			if params["table"] == "broken" {
+				return "", errors.New("version unavailable")
+			}
+			return version, nil
+		}).
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			reads++
+			return &ResourceContent{URI: uri, Text: params["table"] + "@" + version}, nil
+		})
+
+	read := func(uri string) string {
+		t.Helper()
+		content, err := srv.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource() error = %v", err)
+		}
+		return content.Text
+	}
+
+	if got := read("db://snapshot/users"); got != "users@v1" {
+		t.Errorf("first read = %q", got)
+	}
+	if got := read("db://snapshot/users"); got != "users@v1" || reads != 1 {
+		t.Errorf("unchanged read = %q after %d handler calls, want the cached read", got, reads)
+	}
+	if checks != 2 {
+		t.Errorf("CacheETag called %d times, want on every read with no TTL", checks)
+	}
+
+	version = "v2"
+	if got := read("db://snapshot/users"); got != "users@v2" || reads != 2 {
+		t.Errorf("changed read = %q after %d handler calls, want a fresh read", got, reads)
+	}
+
+	entry, ok, _ := srv.ResourceCache().Get(ctx, "db://snapshot/users")
+	if !ok || entry.ETag != "v2" {
+		t.Errorf("cached entry = %+v, want ETag v2", entry)
+	}
+
+	if _, err := srv.ReadResource(ctx, "db://snapshot/broken"); err == nil {
+		t.Error("ReadResource() error = nil, want the CacheETag error")
+	}
+}
+
+func TestMemoryResourceCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryResourceCache()
+
+	if _, ok, err := c.Get(ctx, "config://app"); ok || err != nil {
+		t.Fatalf("Get() on empty cache = %v, %v", ok, err)
+	}
+
+	entry := CachedResource{Content: ResourceContent{URI: "config://app", Text: "ok"}, ETag: "v1", Expires: time.Now()}
+	if err := c.Set(ctx, "config://app", entry); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, ok, err := c.Get(ctx, "config://app")
+	if !ok || err != nil || got.Content.Text != "ok" || got.ETag != "v1" {
+		t.Errorf("Get() = %+v, %v, %v", got, ok, err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+
+	if err := c.Delete(ctx, "config://app"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := c.Delete(ctx, "config://app"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "config://app"); ok {
+		t.Error("Get() after Delete() found the entry")
+	}
+}
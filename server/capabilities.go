@@ -0,0 +1,41 @@
+package server
+
+// WithCapabilities pins the capabilities advertised to clients to caps,
+// turning off detection from registrations. Use it to hide a feature that
+// is registered, for example while it is being rolled out.
+func WithCapabilities(caps Capabilities) Option {
+	return func(s *Server) {
+		s.pinnedCaps = &caps
+	}
+}
+
+// Capabilities returns the capabilities advertised to clients. Unless they
+// are pinned with WithCapabilities, a capability is advertised when it is
+// set in Info.Capabilities or when the server has a registration for it:
+//   - Tools: a tool
+//   - Resources: a resource, resource template or resource provider
+//   - Prompts: a prompt
+//   - Completions: a prompt or resource completion handler
+//
+// Capabilities are read when a client initializes, so registrations made
+// afterwards are only advertised to clients that initialize later.
+func (s *Server) Capabilities() Capabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.capabilitiesLocked()
+}
+
+// capabilitiesLocked returns the advertised capabilities. The caller must
+// hold s.mu.
+func (s *Server) capabilitiesLocked() Capabilities {
+	if s.pinnedCaps != nil {
+		return *s.pinnedCaps
+	}
+
+	caps := s.info.Capabilities
+	caps.Tools = caps.Tools || len(s.tools) > 0
+	caps.Resources = caps.Resources || len(s.resources) > 0 || len(s.providers) > 0
+	caps.Prompts = caps.Prompts || len(s.prompts) > 0
+	caps.Completions = caps.Completions || s.completions != nil
+	return caps
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestServer_Capabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Capabilities
+		opts     []Option
+		register func(s *Server)
+		want     Capabilities
+	}{
+		{
+			name: "nothing registered",
+			want: Capabilities{},
+		},
+		{
+			name: "detected from registrations",
+			register: func(s *Server) {
+				s.Tool("echo").Handler(func(in struct{}) (string, error) { return "", nil })
+				s.Resource("config://app").Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+					return nil, nil
+				})
+				s.Prompt("greet").Handler(func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+					return nil, nil
+				})
+				s.PromptCompletion("greet").Handler(func(ctx context.Context, ref CompletionRef, arg CompletionArgument) (*CompletionResult, error) {
+					return nil, nil
+				})
+			},
+			want: Capabilities{Tools: true, Resources: true, Prompts: true, Completions: true},
+		},
+		{
+			name: "resource provider",
+			register: func(s *Server) {
+				s.AddResourceProvider(NewFSProvider(fstest.MapFS{}, "file:///"))
+			},
+			want: Capabilities{Resources: true},
+		},
+		{
+			name: "info capabilities are kept",
+			info: Capabilities{Prompts: true},
+			register: func(s *Server) {
+				s.Tool("echo").Handler(func(in struct{}) (string, error) { return "", nil })
+			},
+			want: Capabilities{Tools: true, Prompts: true},
+		},
+		{
+			name: "pinned",
+			info: Capabilities{Prompts: true},
+			opts: []Option{WithCapabilities(Capabilities{Resources: true})},
+			register: func(s *Server) {
+				s.Tool("echo").Handler(func(in struct{}) (string, error) { return "", nil })
+			},
+			want: Capabilities{Resources: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Info{Name: "test", Version: "1.0.0", Capabilities: tt.info}, tt.opts...)
+			if tt.register != nil {
+				tt.register(s)
+			}
+			if got := s.Capabilities(); got != tt.want {
+				t.Errorf("Capabilities() = %+v, want %+v", got, tt.want)
+			}
+			if got := s.Manifest().Capabilities; got != tt.want {
+				t.Errorf("Manifest().Capabilities = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// ClientInfo describes the client of a connection, as negotiated in the
+// initialize request.
+type ClientInfo struct {
+	// Name and Version identify the client implementation, such as
+	// "claude-desktop" and "0.9.0".
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// ProtocolVersion is the protocol version the client requested.
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// Capabilities are the features the client declared.
+	Capabilities ClientCapabilities `json:"capabilities"`
+}
+
+// clientInfoKey is the context key for the client info.
+type clientInfoKey struct{}
+
+// ContextWithClientInfo returns a context with the client info attached.
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFromContext returns the info of the client that sent the
+// current request. It reports false for requests that do not belong to an
+// initialized connection, such as sessionless HTTP requests.
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info, ok
+}
+
+// clientRegistry holds the client info of each initialized connection.
+type clientRegistry struct {
+	mu      sync.Mutex
+	clients map[string]ClientInfo // connection ID -> client
+}
+
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{clients: make(map[string]ClientInfo)}
+}
+
+// SetClientInfo records the client of connection connID. Request handlers
+// call it when the connection is initialized or resumed; the info is
+// discarded by ForgetConnection.
+func (s *Server) SetClientInfo(connID string, info ClientInfo) {
+	if connID == "" {
+		return
+	}
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	s.clients.clients[connID] = info
+}
+
+// ClientInfo returns the client of connection connID, or false if the
+// connection is not initialized.
+func (s *Server) ClientInfo(connID string) (ClientInfo, bool) {
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	info, ok := s.clients.clients[connID]
+	return info, ok
+}
+
+// forgetClient discards the client info of a closed connection.
+func (s *Server) forgetClient(connID string) {
+	s.clients.mu.Lock()
+	defer s.clients.mu.Unlock()
+	delete(s.clients.clients, connID)
+}
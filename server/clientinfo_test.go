@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientInfoFromContext(t *testing.T) {
+	if _, ok := ClientInfoFromContext(context.Background()); ok {
+		t.Error("ClientInfoFromContext() reported info on an empty context")
+	}
+	want := ClientInfo{Name: "cursor", Version: "1.2.0", Capabilities: ClientCapabilities{Sampling: true}}
+	got, ok := ClientInfoFromContext(ContextWithClientInfo(context.Background(), want))
+	if !ok || got != want {
+		t.Errorf("ClientInfoFromContext() = %+v, %v, want %+v", got, ok, want)
+	}
+}
+
+func TestServer_ClientInfo(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	srv.SetClientInfo("", ClientInfo{Name: "ignored"})
+	if _, ok := srv.ClientInfo(""); ok {
+		t.Error("client info recorded for an empty connection ID")
+	}
+
+	srv.SetClientInfo("conn-1", ClientInfo{Name: "cursor"})
+	if info, ok := srv.ClientInfo("conn-1"); !ok || info.Name != "cursor" {
+		t.Errorf("ClientInfo() = %+v, %v", info, ok)
+	}
+	srv.ForgetConnection("conn-1")
+	if _, ok := srv.ClientInfo("conn-1"); ok {
+		t.Error("ClientInfo() after ForgetConnection reported info")
+	}
+}
+
+func TestSession_ClientInfo(t *testing.T) {
+	store := NewMemoryStore()
+	info := ClientInfo{Name: "cursor", Version: "1.2.0", ProtocolVersion: "2025-06-18"}
+
+	session := NewSession("s1", nil, nil, WithClientInfo(info), WithSessionStateStore(store))
+	session.SetClientCapabilities(ClientCapabilities{Roots: &RootsCapability{ListChanged: true}})
+	got, ok := session.ClientInfo()
+	if !ok || got.Name != "cursor" || got.Capabilities.Roots == nil {
+		t.Errorf("ClientInfo() = %+v, %v, want current capabilities", got, ok)
+	}
+
+	restored := NewSession("s1", nil, nil, WithSessionStateStore(store))
+	got, ok = restored.ClientInfo()
+	if !ok || got.Version != "1.2.0" || got.ProtocolVersion != "2025-06-18" || got.Capabilities.Roots == nil {
+		t.Errorf("restored ClientInfo() = %+v, %v", got, ok)
+	}
+
+	if _, ok := NewSession("s2", nil, nil).ClientInfo(); ok {
+		t.Error("ClientInfo() of a session without client reported info")
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// ComplianceStats counts protocol violations by clients, grouped by type.
+// Violations are counted whether or not strict compliance is enabled.
+type ComplianceStats struct {
+	// MissingInitialize counts requests sent before initialize.
+	MissingInitialize int64
+	// NotInitialized counts requests sent after initialize but before
+	// the notifications/initialized notification.
+	NotInitialized int64
+	// UnknownNotification counts notifications with unknown methods.
+	UnknownNotification int64
+}
+
+// LifecycleState is the lifecycle phase of a client connection.
+type LifecycleState int
+
+// Lifecycle states. A connection starts uninitialized, moves to
+// initializing when the client sends initialize, and becomes ready once
+// the client sends the notifications/initialized notification.
+const (
+	StateUninitialized LifecycleState = iota
+	StateInitializing
+	StateReady
+)
+
+// String returns the name of the state.
+func (s LifecycleState) String() string {
+	switch s {
+	case StateUninitialized:
+		return "uninitialized"
+	case StateInitializing:
+		return "initializing"
+	case StateReady:
+		return "ready"
+	default:
+		return "unknown"
+	}
+}
+
+// knownNotifications are the client-to-server notifications defined by the spec.
+var knownNotifications = map[string]struct{}{
+	protocol.MethodInitialized:      {},
+	protocol.MethodCancelled:        {},
+	protocol.MethodProgress:         {},
+	protocol.MethodRootsListChanged: {},
+}
+
+// complianceTracker tracks client lifecycle per connection and counts violations.
+type complianceTracker struct {
+	mu     sync.Mutex
+	phases map[string]LifecycleState // connection ID -> state
+
+	missingInitialize   atomic.Int64
+	notInitialized      atomic.Int64
+	unknownNotification atomic.Int64
+}
+
+func newComplianceTracker() *complianceTracker {
+	return &complianceTracker{
+		phases: make(map[string]LifecycleState),
+	}
+}
+
+// WithLifecycleEnforcement controls whether requests sent before the
+// connection is ready are rejected. Enforcement is enabled by default:
+// until a client has sent initialize and the notifications/initialized
+// notification, every request other than ping fails with an
+// InvalidRequest error. This includes HTTP requests sent without a
+// session ID, which never completed the handshake.
+func WithLifecycleEnforcement(enabled bool) Option {
+	return func(s *Server) {
+		s.lifecycleDisabled = !enabled
+	}
+}
+
+// LifecycleEnforcement reports whether lifecycle enforcement is enabled.
+func (s *Server) LifecycleEnforcement() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.lifecycleDisabled || s.strictCompliance
+}
+
+// WithStrictClientCompliance rejects requests from clients that violate the
+// protocol instead of tolerating them. With strict compliance enabled, the
+// server returns an InvalidRequest error when a client:
+//   - sends a request before initialize
+//   - sends a request before the notifications/initialized notification
+//   - sends a notification with an unknown method, neither defined by the
+//     spec nor registered with Method
+//
+// Strict compliance implies lifecycle enforcement. ping is always allowed,
+// as the spec permits it at any time.
+func WithStrictClientCompliance() Option {
+	return func(s *Server) {
+		s.strictCompliance = true
+	}
+}
+
+// StrictClientCompliance reports whether strict client compliance is enabled.
+func (s *Server) StrictClientCompliance() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.strictCompliance
+}
+
+// ComplianceStats returns the number of client protocol violations seen so far.
+func (s *Server) ComplianceStats() ComplianceStats {
+	return ComplianceStats{
+		MissingInitialize:   s.compliance.missingInitialize.Load(),
+		NotInitialized:      s.compliance.notInitialized.Load(),
+		UnknownNotification: s.compliance.unknownNotification.Load(),
+	}
+}
+
+// LifecycleState returns the lifecycle state of the connection identified by connID.
+func (s *Server) LifecycleState(connID string) LifecycleState {
+	s.compliance.mu.Lock()
+	defer s.compliance.mu.Unlock()
+	return s.compliance.phases[connID]
+}
+
+// CheckClientCompliance records req against the lifecycle of the connection
+// identified by connID and returns an error if it violates the protocol and
+// the violation is enforced. Lifecycle checks are skipped when connID is
+// empty, as for requests not made over a transport; HTTP requests without
+// a session go through CheckSessionlessCompliance instead.
+func (s *Server) CheckClientCompliance(connID string, req *protocol.Request) error {
+	t := s.compliance
+
+	if req.IsNotification() {
+		if _, ok := knownNotifications[req.Method]; !ok && !s.hasMethod(req.Method) {
+			t.unknownNotification.Add(1)
+			if s.StrictClientCompliance() {
+				return protocol.NewInvalidRequest("unknown notification: " + req.Method)
+			}
+			return nil
+		}
+	}
+
+	if connID == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	phase := t.phases[connID]
+	switch req.Method {
+	case protocol.MethodInitialize:
+		if phase == StateUninitialized {
+			t.phases[connID] = StateInitializing
+		}
+		t.mu.Unlock()
+		return nil
+	case protocol.MethodInitialized:
+		if phase == StateInitializing {
+			t.phases[connID] = StateReady
+		}
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+
+	if req.Method == protocol.MethodPing || req.IsNotification() {
+		return nil
+	}
+
+	switch phase {
+	case StateUninitialized:
+		t.missingInitialize.Add(1)
+		return s.lifecycleViolation("server not initialized: " + req.Method + " sent before initialize")
+	case StateInitializing:
+		t.notInitialized.Add(1)
+		return s.lifecycleViolation("server not initialized: " + req.Method + " sent before notifications/initialized")
+	}
+	return nil
+}
+
+// CheckSessionlessCompliance checks a request that arrived without a
+// connection ID on a transport that issues one on initialize, such as HTTP.
+// Such requests can't have completed the initialize handshake, so only
+// initialize, ping and notifications are allowed.
+func (s *Server) CheckSessionlessCompliance(req *protocol.Request) error {
+	if err := s.CheckClientCompliance("", req); err != nil {
+		return err
+	}
+	switch {
+	case req.Method == protocol.MethodInitialize, req.Method == protocol.MethodPing, req.IsNotification():
+		return nil
+	}
+	s.compliance.missingInitialize.Add(1)
+	return s.lifecycleViolation("server not initialized: " + req.Method + " sent without a session")
+}
+
+// ResumeConnection marks an uninitialized connection as ready without an
+// initialize handshake. Request handlers call it when a request belongs to
+// a session that was initialized on another server instance, as recorded
+// in the SessionStore.
+func (s *Server) ResumeConnection(connID string) {
+	if connID == "" {
+		return
+	}
+	s.compliance.mu.Lock()
+	defer s.compliance.mu.Unlock()
+	if s.compliance.phases[connID] == StateUninitialized {
+		s.compliance.phases[connID] = StateReady
+	}
+}
+
+// hasMethod reports whether a custom method is registered with Method.
+func (s *Server) hasMethod(name string) bool {
+	_, ok := s.MethodHandler(name)
+	return ok
+}
+
+// ForgetConnection discards the lifecycle state and client info of a
+// closed connection and frees its slot in the per-identity session limit.
+func (s *Server) ForgetConnection(connID string) {
+	s.forgetLifecycle(connID)
+	s.forgetClient(connID)
+	s.sessionLimits.release(connID)
+}
+
+// forgetLifecycle resets the lifecycle of a connection to uninitialized.
+func (s *Server) forgetLifecycle(connID string) {
+	s.compliance.mu.Lock()
+	defer s.compliance.mu.Unlock()
+	delete(s.compliance.phases, connID)
+}
+
+// lifecycleViolation returns an InvalidRequest error when lifecycle
+// enforcement is enabled, or nil otherwise.
+func (s *Server) lifecycleViolation(msg string) error {
+	if !s.LifecycleEnforcement() {
+		return nil
+	}
+	return protocol.NewInvalidRequest(msg)
+}
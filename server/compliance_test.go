@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func complianceRequest(method string, notification bool) *protocol.Request {
+	req := &protocol.Request{JSONRPC: "2.0", Method: method}
+	if !notification {
+		req.ID = json.RawMessage(`1`)
+	}
+	return req
+}
+
+func TestServer_CheckClientCompliance(t *testing.T) {
+	modes := map[string][]Option{
+		"lenient": {WithLifecycleEnforcement(false)},
+		"default": nil,
+		"strict":  {WithStrictClientCompliance()},
+	}
+
+	tests := []struct {
+		name      string
+		connID    string
+		sequence  []*protocol.Request
+		errModes  []string
+		wantStats ComplianceStats
+	}{
+		{
+			name:   "full handshake",
+			connID: "conn-1",
+			sequence: []*protocol.Request{
+				complianceRequest(protocol.MethodInitialize, false),
+				complianceRequest(protocol.MethodInitialized, true),
+				complianceRequest(protocol.MethodToolsList, false),
+			},
+		},
+		{
+			name:   "request before initialize",
+			connID: "conn-1",
+			sequence: []*protocol.Request{
+				complianceRequest(protocol.MethodToolsList, false),
+			},
+			errModes:  []string{"default", "strict"},
+			wantStats: ComplianceStats{MissingInitialize: 1},
+		},
+		{
+			name:   "request before initialized notification",
+			connID: "conn-1",
+			sequence: []*protocol.Request{
+				complianceRequest(protocol.MethodInitialize, false),
+				complianceRequest(protocol.MethodToolsCall, false),
+			},
+			errModes:  []string{"default", "strict"},
+			wantStats: ComplianceStats{NotInitialized: 1},
+		},
+		{
+			name:   "unknown notification",
+			connID: "conn-1",
+			sequence: []*protocol.Request{
+				complianceRequest("notifications/bogus", true),
+			},
+			errModes:  []string{"strict"},
+			wantStats: ComplianceStats{UnknownNotification: 1},
+		},
+		{
+			name:   "ping is always allowed",
+			connID: "conn-1",
+			sequence: []*protocol.Request{
+				complianceRequest(protocol.MethodPing, false),
+			},
+		},
+		{
+			name: "lifecycle not checked without connection ID",
+			sequence: []*protocol.Request{
+				complianceRequest(protocol.MethodToolsList, false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for mode, opts := range modes {
+				srv := New(Info{Name: "test", Version: "1.0.0"}, opts...)
+
+				var err error
+				for _, req := range tt.sequence {
+					err = srv.CheckClientCompliance(tt.connID, req)
+				}
+
+				if slices.Contains(tt.errModes, mode) {
+					var mcpErr *protocol.Error
+					if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeInvalidRequest {
+						t.Errorf("%s: error = %v, want InvalidRequest", mode, err)
+					}
+				} else if err != nil {
+					t.Errorf("%s: unexpected error: %v", mode, err)
+				}
+
+				if got := srv.ComplianceStats(); got != tt.wantStats {
+					t.Errorf("%s: stats = %+v, want %+v", mode, got, tt.wantStats)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_LifecycleState(t *testing.T) {
+	srv := New(Info{Name: "test"})
+
+	steps := []struct {
+		req  *protocol.Request
+		want LifecycleState
+	}{
+		{complianceRequest(protocol.MethodPing, false), StateUninitialized},
+		{complianceRequest(protocol.MethodInitialize, false), StateInitializing},
+		{complianceRequest(protocol.MethodInitialized, true), StateReady},
+		{complianceRequest(protocol.MethodInitialize, false), StateReady},
+	}
+	for _, step := range steps {
+		_ = srv.CheckClientCompliance("conn-1", step.req)
+		if got := srv.LifecycleState("conn-1"); got != step.want {
+			t.Errorf("after %s: state = %s, want %s", step.req.Method, got, step.want)
+		}
+	}
+}
+
+func TestServer_LifecycleEnforcement(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{name: "enabled by default", want: true},
+		{name: "disabled", opts: []Option{WithLifecycleEnforcement(false)}, want: false},
+		{name: "implied by strict mode", opts: []Option{WithLifecycleEnforcement(false), WithStrictClientCompliance()}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(Info{Name: "test"}, tt.opts...).LifecycleEnforcement(); got != tt.want {
+				t.Errorf("LifecycleEnforcement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ForgetConnection(t *testing.T) {
+	srv := New(Info{Name: "test"}, WithStrictClientCompliance())
+
+	_ = srv.CheckClientCompliance("conn-1", complianceRequest(protocol.MethodInitialize, false))
+	_ = srv.CheckClientCompliance("conn-1", complianceRequest(protocol.MethodInitialized, true))
+	if err := srv.CheckClientCompliance("conn-1", complianceRequest(protocol.MethodToolsList, false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.ForgetConnection("conn-1")
+
+	if err := srv.CheckClientCompliance("conn-1", complianceRequest(protocol.MethodToolsList, false)); err == nil {
+		t.Error("expected error after connection state was discarded")
+	}
+}
+
+func TestServer_ResumeConnection(t *testing.T) {
+	srv := New(Info{Name: "test"}, WithStrictClientCompliance())
+
+	srv.ResumeConnection("conn-1")
+	if got := srv.LifecycleState("conn-1"); got != StateReady {
+		t.Errorf("LifecycleState() = %v, want %v", got, StateReady)
+	}
+	if err := srv.CheckClientCompliance("conn-1", complianceRequest(protocol.MethodToolsList, false)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_ = srv.CheckClientCompliance("conn-2", complianceRequest(protocol.MethodInitialize, false))
+	srv.ResumeConnection("conn-2")
+	if got := srv.LifecycleState("conn-2"); got != StateInitializing {
+		t.Errorf("LifecycleState() of an initializing connection = %v, want %v", got, StateInitializing)
+	}
+}
+
+func TestServer_CheckClientCompliance_CustomNotification(t *testing.T) {
+	srv := New(Info{Name: "test"}, WithStrictClientCompliance())
+	req := complianceRequest("x-test/changed", true)
+
+	if err := srv.CheckClientCompliance("", req); err == nil {
+		t.Error("expected error for unregistered notification")
+	}
+	srv.Method("x-test/changed", func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return nil, nil
+	})
+	if err := srv.CheckClientCompliance("", req); err != nil {
+		t.Errorf("registered notification error = %v", err)
+	}
+}
+
+func TestServer_CheckSessionlessCompliance(t *testing.T) {
+	srv := New(Info{Name: "test"})
+
+	for _, req := range []*protocol.Request{
+		complianceRequest(protocol.MethodInitialize, false),
+		complianceRequest(protocol.MethodPing, false),
+		complianceRequest(protocol.MethodInitialized, true),
+	} {
+		if err := srv.CheckSessionlessCompliance(req); err != nil {
+			t.Errorf("%s: unexpected error: %v", req.Method, err)
+		}
+	}
+
+	err := srv.CheckSessionlessCompliance(complianceRequest(protocol.MethodToolsList, false))
+	var mcpErr *protocol.Error
+	if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeInvalidRequest {
+		t.Errorf("tools/list error = %v, want InvalidRequest", err)
+	}
+	if got := srv.ComplianceStats().MissingInitialize; got != 1 {
+		t.Errorf("MissingInitialize = %d, want 1", got)
+	}
+
+	lenient := New(Info{Name: "test"}, WithLifecycleEnforcement(false))
+	if err := lenient.CheckSessionlessCompliance(complianceRequest(protocol.MethodToolsList, false)); err != nil {
+		t.Errorf("lenient: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package server
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/schema"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodesItself reports whether encoding/json decodes values of type t
+// with their UnmarshalJSON or UnmarshalText method.
+func decodesItself(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(unmarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+// decodeInput decodes tool arguments into a new value of type t.
+//
+// Inputs that need conversion (see needsConversion) get two extra steps:
+// time.Duration fields accept the strings of their generated schema, such
+// as "1m30s", as well as numbers of nanoseconds as encoding/json does; and
+// interface fields registered with schema.RegisterUnion decode into the
+// variant named by their discriminator property.
+func decodeInput(input json.RawMessage, t reflect.Type, convert bool) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if !convert {
+		if err := json.Unmarshal(input, ptr.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr.Elem(), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return reflect.Value{}, err
+	}
+	value, err := convertDurations(value, t, "")
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if input, err = json.Marshal(value); err != nil {
+		return reflect.Value{}, err
+	}
+	if err := decodeValue(input, ptr.Elem(), ""); err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+// needsConversion reports whether values of type t contain time.Duration
+// fields or union interfaces, which encoding/json cannot decode alone.
+func needsConversion(t reflect.Type) bool {
+	return containsType(t, func(t reflect.Type) bool {
+		return t == durationType || isUnion(t)
+	}, make(map[reflect.Type]bool))
+}
+
+// hasUnion reports whether values of type t contain union interfaces.
+func hasUnion(t reflect.Type) bool {
+	return containsType(t, isUnion, make(map[reflect.Type]bool))
+}
+
+func isUnion(t reflect.Type) bool {
+	_, ok := schema.LookupUnion(t)
+	return ok
+}
+
+// containsType reports whether t or a type it contains matches.
+func containsType(t reflect.Type, match func(reflect.Type) bool, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if match(t) {
+		return true
+	}
+	if seen[t] || decodesItself(t) {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if (field.IsExported() || field.Anonymous) && containsType(field.Type, match, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return containsType(t.Elem(), match, seen)
+	case reflect.Interface:
+		if u, ok := schema.LookupUnion(t); ok {
+			for _, vt := range u.Variants {
+				if containsType(vt, match, seen) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// convertDurations replaces the duration strings in a decoded JSON value
+// of type t with their number of nanoseconds.
+func convertDurations(value any, t reflect.Type, path string) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		s, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid duration %q", trimPath(path), s)
+		}
+		return int64(d), nil
+	}
+	if decodesItself(t) {
+		// Types that decode themselves get their JSON unchanged
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return value, nil
+		}
+		for key, v := range obj {
+			field, ok := jsonField(t, key)
+			if !ok {
+				continue
+			}
+			converted, err := convertDurations(v, field.Type, path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = converted
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return value, nil
+		}
+		for i, item := range items {
+			converted, err := convertDurations(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return value, nil
+		}
+		for key, v := range obj {
+			converted, err := convertDurations(v, t.Elem(), path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = converted
+		}
+	case reflect.Interface:
+		u, ok := schema.LookupUnion(t)
+		obj, isObj := value.(map[string]any)
+		if !ok || !isObj {
+			return value, nil
+		}
+		name, _ := obj[u.Discriminator].(string)
+		if vt, ok := u.Variants[name]; ok {
+			return convertDurations(value, vt, path)
+		}
+	}
+	return value, nil
+}
+
+// decodeValue decodes data into v, which must be settable. Values without
+// union interfaces are decoded by encoding/json; the others are decoded
+// part by part, so each union gets a value of its variant type.
+func decodeValue(data json.RawMessage, v reflect.Value, path string) error {
+	t := v.Type()
+	if !hasUnion(t) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			v.SetZero()
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return decodeUnion(data, v, path)
+	case reflect.Ptr:
+		p := reflect.New(t.Elem())
+		if err := decodeValue(data, p.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return fmt.Errorf("%s: %w", trimPath(path), err)
+		}
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := decodeValue(items[i], v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: unsupported map key type %s", trimPath(path), t.Key())
+		}
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("%s: %w", trimPath(path), err)
+		}
+		m := reflect.MakeMapWithSize(t, len(entries))
+		for key, raw := range entries {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(raw, elem, path+"."+key); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		return decodeStruct(data, v, path)
+	}
+	return nil
+}
+
+// decodeUnion decodes data into union interface v as the variant named by
+// its discriminator property.
+func decodeUnion(data json.RawMessage, v reflect.Value, path string) error {
+	u, _ := schema.LookupUnion(v.Type())
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return fmt.Errorf("%s: %w", trimPath(path), err)
+	}
+	discriminator := trimPath(path + "." + u.Discriminator)
+	var name string
+	if raw, ok := probe[u.Discriminator]; !ok || json.Unmarshal(raw, &name) != nil {
+		return fmt.Errorf("%s: missing variant name", discriminator)
+	}
+	vt, ok := u.Variants[name]
+	if !ok {
+		return fmt.Errorf("%s: unknown variant %q, want one of %v", discriminator, name, u.Names())
+	}
+
+	if vt.Kind() == reflect.Ptr {
+		p := reflect.New(vt.Elem())
+		if err := decodeValue(data, p.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	p := reflect.New(vt)
+	if err := decodeValue(data, p.Elem(), path); err != nil {
+		return err
+	}
+	v.Set(p.Elem())
+	return nil
+}
+
+// decodeStruct decodes the fields of data that contain unions one by one,
+// and the others with encoding/json.
+func decodeStruct(data json.RawMessage, v reflect.Value, path string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%s: %w", trimPath(path), err)
+	}
+	for key, raw := range fields {
+		field, ok := jsonField(v.Type(), key)
+		if !ok || !hasUnion(field.Type) {
+			continue
+		}
+		fv, err := fieldByIndex(v, field.Index)
+		if err != nil {
+			return fmt.Errorf("%s: %w", trimPath(path+"."+key), err)
+		}
+		if err := decodeValue(raw, fv, path+"."+key); err != nil {
+			return err
+		}
+		delete(fields, key)
+	}
+
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rest, v.Addr().Interface())
+}
+
+// fieldByIndex returns the nested field of struct v, allocating embedded
+// struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// jsonField returns the field of struct type t that encoding/json decodes
+// key into: the field with that JSON name, or else one whose name matches
+// case-insensitively, or else such a field promoted from an embedded
+// struct. The Index of the field is relative to t.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	return findJSONField(t, key, map[reflect.Type]bool{t: true})
+}
+
+func findJSONField(t reflect.Type, key string, visited map[reflect.Type]bool) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	found := false
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, field)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field, true
+		}
+		if !found && strings.EqualFold(name, key) {
+			fold, found = field, true
+		}
+	}
+	if found {
+		return fold, true
+	}
+	for _, e := range embedded {
+		ft := e.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if visited[ft] {
+			continue
+		}
+		visited[ft] = true
+		if field, ok := findJSONField(ft, key, visited); ok {
+			field.Index = append([]int{e.Index[0]}, field.Index...)
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// trimPath strips the leading dot of a field path.
+func trimPath(path string) string {
+	return strings.TrimPrefix(path, ".")
+}
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/schema"
+)
+
+func TestTool_Execute_Durations(t *testing.T) {
+	type Window struct {
+		Every time.Duration `json:"every"`
+	}
+	type Input struct {
+		Timeout time.Duration            `json:"timeout"`
+		Retry   *time.Duration           `json:"retry,omitempty"`
+		Since   time.Time                `json:"since"`
+		Steps   []time.Duration          `json:"steps"`
+		Windows map[string]Window        `json:"windows"`
+		Limits  map[string]time.Duration `json:"limits"`
+		Legacy  time.Duration            // matched case-insensitively
+	}
+
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithInputValidation())
+	srv.Tool("schedule").Handler(func(in Input) (Input, error) { return in, nil })
+	tool, _ := srv.getTool("schedule")
+
+	tests := []struct {
+		name    string
+		input   string
+		check   func(t *testing.T, in Input)
+		wantErr string
+	}{
+		{
+			name:  "duration strings",
+			input: `{"timeout": "1m30s", "retry": "250ms", "steps": ["1s", "2s"], "windows": {"a": {"every": "1h"}}, "limits": {"b": "5m"}, "legacy": "2s"}`,
+			check: func(t *testing.T, in Input) {
+				if in.Timeout != 90*time.Second || *in.Retry != 250*time.Millisecond || in.Legacy != 2*time.Second {
+					t.Errorf("durations = %v, %v, %v", in.Timeout, *in.Retry, in.Legacy)
+				}
+				if len(in.Steps) != 2 || in.Steps[1] != 2*time.Second {
+					t.Errorf("Steps = %v", in.Steps)
+				}
+				if in.Windows["a"].Every != time.Hour || in.Limits["b"] != 5*time.Minute {
+					t.Errorf("Windows = %v, Limits = %v", in.Windows, in.Limits)
+				}
+			},
+		},
+		{
+			name:  "date-time",
+			input: `{"since": "2024-05-01T10:00:00Z"}`,
+			check: func(t *testing.T, in Input) {
+				if want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC); !in.Since.Equal(want) {
+					t.Errorf("Since = %v, want %v", in.Since, want)
+				}
+			},
+		},
+		{
+			name:    "invalid duration",
+			input:   `{"timeout": "soon"}`,
+			wantErr: "timeout: value does not match pattern",
+		},
+		{
+			name:    "invalid date-time",
+			input:   `{"since": "yesterday"}`,
+			wantErr: "since: value is not a valid date-time",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), []byte(tt.input))
+			if tt.wantErr != "" {
+				var protoErr *protocol.Error
+				if !errors.As(err, &protoErr) || protoErr.Code != protocol.CodeInvalidParams || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want InvalidParams containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			tt.check(t, result.(Input))
+		})
+	}
+}
+
+func TestDecodeInput_Durations(t *testing.T) {
+	type Input struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr string
+	}{
+		{"string", `{"timeout": "2h"}`, 2 * time.Hour, ""},
+		{"nanoseconds", `{"timeout": 1500}`, 1500, ""},
+		{"invalid", `{"timeout": "soon"}`, 0, `timeout: invalid duration "soon"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := decodeInput([]byte(tt.input), reflect.TypeOf(Input{}), true)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("decodeInput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeInput() error = %v", err)
+			}
+			if got := v.Interface().(Input).Timeout; got != tt.want {
+				t.Errorf("Timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// window decodes itself from a string such as "5m", so its duration field
+// must not be converted.
+type window struct {
+	Length time.Duration
+}
+
+func (w *window) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(s)
+	w.Length = d
+	return err
+}
+
+func TestDecodeInput_Unmarshaler(t *testing.T) {
+	type Input struct {
+		Timeout time.Duration `json:"timeout"`
+		Window  window        `json:"window"`
+	}
+	v, err := decodeInput([]byte(`{"timeout": "1s", "window": "5m"}`), reflect.TypeOf(Input{}), true)
+	if err != nil {
+		t.Fatalf("decodeInput() error = %v", err)
+	}
+	if in := v.Interface().(Input); in.Timeout != time.Second || in.Window.Length != 5*time.Minute {
+		t.Errorf("decoded = %+v", in)
+	}
+	if needsConversion(reflect.TypeOf(window{})) {
+		t.Error("needsConversion(window) = true, want false for a json.Unmarshaler")
+	}
+}
+
+// level is an enum decoded from its name.
+type level int
+
+const (
+	levelInfo level = iota
+	levelWarn
+)
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"info", "warn"}[l]), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*l = levelInfo
+	case "warn":
+		*l = levelWarn
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+// span decodes itself from text, so its duration field must not be
+// converted.
+type span struct {
+	Length time.Duration
+}
+
+func (s *span) UnmarshalText(text []byte) error {
+	d, err := time.ParseDuration(string(text))
+	s.Length = d
+	return err
+}
+
+func TestTool_Execute_TextTypes(t *testing.T) {
+	type Input struct {
+		Addr   netip.Addr    `json:"addr" jsonschema:"required"`
+		Level  level         `json:"level" jsonschema:"enum=info|warn"`
+		Window span          `json:"window"`
+		Every  time.Duration `json:"every"`
+	}
+
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithInputValidation())
+	srv.Tool("probe").Handler(func(in Input) (Input, error) { return in, nil })
+	tool, _ := srv.getTool("probe")
+
+	result, err := tool.Execute(context.Background(), []byte(`{"addr": "10.0.0.1", "level": "warn", "window": "5m", "every": "1s"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	in := result.(Input)
+	if in.Addr != netip.MustParseAddr("10.0.0.1") || in.Level != levelWarn || in.Window.Length != 5*time.Minute || in.Every != time.Second {
+		t.Errorf("decoded = %+v", in)
+	}
+
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`{"addr": 10}`, "addr: expected string, got float64"},
+		{`{"addr": "10.0.0.1", "level": "debug"}`, "level: value must be one of: [info warn]"},
+		{`{"addr": "not an address"}`, "failed to parse input"},
+	}
+	for _, tt := range tests {
+		_, err := tool.Execute(context.Background(), []byte(tt.input))
+		var protoErr *protocol.Error
+		if !errors.As(err, &protoErr) || protoErr.Code != protocol.CodeInvalidParams || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Execute(%s) error = %v, want InvalidParams containing %q", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+type retryPolicy struct {
+	Backoff time.Duration `json:"backoff"`
+}
+
+func TestDecodeInput_EmbeddedDurations(t *testing.T) {
+	type Input struct {
+		retryPolicy
+		Name string `json:"name"`
+	}
+	typ := reflect.TypeOf(Input{})
+	if !needsConversion(typ) {
+		t.Fatal("needsConversion() = false for a promoted duration field")
+	}
+	v, err := decodeInput([]byte(`{"name": "x", "backoff": "2s"}`), typ, true)
+	if err != nil {
+		t.Fatalf("decodeInput() error = %v", err)
+	}
+	if got := v.Interface().(Input).Backoff; got != 2*time.Second {
+		t.Errorf("Backoff = %v, want 2s", got)
+	}
+}
+
+type payment interface{ amount() int }
+
+type card struct {
+	Number string `json:"number" jsonschema:"required"`
+	Cents  int    `json:"cents"`
+}
+
+func (c card) amount() int { return c.Cents }
+
+type voucher struct {
+	Code  string        `json:"code"`
+	Cents int           `json:"cents"`
+	Valid time.Duration `json:"valid"`
+}
+
+func (v *voucher) amount() int { return v.Cents }
+
+type checkoutInput struct {
+	Payment  payment            `json:"payment"`
+	Split    []payment          `json:"split"`
+	Fallback map[string]payment `json:"fallback"`
+	Note     string             `json:"note"`
+}
+
+func init() {
+	if err := schema.RegisterUnion("method", map[string]payment{"card": card{}, "voucher": &voucher{}}); err != nil {
+		panic(err)
+	}
+}
+
+func TestTool_Execute_Union(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithInputValidation())
+	srv.Tool("checkout").Handler(func(in checkoutInput) (checkoutInput, error) { return in, nil })
+	tool, _ := srv.getTool("checkout")
+
+	t.Run("decodes variants", func(t *testing.T) {
+		result, err := tool.Execute(context.Background(), []byte(`{
+			"payment": {"method": "card", "number": "4242", "cents": 500},
+			"split": [{"method": "voucher", "code": "X", "cents": 100, "valid": "24h"}, null],
+			"fallback": {"a": {"method": "card", "number": "1"}},
+			"note": "gift"}`))
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		in := result.(checkoutInput)
+		if c, ok := in.Payment.(card); !ok || c.Number != "4242" || c.amount() != 500 {
+			t.Errorf("Payment = %#v, want card", in.Payment)
+		}
+		if v, ok := in.Split[0].(*voucher); !ok || v.Code != "X" || v.Valid != 24*time.Hour {
+			t.Errorf("Split[0] = %#v, want *voucher", in.Split[0])
+		}
+		if len(in.Split) != 2 || in.Split[1] != nil {
+			t.Errorf("Split = %#v, want nil second entry", in.Split)
+		}
+		if c, ok := in.Fallback["a"].(card); !ok || c.Number != "1" {
+			t.Errorf("Fallback = %#v", in.Fallback)
+		}
+		if in.Note != "gift" {
+			t.Errorf("Note = %q", in.Note)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"unknown variant", `{"payment": {"method": "cash"}}`, "payment.method: value must be one of: [card voucher]"},
+		{"variant field", `{"payment": {"method": "card"}}`, "payment.number: required field is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), []byte(tt.input))
+			var protoErr *protocol.Error
+			if !errors.As(err, &protoErr) || protoErr.Code != protocol.CodeInvalidParams || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want InvalidParams containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeInput_UnionErrors(t *testing.T) {
+	typ := reflect.TypeOf(checkoutInput{})
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`{"payment": {"cents": 1}}`, "payment.method: missing variant name"},
+		{`{"split": [{"method": "cash"}]}`, `split[0].method: unknown variant "cash", want one of [card voucher]`},
+		{`{"payment": {"method": "card", "cents": "many"}}`, "cannot unmarshal string"},
+	}
+	for _, tt := range tests {
+		_, err := decodeInput([]byte(tt.input), typ, true)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("decodeInput(%s) error = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Names of the diagnostics tools registered by WithDiagnosticsTools.
+const (
+	DiagnosticsEchoTool  = "mcp.echo"
+	DiagnosticsSleepTool = "mcp.sleep"
+	DiagnosticsFailTool  = "mcp.fail"
+)
+
+// maxDiagnosticsSleep bounds how long mcp.sleep may block.
+const maxDiagnosticsSleep = 5 * time.Minute
+
+// EchoInput is the input of the mcp.echo diagnostics tool.
+type EchoInput struct {
+	Message string `json:"message" jsonschema:"required,description=Message to echo back"`
+}
+
+// SleepInput is the input of the mcp.sleep diagnostics tool.
+type SleepInput struct {
+	DurationMS int `json:"duration_ms" jsonschema:"required,description=How long to sleep in milliseconds"`
+}
+
+// FailInput is the input of the mcp.fail diagnostics tool.
+type FailInput struct {
+	Message string `json:"message,omitempty" jsonschema:"description=Error message to return"`
+	Code    int    `json:"code,omitempty" jsonschema:"description=JSON-RPC error code to return"`
+}
+
+// WithDiagnosticsTools registers hidden tools for smoke testing a deployed
+// server:
+//   - mcp.echo returns its message, to check connectivity and authentication
+//   - mcp.sleep blocks for duration_ms, to check timeouts and cancellation
+//   - mcp.fail returns an error, to check error handling
+//
+// The tools can always be called, but they are left out of tools/list
+// unless debug mode is enabled with WithDebug.
+func WithDiagnosticsTools() Option {
+	return func(s *Server) {
+		s.diagnostics = true
+	}
+}
+
+// WithDebug enables debug mode, in which hidden tools such as the
+// diagnostics tools are included in tools/list.
+func WithDebug(enabled bool) Option {
+	return func(s *Server) {
+		s.debug = enabled
+	}
+}
+
+// Debug reports whether debug mode is enabled.
+func (s *Server) Debug() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.debug
+}
+
+// registerDiagnosticsTools registers the mcp.echo, mcp.sleep and mcp.fail tools.
+func (s *Server) registerDiagnosticsTools() {
+	s.Tool(DiagnosticsEchoTool).
+		Description("Echo a message back (diagnostics)").
+		Hidden().
+		Handler(func(input EchoInput) (string, error) {
+			return input.Message, nil
+		})
+
+	s.Tool(DiagnosticsSleepTool).
+		Description("Sleep for a duration (diagnostics)").
+		Hidden().
+		Handler(func(ctx context.Context, input SleepInput) (string, error) {
+			d := time.Duration(input.DurationMS) * time.Millisecond
+			if d < 0 || d > maxDiagnosticsSleep {
+				return "", protocol.NewInvalidParams(fmt.Sprintf("duration_ms must be between 0 and %d", maxDiagnosticsSleep.Milliseconds()))
+			}
+
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-timer.C:
+				return fmt.Sprintf("slept %dms", input.DurationMS), nil
+			}
+		})
+
+	s.Tool(DiagnosticsFailTool).
+		Description("Return an error (diagnostics)").
+		Hidden().
+		Handler(func(input FailInput) (string, error) {
+			msg := input.Message
+			if msg == "" {
+				msg = "diagnostics failure"
+			}
+			if input.Code != 0 {
+				return "", &protocol.Error{Code: input.Code, Message: msg}
+			}
+			return "", errors.New(msg)
+		})
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestWithDiagnosticsTools(t *testing.T) {
+	diagnostics := []string{DiagnosticsEchoTool, DiagnosticsFailTool, DiagnosticsSleepTool}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantListed []string
+	}{
+		{
+			name:       "disabled",
+			opts:       nil,
+			wantListed: []string{"app"},
+		},
+		{
+			name:       "hidden by default",
+			opts:       []Option{WithDiagnosticsTools()},
+			wantListed: []string{"app"},
+		},
+		{
+			name:       "listed in debug mode",
+			opts:       []Option{WithDiagnosticsTools(), WithDebug(true)},
+			wantListed: append([]string{"app"}, diagnostics...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"}, tt.opts...)
+			srv.Tool("app").Handler(func(input struct{}) (string, error) { return "", nil })
+
+			tools := srv.Tools()
+			if len(tools) != len(tt.wantListed) {
+				t.Fatalf("got %d tools, want %d", len(tools), len(tt.wantListed))
+			}
+			for i, name := range tt.wantListed {
+				if tools[i].Name != name {
+					t.Errorf("tools[%d] = %q, want %q", i, tools[i].Name, name)
+				}
+			}
+
+			// Diagnostics tools are callable whether or not they are listed
+			_, ok := srv.GetTool(DiagnosticsEchoTool)
+			if want := tt.opts != nil; ok != want {
+				t.Errorf("GetTool(%q) found = %v, want %v", DiagnosticsEchoTool, ok, want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsTools_Execute(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithDiagnosticsTools())
+
+	execute := func(ctx context.Context, name, input string) (any, error) {
+		t.Helper()
+		tool, ok := srv.GetTool(name)
+		if !ok {
+			t.Fatalf("tool %q not registered", name)
+		}
+		return tool.Execute(ctx, json.RawMessage(input))
+	}
+
+	t.Run("echo returns message", func(t *testing.T) {
+		result, err := execute(context.Background(), DiagnosticsEchoTool, `{"message":"hello"}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != "hello" {
+			t.Errorf("result = %v, want hello", result)
+		}
+	})
+
+	t.Run("sleep completes", func(t *testing.T) {
+		result, err := execute(context.Background(), DiagnosticsSleepTool, `{"duration_ms":1}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != "slept 1ms" {
+			t.Errorf("result = %v, want %q", result, "slept 1ms")
+		}
+	})
+
+	t.Run("sleep honors cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := execute(ctx, DiagnosticsSleepTool, `{"duration_ms":60000}`)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error = %v, want deadline exceeded", err)
+		}
+	})
+
+	t.Run("sleep rejects out of range durations", func(t *testing.T) {
+		_, err := execute(context.Background(), DiagnosticsSleepTool, `{"duration_ms":-1}`)
+		if !errors.Is(err, protocol.NewInvalidParams("")) {
+			t.Errorf("error = %v, want invalid params", err)
+		}
+	})
+
+	t.Run("fail returns plain error", func(t *testing.T) {
+		_, err := execute(context.Background(), DiagnosticsFailTool, `{}`)
+		if err == nil || err.Error() != "diagnostics failure" {
+			t.Errorf("error = %v, want diagnostics failure", err)
+		}
+	})
+
+	t.Run("fail returns protocol error with code", func(t *testing.T) {
+		_, err := execute(context.Background(), DiagnosticsFailTool, `{"message":"denied","code":-32002}`)
+		var protoErr *protocol.Error
+		if !errors.As(err, &protoErr) {
+			t.Fatalf("error = %v, want *protocol.Error", err)
+		}
+		if protoErr.Code != protocol.CodeUnauthorized || protoErr.Message != "denied" {
+			t.Errorf("error = %+v, want code %d message denied", protoErr, protocol.CodeUnauthorized)
+		}
+	})
+}
@@ -11,13 +11,11 @@
 //	srv := server.New(server.Info{
 //	    Name:    "my-server",
 //	    Version: "1.0.0",
-//	    Capabilities: server.Capabilities{
-//	        Tools:     true,
-//	        Resources: true,
-//	        Prompts:   true,
-//	    },
 //	})
 //
+// The capabilities advertised to clients are detected from what is
+// registered; WithCapabilities pins them explicitly.
+//
 // # Tools
 //
 // Tools are registered using the fluent builder API:
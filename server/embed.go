@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// EmbeddedResource represents resource contents embedded in a prompt
+// message or tool result.
+type EmbeddedResource struct {
+	Type     string           `json:"type"` // Always "resource"
+	Resource *ResourceContent `json:"resource"`
+}
+
+// ResourceLink represents a link to a resource in a prompt message or tool
+// result. The client reads the linked resource with resources/read.
+type ResourceLink struct {
+	Type        string `json:"type"` // Always "resource_link"
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// NewEmbeddedResource creates embedded resource content from the contents
+// of a resource.
+func NewEmbeddedResource(content *ResourceContent) EmbeddedResource {
+	return EmbeddedResource{Type: ContentTypeResource, Resource: content}
+}
+
+// NewResourceLink creates a link to the resource at uri.
+func NewResourceLink(uri, name, mimeType string) ResourceLink {
+	return ResourceLink{
+		Type:     ContentTypeResourceLink,
+		URI:      uri,
+		Name:     name,
+		MimeType: mimeType,
+	}
+}
+
+// EmbedResource reads the resource at uri, from the registered resources
+// or the resource providers, and returns its contents as embedded resource
+// content. It returns a not found error if nothing serves uri.
+//
+// Example:
+//
+//	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+//	    file, err := srv.EmbedResource(ctx, "file:///src/"+args["path"])
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &server.PromptResult{
+//	        Messages: []server.PromptMessage{
+//	            {Role: "user", Content: server.TextContent{Type: "text", Text: "Review this file:"}},
+//	            {Role: "user", Content: file},
+//	        },
+//	    }, nil
+//	})
+func (s *Server) EmbedResource(ctx context.Context, uri string) (EmbeddedResource, error) {
+	content, err := s.ReadResource(ctx, uri)
+	if err != nil {
+		return EmbeddedResource{}, err
+	}
+	if content == nil {
+		content = &ResourceContent{}
+	}
+	if content.URI == "" {
+		content.URI = uri
+	}
+	if content.MimeType == "" {
+		if resource, ok := s.FindResourceForURI(uri); ok {
+			content.MimeType = resource.mimeType
+		}
+	}
+	return NewEmbeddedResource(content), nil
+}
+
+// LinkResource returns a link to the registered resource matching uri,
+// using the name, description and MIME type the resource was registered
+// with. It returns a not found error if no registered resource matches uri.
+func (s *Server) LinkResource(uri string) (ResourceLink, error) {
+	resource, ok := s.FindResourceForURI(uri)
+	if !ok {
+		return ResourceLink{}, protocol.NewNotFound("resource not found: " + uri)
+	}
+
+	link := NewResourceLink(uri, resource.name, resource.mimeType)
+	link.Description = resource.description
+	return link, nil
+}
+
+// content converts the embedded resource to a tool result content block.
+func (e EmbeddedResource) content() protocol.Content {
+	c := protocol.Content{Type: ContentTypeResource}
+	if e.Resource != nil {
+		c.Resource = &protocol.ResourceContents{
+			URI:      e.Resource.URI,
+			MimeType: e.Resource.MimeType,
+			Text:     e.Resource.Text,
+			Blob:     e.Resource.Blob,
+		}
+	}
+	return c
+}
+
+// content converts the link to a tool result content block.
+func (l ResourceLink) content() protocol.Content {
+	return protocol.Content{
+		Type:        ContentTypeResourceLink,
+		URI:         l.URI,
+		Name:        l.Name,
+		Description: l.Description,
+		MimeType:    l.MimeType,
+	}
+}
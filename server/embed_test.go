@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func newEmbedTestServer() *Server {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	srv.Resource("file:///src/{path}").
+		Name("source").
+		Description("Source files").
+		MimeType("text/x-go").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			if params["path"] == "broken.go" {
+				return nil, errors.New("read failed")
+			}
+			return &ResourceContent{Text: "package " + params["path"]}, nil
+		})
+	return srv
+}
+
+func TestServer_EmbedResource(t *testing.T) {
+	srv := newEmbedTestServer()
+
+	t.Run("reads registered resource", func(t *testing.T) {
+		got, err := srv.EmbedResource(context.Background(), "file:///src/main.go")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := json.Marshal(got)
+		want := `{"type":"resource","resource":{"uri":"file:///src/main.go","mimeType":"text/x-go","text":"package main.go"}}`
+		if string(data) != want {
+			t.Errorf("EmbedResource() = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err := srv.EmbedResource(context.Background(), "file:///docs/readme.md")
+		var mcpErr *protocol.Error
+		if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeNotFound {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		_, err := srv.EmbedResource(context.Background(), "file:///src/broken.go")
+		if err == nil || err.Error() != "read failed" {
+			t.Errorf("expected read error, got %v", err)
+		}
+	})
+}
+
+func TestServer_LinkResource(t *testing.T) {
+	srv := newEmbedTestServer()
+
+	t.Run("links registered resource", func(t *testing.T) {
+		got, err := srv.LinkResource("file:///src/main.go")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := json.Marshal(got)
+		want := `{"type":"resource_link","uri":"file:///src/main.go","name":"source","description":"Source files","mimeType":"text/x-go"}`
+		if string(data) != want {
+			t.Errorf("LinkResource() = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err := srv.LinkResource("file:///docs/readme.md")
+		var mcpErr *protocol.Error
+		if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeNotFound {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestToolResult_LinkAndEmbed(t *testing.T) {
+	srv := newEmbedTestServer()
+
+	link, err := srv.LinkResource("file:///src/main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	embedded, err := srv.EmbedResource(context.Background(), "file:///src/util.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := json.Marshal(NewToolResult().Link(link).Embed(embedded).CallToolResult())
+	want := `{"content":[` +
+		`{"type":"resource_link","mimeType":"text/x-go","uri":"file:///src/main.go","name":"source","description":"Source files"},` +
+		`{"type":"resource","resource":{"uri":"file:///src/util.go","mimeType":"text/x-go","text":"package util.go"}}]}`
+	if string(data) != want {
+		t.Errorf("got %s\nwant %s", data, want)
+	}
+}
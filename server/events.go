@@ -0,0 +1,105 @@
+package server
+
+import "sync"
+
+// ListKind identifies a list that clients fetch and that the server
+// notifies them about when it changes.
+type ListKind string
+
+// List kinds.
+const (
+	ListKindTools     ListKind = "tools"
+	ListKindResources ListKind = "resources"
+	ListKindPrompts   ListKind = "prompts"
+)
+
+// OnListChanged registers fn to be called when a tool, resource, resource
+// provider or prompt is added or removed. Request handlers use it to send
+// list_changed notifications to their sessions. It returns a function that
+// removes fn.
+func (s *Server) OnListChanged(fn func(kind ListKind)) (remove func()) {
+	return s.listChanged.add(fn)
+}
+
+// RemoveTool removes the tool with the given name and reports whether it
+// was registered.
+func (s *Server) RemoveTool(name string) bool {
+	s.mu.Lock()
+	_, ok := s.tools[name]
+	delete(s.tools, name)
+	s.mu.Unlock()
+
+	if ok {
+		s.listChanged.emit(ListKindTools)
+	}
+	return ok
+}
+
+// RemoveResource removes the resource registered with uriTemplate and
+// reports whether it was registered.
+func (s *Server) RemoveResource(uriTemplate string) bool {
+	s.mu.Lock()
+	_, ok := s.resources[uriTemplate]
+	delete(s.resources, uriTemplate)
+	s.mu.Unlock()
+
+	if ok {
+		s.listChanged.emit(ListKindResources)
+	}
+	return ok
+}
+
+// RemovePrompt removes the prompt with the given name and reports whether
+// it was registered.
+func (s *Server) RemovePrompt(name string) bool {
+	s.mu.Lock()
+	_, ok := s.prompts[name]
+	delete(s.prompts, name)
+	s.mu.Unlock()
+
+	if ok {
+		s.listChanged.emit(ListKindPrompts)
+	}
+	return ok
+}
+
+// listeners is a set of event callbacks. The zero value is ready to use.
+type listeners[T any] struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func(T)
+}
+
+// add registers fn and returns a function that removes it.
+func (l *listeners[T]) add(fn func(T)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fns == nil {
+		l.fns = make(map[int]func(T))
+	}
+	id := l.next
+	l.next++
+	l.fns[id] = fn
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.fns, id)
+	}
+}
+
+// emit calls every registered callback with v. Callbacks run without the
+// lock held, so they may register or remove listeners.
+func (l *listeners[T]) emit(v T) {
+	l.mu.Lock()
+	fns := make([]func(T), 0, len(l.fns))
+	for _, fn := range l.fns {
+		fns = append(fns, fn)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(v)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestServer_OnListChanged(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	var got []ListKind
+	remove := srv.OnListChanged(func(kind ListKind) { got = append(got, kind) })
+
+	srv.Tool("greet").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "hi", nil
+	})
+	srv.Resource("config://app").Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{}, nil
+	})
+	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+		return &PromptResult{}, nil
+	})
+	srv.AddResourceProvider(&staticProvider{})
+
+	want := []ListKind{ListKindTools, ListKindResources, ListKindPrompts, ListKindResources}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		remove func() bool
+		want   bool
+		kind   ListKind
+	}{
+		{name: "tool", remove: func() bool { return srv.RemoveTool("greet") }, want: true, kind: ListKindTools},
+		{name: "missing tool", remove: func() bool { return srv.RemoveTool("greet") }},
+		{name: "resource", remove: func() bool { return srv.RemoveResource("config://app") }, want: true, kind: ListKindResources},
+		{name: "missing resource", remove: func() bool { return srv.RemoveResource("config://other") }},
+		{name: "prompt", remove: func() bool { return srv.RemovePrompt("review") }, want: true, kind: ListKindPrompts},
+		{name: "missing prompt", remove: func() bool { return srv.RemovePrompt("review") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			if ok := tt.remove(); ok != tt.want {
+				t.Fatalf("remove = %v, want %v", ok, tt.want)
+			}
+			if !tt.want {
+				if len(got) != 0 {
+					t.Errorf("events = %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0] != tt.kind {
+				t.Errorf("events = %v, want [%s]", got, tt.kind)
+			}
+		})
+	}
+
+	if _, ok := srv.GetTool("greet"); ok {
+		t.Error("removed tool is still registered")
+	}
+
+	got = nil
+	remove()
+	srv.Tool("again").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "", nil
+	})
+	if len(got) != 0 {
+		t.Errorf("removed listener got %v", got)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileStore is a SubscriptionStore, SessionStore and TaskStore that keeps
+// its state in a JSON file, so subscriptions, sessions and tasks survive a
+// restart. Like MemoryStore, it also implements transport.SessionStore.
+//
+// Every change rewrites the file, which makes FileStore suitable for a
+// single server instance with modest traffic. The file is not locked, so
+// it must not be shared by several processes; use a networked store such
+// as Redis for horizontally scaled deployments.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	mem  *MemoryStore
+}
+
+// fileStoreData is the on-disk format of a FileStore.
+type fileStoreData struct {
+	Subscriptions map[string][]string     `json:"subscriptions"` // URI -> session IDs
+	Sessions      map[string]SessionState `json:"sessions"`
+	Tasks         map[string]TaskInfo     `json:"tasks,omitempty"`
+}
+
+// NewFileStore opens the store at path, loading existing state if the file
+// exists. The file is created on the first change.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{
+		path: path,
+		mem:  NewMemoryStore(),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read store: %w", err)
+	}
+
+	var stored fileStoreData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode store %s: %w", path, err)
+	}
+	for uri, ids := range stored.Subscriptions {
+		for _, id := range ids {
+			_ = f.mem.Subscribe(context.Background(), id, uri)
+		}
+	}
+	for id, state := range stored.Sessions {
+		state.ID = id
+		_ = f.mem.SaveSession(context.Background(), state)
+	}
+	for id, task := range stored.Tasks {
+		task.ID = id
+		_ = f.mem.SaveTask(context.Background(), task)
+	}
+
+	return f, nil
+}
+
+// Subscribe adds a subscription.
+func (f *FileStore) Subscribe(ctx context.Context, sessionID, uri string) error {
+	return f.update(func() error { return f.mem.Subscribe(ctx, sessionID, uri) })
+}
+
+// Unsubscribe removes a subscription.
+func (f *FileStore) Unsubscribe(ctx context.Context, sessionID, uri string) error {
+	return f.update(func() error { return f.mem.Unsubscribe(ctx, sessionID, uri) })
+}
+
+// UnsubscribeAll removes all subscriptions of a session.
+func (f *FileStore) UnsubscribeAll(ctx context.Context, sessionID string) error {
+	return f.update(func() error { return f.mem.UnsubscribeAll(ctx, sessionID) })
+}
+
+// Subscribers returns the sessions subscribed to uri, sorted.
+func (f *FileStore) Subscribers(ctx context.Context, uri string) ([]string, error) {
+	return f.mem.Subscribers(ctx, uri)
+}
+
+// Count returns the total number of subscriptions.
+func (f *FileStore) Count(ctx context.Context) (int, error) {
+	return f.mem.Count(ctx)
+}
+
+// SaveSession stores the state of a session.
+func (f *FileStore) SaveSession(ctx context.Context, state SessionState) error {
+	return f.update(func() error { return f.mem.SaveSession(ctx, state) })
+}
+
+// LoadSession returns the state of a session.
+func (f *FileStore) LoadSession(ctx context.Context, id string) (SessionState, error) {
+	return f.mem.LoadSession(ctx, id)
+}
+
+// CreateSession records a new session with empty state, keeping the state
+// of an existing session.
+func (f *FileStore) CreateSession(ctx context.Context, id string) error {
+	return f.update(func() error { return f.mem.CreateSession(ctx, id) })
+}
+
+// SessionExists reports whether state is stored for a session.
+func (f *FileStore) SessionExists(ctx context.Context, id string) (bool, error) {
+	return f.mem.SessionExists(ctx, id)
+}
+
+// DeleteSession removes the state of a session.
+func (f *FileStore) DeleteSession(ctx context.Context, id string) error {
+	return f.update(func() error { return f.mem.DeleteSession(ctx, id) })
+}
+
+// SaveTask stores the state of a task.
+func (f *FileStore) SaveTask(ctx context.Context, task TaskInfo) error {
+	return f.update(func() error { return f.mem.SaveTask(ctx, task) })
+}
+
+// LoadTask returns the state of a task.
+func (f *FileStore) LoadTask(ctx context.Context, id string) (TaskInfo, error) {
+	return f.mem.LoadTask(ctx, id)
+}
+
+// DeleteTask removes a task.
+func (f *FileStore) DeleteTask(ctx context.Context, id string) error {
+	return f.update(func() error { return f.mem.DeleteTask(ctx, id) })
+}
+
+// update applies change to the in-memory state and writes it to disk.
+func (f *FileStore) update(change func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// write atomically replaces the file with the current state.
+func (f *FileStore) write() error {
+	data, err := json.MarshalIndent(f.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+	return nil
+}
+
+// snapshot copies the in-memory state into its on-disk format.
+func (f *FileStore) snapshot() fileStoreData {
+	m := f.mem
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored := fileStoreData{
+		Subscriptions: make(map[string][]string, len(m.subscriptions)),
+		Sessions:      make(map[string]SessionState, len(m.sessions)),
+	}
+	for uri, sessions := range m.subscriptions {
+		ids := make([]string, 0, len(sessions))
+		for id := range sessions {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		stored.Subscriptions[uri] = ids
+	}
+	for id, state := range m.sessions {
+		stored.Sessions[id] = state
+	}
+	if len(m.tasks) > 0 {
+		stored.Tasks = make(map[string]TaskInfo, len(m.tasks))
+		for id, task := range m.tasks {
+			stored.Tasks[id] = task
+		}
+	}
+	return stored
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// defaultFileSystemMaxSize limits the size of files read by
+// FileSystemProvider.
+const defaultFileSystemMaxSize = 10 << 20
+
+// FileSystemProvider serves the files under a directory as file://
+// resources with absolute paths, such as file:///home/me/project/go.mod.
+// Reading a directory returns its entries, one per line, with a trailing
+// slash for subdirectories. Paths that leave the directory, including
+// through symbolic links, are not served.
+type FileSystemProvider struct {
+	dir       string
+	root      string
+	fsys      fs.FS
+	watcher   FileWatcher
+	maxSize   int64
+	onlyRoots bool
+}
+
+// FileSystemOption configures a FileSystemProvider.
+type FileSystemOption func(*FileSystemProvider)
+
+// WithFileSystemFS serves the files of fsys, such as an embed.FS, at the
+// directory path given to NewFileSystemProvider instead of reading the
+// directory from disk.
+func WithFileSystemFS(fsys fs.FS) FileSystemOption {
+	return func(p *FileSystemProvider) {
+		p.fsys = fsys
+	}
+}
+
+// WithFileSystemMaxSize limits the size of files that can be read.
+// Defaults to 10 MB.
+func WithFileSystemMaxSize(n int64) FileSystemOption {
+	return func(p *FileSystemProvider) {
+		p.maxSize = n
+	}
+}
+
+// WithFileSystemWatcher sets the watcher Watch uses to report changed
+// files. Defaults to a watcher polling every second when the files are
+// read from disk; with WithFileSystemFS, changes are only watched if a
+// watcher is given, at the directory path on disk.
+func WithFileSystemWatcher(w FileWatcher) FileSystemOption {
+	return func(p *FileSystemProvider) {
+		p.watcher = w
+	}
+}
+
+// WithFileSystemClientRoots restricts the resources to the roots the
+// client reports, so a server only exposes the workspace the user opened.
+// Files outside every root are not listed and reading them fails with a
+// forbidden error. Clients without the roots capability see nothing.
+func WithFileSystemClientRoots() FileSystemOption {
+	return func(p *FileSystemProvider) {
+		p.onlyRoots = true
+	}
+}
+
+// NewFileSystemProvider creates a provider serving the files under dir.
+// It returns an error if dir is not a directory.
+func NewFileSystemProvider(dir string, opts ...FileSystemOption) (*FileSystemProvider, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", dir, err)
+	}
+	p := &FileSystemProvider{dir: filepath.ToSlash(abs), root: abs, maxSize: defaultFileSystemMaxSize}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if !strings.HasPrefix(p.dir, "/") {
+		// Windows drive paths become file:///C:/...
+		p.dir = "/" + p.dir
+	}
+
+	if p.fsys == nil {
+		root, err := os.OpenRoot(abs)
+		if err != nil {
+			return nil, err
+		}
+		p.fsys = root.FS()
+		if p.watcher == nil {
+			p.watcher = NewPollingWatcher(0)
+		}
+	}
+	info, err := fs.Stat(p.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return p, nil
+}
+
+// FileSystemResources serves the files under dir as file:// resources. See
+// FileSystemProvider.
+//
+// Example:
+//
+//	if err := srv.FileSystemResources("./workspace",
+//	    server.WithFileSystemClientRoots(),
+//	    server.WithFileSystemMaxSize(1<<20),
+//	); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Server) FileSystemResources(dir string, opts ...FileSystemOption) error {
+	p, err := NewFileSystemProvider(dir, opts...)
+	if err != nil {
+		return err
+	}
+	s.AddResourceProvider(p)
+	return nil
+}
+
+// List returns every regular file the client may read.
+func (p *FileSystemProvider) List(ctx context.Context) ([]ResourceEntry, error) {
+	roots, err := p.roots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ResourceEntry
+	err = fs.WalkDir(p.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !p.allowed(roots, name) {
+			return nil
+		}
+		entries = append(entries, ResourceEntry{
+			URI:      p.uri(name),
+			Name:     name,
+			MimeType: mimeTypeByExtension(name),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Read reads the file or lists the directory at uri.
+func (p *FileSystemProvider) Read(ctx context.Context, uri string) (*ResourceContent, error) {
+	name, ok := p.name(uri)
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	roots, err := p.roots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !p.allowed(roots, name) {
+		return nil, protocol.NewForbidden("outside the client's roots: " + uri)
+	}
+
+	info, err := fs.Stat(p.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return p.readDir(uri, name)
+	}
+	if info.Size() > p.maxSize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrResourceTooLarge, uri, p.maxSize)
+	}
+
+	f, err := p.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewReaderContent(uri, "", f, p.maxSize)
+}
+
+// Watch reports the URIs of files created, modified or removed until ctx
+// is done. It returns nil immediately if the provider has no watcher.
+func (p *FileSystemProvider) Watch(ctx context.Context, onChange func(uri string)) error {
+	if p.watcher == nil {
+		return nil
+	}
+	return p.watcher.Watch(ctx, []string{p.root}, func(changed string) {
+		rel, err := filepath.Rel(p.root, changed)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		onChange(p.uri(filepath.ToSlash(rel)))
+	})
+}
+
+// readDir lists the entries of a directory.
+func (p *FileSystemProvider) readDir(uri, name string) (*ResourceContent, error) {
+	entries, err := fs.ReadDir(p.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, e := range entries {
+		sb.WriteString(e.Name())
+		if e.IsDir() {
+			sb.WriteByte('/')
+		}
+		sb.WriteByte('\n')
+	}
+	return &ResourceContent{URI: uri, MimeType: "text/plain", Text: sb.String()}, nil
+}
+
+// uri returns the URI of the file at name.
+func (p *FileSystemProvider) uri(name string) string {
+	return (&url.URL{Scheme: "file", Path: path.Join(p.dir, name)}).String()
+}
+
+// name returns the path relative to the directory of a file:// URI. It
+// reports false for URIs outside the directory.
+func (p *FileSystemProvider) name(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	if u.Path == p.dir || u.Path == p.dir+"/" {
+		return ".", true
+	}
+	rest, ok := strings.CutPrefix(u.Path, p.dir+"/")
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if !fs.ValidPath(rest) {
+		return "", false
+	}
+	return rest, true
+}
+
+// roots returns the paths of the client's roots when the provider is
+// restricted to them, fetching them from the client if none are cached.
+// It returns nil if the provider is not restricted.
+func (p *FileSystemProvider) roots(ctx context.Context) ([]string, error) {
+	if !p.onlyRoots {
+		return nil, nil
+	}
+	session := SessionFromContext(ctx)
+	if session == nil || !session.SupportsFeature("roots") {
+		return []string{}, nil
+	}
+
+	roots := session.Roots()
+	if roots == nil {
+		result, err := session.ListRoots(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list client roots: %w", err)
+		}
+		roots = result.Roots
+	}
+
+	paths := make([]string, 0, len(roots))
+	for _, r := range roots {
+		if u, err := url.Parse(r.URI); err == nil && u.Scheme == "file" {
+			paths = append(paths, strings.TrimSuffix(u.Path, "/"))
+		}
+	}
+	return paths, nil
+}
+
+// allowed reports whether the file at name lies within one of roots. A nil
+// roots allows everything.
+func (p *FileSystemProvider) allowed(roots []string, name string) bool {
+	if roots == nil {
+		return true
+	}
+	full := path.Join(p.dir, name)
+	return slices.ContainsFunc(roots, func(root string) bool {
+		return full == root || strings.HasPrefix(full, root+"/") || root == ""
+	})
+}
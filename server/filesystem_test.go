@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// fileURI returns the file:// URI of a path on disk.
+func fileURI(p string) string {
+	p = filepath.ToSlash(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+func newWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"README.md":        "# Project",
+		"src/main.go":      "package main",
+		"src/util/util.go": "package util",
+		"logo.bin":         "\x89PNG\r\n\x1a\n\x00\x00",
+		"notes":            "plain notes",
+		"big file.txt":     strings.Repeat("x", 64),
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFileSystemProvider(t *testing.T) {
+	dir := newWorkspace(t)
+	p, err := NewFileSystemProvider(dir, WithFileSystemMaxSize(32))
+	if err != nil {
+		t.Fatalf("NewFileSystemProvider() error = %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("lists files", func(t *testing.T) {
+		entries, err := p.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(entries) != 6 {
+			t.Fatalf("List() returned %d entries, want 6: %+v", len(entries), entries)
+		}
+		for _, e := range entries {
+			if e.URI != fileURI(filepath.Join(dir, filepath.FromSlash(e.Name))) {
+				t.Errorf("URI = %q for %q", e.URI, e.Name)
+			}
+		}
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		wantText string
+		wantMime string
+		wantBlob bool
+		wantErr  error
+	}{
+		{name: "markdown", path: "README.md", wantText: "# Project", wantMime: "text/markdown"},
+		{name: "nested", path: "src/util/util.go", wantText: "package util"},
+		{name: "sniffed binary", path: "logo.bin", wantMime: "image/png", wantBlob: true},
+		{name: "no extension", path: "notes", wantText: "plain notes", wantMime: "text/plain"},
+		{name: "directory", path: "src", wantText: "main.go\nutil/\n", wantMime: "text/plain"},
+		{name: "root directory", path: "", wantText: "README.md\nbig file.txt\nlogo.bin\nnotes\nsrc/\n"},
+		{name: "missing", path: "missing.txt", wantErr: ErrResourceNotFound},
+		{name: "traversal", path: "src/../../etc/passwd", wantErr: ErrResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := fileURI(dir) + "/" + tt.path
+			content, err := p.Read(ctx, uri)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if tt.wantText != "" && content.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", content.Text, tt.wantText)
+			}
+			if tt.wantMime != "" && !strings.HasPrefix(content.MimeType, tt.wantMime) {
+				t.Errorf("MimeType = %q, want %q", content.MimeType, tt.wantMime)
+			}
+			if tt.wantBlob {
+				if _, err := base64.StdEncoding.DecodeString(content.Blob); err != nil || content.Blob == "" {
+					t.Errorf("Blob = %q, want base64 data", content.Blob)
+				}
+			}
+		})
+	}
+
+	t.Run("size limit", func(t *testing.T) {
+		_, err := p.Read(ctx, fileURI(filepath.Join(dir, "big file.txt")))
+		if !errors.Is(err, ErrResourceTooLarge) {
+			t.Errorf("Read() error = %v, want size limit error", err)
+		}
+	})
+
+	t.Run("other URIs", func(t *testing.T) {
+		for _, uri := range []string{"docs://README.md", fileURI(filepath.Dir(dir)) + "/other/README.md", "file://host" + fileURI(dir)[7:] + "/README.md"} {
+			if _, err := p.Read(ctx, uri); !errors.Is(err, ErrResourceNotFound) {
+				t.Errorf("Read(%q) error = %v, want ErrResourceNotFound", uri, err)
+			}
+		}
+	})
+
+	t.Run("symlink escaping the directory", func(t *testing.T) {
+		outside := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "link.txt")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		defer os.Remove(filepath.Join(dir, "link.txt"))
+
+		content, err := p.Read(ctx, fileURI(filepath.Join(dir, "link.txt")))
+		if err == nil {
+			t.Errorf("Read() = %q, want error for a link leaving the directory", content.Text)
+		}
+	})
+}
+
+func TestFileSystemProvider_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"guide/intro.md": {Data: []byte("# Intro")},
+	}
+	p, err := NewFileSystemProvider("/srv/docs", WithFileSystemFS(fsys))
+	if err != nil {
+		t.Fatalf("NewFileSystemProvider() error = %v", err)
+	}
+
+	base := fileURI(filepath.Join(string(filepath.Separator), "srv", "docs"))
+	content, err := p.Read(context.Background(), base+"/guide/intro.md")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if content.Text != "# Intro" {
+		t.Errorf("Text = %q, want %q", content.Text, "# Intro")
+	}
+}
+
+func TestFileSystemProvider_ClientRoots(t *testing.T) {
+	dir := newWorkspace(t)
+	p, err := NewFileSystemProvider(dir, WithFileSystemClientRoots())
+	if err != nil {
+		t.Fatalf("NewFileSystemProvider() error = %v", err)
+	}
+
+	rootsResponse := func(uris ...string) *protocol.Response {
+		roots := make([]any, 0, len(uris))
+		for _, uri := range uris {
+			roots = append(roots, map[string]any{"uri": uri})
+		}
+		return &protocol.Response{JSONRPC: "2.0", ID: json.RawMessage(`1`), Result: map[string]any{"roots": roots}}
+	}
+	sessionCtx := func(caps ClientCapabilities, responses ...*protocol.Response) context.Context {
+		session := NewSession("s", &mockRequestSender{responses: responses}, &mockNotificationSender{}, WithClientCapabilities(caps))
+		return ContextWithSession(context.Background(), session)
+	}
+	withRoots := ClientCapabilities{Roots: &RootsCapability{}}
+
+	t.Run("only files within roots", func(t *testing.T) {
+		ctx := sessionCtx(withRoots, rootsResponse(fileURI(filepath.Join(dir, "src"))))
+
+		entries, err := p.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		if strings.Join(names, ",") != "src/main.go,src/util/util.go" {
+			t.Errorf("List() = %v, want the files under src", names)
+		}
+
+		if _, err := p.Read(ctx, fileURI(filepath.Join(dir, "src", "main.go"))); err != nil {
+			t.Errorf("Read() within roots error = %v", err)
+		}
+		_, err = p.Read(ctx, fileURI(filepath.Join(dir, "README.md")))
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeForbidden}) {
+			t.Errorf("Read() outside roots error = %v, want forbidden", err)
+		}
+	})
+
+	t.Run("root prefix is not a parent", func(t *testing.T) {
+		ctx := sessionCtx(withRoots, rootsResponse(fileURI(filepath.Join(dir, "sr"))))
+
+		_, err := p.Read(ctx, fileURI(filepath.Join(dir, "src", "main.go")))
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeForbidden}) {
+			t.Errorf("Read() error = %v, want forbidden", err)
+		}
+	})
+
+	t.Run("cached roots", func(t *testing.T) {
+		session := NewSession("s", &mockRequestSender{}, &mockNotificationSender{}, WithClientCapabilities(withRoots))
+		session.HandleRootsChanged([]Root{{URI: fileURI(dir)}})
+		ctx := ContextWithSession(context.Background(), session)
+
+		if _, err := p.Read(ctx, fileURI(filepath.Join(dir, "README.md"))); err != nil {
+			t.Errorf("Read() error = %v", err)
+		}
+	})
+
+	t.Run("client without roots", func(t *testing.T) {
+		for name, ctx := range map[string]context.Context{
+			"no session":    context.Background(),
+			"no capability": sessionCtx(ClientCapabilities{}),
+		} {
+			entries, err := p.List(ctx)
+			if err != nil || len(entries) != 0 {
+				t.Errorf("%s: List() = %v, %v, want nothing", name, entries, err)
+			}
+			if _, err := p.Read(ctx, fileURI(filepath.Join(dir, "README.md"))); !errors.Is(err, &protocol.Error{Code: protocol.CodeForbidden}) {
+				t.Errorf("%s: Read() error = %v, want forbidden", name, err)
+			}
+		}
+	})
+}
+
+func TestServer_FileSystemResources(t *testing.T) {
+	dir := newWorkspace(t)
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	if err := srv.FileSystemResources(dir); err != nil {
+		t.Fatalf("FileSystemResources() error = %v", err)
+	}
+	content, err := srv.ReadResource(context.Background(), fileURI(path.Join(filepath.ToSlash(dir), "src/main.go")))
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if content.Text != "package main" {
+		t.Errorf("Text = %q, want %q", content.Text, "package main")
+	}
+
+	if err := srv.FileSystemResources(filepath.Join(dir, "README.md")); err == nil {
+		t.Error("FileSystemResources() of a file succeeded, want error")
+	}
+	if err := srv.FileSystemResources(filepath.Join(dir, "missing")); err == nil {
+		t.Error("FileSystemResources() of a missing directory succeeded, want error")
+	}
+}
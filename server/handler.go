@@ -21,3 +21,63 @@ func Chain(middlewares ...Middleware) Middleware {
 		return final
 	}
 }
+
+// Method registers fn as the handler of a custom JSON-RPC method, so a
+// server can expose vendor extensions without forking the request handler.
+// Prefix custom methods with a namespace you own to avoid clashes with
+// future spec methods. The methods of the MCP spec are always handled by
+// the server and cannot be replaced; methods that are neither built in nor
+// registered still go to the OnUnknownMethod handler, or fail with a method
+// not found error. Registering a method again replaces its handler, and a
+// nil fn removes it.
+//
+// fn also receives notifications sent with the method; their response is
+// discarded.
+//
+// Example:
+//
+//	srv.Method("x-myorg/reindex", func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+//	    var params struct{ Index string `json:"index"` }
+//	    if err := json.Unmarshal(req.Params, &params); err != nil {
+//	        return nil, protocol.NewInvalidParams(err.Error())
+//	    }
+//	    return protocol.NewResponse(req.ID, map[string]any{"queued": params.Index}), nil
+//	})
+func (s *Server) Method(name string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fn == nil {
+		delete(s.methods, name)
+		return
+	}
+	s.methods[name] = fn
+}
+
+// MethodHandler returns the handler registered with Method for a custom
+// method.
+func (s *Server) MethodHandler(name string) (HandlerFunc, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn, ok := s.methods[name]
+	return fn, ok
+}
+
+// OnUnknownMethod sets the handler for requests and notifications whose
+// method the server does not implement, replacing the default method not
+// found error. Gateways use it to forward methods upstream:
+//
+//	srv.OnUnknownMethod(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+//	    return upstream.Forward(ctx, req)
+//	})
+func (s *Server) OnUnknownMethod(fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unknownMethod = fn
+}
+
+// UnknownMethodHandler returns the handler set with OnUnknownMethod, or nil.
+func (s *Server) UnknownMethodHandler() HandlerFunc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unknownMethod
+}
@@ -0,0 +1,841 @@
+// Package handler serves the MCP methods of a server.Server over any
+// transport. It is the request handler behind the mcp package's Serve
+// functions and the testutil TestClient; use it directly to run a server
+// on a custom transport:
+//
+//	h := handler.New(srv, handler.WithMiddleware(middleware.Recover()))
+//	err := transport.NewStdio().Serve(ctx, h)
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/middleware"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+// Option configures a Handler.
+type Option func(*config)
+
+type config struct {
+	middleware []middleware.Middleware
+}
+
+// WithMiddleware adds middleware to the request handling chain. The first
+// middleware runs first.
+func WithMiddleware(m ...middleware.Middleware) Option {
+	return func(c *config) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+// Handler serves the MCP methods of a server. It implements
+// transport.Handler and transport.ConnectionObserver, keeping a session for
+// each connection of transports that can send requests to the client.
+type Handler struct {
+	srv        *server.Server
+	handleFunc middleware.HandlerFunc
+
+	// Sessions of connections that support server-initiated requests
+	sessionsMu sync.Mutex
+	sessions   map[string]*server.Session
+	keepAlives map[string]context.CancelFunc
+	// Connections watched for the idle timeout
+	idleWatches map[string]func()
+}
+
+// New creates the handler for srv. It subscribes to the resource and list
+// change events of srv to notify the sessions it serves, so create one
+// handler per server and share it between transports.
+func New(srv *server.Server, opts ...Option) *Handler {
+	options := &config{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	h := &Handler{
+		srv:         srv,
+		sessions:    make(map[string]*server.Session),
+		keepAlives:  make(map[string]context.CancelFunc),
+		idleWatches: make(map[string]func()),
+	}
+
+	// Build the handler function
+	baseHandler := middleware.HandlerFunc(h.handle)
+
+	// Apply middleware if any
+	if len(options.middleware) > 0 {
+		h.handleFunc = middleware.Chain(options.middleware...)(baseHandler)
+	} else {
+		h.handleFunc = baseHandler
+	}
+
+	srv.OnResourceUpdated(h.notifyResourceUpdated)
+	srv.OnListChanged(h.notifyListChanged)
+
+	return h
+}
+
+// HandleRequest dispatches a request through the middleware chain.
+func (h *Handler) HandleRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	resp, err := h.handleFunc(h.withClientInfo(ctx), req)
+	if err != nil {
+		h.srv.HookError(ctx, server.ErrorEvent{Method: req.Method, Err: err})
+	}
+	return resp, err
+}
+
+// ConnectionClosed releases per-connection lifecycle state and sessions.
+func (h *Handler) ConnectionClosed(id string) {
+	h.srv.ForgetConnection(id)
+	h.sessionsMu.Lock()
+	stop, watched := h.idleWatches[id]
+	delete(h.idleWatches, id)
+	h.sessionsMu.Unlock()
+	if watched {
+		stop()
+	}
+
+	if session := h.removeSession(id); session != nil {
+		_ = session.Close(context.Background())
+	}
+}
+
+// withClientInfo attaches the client of the connection of ctx, if it is
+// initialized.
+func (h *Handler) withClientInfo(ctx context.Context) context.Context {
+	if info, ok := h.srv.ClientInfo(transport.ConnectionIDFromContext(ctx)); ok {
+		return server.ContextWithClientInfo(ctx, info)
+	}
+	return ctx
+}
+
+// removeSession forgets the session of a connection and stops pinging it.
+// It returns the session, or nil if none.
+func (h *Handler) removeSession(connID string) *server.Session {
+	h.sessionsMu.Lock()
+	session := h.sessions[connID]
+	delete(h.sessions, connID)
+	if stop, ok := h.keepAlives[connID]; ok {
+		stop()
+		delete(h.keepAlives, connID)
+	}
+	h.sessionsMu.Unlock()
+
+	if session != nil {
+		h.srv.RemoveSession(context.Background(), session)
+	}
+	return session
+}
+
+// startSession creates the session for a connection being initialized.
+// Sessions require a transport that can send requests to the client; on
+// other transports only the state of the session is stored, if a session
+// store is configured, so other server instances can resume it.
+func (h *Handler) startSession(ctx context.Context, connID string, req *protocol.Request) {
+	if connID == "" {
+		return
+	}
+
+	var params protocol.InitializeParams
+	if len(req.Params) > 0 {
+		_ = json.Unmarshal(req.Params, &params)
+	}
+
+	caps := server.ClientCapabilities{
+		Sampling: params.Capabilities.Sampling != nil,
+	}
+	if sampling := params.Capabilities.Sampling; sampling != nil {
+		caps.SamplingTools = sampling.Tools != nil
+	}
+	if roots := params.Capabilities.Roots; roots != nil {
+		caps.Roots = &server.RootsCapability{ListChanged: roots.ListChanged}
+	}
+	info := server.ClientInfo{
+		Name:            params.ClientInfo.Name,
+		Version:         params.ClientInfo.Version,
+		ProtocolVersion: params.ProtocolVersion,
+		Capabilities:    caps,
+	}
+	h.srv.SetClientInfo(connID, info)
+
+	sender := transport.RequestSenderFromContext(ctx)
+	notifier := transport.NotificationSenderFromContext(ctx)
+	store := h.srv.SessionStore()
+	if sender == nil || notifier == nil {
+		if store != nil {
+			_ = store.SaveSession(ctx, server.SessionState{ID: connID, LogLevel: server.LogLevelInfo, ClientCapabilities: caps, ClientInfo: &info})
+		}
+		return
+	}
+
+	opts := []server.SessionOption{
+		server.WithClientInfo(info),
+		server.WithSessionSubscriptions(h.srv.SubscriptionStore()),
+	}
+	if store != nil {
+		opts = append(opts, server.WithSessionStateStore(store))
+	}
+	session := server.NewSession(connID, sender, notifier, opts...)
+	_ = session.Save(ctx)
+	h.addSession(ctx, connID, session, sender)
+}
+
+// resumeSession resumes a session that was initialized on another server
+// instance, as recorded in the session store, when its first request
+// reaches this one. The connection skips the initialize handshake and,
+// on transports that can reach the client, gets a session restored from
+// the stored state.
+func (h *Handler) resumeSession(ctx context.Context, connID string) {
+	store := h.srv.SessionStore()
+	if connID == "" || store == nil || h.srv.LifecycleState(connID) != server.StateUninitialized {
+		return
+	}
+	state, err := store.LoadSession(ctx, connID)
+	if err != nil {
+		return
+	}
+	h.srv.ResumeConnection(connID)
+	if state.ClientInfo != nil {
+		info := *state.ClientInfo
+		info.Capabilities = state.ClientCapabilities
+		h.srv.SetClientInfo(connID, info)
+	}
+	h.watchIdle(ctx, connID)
+
+	sender := transport.RequestSenderFromContext(ctx)
+	notifier := transport.NotificationSenderFromContext(ctx)
+	if sender == nil || notifier == nil || h.Session(connID) != nil {
+		return
+	}
+	session := server.NewSession(connID, sender, notifier,
+		server.WithSessionSubscriptions(h.srv.SubscriptionStore()),
+		server.WithSessionStateStore(store),
+	)
+	h.addSession(ctx, connID, session, sender)
+}
+
+// addSession registers the session of a connection, replacing any previous
+// one, and starts pinging it on network transports.
+func (h *Handler) addSession(ctx context.Context, connID string, session *server.Session, sender transport.RequestSender) {
+	h.sessionsMu.Lock()
+	replaced := h.sessions[connID]
+	h.sessions[connID] = session
+	if stop, ok := h.keepAlives[connID]; ok {
+		stop()
+		delete(h.keepAlives, connID)
+	}
+	if transport.TransportFromContext(ctx) != transport.TransportStdio && h.srv.PingInterval() > 0 {
+		keepAliveCtx, stop := context.WithCancel(context.Background())
+		h.keepAlives[connID] = stop
+		go h.keepAlive(keepAliveCtx, connID, session, sender)
+	}
+	h.sessionsMu.Unlock()
+
+	if replaced != nil {
+		h.srv.RemoveSession(ctx, replaced)
+	}
+	h.srv.AddSession(ctx, session)
+}
+
+// keepAlive pings a session until it is removed, and closes its
+// connection if it stops answering. Transports that can't close a
+// connection from the server side only lose the session, so the client
+// must initialize again.
+func (h *Handler) keepAlive(ctx context.Context, connID string, session *server.Session, sender transport.RequestSender) {
+	if err := h.srv.KeepAlive(ctx, connID, session.Ping); !errors.Is(err, server.ErrSessionUnresponsive) {
+		return
+	}
+	if closer, ok := sender.(transport.ConnectionCloser); ok {
+		_ = closer.CloseConnection()
+		return
+	}
+	h.ConnectionClosed(connID)
+}
+
+// watchIdle closes the connection once its client has been idle for the
+// idle timeout of the server. Stdio connections last as long as the
+// process and are never closed.
+func (h *Handler) watchIdle(ctx context.Context, connID string) {
+	if connID == "" || transport.TransportFromContext(ctx) == transport.TransportStdio {
+		return
+	}
+	closer, _ := transport.RequestSenderFromContext(ctx).(transport.ConnectionCloser)
+	stop := h.srv.WatchIdle(connID, func(lastActive time.Time) {
+		h.expireIdle(connID, closer, lastActive)
+	})
+
+	h.sessionsMu.Lock()
+	prev, watched := h.idleWatches[connID]
+	h.idleWatches[connID] = stop
+	h.sessionsMu.Unlock()
+	if watched {
+		prev()
+	}
+}
+
+// expireIdle closes an idle connection: it runs the OnSessionExpired
+// hooks, deletes the stored session state so other instances don't resume
+// it, and releases the session.
+func (h *Handler) expireIdle(connID string, closer transport.ConnectionCloser, lastActive time.Time) {
+	ctx := context.Background()
+	h.srv.HookSessionExpired(ctx, server.SessionExpiredEvent{
+		ID:         connID,
+		Session:    h.Session(connID),
+		LastActive: lastActive,
+	})
+	if store := h.srv.SessionStore(); store != nil {
+		_ = store.DeleteSession(ctx, connID)
+	}
+	if closer != nil {
+		_ = closer.CloseConnection()
+	}
+	h.ConnectionClosed(connID)
+}
+
+// admitSession enforces the per-identity session limit on network
+// transports and closes the sessions it evicts.
+func (h *Handler) admitSession(ctx context.Context, connID string) error {
+	identity := middleware.IdentityFromContext(ctx)
+	if identity == nil || transport.TransportFromContext(ctx) == transport.TransportStdio {
+		return nil
+	}
+
+	evicted, err := h.srv.AdmitSession(identity.ID, connID)
+	if err != nil {
+		return err
+	}
+	for _, id := range evicted {
+		if session := h.removeSession(id); session != nil {
+			_ = session.Close(ctx)
+		}
+	}
+	return nil
+}
+
+// Session returns the session of a connection, or nil if none.
+func (h *Handler) Session(connID string) *server.Session {
+	h.sessionsMu.Lock()
+	defer h.sessionsMu.Unlock()
+	return h.sessions[connID]
+}
+
+// notifyResourceUpdated sends a resource updated notification to the
+// sessions of this handler subscribed to uri.
+func (h *Handler) notifyResourceUpdated(uri string) {
+	subscribers, err := h.srv.SubscriptionStore().Subscribers(context.Background(), uri)
+	if err != nil {
+		return
+	}
+	for _, id := range subscribers {
+		if session := h.Session(id); session != nil {
+			_ = session.NotifyResourceUpdated(uri)
+		}
+	}
+}
+
+// notifyListChanged sends a list changed notification to every session of
+// this handler.
+func (h *Handler) notifyListChanged(kind server.ListKind) {
+	h.sessionsMu.Lock()
+	sessions := make([]*server.Session, 0, len(h.sessions))
+	for _, session := range h.sessions {
+		sessions = append(sessions, session)
+	}
+	h.sessionsMu.Unlock()
+
+	for _, session := range sessions {
+		switch kind {
+		case server.ListKindTools:
+			_ = session.NotifyToolListChanged()
+		case server.ListKindResources:
+			_ = session.NotifyResourceListChanged()
+		case server.ListKindPrompts:
+			_ = session.NotifyPromptListChanged()
+		}
+	}
+}
+
+// handleRootsChanged refreshes the cached roots after the client reports a change.
+func (h *Handler) handleRootsChanged(ctx context.Context) {
+	session := server.SessionFromContext(ctx)
+	if session == nil || !session.SupportsFeature("roots") {
+		return
+	}
+	result, err := session.ListRoots(ctx)
+	if err != nil {
+		return
+	}
+	session.HandleRootsChanged(result.Roots)
+}
+
+// checkCompliance checks req against the lifecycle of its connection.
+// HTTP requests sent without a session ID are outside any session and are
+// checked as such rather than exempted.
+func (h *Handler) checkCompliance(ctx context.Context, connID string, req *protocol.Request) error {
+	if connID == "" && transport.TransportFromContext(ctx) == transport.TransportHTTP {
+		return h.srv.CheckSessionlessCompliance(req)
+	}
+	return h.srv.CheckClientCompliance(connID, req)
+}
+
+func (h *Handler) handle(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	connID := transport.ConnectionIDFromContext(ctx)
+	if req.Method != protocol.MethodInitialize {
+		h.resumeSession(ctx, connID)
+	}
+	if err := h.checkCompliance(ctx, connID, req); err != nil {
+		return nil, err
+	}
+	h.srv.SessionSeen(connID)
+	defer h.srv.SessionActive(connID)()
+
+	if req.Method == protocol.MethodInitialize {
+		if err := h.admitSession(ctx, connID); err != nil {
+			return nil, err
+		}
+		h.startSession(ctx, connID, req)
+		h.watchIdle(ctx, connID)
+	}
+	if session := h.Session(connID); session != nil {
+		ctx = server.ContextWithSession(ctx, session)
+	}
+	ctx = h.withClientInfo(ctx)
+
+	switch req.Method {
+	case protocol.MethodInitialize:
+		return h.handleInitialize(ctx, req)
+	case protocol.MethodToolsList:
+		return h.handleToolsList(req)
+	case protocol.MethodToolsCall:
+		return h.handleToolsCall(ctx, req)
+	case protocol.MethodResourcesList:
+		return h.handleResourcesList(ctx, req)
+	case protocol.MethodResourcesRead:
+		return h.handleResourcesRead(ctx, req)
+	case protocol.MethodResourcesTemplatesList:
+		return h.handleResourceTemplatesList(req)
+	case protocol.MethodResourcesSubscribe:
+		return h.handleResourcesSubscribe(ctx, req)
+	case protocol.MethodResourcesUnsubscribe:
+		return h.handleResourcesUnsubscribe(ctx, req)
+	case protocol.MethodPromptsList:
+		return h.handlePromptsList(req)
+	case protocol.MethodPromptsGet:
+		return h.handlePromptsGet(ctx, req)
+	case protocol.MethodPing:
+		return h.handlePing(req)
+	case protocol.MethodInitialized:
+		return nil, nil
+	case protocol.MethodRootsListChanged:
+		h.handleRootsChanged(ctx)
+		return nil, nil
+	case protocol.MethodCancelled:
+		// Transports cancel the referenced request before it reaches here;
+		// the request may have started a task that outlives it
+		var params server.CancelledNotification
+		if json.Unmarshal(req.Params, &params) == nil {
+			h.srv.CancelTaskRequest(transport.ConnectionIDFromContext(ctx), params.RequestID)
+		}
+		return nil, nil
+	default:
+		if fn, ok := h.srv.MethodHandler(req.Method); ok {
+			if req.IsNotification() {
+				_, _ = fn(ctx, req)
+				return nil, nil
+			}
+			return fn(ctx, req)
+		}
+		if fallback := h.srv.UnknownMethodHandler(); fallback != nil {
+			return fallback(ctx, req)
+		}
+		return nil, protocol.NewMethodNotFound(req.Method)
+	}
+}
+
+func (h *Handler) handleInitialize(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	manifest := h.srv.Manifest()
+
+	// Subscriptions and list changes need a session to deliver
+	// notifications to
+	notifies := server.SessionFromContext(ctx) != nil
+
+	// Build capabilities based on what's registered
+	var capabilities protocol.ServerCapabilities
+	if manifest.Capabilities.Tools {
+		capabilities.Tools = &protocol.ToolsCapability{ListChanged: notifies}
+	}
+	if manifest.Capabilities.Resources {
+		capabilities.Resources = &protocol.ResourcesCapability{
+			Subscribe:   notifies,
+			ListChanged: notifies,
+		}
+	}
+	if manifest.Capabilities.Prompts {
+		capabilities.Prompts = &protocol.PromptsCapability{ListChanged: notifies}
+	}
+
+	result := protocol.InitializeResult{
+		ProtocolVersion: manifest.ProtocolVersion,
+		ServerInfo: protocol.Implementation{
+			Name:    manifest.Name,
+			Version: manifest.Version,
+		},
+		Capabilities: capabilities,
+		Instructions: h.srv.Instructions(),
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handleToolsList(req *protocol.Request) (*protocol.Response, error) {
+	cursor, err := parseCursor(req)
+	if err != nil {
+		return nil, err
+	}
+	tools, nextCursor, err := h.srv.ToolsPage(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.ToolsListResult{
+		Tools:      make([]protocol.Tool, 0, len(tools)),
+		NextCursor: nextCursor,
+	}
+	for _, t := range tools {
+		result.Tools = append(result.Tools, t.ListItem())
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handleToolsCall(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	// Parse params
+	var params protocol.CallToolParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, protocol.NewInvalidParams(err.Error())
+	}
+
+	// Get tool
+	tool, ok := h.srv.GetTool(params.Name)
+	if !ok {
+		return nil, protocol.NewNotFound("tool not found: " + params.Name)
+	}
+	ctx = server.ContextWithRequestID(ctx, transport.ConnectionIDFromContext(ctx), req.ID)
+
+	// Set up progress reporting if token is present
+	progressToken := server.ExtractProgressToken(req.Params)
+	if progressToken != "" {
+		if sender := transport.NotificationSenderFromContext(ctx); sender != nil {
+			// Adapt transport.NotificationSender to server.NotificationSender
+			reporter := server.NewProgressReporter(progressToken, &notificationAdapter{sender})
+			ctx = server.ContextWithProgress(ctx, reporter)
+		}
+	}
+
+	call := server.ToolCallEvent{Name: params.Name, Arguments: params.Arguments}
+	if err := h.srv.HookToolCall(ctx, call); err != nil {
+		return nil, toProtocolError(err)
+	}
+
+	// Execute tool, collecting the _meta it sets
+	ctx = server.ContextWithResultMeta(ctx)
+	start := time.Now()
+	result, err := tool.Execute(ctx, params.Arguments)
+	h.srv.HookToolResult(ctx, server.ToolResultEvent{
+		Name:      call.Name,
+		Arguments: call.Arguments,
+		Result:    result,
+		Err:       err,
+		Duration:  time.Since(start),
+	})
+	if toolErr, ok := server.AsToolError(err); ok {
+		// Execution failures are results the model can see
+		return protocol.NewResponse(req.ID, protocol.CallToolResult{
+			Content: []protocol.Content{{Type: "text", Text: toolErr.Message}},
+			IsError: true,
+			Meta:    server.ResultMetaFromContext(ctx),
+		}), nil
+	}
+	if err != nil {
+		return nil, toProtocolError(err)
+	}
+	if err := h.srv.CheckToolOutput(ctx, tool, result); err != nil {
+		return nil, err
+	}
+
+	if rich, ok := result.(*server.ToolResult); ok && rich != nil {
+		if err := rich.Err(); err != nil {
+			return nil, protocol.NewInternalError(err.Error())
+		}
+		response := rich.CallToolResult()
+		response.Meta = server.MergeResultMeta(ctx, response.Meta)
+		return protocol.NewResponse(req.ID, response), nil
+	}
+
+	text, err := toolResultText(result)
+	if err != nil {
+		return nil, protocol.NewInternalError(err.Error())
+	}
+
+	// Format result
+	response := protocol.CallToolResult{
+		Content: []protocol.Content{
+			{Type: "text", Text: text},
+		},
+		Meta: server.ResultMetaFromContext(ctx),
+	}
+	if tool.OutputSchema() != nil {
+		response.StructuredContent = result
+	}
+
+	return protocol.NewResponse(req.ID, response), nil
+}
+
+// toolResultText renders a tool handler result as text content.
+// Strings are used as-is; other values are encoded as JSON.
+func toolResultText(result any) (string, error) {
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("encode tool result: %w", err)
+	}
+	return string(data), nil
+}
+
+func (h *Handler) handleResourcesList(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	cursor, err := parseCursor(req)
+	if err != nil {
+		return nil, err
+	}
+	resources, nextCursor, err := h.srv.ListResourcesPage(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.ResourcesListResult{
+		Resources:  make([]protocol.Resource, 0, len(resources)),
+		NextCursor: nextCursor,
+	}
+	for _, r := range resources {
+		result.Resources = append(result.Resources, r.ListItem())
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handleResourceTemplatesList(req *protocol.Request) (*protocol.Response, error) {
+	cursor, err := parseCursor(req)
+	if err != nil {
+		return nil, err
+	}
+	templates, nextCursor, err := h.srv.ResourceTemplatesPage(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.ResourceTemplatesListResult{
+		ResourceTemplates: make([]protocol.ResourceTemplate, 0, len(templates)),
+		NextCursor:        nextCursor,
+	}
+	for _, t := range templates {
+		result.ResourceTemplates = append(result.ResourceTemplates, t.ListItem())
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handleResourcesRead(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	// Parse params
+	var params protocol.ReadResourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, protocol.NewInvalidParams(err.Error())
+	}
+
+	if err := h.srv.HookResourceRead(ctx, server.ResourceReadEvent{URI: params.URI}); err != nil {
+		return nil, toProtocolError(err)
+	}
+
+	ctx = server.ContextWithResultMeta(ctx)
+
+	// HTTP responses stream the bodies of streaming resources
+	if transport.TransportFromContext(ctx) == transport.TransportHTTP {
+		result, ok, err := h.srv.StreamResource(ctx, params.URI)
+		if err != nil {
+			return nil, toProtocolError(err)
+		}
+		if ok {
+			return protocol.NewResponse(req.ID, result), nil
+		}
+	}
+
+	// Read from the matching resource or provider
+	content, err := h.srv.ReadResource(ctx, params.URI)
+	if err != nil {
+		return nil, toProtocolError(err)
+	}
+
+	result := protocol.ReadResourceResult{
+		Contents: []protocol.ResourceContents{
+			{
+				URI:      content.URI,
+				MimeType: content.MimeType,
+				Text:     content.Text,
+				Blob:     content.Blob,
+			},
+		},
+		Meta: server.ResultMetaFromContext(ctx),
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handleResourcesSubscribe(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	session, uri, err := h.subscriptionRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Subscribe(uri); err != nil {
+		return nil, protocol.NewInternalError(err.Error())
+	}
+	return protocol.NewResponse(req.ID, protocol.EmptyResult{}), nil
+}
+
+func (h *Handler) handleResourcesUnsubscribe(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	session, uri, err := h.subscriptionRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Unsubscribe(uri); err != nil {
+		return nil, protocol.NewInternalError(err.Error())
+	}
+	return protocol.NewResponse(req.ID, protocol.EmptyResult{}), nil
+}
+
+// subscriptionRequest returns the session and canonical URI of a
+// resources/subscribe or resources/unsubscribe request.
+func (h *Handler) subscriptionRequest(ctx context.Context, req *protocol.Request) (*server.Session, string, error) {
+	var params server.SubscribeRequest
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, "", protocol.NewInvalidParams(err.Error())
+	}
+	if params.URI == "" {
+		return nil, "", protocol.NewInvalidParams("uri is required")
+	}
+
+	// Updates are delivered as notifications on the session
+	session := server.SessionFromContext(ctx)
+	if session == nil {
+		return nil, "", protocol.NewInvalidRequest("resource subscriptions require a transport with a session")
+	}
+	return session, server.CanonicalURI(params.URI, h.srv.TrailingSlashPolicy()), nil
+}
+
+func (h *Handler) handlePromptsList(req *protocol.Request) (*protocol.Response, error) {
+	cursor, err := parseCursor(req)
+	if err != nil {
+		return nil, err
+	}
+	prompts, nextCursor, err := h.srv.PromptsPage(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.PromptsListResult{
+		Prompts:    make([]protocol.Prompt, 0, len(prompts)),
+		NextCursor: nextCursor,
+	}
+	for _, p := range prompts {
+		result.Prompts = append(result.Prompts, p.ListItem())
+	}
+
+	return protocol.NewResponse(req.ID, result), nil
+}
+
+func (h *Handler) handlePromptsGet(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	// Parse params
+	var params protocol.GetPromptParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, protocol.NewInvalidParams(err.Error())
+	}
+
+	// Get prompt
+	prompt, ok := h.srv.GetPrompt(params.Name)
+	if !ok {
+		return nil, protocol.NewNotFound("prompt not found: " + params.Name)
+	}
+
+	if err := h.srv.HookPromptGet(ctx, server.PromptGetEvent{Name: params.Name, Arguments: params.Arguments}); err != nil {
+		return nil, toProtocolError(err)
+	}
+
+	// Execute prompt
+	ctx = server.ContextWithResultMeta(ctx)
+	result, err := prompt.Get(ctx, params.Arguments)
+	if err != nil {
+		var mcpErr *protocol.Error
+		if errors.As(err, &mcpErr) {
+			return nil, mcpErr
+		}
+		return nil, protocol.NewInvalidParams(err.Error())
+	}
+
+	response := protocol.GetPromptResult{
+		Description: result.Description,
+		Messages:    make([]protocol.PromptMessage, 0, len(result.Messages)),
+		Meta:        server.ResultMetaFromContext(ctx),
+	}
+	for _, m := range result.Messages {
+		response.Messages = append(response.Messages, protocol.PromptMessage{
+			Role:    m.Role,
+			Content: m.Content,
+		})
+	}
+
+	return protocol.NewResponse(req.ID, response), nil
+}
+
+// toProtocolError returns err as is if it is an MCP error, including the
+// domain errors of the errors package, or wraps it in an internal error.
+func toProtocolError(err error) error {
+	var mcpErr *protocol.Error
+	if errors.As(err, &mcpErr) {
+		return mcpErr
+	}
+	return protocol.NewInternalError(err.Error())
+}
+
+// parseCursor extracts the optional pagination cursor from list request params.
+func parseCursor(req *protocol.Request) (string, error) {
+	if len(req.Params) == 0 {
+		return "", nil
+	}
+
+	var params protocol.PaginatedParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return "", protocol.NewInvalidParams(err.Error())
+	}
+	return params.Cursor, nil
+}
+
+func (h *Handler) handlePing(req *protocol.Request) (*protocol.Response, error) {
+	return protocol.NewResponse(req.ID, protocol.EmptyResult{}), nil
+}
+
+// notificationAdapter adapts transport.NotificationSender to server.NotificationSender.
+type notificationAdapter struct {
+	sender transport.NotificationSender
+}
+
+func (a *notificationAdapter) SendNotification(method string, params any) error {
+	return a.sender.SendNotification(method, params)
+}
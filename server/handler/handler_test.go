@@ -0,0 +1,2176 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	mcperrors "github.com/felixgeelhaar/mcp-go/errors"
+	"github.com/felixgeelhaar/mcp-go/middleware"
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+// handshake is the initialize exchange a client sends before other requests.
+const handshake = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}` + "\n" +
+	`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"
+
+// nopConn is a connection that accepts notifications and fails requests.
+type nopConn struct{}
+
+func (nopConn) SendNotification(method string, params any) error { return nil }
+
+func (nopConn) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return nil, context.Canceled
+}
+
+func TestNew(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	srv.Tool("echo").Handler(func(in struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return in.Text, nil
+	})
+
+	var methods []string
+	h := New(srv, WithMiddleware(func(next middleware.HandlerFunc) middleware.HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			methods = append(methods, req.Method)
+			return next(ctx, req)
+		}
+	}))
+	var _ transport.Handler = h
+	var _ transport.ConnectionObserver = h
+
+	ctx := transport.ContextWithConnectionID(context.Background(), "conn-1")
+	ctx = transport.ContextWithRequestSender(ctx, nopConn{})
+	ctx = transport.ContextWithNotificationSender(ctx, nopConn{})
+	send := func(method, params string) *protocol.Response {
+		t.Helper()
+		req := &protocol.Request{JSONRPC: "2.0", Method: method, Params: json.RawMessage(params)}
+		if method != protocol.MethodInitialized {
+			req.ID = json.RawMessage(`1`)
+		}
+		resp, err := h.HandleRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		return resp
+	}
+
+	send(protocol.MethodInitialize, `{}`)
+	send(protocol.MethodInitialized, ``)
+	if h.Session("conn-1") == nil {
+		t.Fatal("Session() = nil after initialize")
+	}
+
+	resp := send(protocol.MethodToolsCall, `{"name":"echo","arguments":{"text":"hi"}}`)
+	result := resp.Result.(protocol.CallToolResult)
+	if len(result.Content) != 1 || result.Content[0].Text != "hi" {
+		t.Errorf("result = %+v, want hi", result)
+	}
+	if len(methods) != 3 {
+		t.Errorf("middleware saw %v, want every request", methods)
+	}
+
+	h.ConnectionClosed("conn-1")
+	if h.Session("conn-1") != nil {
+		t.Error("Session() after close != nil")
+	}
+}
+
+func TestToolResultText(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		want   string
+	}{
+		{name: "string", result: "hello", want: "hello"},
+		{name: "number", result: 8, want: "8"},
+		{name: "struct", result: struct {
+			Sum int `json:"sum"`
+		}{Sum: 8}, want: `{"sum":8}`},
+		{name: "nil", result: nil, want: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toolResultText(tt.result)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("toolResultText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_ListTitlesAndIcons(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	icon := server.Icon{Src: "https://example.com/icon.png", MimeType: "image/png"}
+	srv.Tool("search").Title("Search").Icons(icon).
+		Handler(func(in struct{}) (string, error) { return "", nil })
+	srv.Tool("plain").
+		Handler(func(in struct{}) (string, error) { return "", nil })
+	srv.Resource("config://app").Title("Config").Icons(icon).
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri}, nil
+		})
+	srv.Resource("file://{path}").Title("Files").Icons(icon).
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri}, nil
+		})
+	srv.Prompt("review").Title("Review").Icons(icon).
+		Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+			return &server.PromptResult{}, nil
+		})
+	h := New(srv)
+	ctx := context.Background()
+
+	list := func(method, key string) []map[string]any {
+		t.Helper()
+		resp, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: method})
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		data, err := json.Marshal(resp.Result)
+		if err != nil {
+			t.Fatalf("marshal %s result: %v", method, err)
+		}
+		var result map[string][]map[string]any
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatalf("unmarshal %s result: %v", method, err)
+		}
+		return result[key]
+	}
+
+	tests := []struct {
+		method, key string
+		wantTitle   string
+	}{
+		{protocol.MethodToolsList, "tools", "Search"},
+		{protocol.MethodResourcesList, "resources", "Config"},
+		{protocol.MethodResourcesTemplatesList, "resourceTemplates", "Files"},
+		{protocol.MethodPromptsList, "prompts", "Review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var item map[string]any
+			for _, it := range list(tt.method, tt.key) {
+				if it["title"] == tt.wantTitle {
+					item = it
+				}
+			}
+			if item["title"] != tt.wantTitle {
+				t.Errorf("title = %v, want %q", item["title"], tt.wantTitle)
+			}
+			icons, _ := item["icons"].([]any)
+			if len(icons) != 1 || icons[0].(map[string]any)["src"] != icon.Src {
+				t.Errorf("icons = %v, want %s", item["icons"], icon.Src)
+			}
+		})
+	}
+
+	for _, item := range list(protocol.MethodToolsList, "tools") {
+		if item["name"] != "plain" {
+			continue
+		}
+		if _, ok := item["title"]; ok {
+			t.Errorf("untitled tool listed title %v", item["title"])
+		}
+		if _, ok := item["icons"]; ok {
+			t.Errorf("tool without icons listed icons %v", item["icons"])
+		}
+	}
+}
+
+func TestHandleRequest_DomainErrors(t *testing.T) {
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"})
+	srv.Tool("lookup").Handler(func(in struct{}) (string, error) {
+		return "", mcperrors.NotFound("user %s", "42")
+	})
+	srv.Resource("quota://usage").Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+		return nil, mcperrors.RateLimited("quota exceeded").WithRetryAfter(30 * time.Second)
+	})
+	srv.Prompt("deploy").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+		return nil, mcperrors.PermissionDenied("deploys are frozen")
+	})
+	h := New(srv)
+
+	tests := []struct {
+		method, params string
+		wantCode       int
+		wantData       any
+	}{
+		{protocol.MethodToolsCall, `{"name":"lookup","arguments":{}}`, protocol.CodeNotFound, nil},
+		{protocol.MethodResourcesRead, `{"uri":"quota://usage"}`, protocol.CodeRateLimited, map[string]any{"retryAfter": 30}},
+		{protocol.MethodPromptsGet, `{"name":"deploy"}`, protocol.CodeForbidden, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			_, err := h.HandleRequest(context.Background(), &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Method:  tt.method,
+				Params:  json.RawMessage(tt.params),
+			})
+			protoErr, ok := err.(*protocol.Error)
+			if !ok {
+				t.Fatalf("error = %T %v, want *protocol.Error", err, err)
+			}
+			if protoErr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", protoErr.Code, tt.wantCode)
+			}
+			if tt.wantData != nil && !reflect.DeepEqual(protoErr.Data, tt.wantData) {
+				t.Errorf("data = %v, want %v", protoErr.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_ClientInfo(t *testing.T) {
+	store := server.NewMemoryStore()
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"}, server.WithSessionStore(store))
+	srv.Tool("whoami").Handler(func(ctx context.Context, in struct{}) (string, error) {
+		info, ok := server.ClientInfoFromContext(ctx)
+		if !ok {
+			return "unknown", nil
+		}
+		return info.Name + "/" + info.Version + " " + info.ProtocolVersion, nil
+	})
+
+	var seen []string
+	h := New(srv, WithMiddleware(func(next middleware.HandlerFunc) middleware.HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			info, _ := server.ClientInfoFromContext(ctx)
+			seen = append(seen, info.Name)
+			return next(ctx, req)
+		}
+	}))
+
+	ctx := transport.ContextWithConnectionID(context.Background(), "conn-1")
+	ctx = transport.ContextWithRequestSender(ctx, nopConn{})
+	ctx = transport.ContextWithNotificationSender(ctx, nopConn{})
+	send := func(h *Handler, ctx context.Context, method, params string) *protocol.Response {
+		t.Helper()
+		req := &protocol.Request{JSONRPC: "2.0", Method: method, Params: json.RawMessage(params)}
+		if method != protocol.MethodInitialized {
+			req.ID = json.RawMessage(`1`)
+		}
+		resp, err := h.HandleRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		return resp
+	}
+	whoami := func(h *Handler, ctx context.Context) string {
+		t.Helper()
+		resp := send(h, ctx, protocol.MethodToolsCall, `{"name":"whoami","arguments":{}}`)
+		return resp.Result.(protocol.CallToolResult).Content[0].Text
+	}
+
+	send(h, ctx, protocol.MethodInitialize, `{"protocolVersion":"2025-06-18","clientInfo":{"name":"claude-desktop","version":"0.9.0"},"capabilities":{"sampling":{}}}`)
+	send(h, ctx, protocol.MethodInitialized, ``)
+	if got := whoami(h, ctx); got != "claude-desktop/0.9.0 2025-06-18" {
+		t.Errorf("tool saw client %q", got)
+	}
+	if want := []string{"", "claude-desktop", "claude-desktop"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("middleware saw clients %v, want %v", seen, want)
+	}
+	if info, ok := h.Session("conn-1").ClientInfo(); !ok || !info.Capabilities.Sampling {
+		t.Errorf("session client = %+v, %v", info, ok)
+	}
+
+	// Another instance sharing the store resumes the connection
+	other := New(server.New(server.Info{Name: "test", Version: "1.0.0"}, server.WithSessionStore(store)))
+	other.srv.Tool("whoami").Handler(func(ctx context.Context, in struct{}) (string, error) {
+		info, _ := server.ClientInfoFromContext(ctx)
+		return info.Name, nil
+	})
+	if got := whoami(other, ctx); got != "claude-desktop" {
+		t.Errorf("resumed connection client = %q", got)
+	}
+
+	h.ConnectionClosed("conn-1")
+	if _, ok := srv.ClientInfo("conn-1"); ok {
+		t.Error("client info kept after close")
+	}
+	if got := whoami(h, transport.ContextWithConnectionID(context.Background(), "")); got != "unknown" {
+		t.Errorf("sessionless request client = %q", got)
+	}
+}
+
+func TestHandleRequest_IdleTimeout(t *testing.T) {
+	store := server.NewMemoryStore()
+	srv := server.New(server.Info{Name: "test", Version: "1.0.0"},
+		server.WithIdleTimeout(20*time.Millisecond), server.WithSessionStore(store))
+	expired := make(chan server.SessionExpiredEvent, 2)
+	srv.OnSessionExpired(func(ctx context.Context, e server.SessionExpiredEvent) { expired <- e })
+	h := New(srv)
+
+	initialize := func(ctx context.Context) {
+		t.Helper()
+		for _, method := range []string{protocol.MethodInitialize, protocol.MethodInitialized} {
+			req := &protocol.Request{JSONRPC: "2.0", Method: method, Params: json.RawMessage(`{}`)}
+			if method == protocol.MethodInitialize {
+				req.ID = json.RawMessage(`1`)
+			}
+			if _, err := h.HandleRequest(ctx, req); err != nil {
+				t.Fatalf("%s error = %v", method, err)
+			}
+		}
+	}
+	waitExpired := func(id string) server.SessionExpiredEvent {
+		t.Helper()
+		select {
+		case e := <-expired:
+			if e.ID != id {
+				t.Errorf("expired %q, want %q", e.ID, id)
+			}
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s did not expire", id)
+		}
+		return server.SessionExpiredEvent{}
+	}
+
+	t.Run("http", func(t *testing.T) {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{ID: "http-1", Transport: transport.TransportHTTP})
+		initialize(ctx)
+		e := waitExpired("http-1")
+		if e.Session != nil || e.LastActive.IsZero() {
+			t.Errorf("event = %+v, want no session and last activity", e)
+		}
+		if state := srv.LifecycleState("http-1"); state != server.StateUninitialized {
+			t.Errorf("lifecycle state after expiry = %v", state)
+		}
+		if _, err := store.LoadSession(ctx, "http-1"); err == nil {
+			t.Error("stored session state kept after expiry")
+		}
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		conn := &closingConn{closed: make(chan struct{})}
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{ID: "ws-1", Transport: transport.TransportWebSocket})
+		ctx = transport.ContextWithRequestSender(ctx, conn)
+		ctx = transport.ContextWithNotificationSender(ctx, conn)
+		initialize(ctx)
+		if e := waitExpired("ws-1"); e.Session == nil || e.Session.ID() != "ws-1" {
+			t.Errorf("event session = %v, want ws-1", e.Session)
+		}
+		<-conn.closed
+		if h.Session("ws-1") != nil {
+			t.Error("session kept after expiry")
+		}
+	})
+
+	t.Run("stdio", func(t *testing.T) {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{ID: "stdio-1", Transport: transport.TransportStdio})
+		initialize(ctx)
+		select {
+		case e := <-expired:
+			t.Errorf("stdio connection expired: %+v", e)
+		case <-time.After(100 * time.Millisecond):
+		}
+		h.ConnectionClosed("stdio-1")
+	})
+
+	if got := srv.LivenessStats().Idle; got != 2 {
+		t.Errorf("LivenessStats().Idle = %d, want 2", got)
+	}
+}
+
+func TestStdio_Initialize(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Capabilities: server.Capabilities{
+			Tools: true,
+		},
+	})
+
+	// Prepare initialize request
+	initReq := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params": map[string]any{
+			"protocolVersion": "2024-11-05",
+			"clientInfo": map[string]any{
+				"name":    "test-client",
+				"version": "1.0.0",
+			},
+		},
+	}
+	initBytes, _ := json.Marshal(initReq)
+
+	in := bytes.NewBuffer(append(initBytes, '\n'))
+	out := &bytes.Buffer{}
+
+	// Create stdio transport with custom streams
+	tr := transport.NewStdio(
+		transport.WithStdin(in),
+		transport.WithStdout(out),
+	)
+
+	h := New(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_ = tr.Serve(ctx, h)
+
+	output := out.String()
+	if !strings.Contains(output, `"protocolVersion"`) {
+		t.Errorf("expected protocolVersion in response, got %q", output)
+	}
+	if !strings.Contains(output, `"test-server"`) {
+		t.Errorf("expected server name in response, got %q", output)
+	}
+}
+
+func TestStdio_ToolsList(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:    "test-server",
+		Version: "1.0.0",
+	})
+
+	type SearchInput struct {
+		Query string `json:"query"`
+	}
+
+	srv.Tool("search").
+		Description("Search for items").
+		Handler(func(input SearchInput) (string, error) {
+			return "result", nil
+		})
+
+	// Prepare tools/list request
+	listReq := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/list",
+	}
+	listBytes, _ := json.Marshal(listReq)
+
+	in := bytes.NewBufferString(handshake + string(listBytes) + "\n")
+	out := &bytes.Buffer{}
+
+	tr := transport.NewStdio(
+		transport.WithStdin(in),
+		transport.WithStdout(out),
+	)
+
+	h := New(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_ = tr.Serve(ctx, h)
+
+	output := out.String()
+	if !strings.Contains(output, `"search"`) {
+		t.Errorf("expected tool name in response, got %q", output)
+	}
+	if !strings.Contains(output, `"Search for items"`) {
+		t.Errorf("expected tool description in response, got %q", output)
+	}
+}
+
+func TestStdio_ToolsCall(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:    "test-server",
+		Version: "1.0.0",
+	})
+
+	type AddInput struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+
+	srv.Tool("add").
+		Description("Add two numbers").
+		Handler(func(input AddInput) (int, error) {
+			return input.A + input.B, nil
+		})
+
+	// Prepare tools/call request
+	callReq := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]any{
+			"name":      "add",
+			"arguments": map[string]any{"a": 5, "b": 3},
+		},
+	}
+	callBytes, _ := json.Marshal(callReq)
+
+	in := bytes.NewBufferString(handshake + string(callBytes) + "\n")
+	out := &bytes.Buffer{}
+
+	tr := transport.NewStdio(
+		transport.WithStdin(in),
+		transport.WithStdout(out),
+	)
+
+	h := New(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_ = tr.Serve(ctx, h)
+
+	output := out.String()
+	if !strings.Contains(output, `"content"`) {
+		t.Errorf("expected content in response, got %q", output)
+	}
+	if !strings.Contains(output, "8") {
+		t.Errorf("expected result 8 in response, got %q", output)
+	}
+}
+
+func TestStdio_Ping(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:    "test-server",
+		Version: "1.0.0",
+	})
+
+	// Prepare ping request
+	pingReq := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "ping",
+	}
+	pingBytes, _ := json.Marshal(pingReq)
+
+	in := bytes.NewBuffer(append(pingBytes, '\n'))
+	out := &bytes.Buffer{}
+
+	tr := transport.NewStdio(
+		transport.WithStdin(in),
+		transport.WithStdout(out),
+	)
+
+	h := New(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_ = tr.Serve(ctx, h)
+
+	output := out.String()
+	if !strings.Contains(output, `"result"`) {
+		t.Errorf("expected result in response, got %q", output)
+	}
+}
+
+func TestStdio_CancelledToolCall(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:    "test-server",
+		Version: "1.0.0",
+	})
+
+	type SlowInput struct{}
+
+	started := make(chan struct{})
+	observed := make(chan error, 1)
+	srv.Tool("slow").Handler(func(ctx context.Context, input SlowInput) (string, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			observed <- ctx.Err()
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			observed <- nil
+			return "finished", nil
+		}
+	})
+
+	inR, inW := io.Pipe()
+	out := &bytes.Buffer{}
+
+	tr := transport.NewStdio(
+		transport.WithStdin(inR),
+		transport.WithStdout(out),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tr.Serve(ctx, New(srv))
+	}()
+
+	_, _ = inW.Write([]byte(handshake))
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}` + "\n"))
+	<-started
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}` + "\n"))
+
+	select {
+	case err := <-observed:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("tool ctx error = %v, want context.Canceled", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("tool did not observe cancellation")
+	}
+
+	_ = inW.Close()
+	<-done
+
+	if strings.Contains(out.String(), `"id":1`) {
+		t.Errorf("expected no response for cancelled request, got %q", out.String())
+	}
+}
+
+func TestStdio_StrictClientCompliance(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithStrictClientCompliance())
+
+	lines := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`,
+	}, "\n") + "\n"
+
+	out := &bytes.Buffer{}
+	tr := transport.NewStdio(
+		transport.WithStdin(strings.NewReader(lines)),
+		transport.WithStdout(out),
+	)
+	if err := tr.Serve(context.Background(), New(srv)); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	responses := map[string]protocol.Response{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp protocol.Response
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses[string(resp.ID)] = resp
+	}
+
+	for _, id := range []string{"1", "3"} {
+		if resp := responses[id]; resp.Error == nil || resp.Error.Code != protocol.CodeInvalidRequest {
+			t.Errorf("response %s: expected InvalidRequest error, got %+v", id, resp)
+		}
+	}
+	if resp := responses["4"]; resp.Error != nil {
+		t.Errorf("response 4: unexpected error %v", resp.Error)
+	}
+
+	stats := srv.ComplianceStats()
+	if stats.MissingInitialize != 1 || stats.NotInitialized != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestHandleRequest_ToolErrorResult(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("divide").Handler(func(input struct{ B int }) (int, error) {
+		if input.B == 0 {
+			return 0, server.NewToolError("division by zero")
+		}
+		return 1, nil
+	})
+
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"divide","arguments":{"B":0}}`),
+	}
+
+	resp, err := New(srv).HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected result, got error %v", err)
+	}
+
+	var result protocol.CallToolResult
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected isError to be set")
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != "division by zero" {
+		t.Errorf("content = %+v, want division by zero", result.Content)
+	}
+}
+
+func TestHandleRequest_RichToolResult(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("chart").Handler(func(input struct{}) (*server.ToolResult, error) {
+		return server.NewToolResult().Text("chart").Image([]byte("png"), "image/png"), nil
+	})
+
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"chart","arguments":{}}`),
+	}
+
+	resp, err := New(srv).HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	var result protocol.CallToolResult
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content blocks, want 2", len(result.Content))
+	}
+	if result.Content[1].Type != "image" || result.Content[1].MimeType != "image/png" {
+		t.Errorf("image block = %+v", result.Content[1])
+	}
+}
+
+func TestHandleRequest_ResourceTemplatesList(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithPageSize(1))
+	handler := func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+		return &server.ResourceContent{URI: uri}, nil
+	}
+	srv.Resource("config://app").Name("Config").Handler(handler)
+	srv.Resource("users://{id}").
+		Name("User").
+		Description("User by ID").
+		MimeType("application/json").
+		Audience("user").
+		Handler(handler)
+	srv.Resource("files://{path}").Name("File").Handler(handler)
+
+	h := New(srv)
+	list := func(params string) protocol.ResourceTemplatesListResult {
+		t.Helper()
+		req := &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodResourcesTemplatesList,
+			Params:  json.RawMessage(params),
+		}
+		resp, err := h.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		var result protocol.ResourceTemplatesListResult
+		if err := protocol.DecodeResult(resp, &result); err != nil {
+			t.Fatalf("DecodeResult() error = %v", err)
+		}
+		return result
+	}
+
+	first := list(`{}`)
+	if len(first.ResourceTemplates) != 1 || first.ResourceTemplates[0].URITemplate != "files://{path}" {
+		t.Fatalf("first page = %+v", first.ResourceTemplates)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("expected next cursor")
+	}
+
+	second := list(`{"cursor":"` + first.NextCursor + `"}`)
+	if len(second.ResourceTemplates) != 1 {
+		t.Fatalf("second page = %+v", second.ResourceTemplates)
+	}
+	got := second.ResourceTemplates[0]
+	if got.URITemplate != "users://{id}" || got.Name != "User" || got.Description != "User by ID" || got.MimeType != "application/json" {
+		t.Errorf("template = %+v", got)
+	}
+	if got.Annotations == nil {
+		t.Error("expected annotations")
+	}
+	if second.NextCursor != "" {
+		t.Errorf("NextCursor = %q, want empty", second.NextCursor)
+	}
+}
+
+func TestHandleRequest_ResourceProvider(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.AddResourceProvider(server.NewFSProvider(fstest.MapFS{
+		"readme.md": {Data: []byte("# Readme")},
+	}, "docs://"))
+	h := New(srv)
+
+	resp, err := h.HandleRequest(context.Background(), &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodResourcesList,
+	})
+	if err != nil {
+		t.Fatalf("resources/list error = %v", err)
+	}
+	var list protocol.ResourcesListResult
+	if err := protocol.DecodeResult(resp, &list); err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	if len(list.Resources) != 1 || list.Resources[0].URI != "docs://readme.md" {
+		t.Fatalf("resources = %+v", list.Resources)
+	}
+
+	resp, err = h.HandleRequest(context.Background(), &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`2`),
+		Method:  protocol.MethodResourcesRead,
+		Params:  json.RawMessage(`{"uri":"docs://readme.md"}`),
+	})
+	if err != nil {
+		t.Fatalf("resources/read error = %v", err)
+	}
+	var read protocol.ReadResourceResult
+	if err := protocol.DecodeResult(resp, &read); err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].Text != "# Readme" {
+		t.Errorf("contents = %+v", read.Contents)
+	}
+
+	_, err = h.HandleRequest(context.Background(), &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`3`),
+		Method:  protocol.MethodResourcesRead,
+		Params:  json.RawMessage(`{"uri":"docs://missing.md"}`),
+	})
+	var mcpErr *protocol.Error
+	if !errors.As(err, &mcpErr) || mcpErr.Code != protocol.CodeNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestHandleRequest_MaxSessionsPerIdentity(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithMaxSessionsPerIdentity(1))
+	h := New(srv)
+
+	initialize := func(connID, transportName string) error {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{
+			ID:        connID,
+			Transport: transportName,
+		})
+		ctx = middleware.ContextWithIdentity(ctx, &middleware.Identity{ID: "alice"})
+		_, err := h.HandleRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodInitialize,
+			Params:  json.RawMessage(`{}`),
+		})
+		return err
+	}
+
+	if err := initialize("ws-1", transport.TransportWebSocket); err != nil {
+		t.Fatalf("first initialize error = %v", err)
+	}
+	if err := initialize("ws-2", transport.TransportWebSocket); !errors.Is(err, &protocol.Error{Code: protocol.CodeRateLimited}) {
+		t.Errorf("second initialize error = %v, want rate limited", err)
+	}
+	// stdio is a single local connection and is never limited
+	if err := initialize("stdio-1", transport.TransportStdio); err != nil {
+		t.Errorf("stdio initialize error = %v", err)
+	}
+
+	h.ConnectionClosed("ws-1")
+	if err := initialize("ws-2", transport.TransportWebSocket); err != nil {
+		t.Errorf("initialize after close error = %v", err)
+	}
+}
+
+func TestHandleRequest_MaxSessionsPerIdentity_HTTPExpiry(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"},
+		server.WithMaxSessionsPerIdentity(1),
+		server.WithIdleTimeout(10*time.Millisecond),
+	)
+	h := New(srv)
+
+	initialize := func(connID string) error {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{
+			ID:        connID,
+			Transport: transport.TransportHTTP,
+		})
+		ctx = middleware.ContextWithIdentity(ctx, &middleware.Identity{ID: "alice"})
+		_, err := h.HandleRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodInitialize,
+			Params:  json.RawMessage(`{}`),
+		})
+		return err
+	}
+
+	// The client abandons http-1 without ending it
+	if err := initialize("http-1"); err != nil {
+		t.Fatalf("first initialize error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.SessionCount("alice") > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := initialize("http-2"); err != nil {
+		t.Errorf("initialize after expiry error = %v", err)
+	}
+}
+
+// recordingConn is a connection that records notifications sent to the
+// client. It cannot complete server-initiated requests.
+type recordingConn struct {
+	mu            sync.Mutex
+	notifications []*protocol.Request
+}
+
+func (c *recordingConn) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) SendNotification(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications = append(c.notifications, &protocol.Request{Method: method, Params: data})
+	return nil
+}
+
+func (c *recordingConn) Notifications() []*protocol.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*protocol.Request(nil), c.notifications...)
+}
+
+func TestHandleRequest_ResourceSubscriptions(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		Capabilities: server.Capabilities{Resources: true},
+	})
+	srv.Resource("config://app").
+		Name("config").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+			return &server.ResourceContent{URI: uri, Text: "{}"}, nil
+		})
+	h := New(srv)
+
+	connect := func(connID string) (context.Context, *recordingConn) {
+		conn := &recordingConn{}
+		ctx := transport.ContextWithConnectionID(context.Background(), connID)
+		ctx = transport.ContextWithRequestSender(ctx, conn)
+		ctx = transport.ContextWithNotificationSender(ctx, conn)
+		return ctx, conn
+	}
+	call := func(ctx context.Context, method, params string) (*protocol.Response, error) {
+		return h.HandleRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  method,
+			Params:  json.RawMessage(params),
+		})
+	}
+	initialize := func(ctx context.Context) protocol.InitializeResult {
+		t.Helper()
+		resp, err := call(ctx, protocol.MethodInitialize, `{}`)
+		if err != nil {
+			t.Fatalf("initialize error = %v", err)
+		}
+		if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", Method: protocol.MethodInitialized}); err != nil {
+			t.Fatalf("initialized error = %v", err)
+		}
+		var result protocol.InitializeResult
+		if err := protocol.DecodeResult(resp, &result); err != nil {
+			t.Fatalf("decode initialize result: %v", err)
+		}
+		return result
+	}
+
+	ctxA, connA := connect("conn-a")
+	ctxB, connB := connect("conn-b")
+
+	result := initialize(ctxA)
+	if result.Capabilities.Resources == nil || !result.Capabilities.Resources.Subscribe {
+		t.Errorf("resources capability = %+v, want subscribe", result.Capabilities.Resources)
+	}
+	initialize(ctxB)
+
+	if _, err := call(ctxA, protocol.MethodResourcesSubscribe, `{"uri":"CONFIG://app/"}`); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+	if _, err := call(ctxB, protocol.MethodResourcesSubscribe, `{"uri":"config://other"}`); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+
+	srv.NotifyResourceUpdated("config://app")
+
+	notifications := connA.Notifications()
+	if len(notifications) != 1 || notifications[0].Method != protocol.MethodResourceUpdated {
+		t.Fatalf("conn-a notifications = %+v, want one resource update", notifications)
+	}
+	if got := string(notifications[0].Params); got != `{"uri":"config://app"}` {
+		t.Errorf("params = %s", got)
+	}
+	if n := len(connB.Notifications()); n != 0 {
+		t.Errorf("conn-b got %d notifications, want 0", n)
+	}
+
+	if _, err := call(ctxA, protocol.MethodResourcesUnsubscribe, `{"uri":"config://app"}`); err != nil {
+		t.Fatalf("unsubscribe error = %v", err)
+	}
+	srv.NotifyResourceUpdated("config://app")
+	if n := len(connA.Notifications()); n != 1 {
+		t.Errorf("conn-a got %d notifications after unsubscribe, want 1", n)
+	}
+
+	t.Run("closed connections are unsubscribed", func(t *testing.T) {
+		h.ConnectionClosed("conn-b")
+		if subs, _ := srv.SubscriptionStore().Subscribers(context.Background(), "config://other"); len(subs) != 0 {
+			t.Errorf("subscribers = %v, want none", subs)
+		}
+	})
+
+	t.Run("missing uri", func(t *testing.T) {
+		_, err := call(ctxA, protocol.MethodResourcesSubscribe, `{}`)
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeInvalidParams}) {
+			t.Errorf("error = %v, want invalid params", err)
+		}
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		ctx := transport.ContextWithConnectionID(context.Background(), "http-1")
+		resp, err := call(ctx, protocol.MethodInitialize, `{}`)
+		if err != nil {
+			t.Fatalf("initialize error = %v", err)
+		}
+		var result protocol.InitializeResult
+		if err := protocol.DecodeResult(resp, &result); err != nil {
+			t.Fatalf("decode initialize result: %v", err)
+		}
+		if result.Capabilities.Resources.Subscribe {
+			t.Error("subscribe advertised without a session")
+		}
+		if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", Method: protocol.MethodInitialized}); err != nil {
+			t.Fatalf("initialized error = %v", err)
+		}
+
+		_, err = call(ctx, protocol.MethodResourcesSubscribe, `{"uri":"config://app"}`)
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeInvalidRequest}) {
+			t.Errorf("error = %v, want invalid request", err)
+		}
+	})
+}
+
+func TestHandleRequest_ListChanged(t *testing.T) {
+	srv := server.New(server.Info{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		Capabilities: server.Capabilities{Tools: true, Resources: true, Prompts: true},
+	})
+	h := New(srv)
+
+	conn := &recordingConn{}
+	ctx := transport.ContextWithConnectionID(context.Background(), "conn-1")
+	ctx = transport.ContextWithRequestSender(ctx, conn)
+	ctx = transport.ContextWithNotificationSender(ctx, conn)
+
+	resp, err := h.HandleRequest(ctx, &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodInitialize,
+		Params:  json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	var result protocol.InitializeResult
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		t.Fatalf("decode initialize result: %v", err)
+	}
+	caps := result.Capabilities
+	if !caps.Tools.ListChanged || !caps.Resources.ListChanged || !caps.Prompts.ListChanged {
+		t.Errorf("capabilities = %+v, want listChanged everywhere", caps)
+	}
+
+	srv.Tool("greet").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "hi", nil
+	})
+	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+		return &server.PromptResult{}, nil
+	})
+	srv.AddResourceProvider(server.NewFSProvider(fstest.MapFS{}, "docs://"))
+	srv.RemoveTool("greet")
+	srv.RemoveTool("greet")
+
+	var methods []string
+	for _, n := range conn.Notifications() {
+		methods = append(methods, n.Method)
+	}
+	want := []string{
+		protocol.MethodToolListChanged,
+		protocol.MethodPromptListChanged,
+		protocol.MethodResourceListChanged,
+		protocol.MethodToolListChanged,
+	}
+	if strings.Join(methods, ",") != strings.Join(want, ",") {
+		t.Errorf("notifications = %v, want %v", methods, want)
+	}
+
+	t.Run("closed sessions are not notified", func(t *testing.T) {
+		h.ConnectionClosed("conn-1")
+		srv.RemovePrompt("review")
+		if n := len(conn.Notifications()); n != len(want) {
+			t.Errorf("got %d notifications, want %d", n, len(want))
+		}
+	})
+}
+
+func TestHandleRequest_UnknownMethod(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	h := New(srv)
+	ctx := context.Background()
+
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`7`),
+		Method:  "upstream/search",
+		Params:  json.RawMessage(`{"q":"mcp"}`),
+	}
+
+	_, err := h.HandleRequest(ctx, req)
+	if !errors.Is(err, &protocol.Error{Code: protocol.CodeMethodNotFound}) {
+		t.Fatalf("error = %v, want method not found", err)
+	}
+
+	var forwarded []string
+	srv.OnUnknownMethod(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		forwarded = append(forwarded, req.Method)
+		return protocol.NewResponse(req.ID, map[string]any{"results": []string{}}), nil
+	})
+
+	resp, err := h.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.ID) != "7" {
+		t.Errorf("response ID = %s, want 7", resp.ID)
+	}
+	if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", Method: protocol.MethodPing, ID: json.RawMessage(`8`)}); err != nil {
+		t.Fatalf("ping error = %v", err)
+	}
+	if len(forwarded) != 1 || forwarded[0] != "upstream/search" {
+		t.Errorf("forwarded = %v, want only the unknown method", forwarded)
+	}
+}
+
+func TestHandleRequest_SamplingCapabilities(t *testing.T) {
+	tests := []struct {
+		name      string
+		caps      string
+		wantTools bool
+	}{
+		{name: "sampling", caps: `{"sampling":{}}`},
+		{name: "sampling with tools", caps: `{"sampling":{"tools":{}}}`, wantTools: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(server.New(server.Info{Name: "test-server", Version: "1.0.0"}))
+			conn := &recordingConn{}
+			ctx := transport.ContextWithConnectionID(context.Background(), "conn-1")
+			ctx = transport.ContextWithRequestSender(ctx, conn)
+			ctx = transport.ContextWithNotificationSender(ctx, conn)
+
+			_, err := h.HandleRequest(ctx, &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Method:  protocol.MethodInitialize,
+				Params:  json.RawMessage(`{"capabilities":` + tt.caps + `}`),
+			})
+			if err != nil {
+				t.Fatalf("initialize error = %v", err)
+			}
+
+			session := h.Session("conn-1")
+			if !session.SupportsFeature("sampling") {
+				t.Error("sampling not supported")
+			}
+			if got := session.SupportsFeature("sampling.tools"); got != tt.wantTools {
+				t.Errorf("sampling.tools = %v, want %v", got, tt.wantTools)
+			}
+		})
+	}
+}
+
+func TestStdio_SamplingRoundTrip(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+
+	type SummarizeInput struct {
+		Text string `json:"text"`
+	}
+	srv.Tool("summarize").Handler(func(ctx context.Context, input SummarizeInput) (string, error) {
+		session := server.SessionFromContext(ctx)
+		if session == nil {
+			return "", errors.New("no session")
+		}
+		result, err := session.CreateMessage(ctx, &server.CreateMessageRequest{
+			Messages: []server.SamplingMessage{
+				{Role: server.RoleUser, Content: server.NewTextContent("Summarize: " + input.Text)},
+			},
+			MaxTokens: 100,
+		})
+		if err != nil {
+			return "", err
+		}
+		return result.Content.Text, nil
+	})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	tr := transport.NewStdio(
+		transport.WithStdin(inR),
+		transport.WithStdout(outW),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tr.Serve(context.Background(), New(srv))
+	}()
+
+	scanner := bufio.NewScanner(outR)
+	readLine := func() map[string]any {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatal("expected output line")
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid JSON %q: %v", scanner.Text(), err)
+		}
+		return msg
+	}
+
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"sampling":{}},"clientInfo":{"name":"c","version":"1"}}}` + "\n"))
+	readLine() // initialize response
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"summarize","arguments":{"text":"long story"}}}` + "\n"))
+
+	samplingReq := readLine()
+	if samplingReq["method"] != "sampling/createMessage" {
+		t.Fatalf("expected sampling request, got %v", samplingReq)
+	}
+	id, _ := json.Marshal(samplingReq["id"])
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","content":{"type":"text","text":"short story"},"model":"m"}}` + "\n"))
+
+	toolResp := readLine()
+	result, _ := toolResp["result"].(map[string]any)
+	content, _ := result["content"].([]any)
+	if len(content) != 1 || content[0].(map[string]any)["text"] != "short story" {
+		t.Errorf("tool response = %v", toolResp)
+	}
+
+	_ = inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
+
+func TestHandleRequest_ResultMeta(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("search").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		server.WithResultMeta(ctx, "traceId", "abc")
+		return "found", nil
+	})
+	srv.Tool("report").Handler(func(ctx context.Context, input struct{}) (*server.ToolResult, error) {
+		server.WithResultMeta(ctx, "traceId", "abc")
+		server.WithResultMeta(ctx, "cost", "1")
+		return server.NewToolResult().Text("done").WithMeta("cost", "2"), nil
+	})
+	srv.Tool("fail").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		server.WithResultMeta(ctx, "traceId", "abc")
+		return "", server.NewToolError("boom")
+	})
+	srv.Resource("config://app").Name("Config").Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+		server.WithResultMeta(ctx, "maxAge", 60)
+		return &server.ResourceContent{URI: uri, Text: "{}"}, nil
+	})
+	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+		server.WithResultMeta(ctx, "traceId", "abc")
+		return &server.PromptResult{}, nil
+	})
+	h := New(srv)
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		want   map[string]any
+	}{
+		{"tool", protocol.MethodToolsCall, `{"name":"search","arguments":{}}`, map[string]any{"traceId": "abc"}},
+		{"tool result overrides", protocol.MethodToolsCall, `{"name":"report","arguments":{}}`, map[string]any{"traceId": "abc", "cost": "2"}},
+		{"tool error", protocol.MethodToolsCall, `{"name":"fail","arguments":{}}`, map[string]any{"traceId": "abc"}},
+		{"resource", protocol.MethodResourcesRead, `{"uri":"config://app"}`, map[string]any{"maxAge": float64(60)}},
+		{"prompt", protocol.MethodPromptsGet, `{"name":"review"}`, map[string]any{"traceId": "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := h.HandleRequest(context.Background(), &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Method:  tt.method,
+				Params:  json.RawMessage(tt.params),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var result struct {
+				Meta map[string]any `json:"_meta"`
+			}
+			if err := protocol.DecodeResult(resp, &result); err != nil {
+				t.Fatalf("decode result: %v", err)
+			}
+			if !reflect.DeepEqual(result.Meta, tt.want) {
+				t.Errorf("_meta = %v, want %v", result.Meta, tt.want)
+			}
+		})
+	}
+}
+
+// closingConn is a recordingConn whose connection the server can close.
+type closingConn struct {
+	recordingConn
+	closed chan struct{}
+}
+
+func (c *closingConn) CloseConnection() error {
+	close(c.closed)
+	return nil
+}
+
+func TestHandleRequest_KeepAlive(t *testing.T) {
+	initialize := func(h *Handler, connID string, conn transport.RequestSender, notifier transport.NotificationSender) {
+		t.Helper()
+		ctx := transport.ContextWithConnectionID(context.Background(), connID)
+		ctx = transport.ContextWithRequestSender(ctx, conn)
+		ctx = transport.ContextWithNotificationSender(ctx, notifier)
+		if _, err := h.HandleRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodInitialize,
+			Params:  json.RawMessage(`{}`),
+		}); err != nil {
+			t.Fatalf("initialize error = %v", err)
+		}
+	}
+
+	t.Run("closes the connection of an unresponsive session", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithPingInterval(time.Millisecond, 2))
+		h := New(srv)
+		conn := &closingConn{closed: make(chan struct{})}
+		initialize(h, "ws-1", conn, conn)
+
+		select {
+		case <-conn.closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection not closed")
+		}
+		if got := srv.LivenessStats().Expired; got != 1 {
+			t.Errorf("Expired = %d, want 1", got)
+		}
+	})
+
+	t.Run("drops the session when the connection can't be closed", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithPingInterval(time.Millisecond, 2))
+		h := New(srv)
+		conn := &recordingConn{}
+		initialize(h, "ws-1", conn, conn)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for h.Session("ws-1") != nil && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if h.Session("ws-1") != nil {
+			t.Fatal("session not dropped")
+		}
+	})
+
+	t.Run("pings HTTP sessions over their stream", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithPingInterval(10*time.Millisecond, 2))
+		ts := httptest.NewServer(transport.NewHTTP("").Handler(New(srv)))
+		defer ts.Close()
+
+		send := func(ctx context.Context, method, path, sessionID, body string) (*http.Response, error) {
+			req, _ := http.NewRequestWithContext(ctx, method, ts.URL+path, strings.NewReader(body))
+			if sessionID != "" {
+				req.Header.Set(transport.SessionIDHeader, sessionID)
+			}
+			return http.DefaultClient.Do(req)
+		}
+		waitFor := func(cond func() bool) bool {
+			deadline := time.Now().Add(5 * time.Second)
+			for !cond() && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			return cond()
+		}
+
+		resp, err := send(context.Background(), http.MethodPost, "/mcp", "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+		if err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+		resp.Body.Close()
+		sessionID := resp.Header.Get(transport.SessionIDHeader)
+
+		// Answer pings arriving on the session's stream
+		streamCtx, closeStream := context.WithCancel(context.Background())
+		stream, err := send(streamCtx, http.MethodGet, "/mcp/sse", sessionID, "")
+		if err != nil {
+			t.Fatalf("open stream failed: %v", err)
+		}
+		go func() {
+			defer stream.Body.Close()
+			scanner := bufio.NewScanner(stream.Body)
+			for scanner.Scan() {
+				var req protocol.Request
+				data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+				if !ok || json.Unmarshal([]byte(data), &req) != nil || req.Method != protocol.MethodPing {
+					continue
+				}
+				reply := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{}}`, req.ID)
+				if resp, err := send(context.Background(), http.MethodPost, "/mcp", sessionID, reply); err == nil {
+					resp.Body.Close()
+				}
+			}
+		}()
+
+		if !waitFor(func() bool { return srv.LivenessStats().Pings >= 3 }) {
+			t.Fatalf("LivenessStats() = %+v, want pings", srv.LivenessStats())
+		}
+		if stats := srv.LivenessStats(); stats.Expired != 0 {
+			t.Errorf("Expired = %d while answering pings, want 0", stats.Expired)
+		}
+		if live := srv.SessionLiveness(); len(live) != 1 || live[0].ID != sessionID {
+			t.Errorf("SessionLiveness() = %v, want %s", live, sessionID)
+		}
+
+		closeStream()
+		if !waitFor(func() bool { return srv.LivenessStats().Expired == 1 && len(srv.Sessions()) == 0 }) {
+			t.Fatalf("LivenessStats() = %+v, Sessions() = %v after the stream closed", srv.LivenessStats(), srv.Sessions())
+		}
+		resp, err = send(context.Background(), http.MethodPost, "/mcp", sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("status after expiry = %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("stops pinging closed connections", func(t *testing.T) {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithPingInterval(time.Hour, 1))
+		h := New(srv)
+		conn := &recordingConn{}
+		initialize(h, "ws-1", conn, conn)
+
+		h.ConnectionClosed("ws-1")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for len(srv.SessionLiveness()) > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := srv.SessionLiveness(); len(got) != 0 {
+			t.Errorf("SessionLiveness() = %v, want none", got)
+		}
+	})
+}
+
+func TestHandleRequest_ListOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []server.Option
+		want []string
+	}{
+		{name: "by name", want: []string{"add", "divide", "subtract"}},
+		{name: "by registration", opts: []server.Option{server.WithListOrder(server.ListOrderRegistration)}, want: []string{"subtract", "add", "divide"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, append(tt.opts, server.WithPageSize(2))...)
+			for _, name := range []string{"subtract", "add", "divide"} {
+				srv.Tool(name).Handler(func(ctx context.Context, input struct{}) (string, error) { return name, nil })
+			}
+			h := New(srv)
+
+			var names []string
+			cursor := ""
+			for {
+				params, _ := json.Marshal(protocol.PaginatedParams{Cursor: cursor})
+				resp, err := h.HandleRequest(context.Background(), &protocol.Request{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Method:  protocol.MethodToolsList,
+					Params:  params,
+				})
+				if err != nil {
+					t.Fatalf("tools/list error = %v", err)
+				}
+				var result protocol.ToolsListResult
+				if err := protocol.DecodeResult(resp, &result); err != nil {
+					t.Fatalf("decode result: %v", err)
+				}
+				for _, tool := range result.Tools {
+					names = append(names, tool.Name)
+				}
+				if cursor = result.NextCursor; cursor == "" {
+					break
+				}
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("tools = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_Hooks(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("greet").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "hi", nil
+	})
+	var deleted bool
+	srv.Tool("delete").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		deleted = true
+		return "deleted", nil
+	})
+	srv.Resource("config://app").Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+		return &server.ResourceContent{URI: uri, Text: "{}"}, nil
+	})
+	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+		return &server.PromptResult{}, nil
+	})
+
+	var events []string
+	srv.OnToolCall(func(ctx context.Context, call server.ToolCallEvent) error {
+		events = append(events, "call:"+call.Name)
+		if call.Name == "delete" {
+			return protocol.NewInvalidRequest("delete is not allowed")
+		}
+		return nil
+	})
+	srv.OnToolResult(func(ctx context.Context, result server.ToolResultEvent) {
+		events = append(events, fmt.Sprintf("result:%s:%v", result.Name, result.Result))
+	})
+	srv.OnResourceRead(func(ctx context.Context, read server.ResourceReadEvent) error {
+		events = append(events, "read:"+read.URI)
+		return nil
+	})
+	srv.OnPromptGet(func(ctx context.Context, get server.PromptGetEvent) error {
+		events = append(events, "prompt:"+get.Name)
+		return errors.New("prompts disabled")
+	})
+	srv.OnError(func(ctx context.Context, failure server.ErrorEvent) {
+		events = append(events, "error:"+failure.Method)
+	})
+	h := New(srv)
+
+	tests := []struct {
+		name     string
+		method   string
+		params   string
+		wantCode int
+		want     []string
+	}{
+		{"tool", protocol.MethodToolsCall, `{"name":"greet","arguments":{}}`, 0, []string{"call:greet", "result:greet:hi"}},
+		{"rejected tool", protocol.MethodToolsCall, `{"name":"delete","arguments":{}}`, protocol.CodeInvalidRequest, []string{"call:delete", "error:tools/call"}},
+		{"resource", protocol.MethodResourcesRead, `{"uri":"config://app"}`, 0, []string{"read:config://app"}},
+		{"rejected prompt", protocol.MethodPromptsGet, `{"name":"review"}`, protocol.CodeInternalError, []string{"prompt:review", "error:prompts/get"}},
+		{"unknown tool", protocol.MethodToolsCall, `{"name":"missing","arguments":{}}`, protocol.CodeNotFound, []string{"error:tools/call"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			_, err := h.HandleRequest(context.Background(), &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Method:  tt.method,
+				Params:  json.RawMessage(tt.params),
+			})
+			if tt.wantCode == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantCode != 0 && !errors.Is(err, &protocol.Error{Code: tt.wantCode}) {
+				t.Fatalf("error = %v, want code %d", err, tt.wantCode)
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("events = %v, want %v", events, tt.want)
+			}
+		})
+	}
+
+	if deleted {
+		t.Error("rejected tool ran")
+	}
+}
+
+func TestHandleRequest_InputValidation(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithInputValidation())
+	srv.Tool("greet").Handler(func(input struct {
+		Name string `json:"name" jsonschema:"required"`
+	}) (string, error) {
+		return "hello " + input.Name, nil
+	})
+
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"greet","arguments":{}}`),
+	}
+
+	_, err := New(srv).HandleRequest(context.Background(), req)
+	var rpcErr *protocol.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != protocol.CodeInvalidParams {
+		t.Fatalf("HandleRequest() error = %v, want InvalidParams", err)
+	}
+	data, ok := rpcErr.Data.(server.ValidationErrorData)
+	if !ok || len(data.Errors) != 1 || data.Errors[0].Path != "name" {
+		t.Errorf("Data = %+v, want the missing name field", rpcErr.Data)
+	}
+}
+
+func TestHandleRequest_OutputSchema(t *testing.T) {
+	outputSchema := json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`)
+
+	newServer := func(debug bool) *server.Server {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithOutputValidation(), server.WithDebug(debug))
+		srv.Tool("city").OutputSchema(outputSchema).Handler(func(input struct{ City string }) (map[string]any, error) {
+			if input.City == "" {
+				return map[string]any{}, nil
+			}
+			return map[string]any{"city": input.City}, nil
+		})
+		return srv
+	}
+	call := func(args string) *protocol.Request {
+		return &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodToolsCall,
+			Params:  json.RawMessage(`{"name":"city","arguments":` + args + `}`),
+		}
+	}
+
+	t.Run("sends structured content", func(t *testing.T) {
+		resp, err := New(newServer(true)).HandleRequest(context.Background(), call(`{"City":"Oslo"}`))
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		var result protocol.CallToolResult
+		if err := protocol.DecodeResult(resp, &result); err != nil {
+			t.Fatalf("DecodeResult() error = %v", err)
+		}
+		structured, _ := result.StructuredContent.(map[string]any)
+		if structured["city"] != "Oslo" {
+			t.Errorf("StructuredContent = %v, want city Oslo", result.StructuredContent)
+		}
+		if len(result.Content) != 1 || result.Content[0].Text != `{"city":"Oslo"}` {
+			t.Errorf("Content = %+v, want the JSON text", result.Content)
+		}
+	})
+
+	t.Run("fails invalid output in debug mode", func(t *testing.T) {
+		_, err := New(newServer(true)).HandleRequest(context.Background(), call(`{}`))
+		if !errors.Is(err, protocol.NewInternalError("")) {
+			t.Fatalf("HandleRequest() error = %v, want internal error", err)
+		}
+	})
+
+	t.Run("reports invalid output otherwise", func(t *testing.T) {
+		srv := newServer(false)
+		var failures []error
+		srv.OnError(func(ctx context.Context, failure server.ErrorEvent) {
+			failures = append(failures, failure.Err)
+		})
+
+		if _, err := New(srv).HandleRequest(context.Background(), call(`{}`)); err != nil {
+			t.Fatalf("HandleRequest() error = %v, want the result to be sent", err)
+		}
+		if len(failures) != 1 {
+			t.Errorf("OnError called %d times, want 1", len(failures))
+		}
+	})
+
+	t.Run("advertises output schema", func(t *testing.T) {
+		req := &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: protocol.MethodToolsList}
+		resp, err := New(newServer(false)).HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		var result protocol.ToolsListResult
+		if err := protocol.DecodeResult(resp, &result); err != nil {
+			t.Fatalf("DecodeResult() error = %v", err)
+		}
+		if len(result.Tools) != 1 || result.Tools[0].OutputSchema == nil {
+			t.Errorf("Tools = %+v, want an output schema", result.Tools)
+		}
+	})
+}
+
+func TestHandleRequest_StreamingResources(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithStreamBufferLimit(16), server.WithLifecycleEnforcement(false))
+	srv.Resource("logs://{name}").StreamHandler(func(ctx context.Context, uri string) (io.ReadCloser, string, error) {
+		return io.NopCloser(strings.NewReader(strings.Repeat("log line\n", 4))), "text/plain", nil
+	})
+	h := New(srv)
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodResourcesRead,
+		Params:  json.RawMessage(`{"uri":"logs://app"}`),
+	}
+
+	t.Run("streamed over HTTP", func(t *testing.T) {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{Transport: transport.TransportHTTP})
+		resp, err := h.HandleRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		result, ok := resp.Result.(protocol.StreamingResult)
+		if !ok {
+			t.Fatalf("Result = %T, want a streaming result", resp.Result)
+		}
+		var buf bytes.Buffer
+		if err := result.WriteJSON(&buf); err != nil {
+			t.Fatalf("WriteJSON() error = %v", err)
+		}
+		var decoded protocol.ReadResourceResult
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Contents[0].Text != strings.Repeat("log line\n", 4) {
+			t.Errorf("Text = %q, want the whole log beyond the buffer limit", decoded.Contents[0].Text)
+		}
+	})
+
+	t.Run("buffered over stdio", func(t *testing.T) {
+		ctx := transport.ContextWithConnectionInfo(context.Background(), transport.ConnectionInfo{Transport: transport.TransportStdio})
+		_, err := h.HandleRequest(ctx, req)
+		var rpcErr *protocol.Error
+		if !errors.As(err, &rpcErr) || !strings.Contains(rpcErr.Message, "too large") {
+			t.Errorf("HandleRequest() error = %v, want the buffer limit error", err)
+		}
+	})
+}
+
+func TestHandleRequest_DeprecatedAndHiddenTools(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	handler := func(struct{}) (string, error) { return "ok", nil }
+	srv.Tool("search").Description("Search documents.").Deprecated("use search_v2").Handler(handler)
+	srv.Tool("internal_reset").Hidden().Handler(handler)
+	h := New(srv)
+
+	resp, err := h.HandleRequest(context.Background(), &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: protocol.MethodToolsList})
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+	var list protocol.ToolsListResult
+	if err := protocol.DecodeResult(resp, &list); err != nil {
+		t.Fatalf("DecodeResult() error = %v", err)
+	}
+	if len(list.Tools) != 1 {
+		t.Fatalf("tools = %+v, want only the deprecated tool", list.Tools)
+	}
+	if got := list.Tools[0]; got.Description != "Deprecated: use search_v2. Search documents." || got.Meta["deprecated"] != "use search_v2" {
+		t.Errorf("tool = %+v, want the deprecation in description and _meta", got)
+	}
+
+	resp, err = h.HandleRequest(context.Background(), &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`2`),
+		Method:  protocol.MethodToolsCall,
+		Params:  json.RawMessage(`{"name":"internal_reset","arguments":{}}`),
+	})
+	if err != nil || resp.Error != nil {
+		t.Errorf("calling a hidden tool: %v, %v", err, resp.Error)
+	}
+}
+
+func TestHandleRequest_CancelTask(t *testing.T) {
+	withSession := func(connID string) context.Context {
+		conn := &recordingConn{}
+		ctx := transport.ContextWithConnectionID(context.Background(), connID)
+		ctx = transport.ContextWithRequestSender(ctx, conn)
+		return transport.ContextWithNotificationSender(ctx, conn)
+	}
+	// Like an HTTP connection with no stream, which has no session
+	withoutSession := func(connID string) context.Context {
+		return transport.ContextWithConnectionID(context.Background(), connID)
+	}
+
+	for name, connect := range map[string]func(connID string) context.Context{
+		"session":    withSession,
+		"no session": withoutSession,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+			srv.Tool("wait").Task().Handler(func(ctx context.Context, input struct{}) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			})
+			h := New(srv)
+
+			initialize := func(ctx context.Context) {
+				t.Helper()
+				if _, err := h.HandleRequest(ctx, &protocol.Request{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage(`1`),
+					Method:  protocol.MethodInitialize,
+					Params:  json.RawMessage(`{}`),
+				}); err != nil {
+					t.Fatalf("initialize error = %v", err)
+				}
+				if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", Method: protocol.MethodInitialized}); err != nil {
+					t.Fatalf("initialized error = %v", err)
+				}
+			}
+			cancel := func(ctx context.Context) {
+				t.Helper()
+				if _, err := h.HandleRequest(ctx, &protocol.Request{
+					JSONRPC: "2.0",
+					Method:  protocol.MethodCancelled,
+					Params:  json.RawMessage(`{"requestId":"call-1","reason":"user aborted"}`),
+				}); err != nil {
+					t.Fatalf("notifications/cancelled error = %v", err)
+				}
+			}
+			status := func(id string) server.TaskStatus {
+				task, err := srv.Task(context.Background(), id)
+				if err != nil {
+					t.Fatalf("Task() error = %v", err)
+				}
+				return task.Status
+			}
+
+			ctx, other := connect("conn-1"), connect("conn-2")
+			initialize(ctx)
+			initialize(other)
+
+			resp, err := h.HandleRequest(ctx, &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`"call-1"`),
+				Method:  protocol.MethodToolsCall,
+				Params:  json.RawMessage(`{"name":"wait","arguments":{}}`),
+			})
+			if err != nil {
+				t.Fatalf("tools/call error = %v", err)
+			}
+			var result protocol.CallToolResult
+			if err := protocol.DecodeResult(resp, &result); err != nil {
+				t.Fatalf("decode result: %v", err)
+			}
+			var announcement struct {
+				TaskID string `json:"taskId"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].Text), &announcement); err != nil {
+				t.Fatalf("announcement %q: %v", result.Content[0].Text, err)
+			}
+
+			// A request with the same ID on another connection is a different request
+			cancel(other)
+			time.Sleep(20 * time.Millisecond)
+			if got := status(announcement.TaskID); got != server.TaskWorking {
+				t.Fatalf("task status after cancel from another connection = %s, want working", got)
+			}
+
+			cancel(ctx)
+			deadline := time.Now().Add(5 * time.Second)
+			for status(announcement.TaskID) != server.TaskCancelled {
+				if time.Now().After(deadline) {
+					t.Fatalf("task status = %s, want cancelled", status(announcement.TaskID))
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_SessionRegistry(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	h := New(srv)
+
+	var events []string
+	srv.OnSessionConnect(func(ctx context.Context, session *server.Session) {
+		events = append(events, "connect "+session.ID())
+	})
+	srv.OnSessionDisconnect(func(ctx context.Context, session *server.Session) {
+		events = append(events, "disconnect "+session.ID())
+	})
+
+	conns := map[string]*recordingConn{"conn-1": {}, "conn-2": {}}
+	for _, id := range []string{"conn-1", "conn-2"} {
+		ctx := transport.ContextWithConnectionID(context.Background(), id)
+		ctx = transport.ContextWithRequestSender(ctx, conns[id])
+		ctx = transport.ContextWithNotificationSender(ctx, conns[id])
+		if _, err := h.HandleRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`1`),
+			Method:  protocol.MethodInitialize,
+			Params:  json.RawMessage(`{}`),
+		}); err != nil {
+			t.Fatalf("initialize error = %v", err)
+		}
+	}
+	if got := len(srv.Sessions()); got != 2 {
+		t.Fatalf("Sessions() = %d, want 2", got)
+	}
+
+	if err := srv.Broadcast(protocol.MethodToolListChanged, nil); err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	for id, conn := range conns {
+		if n := conn.Notifications(); len(n) != 1 || n[0].Method != protocol.MethodToolListChanged {
+			t.Errorf("%s notifications = %v", id, n)
+		}
+	}
+
+	h.ConnectionClosed("conn-1")
+	sessions := srv.Sessions()
+	if len(sessions) != 1 || sessions[0].ID() != "conn-2" {
+		t.Errorf("Sessions() after close = %v, want conn-2", sessions)
+	}
+	want := "connect conn-1,connect conn-2,disconnect conn-1"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestHandleRequest_HTTPSessions(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	ts := httptest.NewServer(transport.NewHTTP("").Handler(New(srv)))
+	defer ts.Close()
+
+	send := func(method, sessionID, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+"/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(transport.SessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	sessionID := send(http.MethodPost, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`).Header.Get(transport.SessionIDHeader)
+	sessions := srv.Sessions()
+	if len(sessions) != 1 || sessions[0].ID() != sessionID {
+		t.Fatalf("Sessions() = %v, want %s", sessions, sessionID)
+	}
+
+	send(http.MethodDelete, sessionID, "")
+	if sessions := srv.Sessions(); len(sessions) != 0 {
+		t.Errorf("Sessions() after DELETE = %v, want none", sessions)
+	}
+}
+
+func TestHandleRequest_HTTPLifecycle(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	ts := httptest.NewServer(transport.NewHTTP("").Handler(New(srv)))
+	defer ts.Close()
+
+	post := func(sessionID, body string) *protocol.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(transport.SessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var rpc protocol.Response
+		_ = json.NewDecoder(resp.Body).Decode(&rpc)
+		return &rpc
+	}
+
+	if resp := post("", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`); resp.Error == nil || resp.Error.Code != protocol.CodeInvalidRequest {
+		t.Errorf("sessionless tools/list error = %+v, want code %d", resp.Error, protocol.CodeInvalidRequest)
+	}
+	if resp := post("", `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.Error != nil {
+		t.Errorf("sessionless ping error = %+v", resp.Error)
+	}
+}
+
+func TestHandleRequest_SharedSessionStore(t *testing.T) {
+	store := server.NewMemoryStore()
+	newInstance := func() *httptest.Server {
+		srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithSessionStore(store))
+		srv.Tool("echo").Handler(func(in struct{ Text string }) (string, error) { return in.Text, nil })
+		ts := httptest.NewServer(transport.NewHTTP("", transport.WithHTTPSessionStore(store)).Handler(New(srv)))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	first, second := newInstance(), newInstance()
+
+	post := func(ts *httptest.Server, sessionID, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(transport.SessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := post(first, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{"sampling":{}}}}`)
+	sessionID := resp.Header.Get(transport.SessionIDHeader)
+	state, err := store.LoadSession(context.Background(), sessionID)
+	if err != nil || !state.ClientCapabilities.Sampling {
+		t.Fatalf("stored state = %+v, %v", state, err)
+	}
+
+	post(second, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp = post(second, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"Text":"hi"}}}`)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"text":"hi"`) {
+		t.Errorf("tools/call on another instance = %s", body)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, second.URL+"/mcp", nil)
+	req.Header.Set(transport.SessionIDHeader, sessionID)
+	if resp, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+	if resp := post(first, sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status after DELETE = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestHandleRequest_ResumeSession(t *testing.T) {
+	store := server.NewMemoryStore()
+	_ = store.SaveSession(context.Background(), server.SessionState{ID: "conn-1", LogLevel: server.LogLevelError})
+
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"}, server.WithSessionStore(store))
+	h := New(srv)
+
+	conn := &recordingConn{}
+	ctx := transport.ContextWithConnectionID(context.Background(), "conn-1")
+	ctx = transport.ContextWithRequestSender(ctx, conn)
+	ctx = transport.ContextWithNotificationSender(ctx, conn)
+	if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: protocol.MethodToolsList}); err != nil {
+		t.Fatalf("tools/list error = %v", err)
+	}
+
+	session, ok := srv.Session("conn-1")
+	if !ok {
+		t.Fatal("session not resumed")
+	}
+	if session.LogLevel() != server.LogLevelError {
+		t.Errorf("LogLevel() = %v, want restored %v", session.LogLevel(), server.LogLevelError)
+	}
+
+	unknown := transport.ContextWithConnectionID(context.Background(), "conn-2")
+	if _, err := h.HandleRequest(unknown, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: protocol.MethodToolsList}); err == nil {
+		t.Error("tools/list on an unknown connection error = nil, want lifecycle error")
+	}
+}
+
+func TestHandleRequest_DetectedCapabilities(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("echo").Handler(func(in struct{}) (string, error) { return "", nil })
+	h := New(srv)
+
+	resp, err := h.HandleRequest(context.Background(), &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  protocol.MethodInitialize,
+		Params:  json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	caps := resp.Result.(protocol.InitializeResult).Capabilities
+	if caps.Tools == nil {
+		t.Error("tools capability not advertised for registered tool")
+	}
+	if caps.Resources != nil || caps.Prompts != nil {
+		t.Errorf("capabilities = %+v, want only tools", caps)
+	}
+}
+
+func TestHandleRequest_CustomMethod(t *testing.T) {
+	srv := server.New(server.Info{Name: "test-server", Version: "1.0.0"})
+	h := New(srv)
+	ctx := context.Background()
+
+	var notified []string
+	srv.Method("x-test/reindex", func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		if req.IsNotification() {
+			notified = append(notified, req.Method)
+		}
+		return protocol.NewResponse(req.ID, map[string]any{"queued": true}), nil
+	})
+	srv.Method(protocol.MethodPing, func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		t.Error("built-in method dispatched to custom handler")
+		return nil, nil
+	})
+	var fallback []string
+	srv.OnUnknownMethod(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		fallback = append(fallback, req.Method)
+		return nil, protocol.NewMethodNotFound(req.Method)
+	})
+
+	resp, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "x-test/reindex"})
+	if err != nil {
+		t.Fatalf("custom method error = %v", err)
+	}
+	if got := resp.Result.(map[string]any)["queued"]; got != true {
+		t.Errorf("result = %v, want queued", resp.Result)
+	}
+
+	resp, err = h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", Method: "x-test/reindex"})
+	if err != nil || resp != nil {
+		t.Errorf("custom notification = %v, %v, want no response", resp, err)
+	}
+	if len(notified) != 1 {
+		t.Errorf("notifications handled = %d, want 1", len(notified))
+	}
+
+	if _, err := h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: protocol.MethodPing}); err != nil {
+		t.Fatalf("ping error = %v", err)
+	}
+
+	_, err = h.HandleRequest(ctx, &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`3`), Method: "x-test/unknown"})
+	if !errors.Is(err, &protocol.Error{Code: protocol.CodeMethodNotFound}) {
+		t.Errorf("unregistered method error = %v, want method not found", err)
+	}
+	if len(fallback) != 1 || fallback[0] != "x-test/unknown" {
+		t.Errorf("fallback = %v, want only the unregistered method", fallback)
+	}
+}
@@ -64,3 +64,53 @@ func TestChain(t *testing.T) {
 		}
 	})
 }
+
+func TestServer_OnUnknownMethod(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	if srv.UnknownMethodHandler() != nil {
+		t.Fatal("expected no unknown method handler by default")
+	}
+
+	srv.OnUnknownMethod(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]string{"forwarded": req.Method}), nil
+	})
+
+	fallback := srv.UnknownMethodHandler()
+	if fallback == nil {
+		t.Fatal("expected unknown method handler")
+	}
+	resp, err := fallback(context.Background(), &protocol.Request{Method: "custom/echo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Result.(map[string]string)["forwarded"]; got != "custom/echo" {
+		t.Errorf("forwarded = %q, want %q", got, "custom/echo")
+	}
+}
+
+func TestServer_Method(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	if _, ok := srv.MethodHandler("x-test/echo"); ok {
+		t.Fatal("expected no handler before registration")
+	}
+
+	srv.Method("x-test/echo", func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]string{"method": req.Method}), nil
+	})
+	fn, ok := srv.MethodHandler("x-test/echo")
+	if !ok {
+		t.Fatal("expected registered handler")
+	}
+	resp, err := fn(context.Background(), &protocol.Request{Method: "x-test/echo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Result.(map[string]string)["method"]; got != "x-test/echo" {
+		t.Errorf("method = %q, want %q", got, "x-test/echo")
+	}
+
+	srv.Method("x-test/echo", nil)
+	if _, ok := srv.MethodHandler("x-test/echo"); ok {
+		t.Error("expected handler to be removed")
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ToolCallEvent describes a tool call about to run.
+type ToolCallEvent struct {
+	// Name is the name of the tool.
+	Name string
+	// Arguments are the raw arguments sent by the client.
+	Arguments json.RawMessage
+}
+
+// ToolResultEvent describes a tool call that has run.
+type ToolResultEvent struct {
+	// Name is the name of the tool.
+	Name string
+	// Arguments are the raw arguments sent by the client.
+	Arguments json.RawMessage
+	// Result is what the tool handler returned, if it succeeded.
+	Result any
+	// Err is the error the tool handler returned, including tool errors.
+	Err error
+	// Duration is how long the handler ran.
+	Duration time.Duration
+}
+
+// ResourceReadEvent describes a resource read about to run.
+type ResourceReadEvent struct {
+	// URI is the URI requested by the client.
+	URI string
+}
+
+// PromptGetEvent describes a prompt request about to run.
+type PromptGetEvent struct {
+	// Name is the name of the prompt.
+	Name string
+	// Arguments are the prompt arguments sent by the client.
+	Arguments map[string]string
+}
+
+// ErrorEvent describes a request that failed.
+type ErrorEvent struct {
+	// Method is the JSON-RPC method of the request.
+	Method string
+	// Err is the error returned to the client.
+	Err error
+}
+
+// OnToolCall registers fn to be called before a tool runs. If fn returns
+// an error the tool does not run and the error is returned to the client,
+// so hooks can enforce policy as well as observe. Hooks run in the order
+// they were registered. It returns a function that removes fn.
+//
+// Example:
+//
+//	srv.OnToolCall(func(ctx context.Context, call server.ToolCallEvent) error {
+//	    if call.Name == "delete" && !isAdmin(ctx) {
+//	        return protocol.NewInvalidRequest("delete requires admin")
+//	    }
+//	    return nil
+//	})
+func (s *Server) OnToolCall(fn func(ctx context.Context, call ToolCallEvent) error) (remove func()) {
+	return s.hooks.toolCall.add(fn)
+}
+
+// OnToolResult registers fn to be called after a tool has run, whether it
+// succeeded or not. It returns a function that removes fn.
+func (s *Server) OnToolResult(fn func(ctx context.Context, result ToolResultEvent)) (remove func()) {
+	return s.hooks.toolResult.add(func(ctx context.Context, result ToolResultEvent) error {
+		fn(ctx, result)
+		return nil
+	})
+}
+
+// OnResourceRead registers fn to be called before a resource is read. If
+// fn returns an error the resource is not read and the error is returned
+// to the client. It returns a function that removes fn.
+func (s *Server) OnResourceRead(fn func(ctx context.Context, read ResourceReadEvent) error) (remove func()) {
+	return s.hooks.resourceRead.add(fn)
+}
+
+// OnPromptGet registers fn to be called before a prompt is rendered. If fn
+// returns an error the prompt is not rendered and the error is returned to
+// the client. It returns a function that removes fn.
+func (s *Server) OnPromptGet(fn func(ctx context.Context, get PromptGetEvent) error) (remove func()) {
+	return s.hooks.promptGet.add(fn)
+}
+
+// OnError registers fn to be called when a request fails with an error
+// response, including failures caused by the hooks above. Tool errors
+// reported as results (isError) are not errors here; see OnToolResult.
+// It returns a function that removes fn.
+func (s *Server) OnError(fn func(ctx context.Context, failure ErrorEvent)) (remove func()) {
+	return s.hooks.failure.add(func(ctx context.Context, failure ErrorEvent) error {
+		fn(ctx, failure)
+		return nil
+	})
+}
+
+// HookToolCall runs the OnToolCall hooks and returns the first error.
+// Request handlers call it before executing a tool.
+func (s *Server) HookToolCall(ctx context.Context, call ToolCallEvent) error {
+	return s.hooks.toolCall.run(ctx, call)
+}
+
+// HookToolResult runs the OnToolResult hooks. Request handlers call it
+// after executing a tool.
+func (s *Server) HookToolResult(ctx context.Context, result ToolResultEvent) {
+	_ = s.hooks.toolResult.run(ctx, result)
+}
+
+// HookResourceRead runs the OnResourceRead hooks and returns the first
+// error. Request handlers call it before reading a resource.
+func (s *Server) HookResourceRead(ctx context.Context, read ResourceReadEvent) error {
+	return s.hooks.resourceRead.run(ctx, read)
+}
+
+// HookPromptGet runs the OnPromptGet hooks and returns the first error.
+// Request handlers call it before rendering a prompt.
+func (s *Server) HookPromptGet(ctx context.Context, get PromptGetEvent) error {
+	return s.hooks.promptGet.run(ctx, get)
+}
+
+// HookError runs the OnError hooks. Request handlers call it for every
+// request answered with an error.
+func (s *Server) HookError(ctx context.Context, failure ErrorEvent) {
+	_ = s.hooks.failure.run(ctx, failure)
+}
+
+// serverHooks holds the hooks registered on a server.
+type serverHooks struct {
+	toolCall     hooks[ToolCallEvent]
+	toolResult   hooks[ToolResultEvent]
+	resourceRead hooks[ResourceReadEvent]
+	promptGet    hooks[PromptGetEvent]
+	failure      hooks[ErrorEvent]
+}
+
+// hooks is an ordered list of callbacks that may fail. The zero value is
+// ready to use.
+type hooks[T any] struct {
+	mu   sync.Mutex
+	next int
+	fns  []hook[T]
+}
+
+type hook[T any] struct {
+	id int
+	fn func(context.Context, T) error
+}
+
+// add registers fn and returns a function that removes it.
+func (h *hooks[T]) add(fn func(context.Context, T) error) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.next
+	h.next++
+	h.fns = append(h.fns, hook[T]{id: id, fn: fn})
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.fns = slices.DeleteFunc(h.fns, func(e hook[T]) bool { return e.id == id })
+	}
+}
+
+// run calls the callbacks in registration order until one fails. Callbacks
+// run without the lock held, so they may register or remove hooks.
+func (h *hooks[T]) run(ctx context.Context, v T) error {
+	h.mu.Lock()
+	fns := slices.Clone(h.fns)
+	h.mu.Unlock()
+
+	for _, e := range fns {
+		if err := e.fn(ctx, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
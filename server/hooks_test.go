@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestServer_Hooks(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	ctx := context.Background()
+	denied := errors.New("denied")
+
+	var calls []string
+	removeFirst := srv.OnToolCall(func(ctx context.Context, call ToolCallEvent) error {
+		calls = append(calls, "first:"+call.Name)
+		return nil
+	})
+	srv.OnToolCall(func(ctx context.Context, call ToolCallEvent) error {
+		calls = append(calls, "second:"+call.Name)
+		if call.Name == "delete" {
+			return denied
+		}
+		return nil
+	})
+	srv.OnToolCall(func(ctx context.Context, call ToolCallEvent) error {
+		calls = append(calls, "third:"+call.Name)
+		return nil
+	})
+
+	t.Run("tool call hooks run in order", func(t *testing.T) {
+		calls = nil
+		if err := srv.HookToolCall(ctx, ToolCallEvent{Name: "greet"}); err != nil {
+			t.Fatalf("HookToolCall() error = %v", err)
+		}
+		want := []string{"first:greet", "second:greet", "third:greet"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("calls = %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("tool call hooks stop at the first error", func(t *testing.T) {
+		calls = nil
+		if err := srv.HookToolCall(ctx, ToolCallEvent{Name: "delete"}); !errors.Is(err, denied) {
+			t.Fatalf("HookToolCall() error = %v, want %v", err, denied)
+		}
+		want := []string{"first:delete", "second:delete"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("calls = %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("removed hooks do not run", func(t *testing.T) {
+		removeFirst()
+		removeFirst()
+		calls = nil
+		_ = srv.HookToolCall(ctx, ToolCallEvent{Name: "greet"})
+		want := []string{"second:greet", "third:greet"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("calls = %v, want %v", calls, want)
+		}
+	})
+}
+
+func TestServer_HookEvents(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	ctx := context.Background()
+	denied := errors.New("denied")
+
+	var results []ToolResultEvent
+	srv.OnToolResult(func(ctx context.Context, result ToolResultEvent) {
+		results = append(results, result)
+	})
+	var failures []ErrorEvent
+	srv.OnError(func(ctx context.Context, failure ErrorEvent) {
+		failures = append(failures, failure)
+	})
+	srv.OnResourceRead(func(ctx context.Context, read ResourceReadEvent) error {
+		if read.URI == "secret://key" {
+			return denied
+		}
+		return nil
+	})
+	srv.OnPromptGet(func(ctx context.Context, get PromptGetEvent) error {
+		if get.Arguments["lang"] == "" {
+			return denied
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		hook    func() error
+		wantErr bool
+	}{
+		{name: "allowed resource", hook: func() error { return srv.HookResourceRead(ctx, ResourceReadEvent{URI: "config://app"}) }},
+		{name: "denied resource", hook: func() error { return srv.HookResourceRead(ctx, ResourceReadEvent{URI: "secret://key"}) }, wantErr: true},
+		{name: "allowed prompt", hook: func() error {
+			return srv.HookPromptGet(ctx, PromptGetEvent{Name: "review", Arguments: map[string]string{"lang": "go"}})
+		}},
+		{name: "denied prompt", hook: func() error { return srv.HookPromptGet(ctx, PromptGetEvent{Name: "review"}) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hook(); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("observers", func(t *testing.T) {
+		srv.HookToolResult(ctx, ToolResultEvent{Name: "greet", Result: "hi"})
+		srv.HookError(ctx, ErrorEvent{Method: "tools/call", Err: denied})
+
+		if len(results) != 1 || results[0].Name != "greet" || results[0].Result != "hi" {
+			t.Errorf("results = %+v", results)
+		}
+		if len(failures) != 1 || failures[0].Method != "tools/call" || !errors.Is(failures[0].Err, denied) {
+			t.Errorf("failures = %+v", failures)
+		}
+	})
+
+	t.Run("hooks may register hooks", func(t *testing.T) {
+		var nested bool
+		remove := srv.OnToolCall(func(ctx context.Context, call ToolCallEvent) error {
+			srv.OnToolCall(func(ctx context.Context, call ToolCallEvent) error {
+				nested = true
+				return nil
+			})
+			return nil
+		})
+		_ = srv.HookToolCall(ctx, ToolCallEvent{Name: "greet"})
+		remove()
+		if nested {
+			t.Error("hook registered during a run was called in the same run")
+		}
+		_ = srv.HookToolCall(ctx, ToolCallEvent{Name: "greet"})
+		if !nested {
+			t.Error("hook registered during a run was not called later")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Icon is an image a client can display next to a tool, resource or
+// prompt. Src is an https or data URI.
+type Icon = protocol.Icon
+
+// validateIcons checks that each icon has a usable source and theme.
+func validateIcons(icons []Icon) error {
+	for i, icon := range icons {
+		switch {
+		case icon.Src == "":
+			return fmt.Errorf("icon %d: missing src", i)
+		case !strings.HasPrefix(icon.Src, "https://") && !strings.HasPrefix(icon.Src, "data:"):
+			return fmt.Errorf("icon %d: src %q must be an https or data URI", i, icon.Src)
+		case icon.Theme != "" && icon.Theme != "light" && icon.Theme != "dark":
+			return fmt.Errorf("icon %d: theme %q must be light or dark", i, icon.Theme)
+		}
+	}
+	return nil
+}
+
+// Icons sets the icons clients display for the tool.
+func (b *ToolBuilder) Icons(icons ...Icon) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := validateIcons(icons); err != nil {
+		b.err = fmt.Errorf("tool %s: %w", b.tool.name, err)
+		return b
+	}
+	b.tool.icons = icons
+	return b
+}
+
+// Title sets the human-readable name clients display for the resource.
+func (b *ResourceBuilder) Title(title string) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.resource.title = title
+	return b
+}
+
+// Icons sets the icons clients display for the resource.
+func (b *ResourceBuilder) Icons(icons ...Icon) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := validateIcons(icons); err != nil {
+		b.err = fmt.Errorf("resource %s: %w", b.resource.uriTemplate, err)
+		return b
+	}
+	b.resource.icons = icons
+	return b
+}
+
+// Title sets the human-readable name clients display for the prompt.
+func (b *PromptBuilder) Title(title string) *PromptBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.prompt.title = title
+	return b
+}
+
+// Icons sets the icons clients display for the prompt.
+func (b *PromptBuilder) Icons(icons ...Icon) *PromptBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := validateIcons(icons); err != nil {
+		b.err = fmt.Errorf("prompt %s: %w", b.prompt.name, err)
+		return b
+	}
+	b.prompt.icons = icons
+	return b
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateIcons(t *testing.T) {
+	tests := []struct {
+		name    string
+		icons   []Icon
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "https", icons: []Icon{{Src: "https://example.com/icon.png", MimeType: "image/png", Sizes: []string{"48x48"}}}},
+		{name: "data uri", icons: []Icon{{Src: "data:image/svg+xml;base64,PHN2Zy8+", Theme: "dark"}}},
+		{name: "missing src", icons: []Icon{{MimeType: "image/png"}}, wantErr: "icon 0: missing src"},
+		{name: "http", icons: []Icon{{Src: "https://example.com/a.png"}, {Src: "http://example.com/b.png"}}, wantErr: "icon 1: src"},
+		{name: "unknown theme", icons: []Icon{{Src: "https://example.com/a.png", Theme: "sepia"}}, wantErr: "theme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIcons(tt.icons)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateIcons() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateIcons() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTitlesAndIcons(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	icons := []Icon{{Src: "https://example.com/icon.svg", MimeType: "image/svg+xml", Sizes: []string{"any"}}}
+
+	srv.Tool("search").
+		Title("Search the web").
+		Icons(icons...).
+		Handler(func(in struct{}) (string, error) { return "", nil })
+	srv.Resource("config://app").
+		Title("App configuration").
+		Icons(icons...).
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri}, nil
+		})
+	srv.Resource("file://{path}").
+		Title("Project files").
+		Icons(icons...).
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri}, nil
+		})
+	srv.Prompt("review").
+		Title("Code review").
+		Icons(icons...).
+		Handler(func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+			return &PromptResult{}, nil
+		})
+
+	tool := srv.Tools()[0]
+	if tool.Title != "Search the web" || !reflect.DeepEqual(tool.Icons, icons) {
+		t.Errorf("tool = %+v, want title and icons", tool)
+	}
+	if tool.Annotations == nil || tool.Annotations.Title != "Search the web" {
+		t.Errorf("tool annotations = %+v, want title annotation for older clients", tool.Annotations)
+	}
+	for _, r := range srv.Resources() {
+		if r.Title == "" || !reflect.DeepEqual(r.Icons, icons) {
+			t.Errorf("resource = %+v, want title and icons", r)
+		}
+	}
+	templates := srv.ResourceTemplates()
+	if len(templates) != 1 || templates[0].Title != "Project files" || !reflect.DeepEqual(templates[0].Icons, icons) {
+		t.Errorf("templates = %+v, want title and icons", templates)
+	}
+	prompt := srv.Prompts()[0]
+	if prompt.Title != "Code review" || !reflect.DeepEqual(prompt.Icons, icons) {
+		t.Errorf("prompt = %+v, want title and icons", prompt)
+	}
+}
+
+func TestIcons_InvalidIcon(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	bad := Icon{Src: "ftp://example.com/icon.png"}
+
+	if err := srv.Tool("search").Icons(bad).Err(); err == nil || !strings.Contains(err.Error(), "tool search") {
+		t.Errorf("tool Err() = %v, want icon error", err)
+	}
+	if err := srv.Resource("config://app").Icons(bad).Err(); err == nil || !strings.Contains(err.Error(), "resource config://app") {
+		t.Errorf("resource Err() = %v, want icon error", err)
+	}
+	if err := srv.Prompt("review").Icons(bad).Err(); err == nil || !strings.Contains(err.Error(), "prompt review") {
+		t.Errorf("prompt Err() = %v, want icon error", err)
+	}
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SessionExpiredEvent describes a connection closed by the idle timeout.
+type SessionExpiredEvent struct {
+	// ID is the connection ID.
+	ID string
+	// Session is the session of the connection, or nil on transports that
+	// cannot send requests to the client, such as plain HTTP.
+	Session *Session
+	// LastActive is when the client last sent a request.
+	LastActive time.Time
+}
+
+// idleTracker records the last activity of connections watched for the
+// idle timeout.
+type idleTracker struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	conns   map[string]*idleConn
+
+	expired hooks[SessionExpiredEvent]
+}
+
+// idleConn is the activity of a watched connection.
+type idleConn struct {
+	lastActive time.Time
+	// busy counts requests in flight, during which the connection is
+	// never idle
+	busy  int
+	timer *time.Timer
+}
+
+func newIdleTracker() *idleTracker {
+	return &idleTracker{conns: make(map[string]*idleConn)}
+}
+
+// DefaultSessionLimitIdleTimeout is the idle timeout of a server with a
+// per-identity session limit and no idle timeout set. HTTP clients may
+// abandon a session without ending it, and only the idle timeout frees
+// its slot.
+const DefaultSessionLimitIdleTimeout = 30 * time.Minute
+
+// WithIdleTimeout closes the connections of network transports (HTTP and
+// WebSocket) once their client has sent no request for d: the session is
+// closed, its subscriptions and stored state are released, and the
+// OnSessionExpired hooks run. Requests still in flight keep a connection
+// active, while answered pings do not. Zero or less disables the timeout
+// (the default), except that a server with WithMaxSessionsPerIdentity
+// uses DefaultSessionLimitIdleTimeout unless d is negative.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.idle.timeout = d
+	}
+}
+
+// IdleTimeout returns the idle timeout, or zero if it is disabled.
+func (s *Server) IdleTimeout() time.Duration {
+	s.idle.mu.Lock()
+	timeout := s.idle.timeout
+	s.idle.mu.Unlock()
+
+	switch {
+	case timeout == 0 && s.MaxSessionsPerIdentity() > 0:
+		return DefaultSessionLimitIdleTimeout
+	case timeout < 0:
+		return 0
+	}
+	return timeout
+}
+
+// OnSessionExpired registers fn to be called when a connection is closed
+// by the idle timeout, before its session is closed. It returns a function
+// that removes fn.
+func (s *Server) OnSessionExpired(fn func(ctx context.Context, expired SessionExpiredEvent)) (remove func()) {
+	return s.idle.expired.add(func(ctx context.Context, e SessionExpiredEvent) error {
+		fn(ctx, e)
+		return nil
+	})
+}
+
+// HookSessionExpired runs the OnSessionExpired hooks. Request handlers
+// call it from the expire function passed to WatchIdle.
+func (s *Server) HookSessionExpired(ctx context.Context, expired SessionExpiredEvent) {
+	_ = s.idle.expired.run(ctx, expired)
+}
+
+// WatchIdle starts tracking the activity of connection connID and calls
+// expire, at most once, when the client has been idle for the idle
+// timeout. SessionSeen and SessionActive record activity. The returned
+// function stops watching; it is a no-op if the idle timeout is disabled
+// or connID is empty.
+//
+// Request handlers watch each connection of a network transport once it
+// is initialized, and close it when expire is called.
+func (s *Server) WatchIdle(connID string, expire func(lastActive time.Time)) (stop func()) {
+	timeout := s.IdleTimeout()
+	if timeout <= 0 || connID == "" {
+		return func() {}
+	}
+
+	t := s.idle
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if old, ok := t.conns[connID]; ok {
+		old.timer.Stop()
+	}
+	c := &idleConn{lastActive: time.Now()}
+	t.conns[connID] = c
+	c.timer = time.AfterFunc(timeout, func() {
+		if lastActive, idle := t.check(connID, c, timeout); idle {
+			s.liveness.idle.Add(1)
+			expire(lastActive)
+		}
+	})
+	return func() { t.forget(connID, c) }
+}
+
+// SessionActive marks connection connID as busy until the returned
+// function is called, so a long-running request does not count as idle
+// time. Request handlers call it for every request.
+func (s *Server) SessionActive(connID string) (done func()) {
+	t := s.idle
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.conns[connID]
+	if !ok {
+		return func() {}
+	}
+	c.busy++
+	c.lastActive = time.Now()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		c.busy--
+		c.lastActive = time.Now()
+	}
+}
+
+// seen records activity on a watched connection.
+func (t *idleTracker) seen(connID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c, ok := t.conns[connID]; ok {
+		c.lastActive = time.Now()
+	}
+}
+
+// check reports whether c has been idle for timeout, and stops watching it
+// if so. Otherwise it schedules the next check.
+func (t *idleTracker) check(connID string, c *idleConn, timeout time.Duration) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conns[connID] != c {
+		return time.Time{}, false
+	}
+	if c.busy > 0 {
+		c.timer.Reset(timeout)
+		return time.Time{}, false
+	}
+	if remaining := timeout - time.Since(c.lastActive); remaining > 0 {
+		c.timer.Reset(remaining)
+		return time.Time{}, false
+	}
+	delete(t.conns, connID)
+	return c.lastActive, true
+}
+
+// forget stops watching connID, unless c has been replaced by a newer
+// watch on the same connection.
+func (t *idleTracker) forget(connID string, c *idleConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.timer.Stop()
+	if t.conns[connID] == c {
+		delete(t.conns, connID)
+	}
+}
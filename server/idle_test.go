@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatchIdle(t *testing.T) {
+	const timeout = 30 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		activity func(s *Server, stop func())
+		wantIdle bool
+	}{
+		{"idle client", func(s *Server, stop func()) {}, true},
+		{"stopped watch", func(s *Server, stop func()) { stop() }, false},
+		{"request in flight", func(s *Server, stop func()) {
+			done := s.SessionActive("conn-1")
+			time.Sleep(3 * timeout)
+			done()
+			stop()
+		}, false},
+		{"active client", func(s *Server, stop func()) {
+			for range 6 {
+				time.Sleep(timeout / 2)
+				s.SessionSeen("conn-1")
+			}
+			stop()
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Info{Name: "test", Version: "1.0.0"}, WithIdleTimeout(timeout))
+			expired := make(chan time.Time, 1)
+			stop := s.WatchIdle("conn-1", func(lastActive time.Time) { expired <- lastActive })
+			tt.activity(s, stop)
+
+			select {
+			case lastActive := <-expired:
+				if !tt.wantIdle {
+					t.Fatal("connection expired while active")
+				}
+				if time.Since(lastActive) < timeout {
+					t.Errorf("expired %v after last activity, want at least %v", time.Since(lastActive), timeout)
+				}
+				if got := s.LivenessStats().Idle; got != 1 {
+					t.Errorf("LivenessStats().Idle = %d, want 1", got)
+				}
+			case <-time.After(4 * timeout):
+				if tt.wantIdle {
+					t.Fatal("idle connection did not expire")
+				}
+			}
+		})
+	}
+}
+
+func TestWatchIdle_Disabled(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"})
+	if s.IdleTimeout() != 0 {
+		t.Errorf("IdleTimeout() = %v, want disabled", s.IdleTimeout())
+	}
+	s.WatchIdle("conn-1", func(time.Time) { t.Error("expired with the timeout disabled") })()
+	s.SessionActive("conn-1")()
+}
+
+func TestIdleTimeout_SessionLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want time.Duration
+	}{
+		{"defaults with a session limit", []Option{WithMaxSessionsPerIdentity(1)}, DefaultSessionLimitIdleTimeout},
+		{"set timeout wins", []Option{WithMaxSessionsPerIdentity(1), WithIdleTimeout(time.Minute)}, time.Minute},
+		{"negative disables", []Option{WithMaxSessionsPerIdentity(1), WithIdleTimeout(-1)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(Info{Name: "test", Version: "1.0.0"}, tt.opts...).IdleTimeout(); got != tt.want {
+				t.Errorf("IdleTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnSessionExpired(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"})
+	var got []string
+	remove := s.OnSessionExpired(func(ctx context.Context, e SessionExpiredEvent) {
+		got = append(got, e.ID)
+	})
+	s.HookSessionExpired(context.Background(), SessionExpiredEvent{ID: "a"})
+	remove()
+	s.HookSessionExpired(context.Background(), SessionExpiredEvent{ID: "b"})
+	if len(got) != 1 || got[0] != "a" {
+		t.Errorf("hooks saw %v, want [a]", got)
+	}
+}
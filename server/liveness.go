@@ -0,0 +1,206 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// ErrSessionUnresponsive is returned by KeepAlive when a session misses
+// too many pings in a row.
+var ErrSessionUnresponsive = errors.New("server: session missed too many pings")
+
+// defaultMaxMissedPings is the number of pings a session may miss in a row
+// when WithPingInterval is given no limit.
+const defaultMaxMissedPings = 3
+
+// LivenessStats counts the effect of server-initiated pings.
+type LivenessStats struct {
+	// Pings counts pings sent to clients.
+	Pings int64
+	// Missed counts pings that failed or were not answered in time.
+	Missed int64
+	// Expired counts sessions closed for missing too many pings in a row.
+	Expired int64
+	// Idle counts sessions closed by the idle timeout.
+	Idle int64
+}
+
+// SessionLiveness describes when a session was last heard from.
+type SessionLiveness struct {
+	// ID is the connection ID of the session.
+	ID string
+	// LastSeen is when the client last sent a request or answered a ping.
+	LastSeen time.Time
+	// MissedPings is the number of pings missed since then.
+	MissedPings int
+}
+
+// livenessTracker records the last activity of the sessions being pinged.
+type livenessTracker struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	maxMissed int
+	sessions  map[string]*SessionLiveness
+
+	pings   atomic.Int64
+	missed  atomic.Int64
+	expired atomic.Int64
+	idle    atomic.Int64
+}
+
+func newLivenessTracker() *livenessTracker {
+	return &livenessTracker{
+		maxMissed: defaultMaxMissedPings,
+		sessions:  make(map[string]*SessionLiveness),
+	}
+}
+
+// WithPingInterval pings the sessions of network connections every
+// interval. A ping not answered within the interval is missed, and a
+// session that misses maxMissed pings in a row is closed; any request
+// from the client resets the count. A maxMissed of zero or less uses the
+// default of 3. An interval of zero or less disables pinging (the default).
+//
+// HTTP sessions are pinged over their SSE stream, so a session with no
+// stream open misses every ping and is closed unless it keeps sending
+// requests.
+func WithPingInterval(interval time.Duration, maxMissed int) Option {
+	return func(s *Server) {
+		s.liveness.interval = interval
+		if maxMissed > 0 {
+			s.liveness.maxMissed = maxMissed
+		}
+	}
+}
+
+// PingInterval returns the interval at which sessions are pinged, or zero
+// if pinging is disabled.
+func (s *Server) PingInterval() time.Duration {
+	s.liveness.mu.Lock()
+	defer s.liveness.mu.Unlock()
+	return s.liveness.interval
+}
+
+// LivenessStats returns the number of pings sent and missed, and of
+// sessions closed for being unresponsive or idle.
+func (s *Server) LivenessStats() LivenessStats {
+	return LivenessStats{
+		Pings:   s.liveness.pings.Load(),
+		Missed:  s.liveness.missed.Load(),
+		Expired: s.liveness.expired.Load(),
+		Idle:    s.liveness.idle.Load(),
+	}
+}
+
+// SessionLiveness returns the liveness of the sessions being pinged,
+// ordered by ID.
+func (s *Server) SessionLiveness() []SessionLiveness {
+	s.liveness.mu.Lock()
+	defer s.liveness.mu.Unlock()
+
+	sessions := make([]SessionLiveness, 0, len(s.liveness.sessions))
+	for _, l := range s.liveness.sessions {
+		sessions = append(sessions, *l)
+	}
+	slices.SortFunc(sessions, func(a, b SessionLiveness) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sessions
+}
+
+// SessionSeen records activity from the client of connection connID,
+// resetting its missed ping count and idle time. Request handlers call it
+// for every request; it does nothing for connections that are neither
+// pinged nor watched for the idle timeout.
+func (s *Server) SessionSeen(connID string) {
+	s.liveness.seen(connID)
+	s.idle.seen(connID)
+}
+
+// seen resets the missed ping count of a pinged session.
+func (l *livenessTracker) seen(connID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.sessions[connID]; ok {
+		entry.LastSeen = time.Now()
+		entry.MissedPings = 0
+	}
+}
+
+// KeepAlive pings the session of connection connID with ping every ping
+// interval until ctx is canceled, returning nil, or the session misses
+// too many pings in a row, returning ErrSessionUnresponsive. The caller
+// then closes the session. An error response to a ping still shows the
+// client is alive. KeepAlive returns immediately if pinging is disabled.
+//
+// Request handlers run KeepAlive for each session on a network transport.
+func (s *Server) KeepAlive(ctx context.Context, connID string, ping func(ctx context.Context) error) error {
+	l := s.liveness
+	l.mu.Lock()
+	interval, maxMissed := l.interval, l.maxMissed
+	if interval <= 0 || connID == "" {
+		l.mu.Unlock()
+		return nil
+	}
+	entry := &SessionLiveness{ID: connID, LastSeen: time.Now()}
+	l.sessions[connID] = entry
+	l.mu.Unlock()
+	defer l.forget(connID, entry)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+		l.pings.Add(1)
+
+		var rpcErr *protocol.Error
+		if err == nil || errors.As(err, &rpcErr) {
+			// An answered ping shows the client is alive, not active
+			l.seen(connID)
+			continue
+		}
+
+		l.missed.Add(1)
+		if l.miss(entry) >= maxMissed {
+			l.expired.Add(1)
+			return ErrSessionUnresponsive
+		}
+	}
+}
+
+// miss records a missed ping and returns the number missed in a row.
+func (l *livenessTracker) miss(entry *SessionLiveness) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.MissedPings++
+	return entry.MissedPings
+}
+
+// forget stops tracking the session of connID, unless entry has been
+// replaced by a newer session on the same connection.
+func (l *livenessTracker) forget(connID string, entry *SessionLiveness) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sessions[connID] == entry {
+		delete(l.sessions, connID)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestWithPingInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantInterval  time.Duration
+		wantMaxMissed int
+	}{
+		{name: "disabled by default", wantMaxMissed: defaultMaxMissedPings},
+		{
+			name:          "custom limit",
+			opts:          []Option{WithPingInterval(time.Second, 5)},
+			wantInterval:  time.Second,
+			wantMaxMissed: 5,
+		},
+		{
+			name:          "default limit",
+			opts:          []Option{WithPingInterval(time.Second, 0)},
+			wantInterval:  time.Second,
+			wantMaxMissed: defaultMaxMissedPings,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"}, tt.opts...)
+			if got := srv.PingInterval(); got != tt.wantInterval {
+				t.Errorf("PingInterval() = %v, want %v", got, tt.wantInterval)
+			}
+			if got := srv.liveness.maxMissed; got != tt.wantMaxMissed {
+				t.Errorf("maxMissed = %d, want %d", got, tt.wantMaxMissed)
+			}
+		})
+	}
+}
+
+func TestServer_KeepAlive(t *testing.T) {
+	t.Run("returns immediately when disabled", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		err := srv.KeepAlive(context.Background(), "conn-1", func(ctx context.Context) error {
+			t.Error("ping called")
+			return nil
+		})
+		if err != nil {
+			t.Errorf("KeepAlive() = %v, want nil", err)
+		}
+	})
+
+	t.Run("expires a session that misses pings", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithPingInterval(time.Millisecond, 3))
+		var pings atomic.Int32
+		err := srv.KeepAlive(context.Background(), "conn-1", func(ctx context.Context) error {
+			pings.Add(1)
+			return errors.New("connection reset")
+		})
+		if !errors.Is(err, ErrSessionUnresponsive) {
+			t.Fatalf("KeepAlive() = %v, want ErrSessionUnresponsive", err)
+		}
+		if got := pings.Load(); got != 3 {
+			t.Errorf("pings = %d, want 3", got)
+		}
+		want := LivenessStats{Pings: 3, Missed: 3, Expired: 1}
+		if got := srv.LivenessStats(); got != want {
+			t.Errorf("LivenessStats() = %+v, want %+v", got, want)
+		}
+		if got := srv.SessionLiveness(); len(got) != 0 {
+			t.Errorf("SessionLiveness() = %v, want none after expiry", got)
+		}
+	})
+
+	t.Run("error responses show the client is alive", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithPingInterval(time.Millisecond, 1))
+		ctx, cancel := context.WithCancel(context.Background())
+		var pings atomic.Int32
+		err := srv.KeepAlive(ctx, "conn-1", func(ctx context.Context) error {
+			if pings.Add(1) == 5 {
+				cancel()
+			}
+			return protocol.NewMethodNotFound("method not found")
+		})
+		if err != nil {
+			t.Errorf("KeepAlive() = %v, want nil", err)
+		}
+		if got := srv.LivenessStats(); got.Missed != 0 || got.Expired != 0 {
+			t.Errorf("LivenessStats() = %+v, want no missed pings", got)
+		}
+	})
+
+	t.Run("activity resets missed pings", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithPingInterval(time.Millisecond, 2))
+		ctx, cancel := context.WithCancel(context.Background())
+		var pings atomic.Int32
+		err := srv.KeepAlive(ctx, "conn-1", func(ctx context.Context) error {
+			if pings.Add(1) == 10 {
+				cancel()
+			}
+			// The client keeps sending requests between pings.
+			defer srv.SessionSeen("conn-1")
+			return context.DeadlineExceeded
+		})
+		if err != nil {
+			t.Errorf("KeepAlive() = %v, want nil", err)
+		}
+		if got := srv.LivenessStats().Expired; got != 0 {
+			t.Errorf("Expired = %d, want 0", got)
+		}
+	})
+}
+
+func TestServer_SessionLiveness(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithPingInterval(time.Hour, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	for _, id := range []string{"ws-2", "ws-1"} {
+		go func() {
+			_ = srv.KeepAlive(ctx, id, func(ctx context.Context) error { return nil })
+			done <- struct{}{}
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(srv.SessionLiveness()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	sessions := srv.SessionLiveness()
+	if len(sessions) != 2 || sessions[0].ID != "ws-1" || sessions[1].ID != "ws-2" {
+		t.Fatalf("SessionLiveness() = %+v, want ws-1 and ws-2", sessions)
+	}
+	before := sessions[0].LastSeen
+	time.Sleep(time.Millisecond)
+	srv.SessionSeen("ws-1")
+	srv.SessionSeen("unknown")
+	if got := srv.SessionLiveness()[0].LastSeen; !got.After(before) {
+		t.Errorf("LastSeen = %v, want after %v", got, before)
+	}
+
+	cancel()
+	<-done
+	<-done
+	if got := srv.SessionLiveness(); len(got) != 0 {
+		t.Errorf("SessionLiveness() = %v, want none after cancel", got)
+	}
+}
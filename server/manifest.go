@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// ManifestDocument is a complete, machine-readable description of a
+// server: its manifest plus every registered tool, resource, resource
+// template and prompt in the form clients see in the list responses.
+type ManifestDocument struct {
+	Manifest
+	Instructions      string                      `json:"instructions,omitempty"`
+	Tools             []protocol.Tool             `json:"tools"`
+	Resources         []protocol.Resource         `json:"resources"`
+	ResourceTemplates []protocol.ResourceTemplate `json:"resourceTemplates"`
+	Prompts           []protocol.Prompt           `json:"prompts"`
+}
+
+// ManifestDocument describes the server and everything registered with
+// it. Resources listed by resource providers are not included since they
+// are only known at request time.
+func (s *Server) ManifestDocument() ManifestDocument {
+	doc := ManifestDocument{
+		Manifest:          s.Manifest(),
+		Instructions:      s.Instructions(),
+		Tools:             []protocol.Tool{},
+		Resources:         []protocol.Resource{},
+		ResourceTemplates: []protocol.ResourceTemplate{},
+		Prompts:           []protocol.Prompt{},
+	}
+	for _, t := range s.Tools() {
+		doc.Tools = append(doc.Tools, t.ListItem())
+	}
+	for _, r := range s.Resources() {
+		doc.Resources = append(doc.Resources, r.ListItem())
+	}
+	for _, t := range s.ResourceTemplates() {
+		doc.ResourceTemplates = append(doc.ResourceTemplates, t.ListItem())
+	}
+	for _, p := range s.Prompts() {
+		doc.Prompts = append(doc.Prompts, p.ListItem())
+	}
+	return doc
+}
+
+// ManifestJSON returns the manifest document of the server as indented
+// JSON, e.g. for publishing to a registry or checking into a repository.
+func (s *Server) ManifestJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(s.ManifestDocument(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestServer_ManifestJSON(t *testing.T) {
+	srv := New(Info{Name: "weather", Version: "1.2.0"}, WithInstructions("Ask about the weather."))
+	srv.Tool("forecast").Description("Get the forecast").Handler(func(in struct {
+		City string `json:"city" jsonschema:"required"`
+	}) (string, error) {
+		return "", nil
+	})
+	srv.Tool("legacy").Description("Old forecast").Deprecated("use forecast").Handler(func(in struct{}) (string, error) {
+		return "", nil
+	})
+	srv.Resource("weather://stations").Name("stations").MimeType("application/json").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri}, nil
+		})
+	srv.Resource("weather://stations/{id}").Name("station").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri}, nil
+		})
+	srv.Prompt("summary").Title("Weather summary").Argument("city", "City to summarize", true).
+		Handler(func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+			return &PromptResult{}, nil
+		})
+
+	data, err := srv.ManifestJSON()
+	if err != nil {
+		t.Fatalf("ManifestJSON() error = %v", err)
+	}
+	var doc struct {
+		Name            string
+		Version         string
+		ProtocolVersion string
+		Instructions    string
+		Capabilities    map[string]bool
+		Tools           []struct {
+			Name        string
+			Description string
+			InputSchema map[string]any
+		}
+		Resources         []struct{ URI, Name, MimeType string }
+		ResourceTemplates []struct{ URITemplate string }
+		Prompts           []struct {
+			Name      string
+			Title     string
+			Arguments []struct {
+				Name     string
+				Required bool
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode manifest: %v\n%s", err, data)
+	}
+
+	if doc.Name != "weather" || doc.Version != "1.2.0" || doc.ProtocolVersion == "" || doc.Instructions != "Ask about the weather." {
+		t.Errorf("header = %s %s %s %q", doc.Name, doc.Version, doc.ProtocolVersion, doc.Instructions)
+	}
+	if !doc.Capabilities["tools"] || !doc.Capabilities["resources"] || !doc.Capabilities["prompts"] {
+		t.Errorf("capabilities = %v", doc.Capabilities)
+	}
+	if len(doc.Tools) != 2 || doc.Tools[0].Name != "forecast" || doc.Tools[0].InputSchema["type"] != "object" {
+		t.Fatalf("tools = %+v", doc.Tools)
+	}
+	if got := doc.Tools[1].Description; got != "Deprecated: use forecast. Old forecast" {
+		t.Errorf("deprecated description = %q", got)
+	}
+	if len(doc.Resources) != 2 || doc.Resources[0].URI != "weather://stations" || doc.Resources[0].MimeType != "application/json" {
+		t.Errorf("resources = %+v", doc.Resources)
+	}
+	if len(doc.ResourceTemplates) != 1 || doc.ResourceTemplates[0].URITemplate != "weather://stations/{id}" {
+		t.Errorf("resource templates = %+v", doc.ResourceTemplates)
+	}
+	if len(doc.Prompts) != 1 || doc.Prompts[0].Title != "Weather summary" ||
+		len(doc.Prompts[0].Arguments) != 1 || !doc.Prompts[0].Arguments[0].Required {
+		t.Errorf("prompts = %+v", doc.Prompts)
+	}
+}
+
+func TestServer_ManifestJSON_Empty(t *testing.T) {
+	data, err := New(Info{Name: "empty", Version: "0.1.0"}).ManifestJSON()
+	if err != nil {
+		t.Fatalf("ManifestJSON() error = %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	for _, key := range []string{"tools", "resources", "resourceTemplates", "prompts"} {
+		if string(doc[key]) != "[]" {
+			t.Errorf("%s = %s, want []", key, doc[key])
+		}
+	}
+	if _, ok := doc["instructions"]; ok {
+		t.Error("empty instructions included")
+	}
+}
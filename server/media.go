@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// AudioContent represents audio content in a prompt message.
+type AudioContent struct {
+	Type     string `json:"type"` // Always "audio"
+	Data     string `json:"data"` // Base64 encoded
+	MimeType string `json:"mimeType"`
+}
+
+// ValidateMimeType checks that mimeType is a well-formed media type of the
+// given kind ("image" or "audio"), such as "image/png" for kind "image".
+func ValidateMimeType(kind, mimeType string) error {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return fmt.Errorf("invalid %s MIME type %q: %w", kind, mimeType, err)
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	if major != kind {
+		return fmt.Errorf("invalid %s MIME type %q: not an %s type", kind, mimeType, kind)
+	}
+	return nil
+}
+
+// ImageFromBytes creates image content for a prompt message from raw image
+// bytes. It returns an error if mimeType is not an image type.
+func ImageFromBytes(data []byte, mimeType string) (ImageContent, error) {
+	if err := ValidateMimeType(ContentTypeImage, mimeType); err != nil {
+		return ImageContent{}, err
+	}
+	return ImageContent{
+		Type:     ContentTypeImage,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}, nil
+}
+
+// AudioFromBytes creates audio content for a prompt message from raw audio
+// bytes. It returns an error if mimeType is not an audio type.
+func AudioFromBytes(data []byte, mimeType string) (AudioContent, error) {
+	if err := ValidateMimeType(ContentTypeAudio, mimeType); err != nil {
+		return AudioContent{}, err
+	}
+	return AudioContent{
+		Type:     ContentTypeAudio,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}, nil
+}
+
+// Bytes decodes the base64 image data.
+func (c ImageContent) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(c.Data)
+}
+
+// Bytes decodes the base64 audio data.
+func (c AudioContent) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(c.Data)
+}
+
+// NewAudioContent creates an audio content block for sampling messages.
+// data must be base64 encoded.
+func NewAudioContent(mimeType, data string) Content {
+	return Content{
+		Type:     ContentTypeAudio,
+		MimeType: mimeType,
+		Data:     data,
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValidateMimeType(t *testing.T) {
+	tests := []struct {
+		kind     string
+		mimeType string
+		wantErr  bool
+	}{
+		{"image", "image/png", false},
+		{"image", "image/svg+xml", false},
+		{"audio", "audio/wav", false},
+		{"audio", "audio/ogg; codecs=opus", false},
+		{"image", "audio/wav", true},
+		{"audio", "image/png", true},
+		{"image", "", true},
+		{"image", "png", true},
+		{"image", "image/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.mimeType, func(t *testing.T) {
+			err := ValidateMimeType(tt.kind, tt.mimeType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMimeType(%q, %q) error = %v, wantErr %v", tt.kind, tt.mimeType, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageFromBytes(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+
+	img, err := ImageFromBytes(data, "image/png")
+	if err != nil {
+		t.Fatalf("ImageFromBytes() error = %v", err)
+	}
+	if img.Type != "image" || img.MimeType != "image/png" {
+		t.Errorf("image = %+v", img)
+	}
+	decoded, err := img.Bytes()
+	if err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("Bytes() = %v, %v; want %v", decoded, err, data)
+	}
+
+	if _, err := ImageFromBytes(data, "text/plain"); err == nil {
+		t.Error("expected error for non-image MIME type")
+	}
+}
+
+func TestAudioFromBytes(t *testing.T) {
+	data := []byte("RIFF....WAVE")
+
+	audio, err := AudioFromBytes(data, "audio/wav")
+	if err != nil {
+		t.Fatalf("AudioFromBytes() error = %v", err)
+	}
+	if audio.Type != "audio" || audio.MimeType != "audio/wav" {
+		t.Errorf("audio = %+v", audio)
+	}
+	decoded, err := audio.Bytes()
+	if err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("Bytes() = %v, %v; want %v", decoded, err, data)
+	}
+
+	if _, err := AudioFromBytes(data, "image/png"); err == nil {
+		t.Error("expected error for non-audio MIME type")
+	}
+}
+
+func TestToolResult_InvalidMimeType(t *testing.T) {
+	result := NewToolResult().
+		Text("ok").
+		Image([]byte("x"), "text/plain").
+		Audio([]byte("y"), "audio/mpeg")
+
+	if result.Err() == nil {
+		t.Fatal("expected error for invalid image MIME type")
+	}
+	if len(result.Content()) != 2 {
+		t.Errorf("got %d blocks, want 2 (invalid block skipped)", len(result.Content()))
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"net/url"
+	"path"
+)
+
+// MessageBuilder builds the messages of a prompt result with the content
+// type of each block filled in.
+//
+// Example:
+//
+//	srv.Prompt("describe").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+//	    msgs := server.Messages().
+//	        System("You describe images for screen readers.").
+//	        UserImage(photo, "image/png").
+//	        User("Describe this image.")
+//	    if err := msgs.Err(); err != nil {
+//	        return nil, err
+//	    }
+//	    return &server.PromptResult{Messages: msgs.Build()}, nil
+//	})
+type MessageBuilder struct {
+	messages []PromptMessage
+	err      error
+}
+
+// Messages creates an empty message builder.
+func Messages() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// System appends instructions for the model. MCP prompts have no system
+// role, so they are sent as a user message.
+func (b *MessageBuilder) System(text string) *MessageBuilder {
+	return b.User(text)
+}
+
+// User appends a text message from the user.
+func (b *MessageBuilder) User(text string) *MessageBuilder {
+	return b.add(RoleUser, TextContent{Type: ContentTypeText, Text: text})
+}
+
+// Assistant appends a text message from the assistant.
+func (b *MessageBuilder) Assistant(text string) *MessageBuilder {
+	return b.add(RoleAssistant, TextContent{Type: ContentTypeText, Text: text})
+}
+
+// UserImage appends an image from the user. data holds the raw image bytes
+// and is base64 encoded for transport. An invalid image MIME type is
+// reported by Err.
+func (b *MessageBuilder) UserImage(data []byte, mimeType string) *MessageBuilder {
+	return b.image(RoleUser, data, mimeType)
+}
+
+// AssistantImage appends an image from the assistant. data holds the raw
+// image bytes and is base64 encoded for transport. An invalid image MIME
+// type is reported by Err.
+func (b *MessageBuilder) AssistantImage(data []byte, mimeType string) *MessageBuilder {
+	return b.image(RoleAssistant, data, mimeType)
+}
+
+// UserAudio appends audio from the user. data holds the raw audio bytes and
+// is base64 encoded for transport. An invalid audio MIME type is reported
+// by Err.
+func (b *MessageBuilder) UserAudio(data []byte, mimeType string) *MessageBuilder {
+	return b.audio(RoleUser, data, mimeType)
+}
+
+// AssistantAudio appends audio from the assistant. data holds the raw audio
+// bytes and is base64 encoded for transport. An invalid audio MIME type is
+// reported by Err.
+func (b *MessageBuilder) AssistantAudio(data []byte, mimeType string) *MessageBuilder {
+	return b.audio(RoleAssistant, data, mimeType)
+}
+
+// UserResource appends the contents of a resource from the user.
+func (b *MessageBuilder) UserResource(content *ResourceContent) *MessageBuilder {
+	return b.add(RoleUser, NewEmbeddedResource(content))
+}
+
+// UserEmbed appends an embedded resource from the user, such as one
+// returned by Server.EmbedResource.
+func (b *MessageBuilder) UserEmbed(resource EmbeddedResource) *MessageBuilder {
+	return b.add(RoleUser, resource)
+}
+
+// UserLink appends a resource link from the user, such as one returned by
+// Server.LinkResource.
+func (b *MessageBuilder) UserLink(link ResourceLink) *MessageBuilder {
+	return b.add(RoleUser, link)
+}
+
+// Err returns the first error encountered while building the messages,
+// such as an invalid MIME type.
+func (b *MessageBuilder) Err() error {
+	return b.err
+}
+
+// Build returns the messages in the order they were added.
+func (b *MessageBuilder) Build() []PromptMessage {
+	messages := make([]PromptMessage, len(b.messages))
+	copy(messages, b.messages)
+	return messages
+}
+
+func (b *MessageBuilder) image(role Role, data []byte, mimeType string) *MessageBuilder {
+	content, err := ImageFromBytes(data, mimeType)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.add(role, content)
+}
+
+func (b *MessageBuilder) audio(role Role, data []byte, mimeType string) *MessageBuilder {
+	content, err := AudioFromBytes(data, mimeType)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.add(role, content)
+}
+
+func (b *MessageBuilder) fail(err error) *MessageBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+func (b *MessageBuilder) add(role Role, content any) *MessageBuilder {
+	b.messages = append(b.messages, PromptMessage{Role: string(role), Content: content})
+	return b
+}
+
+// PromptResultBuilder builds a prompt result fluently. It appends messages
+// like MessageBuilder and adds a description and resource links.
+//
+// Example:
+//
+//	srv.Prompt("review").Handler(func(ctx context.Context, args map[string]string) (*server.PromptResult, error) {
+//	    return server.NewPromptResult().
+//	        Description("Code review").
+//	        System("You are a careful code reviewer.").
+//	        User("Review this file:").
+//	        WithResource("file:///src/" + args["path"]).
+//	        Build()
+//	})
+type PromptResultBuilder struct {
+	description string
+	messages    MessageBuilder
+}
+
+// NewPromptResult creates an empty prompt result builder.
+func NewPromptResult() *PromptResultBuilder {
+	return &PromptResultBuilder{}
+}
+
+// Description sets the description of the result.
+func (b *PromptResultBuilder) Description(description string) *PromptResultBuilder {
+	b.description = description
+	return b
+}
+
+// System appends instructions for the model as a user message.
+func (b *PromptResultBuilder) System(text string) *PromptResultBuilder {
+	b.messages.System(text)
+	return b
+}
+
+// User appends a text message from the user.
+func (b *PromptResultBuilder) User(text string) *PromptResultBuilder {
+	b.messages.User(text)
+	return b
+}
+
+// Assistant appends a text message from the assistant.
+func (b *PromptResultBuilder) Assistant(text string) *PromptResultBuilder {
+	b.messages.Assistant(text)
+	return b
+}
+
+// UserImage appends an image from the user.
+func (b *PromptResultBuilder) UserImage(data []byte, mimeType string) *PromptResultBuilder {
+	b.messages.UserImage(data, mimeType)
+	return b
+}
+
+// AssistantImage appends an image from the assistant.
+func (b *PromptResultBuilder) AssistantImage(data []byte, mimeType string) *PromptResultBuilder {
+	b.messages.AssistantImage(data, mimeType)
+	return b
+}
+
+// UserAudio appends audio from the user.
+func (b *PromptResultBuilder) UserAudio(data []byte, mimeType string) *PromptResultBuilder {
+	b.messages.UserAudio(data, mimeType)
+	return b
+}
+
+// AssistantAudio appends audio from the assistant.
+func (b *PromptResultBuilder) AssistantAudio(data []byte, mimeType string) *PromptResultBuilder {
+	b.messages.AssistantAudio(data, mimeType)
+	return b
+}
+
+// WithResource appends a link to the resource at uri from the user. The
+// client reads the resource itself; use WithLink for a link with a name
+// and MIME type, such as one returned by Server.LinkResource.
+func (b *PromptResultBuilder) WithResource(uri string) *PromptResultBuilder {
+	name := uri
+	if u, err := url.Parse(uri); err == nil && u.Path != "" && path.Base(u.Path) != "/" {
+		name = path.Base(u.Path)
+	}
+	b.messages.UserLink(NewResourceLink(uri, name, mimeTypeByExtension(name)))
+	return b
+}
+
+// WithLink appends a resource link from the user.
+func (b *PromptResultBuilder) WithLink(link ResourceLink) *PromptResultBuilder {
+	b.messages.UserLink(link)
+	return b
+}
+
+// WithContent appends the contents of a resource from the user.
+func (b *PromptResultBuilder) WithContent(content *ResourceContent) *PromptResultBuilder {
+	b.messages.UserResource(content)
+	return b
+}
+
+// WithEmbed appends an embedded resource from the user, such as one
+// returned by Server.EmbedResource.
+func (b *PromptResultBuilder) WithEmbed(resource EmbeddedResource) *PromptResultBuilder {
+	b.messages.UserEmbed(resource)
+	return b
+}
+
+// Err returns the first error encountered while building the result.
+func (b *PromptResultBuilder) Err() error {
+	return b.messages.Err()
+}
+
+// Build returns the prompt result, or the first error encountered while
+// building it, such as an invalid MIME type.
+func (b *PromptResultBuilder) Build() (*PromptResult, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return &PromptResult{Description: b.description, Messages: b.messages.Build()}, nil
+}
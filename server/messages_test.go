@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	resource := &ResourceContent{URI: "file:///notes.md", MimeType: "text/markdown", Text: "# Notes"}
+
+	tests := []struct {
+		name    string
+		build   func(b *MessageBuilder) *MessageBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			build: func(b *MessageBuilder) *MessageBuilder { return b },
+			want:  `[]`,
+		},
+		{
+			name: "text messages",
+			build: func(b *MessageBuilder) *MessageBuilder {
+				return b.System("Be brief.").User("Hi").Assistant("Hello")
+			},
+			want: `[{"role":"user","content":{"type":"text","text":"Be brief."}},` +
+				`{"role":"user","content":{"type":"text","text":"Hi"}},` +
+				`{"role":"assistant","content":{"type":"text","text":"Hello"}}]`,
+		},
+		{
+			name: "media",
+			build: func(b *MessageBuilder) *MessageBuilder {
+				return b.UserImage([]byte("png"), "image/png").AssistantAudio([]byte("wav"), "audio/wav")
+			},
+			want: `[{"role":"user","content":{"type":"image","data":"cG5n","mimeType":"image/png"}},` +
+				`{"role":"assistant","content":{"type":"audio","data":"d2F2","mimeType":"audio/wav"}}]`,
+		},
+		{
+			name: "resources",
+			build: func(b *MessageBuilder) *MessageBuilder {
+				return b.UserResource(resource).UserLink(NewResourceLink("file:///a.go", "a.go", "text/x-go"))
+			},
+			want: `[{"role":"user","content":{"type":"resource","resource":{"uri":"file:///notes.md","mimeType":"text/markdown","text":"# Notes"}}},` +
+				`{"role":"user","content":{"type":"resource_link","uri":"file:///a.go","name":"a.go","mimeType":"text/x-go"}}]`,
+		},
+		{
+			name: "invalid MIME type",
+			build: func(b *MessageBuilder) *MessageBuilder {
+				return b.User("Look:").AssistantImage([]byte("x"), "text/plain").UserAudio([]byte("y"), "audio/mpeg")
+			},
+			want: `[{"role":"user","content":{"type":"text","text":"Look:"}},` +
+				`{"role":"user","content":{"type":"audio","data":"eQ==","mimeType":"audio/mpeg"}}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build(Messages())
+			if err := b.Err(); (err != nil) != tt.wantErr {
+				t.Fatalf("Err() = %v, wantErr %v", err, tt.wantErr)
+			}
+			data, err := json.Marshal(b.Build())
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("messages = %s\nwant %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageBuilder_BuildCopies(t *testing.T) {
+	b := Messages().User("first")
+	msgs := b.Build()
+	b.User("second")
+
+	if len(msgs) != 1 {
+		t.Errorf("len = %d, want 1", len(msgs))
+	}
+	if got := len(b.Build()); got != 2 {
+		t.Errorf("len = %d, want 2", got)
+	}
+}
+
+func TestPromptResultBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(b *PromptResultBuilder) *PromptResultBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			build: func(b *PromptResultBuilder) *PromptResultBuilder { return b },
+			want:  `{"messages":[]}`,
+		},
+		{
+			name: "conversation",
+			build: func(b *PromptResultBuilder) *PromptResultBuilder {
+				return b.Description("Review").System("Be brief.").User("Hi").Assistant("Hello")
+			},
+			want: `{"description":"Review","messages":[{"role":"user","content":{"type":"text","text":"Be brief."}},` +
+				`{"role":"user","content":{"type":"text","text":"Hi"}},` +
+				`{"role":"assistant","content":{"type":"text","text":"Hello"}}]}`,
+		},
+		{
+			name: "media",
+			build: func(b *PromptResultBuilder) *PromptResultBuilder {
+				return b.UserImage([]byte("png"), "image/png").AssistantImage([]byte("png"), "image/png").
+					UserAudio([]byte("wav"), "audio/wav").AssistantAudio([]byte("wav"), "audio/wav")
+			},
+			want: `{"messages":[{"role":"user","content":{"type":"image","data":"cG5n","mimeType":"image/png"}},` +
+				`{"role":"assistant","content":{"type":"image","data":"cG5n","mimeType":"image/png"}},` +
+				`{"role":"user","content":{"type":"audio","data":"d2F2","mimeType":"audio/wav"}},` +
+				`{"role":"assistant","content":{"type":"audio","data":"d2F2","mimeType":"audio/wav"}}]}`,
+		},
+		{
+			name: "resources",
+			build: func(b *PromptResultBuilder) *PromptResultBuilder {
+				return b.WithResource("file:///src/data.json").
+					WithResource("config://app").
+					WithLink(NewResourceLink("file:///a.md", "A", "text/markdown")).
+					WithContent(&ResourceContent{URI: "file:///notes.md", Text: "# Notes"}).
+					WithEmbed(NewEmbeddedResource(&ResourceContent{URI: "file:///b.md", Text: "B"}))
+			},
+			want: `{"messages":[{"role":"user","content":{"type":"resource_link","uri":"file:///src/data.json","name":"data.json","mimeType":"application/json"}},` +
+				`{"role":"user","content":{"type":"resource_link","uri":"config://app","name":"config://app"}},` +
+				`{"role":"user","content":{"type":"resource_link","uri":"file:///a.md","name":"A","mimeType":"text/markdown"}},` +
+				`{"role":"user","content":{"type":"resource","resource":{"uri":"file:///notes.md","text":"# Notes"}}},` +
+				`{"role":"user","content":{"type":"resource","resource":{"uri":"file:///b.md","text":"B"}}}]}`,
+		},
+		{
+			name: "invalid MIME type",
+			build: func(b *PromptResultBuilder) *PromptResultBuilder {
+				return b.User("Look:").AssistantImage([]byte("x"), "text/plain")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build(NewPromptResult())
+			result, err := b.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if b.Err() != err || result != nil {
+					t.Errorf("Build() = %v, want nil result with Err()", result)
+				}
+				return
+			}
+			data, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("result = %s\nwant %s", data, tt.want)
+			}
+		})
+	}
+}
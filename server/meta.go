@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// resultMeta collects the _meta entries of a result while its handler runs.
+type resultMeta struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// resultMetaKey is the context key for the result metadata collector.
+type resultMetaKey struct{}
+
+// ContextWithResultMeta returns a context that collects the metadata set
+// with WithResultMeta. Request handlers attach it before running a tool,
+// resource or prompt handler and send what was collected as _meta.
+func ContextWithResultMeta(ctx context.Context) context.Context {
+	return context.WithValue(ctx, resultMetaKey{}, &resultMeta{})
+}
+
+// WithResultMeta sets key to value in the _meta of the result being built,
+// such as a trace ID, a cache hint or the cost of the request. It does
+// nothing if ctx was not prepared with ContextWithResultMeta.
+//
+// Example:
+//
+//	srv.Tool("search").Handler(func(ctx context.Context, in SearchInput) (string, error) {
+//	    server.WithResultMeta(ctx, "traceId", traceID(ctx))
+//	    return search(ctx, in.Query)
+//	})
+func WithResultMeta(ctx context.Context, key string, value any) {
+	m, ok := ctx.Value(resultMetaKey{}).(*resultMeta)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	m.values[key] = value
+}
+
+// ResultMetaFromContext returns a copy of the metadata collected in ctx,
+// or nil if none was set.
+func ResultMetaFromContext(ctx context.Context) map[string]any {
+	m, ok := ctx.Value(resultMetaKey{}).(*resultMeta)
+	if !ok {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.values)
+}
+
+// MergeResultMeta returns the collected metadata of ctx overlaid with meta,
+// so that entries set on the result itself take precedence. It returns nil
+// if both are empty.
+func MergeResultMeta(ctx context.Context, meta map[string]any) map[string]any {
+	merged := ResultMetaFromContext(ctx)
+	if len(meta) == 0 {
+		return merged
+	}
+	if merged == nil {
+		merged = make(map[string]any, len(meta))
+	}
+	maps.Copy(merged, meta)
+	return merged
+}
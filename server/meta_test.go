@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestResultMeta(t *testing.T) {
+	t.Run("collects values set in a prepared context", func(t *testing.T) {
+		ctx := ContextWithResultMeta(context.Background())
+		WithResultMeta(ctx, "traceId", "abc")
+		WithResultMeta(ctx, "cost", 0.5)
+		WithResultMeta(ctx, "traceId", "def")
+
+		want := map[string]any{"traceId": "def", "cost": 0.5}
+		if got := ResultMetaFromContext(ctx); !reflect.DeepEqual(got, want) {
+			t.Errorf("ResultMetaFromContext() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ignores values without a collector", func(t *testing.T) {
+		ctx := context.Background()
+		WithResultMeta(ctx, "traceId", "abc")
+		if got := ResultMetaFromContext(ctx); got != nil {
+			t.Errorf("ResultMetaFromContext() = %v, want nil", got)
+		}
+	})
+
+	t.Run("returns nil when nothing was set", func(t *testing.T) {
+		ctx := ContextWithResultMeta(context.Background())
+		if got := ResultMetaFromContext(ctx); got != nil {
+			t.Errorf("ResultMetaFromContext() = %v, want nil", got)
+		}
+	})
+
+	t.Run("returns a copy", func(t *testing.T) {
+		ctx := ContextWithResultMeta(context.Background())
+		WithResultMeta(ctx, "traceId", "abc")
+		ResultMetaFromContext(ctx)["traceId"] = "changed"
+		if got := ResultMetaFromContext(ctx)["traceId"]; got != "abc" {
+			t.Errorf("traceId = %v, want abc", got)
+		}
+	})
+}
+
+func TestMergeResultMeta(t *testing.T) {
+	tests := []struct {
+		name      string
+		collected map[string]any
+		meta      map[string]any
+		want      map[string]any
+	}{
+		{name: "both empty"},
+		{
+			name:      "collected only",
+			collected: map[string]any{"traceId": "abc"},
+			want:      map[string]any{"traceId": "abc"},
+		},
+		{
+			name: "result only",
+			meta: map[string]any{"cache": "no-store"},
+			want: map[string]any{"cache": "no-store"},
+		},
+		{
+			name:      "result takes precedence",
+			collected: map[string]any{"traceId": "abc", "cost": 1},
+			meta:      map[string]any{"cost": 2},
+			want:      map[string]any{"traceId": "abc", "cost": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithResultMeta(context.Background())
+			for k, v := range tt.collected {
+				WithResultMeta(ctx, k, v)
+			}
+			if got := MergeResultMeta(ctx, tt.meta); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeResultMeta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ListOrder controls the order of tools, resources, resource templates and
+// prompts in list results. Either order is stable across calls, so clients
+// that cache lists and golden tests see the same result every time.
+type ListOrder int
+
+const (
+	// ListOrderName sorts items by name, and resources by URI template.
+	// It is the default.
+	ListOrderName ListOrder = iota
+	// ListOrderRegistration lists items in the order they were first
+	// registered. Registering an item again under the same name keeps
+	// its position; removing it and registering it again moves it last.
+	ListOrderRegistration
+)
+
+// WithListOrder sets the order of items in list results.
+//
+// Example:
+//
+//	srv := server.New(info, server.WithListOrder(server.ListOrderRegistration))
+func WithListOrder(order ListOrder) Option {
+	return func(s *Server) {
+		s.listOrder = order
+	}
+}
+
+// ListOrder returns the order of items in list results.
+func (s *Server) ListOrder() ListOrder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listOrder
+}
+
+// nextSeq returns the registration sequence for an item registered under
+// key, keeping the sequence of the item it replaces. s.mu must be held.
+func nextSeq[T any](s *Server, m map[string]T, key string, seq func(T) uint64) uint64 {
+	if existing, ok := m[key]; ok {
+		return seq(existing)
+	}
+	s.registrations++
+	return s.registrations
+}
+
+func toolSeq(t *Tool) uint64         { return t.seq }
+func resourceSeq(r *Resource) uint64 { return r.seq }
+func promptSeq(p *Prompt) uint64     { return p.seq }
+
+// ordered returns the values of m in list order: by key, or by
+// registration sequence.
+func ordered[T any](m map[string]T, order ListOrder, seq func(T) uint64) []T {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if order == ListOrderRegistration {
+		slices.SortFunc(keys, func(a, b string) int {
+			return cmp.Compare(seq(m[a]), seq(m[b]))
+		})
+	} else {
+		slices.Sort(keys)
+	}
+
+	values := make([]T, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return values
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func registerOrderFixtures(srv *Server) {
+	resource := func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri}, nil
+	}
+	prompt := func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+		return &PromptResult{}, nil
+	}
+	for _, name := range []string{"zeta", "alpha", "mu", "beta"} {
+		srv.Tool(name).Handler(func(ctx context.Context, input struct{}) (string, error) { return name, nil })
+		srv.Prompt(name).Handler(prompt)
+		srv.Resource("res://" + name).Name(name).Handler(resource)
+		srv.Resource("tpl://" + name + "/{id}").Name(name).Handler(resource)
+	}
+}
+
+func toolNames(srv *Server) []string {
+	var names []string
+	for _, t := range srv.Tools() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func promptNames(srv *Server) []string {
+	var names []string
+	for _, p := range srv.Prompts() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func resourceURIs(srv *Server) []string {
+	var uris []string
+	for _, r := range srv.Resources() {
+		uris = append(uris, r.URITemplate)
+	}
+	return uris
+}
+
+func templateURIs(srv *Server) []string {
+	var uris []string
+	for _, r := range srv.ResourceTemplates() {
+		uris = append(uris, r.URITemplate)
+	}
+	return uris
+}
+
+func TestListOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantNames     []string
+		wantResources []string
+		wantTemplates []string
+	}{
+		{
+			name:      "by name",
+			wantNames: []string{"alpha", "beta", "mu", "zeta"},
+			wantResources: []string{
+				"res://alpha", "res://beta", "res://mu", "res://zeta",
+				"tpl://alpha/{id}", "tpl://beta/{id}", "tpl://mu/{id}", "tpl://zeta/{id}",
+			},
+			wantTemplates: []string{"tpl://alpha/{id}", "tpl://beta/{id}", "tpl://mu/{id}", "tpl://zeta/{id}"},
+		},
+		{
+			name:      "by registration",
+			opts:      []Option{WithListOrder(ListOrderRegistration)},
+			wantNames: []string{"zeta", "alpha", "mu", "beta"},
+			wantResources: []string{
+				"res://zeta", "tpl://zeta/{id}", "res://alpha", "tpl://alpha/{id}",
+				"res://mu", "tpl://mu/{id}", "res://beta", "tpl://beta/{id}",
+			},
+			wantTemplates: []string{"tpl://zeta/{id}", "tpl://alpha/{id}", "tpl://mu/{id}", "tpl://beta/{id}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"}, tt.opts...)
+			registerOrderFixtures(srv)
+
+			// Map-backed registries must not shuffle between calls
+			for range 20 {
+				if got := toolNames(srv); !slices.Equal(got, tt.wantNames) {
+					t.Fatalf("tools = %v, want %v", got, tt.wantNames)
+				}
+				if got := promptNames(srv); !slices.Equal(got, tt.wantNames) {
+					t.Fatalf("prompts = %v, want %v", got, tt.wantNames)
+				}
+				if got := resourceURIs(srv); !slices.Equal(got, tt.wantResources) {
+					t.Fatalf("resources = %v, want %v", got, tt.wantResources)
+				}
+				if got := templateURIs(srv); !slices.Equal(got, tt.wantTemplates) {
+					t.Fatalf("templates = %v, want %v", got, tt.wantTemplates)
+				}
+			}
+		})
+	}
+}
+
+func TestListOrder_Reregistration(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithListOrder(ListOrderRegistration))
+	if srv.ListOrder() != ListOrderRegistration {
+		t.Fatalf("ListOrder() = %v, want ListOrderRegistration", srv.ListOrder())
+	}
+	registerOrderFixtures(srv)
+
+	// Replacing a tool keeps its position
+	srv.Tool("alpha").Description("v2").Handler(func(ctx context.Context, input struct{}) (string, error) { return "", nil })
+	if got, want := toolNames(srv), []string{"zeta", "alpha", "mu", "beta"}; !slices.Equal(got, want) {
+		t.Errorf("after replace, tools = %v, want %v", got, want)
+	}
+
+	// Removing and adding it again moves it last
+	srv.RemoveTool("alpha")
+	srv.Tool("alpha").Handler(func(ctx context.Context, input struct{}) (string, error) { return "", nil })
+	if got, want := toolNames(srv), []string{"zeta", "mu", "beta", "alpha"}; !slices.Equal(got, want) {
+		t.Errorf("after re-add, tools = %v, want %v", got, want)
+	}
+}
+
+func TestFindResourceForURI_Order(t *testing.T) {
+	handler := func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri}, nil
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "by name", want: "files://readme"},
+		{name: "by registration", opts: []Option{WithListOrder(ListOrderRegistration)}, want: "files://{path}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"}, tt.opts...)
+			srv.Resource("files://{path}").Name("file").Handler(handler)
+			srv.Resource("files://readme").Name("readme").Handler(handler)
+
+			for range 20 {
+				r, ok := srv.FindResourceForURI("files://readme")
+				if !ok {
+					t.Fatal("no resource found")
+				}
+				if r.uriTemplate != tt.want {
+					t.Fatalf("matched %q, want %q", r.uriTemplate, tt.want)
+				}
+			}
+		})
+	}
+}
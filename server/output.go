@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// OutputSchema declares the JSON Schema of the tool's results, advertised
+// as outputSchema in tools/list. schema may be a *schema.Schema, a
+// json.RawMessage or any value that encodes to a JSON Schema; MCP requires
+// an object schema. Results of a tool with an output schema are also sent
+// as structuredContent, so clients can use them without parsing text.
+//
+// Example:
+//
+//	out, _ := schema.Generate(Forecast{})
+//	srv.Tool("forecast").OutputSchema(out).ValidateOutput().Handler(forecast)
+func (b *ToolBuilder) OutputSchema(s any) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	s, validatable, err := parseSchema(s)
+	if err != nil {
+		b.err = fmt.Errorf("invalid output schema: %w", err)
+		return b
+	}
+	b.tool.outputSchema = s
+	b.tool.outputValidatable = validatable
+	return b
+}
+
+// ValidateOutput checks the results of the tool against its output schema
+// before they are sent. See Server.CheckToolOutput for how a mismatch is
+// reported. WithOutputValidation enables it for every tool of a server.
+func (b *ToolBuilder) ValidateOutput() *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.tool.validateOutput = true
+	return b
+}
+
+// WithOutputValidation checks the results of every tool that declares an
+// output schema before they are sent, so a server does not silently ship
+// results that contradict its advertised schema. See
+// Server.CheckToolOutput.
+func WithOutputValidation() Option {
+	return func(s *Server) {
+		s.strictOutput = true
+	}
+}
+
+// OutputSchema returns the output schema declared with
+// ToolBuilder.OutputSchema, or nil.
+func (t *Tool) OutputSchema() any {
+	return t.outputSchema
+}
+
+// CheckToolOutput validates result, as returned by t.Execute, against the
+// output schema of t if output validation is enabled for it. A mismatch is
+// a bug in the server: in debug mode (WithDebug) CheckToolOutput returns
+// an internal error listing the invalid fields as ValidationErrorData, so
+// the call fails loudly during development. Otherwise it reports the
+// error to the OnError hooks and returns nil, and the result is sent.
+// For a *ToolResult the structured content is checked, if set.
+//
+// Request handlers call it after a tool runs successfully.
+func (s *Server) CheckToolOutput(ctx context.Context, t *Tool, result any) error {
+	if !t.validateOutput || t.outputValidatable == nil {
+		return nil
+	}
+	if rich, ok := result.(*ToolResult); ok {
+		if rich == nil || rich.structured == nil {
+			return nil
+		}
+		result = rich.structured
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return protocol.NewInternalError(fmt.Sprintf("encode tool result: %v", err))
+	}
+	if err := t.outputValidatable.Validate(data); err != nil {
+		outputErr := protocol.NewInternalError(fmt.Sprintf("tool %s: output validation failed: %v", t.name, err)).
+			WithData(validationErrorData(err))
+		if s.Debug() {
+			return outputErr
+		}
+		s.HookError(ctx, ErrorEvent{Method: protocol.MethodToolsCall, Err: outputErr})
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/schema"
+)
+
+type forecast struct {
+	City  string  `json:"city" jsonschema:"required"`
+	TempC float64 `json:"tempC"`
+}
+
+func TestToolBuilder_OutputSchema(t *testing.T) {
+	t.Run("advertised in tools list", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		out, _ := schema.Generate(forecast{})
+		srv.Tool("forecast").OutputSchema(out).Handler(func(struct{}) (forecast, error) { return forecast{}, nil })
+
+		tool, _ := srv.GetTool("forecast")
+		if tool.OutputSchema() != out {
+			t.Errorf("OutputSchema() = %v, want %v", tool.OutputSchema(), out)
+		}
+		if got := srv.Tools()[0].OutputSchema; got != out {
+			t.Errorf("ToolInfo.OutputSchema = %v, want %v", got, out)
+		}
+	})
+
+	t.Run("raw JSON", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Tool("forecast").
+			OutputSchema(json.RawMessage(`{"type":"object","required":["city"]}`)).
+			Handler(func(struct{}) (forecast, error) { return forecast{}, nil })
+
+		got, ok := srv.Tools()[0].OutputSchema.(map[string]any)
+		if !ok || got["type"] != "object" {
+			t.Errorf("OutputSchema = %#v, want decoded object schema", srv.Tools()[0].OutputSchema)
+		}
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		b := srv.Tool("forecast").OutputSchema(make(chan int))
+		if b.Err() == nil {
+			t.Error("Err() = nil, want invalid output schema")
+		}
+	})
+
+	t.Run("none by default", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Tool("forecast").Handler(func(struct{}) (forecast, error) { return forecast{}, nil })
+
+		if got := srv.Tools()[0].OutputSchema; got != nil {
+			t.Errorf("OutputSchema = %v, want nil", got)
+		}
+	})
+}
+
+func TestServer_CheckToolOutput(t *testing.T) {
+	out, _ := schema.Generate(forecast{})
+	valid := map[string]any{"city": "Berlin", "tempC": 21.5}
+	invalid := map[string]any{"tempC": "warm"}
+
+	tests := []struct {
+		name       string
+		debug      bool
+		perTool    bool
+		serverWide bool
+		result     any
+		wantErr    bool
+		wantHook   bool
+	}{
+		{name: "valid result", serverWide: true, debug: true, result: valid},
+		{name: "invalid result in debug mode", serverWide: true, debug: true, result: invalid, wantErr: true},
+		{name: "invalid result in production", serverWide: true, result: invalid, wantHook: true},
+		{name: "per-tool validation", perTool: true, debug: true, result: invalid, wantErr: true},
+		{name: "validation disabled", debug: true, result: invalid},
+		{name: "tool result structured content", serverWide: true, debug: true, result: NewToolResult().Structured(invalid), wantErr: true},
+		{name: "tool result without structured content", serverWide: true, debug: true, result: NewToolResult().Text("hi")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithDebug(tt.debug)}
+			if tt.serverWide {
+				opts = append(opts, WithOutputValidation())
+			}
+			srv := New(Info{Name: "test", Version: "1.0.0"}, opts...)
+
+			var hooked []ErrorEvent
+			srv.OnError(func(ctx context.Context, failure ErrorEvent) {
+				hooked = append(hooked, failure)
+			})
+
+			b := srv.Tool("forecast").OutputSchema(out)
+			if tt.perTool {
+				b.ValidateOutput()
+			}
+			b.Handler(func(struct{}) (any, error) { return tt.result, nil })
+			tool, _ := srv.GetTool("forecast")
+
+			err := srv.CheckToolOutput(context.Background(), tool, tt.result)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckToolOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var rpcErr *protocol.Error
+				if !errors.As(err, &rpcErr) || rpcErr.Code != protocol.CodeInternalError {
+					t.Fatalf("error = %v, want internal error", err)
+				}
+				data, ok := rpcErr.Data.(ValidationErrorData)
+				if !ok || len(data.Errors) != 2 {
+					t.Errorf("Data = %+v, want the missing city and the invalid tempC", rpcErr.Data)
+				}
+			}
+			if got := len(hooked) > 0; got != tt.wantHook {
+				t.Errorf("OnError called = %v, want %v", got, tt.wantHook)
+			}
+			if tt.wantHook && hooked[0].Method != protocol.MethodToolsCall {
+				t.Errorf("Method = %q, want %q", hooked[0].Method, protocol.MethodToolsCall)
+			}
+		})
+	}
+
+	t.Run("tool without output schema", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithOutputValidation(), WithDebug(true))
+		srv.Tool("echo").Handler(func(struct{}) (string, error) { return "", nil })
+		tool, _ := srv.GetTool("echo")
+
+		if err := srv.CheckToolOutput(context.Background(), tool, 42); err != nil {
+			t.Errorf("CheckToolOutput() error = %v, want nil", err)
+		}
+	})
+}
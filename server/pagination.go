@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// cursorPrefix marks cursors issued by this server.
+const cursorPrefix = "offset:"
+
+// WithPageSize sets the maximum number of items returned per page by
+// tools/list, resources/list, resources/templates/list and prompts/list.
+// A size of zero or less disables pagination (the default).
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.pageSize = size
+	}
+}
+
+// PageSize returns the configured page size, or zero if pagination is disabled.
+func (s *Server) PageSize() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pageSize
+}
+
+// ToolsPage returns the page of tools starting at cursor.
+// An empty cursor starts at the beginning. The returned next cursor is
+// empty when there are no more pages.
+func (s *Server) ToolsPage(cursor string) ([]ToolInfo, string, error) {
+	return paginate(s.Tools(), cursor, s.PageSize())
+}
+
+// ResourcesPage returns the page of resources starting at cursor.
+func (s *Server) ResourcesPage(cursor string) ([]ResourceInfo, string, error) {
+	return paginate(s.Resources(), cursor, s.PageSize())
+}
+
+// ResourceTemplatesPage returns the page of resource templates starting at cursor.
+func (s *Server) ResourceTemplatesPage(cursor string) ([]ResourceTemplateInfo, string, error) {
+	return paginate(s.ResourceTemplates(), cursor, s.PageSize())
+}
+
+// PromptsPage returns the page of prompts starting at cursor.
+func (s *Server) PromptsPage(cursor string) ([]PromptInfo, string, error) {
+	return paginate(s.Prompts(), cursor, s.PageSize())
+}
+
+// paginate slices items into a page starting at the offset encoded in cursor.
+func paginate[T any](items []T, cursor string, size int) ([]T, string, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset > len(items) {
+		return nil, "", protocol.NewInvalidParams("invalid cursor: out of range")
+	}
+
+	if size <= 0 || offset+size >= len(items) {
+		return items[offset:], "", nil
+	}
+
+	end := offset + size
+	return items[offset:end], encodeCursor(end), nil
+}
+
+// encodeCursor encodes an offset as an opaque cursor string.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) <= len(cursorPrefix) || string(raw[:len(cursorPrefix)]) != cursorPrefix {
+		return 0, protocol.NewInvalidParams("invalid cursor")
+	}
+
+	offset, err := strconv.Atoi(string(raw[len(cursorPrefix):]))
+	if err != nil || offset < 0 {
+		return 0, protocol.NewInvalidParams("invalid cursor")
+	}
+	return offset, nil
+}
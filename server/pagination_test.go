@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+func TestServer_ToolsPage(t *testing.T) {
+	type Input struct{}
+	handler := func(ctx context.Context, input Input) (string, error) { return "", nil }
+
+	newServer := func(opts ...Option) *Server {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, opts...)
+		for _, name := range []string{"echo", "add", "delete", "copy", "bump"} {
+			srv.Tool(name).Handler(handler)
+		}
+		return srv
+	}
+
+	t.Run("returns all tools sorted when pagination disabled", func(t *testing.T) {
+		srv := newServer()
+		tools, next, err := srv.ToolsPage("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if next != "" {
+			t.Errorf("next = %q, want empty", next)
+		}
+		want := []string{"add", "bump", "copy", "delete", "echo"}
+		if len(tools) != len(want) {
+			t.Fatalf("len(tools) = %d, want %d", len(tools), len(want))
+		}
+		for i, name := range want {
+			if tools[i].Name != name {
+				t.Errorf("tools[%d] = %q, want %q", i, tools[i].Name, name)
+			}
+		}
+	})
+
+	t.Run("walks pages until cursor is empty", func(t *testing.T) {
+		srv := newServer(WithPageSize(2))
+		var names []string
+		cursor := ""
+		pages := 0
+		for {
+			tools, next, err := srv.ToolsPage(cursor)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pages++
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if pages != 3 {
+			t.Errorf("pages = %d, want 3", pages)
+		}
+		if len(names) != 5 || names[0] != "add" || names[4] != "echo" {
+			t.Errorf("names = %v", names)
+		}
+	})
+
+	t.Run("rejects invalid cursor", func(t *testing.T) {
+		srv := newServer(WithPageSize(2))
+		for _, cursor := range []string{"!!!", encodeCursor(99), "b2Zmc2V0Oi0x"} {
+			if _, _, err := srv.ToolsPage(cursor); err == nil {
+				t.Errorf("cursor %q: expected error", cursor)
+			}
+		}
+	})
+}
+
+func TestServer_PageSize(t *testing.T) {
+	if got := New(Info{Name: "test"}).PageSize(); got != 0 {
+		t.Errorf("PageSize() = %d, want 0", got)
+	}
+	if got := New(Info{Name: "test"}, WithPageSize(10)).PageSize(); got != 10 {
+		t.Errorf("PageSize() = %d, want 10", got)
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 50, 12345} {
+		got, err := decodeCursor(encodeCursor(offset))
+		if err != nil {
+			t.Fatalf("decodeCursor: %v", err)
+		}
+		if got != offset {
+			t.Errorf("offset = %d, want %d", got, offset)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
 // TextContent represents text content in a prompt message.
@@ -43,18 +45,46 @@ type PromptHandler func(ctx context.Context, args map[string]string) (*PromptRes
 // Prompt represents a prompt template exposed via MCP.
 type Prompt struct {
 	name        string
+	title       string
 	description string
 	arguments   []PromptArgument
 	handler     PromptHandler
 	annotations *PromptAnnotations
+	icons       []Icon
+
+	// Position in registration order
+	seq uint64
 }
 
 // PromptInfo represents metadata about a registered prompt.
 type PromptInfo struct {
 	Name        string
+	Title       string
 	Description string
 	Arguments   []PromptArgument
 	Annotations *PromptAnnotations
+	Icons       []Icon
+}
+
+// ListItem returns the prompt as advertised in prompts/list.
+func (p PromptInfo) ListItem() protocol.Prompt {
+	item := protocol.Prompt{
+		Name:        p.Name,
+		Title:       p.Title,
+		Description: p.Description,
+		Icons:       p.Icons,
+	}
+	for _, arg := range p.Arguments {
+		item.Arguments = append(item.Arguments, protocol.PromptArgument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	if p.Annotations != nil {
+		item.Annotations = p.Annotations
+	}
+	return item
 }
 
 // PromptBuilder provides a fluent API for building prompts.
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Template registers the prompt with a handler rendering tmpl, a
+// text/template string or a *template.Template, with the prompt arguments
+// as a map, into a single user message. Arguments the template references,
+// such as {{.language}}, are added as required arguments unless declared
+// with Argument, which also sets their description. Rendering fails if an
+// argument the template uses is missing. An invalid template is reported
+// by Err.
+//
+// Example:
+//
+//	srv.Prompt("code-review").
+//	    Description("Review code").
+//	    Argument("focus", "What to focus on", false).
+//	    Template("Review this {{.language}} code{{if .focus}}, focusing on {{.focus}}{{end}}:\n{{.code}}")
+func (b *PromptBuilder) Template(tmpl any) *PromptBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	var t *template.Template
+	switch v := tmpl.(type) {
+	case string:
+		parsed, err := template.New(b.prompt.name).Parse(v)
+		if err != nil {
+			b.err = fmt.Errorf("prompt %s: %w", b.prompt.name, err)
+			return b
+		}
+		t = parsed
+	case *template.Template:
+		if v == nil {
+			b.err = fmt.Errorf("prompt %s: nil template", b.prompt.name)
+			return b
+		}
+		clone, err := v.Clone()
+		if err != nil {
+			b.err = fmt.Errorf("prompt %s: %w", b.prompt.name, err)
+			return b
+		}
+		t = clone
+	default:
+		b.err = fmt.Errorf("prompt %s: template must be a string or *template.Template, got %T", b.prompt.name, tmpl)
+		return b
+	}
+	t.Option("missingkey=error")
+
+	for _, name := range templateArguments(t) {
+		declared := slices.ContainsFunc(b.prompt.arguments, func(a PromptArgument) bool {
+			return a.Name == name
+		})
+		if !declared {
+			b.prompt.arguments = append(b.prompt.arguments, PromptArgument{Name: name, Required: true})
+		}
+	}
+
+	prompt := b.prompt
+	return b.Handler(func(ctx context.Context, args map[string]string) (*PromptResult, error) {
+		data := make(map[string]string, len(prompt.arguments)+len(args))
+		for _, a := range prompt.arguments {
+			data[a.Name] = ""
+		}
+		for k, v := range args {
+			data[k] = v
+		}
+
+		var sb strings.Builder
+		if err := t.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("render prompt: %w", err)
+		}
+		return &PromptResult{
+			Description: prompt.description,
+			Messages:    Messages().User(sb.String()).Build(),
+		}, nil
+	})
+}
+
+// Err returns the error that stopped the prompt from being registered,
+// such as an invalid template, or nil.
+func (b *PromptBuilder) Err() error {
+	return b.err
+}
+
+// templateArguments returns the names of the top-level fields a template
+// references, such as language in {{.language}} or {{$.language}}, in the
+// order they first appear.
+func templateArguments(t *template.Template) []string {
+	if t.Tree == nil {
+		return nil
+	}
+	var c argCollector
+	c.node(t.Tree.Root, true)
+	return c.names
+}
+
+// argCollector collects the argument names of a template parse tree. Dot
+// holds the arguments only at the top level: inside range and with, only
+// fields of $ are arguments.
+type argCollector struct {
+	names []string
+}
+
+func (c *argCollector) add(name string) {
+	if !slices.Contains(c.names, name) {
+		c.names = append(c.names, name)
+	}
+}
+
+func (c *argCollector) node(n parse.Node, root bool) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.node(child, root)
+		}
+	case *parse.ActionNode:
+		c.pipe(n.Pipe, root)
+	case *parse.TemplateNode:
+		c.pipe(n.Pipe, root)
+	case *parse.IfNode:
+		c.pipe(n.Pipe, root)
+		c.node(n.List, root)
+		c.node(n.ElseList, root)
+	case *parse.RangeNode:
+		c.pipe(n.Pipe, root)
+		c.node(n.List, false)
+		c.node(n.ElseList, root)
+	case *parse.WithNode:
+		c.pipe(n.Pipe, root)
+		c.node(n.List, false)
+		c.node(n.ElseList, root)
+	}
+}
+
+func (c *argCollector) pipe(p *parse.PipeNode, root bool) {
+	if p == nil {
+		return
+	}
+	for _, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			c.arg(arg, root)
+		}
+	}
+}
+
+func (c *argCollector) arg(n parse.Node, root bool) {
+	switch n := n.(type) {
+	case *parse.FieldNode:
+		if root {
+			c.add(n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			c.add(n.Ident[1])
+		}
+	case *parse.PipeNode:
+		c.pipe(n, root)
+	case *parse.ChainNode:
+		if p, ok := n.Node.(*parse.PipeNode); ok {
+			c.pipe(p, root)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "fields", text: "Review {{.language}} code:\n{{.code}}", want: []string{"language", "code"}},
+		{name: "repeated", text: "{{.a}} {{.b}} {{.a}}", want: []string{"a", "b"}},
+		{name: "conditions", text: "{{if .focus}}Focus on {{.focus}}{{else}}{{.fallback}}{{end}}", want: []string{"focus", "fallback"}},
+		{name: "pipelines", text: "{{.name | printf \"%q\"}} {{printf \"%s-%s\" .first (.last)}}", want: []string{"name", "first", "last"}},
+		{name: "range scope", text: "{{range .items}}{{.field}} {{$.prefix}}{{end}}", want: []string{"items", "prefix"}},
+		{name: "with scope", text: "{{with .user}}{{.ignored}}{{else}}{{.anonymous}}{{end}}", want: []string{"user", "anonymous"}},
+		{name: "none", text: "Static text", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("t").Parse(tt.text))
+			got := templateArguments(tmpl)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("templateArguments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptBuilder_Template(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("extracts and renders arguments", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		b := srv.Prompt("review").
+			Description("Review code").
+			Argument("focus", "What to focus on", false).
+			Template("Review this {{.language}} code{{if .focus}}, focusing on {{.focus}}{{end}}:\n{{.code}}")
+		if err := b.Err(); err != nil {
+			t.Fatalf("Err() = %v", err)
+		}
+
+		prompt, ok := srv.GetPrompt("review")
+		if !ok {
+			t.Fatal("prompt not registered")
+		}
+		args := prompt.arguments
+		if len(args) != 3 || args[0].Name != "focus" || args[0].Required ||
+			args[1] != (PromptArgument{Name: "language", Required: true}) ||
+			args[2] != (PromptArgument{Name: "code", Required: true}) {
+			t.Errorf("arguments = %+v", args)
+		}
+
+		result, err := prompt.Get(ctx, map[string]string{"language": "Go", "code": "x := 1"})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if result.Description != "Review code" || len(result.Messages) != 1 {
+			t.Fatalf("result = %+v", result)
+		}
+		if msg := result.Messages[0]; msg.Role != "user" || msg.Content.(TextContent).Text != "Review this Go code:\nx := 1" {
+			t.Errorf("message = %+v", msg)
+		}
+
+		result, _ = prompt.Get(ctx, map[string]string{"language": "Go", "code": "x := 1", "focus": "naming"})
+		if got := result.Messages[0].Content.(TextContent).Text; got != "Review this Go code, focusing on naming:\nx := 1" {
+			t.Errorf("Text = %q", got)
+		}
+	})
+
+	t.Run("missing argument", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Prompt("greet").Template("Hello {{.name}}")
+		prompt, _ := srv.GetPrompt("greet")
+
+		_, err := prompt.Get(ctx, map[string]string{})
+		if err == nil || !strings.Contains(err.Error(), "name") {
+			t.Errorf("Get() error = %v, want missing argument name", err)
+		}
+	})
+
+	t.Run("parsed template", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		tmpl := template.Must(template.New("shout").Funcs(template.FuncMap{"upper": strings.ToUpper}).Parse("{{upper .word}}!"))
+		srv.Prompt("shout").Template(tmpl)
+		prompt, _ := srv.GetPrompt("shout")
+
+		result, err := prompt.Get(ctx, map[string]string{"word": "hey"})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got := result.Messages[0].Content.(TextContent).Text; got != "HEY!" {
+			t.Errorf("Text = %q, want HEY!", got)
+		}
+	})
+
+	t.Run("render error", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Prompt("bad").Template(`{{template "missing" .}}`)
+		prompt, _ := srv.GetPrompt("bad")
+
+		if _, err := prompt.Get(ctx, nil); err == nil {
+			t.Error("Get() error = nil, want render error")
+		}
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for name, tmpl := range map[string]any{
+			"syntax": "Hello {{.name",
+			"type":   42,
+			"nil":    (*template.Template)(nil),
+		} {
+			srv := New(Info{Name: "test", Version: "1.0.0"})
+			b := srv.Prompt("broken").Template(tmpl)
+			if b.Err() == nil {
+				t.Errorf("%s: Err() = nil, want error", name)
+			}
+			if _, ok := srv.GetPrompt("broken"); ok {
+				t.Errorf("%s: prompt registered despite error", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// ErrResourceNotFound is returned by a ResourceProvider that does not
+// serve the requested URI.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ResourceEntry describes a resource served by a ResourceProvider.
+type ResourceEntry struct {
+	URI         string
+	Name        string
+	Description string
+	MimeType    string
+}
+
+// ResourceProvider serves a catalog of resources from an external source,
+// such as a file system, an object store or an HTTP origin, without
+// registering each resource with the builder.
+//
+// Read returns an error wrapping ErrResourceNotFound for URIs the provider
+// does not serve, so the server can try the next provider. Watch reports
+// changed URIs to onChange until ctx is done; providers that cannot watch
+// their source return nil immediately.
+type ResourceProvider interface {
+	List(ctx context.Context) ([]ResourceEntry, error)
+	Read(ctx context.Context, uri string) (*ResourceContent, error)
+	Watch(ctx context.Context, onChange func(uri string)) error
+}
+
+// AddResourceProvider registers a provider whose resources are listed by
+// resources/list and read by resources/read. Resources registered with
+// Resource take precedence; providers are consulted in the order they
+// were added.
+func (s *Server) AddResourceProvider(p ResourceProvider) {
+	s.mu.Lock()
+	s.providers = append(s.providers, p)
+	s.mu.Unlock()
+
+	s.listChanged.emit(ListKindResources)
+}
+
+// ResourceProviders returns the registered resource providers.
+func (s *Server) ResourceProviders() []ResourceProvider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ResourceProvider(nil), s.providers...)
+}
+
+// ListResources returns the registered resources followed by the
+// resources of every provider.
+func (s *Server) ListResources(ctx context.Context) ([]ResourceInfo, error) {
+	result := s.Resources()
+	for _, p := range s.ResourceProviders() {
+		entries, err := p.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			result = append(result, ResourceInfo{
+				URITemplate: e.URI,
+				Name:        e.Name,
+				Description: e.Description,
+				MimeType:    e.MimeType,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ListResourcesPage returns the page of ListResources starting at cursor.
+func (s *Server) ListResourcesPage(ctx context.Context, cursor string) ([]ResourceInfo, string, error) {
+	resources, err := s.ListResources(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return paginate(resources, cursor, s.PageSize())
+}
+
+// ReadResource reads the resource at uri from the registered resources or,
+// if none matches, from the providers. It returns a not found error if no
+// resource or provider serves uri.
+func (s *Server) ReadResource(ctx context.Context, uri string) (*ResourceContent, error) {
+	if resource, ok := s.FindResourceForURI(uri); ok {
+		if resource.cached {
+			return s.readCached(ctx, resource, uri)
+		}
+		return resource.Read(ctx, uri)
+	}
+
+	for _, p := range s.ResourceProviders() {
+		content, err := p.Read(ctx, uri)
+		if errors.Is(err, ErrResourceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			content = &ResourceContent{}
+		}
+		if content.URI == "" {
+			content.URI = uri
+		}
+		return content, nil
+	}
+
+	return nil, protocol.NewNotFound("resource not found: " + uri)
+}
+
+// WatchResources runs Watch on every provider, and watches the files of
+// resources registered with ResourceBuilder.WatchFile, until ctx is done,
+// calling onChange with the URI of each changed resource. Use it to notify
+// subscribed clients, for example with Server.NotifyResourceUpdated.
+// It returns the first watch error, or nil once all watches have ended.
+func (s *Server) WatchResources(ctx context.Context, onChange func(uri string)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	watches := []func(context.Context, func(string)) error{s.watchResourceFiles}
+	for _, p := range s.ResourceProviders() {
+		watches = append(watches, p.Watch)
+	}
+	for _, watch := range watches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watch(ctx, onChange); err != nil && !errors.Is(err, context.Canceled) {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// staticProvider serves a fixed set of text resources.
+type staticProvider struct {
+	docs    map[string]string
+	listErr error
+	changes []string
+	watchFn func(ctx context.Context) error
+}
+
+func (p *staticProvider) List(ctx context.Context) ([]ResourceEntry, error) {
+	if p.listErr != nil {
+		return nil, p.listErr
+	}
+	entries := make([]ResourceEntry, 0, len(p.docs))
+	for uri := range p.docs {
+		entries = append(entries, ResourceEntry{URI: uri, Name: uri, MimeType: "text/plain"})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URI < entries[j].URI })
+	return entries, nil
+}
+
+func (p *staticProvider) Read(ctx context.Context, uri string) (*ResourceContent, error) {
+	text, ok := p.docs[uri]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	return &ResourceContent{Text: text}, nil
+}
+
+func (p *staticProvider) Watch(ctx context.Context, onChange func(uri string)) error {
+	for _, uri := range p.changes {
+		onChange(uri)
+	}
+	if p.watchFn != nil {
+		return p.watchFn(ctx)
+	}
+	return nil
+}
+
+func TestServer_AddResourceProvider(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	srv.Resource("config://app").
+		Name("config").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri, Text: "registered"}, nil
+		})
+	srv.AddResourceProvider(&staticProvider{docs: map[string]string{
+		"config://app": "shadowed",
+		"notes://a":    "first",
+	}})
+	srv.AddResourceProvider(&staticProvider{docs: map[string]string{
+		"notes://a": "second",
+		"notes://b": "second",
+	}})
+
+	t.Run("lists registered and provider resources", func(t *testing.T) {
+		resources, err := srv.ListResources(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var uris []string
+		for _, r := range resources {
+			uris = append(uris, r.URITemplate)
+		}
+		want := []string{"config://app", "config://app", "notes://a", "notes://a", "notes://b"}
+		if len(uris) != len(want) {
+			t.Fatalf("uris = %v, want %v", uris, want)
+		}
+		for i := range want {
+			if uris[i] != want[i] {
+				t.Errorf("uris[%d] = %q, want %q", i, uris[i], want[i])
+			}
+		}
+	})
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantText string
+		wantCode int
+	}{
+		{name: "registered resource wins", uri: "config://app", wantText: "registered"},
+		{name: "first provider wins", uri: "notes://a", wantText: "first"},
+		{name: "falls through to later provider", uri: "notes://b", wantText: "second"},
+		{name: "not found", uri: "notes://c", wantCode: protocol.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := srv.ReadResource(context.Background(), tt.uri)
+			if tt.wantCode != 0 {
+				var mcpErr *protocol.Error
+				if !errors.As(err, &mcpErr) || mcpErr.Code != tt.wantCode {
+					t.Fatalf("expected error code %d, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if content.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", content.Text, tt.wantText)
+			}
+			if content.URI != tt.uri {
+				t.Errorf("URI = %q, want %q", content.URI, tt.uri)
+			}
+		})
+	}
+}
+
+func TestServer_ListResourcesError(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	listErr := errors.New("origin unavailable")
+	srv.AddResourceProvider(&staticProvider{listErr: listErr})
+
+	if _, err := srv.ListResources(context.Background()); !errors.Is(err, listErr) {
+		t.Errorf("error = %v, want %v", err, listErr)
+	}
+	if _, _, err := srv.ListResourcesPage(context.Background(), ""); !errors.Is(err, listErr) {
+		t.Errorf("page error = %v, want %v", err, listErr)
+	}
+}
+
+func TestServer_ListResourcesPage(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithPageSize(2))
+	srv.AddResourceProvider(&staticProvider{docs: map[string]string{
+		"notes://a": "", "notes://b": "", "notes://c": "",
+	}})
+
+	page, next, err := srv.ListResourcesPage(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || next == "" {
+		t.Fatalf("first page = %v, next = %q", page, next)
+	}
+
+	page, next, err = srv.ListResourcesPage(context.Background(), next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].URITemplate != "notes://c" || next != "" {
+		t.Errorf("second page = %v, next = %q", page, next)
+	}
+}
+
+func TestServer_WatchResources(t *testing.T) {
+	t.Run("reports changes from all providers", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.AddResourceProvider(&staticProvider{changes: []string{"notes://a"}})
+		srv.AddResourceProvider(&staticProvider{changes: []string{"notes://b", "notes://c"}})
+
+		var mu sync.Mutex
+		var changed []string
+		err := srv.WatchResources(context.Background(), func(uri string) {
+			mu.Lock()
+			defer mu.Unlock()
+			changed = append(changed, uri)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		sort.Strings(changed)
+		if len(changed) != 3 || changed[0] != "notes://a" || changed[2] != "notes://c" {
+			t.Errorf("changed = %v", changed)
+		}
+	})
+
+	t.Run("stops all watches on error", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		watchErr := errors.New("watch failed")
+		srv.AddResourceProvider(&staticProvider{watchFn: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+		srv.AddResourceProvider(&staticProvider{watchFn: func(ctx context.Context) error {
+			return watchErr
+		}})
+
+		done := make(chan error, 1)
+		go func() { done <- srv.WatchResources(context.Background(), func(string) {}) }()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, watchErr) {
+				t.Errorf("error = %v, want %v", err, watchErr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("WatchResources did not return")
+		}
+	})
+}
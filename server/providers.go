@@ -0,0 +1,408 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// FSProvider serves the files of an fs.FS as resources. The URI of a file
+// is the base URI followed by its slash-separated path in the file system.
+type FSProvider struct {
+	fsys    fs.FS
+	baseURI string
+}
+
+// NewFSProvider creates a provider serving the files of fsys under
+// baseURI, for example:
+//
+//	srv.AddResourceProvider(server.NewFSProvider(os.DirFS("./docs"), "docs://"))
+//	// docs://guide/intro.md reads ./docs/guide/intro.md
+func NewFSProvider(fsys fs.FS, baseURI string) *FSProvider {
+	return &FSProvider{fsys: fsys, baseURI: baseURI}
+}
+
+// List returns every regular file in the file system.
+func (p *FSProvider) List(ctx context.Context) ([]ResourceEntry, error) {
+	var entries []ResourceEntry
+	err := fs.WalkDir(p.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		entries = append(entries, ResourceEntry{
+			URI:      p.baseURI + name,
+			Name:     name,
+			MimeType: mimeTypeByExtension(name),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Read reads the file at uri.
+func (p *FSProvider) Read(ctx context.Context, uri string) (*ResourceContent, error) {
+	name, ok := relativeResourcePath(p.baseURI, uri)
+	if !ok || !fs.ValidPath(name) {
+		return nil, ErrResourceNotFound
+	}
+
+	data, err := fs.ReadFile(p.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return contentFromBytes(uri, mimeTypeByExtension(name), data), nil
+}
+
+// Watch returns nil immediately; FSProvider does not watch for changes.
+func (p *FSProvider) Watch(ctx context.Context, onChange func(uri string)) error {
+	return nil
+}
+
+// ObjectInfo describes an object in an ObjectStore.
+type ObjectInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// ObjectStore is the subset of an S3-compatible object store API used by
+// ObjectStoreProvider. Implement it with the client of your storage
+// service. GetObject returns an error wrapping ErrResourceNotFound for
+// missing keys.
+type ObjectStore interface {
+	ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	GetObject(ctx context.Context, key string) ([]byte, ObjectInfo, error)
+}
+
+// ObjectStoreProvider serves the objects of an ObjectStore as resources.
+// The URI of an object is the base URI followed by its key.
+type ObjectStoreProvider struct {
+	store   ObjectStore
+	baseURI string
+	prefix  string
+}
+
+// NewObjectStoreProvider creates a provider serving the objects whose keys
+// start with prefix, for example:
+//
+//	p := server.NewObjectStoreProvider(bucket, "s3://reports/", "2024/")
+//	// s3://reports/2024/q1.pdf reads the object with key 2024/q1.pdf
+func NewObjectStoreProvider(store ObjectStore, baseURI, prefix string) *ObjectStoreProvider {
+	return &ObjectStoreProvider{store: store, baseURI: baseURI, prefix: prefix}
+}
+
+// List returns the objects under the prefix.
+func (p *ObjectStoreProvider) List(ctx context.Context) ([]ResourceEntry, error) {
+	objects, err := p.store.ListObjects(ctx, p.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ResourceEntry, 0, len(objects))
+	for _, o := range objects {
+		mimeType := o.ContentType
+		if mimeType == "" {
+			mimeType = mimeTypeByExtension(o.Key)
+		}
+		entries = append(entries, ResourceEntry{
+			URI:      p.baseURI + o.Key,
+			Name:     o.Key,
+			MimeType: mimeType,
+		})
+	}
+	return entries, nil
+}
+
+// Read reads the object at uri. Keys with "." or ".." segments are not
+// found, since stores that resolve keys as paths would read them outside
+// the prefix.
+func (p *ObjectStoreProvider) Read(ctx context.Context, uri string) (*ResourceContent, error) {
+	key, ok := relativeResourcePath(p.baseURI, uri)
+	if !ok || !strings.HasPrefix(key, p.prefix) || hasDotSegment(key) {
+		return nil, ErrResourceNotFound
+	}
+
+	data, info, err := p.store.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := info.ContentType
+	if mimeType == "" {
+		mimeType = mimeTypeByExtension(key)
+	}
+	return contentFromBytes(uri, mimeType, data), nil
+}
+
+// Watch returns nil immediately; ObjectStoreProvider does not watch for
+// changes.
+func (p *ObjectStoreProvider) Watch(ctx context.Context, onChange func(uri string)) error {
+	return nil
+}
+
+// defaultHTTPProviderMaxSize limits the size of resources fetched by
+// HTTPProvider.
+const defaultHTTPProviderMaxSize = 10 << 20
+
+// HTTPProvider serves resources fetched from an HTTP origin. Resource URIs
+// are the URLs of the fetched documents.
+type HTTPProvider struct {
+	baseURL string
+	base    *url.URL
+	client  *http.Client
+	paths   []string
+	maxSize int64
+}
+
+// HTTPProviderOption configures an HTTPProvider.
+type HTTPProviderOption func(*HTTPProvider)
+
+// WithHTTPProviderClient sets the HTTP client used to fetch resources.
+// Defaults to http.DefaultClient. The provider uses a copy of client whose
+// redirect policy additionally rejects redirects to another origin.
+func WithHTTPProviderClient(client *http.Client) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.client = client
+	}
+}
+
+// WithHTTPProviderPaths sets the paths, relative to the base URL, listed
+// by resources/list. Other URLs under the base URL can still be read.
+func WithHTTPProviderPaths(paths ...string) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.paths = append(p.paths, paths...)
+	}
+}
+
+// WithHTTPProviderMaxSize limits the size of fetched resources.
+// Defaults to 10 MB.
+func WithHTTPProviderMaxSize(n int64) HTTPProviderOption {
+	return func(p *HTTPProvider) {
+		p.maxSize = n
+	}
+}
+
+// NewHTTPProvider creates a provider reading URLs under baseURL, for
+// example:
+//
+//	p := server.NewHTTPProvider("https://docs.example.com/",
+//	    server.WithHTTPProviderPaths("index.md", "api.md"),
+//	)
+func NewHTTPProvider(baseURL string, opts ...HTTPProviderOption) *HTTPProvider {
+	p := &HTTPProvider{
+		baseURL: baseURL,
+		client:  http.DefaultClient,
+		maxSize: defaultHTTPProviderMaxSize,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if base, err := url.Parse(baseURL); err == nil && base.Scheme != "" && base.Host != "" {
+		p.base = base
+	}
+
+	client := *p.client
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if p.base == nil || !sameOrigin(req.URL, p.base) {
+			return fmt.Errorf("redirect to %s leaves origin of %s", req.URL.Redacted(), baseURL)
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	p.client = &client
+	return p
+}
+
+// List returns the configured paths.
+func (p *HTTPProvider) List(ctx context.Context) ([]ResourceEntry, error) {
+	entries := make([]ResourceEntry, 0, len(p.paths))
+	for _, name := range p.paths {
+		entries = append(entries, ResourceEntry{
+			URI:      p.baseURL + name,
+			Name:     name,
+			MimeType: mimeTypeByExtension(name),
+		})
+	}
+	return entries, nil
+}
+
+// Read fetches uri with a GET request.
+func (p *HTTPProvider) Read(ctx context.Context, uri string) (*ResourceContent, error) {
+	target, ok := p.resolve(uri)
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("fetch %s: %s", uri, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > p.maxSize {
+		return nil, fmt.Errorf("fetch %s: resource exceeds %d bytes", uri, p.maxSize)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = mimeTypeByExtension(uri)
+	}
+	return contentFromBytes(uri, mimeType, data), nil
+}
+
+// Watch returns nil immediately; HTTPProvider does not watch for changes.
+func (p *HTTPProvider) Watch(ctx context.Context, onChange func(uri string)) error {
+	return nil
+}
+
+// resolve returns the URL to fetch for uri. The URI must have the scheme
+// and host of the base URL and a path below the base path; the fetched URL
+// is rebuilt from the base URL and the cleaned relative path so that user
+// info, alternate hosts and dot segments in uri never reach the request.
+func (p *HTTPProvider) resolve(uri string) (string, bool) {
+	if p.base == nil {
+		return "", false
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Opaque != "" || u.User != nil || !sameOrigin(u, p.base) {
+		return "", false
+	}
+
+	prefix := p.base.Path
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	rel, ok := strings.CutPrefix(u.Path, prefix)
+	if !ok || rel == "" || strings.Contains(rel, `\`) {
+		return "", false
+	}
+	rel = strings.TrimPrefix(path.Clean("/"+rel), "/")
+	if rel == "" {
+		return "", false
+	}
+
+	target := url.URL{
+		Scheme:   p.base.Scheme,
+		Host:     p.base.Host,
+		Path:     prefix + rel,
+		RawQuery: u.RawQuery,
+	}
+	return target.String(), true
+}
+
+// sameOrigin reports whether a and b have the same scheme and host.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
+
+// relativeResourcePath returns the percent-decoded part of uri after base.
+func relativeResourcePath(base, uri string) (string, bool) {
+	rest, ok := strings.CutPrefix(uri, base)
+	if !ok || rest == "" {
+		return "", false
+	}
+	if decoded, err := url.PathUnescape(rest); err == nil {
+		rest = decoded
+	}
+	return rest, true
+}
+
+// hasDotSegment reports whether key has a "." or ".." path segment.
+func hasDotSegment(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// mimeTypeByExtension returns the MIME type of a file name, without
+// parameters, or "" if the extension is unknown.
+func mimeTypeByExtension(name string) string {
+	mimeType := mime.TypeByExtension(path.Ext(name))
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+	return mimeType
+}
+
+// contentFromBytes returns data as text content for textual MIME types and
+// as base64 blob content otherwise.
+func contentFromBytes(uri, mimeType string, data []byte) *ResourceContent {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+		if utf8.Valid(data) {
+			mimeType = "text/plain"
+		}
+	}
+
+	content := &ResourceContent{URI: uri, MimeType: mimeType}
+	if isTextMimeType(mimeType) && utf8.Valid(data) {
+		content.Text = string(data)
+	} else {
+		content.Blob = base64.StdEncoding.EncodeToString(data)
+	}
+	return content
+}
+
+// isTextMimeType reports whether content of the MIME type is text.
+func isTextMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/yaml", "application/x-yaml", "application/toml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSProvider(t *testing.T) {
+	fsys := fstest.MapFS{
+		"guide/intro.md":   {Data: []byte("# Intro")},
+		"data/config.json": {Data: []byte(`{"debug":true}`)},
+		"img/logo.png":     {Data: []byte{0x89, 'P', 'N', 'G'}},
+		"my notes.txt":     {Data: []byte("notes")},
+	}
+	p := NewFSProvider(fsys, "docs://")
+
+	t.Run("lists files", func(t *testing.T) {
+		entries, err := p.List(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 4 {
+			t.Fatalf("got %d entries, want 4", len(entries))
+		}
+		if entries[0].URI != "docs://data/config.json" || entries[0].MimeType != "application/json" {
+			t.Errorf("entries[0] = %+v", entries[0])
+		}
+	})
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantText string
+		wantBlob []byte
+		notFound bool
+	}{
+		{name: "text file", uri: "docs://data/config.json", wantText: `{"debug":true}`},
+		{name: "binary file", uri: "docs://img/logo.png", wantBlob: []byte{0x89, 'P', 'N', 'G'}},
+		{name: "encoded name", uri: "docs://my%20notes.txt", wantText: "notes"},
+		{name: "missing file", uri: "docs://guide/missing.md", notFound: true},
+		{name: "other scheme", uri: "files://guide/intro.md", notFound: true},
+		{name: "path escape", uri: "docs://../secret", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := p.Read(context.Background(), tt.uri)
+			if tt.notFound {
+				if !errors.Is(err, ErrResourceNotFound) {
+					t.Fatalf("expected ErrResourceNotFound, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if content.URI != tt.uri {
+				t.Errorf("URI = %q, want %q", content.URI, tt.uri)
+			}
+			if tt.wantBlob != nil {
+				if content.Blob != base64.StdEncoding.EncodeToString(tt.wantBlob) {
+					t.Errorf("Blob = %q", content.Blob)
+				}
+				return
+			}
+			if content.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", content.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+// memoryObjectStore is an in-memory ObjectStore.
+type memoryObjectStore map[string]ObjectInfo
+
+func (s memoryObjectStore) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for key, info := range s {
+		if strings.HasPrefix(key, prefix) {
+			info.Key = key
+			objects = append(objects, info)
+		}
+	}
+	return objects, nil
+}
+
+func (s memoryObjectStore) GetObject(ctx context.Context, key string) ([]byte, ObjectInfo, error) {
+	info, ok := s[key]
+	if !ok {
+		return nil, ObjectInfo{}, fmt.Errorf("get %s: %w", key, ErrResourceNotFound)
+	}
+	info.Key = key
+	return []byte("object " + key), info, nil
+}
+
+func TestObjectStoreProvider(t *testing.T) {
+	store := memoryObjectStore{
+		"2024/q1.csv":   {ContentType: "text/csv"},
+		"2023/q4.csv":   {ContentType: "text/csv"},
+		"2024/notes.md": {},
+	}
+	p := NewObjectStoreProvider(store, "s3://reports/", "2024/")
+
+	entries, err := p.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+
+	content, err := p.Read(context.Background(), "s3://reports/2024/q1.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content.Text != "object 2024/q1.csv" || content.MimeType != "text/csv" {
+		t.Errorf("content = %+v", content)
+	}
+
+	for _, uri := range []string{"s3://reports/2023/q4.csv", "s3://reports/2024/missing.csv", "s3://other/2024/q1.csv"} {
+		if _, err := p.Read(context.Background(), uri); !errors.Is(err, ErrResourceNotFound) {
+			t.Errorf("Read(%q) error = %v, want ErrResourceNotFound", uri, err)
+		}
+	}
+}
+
+// pathObjectStore is an ObjectStore resolving keys as paths, like a store
+// backed by a file system.
+type pathObjectStore struct{ memoryObjectStore }
+
+func (s pathObjectStore) GetObject(ctx context.Context, key string) ([]byte, ObjectInfo, error) {
+	return s.memoryObjectStore.GetObject(ctx, path.Clean(key))
+}
+
+func TestObjectStoreProvider_Traversal(t *testing.T) {
+	store := pathObjectStore{memoryObjectStore{
+		"2024/q1.csv":  {ContentType: "text/csv"},
+		"2023/q4.csv":  {ContentType: "text/csv"},
+		"secrets.json": {},
+	}}
+	p := NewObjectStoreProvider(store, "s3://reports/", "2024/")
+
+	for _, uri := range []string{
+		"s3://reports/2024/../2023/q4.csv",
+		"s3://reports/2024/../secrets.json",
+		"s3://reports/2024/%2e%2e/secrets.json",
+		"s3://reports/2024/./q1.csv",
+	} {
+		if _, err := p.Read(context.Background(), uri); !errors.Is(err, ErrResourceNotFound) {
+			t.Errorf("Read(%q) error = %v, want ErrResourceNotFound", uri, err)
+		}
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs/index.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			fmt.Fprint(w, "# Docs")
+		case "/docs/large.txt":
+			fmt.Fprint(w, strings.Repeat("x", 64))
+		case "/docs/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/docs/moved":
+			http.Redirect(w, r, "/docs/index.md", http.StatusFound)
+		case "/docs/offsite":
+			http.Redirect(w, r, "http://evil.test/", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer origin.Close()
+
+	base := origin.URL + "/docs/"
+	p := NewHTTPProvider(base,
+		WithHTTPProviderClient(origin.Client()),
+		WithHTTPProviderPaths("index.md"),
+		WithHTTPProviderMaxSize(32),
+	)
+
+	entries, err := p.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URI != base+"index.md" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	content, err := p.Read(context.Background(), base+"index.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content.Text != "# Docs" || content.MimeType != "text/markdown; charset=utf-8" {
+		t.Errorf("content = %+v", content)
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		notFound bool
+	}{
+		{name: "missing", uri: base + "missing.md", notFound: true},
+		{name: "outside base URL", uri: origin.URL + "/other", notFound: true},
+		{name: "server error", uri: base + "broken"},
+		{name: "too large", uri: base + "large.txt"},
+		{name: "redirect to another origin", uri: base + "offsite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Read(context.Background(), tt.uri)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := errors.Is(err, ErrResourceNotFound); got != tt.notFound {
+				t.Errorf("errors.Is(err, ErrResourceNotFound) = %v, want %v (err: %v)", got, tt.notFound, err)
+			}
+		})
+	}
+
+	content, err = p.Read(context.Background(), base+"moved")
+	if err != nil || content.Text != "# Docs" {
+		t.Errorf("same-origin redirect: content = %+v, err = %v", content, err)
+	}
+}
+
+func TestHTTPProvider_Resolve(t *testing.T) {
+	tests := []struct {
+		base string
+		uri  string
+		want string
+	}{
+		{base: "https://docs.example.com/api/", uri: "https://docs.example.com/api/index.md", want: "https://docs.example.com/api/index.md"},
+		{base: "https://docs.example.com/api", uri: "https://docs.example.com/api/index.md", want: "https://docs.example.com/api/index.md"},
+		{base: "https://docs.example.com", uri: "https://DOCS.example.com/a/b.md?v=2", want: "https://docs.example.com/a/b.md?v=2"},
+		{base: "https://docs.example.com/api/", uri: "https://docs.example.com/api/../secret", want: "https://docs.example.com/api/secret"},
+		{base: "https://docs.example.com/api/", uri: "https://docs.example.com/api/a%2F..%2F..%2Fsecret", want: "https://docs.example.com/api/secret"},
+		{base: "https://docs.example.com", uri: "https://docs.example.com@evil.test/"},
+		{base: "https://docs.example.com", uri: "https://docs.example.com.evil.test/"},
+		{base: "https://docs.example.com", uri: "https://user@docs.example.com/index.md"},
+		{base: "https://docs.example.com", uri: "http://docs.example.com/index.md"},
+		{base: "https://docs.example.com/api", uri: "https://docs.example.com/apikeys"},
+		{base: "https://docs.example.com/api/", uri: "https://docs.example.com/api/"},
+		{base: "https://docs.example.com/api/", uri: "https://docs.example.com/api/..%5Csecret"},
+		{base: "docs.example.com", uri: "docs.example.com/index.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, ok := NewHTTPProvider(tt.base).resolve(tt.uri)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("resolve(%q) = %q, %v; want %q", tt.uri, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,9 @@ package server
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
 // ResourceContent represents the content returned by a resource read.
@@ -22,32 +23,85 @@ type ResourceHandler func(ctx context.Context, uri string, params map[string]str
 type Resource struct {
 	uriTemplate string
 	name        string
+	title       string
 	description string
 	mimeType    string
 	handler     ResourceHandler
+	stream      ResourceStreamHandler
 	annotations *ResourceAnnotations
+	aliases     []string
+	icons       []Icon
+
+	// Read caching, see ResourceBuilder.Cache
+	cached   bool
+	cacheTTL time.Duration
+	etag     func(ctx context.Context, uri string, params map[string]string) (string, error)
+
+	// Files reported by WatchResources, see ResourceBuilder.WatchFile
+	watchFiles []string
 
-	// Compiled regex for URI matching
-	uriRegex   *regexp.Regexp
-	paramNames []string
+	// Compiled template and aliases for URI matching
+	trailingSlash TrailingSlashPolicy
+	patterns      []*uriPattern
+
+	// Position in registration order
+	seq uint64
 }
 
 // ResourceInfo represents metadata about a registered resource.
 type ResourceInfo struct {
 	URITemplate string
+	Aliases     []string
 	Name        string
+	Title       string
 	Description string
 	MimeType    string
 	Annotations *ResourceAnnotations
+	Icons       []Icon
 }
 
 // ResourceTemplateInfo represents metadata about a resource template.
 type ResourceTemplateInfo struct {
 	URITemplate string
+	Aliases     []string
 	Name        string
+	Title       string
 	Description string
 	MimeType    string
 	Annotations *ResourceAnnotations
+	Icons       []Icon
+}
+
+// ListItem returns the resource as advertised in resources/list.
+func (r ResourceInfo) ListItem() protocol.Resource {
+	item := protocol.Resource{
+		URI:         r.URITemplate,
+		Name:        r.Name,
+		Title:       r.Title,
+		Description: r.Description,
+		MimeType:    r.MimeType,
+		Icons:       r.Icons,
+	}
+	if r.Annotations != nil {
+		item.Annotations = r.Annotations
+	}
+	return item
+}
+
+// ListItem returns the template as advertised in resources/templates/list.
+func (t ResourceTemplateInfo) ListItem() protocol.ResourceTemplate {
+	item := protocol.ResourceTemplate{
+		URITemplate: t.URITemplate,
+		Name:        t.Name,
+		Title:       t.Title,
+		Description: t.Description,
+		MimeType:    t.MimeType,
+		Icons:       t.Icons,
+	}
+	if t.Annotations != nil {
+		item.Annotations = t.Annotations
+	}
+	return item
 }
 
 // ResourceBuilder provides a fluent API for building resources.
@@ -84,6 +138,17 @@ func (b *ResourceBuilder) MimeType(mimeType string) *ResourceBuilder {
 	return b
 }
 
+// Alias adds another URI template served by the same handler, for example
+// to serve "docs://{path}" and "file:///docs/{path}" from one resource. The
+// handler receives the parameters of whichever template matched.
+func (b *ResourceBuilder) Alias(uriTemplate string) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.resource.aliases = append(b.resource.aliases, uriTemplate)
+	return b
+}
+
 // Handler sets the resource handler function.
 func (b *ResourceBuilder) Handler(fn ResourceHandler) *ResourceBuilder {
 	if b.err != nil {
@@ -92,8 +157,8 @@ func (b *ResourceBuilder) Handler(fn ResourceHandler) *ResourceBuilder {
 
 	b.resource.handler = fn
 
-	// Compile URI template to regex
-	if err := b.resource.compileTemplate(); err != nil {
+	// Compile URI templates to regexes
+	if err := b.resource.compileTemplate(b.server.TrailingSlashPolicy()); err != nil {
 		b.err = err
 		return b
 	}
@@ -102,75 +167,60 @@ func (b *ResourceBuilder) Handler(fn ResourceHandler) *ResourceBuilder {
 	return b
 }
 
-// compileTemplate converts a URI template to a regex for matching.
-func (r *Resource) compileTemplate() error {
-	// Extract parameter names and build regex
-	paramRegex := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := paramRegex.FindAllStringSubmatch(r.uriTemplate, -1)
+// Err returns the error that stopped the resource from being registered,
+// such as an invalid URI template, or nil.
+func (b *ResourceBuilder) Err() error {
+	return b.err
+}
 
-	r.paramNames = make([]string, 0, len(matches))
-	for _, match := range matches {
-		r.paramNames = append(r.paramNames, match[1])
-	}
+// Aliases returns the alias URI templates of the resource.
+func (r *Resource) Aliases() []string {
+	return r.aliases
+}
 
-	// Escape special regex characters and replace {param} with capture groups
-	pattern := regexp.QuoteMeta(r.uriTemplate)
-	pattern = strings.ReplaceAll(pattern, `\{`, "{")
-	pattern = strings.ReplaceAll(pattern, `\}`, "}")
-	pattern = paramRegex.ReplaceAllString(pattern, `([^/]+)`)
-	pattern = "^" + pattern + "$"
+// compileTemplate compiles the URI template and aliases for matching.
+func (r *Resource) compileTemplate(policy TrailingSlashPolicy) error {
+	r.trailingSlash = policy
+	r.patterns = r.patterns[:0]
+	for _, template := range append([]string{r.uriTemplate}, r.aliases...) {
+		p, err := compileURIPattern(template, policy)
+		if err != nil {
+			return fmt.Errorf("invalid URI template %q: %w", template, err)
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return nil
+}
 
-	var err error
-	r.uriRegex, err = regexp.Compile(pattern)
-	return err
+// match matches a URI against the template and aliases of the resource.
+// It returns the canonical URI and the extracted parameters.
+func (r *Resource) match(uri string) (string, map[string]string, bool) {
+	canonical := CanonicalURI(uri, r.trailingSlash)
+	for _, p := range r.patterns {
+		if params, ok := p.match(canonical); ok {
+			return canonical, params, true
+		}
+	}
+	return "", nil, false
 }
 
-// Read executes the resource handler for the given URI.
+// Read executes the resource handler for the given URI. The handler
+// receives the canonical form of the URI (see CanonicalURI).
 func (r *Resource) Read(ctx context.Context, uri string) (*ResourceContent, error) {
-	params, ok := matchURI(r.uriTemplate, uri)
+	canonical, params, ok := r.match(uri)
 	if !ok {
 		return nil, fmt.Errorf("URI %q does not match template %q", uri, r.uriTemplate)
 	}
 
-	return r.handler(ctx, uri, params)
+	return r.handler(ctx, canonical, params)
 }
 
 // matchURI matches a URI against a template and extracts parameters.
+// Both are canonicalized with the default trailing slash policy.
 func matchURI(template, uri string) (map[string]string, bool) {
-	// Extract parameter names
-	paramRegex := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := paramRegex.FindAllStringSubmatch(template, -1)
-
-	paramNames := make([]string, 0, len(matches))
-	for _, match := range matches {
-		paramNames = append(paramNames, match[1])
-	}
-
-	// Build regex pattern
-	pattern := regexp.QuoteMeta(template)
-	pattern = strings.ReplaceAll(pattern, `\{`, "{")
-	pattern = strings.ReplaceAll(pattern, `\}`, "}")
-	pattern = paramRegex.ReplaceAllString(pattern, `([^/]+)`)
-	pattern = "^" + pattern + "$"
-
-	re, err := regexp.Compile(pattern)
+	p, err := compileURIPattern(template, TrailingSlashStrip)
 	if err != nil {
 		return nil, false
 	}
-
-	// Match URI
-	uriMatches := re.FindStringSubmatch(uri)
-	if uriMatches == nil {
-		return nil, false
-	}
-
-	// Extract parameter values
-	params := make(map[string]string)
-	for i, name := range paramNames {
-		if i+1 < len(uriMatches) {
-			params[name] = uriMatches[i+1]
-		}
-	}
-
-	return params, true
+	return p.match(CanonicalURI(uri, TrailingSlashStrip))
 }
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxResourceSize limits the data NewReaderContent reads when no
+// limit is given.
+const defaultMaxResourceSize = 10 << 20
+
+// ErrResourceTooLarge is returned when resource data exceeds its size limit.
+var ErrResourceTooLarge = errors.New("resource too large")
+
+// DetectMimeType returns the MIME type of resource data named name: the
+// type of its file extension if known, otherwise the type sniffed from the
+// first bytes of data. Unrecognized data is "text/plain" if it is valid
+// UTF-8 and "application/octet-stream" otherwise.
+func DetectMimeType(name string, data []byte) string {
+	mimeType := mimeTypeByExtension(name)
+	if mimeType != "" && mimeType != "application/octet-stream" {
+		return mimeType
+	}
+	if len(data) > 0 {
+		sniffed, _, _ := strings.Cut(http.DetectContentType(data), ";")
+		if sniffed != "text/plain" && sniffed != "application/octet-stream" {
+			return sniffed
+		}
+	}
+	return contentFromBytes("", "", data).MimeType
+}
+
+// NewBlobContent returns binary resource content, base64 encoding data. An
+// empty mimeType is detected from uri and data with DetectMimeType.
+//
+// Example:
+//
+//	srv.Resource("images://{name}").Handler(func(ctx context.Context, uri string, params map[string]string) (*server.ResourceContent, error) {
+//	    png, err := render(params["name"])
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return server.NewBlobContent(uri, "image/png", png), nil
+//	})
+func NewBlobContent(uri, mimeType string, data []byte) *ResourceContent {
+	if mimeType == "" {
+		mimeType = DetectMimeType(uri, data)
+	}
+	return &ResourceContent{
+		URI:      uri,
+		MimeType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// NewReaderContent reads r into resource content: text for textual MIME
+// types and base64 blob data otherwise. An empty mimeType is detected from
+// uri and the data with DetectMimeType. It reads at most maxSize bytes and
+// returns an error wrapping ErrResourceTooLarge for longer data; a maxSize
+// of zero or less uses the default of 10 MB.
+//
+// Example:
+//
+//	f, err := os.Open(path)
+//	if err != nil {
+//	    return nil, err
+//	}
+//	defer f.Close()
+//	return server.NewReaderContent(uri, "", f, 1<<20)
+func NewReaderContent(uri, mimeType string, r io.Reader, maxSize int64) (*ResourceContent, error) {
+	if maxSize <= 0 {
+		maxSize = defaultMaxResourceSize
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", uri, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrResourceTooLarge, uri, maxSize)
+	}
+	if mimeType == "" {
+		mimeType = DetectMimeType(uri, data)
+	}
+	return contentFromBytes(uri, mimeType, data), nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		data []byte
+		want string
+	}{
+		{name: "extension", uri: "file:///a/readme.md", data: []byte("# hi"), want: "text/markdown"},
+		{name: "extension wins over content", uri: "file:///a/data.json", data: pngHeader, want: "application/json"},
+		{name: "sniffed image", uri: "images://logo", data: pngHeader, want: "image/png"},
+		{name: "sniffed behind generic extension", uri: "file:///logo.bin", data: pngHeader, want: "image/png"},
+		{name: "sniffed html", uri: "pages://home", data: []byte("<!DOCTYPE html><html></html>"), want: "text/html"},
+		{name: "utf-8 text", uri: "notes://1", data: []byte("plain notes"), want: "text/plain"},
+		{name: "binary", uri: "data://1", data: []byte{0xff, 0xfe, 0x00, 0x01}, want: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMimeType(tt.uri, tt.data); got != tt.want {
+				t.Errorf("DetectMimeType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBlobContent(t *testing.T) {
+	t.Run("encodes data", func(t *testing.T) {
+		c := NewBlobContent("images://logo", "image/png", pngHeader)
+
+		if c.URI != "images://logo" || c.MimeType != "image/png" || c.Text != "" {
+			t.Errorf("content = %+v", c)
+		}
+		data, err := base64.StdEncoding.DecodeString(c.Blob)
+		if err != nil || string(data) != string(pngHeader) {
+			t.Errorf("Blob decodes to %q, %v", data, err)
+		}
+	})
+
+	t.Run("detects MIME type", func(t *testing.T) {
+		if c := NewBlobContent("images://logo", "", pngHeader); c.MimeType != "image/png" {
+			t.Errorf("MimeType = %q, want image/png", c.MimeType)
+		}
+	})
+
+	t.Run("keeps text as blob", func(t *testing.T) {
+		c := NewBlobContent("file:///a.txt", "", []byte("hello"))
+		if c.MimeType != "text/plain" || c.Blob != base64.StdEncoding.EncodeToString([]byte("hello")) {
+			t.Errorf("content = %+v", c)
+		}
+	})
+}
+
+func TestNewReaderContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		mimeType string
+		r        io.Reader
+		maxSize  int64
+		wantText string
+		wantBlob bool
+		wantMime string
+		wantErr  error
+	}{
+		{name: "text", uri: "file:///a.txt", r: strings.NewReader("hello"), wantText: "hello", wantMime: "text/plain"},
+		{name: "given MIME type", uri: "logs://today", mimeType: "application/json", r: strings.NewReader(`{}`), wantText: `{}`, wantMime: "application/json"},
+		{name: "binary", uri: "images://logo", r: strings.NewReader(string(pngHeader)), wantBlob: true, wantMime: "image/png"},
+		{name: "at limit", uri: "file:///a.txt", r: strings.NewReader("12345"), maxSize: 5, wantText: "12345"},
+		{name: "over limit", uri: "file:///a.txt", r: strings.NewReader("123456"), maxSize: 5, wantErr: ErrResourceTooLarge},
+		{name: "default limit", uri: "file:///a.txt", r: strings.NewReader("hello"), maxSize: -1, wantText: "hello"},
+		{name: "read error", uri: "file:///a.txt", r: iotest.ErrReader(io.ErrUnexpectedEOF), wantErr: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewReaderContent(tt.uri, tt.mimeType, tt.r, tt.maxSize)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewReaderContent() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewReaderContent() error = %v", err)
+			}
+			if c.URI != tt.uri {
+				t.Errorf("URI = %q, want %q", c.URI, tt.uri)
+			}
+			if tt.wantText != "" && c.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", c.Text, tt.wantText)
+			}
+			if tt.wantBlob && (c.Blob == "" || c.Text != "") {
+				t.Errorf("content = %+v, want blob", c)
+			}
+			if tt.wantMime != "" && !strings.HasPrefix(c.MimeType, tt.wantMime) {
+				t.Errorf("MimeType = %q, want %q", c.MimeType, tt.wantMime)
+			}
+		})
+	}
+}
@@ -210,6 +210,54 @@ func TestMatchURI(t *testing.T) {
 			uri:      "users://123",
 			wantOK:   false,
 		},
+		{
+			name:     "percent-encoded parameter is decoded",
+			template: "files://{name}",
+			uri:      "files://my%20notes.txt",
+			want:     map[string]string{"name": "my notes.txt"},
+			wantOK:   true,
+		},
+		{
+			name:     "encoded slash does not match",
+			template: "repos://{repo}/readme",
+			uri:      "repos://alice%2Fmyrepo/readme",
+			wantOK:   false,
+		},
+		{
+			name:     "encoded non-ASCII literal",
+			template: "docs://café/{page}",
+			uri:      "docs://caf%C3%A9/intro",
+			want:     map[string]string{"page": "intro"},
+			wantOK:   true,
+		},
+		{
+			name:     "encoded unreserved literal",
+			template: "users://{id}/profile",
+			uri:      "users://123/%70rofile",
+			want:     map[string]string{"id": "123"},
+			wantOK:   true,
+		},
+		{
+			name:     "dot segments and duplicate slashes",
+			template: "file:///docs/{page}",
+			uri:      "file:///docs//drafts/../intro",
+			want:     map[string]string{"page": "intro"},
+			wantOK:   true,
+		},
+		{
+			name:     "trailing slash",
+			template: "users://{id}",
+			uri:      "users://123/",
+			want:     map[string]string{"id": "123"},
+			wantOK:   true,
+		},
+		{
+			name:     "scheme is case-insensitive",
+			template: "users://{id}",
+			uri:      "USERS://123",
+			want:     map[string]string{"id": "123"},
+			wantOK:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,5 +1,10 @@
 package server
 
+import (
+	"encoding/json"
+	"slices"
+)
+
 // SamplingMessage represents a message in a sampling request.
 type SamplingMessage struct {
 	Role    Role    `json:"role"`
@@ -20,6 +25,14 @@ type Content struct {
 	Text     string `json:"text,omitempty"`
 	MimeType string `json:"mimeType,omitempty"`
 	Data     string `json:"data,omitempty"`
+
+	// Tool use fields, for "tool_use" and "tool_result" content
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Input     map[string]any `json:"input,omitempty"`
+	ToolUseID string         `json:"toolUseId,omitempty"`
+	Content   []Content      `json:"content,omitempty"`
+	IsError   bool           `json:"isError,omitempty"`
 }
 
 // NewTextContent creates a text content block.
@@ -39,6 +52,63 @@ func NewImageContent(mimeType, data string) Content {
 	}
 }
 
+// NewToolUseContent creates a tool use block, in which the model asks to
+// call the named tool with input.
+func NewToolUseContent(id, name string, input map[string]any) Content {
+	return Content{
+		Type:  "tool_use",
+		ID:    id,
+		Name:  name,
+		Input: input,
+	}
+}
+
+// NewToolResultContent creates a tool result block answering the tool use
+// with the given ID.
+func NewToolResultContent(toolUseID string, content ...Content) Content {
+	return Content{
+		Type:      "tool_result",
+		ToolUseID: toolUseID,
+		Content:   content,
+	}
+}
+
+// Values of CreateMessageRequest.IncludeContext.
+const (
+	IncludeContextNone       = "none"
+	IncludeContextThisServer = "thisServer"
+	IncludeContextAllServers = "allServers"
+)
+
+// Values of CreateMessageResult.StopReason.
+const (
+	StopReasonEndTurn      = "endTurn"
+	StopReasonStopSequence = "stopSequence"
+	StopReasonMaxTokens    = "maxTokens"
+	StopReasonToolUse      = "toolUse"
+)
+
+// SamplingTool is a tool the model may call during a sampling request.
+// Tool calls are returned as "tool_use" content; the server runs them and
+// sends the results back as "tool_result" content in a follow-up request.
+type SamplingTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"inputSchema"`
+}
+
+// ToolChoice controls whether the model calls tools.
+type ToolChoice struct {
+	Mode string `json:"mode,omitempty"` // "auto", "required", "none"
+}
+
+// Values of ToolChoice.Mode.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceRequired = "required"
+	ToolChoiceNone     = "none"
+)
+
 // CreateMessageRequest is sent by the server to request an LLM completion from the client.
 type CreateMessageRequest struct {
 	Messages         []SamplingMessage `json:"messages"`
@@ -49,6 +119,10 @@ type CreateMessageRequest struct {
 	IncludeContext   string            `json:"includeContext,omitempty"` // "none", "thisServer", "allServers"
 	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
 	Metadata         map[string]any    `json:"metadata,omitempty"`
+
+	// Tools require a client that supports tool use in sampling
+	Tools      []SamplingTool `json:"tools,omitempty"`
+	ToolChoice *ToolChoice    `json:"toolChoice,omitempty"`
 }
 
 // ModelPreferences expresses preferences for model selection.
@@ -59,6 +133,26 @@ type ModelPreferences struct {
 	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"` // 0-1
 }
 
+// Fallbacks returns successively degraded copies of the preferences, to
+// retry a request the client rejected: each drops the first remaining
+// model hint, and the last is nil, meaning no preferences at all.
+func (p *ModelPreferences) Fallbacks() []*ModelPreferences {
+	if p == nil {
+		return nil
+	}
+
+	var fallbacks []*ModelPreferences
+	for i := 1; i <= len(p.Hints); i++ {
+		degraded := *p
+		degraded.Hints = slices.Clone(p.Hints[i:])
+		if len(degraded.Hints) == 0 {
+			degraded.Hints = nil
+		}
+		fallbacks = append(fallbacks, &degraded)
+	}
+	return append(fallbacks, nil)
+}
+
 // ModelHint hints at a model the client should use.
 type ModelHint struct {
 	Name string `json:"name,omitempty"`
@@ -69,7 +163,57 @@ type CreateMessageResult struct {
 	Role       Role    `json:"role"`
 	Content    Content `json:"content"`
 	Model      string  `json:"model"`
-	StopReason string  `json:"stopReason,omitempty"` // "endTurn", "stopSequence", "maxTokens"
+	StopReason string  `json:"stopReason,omitempty"` // "endTurn", "stopSequence", "maxTokens", "toolUse"
+
+	// Contents holds every content block when the client returned several,
+	// such as parallel tool calls. Content is the first of them.
+	Contents []Content `json:"-"`
+}
+
+// UnmarshalJSON accepts content as a single block or an array of blocks.
+func (r *CreateMessageResult) UnmarshalJSON(data []byte) error {
+	type result CreateMessageResult
+	var raw struct {
+		result
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = CreateMessageResult(raw.result)
+
+	if len(raw.Content) == 0 || raw.Content[0] != '[' {
+		if len(raw.Content) > 0 {
+			if err := json.Unmarshal(raw.Content, &r.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(raw.Content, &r.Contents); err != nil {
+		return err
+	}
+	if len(r.Contents) > 0 {
+		r.Content = r.Contents[0]
+	}
+	return nil
+}
+
+// ToolUses returns the tool use blocks of the result.
+func (r *CreateMessageResult) ToolUses() []Content {
+	contents := r.Contents
+	if contents == nil {
+		contents = []Content{r.Content}
+	}
+
+	var uses []Content
+	for _, c := range contents {
+		if c.Type == "tool_use" {
+			uses = append(uses, c)
+		}
+	}
+	return uses
 }
 
 // SamplingClient is an interface for clients that support sampling.
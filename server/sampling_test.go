@@ -1,6 +1,8 @@
 package server
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -130,3 +132,123 @@ func TestModelPreferences(t *testing.T) {
 		t.Error("intelligence priority not set correctly")
 	}
 }
+
+func TestModelPreferences_Fallbacks(t *testing.T) {
+	cost := 0.3
+
+	tests := []struct {
+		name  string
+		prefs *ModelPreferences
+		want  [][]string // hints of each fallback; nil entry means no preferences
+	}{
+		{name: "nil preferences"},
+		{
+			name:  "no hints",
+			prefs: &ModelPreferences{CostPriority: &cost},
+			want:  [][]string{nil},
+		},
+		{
+			name:  "drops hints one at a time",
+			prefs: &ModelPreferences{Hints: []ModelHint{{Name: "opus"}, {Name: "sonnet"}}, CostPriority: &cost},
+			want:  [][]string{{"sonnet"}, {}, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallbacks := tt.prefs.Fallbacks()
+			if len(fallbacks) != len(tt.want) {
+				t.Fatalf("got %d fallbacks, want %d", len(fallbacks), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := fallbacks[i]
+				if want == nil {
+					if got != nil {
+						t.Errorf("fallbacks[%d] = %+v, want nil", i, got)
+					}
+					continue
+				}
+				var names []string
+				for _, h := range got.Hints {
+					names = append(names, h.Name)
+				}
+				if len(names) != len(want) || (len(want) > 0 && !reflect.DeepEqual(names, want)) {
+					t.Errorf("fallbacks[%d] hints = %v, want %v", i, names, want)
+				}
+				if got.CostPriority != &cost {
+					t.Errorf("fallbacks[%d] lost the cost priority", i)
+				}
+			}
+			if tt.prefs != nil && len(tt.prefs.Hints) == 2 && tt.prefs.Hints[0].Name != "opus" {
+				t.Error("Fallbacks modified the original preferences")
+			}
+		})
+	}
+}
+
+func TestToolContent(t *testing.T) {
+	use := NewToolUseContent("call-1", "get_weather", map[string]any{"city": "Paris"})
+	result := NewToolResultContent("call-1", NewTextContent("18°C"))
+
+	data, err := json.Marshal([]Content{use, result})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `[{"type":"tool_use","id":"call-1","name":"get_weather","input":{"city":"Paris"}},` +
+		`{"type":"tool_result","toolUseId":"call-1","content":[{"type":"text","text":"18°C"}]}]`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+func TestCreateMessageResult_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantText     string
+		wantContents int
+		wantToolUses int
+	}{
+		{
+			name:     "single content",
+			json:     `{"role":"assistant","content":{"type":"text","text":"4"},"model":"m","stopReason":"endTurn"}`,
+			wantText: "4",
+		},
+		{
+			name: "content array with tool calls",
+			json: `{"role":"assistant","model":"m","stopReason":"toolUse","content":[` +
+				`{"type":"text","text":"checking"},` +
+				`{"type":"tool_use","id":"a","name":"weather","input":{"city":"Paris"}},` +
+				`{"type":"tool_use","id":"b","name":"weather","input":{"city":"Rome"}}]}`,
+			wantText:     "checking",
+			wantContents: 3,
+			wantToolUses: 2,
+		},
+		{
+			name:         "single tool call",
+			json:         `{"role":"assistant","model":"m","stopReason":"toolUse","content":{"type":"tool_use","id":"a","name":"weather"}}`,
+			wantToolUses: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result CreateMessageResult
+			if err := json.Unmarshal([]byte(tt.json), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Role != RoleAssistant || result.Model != "m" {
+				t.Errorf("result = %+v", result)
+			}
+			if result.Content.Text != tt.wantText {
+				t.Errorf("Content.Text = %q, want %q", result.Content.Text, tt.wantText)
+			}
+			if len(result.Contents) != tt.wantContents {
+				t.Errorf("got %d contents, want %d", len(result.Contents), tt.wantContents)
+			}
+			if uses := result.ToolUses(); len(uses) != tt.wantToolUses {
+				t.Errorf("got %d tool uses, want %d", len(uses), tt.wantToolUses)
+			}
+		})
+	}
+}
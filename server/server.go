@@ -4,8 +4,10 @@ package server
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/schema"
 )
 
 // Info contains server metadata exposed to clients.
@@ -15,12 +17,14 @@ type Info struct {
 	Capabilities Capabilities
 }
 
-// Capabilities declares what features the server supports.
+// Capabilities declares what features the server supports. Capabilities
+// of registered features are detected automatically; see
+// Server.Capabilities.
 type Capabilities struct {
-	Tools       bool
-	Resources   bool
-	Prompts     bool
-	Completions bool
+	Tools       bool `json:"tools"`
+	Resources   bool `json:"resources"`
+	Prompts     bool `json:"prompts"`
+	Completions bool `json:"completions"`
 }
 
 // Manifest represents the server manifest returned to clients.
@@ -33,10 +37,54 @@ type Manifest struct {
 
 // ToolInfo represents metadata about a registered tool.
 type ToolInfo struct {
-	Name        string
-	Description string
-	InputSchema any
-	Annotations *ToolAnnotations
+	Name         string
+	Title        string
+	Description  string
+	InputSchema  any
+	OutputSchema any
+	Annotations  *ToolAnnotations
+	Icons        []Icon
+	// Deprecated is the deprecation message set with
+	// ToolBuilder.Deprecated, or "" if the tool is not deprecated.
+	Deprecated string
+}
+
+// ListDescription returns the description advertised in tools/list, which
+// starts with the deprecation message of deprecated tools.
+func (t ToolInfo) ListDescription() string {
+	if t.Deprecated == "" {
+		return t.Description
+	}
+	notice := "Deprecated: " + t.Deprecated + "."
+	if t.Description == "" {
+		return notice
+	}
+	return notice + " " + t.Description
+}
+
+// ListMeta returns the _meta advertised in tools/list, or nil if none.
+func (t ToolInfo) ListMeta() map[string]any {
+	if t.Deprecated == "" {
+		return nil
+	}
+	return map[string]any{"deprecated": t.Deprecated}
+}
+
+// ListItem returns the tool as advertised in tools/list.
+func (t ToolInfo) ListItem() protocol.Tool {
+	item := protocol.Tool{
+		Name:         t.Name,
+		Title:        t.Title,
+		Description:  t.ListDescription(),
+		InputSchema:  t.InputSchema,
+		OutputSchema: t.OutputSchema,
+		Meta:         t.ListMeta(),
+		Icons:        t.Icons,
+	}
+	if t.Annotations != nil {
+		item.Annotations = t.Annotations
+	}
+	return item
 }
 
 // Option configures a Server.
@@ -47,27 +95,85 @@ type Server struct {
 	mu sync.RWMutex
 
 	info         Info
+	pinnedCaps   *Capabilities
 	instructions string
 	tools        map[string]*Tool
 	resources    map[string]*Resource
+	providers    []ResourceProvider
 	prompts      map[string]*Prompt
 	middleware   []Middleware
 	completions  *completionRegistry
+	pageSize     int
+	diagnostics  bool
+	debug        bool
+	strictInput  bool
+	schemaOpts   []schema.GenerateOption
+	strictOutput bool
+
+	trailingSlash TrailingSlashPolicy
+
+	listOrder     ListOrder
+	registrations uint64
+
+	subscriptionStore SubscriptionStore
+	sessionStore      SessionStore
+	sessionLimits     *sessionLimiter
+	resourceCache     ResourceCache
+	fileWatcher       FileWatcher
+	streamBufferLimit int64
+	taskStore         TaskStore
+	taskRetention     time.Duration
+	tasks             *taskRunner
+	sessions          *sessionRegistry
+
+	liveness *livenessTracker
+	idle     *idleTracker
+
+	listChanged     listeners[ListKind]
+	resourceUpdated listeners[string]
+
+	hooks serverHooks
+
+	methods       map[string]HandlerFunc
+	unknownMethod HandlerFunc
+
+	strictCompliance  bool
+	lifecycleDisabled bool
+	compliance        *complianceTracker
+	clients           *clientRegistry
 }
 
 // New creates a new MCP server with the given info and options.
 func New(info Info, opts ...Option) *Server {
 	s := &Server{
-		info:      info,
-		tools:     make(map[string]*Tool),
-		resources: make(map[string]*Resource),
-		prompts:   make(map[string]*Prompt),
+		info:       info,
+		tools:      make(map[string]*Tool),
+		resources:  make(map[string]*Resource),
+		prompts:    make(map[string]*Prompt),
+		methods:    make(map[string]HandlerFunc),
+		compliance: newComplianceTracker(),
+		clients:    newClientRegistry(),
+
+		subscriptionStore: NewMemoryStore(),
+		sessionLimits:     newSessionLimiter(),
+		resourceCache:     NewMemoryResourceCache(),
+		fileWatcher:       NewPollingWatcher(0),
+		liveness:          newLivenessTracker(),
+		idle:              newIdleTracker(),
+		taskStore:         NewMemoryStore(),
+		taskRetention:     defaultTaskRetention,
+		sessions:          newSessionRegistry(),
 	}
+	s.tasks = newTaskRunner(s)
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	if s.diagnostics {
+		s.registerDiagnosticsTools()
+	}
+
 	return s
 }
 
@@ -79,6 +185,31 @@ func WithInstructions(instructions string) Option {
 	}
 }
 
+// WithInputValidation validates the arguments of every tool call against
+// the tool's input schema before the handler runs, as ValidateInput does for
+// a single tool. Arguments that json.Unmarshal would accept, such as a
+// value outside an enum or a missing required field, are rejected with an
+// InvalidParams error listing the path of each invalid field.
+func WithInputValidation() Option {
+	return func(s *Server) {
+		s.strictInput = true
+	}
+}
+
+// WithSchemaOptions applies schema generation options to the input
+// schemas generated for tool handlers, for example to forbid unknown
+// properties:
+//
+//	srv := server.New(info,
+//	    server.WithSchemaOptions(schema.NoAdditionalProperties(), schema.NullablePointers()),
+//	    server.WithInputValidation(),
+//	)
+func WithSchemaOptions(opts ...schema.GenerateOption) Option {
+	return func(s *Server) {
+		s.schemaOpts = append(s.schemaOpts, opts...)
+	}
+}
+
 // Instructions returns the server instructions.
 func (s *Server) Instructions() string {
 	s.mu.RLock()
@@ -110,18 +241,26 @@ func (s *Server) Tool(name string) *ToolBuilder {
 	}
 }
 
-// Tools returns info about all registered tools.
+// Tools returns info about all registered tools in list order (sorted by
+// name by default). Hidden tools are only included in debug mode.
 func (s *Server) Tools() []ToolInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]ToolInfo, 0, len(s.tools))
-	for _, t := range s.tools {
+	for _, t := range ordered(s.tools, s.listOrder, toolSeq) {
+		if t.hidden && !s.debug {
+			continue
+		}
 		result = append(result, ToolInfo{
-			Name:        t.name,
-			Description: t.description,
-			InputSchema: t.inputSchema,
-			Annotations: t.annotations,
+			Name:         t.name,
+			Title:        t.title,
+			Description:  t.description,
+			InputSchema:  t.inputSchema,
+			OutputSchema: t.outputSchema,
+			Annotations:  t.annotations,
+			Deprecated:   t.deprecated,
+			Icons:        t.icons,
 		})
 	}
 	return result
@@ -136,15 +275,24 @@ func (s *Server) Manifest() Manifest {
 		Name:            s.info.Name,
 		Version:         s.info.Version,
 		ProtocolVersion: protocol.MCPVersion,
-		Capabilities:    s.info.Capabilities,
+		Capabilities:    s.capabilitiesLocked(),
 	}
 }
 
 // registerTool adds a tool to the server.
 func (s *Server) registerTool(t *Tool) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if s.strictInput {
+		t.validateInput = true
+	}
+	if s.strictOutput {
+		t.validateOutput = true
+	}
+	t.seq = nextSeq(s, s.tools, t.name, toolSeq)
 	s.tools[t.name] = t
+	s.mu.Unlock()
+
+	s.listChanged.emit(ListKindTools)
 }
 
 // getTool retrieves a tool by name (internal).
@@ -170,19 +318,23 @@ func (s *Server) Resource(uriTemplate string) *ResourceBuilder {
 	}
 }
 
-// Resources returns info about all registered resources.
+// Resources returns info about all registered resources in list order
+// (sorted by URI template by default).
 func (s *Server) Resources() []ResourceInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]ResourceInfo, 0, len(s.resources))
-	for _, r := range s.resources {
+	for _, r := range ordered(s.resources, s.listOrder, resourceSeq) {
 		result = append(result, ResourceInfo{
 			URITemplate: r.uriTemplate,
+			Aliases:     r.aliases,
 			Name:        r.name,
+			Title:       r.title,
 			Description: r.description,
 			MimeType:    r.mimeType,
 			Annotations: r.annotations,
+			Icons:       r.icons,
 		})
 	}
 	return result
@@ -191,8 +343,11 @@ func (s *Server) Resources() []ResourceInfo {
 // registerResource adds a resource to the server.
 func (s *Server) registerResource(r *Resource) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	r.seq = nextSeq(s, s.resources, r.uriTemplate, resourceSeq)
 	s.resources[r.uriTemplate] = r
+	s.mu.Unlock()
+
+	s.listChanged.emit(ListKindResources)
 }
 
 // getResource retrieves a resource by URI template.
@@ -208,19 +363,28 @@ func (s *Server) GetResource(uriTemplate string) (*Resource, bool) {
 	return s.getResource(uriTemplate)
 }
 
-// FindResourceForURI finds a resource that matches the given URI.
+// FindResourceForURI finds a resource that matches the given URI. When
+// several match, the first in list order wins.
 func (s *Server) FindResourceForURI(uri string) (*Resource, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, r := range s.resources {
-		if _, ok := matchURI(r.uriTemplate, uri); ok {
+	for _, r := range ordered(s.resources, s.listOrder, resourceSeq) {
+		if _, _, ok := r.match(uri); ok {
 			return r, true
 		}
 	}
 	return nil, false
 }
 
+// TrailingSlashPolicy returns how trailing slashes in resource URIs are
+// matched.
+func (s *Server) TrailingSlashPolicy() TrailingSlashPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.trailingSlash
+}
+
 // Prompt starts building a new prompt with the given name.
 func (s *Server) Prompt(name string) *PromptBuilder {
 	return &PromptBuilder{
@@ -231,18 +395,21 @@ func (s *Server) Prompt(name string) *PromptBuilder {
 	}
 }
 
-// Prompts returns info about all registered prompts.
+// Prompts returns info about all registered prompts in list order (sorted
+// by name by default).
 func (s *Server) Prompts() []PromptInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]PromptInfo, 0, len(s.prompts))
-	for _, p := range s.prompts {
+	for _, p := range ordered(s.prompts, s.listOrder, promptSeq) {
 		result = append(result, PromptInfo{
 			Name:        p.name,
+			Title:       p.title,
 			Description: p.description,
 			Arguments:   p.arguments,
 			Annotations: p.annotations,
+			Icons:       p.icons,
 		})
 	}
 	return result
@@ -251,8 +418,11 @@ func (s *Server) Prompts() []PromptInfo {
 // registerPrompt adds a prompt to the server.
 func (s *Server) registerPrompt(p *Prompt) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	p.seq = nextSeq(s, s.prompts, p.name, promptSeq)
 	s.prompts[p.name] = p
+	s.mu.Unlock()
+
+	s.listChanged.emit(ListKindPrompts)
 }
 
 // getPrompt retrieves a prompt by name.
@@ -301,21 +471,25 @@ func (s *Server) HandleCompletion(ctx context.Context, ref CompletionRef, arg Co
 	return completions.Handle(ctx, ref, arg)
 }
 
-// ResourceTemplates returns info about all registered resource templates.
+// ResourceTemplates returns info about all registered resource templates
+// in list order (sorted by URI template by default).
 func (s *Server) ResourceTemplates() []ResourceTemplateInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]ResourceTemplateInfo, 0, len(s.resources))
-	for _, r := range s.resources {
+	for _, r := range ordered(s.resources, s.listOrder, resourceSeq) {
 		// Only include resources with URI templates (containing {})
 		if isTemplate(r.uriTemplate) {
 			result = append(result, ResourceTemplateInfo{
 				URITemplate: r.uriTemplate,
+				Aliases:     r.aliases,
 				Name:        r.name,
+				Title:       r.title,
 				Description: r.description,
 				MimeType:    r.mimeType,
 				Annotations: r.annotations,
+				Icons:       r.icons,
 			})
 		}
 	}
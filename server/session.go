@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -31,12 +32,22 @@ type Session struct {
 
 	// Client capabilities (what the client supports)
 	clientCaps ClientCapabilities
+	capsSet    bool
+
+	// Client identity from initialize, if known
+	clientInfo *ClientInfo
+
+	// Persistent state, if configured
+	store SessionStore
 }
 
 // ClientCapabilities describes what features the client supports.
 type ClientCapabilities struct {
 	Sampling bool             `json:"sampling,omitempty"`
 	Roots    *RootsCapability `json:"roots,omitempty"`
+
+	// SamplingTools reports that sampling requests may include tools
+	SamplingTools bool `json:"samplingTools,omitempty"`
 }
 
 // RootsCapability describes the client's roots support.
@@ -58,6 +69,35 @@ type SessionOption func(*Session)
 func WithClientCapabilities(caps ClientCapabilities) SessionOption {
 	return func(s *Session) {
 		s.clientCaps = caps
+		s.capsSet = true
+	}
+}
+
+// WithClientInfo sets the client of the session and its capabilities.
+func WithClientInfo(info ClientInfo) SessionOption {
+	return func(s *Session) {
+		s.clientInfo = &info
+		s.clientCaps = info.Capabilities
+		s.capsSet = true
+	}
+}
+
+// WithSessionSubscriptions keeps the session's resource subscriptions in
+// store instead of a private in-memory store. Share a store between
+// sessions to look up subscribers across all of them.
+func WithSessionSubscriptions(store SubscriptionStore) SessionOption {
+	return func(s *Session) {
+		s.subscriptions = NewSubscriptionManagerWithStore(store)
+	}
+}
+
+// WithSessionStateStore persists the session state (log level, client
+// capabilities and roots) in store. When the store already holds state for
+// the session ID, the session is restored from it; client capabilities set
+// with WithClientCapabilities take precedence over the stored ones.
+func WithSessionStateStore(store SessionStore) SessionOption {
+	return func(s *Session) {
+		s.store = store
 	}
 }
 
@@ -71,21 +111,84 @@ func WithRootsChangeCallback(callback func([]Root)) SessionOption {
 // NewSession creates a new session with the given ID and options.
 func NewSession(id string, sender RequestSender, notifier NotificationSender, opts ...SessionOption) *Session {
 	s := &Session{
-		id:            id,
-		sender:        sender,
-		notifier:      notifier,
-		logLevel:      LogLevelInfo,
-		cancellation:  NewCancellationManager(),
-		subscriptions: NewSubscriptionManager(),
+		id:           id,
+		sender:       sender,
+		notifier:     notifier,
+		logLevel:     LogLevelInfo,
+		cancellation: NewCancellationManager(),
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	if s.subscriptions == nil {
+		s.subscriptions = NewSubscriptionManager()
+	}
+	if s.store != nil {
+		s.restore()
+	}
+
 	return s
 }
 
+// restore loads the stored state of the session, if any.
+func (s *Session) restore() {
+	state, err := s.store.LoadSession(context.Background(), s.id)
+	if err != nil {
+		return
+	}
+
+	if state.LogLevel != "" {
+		s.logLevel = state.LogLevel
+	}
+	if !s.capsSet {
+		s.clientCaps = state.ClientCapabilities
+	}
+	if s.clientInfo == nil && state.ClientInfo != nil {
+		info := *state.ClientInfo
+		s.clientInfo = &info
+	}
+	s.roots = state.Roots
+}
+
+// State returns a snapshot of the session's persistent state.
+func (s *Session) State() SessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return SessionState{
+		ID:                 s.id,
+		LogLevel:           s.logLevel,
+		ClientCapabilities: s.clientCaps,
+		Roots:              s.roots,
+		ClientInfo:         s.clientInfoLocked(),
+	}
+}
+
+// Save writes the session state to the session store.
+// It does nothing if no store is configured.
+func (s *Session) Save(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SaveSession(ctx, s.State())
+}
+
+// Close ends the session, removing its subscriptions and stored state.
+func (s *Session) Close(ctx context.Context) error {
+	err := s.subscriptions.UnsubscribeAll(s.id)
+	if s.store != nil {
+		err = errors.Join(err, s.store.DeleteSession(ctx, s.id))
+	}
+	return err
+}
+
+// persist saves the session state after a change. Failures are ignored;
+// use Save to handle them.
+func (s *Session) persist() {
+	_ = s.Save(context.Background())
+}
+
 // ID returns the session ID.
 func (s *Session) ID() string {
 	return s.id
@@ -98,11 +201,36 @@ func (s *Session) ClientCapabilities() ClientCapabilities {
 	return s.clientCaps
 }
 
+// ClientInfo returns the client of the session, or false if it was not
+// set with WithClientInfo or restored from the session store.
+func (s *Session) ClientInfo() (ClientInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info := s.clientInfoLocked()
+	if info == nil {
+		return ClientInfo{}, false
+	}
+	return *info, true
+}
+
+// clientInfoLocked returns a copy of the client info with the current
+// capabilities, or nil. The caller must hold s.mu.
+func (s *Session) clientInfoLocked() *ClientInfo {
+	if s.clientInfo == nil {
+		return nil
+	}
+	info := *s.clientInfo
+	info.Capabilities = s.clientCaps
+	return &info
+}
+
 // SetClientCapabilities updates the client's capabilities.
 func (s *Session) SetClientCapabilities(caps ClientCapabilities) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.clientCaps = caps
+	s.mu.Unlock()
+
+	s.persist()
 }
 
 // SupportsFeature returns true if the client supports the given feature.
@@ -113,6 +241,8 @@ func (s *Session) SupportsFeature(feature string) bool {
 	switch feature {
 	case "sampling":
 		return s.clientCaps.Sampling
+	case "sampling.tools":
+		return s.clientCaps.Sampling && s.clientCaps.SamplingTools
 	case "roots":
 		return s.clientCaps.Roots != nil
 	case "roots.listChanged":
@@ -128,6 +258,9 @@ func (s *Session) CreateMessage(ctx context.Context, req *CreateMessageRequest)
 	if !s.SupportsFeature("sampling") {
 		return nil, fmt.Errorf("client does not support sampling")
 	}
+	if len(req.Tools) > 0 && !s.SupportsFeature("sampling.tools") {
+		return nil, fmt.Errorf("client does not support tools in sampling")
+	}
 
 	params, err := json.Marshal(req)
 	if err != nil {
@@ -169,6 +302,28 @@ func (s *Session) CreateMessage(ctx context.Context, req *CreateMessageRequest)
 	return &result, nil
 }
 
+// CreateMessageWithFallback sends a sampling request and, if the client
+// rejects it with an invalid params error, retries with degraded model
+// preferences from ModelPreferences.Fallbacks: first dropping model hints
+// one at a time, then sending no preferences. It returns the result of
+// the first accepted request, or the error of the last attempt.
+func (s *Session) CreateMessageWithFallback(ctx context.Context, req *CreateMessageRequest) (*CreateMessageResult, error) {
+	result, err := s.CreateMessage(ctx, req)
+	if !errors.Is(err, &protocol.Error{Code: protocol.CodeInvalidParams}) {
+		return result, err
+	}
+
+	for _, prefs := range req.ModelPreferences.Fallbacks() {
+		degraded := *req
+		degraded.ModelPreferences = prefs
+		result, err = s.CreateMessage(ctx, &degraded)
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeInvalidParams}) {
+			return result, err
+		}
+	}
+	return nil, err
+}
+
 // ListRoots requests the list of roots from the client.
 // Returns an error if the client doesn't support roots.
 func (s *Session) ListRoots(ctx context.Context) (*ListRootsResult, error) {
@@ -212,10 +367,32 @@ func (s *Session) ListRoots(ctx context.Context) (*ListRootsResult, error) {
 	s.mu.Lock()
 	s.roots = result.Roots
 	s.mu.Unlock()
+	s.persist()
 
 	return &result, nil
 }
 
+// Ping sends a ping to the client and waits for its response.
+func (s *Session) Ping(ctx context.Context) error {
+	idRaw, err := json.Marshal(s.requestID.Add(1))
+	if err != nil {
+		return fmt.Errorf("marshal request ID: %w", err)
+	}
+
+	resp, err := s.sender.SendRequest(ctx, &protocol.Request{
+		JSONRPC: protocol.JSONRPCVersion,
+		ID:      idRaw,
+		Method:  protocol.MethodPing,
+	})
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
 // Roots returns the cached roots. Call ListRoots first to populate.
 func (s *Session) Roots() []Root {
 	s.mu.RLock()
@@ -229,6 +406,7 @@ func (s *Session) HandleRootsChanged(roots []Root) {
 	s.roots = roots
 	callback := s.rootsChange
 	s.mu.Unlock()
+	s.persist()
 
 	if callback != nil {
 		callback(roots)
@@ -297,8 +475,10 @@ func (s *Session) Emergency(logger string, data any) {
 // SetLogLevel sets the minimum log level.
 func (s *Session) SetLogLevel(level LogLevel) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.logLevel = level
+	s.mu.Unlock()
+
+	s.persist()
 }
 
 // LogLevel returns the current minimum log level.
@@ -323,13 +503,13 @@ func (s *Session) CancellationManager() *CancellationManager {
 }
 
 // Subscribe adds a subscription for a resource URI.
-func (s *Session) Subscribe(uri string) {
-	s.subscriptions.Subscribe(s.id, uri)
+func (s *Session) Subscribe(uri string) error {
+	return s.subscriptions.Subscribe(s.id, uri)
 }
 
 // Unsubscribe removes a subscription for a resource URI.
-func (s *Session) Unsubscribe(uri string) {
-	s.subscriptions.Unsubscribe(s.id, uri)
+func (s *Session) Unsubscribe(uri string) error {
+	return s.subscriptions.Unsubscribe(s.id, uri)
 }
 
 // SubscriptionManager returns the session's subscription manager.
@@ -337,6 +517,11 @@ func (s *Session) SubscriptionManager() *SubscriptionManager {
 	return s.subscriptions
 }
 
+// Notify sends a notification to the client of the session.
+func (s *Session) Notify(method string, params any) error {
+	return s.notifier.SendNotification(method, params)
+}
+
 // NotifyResourceUpdated sends a resource updated notification.
 func (s *Session) NotifyResourceUpdated(uri string) error {
 	notification := ResourceUpdatedNotification{URI: uri}
@@ -181,6 +181,126 @@ func TestSessionCreateMessageNoCapability(t *testing.T) {
 	}
 }
 
+func TestSessionCreateMessageTools(t *testing.T) {
+	req := &CreateMessageRequest{
+		Messages:   []SamplingMessage{{Role: RoleUser, Content: NewTextContent("Weather in Paris?")}},
+		MaxTokens:  100,
+		Tools:      []SamplingTool{{Name: "get_weather", InputSchema: map[string]any{"type": "object"}}},
+		ToolChoice: &ToolChoice{Mode: ToolChoiceAuto},
+	}
+
+	t.Run("requires tools capability", func(t *testing.T) {
+		session := NewSession("session-1", &mockRequestSender{}, &mockNotificationSender{},
+			WithClientCapabilities(ClientCapabilities{Sampling: true}))
+
+		if _, err := session.CreateMessage(context.Background(), req); err == nil {
+			t.Error("expected error when tools in sampling are not supported")
+		}
+	})
+
+	t.Run("sends tools", func(t *testing.T) {
+		sender := &mockRequestSender{
+			responses: []*protocol.Response{{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`1`),
+				Result: map[string]any{
+					"role":       "assistant",
+					"model":      "m",
+					"stopReason": StopReasonToolUse,
+					"content": []any{
+						map[string]any{"type": "tool_use", "id": "call-1", "name": "get_weather", "input": map[string]any{"city": "Paris"}},
+					},
+				},
+			}},
+		}
+		session := NewSession("session-1", sender, &mockNotificationSender{},
+			WithClientCapabilities(ClientCapabilities{Sampling: true, SamplingTools: true}))
+
+		result, err := session.CreateMessage(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		uses := result.ToolUses()
+		if len(uses) != 1 || uses[0].Name != "get_weather" || uses[0].Input["city"] != "Paris" {
+			t.Errorf("tool uses = %+v", uses)
+		}
+
+		var params map[string]any
+		if err := json.Unmarshal(sender.requests[0].Params, &params); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := params["tools"]; !ok {
+			t.Error("request params missing tools")
+		}
+		if choice, _ := params["toolChoice"].(map[string]any); choice["mode"] != "auto" {
+			t.Errorf("toolChoice = %v", params["toolChoice"])
+		}
+	})
+}
+
+func TestSessionCreateMessageWithFallback(t *testing.T) {
+	rejected := &protocol.Response{JSONRPC: "2.0", Error: protocol.NewInvalidParams("unknown model hint")}
+	accepted := &protocol.Response{
+		JSONRPC: "2.0",
+		Result:  map[string]any{"role": "assistant", "content": map[string]any{"type": "text", "text": "ok"}, "model": "m"},
+	}
+	req := &CreateMessageRequest{
+		Messages:  []SamplingMessage{{Role: RoleUser, Content: NewTextContent("hi")}},
+		MaxTokens: 10,
+		ModelPreferences: &ModelPreferences{
+			Hints: []ModelHint{{Name: "opus"}, {Name: "sonnet"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		responses []*protocol.Response
+		wantErr   bool
+		wantSent  int
+		lastHints int // number of hints in the last request; -1 means no preferences
+	}{
+		{name: "accepted first time", responses: []*protocol.Response{accepted}, wantSent: 1, lastHints: 2},
+		{name: "drops a hint", responses: []*protocol.Response{rejected, accepted}, wantSent: 2, lastHints: 1},
+		{name: "drops preferences", responses: []*protocol.Response{rejected, rejected, rejected, accepted}, wantSent: 4, lastHints: -1},
+		{name: "gives up", responses: []*protocol.Response{rejected, rejected, rejected, rejected}, wantErr: true, wantSent: 4, lastHints: -1},
+		{
+			name:      "other errors are not retried",
+			responses: []*protocol.Response{{JSONRPC: "2.0", Error: protocol.NewInternalError("rejected by user")}},
+			wantErr:   true,
+			wantSent:  1,
+			lastHints: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &mockRequestSender{responses: tt.responses}
+			session := NewSession("session-1", sender, &mockNotificationSender{},
+				WithClientCapabilities(ClientCapabilities{Sampling: true}))
+
+			_, err := session.CreateMessageWithFallback(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(sender.requests) != tt.wantSent {
+				t.Fatalf("sent %d requests, want %d", len(sender.requests), tt.wantSent)
+			}
+
+			var last CreateMessageRequest
+			if err := json.Unmarshal(sender.requests[len(sender.requests)-1].Params, &last); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			hints := -1
+			if last.ModelPreferences != nil {
+				hints = len(last.ModelPreferences.Hints)
+			}
+			if hints != tt.lastHints {
+				t.Errorf("last request hints = %d, want %d", hints, tt.lastHints)
+			}
+		})
+	}
+}
+
 func TestSessionListRoots(t *testing.T) {
 	sender := &mockRequestSender{
 		responses: []*protocol.Response{
@@ -219,6 +339,29 @@ func TestSessionListRoots(t *testing.T) {
 	}
 }
 
+func TestSessionPing(t *testing.T) {
+	sender := &mockRequestSender{
+		responses: []*protocol.Response{
+			{JSONRPC: "2.0", ID: json.RawMessage(`1`), Result: map[string]any{}},
+			{JSONRPC: "2.0", ID: json.RawMessage(`2`), Error: protocol.NewMethodNotFound("method not found")},
+		},
+	}
+	session := NewSession("session-1", sender, &mockNotificationSender{})
+
+	if err := session.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := sender.requests[0].Method; got != protocol.MethodPing {
+		t.Errorf("method = %q, want %q", got, protocol.MethodPing)
+	}
+	if err := session.Ping(context.Background()); !errors.Is(err, &protocol.Error{Code: protocol.CodeMethodNotFound}) {
+		t.Errorf("Ping() error = %v, want method not found", err)
+	}
+	if err := session.Ping(context.Background()); err == nil {
+		t.Error("Ping() with failing sender succeeded")
+	}
+}
+
 func TestSessionListRootsNoCapability(t *testing.T) {
 	sender := &mockRequestSender{}
 	notifier := &mockNotificationSender{}
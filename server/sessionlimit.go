@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// SessionLimitStats counts the effect of the per-identity session limit.
+type SessionLimitStats struct {
+	// Rejected counts initialize requests rejected because the identity
+	// had reached its limit.
+	Rejected int64
+	// Evicted counts sessions evicted to make room for a newer one.
+	Evicted int64
+}
+
+// SessionEvictedFunc is called when a session is evicted because its
+// identity opened a session beyond the limit.
+type SessionEvictedFunc func(identity, connID string)
+
+// sessionLimiter tracks the sessions of each identity in the order they
+// were admitted.
+type sessionLimiter struct {
+	mu         sync.Mutex
+	max        int
+	evict      bool
+	onEvicted  SessionEvictedFunc
+	byIdentity map[string][]string // identity -> connection IDs, oldest first
+	identityOf map[string]string   // connection ID -> identity
+
+	rejected atomic.Int64
+	evicted  atomic.Int64
+}
+
+func newSessionLimiter() *sessionLimiter {
+	return &sessionLimiter{
+		byIdentity: make(map[string][]string),
+		identityOf: make(map[string]string),
+	}
+}
+
+// WithMaxSessionsPerIdentity limits the number of concurrent sessions an
+// authenticated identity may hold on network transports. When an identity
+// at the limit initializes a new session, the initialize request is
+// rejected with a rate limited error, unless WithSessionEviction is set.
+// A limit of zero or less disables the check (the default).
+//
+// The identity is the one set by the authentication middleware;
+// unauthenticated sessions are not limited. A session frees its slot when
+// its connection closes; HTTP sessions the client never ends are freed by
+// the idle timeout, which defaults to DefaultSessionLimitIdleTimeout.
+func WithMaxSessionsPerIdentity(n int) Option {
+	return func(s *Server) {
+		s.sessionLimits.max = n
+	}
+}
+
+// WithSessionEviction evicts the oldest session of an identity at its
+// session limit instead of rejecting the new one. An evicted connection
+// must initialize again before it is served. onEvicted, if not nil, is
+// called for every evicted session.
+func WithSessionEviction(onEvicted SessionEvictedFunc) Option {
+	return func(s *Server) {
+		s.sessionLimits.evict = true
+		s.sessionLimits.onEvicted = onEvicted
+	}
+}
+
+// MaxSessionsPerIdentity returns the per-identity session limit, or zero
+// if sessions are not limited.
+func (s *Server) MaxSessionsPerIdentity() int {
+	s.sessionLimits.mu.Lock()
+	defer s.sessionLimits.mu.Unlock()
+	return s.sessionLimits.max
+}
+
+// SessionLimitStats returns the number of rejected and evicted sessions.
+func (s *Server) SessionLimitStats() SessionLimitStats {
+	return SessionLimitStats{
+		Rejected: s.sessionLimits.rejected.Load(),
+		Evicted:  s.sessionLimits.evicted.Load(),
+	}
+}
+
+// SessionCount returns the number of sessions held by identity.
+func (s *Server) SessionCount(identity string) int {
+	s.sessionLimits.mu.Lock()
+	defer s.sessionLimits.mu.Unlock()
+	return len(s.sessionLimits.byIdentity[identity])
+}
+
+// AdmitSession records that the connection connID, authenticated as
+// identity, is initializing a session. It returns a rate limited error if
+// the identity is at its limit and eviction is disabled. With eviction
+// enabled, the oldest sessions of the identity are evicted and returned:
+// their lifecycle state is forgotten, their state is deleted from the
+// session store so no instance resumes them, and the eviction hook is
+// called.
+//
+// Request handlers call AdmitSession on initialize; it does nothing if no
+// limit is configured or identity or connID is empty. Re-initializing a
+// connection does not count as a new session.
+func (s *Server) AdmitSession(identity, connID string) (evicted []string, err error) {
+	l := s.sessionLimits
+	if identity == "" || connID == "" {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	if l.max <= 0 {
+		l.mu.Unlock()
+		return nil, nil
+	}
+	if _, ok := l.identityOf[connID]; ok {
+		l.mu.Unlock()
+		return nil, nil
+	}
+
+	conns := l.byIdentity[identity]
+	if len(conns) >= l.max {
+		if !l.evict {
+			l.mu.Unlock()
+			l.rejected.Add(1)
+			return nil, &protocol.Error{
+				Code:    protocol.CodeRateLimited,
+				Message: fmt.Sprintf("too many sessions: identity %s has reached the limit of %d", identity, l.max),
+			}
+		}
+		n := len(conns) - l.max + 1
+		evicted = append(evicted, conns[:n]...)
+		conns = conns[n:]
+		for _, id := range evicted {
+			delete(l.identityOf, id)
+		}
+	}
+	l.byIdentity[identity] = append(conns, connID)
+	l.identityOf[connID] = identity
+	onEvicted := l.onEvicted
+	l.mu.Unlock()
+
+	for _, id := range evicted {
+		l.evicted.Add(1)
+		s.forgetLifecycle(id)
+		if store := s.SessionStore(); store != nil {
+			_ = store.DeleteSession(context.Background(), id)
+		}
+		if onEvicted != nil {
+			onEvicted(identity, id)
+		}
+	}
+	return evicted, nil
+}
+
+// release frees the session slot held by connID, if any.
+func (l *sessionLimiter) release(connID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	identity, ok := l.identityOf[connID]
+	if !ok {
+		return
+	}
+	delete(l.identityOf, connID)
+
+	conns := l.byIdentity[identity]
+	for i, id := range conns {
+		if id == connID {
+			conns = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(l.byIdentity, identity)
+	} else {
+		l.byIdentity[identity] = conns
+	}
+}
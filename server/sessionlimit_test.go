@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestAdmitSession(t *testing.T) {
+	t.Run("unlimited by default", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		for _, conn := range []string{"c1", "c2", "c3"} {
+			if _, err := srv.AdmitSession("alice", conn); err != nil {
+				t.Fatalf("AdmitSession(%q) error = %v", conn, err)
+			}
+		}
+		if n := srv.SessionCount("alice"); n != 0 {
+			t.Errorf("SessionCount() = %d, want 0 when unlimited", n)
+		}
+	})
+
+	t.Run("rejects sessions over the limit", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithMaxSessionsPerIdentity(2))
+
+		for _, conn := range []string{"c1", "c2"} {
+			if _, err := srv.AdmitSession("alice", conn); err != nil {
+				t.Fatalf("AdmitSession(%q) error = %v", conn, err)
+			}
+		}
+		_, err := srv.AdmitSession("alice", "c3")
+		var protoErr *protocol.Error
+		if !errors.As(err, &protoErr) || protoErr.Code != protocol.CodeRateLimited {
+			t.Fatalf("AdmitSession() error = %v, want rate limited", err)
+		}
+
+		// Other identities and re-initialized connections are unaffected
+		if _, err := srv.AdmitSession("bob", "c4"); err != nil {
+			t.Errorf("AdmitSession(bob) error = %v", err)
+		}
+		if _, err := srv.AdmitSession("alice", "c1"); err != nil {
+			t.Errorf("re-initialize error = %v", err)
+		}
+		// Unauthenticated sessions are not limited
+		if _, err := srv.AdmitSession("", "c5"); err != nil {
+			t.Errorf("AdmitSession(\"\") error = %v", err)
+		}
+
+		if got := srv.SessionLimitStats(); got != (SessionLimitStats{Rejected: 1}) {
+			t.Errorf("SessionLimitStats() = %+v", got)
+		}
+	})
+
+	t.Run("closing a connection frees its slot", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithMaxSessionsPerIdentity(1))
+
+		_, _ = srv.AdmitSession("alice", "c1")
+		srv.ForgetConnection("c1")
+
+		if _, err := srv.AdmitSession("alice", "c2"); err != nil {
+			t.Errorf("AdmitSession() after close error = %v", err)
+		}
+		if n := srv.SessionCount("alice"); n != 1 {
+			t.Errorf("SessionCount() = %d, want 1", n)
+		}
+	})
+
+	t.Run("evicts the oldest session", func(t *testing.T) {
+		var hooked []string
+		srv := New(Info{Name: "test", Version: "1.0.0"},
+			WithMaxSessionsPerIdentity(2),
+			WithSessionEviction(func(identity, connID string) {
+				hooked = append(hooked, identity+"/"+connID)
+			}),
+		)
+
+		// Bring c1 to the ready state so eviction can be observed
+		_ = srv.CheckClientCompliance("c1", &protocol.Request{Method: protocol.MethodInitialize, ID: []byte("1")})
+		_ = srv.CheckClientCompliance("c1", &protocol.Request{Method: protocol.MethodInitialized})
+
+		_, _ = srv.AdmitSession("alice", "c1")
+		_, _ = srv.AdmitSession("alice", "c2")
+		evicted, err := srv.AdmitSession("alice", "c3")
+		if err != nil {
+			t.Fatalf("AdmitSession() error = %v", err)
+		}
+
+		if !slices.Equal(evicted, []string{"c1"}) {
+			t.Errorf("evicted = %v, want [c1]", evicted)
+		}
+		if !slices.Equal(hooked, []string{"alice/c1"}) {
+			t.Errorf("hook calls = %v, want [alice/c1]", hooked)
+		}
+		if state := srv.LifecycleState("c1"); state != StateUninitialized {
+			t.Errorf("evicted connection state = %v, want uninitialized", state)
+		}
+		if got := srv.SessionLimitStats(); got != (SessionLimitStats{Evicted: 1}) {
+			t.Errorf("SessionLimitStats() = %+v", got)
+		}
+		if n := srv.SessionCount("alice"); n != 2 {
+			t.Errorf("SessionCount() = %d, want 2", n)
+		}
+	})
+
+	t.Run("eviction deletes the stored session", func(t *testing.T) {
+		store := NewMemoryStore()
+		srv := New(Info{Name: "test", Version: "1.0.0"},
+			WithSessionStore(store),
+			WithMaxSessionsPerIdentity(1),
+			WithSessionEviction(nil),
+		)
+		ctx := context.Background()
+		_ = store.SaveSession(ctx, SessionState{ID: "c1"})
+
+		_, _ = srv.AdmitSession("alice", "c1")
+		if _, err := srv.AdmitSession("alice", "c2"); err != nil {
+			t.Fatalf("AdmitSession() error = %v", err)
+		}
+		if _, err := store.LoadSession(ctx, "c1"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("LoadSession() of evicted session error = %v, want not found", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// sessionRegistry holds the active sessions of a server by ID.
+type sessionRegistry struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+
+	connect    hooks[*Session]
+	disconnect hooks[*Session]
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{sessions: make(map[string]*Session)}
+}
+
+// AddSession registers an active session, replacing any session with the
+// same ID, and runs the OnSessionConnect hooks. Request handlers call it
+// once a client has initialized.
+func (s *Server) AddSession(ctx context.Context, session *Session) {
+	r := s.sessions
+	r.mu.Lock()
+	r.sessions[session.ID()] = session
+	r.mu.Unlock()
+
+	_ = r.connect.run(ctx, session)
+}
+
+// RemoveSession unregisters a session and runs the OnSessionDisconnect
+// hooks. It does nothing if the session is not registered, including when
+// a newer session with the same ID replaced it. Request handlers call it
+// when the connection of a session closes.
+func (s *Server) RemoveSession(ctx context.Context, session *Session) {
+	r := s.sessions
+	r.mu.Lock()
+	registered := r.sessions[session.ID()] == session
+	if registered {
+		delete(r.sessions, session.ID())
+	}
+	r.mu.Unlock()
+
+	if registered {
+		_ = r.disconnect.run(ctx, session)
+	}
+}
+
+// Sessions returns the active sessions, sorted by ID.
+func (s *Server) Sessions() []*Session {
+	r := s.sessions
+	r.mu.RLock()
+	sessions := make([]*Session, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, session)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID() < sessions[j].ID() })
+	return sessions
+}
+
+// Session returns the active session with the given ID.
+func (s *Server) Session(id string) (*Session, bool) {
+	s.sessions.mu.RLock()
+	defer s.sessions.mu.RUnlock()
+	session, ok := s.sessions.sessions[id]
+	return session, ok
+}
+
+// Broadcast sends a notification to every active session. It returns the
+// errors of the sessions the notification could not be sent to, joined.
+//
+// Example:
+//
+//	err := srv.Broadcast("notifications/message", map[string]any{
+//	    "level": "warning",
+//	    "data":  "maintenance in 5 minutes",
+//	})
+func (s *Server) Broadcast(method string, params any) error {
+	var errs []error
+	for _, session := range s.Sessions() {
+		if err := session.Notify(method, params); err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", session.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnSessionConnect registers fn to be called when a client initializes a
+// session. It returns a function that removes fn.
+func (s *Server) OnSessionConnect(fn func(ctx context.Context, session *Session)) (remove func()) {
+	return s.sessions.connect.add(func(ctx context.Context, session *Session) error {
+		fn(ctx, session)
+		return nil
+	})
+}
+
+// OnSessionDisconnect registers fn to be called when the connection of a
+// session closes. It returns a function that removes fn.
+func (s *Server) OnSessionDisconnect(fn func(ctx context.Context, session *Session)) (remove func()) {
+	return s.sessions.disconnect.add(func(ctx context.Context, session *Session) error {
+		fn(ctx, session)
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingNotifier is a NotificationSender whose sends fail.
+type failingNotifier struct{}
+
+func (failingNotifier) SendNotification(method string, params any) error {
+	return errors.New("connection closed")
+}
+
+func TestServer_Sessions(t *testing.T) {
+	ctx := context.Background()
+	s := New(Info{Name: "test", Version: "1.0.0"})
+
+	var connected, disconnected []string
+	s.OnSessionConnect(func(ctx context.Context, session *Session) {
+		connected = append(connected, session.ID())
+	})
+	removeDisconnect := s.OnSessionDisconnect(func(ctx context.Context, session *Session) {
+		disconnected = append(disconnected, session.ID())
+	})
+
+	b := NewSession("b", nil, &mockNotifier{})
+	a := NewSession("a", nil, &mockNotifier{})
+	s.AddSession(ctx, b)
+	s.AddSession(ctx, a)
+
+	var ids []string
+	for _, session := range s.Sessions() {
+		ids = append(ids, session.ID())
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Errorf("Sessions() = %v, want [a b]", ids)
+	}
+	if got, ok := s.Session("a"); !ok || got != a {
+		t.Errorf("Session(a) = %v, %v", got, ok)
+	}
+	if _, ok := s.Session("missing"); ok {
+		t.Error("Session(missing) found")
+	}
+
+	// A newer session with the same ID replaces the old one, which can no
+	// longer remove it
+	newer := NewSession("a", nil, &mockNotifier{})
+	s.AddSession(ctx, newer)
+	s.RemoveSession(ctx, a)
+	if got, _ := s.Session("a"); got != newer {
+		t.Error("removing a replaced session removed its replacement")
+	}
+
+	s.RemoveSession(ctx, newer)
+	removeDisconnect()
+	s.RemoveSession(ctx, b)
+
+	if strings.Join(connected, ",") != "b,a,a" {
+		t.Errorf("connected = %v, want [b a a]", connected)
+	}
+	if strings.Join(disconnected, ",") != "a" {
+		t.Errorf("disconnected = %v, want [a]", disconnected)
+	}
+	if len(s.Sessions()) != 0 {
+		t.Errorf("Sessions() = %v, want none", s.Sessions())
+	}
+}
+
+func TestServer_Broadcast(t *testing.T) {
+	ctx := context.Background()
+	s := New(Info{Name: "test", Version: "1.0.0"})
+
+	n1, n2 := &mockNotifier{}, &mockNotifier{}
+	s.AddSession(ctx, NewSession("s1", nil, n1))
+	s.AddSession(ctx, NewSession("s2", nil, n2))
+
+	if err := s.Broadcast("notifications/message", map[string]any{"data": "hello"}); err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	for _, n := range []*mockNotifier{n1, n2} {
+		got := n.getNotifications()
+		if len(got) != 1 || got[0].Method != "notifications/message" {
+			t.Errorf("notifications = %+v", got)
+		}
+	}
+
+	s.AddSession(ctx, NewSession("s3", nil, failingNotifier{}))
+	err := s.Broadcast("notifications/message", nil)
+	if err == nil || !strings.Contains(err.Error(), "session s3") {
+		t.Errorf("Broadcast() error = %v, want failure of s3", err)
+	}
+	if got := n1.getNotifications(); len(got) != 2 {
+		t.Errorf("s1 notifications = %d, want 2 despite the failure", len(got))
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// StaticOption configures StaticResources.
+type StaticOption func(*staticConfig)
+
+type staticConfig struct {
+	mimeMap map[string]string
+}
+
+// WithMimeMap sets the MIME types of file extensions, such as
+// {".tmpl": "text/x-go-template"}, overriding the detected types.
+func WithMimeMap(m map[string]string) StaticOption {
+	return func(c *staticConfig) {
+		c.mimeMap = m
+	}
+}
+
+// StaticResources registers every file of fsys as a resource whose URI is
+// baseURI followed by the file's slash-separated path. The files are read
+// once, at registration, so fsys is typically an embed.FS shipping
+// documentation or templates with the server. MIME types come from
+// WithMimeMap or are detected with DetectMimeType.
+//
+// Example:
+//
+//	//go:embed docs
+//	var docs embed.FS
+//
+//	sub, _ := fs.Sub(docs, "docs")
+//	if err := srv.StaticResources("docs://", sub,
+//	    server.WithMimeMap(map[string]string{".tmpl": "text/plain"}),
+//	); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Server) StaticResources(baseURI string, fsys fs.FS, opts ...StaticOption) error {
+	cfg := &staticConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		mimeType, ok := cfg.mimeMap[path.Ext(name)]
+		if !ok {
+			mimeType = DetectMimeType(name, data)
+		}
+		b := s.Resource(baseURI + name).
+			Name(name).
+			MimeType(mimeType).
+			Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+				return contentFromBytes(uri, mimeType, data), nil
+			})
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("register %s: %w", name, err)
+		}
+		return nil
+	})
+}
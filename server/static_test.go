@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestServer_StaticResources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":           {Data: []byte("# Docs")},
+		"guide/intro.md":      {Data: []byte("# Intro")},
+		"templates/page.tmpl": {Data: []byte("{{.Title}}")},
+		"images/logo.png":     {Data: []byte("\x89PNG\r\n\x1a\n\x00\x00")},
+		"notes":               {Data: []byte("plain notes")},
+	}
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	err := srv.StaticResources("docs://", fsys, WithMimeMap(map[string]string{".tmpl": "text/x-go-template"}))
+	if err != nil {
+		t.Fatalf("StaticResources() error = %v", err)
+	}
+
+	resources := srv.Resources()
+	if len(resources) != 5 {
+		t.Fatalf("Resources() returned %d resources, want 5", len(resources))
+	}
+	r, ok := srv.GetResource("docs://guide/intro.md")
+	if !ok {
+		t.Fatal("docs://guide/intro.md not registered")
+	}
+	if r.name != "guide/intro.md" || r.mimeType != "text/markdown" {
+		t.Errorf("resource = %q (%s), want name guide/intro.md with markdown type", r.name, r.mimeType)
+	}
+
+	tests := []struct {
+		uri      string
+		wantText string
+		wantMime string
+		wantBlob bool
+	}{
+		{uri: "docs://README.md", wantText: "# Docs", wantMime: "text/markdown"},
+		{uri: "docs://templates/page.tmpl", wantText: "{{.Title}}", wantMime: "text/x-go-template"},
+		{uri: "docs://images/logo.png", wantMime: "image/png", wantBlob: true},
+		{uri: "docs://notes", wantText: "plain notes", wantMime: "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			content, err := srv.ReadResource(context.Background(), tt.uri)
+			if err != nil {
+				t.Fatalf("ReadResource() error = %v", err)
+			}
+			if content.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", content.Text, tt.wantText)
+			}
+			if content.MimeType != tt.wantMime {
+				t.Errorf("MimeType = %q, want %q", content.MimeType, tt.wantMime)
+			}
+			if tt.wantBlob {
+				if data, err := base64.StdEncoding.DecodeString(content.Blob); err != nil || string(data) != string(fsys["images/logo.png"].Data) {
+					t.Errorf("Blob = %q, want the file data", content.Blob)
+				}
+			}
+		})
+	}
+}
+
+// errFS is a file system that cannot be opened.
+type errFS struct{}
+
+func (errFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestServer_StaticResources_Errors(t *testing.T) {
+	t.Run("unreadable file system", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		if err := srv.StaticResources("docs://", errFS{}); !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("StaticResources() error = %v, want permission error", err)
+		}
+	})
+
+	t.Run("empty file system", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		if err := srv.StaticResources("docs://", fstest.MapFS{}); err != nil {
+			t.Errorf("StaticResources() error = %v", err)
+		}
+		if len(srv.Resources()) != 0 {
+			t.Errorf("Resources() = %v, want none", srv.Resources())
+		}
+	})
+}
+
+func TestResourceBuilder_Err(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	b := srv.Resource("docs://{path}").Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return nil, nil
+	})
+	if err := b.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
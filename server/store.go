@@ -0,0 +1,275 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrSessionNotFound is returned by SessionStore.LoadSession when no state
+// is stored for a session.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SubscriptionStore persists resource subscriptions, keyed by session ID.
+// The default store keeps subscriptions in memory. Implement it on top of a
+// shared database, such as Redis, so horizontally scaled deployments see
+// the same subscriptions and keep them across restarts.
+//
+// Implementations must be safe for concurrent use. Use
+// testutil.RunSubscriptionStoreContract to verify an implementation.
+type SubscriptionStore interface {
+	// Subscribe adds a subscription. Subscribing twice is not an error.
+	Subscribe(ctx context.Context, sessionID, uri string) error
+	// Unsubscribe removes a subscription. Removing a missing subscription
+	// is not an error.
+	Unsubscribe(ctx context.Context, sessionID, uri string) error
+	// UnsubscribeAll removes all subscriptions of a session.
+	UnsubscribeAll(ctx context.Context, sessionID string) error
+	// Subscribers returns the IDs of sessions subscribed to uri, sorted.
+	Subscribers(ctx context.Context, uri string) ([]string, error)
+	// Count returns the total number of subscriptions.
+	Count(ctx context.Context) (int, error)
+}
+
+// SessionState is the persistent state of a session.
+type SessionState struct {
+	ID                 string             `json:"id"`
+	LogLevel           LogLevel           `json:"logLevel,omitempty"`
+	ClientCapabilities ClientCapabilities `json:"clientCapabilities"`
+	Roots              []Root             `json:"roots,omitempty"`
+	// ClientInfo identifies the client, or is nil for sessions saved
+	// before it was recorded.
+	ClientInfo *ClientInfo `json:"clientInfo,omitempty"`
+}
+
+// SessionStore persists session state, so a session can be restored on
+// another server instance or after a restart.
+//
+// Implementations must be safe for concurrent use. Use
+// testutil.RunSessionStoreContract to verify an implementation.
+type SessionStore interface {
+	// SaveSession creates or replaces the state of a session.
+	SaveSession(ctx context.Context, state SessionState) error
+	// LoadSession returns the state of a session, or ErrSessionNotFound.
+	LoadSession(ctx context.Context, id string) (SessionState, error)
+	// DeleteSession removes the state of a session. Deleting a missing
+	// session is not an error.
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// WithSubscriptionStore sets the store used for resource subscriptions of
+// all sessions. Defaults to an in-memory store.
+func WithSubscriptionStore(store SubscriptionStore) Option {
+	return func(s *Server) {
+		s.subscriptionStore = store
+	}
+}
+
+// SubscriptionStore returns the store used for resource subscriptions.
+func (s *Server) SubscriptionStore() SubscriptionStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subscriptionStore
+}
+
+// WithSessionStore sets the store used to persist session state.
+// By default session state is not persisted.
+func WithSessionStore(store SessionStore) Option {
+	return func(s *Server) {
+		s.sessionStore = store
+	}
+}
+
+// SessionStore returns the store used to persist session state, or nil.
+func (s *Server) SessionStore() SessionStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sessionStore
+}
+
+// MemoryStore is an in-memory SubscriptionStore, SessionStore and
+// TaskStore. It also implements transport.SessionStore, so it can record
+// the sessions issued by the HTTP transport. State is lost when the
+// process exits.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[string]struct{} // URI -> set of session IDs
+	sessions      map[string]SessionState
+	tasks         map[string]TaskInfo
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscriptions: make(map[string]map[string]struct{}),
+		sessions:      make(map[string]SessionState),
+		tasks:         make(map[string]TaskInfo),
+	}
+}
+
+// Subscribe adds a subscription.
+func (m *MemoryStore) Subscribe(ctx context.Context, sessionID, uri string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.subscriptions[uri] == nil {
+		m.subscriptions[uri] = make(map[string]struct{})
+	}
+	m.subscriptions[uri][sessionID] = struct{}{}
+	return nil
+}
+
+// Unsubscribe removes a subscription.
+func (m *MemoryStore) Unsubscribe(ctx context.Context, sessionID, uri string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sessions, ok := m.subscriptions[uri]; ok {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(m.subscriptions, uri)
+		}
+	}
+	return nil
+}
+
+// UnsubscribeAll removes all subscriptions of a session.
+func (m *MemoryStore) UnsubscribeAll(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for uri, sessions := range m.subscriptions {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(m.subscriptions, uri)
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the sessions subscribed to uri, sorted.
+func (m *MemoryStore) Subscribers(ctx context.Context, uri string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions, ok := m.subscriptions[uri]
+	if !ok {
+		return nil, nil
+	}
+
+	result := make([]string, 0, len(sessions))
+	for id := range sessions {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Count returns the total number of subscriptions.
+func (m *MemoryStore) Count(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, sessions := range m.subscriptions {
+		count += len(sessions)
+	}
+	return count, nil
+}
+
+// SaveSession stores the state of a session.
+func (m *MemoryStore) SaveSession(ctx context.Context, state SessionState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[state.ID] = cloneSessionState(state)
+	return nil
+}
+
+// LoadSession returns the state of a session.
+func (m *MemoryStore) LoadSession(ctx context.Context, id string) (SessionState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.sessions[id]
+	if !ok {
+		return SessionState{}, ErrSessionNotFound
+	}
+	return cloneSessionState(state), nil
+}
+
+// CreateSession records a new session with empty state, keeping the state
+// of an existing session.
+func (m *MemoryStore) CreateSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		m.sessions[id] = SessionState{ID: id}
+	}
+	return nil
+}
+
+// SessionExists reports whether state is stored for a session.
+func (m *MemoryStore) SessionExists(ctx context.Context, id string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.sessions[id]
+	return ok, nil
+}
+
+// DeleteSession removes the state of a session.
+func (m *MemoryStore) DeleteSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// cloneSessionState copies the slices and pointers of state, so stored
+// state cannot be modified by callers.
+func cloneSessionState(state SessionState) SessionState {
+	if state.Roots != nil {
+		state.Roots = append([]Root(nil), state.Roots...)
+	}
+	if state.ClientCapabilities.Roots != nil {
+		roots := *state.ClientCapabilities.Roots
+		state.ClientCapabilities.Roots = &roots
+	}
+	if state.ClientInfo != nil {
+		info := *state.ClientInfo
+		if info.Capabilities.Roots != nil {
+			roots := *info.Capabilities.Roots
+			info.Capabilities.Roots = &roots
+		}
+		state.ClientInfo = &info
+	}
+	return state
+}
+
+// SaveTask stores the state of a task.
+func (m *MemoryStore) SaveTask(ctx context.Context, task TaskInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[task.ID] = task
+	return nil
+}
+
+// LoadTask returns the state of a task.
+func (m *MemoryStore) LoadTask(ctx context.Context, id string) (TaskInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return TaskInfo{}, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// DeleteTask removes a task.
+func (m *MemoryStore) DeleteTask(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tasks, id)
+	return nil
+}
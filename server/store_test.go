@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFileStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if err := store.Subscribe(ctx, "s1", "file:///a"); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := store.SaveSession(ctx, SessionState{ID: "s1", LogLevel: LogLevelError}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error = %v", err)
+	}
+
+	subscribers, _ := reopened.Subscribers(ctx, "file:///a")
+	if !slices.Equal(subscribers, []string{"s1"}) {
+		t.Errorf("Subscribers() = %v, want [s1]", subscribers)
+	}
+	state, err := reopened.LoadSession(ctx, "s1")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if state.LogLevel != LogLevelError {
+		t.Errorf("LogLevel = %q, want %q", state.LogLevel, LogLevelError)
+	}
+}
+
+func TestNewFileStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("expected error for invalid store file")
+	}
+}
+
+func TestMemoryStore_LoadReturnsCopy(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	_ = store.SaveSession(ctx, SessionState{ID: "s1", Roots: []Root{{URI: "file:///a"}}})
+
+	state, _ := store.LoadSession(ctx, "s1")
+	state.Roots[0].URI = "file:///changed"
+
+	again, _ := store.LoadSession(ctx, "s1")
+	if again.Roots[0].URI != "file:///a" {
+		t.Errorf("stored state was modified: %v", again.Roots)
+	}
+}
+
+func TestSession_PersistsState(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	session := NewSession("s1", &mockRequestSender{}, &mockNotificationSender{},
+		WithSessionStateStore(store),
+		WithClientCapabilities(ClientCapabilities{Sampling: true}),
+	)
+	session.SetLogLevel(LogLevelError)
+	session.HandleRootsChanged([]Root{{URI: "file:///workspace"}})
+
+	state, err := store.LoadSession(ctx, "s1")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if state.LogLevel != LogLevelError || !state.ClientCapabilities.Sampling || len(state.Roots) != 1 {
+		t.Errorf("stored state = %+v", state)
+	}
+
+	// A new session with the same ID is restored from the store
+	restored := NewSession("s1", &mockRequestSender{}, &mockNotificationSender{}, WithSessionStateStore(store))
+	if restored.LogLevel() != LogLevelError {
+		t.Errorf("restored LogLevel = %q, want %q", restored.LogLevel(), LogLevelError)
+	}
+	if !restored.SupportsFeature("sampling") {
+		t.Error("expected restored sampling capability")
+	}
+	if len(restored.Roots()) != 1 {
+		t.Errorf("restored roots = %v", restored.Roots())
+	}
+
+	if err := restored.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := store.LoadSession(ctx, "s1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("LoadSession() after Close error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSession_SharedSubscriptionStore(t *testing.T) {
+	store := NewMemoryStore()
+	s1 := NewSession("s1", &mockRequestSender{}, &mockNotificationSender{}, WithSessionSubscriptions(store))
+	s2 := NewSession("s2", &mockRequestSender{}, &mockNotificationSender{}, WithSessionSubscriptions(store))
+
+	_ = s1.Subscribe("file:///a")
+	_ = s2.Subscribe("file:///a")
+
+	subscribers := s1.SubscriptionManager().Subscribers("file:///a")
+	if !slices.Equal(subscribers, []string{"s1", "s2"}) {
+		t.Errorf("Subscribers() = %v, want [s1 s2]", subscribers)
+	}
+
+	_ = s1.Close(context.Background())
+	if s2.SubscriptionManager().IsSubscribed("s1", "file:///a") {
+		t.Error("expected s1 subscriptions to be removed on Close")
+	}
+}
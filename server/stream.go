@@ -0,0 +1,242 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// streamChunkSize is the size of the chunks a streamed resource is read in.
+const streamChunkSize = 32 << 10
+
+// ResourceStreamHandler opens a resource for streaming, returning its body
+// and MIME type. An empty MIME type is detected from the URI and the first
+// bytes of the body. The server closes the body.
+type ResourceStreamHandler func(ctx context.Context, uri string) (io.ReadCloser, string, error)
+
+// WithStreamBufferLimit limits the data buffered when a streaming resource
+// is read by a transport that cannot stream, such as stdio; longer bodies
+// fail with ErrResourceTooLarge. Defaults to 10 MB.
+func WithStreamBufferLimit(n int64) Option {
+	return func(s *Server) {
+		s.streamBufferLimit = n
+	}
+}
+
+// StreamBufferLimit returns the limit on buffered streaming resources.
+func (s *Server) StreamBufferLimit() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.streamBufferLimit <= 0 {
+		return defaultMaxResourceSize
+	}
+	return s.streamBufferLimit
+}
+
+// StreamHandler sets a streaming handler for resources too large to hold
+// in memory, such as log files and exports. Over HTTP, the body is encoded
+// into the response as it is read. Other transports and in-process reads
+// buffer it, up to the limit set with WithStreamBufferLimit. Text MIME
+// types are sent as text, with invalid UTF-8 replaced, and other types as
+// base64 blobs.
+//
+// Example:
+//
+//	srv.Resource("logs://{name}").
+//	    MimeType("text/plain").
+//	    StreamHandler(func(ctx context.Context, uri string) (io.ReadCloser, string, error) {
+//	        f, err := os.Open(filepath.Join(logDir, path.Base(uri)))
+//	        return f, "text/plain", err
+//	    })
+func (b *ResourceBuilder) StreamHandler(fn ResourceStreamHandler) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.resource.stream = fn
+	srv := b.server
+	return b.Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		body, mimeType, err := fn(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return NewReaderContent(uri, mimeType, body, srv.StreamBufferLimit())
+	})
+}
+
+// StreamResource opens the resource at uri for streaming if it has a
+// streaming handler and is not cached. It reports false, without error,
+// for other resources, which are read with ReadResource. The returned
+// result holds the open body; encoding it closes the body.
+func (s *Server) StreamResource(ctx context.Context, uri string) (protocol.StreamingResult, bool, error) {
+	resource, ok := s.FindResourceForURI(uri)
+	if !ok || resource.stream == nil || resource.cached {
+		return nil, false, nil
+	}
+	canonical, _, ok := resource.match(uri)
+	if !ok {
+		return nil, false, nil
+	}
+
+	body, mimeType, err := resource.stream(ctx, canonical)
+	if err != nil {
+		return nil, true, err
+	}
+	return &streamResult{
+		uri:      canonical,
+		mimeType: mimeType,
+		body:     body,
+		limit:    s.StreamBufferLimit(),
+		meta:     ResultMetaFromContext(ctx),
+	}, true, nil
+}
+
+// streamResult is a resources/read result encoded while its body is read.
+// MarshalJSON buffers the body, up to limit, for transports that cannot
+// stream; WriteJSON then writes the buffered encoding.
+type streamResult struct {
+	uri      string
+	mimeType string
+	body     io.ReadCloser
+	limit    int64
+	meta     map[string]any
+
+	mu       sync.Mutex
+	consumed bool
+	encoded  []byte
+	err      error
+}
+
+// MarshalJSON reads the whole body and returns the encoded result.
+func (r *streamResult) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.consumed {
+		return r.encoded, r.err
+	}
+	r.consumed = true
+	defer r.body.Close()
+
+	content, err := NewReaderContent(r.uri, r.mimeType, r.body, r.limit)
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	r.encoded, r.err = json.Marshal(protocol.ReadResourceResult{
+		Contents: []protocol.ResourceContents{{
+			URI:      content.URI,
+			MimeType: content.MimeType,
+			Text:     content.Text,
+			Blob:     content.Blob,
+		}},
+		Meta: r.meta,
+	})
+	return r.encoded, r.err
+}
+
+// WriteJSON encodes the result to w as the body is read.
+func (r *streamResult) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.consumed {
+		if r.err != nil {
+			return r.err
+		}
+		_, err := w.Write(r.encoded)
+		return err
+	}
+	r.consumed = true
+	defer r.body.Close()
+
+	body := bufio.NewReaderSize(r.body, streamChunkSize)
+	mimeType := r.mimeType
+	if mimeType == "" {
+		head, _ := body.Peek(512)
+		mimeType = DetectMimeType(r.uri, head)
+	}
+	uri, _ := json.Marshal(r.uri)
+	mime, _ := json.Marshal(mimeType)
+
+	field := "blob"
+	if isTextMimeType(mimeType) {
+		field = "text"
+	}
+	if _, err := io.WriteString(w, `{"contents":[{"uri":`+string(uri)+`,"mimeType":`+string(mime)+`,"`+field+`":"`); err != nil {
+		return err
+	}
+
+	var err error
+	if field == "text" {
+		err = writeJSONText(w, body)
+	} else {
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		if _, err = io.Copy(enc, body); err == nil {
+			err = enc.Close()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	tail := `"}]`
+	if len(r.meta) > 0 {
+		meta, err := json.Marshal(r.meta)
+		if err != nil {
+			return err
+		}
+		tail += `,"_meta":` + string(meta)
+	}
+	_, err = io.WriteString(w, tail+"}")
+	return err
+}
+
+// writeJSONText writes the text read from r as the contents of a JSON
+// string, holding back runes split across chunks.
+func writeJSONText(w io.Writer, r io.Reader) error {
+	buf := make([]byte, streamChunkSize+utf8.UTFMax)
+	pending := 0
+	for {
+		n, readErr := r.Read(buf[pending : pending+streamChunkSize])
+		n += pending
+		cut := n
+		if readErr == nil {
+			cut = fullRunes(buf[:n])
+		}
+		if cut > 0 {
+			enc, err := json.Marshal(string(buf[:cut]))
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(enc[1 : len(enc)-1]); err != nil {
+				return err
+			}
+		}
+		pending = copy(buf, buf[cut:n])
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// fullRunes returns the length of the longest prefix of p that does not
+// end in an incomplete UTF-8 sequence.
+func fullRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// trackedBody is a stream body recording whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func streamServer(body func() io.Reader, mimeType string, opts ...Option) (*Server, *[]*trackedBody) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, opts...)
+	var bodies []*trackedBody
+	srv.Resource("logs://{name}").StreamHandler(func(ctx context.Context, uri string) (io.ReadCloser, string, error) {
+		if strings.HasSuffix(uri, "/missing") {
+			return nil, "", protocol.NewNotFound("no such log")
+		}
+		b := &trackedBody{Reader: body()}
+		bodies = append(bodies, b)
+		return b, mimeType, nil
+	})
+	return srv, &bodies
+}
+
+func TestResourceBuilder_StreamHandler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("buffered read", func(t *testing.T) {
+		srv, bodies := streamServer(func() io.Reader { return strings.NewReader("line 1\nline 2\n") }, "text/plain")
+		content, err := srv.ReadResource(ctx, "logs://app")
+		if err != nil {
+			t.Fatalf("ReadResource() error = %v", err)
+		}
+		if content.Text != "line 1\nline 2\n" || content.MimeType != "text/plain" {
+			t.Errorf("content = %+v", content)
+		}
+		if !(*bodies)[0].closed {
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("buffer limit", func(t *testing.T) {
+		srv, _ := streamServer(func() io.Reader { return strings.NewReader(strings.Repeat("x", 64)) }, "text/plain", WithStreamBufferLimit(32))
+		if _, err := srv.ReadResource(ctx, "logs://app"); !errors.Is(err, ErrResourceTooLarge) {
+			t.Errorf("ReadResource() error = %v, want ErrResourceTooLarge", err)
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		srv, _ := streamServer(func() io.Reader { return strings.NewReader("") }, "text/plain")
+		if _, err := srv.ReadResource(ctx, "logs://missing"); !errors.Is(err, &protocol.Error{Code: protocol.CodeNotFound}) {
+			t.Errorf("ReadResource() error = %v, want not found", err)
+		}
+	})
+
+	t.Run("default buffer limit", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		if got := srv.StreamBufferLimit(); got != defaultMaxResourceSize {
+			t.Errorf("StreamBufferLimit() = %d, want %d", got, defaultMaxResourceSize)
+		}
+	})
+}
+
+func TestServer_StreamResource(t *testing.T) {
+	ctx := context.Background()
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00\xff", 40<<10)
+
+	tests := []struct {
+		name     string
+		body     string
+		reader   func(string) io.Reader
+		mimeType string
+		wantMime string
+		wantText bool
+	}{
+		{name: "text", body: "hello <world> \"quoted\"\n", mimeType: "text/plain", wantMime: "text/plain", wantText: true},
+		{name: "runes split across reads", body: "grüße 🌍 " + strings.Repeat("é", streamChunkSize), reader: func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) }, mimeType: "text/plain", wantMime: "text/plain", wantText: true},
+		{name: "one byte reads", body: "naïve ✓", reader: func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) }, mimeType: "text/markdown", wantMime: "text/markdown", wantText: true},
+		{name: "binary", body: png, mimeType: "image/png", wantMime: "image/png"},
+		{name: "detected type", body: png, wantMime: "image/png"},
+		{name: "empty", body: "", mimeType: "text/plain", wantMime: "text/plain", wantText: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := func() io.Reader { return strings.NewReader(tt.body) }
+			if tt.reader != nil {
+				reader = func() io.Reader { return tt.reader(tt.body) }
+			}
+			srv, bodies := streamServer(reader, tt.mimeType)
+
+			result, ok, err := srv.StreamResource(ctx, "logs://app")
+			if err != nil || !ok {
+				t.Fatalf("StreamResource() = %v, %v", ok, err)
+			}
+			var buf bytes.Buffer
+			if err := result.WriteJSON(&buf); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+			if !(*bodies)[0].closed {
+				t.Error("body not closed")
+			}
+
+			var decoded protocol.ReadResourceResult
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+				t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+			}
+			got := decoded.Contents[0]
+			if got.URI != "logs://app" || got.MimeType != tt.wantMime {
+				t.Errorf("contents = %s (%s), want logs://app (%s)", got.URI, got.MimeType, tt.wantMime)
+			}
+			if tt.wantText {
+				if got.Text != tt.body {
+					t.Errorf("Text = %q, want %q", got.Text, tt.body)
+				}
+				return
+			}
+			data, err := base64.StdEncoding.DecodeString(got.Blob)
+			if err != nil || string(data) != tt.body {
+				t.Errorf("Blob decodes to %d bytes (%v), want the %d byte body", len(data), err, len(tt.body))
+			}
+		})
+	}
+}
+
+func TestServer_StreamResource_Fallbacks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("regular resource", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Resource("config://app").Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri, Text: "ok"}, nil
+		})
+		if _, ok, err := srv.StreamResource(ctx, "config://app"); ok || err != nil {
+			t.Errorf("StreamResource() = %v, %v, want not streamed", ok, err)
+		}
+		if _, ok, err := srv.StreamResource(ctx, "unknown://x"); ok || err != nil {
+			t.Errorf("StreamResource() of unknown URI = %v, %v, want not streamed", ok, err)
+		}
+	})
+
+	t.Run("cached resource", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Resource("logs://app").Cache(time.Minute).StreamHandler(func(ctx context.Context, uri string) (io.ReadCloser, string, error) {
+			return io.NopCloser(strings.NewReader("ok")), "text/plain", nil
+		})
+		if _, ok, _ := srv.StreamResource(ctx, "logs://app"); ok {
+			t.Error("StreamResource() streamed a cached resource")
+		}
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		srv, _ := streamServer(func() io.Reader { return strings.NewReader("") }, "text/plain")
+		if _, ok, err := srv.StreamResource(ctx, "logs://missing"); !ok || !errors.Is(err, &protocol.Error{Code: protocol.CodeNotFound}) {
+			t.Errorf("StreamResource() = %v, %v, want not found", ok, err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		srv, _ := streamServer(func() io.Reader { return iotest.ErrReader(errors.New("disk failure")) }, "text/plain")
+		result, _, _ := srv.StreamResource(ctx, "logs://app")
+		if err := result.WriteJSON(io.Discard); err == nil {
+			t.Error("WriteJSON() error = nil, want the read error")
+		}
+	})
+}
+
+func TestStreamResult_MarshalJSON(t *testing.T) {
+	ctx := ContextWithResultMeta(context.Background())
+	srv, bodies := streamServer(func() io.Reader { return strings.NewReader("hello") }, "text/plain")
+	WithResultMeta(ctx, "source", "disk")
+
+	result, _, err := srv.StreamResource(ctx, "logs://app")
+	if err != nil {
+		t.Fatalf("StreamResource() error = %v", err)
+	}
+	marshaled, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !(*bodies)[0].closed {
+		t.Error("body not closed")
+	}
+
+	// WriteJSON after MarshalJSON writes the buffered encoding
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if buf.String() != string(marshaled) {
+		t.Errorf("WriteJSON() = %s, want %s", buf.String(), marshaled)
+	}
+
+	var decoded protocol.ReadResourceResult
+	if err := json.Unmarshal(marshaled, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Contents[0].Text != "hello" || decoded.Meta["source"] != "disk" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	t.Run("streamed meta", func(t *testing.T) {
+		result, _, _ := srv.StreamResource(ctx, "logs://app")
+		var buf bytes.Buffer
+		if err := result.WriteJSON(&buf); err != nil {
+			t.Fatalf("WriteJSON() error = %v", err)
+		}
+		var decoded protocol.ReadResourceResult
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Meta["source"] != "disk" {
+			t.Errorf("decoded = %+v, %v, want the result meta", decoded, err)
+		}
+	})
+
+	t.Run("over the buffer limit", func(t *testing.T) {
+		srv, _ := streamServer(func() io.Reader { return strings.NewReader(strings.Repeat("x", 64)) }, "text/plain", WithStreamBufferLimit(32))
+		result, _, _ := srv.StreamResource(context.Background(), "logs://app")
+		if _, err := json.Marshal(result); !errors.Is(err, ErrResourceTooLarge) {
+			t.Errorf("Marshal() error = %v, want ErrResourceTooLarge", err)
+		}
+	})
+}
@@ -1,7 +1,8 @@
 package server
 
 import (
-	"sync"
+	"context"
+	"slices"
 )
 
 // SubscribeRequest is sent by the client to subscribe to resource updates.
@@ -19,100 +20,90 @@ type ResourceUpdatedNotification struct {
 	URI string `json:"uri"`
 }
 
-// SubscriptionManager tracks resource subscriptions.
+// SubscriptionManager tracks resource subscriptions. It keeps them in a
+// SubscriptionStore, in memory by default.
 type SubscriptionManager struct {
-	mu            sync.RWMutex
-	subscriptions map[string]map[string]struct{} // URI -> set of client IDs
+	store SubscriptionStore
 }
 
-// NewSubscriptionManager creates a new subscription manager.
+// NewSubscriptionManager creates a subscription manager backed by an
+// in-memory store.
 func NewSubscriptionManager() *SubscriptionManager {
-	return &SubscriptionManager{
-		subscriptions: make(map[string]map[string]struct{}),
-	}
+	return NewSubscriptionManagerWithStore(NewMemoryStore())
 }
 
-// Subscribe adds a client subscription for a resource URI.
-func (m *SubscriptionManager) Subscribe(clientID, uri string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// NewSubscriptionManagerWithStore creates a subscription manager backed by store.
+func NewSubscriptionManagerWithStore(store SubscriptionStore) *SubscriptionManager {
+	return &SubscriptionManager{store: store}
+}
 
-	if m.subscriptions[uri] == nil {
-		m.subscriptions[uri] = make(map[string]struct{})
-	}
-	m.subscriptions[uri][clientID] = struct{}{}
+// Store returns the store holding the subscriptions.
+func (m *SubscriptionManager) Store() SubscriptionStore {
+	return m.store
+}
+
+// Subscribe adds a client subscription for a resource URI.
+func (m *SubscriptionManager) Subscribe(clientID, uri string) error {
+	return m.store.Subscribe(context.Background(), clientID, uri)
 }
 
 // Unsubscribe removes a client subscription for a resource URI.
-func (m *SubscriptionManager) Unsubscribe(clientID, uri string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if clients, ok := m.subscriptions[uri]; ok {
-		delete(clients, clientID)
-		if len(clients) == 0 {
-			delete(m.subscriptions, uri)
-		}
-	}
+func (m *SubscriptionManager) Unsubscribe(clientID, uri string) error {
+	return m.store.Unsubscribe(context.Background(), clientID, uri)
 }
 
 // UnsubscribeAll removes all subscriptions for a client.
-func (m *SubscriptionManager) UnsubscribeAll(clientID string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	for uri, clients := range m.subscriptions {
-		delete(clients, clientID)
-		if len(clients) == 0 {
-			delete(m.subscriptions, uri)
-		}
-	}
+func (m *SubscriptionManager) UnsubscribeAll(clientID string) error {
+	return m.store.UnsubscribeAll(context.Background(), clientID)
 }
 
 // Subscribers returns the client IDs subscribed to a resource URI.
+// It returns nil if the store fails.
 func (m *SubscriptionManager) Subscribers(uri string) []string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	clients, ok := m.subscriptions[uri]
-	if !ok {
+	subscribers, err := m.store.Subscribers(context.Background(), uri)
+	if err != nil {
 		return nil
 	}
-
-	result := make([]string, 0, len(clients))
-	for clientID := range clients {
-		result = append(result, clientID)
-	}
-	return result
+	return subscribers
 }
 
 // HasSubscribers returns true if the resource URI has any subscribers.
 func (m *SubscriptionManager) HasSubscribers(uri string) bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return len(m.subscriptions[uri]) > 0
+	return len(m.Subscribers(uri)) > 0
 }
 
 // IsSubscribed returns true if the client is subscribed to the resource URI.
 func (m *SubscriptionManager) IsSubscribed(clientID, uri string) bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if clients, ok := m.subscriptions[uri]; ok {
-		_, subscribed := clients[clientID]
-		return subscribed
-	}
-	return false
+	return slices.Contains(m.Subscribers(uri), clientID)
 }
 
 // SubscriptionCount returns the total number of subscriptions.
+// It returns 0 if the store fails.
 func (m *SubscriptionManager) SubscriptionCount() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	count := 0
-	for _, clients := range m.subscriptions {
-		count += len(clients)
+	count, err := m.store.Count(context.Background())
+	if err != nil {
+		return 0
 	}
 	return count
 }
+
+// NotifyResourceUpdated tells the sessions subscribed to uri that the
+// resource changed. Every connection served by the server delivers a
+// notifications/resources/updated to its subscribed session. It also drops
+// the cached read of uri, if any.
+//
+// Pair it with WatchResources to forward provider changes:
+//
+//	go srv.WatchResources(ctx, srv.NotifyResourceUpdated)
+func (s *Server) NotifyResourceUpdated(uri string) {
+	canonical := CanonicalURI(uri, s.TrailingSlashPolicy())
+	_ = s.ResourceCache().Delete(context.Background(), canonical)
+	s.resourceUpdated.emit(canonical)
+}
+
+// OnResourceUpdated registers fn to be called with the canonical URI
+// passed to NotifyResourceUpdated. Request handlers use it to deliver
+// notifications to their sessions. It returns a function that removes fn.
+func (s *Server) OnResourceUpdated(fn func(uri string)) (remove func()) {
+	return s.resourceUpdated.add(fn)
+}
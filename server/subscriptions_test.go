@@ -182,3 +182,22 @@ func TestResourceUpdatedNotification(t *testing.T) {
 		t.Errorf("expected URI 'file:///config.json', got %q", notification.URI)
 	}
 }
+
+func TestServer_NotifyResourceUpdated(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	var first, second []string
+	srv.OnResourceUpdated(func(uri string) { first = append(first, uri) })
+	remove := srv.OnResourceUpdated(func(uri string) { second = append(second, uri) })
+
+	srv.NotifyResourceUpdated("FILE:///config.json")
+	remove()
+	srv.NotifyResourceUpdated("file:///data/")
+
+	if len(first) != 2 || first[0] != "file:///config.json" || first[1] != "file:///data" {
+		t.Errorf("first listener got %v", first)
+	}
+	if len(second) != 1 {
+		t.Errorf("removed listener got %v, want one update", second)
+	}
+}
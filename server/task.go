@@ -0,0 +1,457 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// TaskURITemplate is the URI template of the resource exposing the state
+// of each task.
+const TaskURITemplate = "tasks://{id}"
+
+// defaultTaskRetention is how long finished tasks are kept by default.
+const defaultTaskRetention = time.Hour
+
+// ErrTaskNotFound is returned by TaskStore.LoadTask when no task has the
+// given ID.
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskStatus is the state of a task.
+type TaskStatus string
+
+// Task states. A task is working until it completes, fails or is
+// cancelled.
+const (
+	TaskWorking   TaskStatus = "working"
+	TaskCompleted TaskStatus = "completed"
+	TaskFailed    TaskStatus = "failed"
+	TaskCancelled TaskStatus = "cancelled"
+)
+
+// Done reports whether a task in this state has finished.
+func (s TaskStatus) Done() bool {
+	return s != TaskWorking
+}
+
+// TaskInfo is the state of a task, as read from its tasks://{id} resource.
+type TaskInfo struct {
+	ID     string     `json:"id"`
+	Tool   string     `json:"tool"`
+	Status TaskStatus `json:"status"`
+
+	// Progress, Total and Message are the latest progress the handler
+	// reported.
+	Progress float64  `json:"progress,omitempty"`
+	Total    *float64 `json:"total,omitempty"`
+	Message  string   `json:"message,omitempty"`
+
+	// Result is the tools/call result of a completed task.
+	Result *protocol.CallToolResult `json:"result,omitempty"`
+	// Error is the error of a failed task.
+	Error string `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// URI returns the URI of the resource exposing the task.
+func (t TaskInfo) URI() string {
+	return "tasks://" + t.ID
+}
+
+// TaskStore persists the state of tasks, so it can be read after a restart
+// or from another server instance. MemoryStore and FileStore implement it.
+//
+// Implementations must be safe for concurrent use. Use
+// testutil.RunTaskStoreContract to verify an implementation.
+type TaskStore interface {
+	// SaveTask creates or replaces the state of a task.
+	SaveTask(ctx context.Context, task TaskInfo) error
+	// LoadTask returns the state of a task, or ErrTaskNotFound.
+	LoadTask(ctx context.Context, id string) (TaskInfo, error)
+	// DeleteTask removes a task. Deleting a missing task is not an error.
+	DeleteTask(ctx context.Context, id string) error
+}
+
+// WithTaskStore sets the store tasks are saved to. Defaults to an
+// in-memory store.
+func WithTaskStore(store TaskStore) Option {
+	return func(s *Server) {
+		s.taskStore = store
+	}
+}
+
+// TaskStore returns the store tasks are saved to.
+func (s *Server) TaskStore() TaskStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taskStore
+}
+
+// WithTaskRetention sets how long finished tasks are kept before they are
+// deleted from the task store. Zero or less keeps them until deleted from
+// the store directly. Defaults to one hour.
+func WithTaskRetention(d time.Duration) Option {
+	return func(s *Server) {
+		s.taskRetention = d
+	}
+}
+
+// TaskRetention returns how long finished tasks are kept.
+func (s *Server) TaskRetention() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taskRetention
+}
+
+// Task runs the tool as a long-running task. A call returns as soon as the
+// input is validated, with the task ID and a link to the tasks://{id}
+// resource, while the handler keeps running in the background. Clients
+// poll the resource, or subscribe to it to be notified when the task
+// finishes, to read its status, progress and result. Progress the handler
+// reports is recorded on the task; once the call has returned it is no
+// longer sent as progress notifications, even if the call had a progress
+// token.
+//
+// The task ends when the handler returns, or is cancelled with
+// Server.CancelTask or by a notifications/cancelled message for the call
+// that started it, sent on the same connection. Handlers can read their task ID with TaskIDFromContext.
+//
+// Example:
+//
+//	srv.Tool("reindex").
+//	    Description("Rebuild the search index").
+//	    Task().
+//	    Handler(func(ctx context.Context, in ReindexInput) (string, error) {
+//	        for i, shard := range shards {
+//	            if err := reindex(ctx, shard); err != nil {
+//	                return "", err
+//	            }
+//	            server.ProgressFromContext(ctx).Report(float64(i+1), &total)
+//	        }
+//	        return "done", nil
+//	    })
+func (b *ToolBuilder) Task() *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.tool.tasks = b.server.tasks
+	b.server.tasks.registerResource()
+	return b
+}
+
+// Task returns the state of a task.
+func (s *Server) Task(ctx context.Context, id string) (TaskInfo, error) {
+	return s.TaskStore().LoadTask(ctx, id)
+}
+
+// CancelTask cancels a task running on this server. It reports whether
+// the task was running.
+func (s *Server) CancelTask(id string) bool {
+	return s.tasks.cancel(id)
+}
+
+// CancelTaskRequest cancels the task started by the tools/call request
+// with the given JSON-RPC ID on connection connID, as requested by a
+// notifications/cancelled message. It reports whether a task was running.
+// Tasks started without a connection ID can only be cancelled by ID.
+func (s *Server) CancelTaskRequest(connID string, requestID json.RawMessage) bool {
+	if connID == "" {
+		return false
+	}
+	s.tasks.mu.Lock()
+	id, ok := s.tasks.requests[requestKey(connID, requestID)]
+	s.tasks.mu.Unlock()
+	return ok && s.tasks.cancel(id)
+}
+
+// requestIDKey is the context key for the request being handled.
+type requestIDKey struct{}
+
+// requestRef identifies a request by its connection and JSON-RPC ID.
+type requestRef struct {
+	connID string
+	id     json.RawMessage
+}
+
+// ContextWithRequestID returns a context carrying the JSON-RPC ID of the
+// request being handled and the ID of the connection it arrived on, which
+// lets a notifications/cancelled message from that connection cancel the
+// task a tools/call request started.
+func ContextWithRequestID(ctx context.Context, connID string, id json.RawMessage) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestRef{connID: connID, id: id})
+}
+
+// RequestIDFromContext returns the JSON-RPC ID of the request being
+// handled, or nil if none.
+func RequestIDFromContext(ctx context.Context) json.RawMessage {
+	ref, _ := ctx.Value(requestIDKey{}).(requestRef)
+	return ref.id
+}
+
+// taskIDKey is the context key for the ID of the running task.
+type taskIDKey struct{}
+
+// TaskIDFromContext returns the ID of the task a handler runs as, or ""
+// if the handler was not started as a task.
+func TaskIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(taskIDKey{}).(string)
+	return id
+}
+
+// requestKey identifies a request across connections.
+func requestKey(connID string, requestID json.RawMessage) string {
+	return connID + "\x00" + string(bytes.TrimSpace(requestID))
+}
+
+// taskRunner runs the task tools of a server.
+type taskRunner struct {
+	server       *Server
+	resourceOnce sync.Once
+
+	mu       sync.Mutex
+	running  map[string]context.CancelFunc // task ID -> cancel
+	requests map[string]string             // request key -> task ID
+}
+
+func newTaskRunner(s *Server) *taskRunner {
+	return &taskRunner{
+		server:   s,
+		running:  make(map[string]context.CancelFunc),
+		requests: make(map[string]string),
+	}
+}
+
+// registerResource registers the tasks://{id} resource.
+func (r *taskRunner) registerResource() {
+	r.resourceOnce.Do(func() {
+		r.server.Resource(TaskURITemplate).
+			Name("Task").
+			Description("Status, progress and result of a long-running tool call").
+			MimeType("application/json").
+			Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+				task, err := r.server.Task(ctx, params["id"])
+				if errors.Is(err, ErrTaskNotFound) {
+					return nil, protocol.NewNotFound("task not found: " + params["id"])
+				}
+				if err != nil {
+					return nil, err
+				}
+				data, err := json.Marshal(task)
+				if err != nil {
+					return nil, err
+				}
+				return &ResourceContent{URI: uri, MimeType: "application/json", Text: string(data)}, nil
+			})
+	})
+}
+
+// start starts a task running the tool with a decoded input and returns
+// the result announcing it.
+func (r *taskRunner) start(ctx context.Context, t *Tool, input reflect.Value) (*ToolResult, error) {
+	now := time.Now()
+	task := TaskInfo{
+		ID:        newTaskID(),
+		Tool:      t.name,
+		Status:    TaskWorking,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.server.TaskStore().SaveTask(ctx, task); err != nil {
+		return nil, fmt.Errorf("save task: %w", err)
+	}
+
+	// The task outlives the request but keeps its values, such as the
+	// session and the progress reporter
+	taskCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	taskCtx = context.WithValue(taskCtx, taskIDKey{}, task.ID)
+	taskCtx = ContextWithResultMeta(taskCtx)
+	progress := &taskProgress{
+		runner: r,
+		id:     task.ID,
+		next:   ProgressFromContext(ctx),
+	}
+	taskCtx = ContextWithProgress(taskCtx, progress)
+	// The call is answered when start returns; progress reported later has
+	// no request to belong to
+	defer progress.detach()
+
+	var key string
+	if ref, _ := ctx.Value(requestIDKey{}).(requestRef); ref.connID != "" && ref.id != nil {
+		key = requestKey(ref.connID, ref.id)
+	}
+
+	r.mu.Lock()
+	r.running[task.ID] = cancel
+	if key != "" {
+		r.requests[key] = task.ID
+	}
+	r.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := t.call(taskCtx, input)
+		r.finish(taskCtx, t, task.ID, key, result, err)
+	}()
+
+	announcement, _ := json.Marshal(map[string]any{
+		"taskId": task.ID,
+		"status": task.Status,
+	})
+	return NewToolResult().
+		Text(string(announcement)).
+		ResourceLink(task.URI(), "Task "+task.ID, "application/json"), nil
+}
+
+// finish records the outcome of a task.
+func (r *taskRunner) finish(ctx context.Context, t *Tool, id, key string, result any, err error) {
+	r.mu.Lock()
+	delete(r.running, id)
+	if key != "" {
+		delete(r.requests, key)
+	}
+	r.mu.Unlock()
+
+	store := r.server.TaskStore()
+	bg := context.WithoutCancel(ctx)
+	r.update(bg, id, func(task *TaskInfo) {
+		switch {
+		case ctx.Err() != nil:
+			task.Status = TaskCancelled
+		case err != nil:
+			task.Status = TaskFailed
+			task.Error = err.Error()
+			if toolErr, ok := AsToolError(err); ok {
+				task.Error = toolErr.Message
+			}
+		default:
+			callResult, err := taskResult(ctx, t, result)
+			if err != nil {
+				task.Status = TaskFailed
+				task.Error = err.Error()
+				return
+			}
+			task.Status = TaskCompleted
+			task.Result = callResult
+		}
+	})
+	r.server.NotifyResourceUpdated("tasks://" + id)
+
+	if retention := r.server.TaskRetention(); retention > 0 {
+		time.AfterFunc(retention, func() {
+			_ = store.DeleteTask(context.Background(), id)
+		})
+	}
+}
+
+// update applies change to the stored state of a task.
+func (r *taskRunner) update(ctx context.Context, id string, change func(task *TaskInfo)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	store := r.server.TaskStore()
+	task, err := store.LoadTask(ctx, id)
+	if err != nil {
+		return
+	}
+	change(&task)
+	task.UpdatedAt = time.Now()
+	_ = store.SaveTask(ctx, task)
+}
+
+// cancel cancels a running task.
+func (r *taskRunner) cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.running[id]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// taskResult converts the result of a task handler to a tools/call result.
+func taskResult(ctx context.Context, t *Tool, result any) (*protocol.CallToolResult, error) {
+	if rich, ok := result.(*ToolResult); ok && rich != nil {
+		if err := rich.Err(); err != nil {
+			return nil, err
+		}
+		callResult := rich.CallToolResult()
+		callResult.Meta = MergeResultMeta(ctx, callResult.Meta)
+		return &callResult, nil
+	}
+
+	text, ok := result.(string)
+	if !ok {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("encode tool result: %w", err)
+		}
+		text = string(data)
+	}
+	callResult := &protocol.CallToolResult{
+		Content: []protocol.Content{{Type: "text", Text: text}},
+		Meta:    ResultMetaFromContext(ctx),
+	}
+	if t.OutputSchema() != nil {
+		callResult.StructuredContent = result
+	}
+	return callResult, nil
+}
+
+// taskProgress records the progress of a task and forwards it to the
+// reporter of the call that started it until the call returns.
+type taskProgress struct {
+	runner *taskRunner
+	id     string
+
+	mu   sync.Mutex
+	next ProgressReporter
+}
+
+// detach stops forwarding progress to the reporter of the call.
+func (p *taskProgress) detach() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next = &noopProgressReporter{}
+}
+
+func (p *taskProgress) reporter() ProgressReporter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.next
+}
+
+func (p *taskProgress) Token() ProgressToken {
+	return p.reporter().Token()
+}
+
+func (p *taskProgress) Report(progress float64, total *float64) error {
+	return p.ReportWithMessage(progress, total, "")
+}
+
+func (p *taskProgress) ReportWithMessage(progress float64, total *float64, message string) error {
+	p.runner.update(context.Background(), p.id, func(task *TaskInfo) {
+		task.Progress = progress
+		task.Total = total
+		task.Message = message
+	})
+	return p.reporter().ReportWithMessage(progress, total, message)
+}
+
+// newTaskID returns a random task ID.
+func newTaskID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
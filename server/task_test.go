@@ -0,0 +1,341 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+type taskInput struct {
+	Steps int `json:"steps"`
+}
+
+// startTask calls a task tool and returns the ID of the task it started.
+func startTask(t *testing.T, s *Server, ctx context.Context, name, args string) string {
+	t.Helper()
+	tool, ok := s.GetTool(name)
+	if !ok {
+		t.Fatalf("tool %q not registered", name)
+	}
+	result, err := tool.Execute(ctx, json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	content := result.(*ToolResult).Content()
+	if len(content) != 2 || content[1].Type != "resource_link" {
+		t.Fatalf("content = %+v, want text and resource link", content)
+	}
+	var announcement struct {
+		TaskID string     `json:"taskId"`
+		Status TaskStatus `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(content[0].Text), &announcement); err != nil {
+		t.Fatalf("announcement %q: %v", content[0].Text, err)
+	}
+	if announcement.Status != TaskWorking || content[1].URI != "tasks://"+announcement.TaskID {
+		t.Fatalf("announcement = %+v, link %q", announcement, content[1].URI)
+	}
+	return announcement.TaskID
+}
+
+// waitForTask waits until a task has finished and returns its state.
+func waitForTask(t *testing.T, s *Server, id string) TaskInfo {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, err := s.Task(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Task() error = %v", err)
+		}
+		if task.Status.Done() {
+			return task
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s still %s", id, task.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestToolBuilder_Task(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"})
+	release := make(chan struct{})
+	progressed := make(chan struct{})
+	var gotTaskID string
+	s.Tool("reindex").Task().Handler(func(ctx context.Context, in taskInput) (string, error) {
+		gotTaskID = TaskIDFromContext(ctx)
+		total := float64(in.Steps)
+		ProgressFromContext(ctx).ReportWithMessage(1, &total, "shard 1")
+		close(progressed)
+		<-release
+		return "reindexed", nil
+	})
+
+	updated := make(chan string, 10)
+	s.OnResourceUpdated(func(uri string) { updated <- uri })
+
+	id := startTask(t, s, context.Background(), "reindex", `{"steps": 3}`)
+
+	<-progressed
+	task, err := s.Task(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Task() error = %v", err)
+	}
+	if task.Status != TaskWorking || task.Tool != "reindex" || task.Progress != 1 || *task.Total != 3 || task.Message != "shard 1" {
+		t.Errorf("running task = %+v", task)
+	}
+
+	close(release)
+	task = waitForTask(t, s, id)
+	if task.Status != TaskCompleted || task.Result == nil || task.Result.Content[0].Text != "reindexed" {
+		t.Errorf("finished task = %+v", task)
+	}
+	if gotTaskID != id {
+		t.Errorf("TaskIDFromContext() = %q, want %q", gotTaskID, id)
+	}
+	select {
+	case uri := <-updated:
+		if uri != "tasks://"+id {
+			t.Errorf("updated %q, want tasks://%s", uri, id)
+		}
+	case <-time.After(time.Second):
+		t.Error("no resource updated notification")
+	}
+
+	content, err := s.ReadResource(context.Background(), "tasks://"+id)
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var read TaskInfo
+	if err := json.Unmarshal([]byte(content.Text), &read); err != nil {
+		t.Fatalf("resource %q: %v", content.Text, err)
+	}
+	if read.ID != id || read.Status != TaskCompleted || content.MimeType != "application/json" {
+		t.Errorf("resource = %s", content.Text)
+	}
+
+	if _, err := s.ReadResource(context.Background(), "tasks://missing"); err == nil {
+		t.Error("ReadResource() of a missing task error = nil, want error")
+	}
+}
+
+// recordingProgress is a ProgressReporter that records reports.
+type recordingProgress struct {
+	mu      sync.Mutex
+	reports []float64
+}
+
+func (p *recordingProgress) Report(progress float64, total *float64) error {
+	return p.ReportWithMessage(progress, total, "")
+}
+
+func (p *recordingProgress) ReportWithMessage(progress float64, total *float64, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reports = append(p.reports, progress)
+	return nil
+}
+
+func (p *recordingProgress) Token() ProgressToken { return "token-1" }
+
+func TestToolBuilder_Task_ProgressAfterReturn(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"})
+	release := make(chan struct{})
+	var token ProgressToken
+	s.Tool("job").Task().Handler(func(ctx context.Context, in taskInput) (string, error) {
+		<-release
+		token = ProgressFromContext(ctx).Token()
+		ProgressFromContext(ctx).Report(1, nil)
+		return "done", nil
+	})
+
+	call := &recordingProgress{}
+	id := startTask(t, s, ContextWithProgress(context.Background(), call), "job", `{}`)
+	close(release)
+
+	if task := waitForTask(t, s, id); task.Progress != 1 {
+		t.Errorf("task progress = %v, want 1", task.Progress)
+	}
+	if len(call.reports) != 0 || token != "" {
+		t.Errorf("progress forwarded after the call returned: %v, token %q", call.reports, token)
+	}
+}
+
+func TestToolBuilder_Task_Outcomes(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   any
+		want      TaskStatus
+		wantError string
+		wantText  string
+	}{
+		{
+			name:      "error",
+			handler:   func(ctx context.Context, in taskInput) (string, error) { return "", errors.New("disk full") },
+			want:      TaskFailed,
+			wantError: "disk full",
+		},
+		{
+			name:      "tool error",
+			handler:   func(ctx context.Context, in taskInput) (string, error) { return "", NewToolError("no such index") },
+			want:      TaskFailed,
+			wantError: "no such index",
+		},
+		{
+			name: "structured result",
+			handler: func(ctx context.Context, in taskInput) (map[string]int, error) {
+				return map[string]int{"steps": in.Steps}, nil
+			},
+			want:     TaskCompleted,
+			wantText: `{"steps":2}`,
+		},
+		{
+			name: "rich result",
+			handler: func(ctx context.Context, in taskInput) (*ToolResult, error) {
+				return NewToolResult().Text("rich").WithError(), nil
+			},
+			want:     TaskCompleted,
+			wantText: "rich",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Info{Name: "test", Version: "1.0.0"})
+			s.Tool("job").Task().Handler(tt.handler)
+
+			task := waitForTask(t, s, startTask(t, s, context.Background(), "job", `{"steps": 2}`))
+			if task.Status != tt.want || task.Error != tt.wantError {
+				t.Errorf("task = %+v, want status %s, error %q", task, tt.want, tt.wantError)
+			}
+			if tt.wantText != "" && (task.Result == nil || task.Result.Content[0].Text != tt.wantText) {
+				t.Errorf("result = %+v, want text %q", task.Result, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestToolBuilder_Task_InvalidInput(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"})
+	s.Tool("job").Task().Handler(func(ctx context.Context, in taskInput) (string, error) {
+		t.Error("handler called with invalid input")
+		return "", nil
+	})
+
+	tool, _ := s.GetTool("job")
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"steps": "many"}`))
+	var protoErr *protocol.Error
+	if !errors.As(err, &protoErr) || protoErr.Code != protocol.CodeInvalidParams {
+		t.Errorf("Execute() error = %v, want InvalidParams", err)
+	}
+}
+
+func TestServer_CancelTask(t *testing.T) {
+	newServer := func() *Server {
+		s := New(Info{Name: "test", Version: "1.0.0"})
+		s.Tool("wait").Task().Handler(func(ctx context.Context, in taskInput) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+		return s
+	}
+
+	t.Run("by ID", func(t *testing.T) {
+		s := newServer()
+		id := startTask(t, s, context.Background(), "wait", `{}`)
+		if !s.CancelTask(id) {
+			t.Fatal("CancelTask() = false, want true")
+		}
+		if task := waitForTask(t, s, id); task.Status != TaskCancelled {
+			t.Errorf("status = %s, want cancelled", task.Status)
+		}
+		if s.CancelTask(id) {
+			t.Error("CancelTask() of a finished task = true, want false")
+		}
+	})
+
+	t.Run("by request", func(t *testing.T) {
+		s := newServer()
+		ctx := ContextWithRequestID(context.Background(), "conn-1", json.RawMessage(`7`))
+		id := startTask(t, s, ctx, "wait", `{}`)
+		if s.CancelTaskRequest("conn-1", json.RawMessage(`8`)) {
+			t.Error("CancelTaskRequest() of another request = true, want false")
+		}
+		if s.CancelTaskRequest("conn-2", json.RawMessage(`7`)) {
+			t.Error("CancelTaskRequest() from another connection = true, want false")
+		}
+		if !s.CancelTaskRequest("conn-1", json.RawMessage(` 7 `)) {
+			t.Fatal("CancelTaskRequest() = false, want true")
+		}
+		if task := waitForTask(t, s, id); task.Status != TaskCancelled {
+			t.Errorf("status = %s, want cancelled", task.Status)
+		}
+	})
+
+	t.Run("not by request without a connection", func(t *testing.T) {
+		s := newServer()
+		ctx := ContextWithRequestID(context.Background(), "", json.RawMessage(`7`))
+		id := startTask(t, s, ctx, "wait", `{}`)
+		if s.CancelTaskRequest("", json.RawMessage(`7`)) {
+			t.Error("CancelTaskRequest() without a connection = true, want false")
+		}
+		s.CancelTask(id)
+	})
+
+	t.Run("request cancellation does not cancel the task", func(t *testing.T) {
+		s := newServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		id := startTask(t, s, ctx, "wait", `{}`)
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		if task, _ := s.Task(context.Background(), id); task.Status != TaskWorking {
+			t.Errorf("status = %s, want working", task.Status)
+		}
+		s.CancelTask(id)
+	})
+}
+
+func TestWithTaskRetention(t *testing.T) {
+	s := New(Info{Name: "test", Version: "1.0.0"}, WithTaskRetention(10*time.Millisecond))
+	s.Tool("job").Task().Handler(func(in taskInput) (string, error) { return "ok", nil })
+
+	id := startTask(t, s, context.Background(), "job", `{}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := s.Task(context.Background(), id)
+		if errors.Is(err, ErrTaskNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task not deleted, Task() error = %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// failingTaskStore is a TaskStore whose saves fail.
+type failingTaskStore struct{ *MemoryStore }
+
+func (failingTaskStore) SaveTask(ctx context.Context, task TaskInfo) error {
+	return errors.New("store down")
+}
+
+func TestWithTaskStore(t *testing.T) {
+	store := NewMemoryStore()
+	s := New(Info{Name: "test", Version: "1.0.0"}, WithTaskStore(store))
+	if s.TaskStore() != store {
+		t.Error("TaskStore() is not the configured store")
+	}
+
+	failing := New(Info{Name: "test", Version: "1.0.0"}, WithTaskStore(failingTaskStore{NewMemoryStore()}))
+	failing.Tool("job").Task().Handler(func(in taskInput) (string, error) { return "ok", nil })
+	tool, _ := failing.GetTool("job")
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "store down") {
+		t.Errorf("Execute() error = %v, want store error", err)
+	}
+}
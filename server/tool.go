@@ -3,7 +3,9 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
@@ -12,15 +14,30 @@ import (
 
 // Tool represents a callable function exposed via MCP.
 type Tool struct {
-	name          string
-	description   string
-	inputType     reflect.Type
-	inputSchema   any
-	validatable   *schema.Schema
-	validateInput bool
-	handler       any
-	hasContext    bool
-	annotations   *ToolAnnotations
+	name         string
+	title        string
+	description  string
+	inputType    reflect.Type
+	convert      bool
+	inputSchema  any
+	validatable  *schema.Schema
+	validator    *schema.Validator
+	customSchema bool
+
+	outputSchema      any
+	outputValidatable *schema.Schema
+	validateOutput    bool
+	validateInput     bool
+	handler           any
+	hasContext        bool
+	annotations       *ToolAnnotations
+	hidden            bool
+	deprecated        string
+	icons             []Icon
+	tasks             *taskRunner
+
+	// Position in registration order
+	seq uint64
 }
 
 // ToolBuilder provides a fluent API for building tools.
@@ -39,9 +56,93 @@ func (b *ToolBuilder) Description(desc string) *ToolBuilder {
 	return b
 }
 
+// Hidden excludes the tool from tools/list outside debug mode. Hidden tools
+// can still be called by name, which suits internal and testing tools.
+func (b *ToolBuilder) Hidden() *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.tool.hidden = true
+	return b
+}
+
+// Deprecated marks the tool as deprecated with a message such as
+// "use search_v2". The tool keeps working; tools/list prefixes its
+// description with the message and sets "deprecated" in its _meta, so
+// clients and models can move to the replacement.
+func (b *ToolBuilder) Deprecated(message string) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.tool.deprecated = message
+	return b
+}
+
+// InputSchema sets the JSON Schema advertised for the tool input instead of
+// the one generated from the handler input type. schema may be a
+// *schema.Schema, a json.RawMessage or any value that encodes to a JSON
+// Schema. It is meant for tools whose input is described elsewhere, such as
+// tools proxied from another server, and is typically used with a handler
+// taking a json.RawMessage. ValidateInput checks the keywords the schema
+// package supports.
+func (b *ToolBuilder) InputSchema(s any) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	s, validatable, err := parseSchema(s)
+	if err != nil {
+		b.err = fmt.Errorf("invalid input schema: %w", err)
+		return b
+	}
+
+	b.tool.setInputSchema(s, validatable)
+	b.tool.customSchema = true
+	return b
+}
+
+// InputSchemaJSON sets the input schema from a JSON Schema document, such
+// as one maintained by hand or shared with other services, instead of
+// mirroring it as a Go struct. The document must describe an object. It is
+// advertised as given, and ValidateInput checks inputs against it; the
+// handler may still take a struct, which the arguments decode into.
+func (b *ToolBuilder) InputSchemaJSON(data []byte) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		b.err = fmt.Errorf("invalid input schema: %w", err)
+		return b
+	}
+	if doc["type"] != "object" {
+		b.err = fmt.Errorf(`invalid input schema: type is %v, want "object"`, doc["type"])
+		return b
+	}
+	return b.InputSchema(json.RawMessage(data))
+}
+
+// InputSchemaFile sets the input schema from the JSON Schema document in
+// the file at path, as InputSchemaJSON does.
+func (b *ToolBuilder) InputSchemaFile(path string) *ToolBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.err = fmt.Errorf("input schema: %w", err)
+		return b
+	}
+	return b.InputSchemaJSON(data)
+}
+
 // ValidateInput enables runtime schema validation of tool inputs.
 // When enabled, inputs are validated against the JSON Schema before
-// the handler is called. Invalid inputs result in an InvalidParams error.
+// the handler is called. Invalid inputs result in an InvalidParams error
+// whose data lists the invalid fields; see ValidationErrorData.
+// WithInputValidation enables it for every tool of a server.
 func (b *ToolBuilder) ValidateInput() *ToolBuilder {
 	if b.err != nil {
 		return b
@@ -69,6 +170,12 @@ func (b *ToolBuilder) Handler(fn any) *ToolBuilder {
 	return b
 }
 
+// Err returns the error that stopped the tool from being registered, such
+// as an invalid handler signature, or nil.
+func (b *ToolBuilder) Err() error {
+	return b.err
+}
+
 // validateHandler validates the handler function signature.
 func (b *ToolBuilder) validateHandler(fn any) error {
 	fnType := reflect.TypeOf(fn)
@@ -101,14 +208,16 @@ func (b *ToolBuilder) validateHandler(fn any) error {
 		inputType = inputType.Elem()
 	}
 	b.tool.inputType = inputType
+	b.tool.convert = needsConversion(inputType)
 
-	// Generate input schema
-	inputSchema, err := schema.GenerateFromType(inputType)
-	if err != nil {
-		return fmt.Errorf("failed to generate input schema: %w", err)
+	// Generate input schema, unless one was set with InputSchema
+	if !b.tool.customSchema {
+		inputSchema, err := generateInputSchema(inputType, b.server.schemaOpts...)
+		if err != nil {
+			return fmt.Errorf("failed to generate input schema: %w", err)
+		}
+		b.tool.setInputSchema(inputSchema, inputSchema)
 	}
-	b.tool.inputSchema = inputSchema
-	b.tool.validatable = inputSchema // Store for validation
 
 	// Check outputs
 	if fnType.NumOut() != 2 {
@@ -124,31 +233,130 @@ func (b *ToolBuilder) validateHandler(fn any) error {
 	return nil
 }
 
+// setInputSchema sets the advertised input schema and the schema inputs
+// are validated against, compiled for the validation of every call. A
+// schema that does not compile, such as one with an invalid pattern, is
+// validated uncompiled, which reports the problem per call.
+func (t *Tool) setInputSchema(s any, validatable *schema.Schema) {
+	t.inputSchema = s
+	t.validatable = validatable
+	t.validator, _ = validatable.Compile()
+}
+
+// parseSchema returns a schema given as a *schema.Schema, a json.RawMessage
+// or any value that encodes to a JSON Schema, in the form to advertise and
+// in the form to validate with.
+func parseSchema(s any) (any, *schema.Schema, error) {
+	if validatable, ok := s.(*schema.Schema); ok {
+		return s, validatable, nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	validatable := &schema.Schema{}
+	if err := json.Unmarshal(data, validatable); err != nil {
+		return nil, nil, err
+	}
+	if _, isRaw := s.(json.RawMessage); isRaw {
+		// Keep the schema as received rather than as raw bytes
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, nil, err
+		}
+		s = decoded
+	}
+	return s, validatable, nil
+}
+
+// ValidationErrorData is the data of the InvalidParams error returned for
+// tool arguments that fail schema validation.
+//
+// Example error:
+//
+//	{"code": -32602, "message": "input validation failed: ...",
+//	 "data": {"errors": [{"path": "user.email", "message": "required field is missing"}]}}
+type ValidationErrorData struct {
+	// Errors lists each invalid field with its path in the arguments.
+	Errors schema.ValidationErrors `json:"errors"`
+}
+
+// inputValidationError converts a schema validation error to an
+// InvalidParams error carrying the invalid fields as ValidationErrorData.
+func inputValidationError(err error) *protocol.Error {
+	return protocol.NewInvalidParams(fmt.Sprintf("input validation failed: %v", err)).WithData(validationErrorData(err))
+}
+
+// validationErrorData lists the invalid fields of a schema validation error.
+func validationErrorData(err error) ValidationErrorData {
+	data := ValidationErrorData{}
+	var list schema.ValidationErrors
+	var single *schema.ValidationError
+	switch {
+	case errors.As(err, &list):
+		data.Errors = list
+	case errors.As(err, &single):
+		data.Errors = schema.ValidationErrors{single}
+	}
+	return data
+}
+
+// rawMessageType is the type of handler inputs taken as raw JSON.
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// generateInputSchema generates the input schema of a handler input type.
+// Raw JSON inputs accept any object.
+func generateInputSchema(t reflect.Type, opts ...schema.GenerateOption) (*schema.Schema, error) {
+	if t == rawMessageType {
+		return &schema.Schema{Type: "object"}, nil
+	}
+	return schema.GenerateFromType(t, opts...)
+}
+
 // Execute runs the tool handler with the given JSON input.
 func (t *Tool) Execute(ctx context.Context, input json.RawMessage) (any, error) {
 	// Validate input against schema if enabled
 	if t.validateInput && t.validatable != nil {
-		if err := t.validatable.Validate(input); err != nil {
-			return nil, protocol.NewInvalidParams(fmt.Sprintf("input validation failed: %v", err))
+		var err error
+		if t.validator != nil {
+			err = t.validator.Validate(input)
+		} else {
+			err = t.validatable.Validate(input)
+		}
+		if err != nil {
+			return nil, inputValidationError(err)
 		}
 	}
 
-	// Create input value
-	inputPtr := reflect.New(t.inputType)
-	if err := json.Unmarshal(input, inputPtr.Interface()); err != nil {
+	in, err := decodeInput(input, t.inputType, t.convert)
+	if err != nil {
 		return nil, protocol.NewInvalidParams(fmt.Sprintf("failed to parse input: %v", err))
 	}
 
+	if t.tasks != nil {
+		return t.tasks.start(ctx, t, in)
+	}
+	return t.call(ctx, in)
+}
+
+// call runs the tool handler with a decoded input.
+func (t *Tool) call(ctx context.Context, input reflect.Value) (any, error) {
 	// Build arguments
 	fnVal := reflect.ValueOf(t.handler)
 	var args []reflect.Value
 
+	// Context-aware handlers get a transaction for compensation callbacks
+	var tx *Transaction
 	if t.hasContext {
+		tx = &Transaction{}
+		ctx = ContextWithTx(ctx, tx)
 		args = append(args, reflect.ValueOf(ctx))
+		defer tx.rollbackOnPanic(ctx)
 	}
 
 	// Use the value, not pointer, for the input
-	args = append(args, inputPtr.Elem())
+	args = append(args, input)
 
 	// Call handler
 	results := fnVal.Call(args)
@@ -157,8 +365,17 @@ func (t *Tool) Execute(ctx context.Context, input json.RawMessage) (any, error)
 	resultVal := results[0].Interface()
 	errVal := results[1].Interface()
 
+	var handlerErr error
 	if errVal != nil {
-		return nil, errVal.(error)
+		handlerErr = errVal.(error)
+	}
+
+	if tx != nil {
+		handlerErr = tx.finish(ctx, handlerErr)
+	}
+
+	if handlerErr != nil {
+		return nil, handlerErr
 	}
 
 	return resultVal, nil
@@ -2,8 +2,16 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/schema"
 )
 
 func TestToolBuilder(t *testing.T) {
@@ -161,3 +169,394 @@ func TestTool_Execute(t *testing.T) {
 		}
 	})
 }
+
+func TestToolBuilder_InputSchemaJSON(t *testing.T) {
+	doc := []byte(`{
+		"type": "object",
+		"properties": {
+			"user": {"$ref": "#/definitions/user"},
+			"limit": {"type": "integer", "maximum": 50}
+		},
+		"required": ["user"],
+		"definitions": {
+			"user": {"type": "object", "properties": {"id": {"type": "string", "minLength": 1}}, "required": ["id"]}
+		}
+	}`)
+	path := filepath.Join(t.TempDir(), "lookup.json")
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type lookupInput struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		Limit int `json:"limit"`
+	}
+	handler := func(in lookupInput) (string, error) { return in.User.ID, nil }
+
+	builders := map[string]func(b *ToolBuilder) *ToolBuilder{
+		"json": func(b *ToolBuilder) *ToolBuilder { return b.InputSchemaJSON(doc) },
+		"file": func(b *ToolBuilder) *ToolBuilder { return b.InputSchemaFile(path) },
+	}
+	for name, build := range builders {
+		t.Run(name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"})
+			b := build(srv.Tool("lookup")).ValidateInput().Handler(handler)
+			if err := b.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			advertised, _ := srv.Tools()[0].InputSchema.(map[string]any)
+			if _, ok := advertised["definitions"]; !ok {
+				t.Errorf("InputSchema = %v, want the document as given", advertised)
+			}
+
+			tool, _ := srv.getTool("lookup")
+			result, err := tool.Execute(context.Background(), []byte(`{"user":{"id":"u1"},"limit":10}`))
+			if err != nil || result != "u1" {
+				t.Fatalf("Execute() = %v, %v", result, err)
+			}
+			_, err = tool.Execute(context.Background(), []byte(`{"user":{"id":""},"limit":100}`))
+			if err == nil || !strings.Contains(err.Error(), "user.id: length 0 is less than minimum length 1") ||
+				!strings.Contains(err.Error(), "limit: value 100 is greater than maximum 50") {
+				t.Errorf("Execute() error = %v, want validation errors", err)
+			}
+		})
+	}
+
+	t.Run("schema that does not compile", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Tool("code").
+			InputSchemaJSON([]byte(`{"type":"object","properties":{"code":{"type":"string","pattern":"("}}}`)).
+			ValidateInput().
+			Handler(func(args json.RawMessage) (string, error) { return "", nil })
+		tool, _ := srv.getTool("code")
+		if tool.validator != nil {
+			t.Fatal("schema with an invalid pattern compiled")
+		}
+		_, err := tool.Execute(context.Background(), []byte(`{"code":"x"}`))
+		if err == nil || !strings.Contains(err.Error(), `code: invalid pattern "("`) {
+			t.Errorf("Execute() error = %v, want the invalid pattern reported", err)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		build   func(b *ToolBuilder) *ToolBuilder
+		wantErr string
+	}{
+		{"invalid JSON", func(b *ToolBuilder) *ToolBuilder { return b.InputSchemaJSON([]byte(`{"type":`)) }, "invalid input schema"},
+		{"not an object schema", func(b *ToolBuilder) *ToolBuilder { return b.InputSchemaJSON([]byte(`{"type":"string"}`)) }, `type is string, want "object"`},
+		{"missing file", func(b *ToolBuilder) *ToolBuilder { return b.InputSchemaFile(filepath.Join(t.TempDir(), "none.json")) }, "input schema: open"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"})
+			b := tt.build(srv.Tool("bad")).Handler(handler)
+			if err := b.Err(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Err() = %v, want %q", err, tt.wantErr)
+			}
+			if len(srv.Tools()) != 0 {
+				t.Error("tool with invalid schema was registered")
+			}
+		})
+	}
+}
+
+func TestToolBuilder_InputSchema(t *testing.T) {
+	raw := json.RawMessage(`{"type":"object","properties":{"q":{"type":"string"}},"required":["q"]}`)
+
+	tests := []struct {
+		name   string
+		schema any
+		before bool
+	}{
+		{name: "raw JSON before handler", schema: raw, before: true},
+		{name: "raw JSON after handler", schema: raw},
+		{name: "map", schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"q": map[string]any{"type": "string"}},
+			"required":   []any{"q"},
+		}},
+		{name: "schema value", schema: &schema.Schema{
+			Type:       "object",
+			Properties: map[string]*schema.Schema{"q": {Type: "string"}},
+			Required:   []string{"q"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"})
+			handler := func(ctx context.Context, args json.RawMessage) (string, error) {
+				return string(args), nil
+			}
+
+			b := srv.Tool("proxy")
+			if tt.before {
+				b.InputSchema(tt.schema).ValidateInput().Handler(handler)
+			} else {
+				b.ValidateInput().Handler(handler).InputSchema(tt.schema)
+			}
+			if err := b.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			data, err := json.Marshal(srv.Tools()[0].InputSchema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want map[string]any
+			_ = json.Unmarshal(data, &got)
+			_ = json.Unmarshal(raw, &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("InputSchema = %s, want %s", data, raw)
+			}
+
+			tool, _ := srv.getTool("proxy")
+			result, err := tool.Execute(context.Background(), []byte(`{"q":"go"}`))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result != `{"q":"go"}` {
+				t.Errorf("result = %v, want the raw arguments", result)
+			}
+			if _, err := tool.Execute(context.Background(), []byte(`{}`)); err == nil {
+				t.Error("Execute() without required field succeeded, want validation error")
+			}
+		})
+	}
+
+	t.Run("invalid schema", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		b := srv.Tool("bad").InputSchema(func() {})
+		if b.Err() == nil {
+			t.Fatal("Err() = nil, want error")
+		}
+		b.Handler(func(args json.RawMessage) (string, error) { return "", nil })
+		if len(srv.Tools()) != 0 {
+			t.Error("tool with invalid schema was registered")
+		}
+	})
+
+	t.Run("raw input defaults to any object", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Tool("raw").Handler(func(args json.RawMessage) (string, error) { return "", nil })
+
+		s, ok := srv.Tools()[0].InputSchema.(*schema.Schema)
+		if !ok || s.Type != "object" || s.Items != nil {
+			t.Errorf("InputSchema = %#v, want an object schema", srv.Tools()[0].InputSchema)
+		}
+	})
+}
+
+func TestToolBuilder_Err(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	if err := srv.Tool("ok").Handler(func(struct{}) (string, error) { return "", nil }).Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	if err := srv.Tool("bad").Handler("not a function").Err(); err == nil {
+		t.Error("Err() = nil, want error for invalid handler")
+	}
+}
+
+func TestWithInputValidation(t *testing.T) {
+	type Address struct {
+		City string `json:"city" jsonschema:"required"`
+	}
+	type Input struct {
+		Name    string    `json:"name" jsonschema:"required"`
+		Tags    []string  `json:"tags"`
+		Address Address   `json:"address"`
+		Others  []Address `json:"others"`
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		wantPaths []string
+	}{
+		{name: "valid", input: `{"name":"a","address":{"city":"x"}}`},
+		{name: "missing required", input: `{"address":{"city":"x"}}`, wantPaths: []string{"name"}},
+		{name: "nested required", input: `{"name":"a","address":{}}`, wantPaths: []string{"address.city"}},
+		{name: "wrong item type", input: `{"name":"a","tags":["ok",1]}`, wantPaths: []string{"tags[1]"}},
+		{name: "array of objects", input: `{"name":"a","others":[{"city":"x"},{}]}`, wantPaths: []string{"others[1].city"}},
+		{name: "invalid JSON", input: `{`, wantPaths: []string{""}},
+	}
+
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithInputValidation())
+	srv.Tool("register").Handler(func(in Input) (string, error) { return in.Name, nil })
+	tool, _ := srv.getTool("register")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), json.RawMessage(tt.input))
+			if tt.wantPaths == nil {
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				return
+			}
+
+			var rpcErr *protocol.Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != protocol.CodeInvalidParams {
+				t.Fatalf("Execute() error = %v, want InvalidParams", err)
+			}
+			data, ok := rpcErr.Data.(ValidationErrorData)
+			if !ok {
+				t.Fatalf("Data = %T, want ValidationErrorData", rpcErr.Data)
+			}
+			var paths []string
+			for _, e := range data.Errors {
+				paths = append(paths, e.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("paths = %q, want %q", paths, tt.wantPaths)
+			}
+		})
+	}
+
+	t.Run("encodes field paths", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+		var rpcErr *protocol.Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		data, _ := json.Marshal(rpcErr)
+		want := `"data":{"errors":[{"path":"name","message":"required field is missing"}]}`
+		if !strings.Contains(string(data), want) {
+			t.Errorf("error JSON = %s, want it to contain %s", data, want)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		srv.Tool("register").Handler(func(in Input) (string, error) { return in.Name, nil })
+		tool, _ := srv.getTool("register")
+
+		if _, err := tool.Execute(context.Background(), json.RawMessage(`{}`)); err != nil {
+			t.Errorf("Execute() error = %v, want nil without validation", err)
+		}
+	})
+}
+
+func TestToolBuilder_HiddenAndDeprecated(t *testing.T) {
+	newServer := func(debug bool) *Server {
+		srv := New(Info{Name: "test", Version: "1.0.0"}, WithDebug(debug))
+		handler := func(struct{}) (string, error) { return "ok", nil }
+		srv.Tool("search").Description("Search documents.").Deprecated("use search_v2").Handler(handler)
+		srv.Tool("search_v2").Description("Search documents.").Handler(handler)
+		srv.Tool("internal_reset").Hidden().Handler(handler)
+		return srv
+	}
+
+	t.Run("hidden tools are not listed", func(t *testing.T) {
+		srv := newServer(false)
+		var names []string
+		for _, info := range srv.Tools() {
+			names = append(names, info.Name)
+		}
+		if strings.Join(names, ",") != "search,search_v2" {
+			t.Errorf("Tools() = %v, want the hidden tool left out", names)
+		}
+		if _, ok := srv.GetTool("internal_reset"); !ok {
+			t.Error("hidden tool not callable by name")
+		}
+	})
+
+	t.Run("hidden tools are listed in debug mode", func(t *testing.T) {
+		if n := len(newServer(true).Tools()); n != 3 {
+			t.Errorf("Tools() returned %d tools, want 3", n)
+		}
+	})
+
+	t.Run("deprecated tools are annotated", func(t *testing.T) {
+		tools := newServer(false).Tools()
+		if tools[0].Deprecated != "use search_v2" || tools[1].Deprecated != "" {
+			t.Errorf("Deprecated = %q, %q", tools[0].Deprecated, tools[1].Deprecated)
+		}
+	})
+}
+
+func TestToolInfo_ListDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     ToolInfo
+		wantDesc string
+		wantMeta map[string]any
+	}{
+		{name: "current", info: ToolInfo{Description: "Search."}, wantDesc: "Search."},
+		{name: "deprecated", info: ToolInfo{Description: "Search.", Deprecated: "use search_v2"}, wantDesc: "Deprecated: use search_v2. Search.", wantMeta: map[string]any{"deprecated": "use search_v2"}},
+		{name: "deprecated without description", info: ToolInfo{Deprecated: "use search_v2"}, wantDesc: "Deprecated: use search_v2.", wantMeta: map[string]any{"deprecated": "use search_v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.ListDescription(); got != tt.wantDesc {
+				t.Errorf("ListDescription() = %q, want %q", got, tt.wantDesc)
+			}
+			if got := tt.info.ListMeta(); !reflect.DeepEqual(got, tt.wantMeta) {
+				t.Errorf("ListMeta() = %v, want %v", got, tt.wantMeta)
+			}
+		})
+	}
+}
+
+type categoryInput struct {
+	Name     string          `json:"name" jsonschema:"required"`
+	Children []categoryInput `json:"children"`
+}
+
+func TestTool_RecursiveInput(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithInputValidation())
+	srv.Tool("import").Handler(func(in categoryInput) (int, error) {
+		return len(in.Children), nil
+	})
+
+	tool, ok := srv.getTool("import")
+	if !ok {
+		t.Fatal("tool not found")
+	}
+	data, _ := json.Marshal(tool.inputSchema)
+	if !strings.Contains(string(data), `"items":{"$ref":"#"}`) {
+		t.Errorf("input schema = %s, want self reference", data)
+	}
+
+	result, err := tool.Execute(context.Background(), []byte(`{"name": "a", "children": [{"name": "b"}, {"name": "c"}]}`))
+	if err != nil || result != 2 {
+		t.Fatalf("Execute() = %v, %v", result, err)
+	}
+	if _, err := tool.Execute(context.Background(), []byte(`{"name": "a", "children": [{}]}`)); err == nil ||
+		!strings.Contains(err.Error(), "children[0].name: required field is missing") {
+		t.Errorf("Execute() error = %v, want nested validation error", err)
+	}
+}
+
+func TestWithSchemaOptions(t *testing.T) {
+	type Input struct {
+		Query string  `json:"query"`
+		Limit *int    `json:"limit"`
+		Sort  *string `json:"sort"`
+	}
+	srv := New(Info{Name: "test", Version: "1.0.0"},
+		WithSchemaOptions(schema.NoAdditionalProperties(), schema.NullablePointers(), schema.WithDialect(schema.Draft202012)),
+		WithInputValidation())
+	srv.Tool("search").Handler(func(in Input) (string, error) { return in.Query, nil })
+	tool, _ := srv.getTool("search")
+
+	data, _ := json.Marshal(tool.inputSchema)
+	for _, want := range []string{`{"$schema":"https://json-schema.org/draft/2020-12/schema",`, `"additionalProperties":false`, `"limit":{"type":["integer","null"]}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("input schema = %s, want %s", data, want)
+		}
+	}
+
+	if _, err := tool.Execute(context.Background(), []byte(`{"query": "go", "limit": null}`)); err != nil {
+		t.Errorf("Execute() error = %v", err)
+	}
+	_, err := tool.Execute(context.Background(), []byte(`{"query": "go", "lmit": 5}`))
+	if err == nil || !strings.Contains(err.Error(), "lmit: additional property is not allowed") {
+		t.Errorf("Execute() error = %v, want unknown property rejected", err)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ToolError is a tool execution failure reported to the client as a normal
+// tools/call result with isError set, rather than as a JSON-RPC error.
+// This lets the model see the failure and react to it, for example by
+// retrying with different arguments.
+//
+// Return a ToolError from a tool handler for failures the model should
+// know about. Errors of other types, including *protocol.Error, remain
+// protocol-level failures.
+//
+// Example:
+//
+//	func(ctx context.Context, in SearchInput) (string, error) {
+//	    if in.Query == "" {
+//	        return "", server.NewToolError("query must not be empty")
+//	    }
+//	    ...
+//	}
+type ToolError struct {
+	// Message is the text shown to the model.
+	Message string
+	// Err is the underlying cause, if any. It is not sent to the client.
+	Err error
+}
+
+// NewToolError creates a ToolError with the given message.
+func NewToolError(msg string) *ToolError {
+	return &ToolError{Message: msg}
+}
+
+// ToolErrorf creates a ToolError with a formatted message. If the format
+// contains a %w verb, the wrapped error is available through Unwrap.
+func ToolErrorf(format string, args ...any) *ToolError {
+	err := fmt.Errorf(format, args...)
+	return &ToolError{Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// Error implements the error interface.
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying cause.
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// AsToolError reports whether err is or wraps a *ToolError and returns it.
+func AsToolError(err error) (*ToolError, bool) {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr, true
+	}
+	return nil, false
+}
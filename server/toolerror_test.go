@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestToolError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantMsg   string
+		wantCause error
+	}{
+		{
+			name:    "message",
+			err:     NewToolError("query must not be empty"),
+			wantMsg: "query must not be empty",
+		},
+		{
+			name:      "formatted with cause",
+			err:       ToolErrorf("cannot read %s: %w", "a.txt", fs.ErrNotExist),
+			wantMsg:   "cannot read a.txt: file does not exist",
+			wantCause: fs.ErrNotExist,
+		},
+		{
+			name:    "wrapped",
+			err:     fmt.Errorf("search: %w", NewToolError("no results")),
+			wantMsg: "no results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolErr, ok := AsToolError(tt.err)
+			if !ok {
+				t.Fatal("expected ToolError")
+			}
+			if toolErr.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", toolErr.Message, tt.wantMsg)
+			}
+			if tt.wantCause != nil && !errors.Is(tt.err, tt.wantCause) {
+				t.Errorf("expected errors.Is to find %v", tt.wantCause)
+			}
+		})
+	}
+}
+
+func TestAsToolError_Other(t *testing.T) {
+	if _, ok := AsToolError(errors.New("boom")); ok {
+		t.Error("expected plain error not to be a ToolError")
+	}
+	if _, ok := AsToolError(nil); ok {
+		t.Error("expected nil not to be a ToolError")
+	}
+}
+
+func TestTool_ExecuteToolError(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	srv.Tool("fail").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "", NewToolError("bad input")
+	})
+
+	tool, _ := srv.GetTool("fail")
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+
+	toolErr, ok := AsToolError(err)
+	if !ok {
+		t.Fatalf("error = %v, want ToolError", err)
+	}
+	if toolErr.Message != "bad input" {
+		t.Errorf("Message = %q, want %q", toolErr.Message, "bad input")
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/base64"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// Content types of tool result blocks.
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResourceLink = "resource_link"
+	ContentTypeResource     = "resource"
+)
+
+// ToolResult is a tool result made of several content blocks. Return it
+// from a tool handler to send non-text output such as images, or more than
+// one block. Other handler results are sent as a single text block.
+//
+// Example:
+//
+//	srv.Tool("screenshot").Handler(func(ctx context.Context, in ShotInput) (*server.ToolResult, error) {
+//	    png, err := capture(ctx, in.URL)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return server.NewToolResult().
+//	        Text("Screenshot of " + in.URL).
+//	        Image(png, "image/png"), nil
+//	})
+type ToolResult struct {
+	content    []protocol.Content
+	structured any
+	isError    bool
+	err        error
+
+	meta map[string]any
+}
+
+// NewToolResult creates an empty tool result.
+func NewToolResult() *ToolResult {
+	return &ToolResult{}
+}
+
+// Text appends a text block.
+func (r *ToolResult) Text(text string) *ToolResult {
+	return r.add(protocol.Content{Type: ContentTypeText, Text: text})
+}
+
+// Image appends an image block. data holds the raw image bytes and is
+// base64 encoded for transport. An invalid image MIME type is reported by Err.
+func (r *ToolResult) Image(data []byte, mimeType string) *ToolResult {
+	return r.media(ContentTypeImage, data, mimeType)
+}
+
+// Audio appends an audio block. data holds the raw audio bytes and is
+// base64 encoded for transport. An invalid audio MIME type is reported by Err.
+func (r *ToolResult) Audio(data []byte, mimeType string) *ToolResult {
+	return r.media(ContentTypeAudio, data, mimeType)
+}
+
+// ResourceLink appends a link to a resource the client can read with
+// resources/read.
+func (r *ToolResult) ResourceLink(uri, name, mimeType string) *ToolResult {
+	return r.Link(NewResourceLink(uri, name, mimeType))
+}
+
+// EmbeddedResource appends the contents of a resource.
+func (r *ToolResult) EmbeddedResource(content *ResourceContent) *ToolResult {
+	return r.Embed(NewEmbeddedResource(content))
+}
+
+// Link appends a resource link, such as one returned by Server.LinkResource.
+func (r *ToolResult) Link(link ResourceLink) *ToolResult {
+	return r.add(link.content())
+}
+
+// Embed appends an embedded resource, such as one returned by
+// Server.EmbedResource.
+func (r *ToolResult) Embed(resource EmbeddedResource) *ToolResult {
+	return r.add(resource.content())
+}
+
+// Append appends content blocks in their wire form, such as blocks received
+// from another MCP server.
+func (r *ToolResult) Append(content ...protocol.Content) *ToolResult {
+	r.content = append(r.content, content...)
+	return r
+}
+
+// Structured sets the structured content of the result, the JSON value
+// that tools declaring an output schema send next to their content blocks.
+func (r *ToolResult) Structured(v any) *ToolResult {
+	r.structured = v
+	return r
+}
+
+// Truncate fits the content of the result into about maxTokens tokens. See
+// TruncateContent.
+func (r *ToolResult) Truncate(maxTokens int, strategy TruncateStrategy) *ToolResult {
+	r.content = TruncateContent(r.content, maxTokens, strategy)
+	return r
+}
+
+// WithError marks the result as a tool execution failure (isError).
+// See also ToolError for failures that only carry a message.
+func (r *ToolResult) WithError() *ToolResult {
+	r.isError = true
+	return r
+}
+
+// WithMeta sets key to value in the _meta of the result. Entries set here
+// take precedence over those set with WithResultMeta.
+func (r *ToolResult) WithMeta(key string, value any) *ToolResult {
+	if r.meta == nil {
+		r.meta = make(map[string]any)
+	}
+	r.meta[key] = value
+	return r
+}
+
+// Meta returns the metadata set with WithMeta.
+func (r *ToolResult) Meta() map[string]any {
+	return r.meta
+}
+
+// Content returns the content blocks of the result.
+func (r *ToolResult) Content() []protocol.Content {
+	return r.content
+}
+
+// Err returns the first error encountered while building the result, such
+// as an invalid MIME type. A result with an error is not sent to the client.
+func (r *ToolResult) Err() error {
+	return r.err
+}
+
+// IsError reports whether the result is marked as a failure.
+func (r *ToolResult) IsError() bool {
+	return r.isError
+}
+
+// CallToolResult converts the result to its wire form.
+func (r *ToolResult) CallToolResult() protocol.CallToolResult {
+	content := r.content
+	if content == nil {
+		content = []protocol.Content{}
+	}
+	return protocol.CallToolResult{Content: content, StructuredContent: r.structured, IsError: r.isError, Meta: r.meta}
+}
+
+func (r *ToolResult) media(kind string, data []byte, mimeType string) *ToolResult {
+	if err := ValidateMimeType(kind, mimeType); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return r
+	}
+	return r.add(protocol.Content{
+		Type:     kind,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	})
+}
+
+func (r *ToolResult) add(c protocol.Content) *ToolResult {
+	r.content = append(r.content, c)
+	return r
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestToolResult(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G'}
+	wav := []byte("RIFF")
+
+	result := NewToolResult().
+		Text("two attachments").
+		Image(png, "image/png").
+		Audio(wav, "audio/wav").
+		ResourceLink("file:///report.pdf", "report", "application/pdf").
+		EmbeddedResource(&ResourceContent{URI: "file:///notes.txt", MimeType: "text/plain", Text: "notes"})
+
+	want := []protocol.Content{
+		{Type: ContentTypeText, Text: "two attachments"},
+		{Type: ContentTypeImage, Data: base64.StdEncoding.EncodeToString(png), MimeType: "image/png"},
+		{Type: ContentTypeAudio, Data: base64.StdEncoding.EncodeToString(wav), MimeType: "audio/wav"},
+		{Type: ContentTypeResourceLink, URI: "file:///report.pdf", Name: "report", MimeType: "application/pdf"},
+		{Type: ContentTypeResource, Resource: &protocol.ResourceContents{URI: "file:///notes.txt", MimeType: "text/plain", Text: "notes"}},
+	}
+
+	got := result.Content()
+	if len(got) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		gotJSON, _ := json.Marshal(got[i])
+		wantJSON, _ := json.Marshal(want[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("block %d = %s, want %s", i, gotJSON, wantJSON)
+		}
+	}
+	if result.IsError() {
+		t.Error("expected result not to be an error")
+	}
+}
+
+func TestToolResult_CallToolResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *ToolResult
+		want   string
+	}{
+		{
+			name:   "empty",
+			result: NewToolResult(),
+			want:   `{"content":[]}`,
+		},
+		{
+			name:   "error",
+			result: NewToolResult().Text("quota exceeded").WithError(),
+			want:   `{"content":[{"type":"text","text":"quota exceeded"}],"isError":true}`,
+		},
+		{
+			name:   "resource link",
+			result: NewToolResult().ResourceLink("file:///a", "a", ""),
+			want:   `{"content":[{"type":"resource_link","uri":"file:///a","name":"a"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.result.CallToolResult())
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolResult_WithMeta(t *testing.T) {
+	result := NewToolResult().Text("ok").WithMeta("traceId", "abc")
+
+	data, err := json.Marshal(result.CallToolResult())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"content":[{"type":"text","text":"ok"}],"_meta":{"traceId":"abc"}}`
+	if string(data) != want {
+		t.Errorf("CallToolResult() = %s, want %s", data, want)
+	}
+
+	data, _ = json.Marshal(NewToolResult().Text("ok").CallToolResult())
+	if string(data) != `{"content":[{"type":"text","text":"ok"}]}` {
+		t.Errorf("result without meta = %s", data)
+	}
+}
+
+func TestToolResult_Append(t *testing.T) {
+	blocks := []protocol.Content{
+		{Type: ContentTypeText, Text: "forwarded"},
+		{Type: ContentTypeResourceLink, URI: "file:///a.txt", Name: "a"},
+	}
+
+	result := NewToolResult().Text("first").Append(blocks...)
+
+	got := result.Content()
+	if len(got) != 3 {
+		t.Fatalf("got %d blocks, want 3", len(got))
+	}
+	if got[0].Text != "first" || got[1].Text != "forwarded" || got[2].URI != "file:///a.txt" {
+		t.Errorf("content = %+v", got)
+	}
+}
+
+func TestToolResult_Structured(t *testing.T) {
+	result := NewToolResult().Text(`{"city":"Oslo"}`).Structured(map[string]any{"city": "Oslo"})
+
+	wire := result.CallToolResult()
+	structured, ok := wire.StructuredContent.(map[string]any)
+	if !ok || structured["city"] != "Oslo" {
+		t.Errorf("StructuredContent = %v, want city Oslo", wire.StructuredContent)
+	}
+
+	data, _ := json.Marshal(NewToolResult().Text("plain").CallToolResult())
+	if strings.Contains(string(data), "structuredContent") {
+		t.Errorf("result without structured content encodes as %s", data)
+	}
+}
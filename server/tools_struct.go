@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// ToolDescriber is implemented by services passed to ToolsFromStruct that
+// describe their tools. ToolDescriptions maps method names to tool
+// descriptions.
+type ToolDescriber interface {
+	ToolDescriptions() map[string]string
+}
+
+// StructOption configures ToolsFromStruct.
+type StructOption func(*structConfig)
+
+type structConfig struct {
+	docs   map[string]string
+	prefix string
+}
+
+// WithToolDocs sets tool descriptions by method name, taking precedence
+// over the service's ToolDescriptions.
+func WithToolDocs(docs map[string]string) StructOption {
+	return func(c *structConfig) {
+		c.docs = docs
+	}
+}
+
+// WithToolPrefix prefixes the name of every tool, such as "billing." to
+// namespace the tools of one service.
+func WithToolPrefix(prefix string) StructOption {
+	return func(c *structConfig) {
+		c.prefix = prefix
+	}
+}
+
+// ToolsFromStruct registers every exported method of service with a tool
+// handler signature, func(T) (R, error) or func(context.Context, T)
+// (R, error), as a tool. Tool names are the method names in snake case,
+// such as search_docs for SearchDocs. Descriptions come from WithToolDocs
+// or, if service implements ToolDescriber, from ToolDescriptions. Methods
+// with other signatures are skipped. It returns an error if no method is a
+// handler or a tool cannot be registered.
+//
+// Example:
+//
+//	type Docs struct{ index *search.Index }
+//
+//	func (d *Docs) Search(ctx context.Context, in SearchInput) ([]Hit, error) { ... }
+//	func (d *Docs) Fetch(in FetchInput) (*Doc, error) { ... }
+//
+//	err := srv.ToolsFromStruct(&Docs{index: idx}, server.WithToolDocs(map[string]string{
+//	    "Search": "Full-text search over the documentation",
+//	    "Fetch":  "Fetch a document by ID",
+//	}))
+func (s *Server) ToolsFromStruct(service any, opts ...StructOption) error {
+	cfg := &structConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	var described map[string]string
+	if d, ok := service.(ToolDescriber); ok {
+		described = d.ToolDescriptions()
+	}
+
+	v := reflect.ValueOf(service)
+	if !v.IsValid() {
+		return fmt.Errorf("tools from struct: nil service")
+	}
+	registered := 0
+	for i := range v.NumMethod() {
+		method := v.Type().Method(i)
+		fn := v.Method(i)
+		if method.Name == "ToolDescriptions" || !isToolHandler(fn.Type()) {
+			continue
+		}
+
+		description, ok := cfg.docs[method.Name]
+		if !ok {
+			description = described[method.Name]
+		}
+		b := s.Tool(cfg.prefix + snakeCase(method.Name)).
+			Description(description).
+			Handler(fn.Interface())
+		if err := b.Err(); err != nil {
+			return fmt.Errorf("tool from %s: %w", method.Name, err)
+		}
+		registered++
+	}
+	if registered == 0 {
+		return fmt.Errorf("tools from struct: %T has no handler methods", service)
+	}
+	return nil
+}
+
+// isToolHandler reports whether t is a tool handler signature.
+func isToolHandler(t reflect.Type) bool {
+	errType := reflect.TypeFor[error]()
+	if t.NumOut() != 2 || t.Out(1) != errType || t.IsVariadic() {
+		return false
+	}
+	switch t.NumIn() {
+	case 1:
+		return t.In(0) != reflect.TypeFor[context.Context]()
+	case 2:
+		return t.In(0) == reflect.TypeFor[context.Context]()
+	}
+	return false
+}
+
+// snakeCase converts a Go identifier to snake case, keeping acronyms
+// together: SearchDocs becomes search_docs and GetHTTPStatus
+// get_http_status.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
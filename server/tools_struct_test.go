@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type searchInput struct {
+	Query string `json:"query" jsonschema:"required"`
+}
+
+type docService struct {
+	prefix string
+}
+
+func (d *docService) Search(ctx context.Context, in searchInput) ([]string, error) {
+	return []string{d.prefix + in.Query}, nil
+}
+
+func (d *docService) GetHTTPStatus(in struct{}) (int, error) {
+	return 200, nil
+}
+
+// Methods with other signatures are skipped
+func (d *docService) String() string                        { return "docs" }
+func (d *docService) Close() error                          { return nil }
+func (d *docService) Variadic(in ...string) (string, error) { return "", nil }
+func (d *docService) TwoInputs(a, b string) (string, error) { return "", nil }
+
+func (d *docService) ToolDescriptions() map[string]string {
+	return map[string]string{"Search": "Search the docs", "GetHTTPStatus": "Report the status"}
+}
+
+type noTools struct{}
+
+func (noTools) Name() string { return "none" }
+
+func TestServer_ToolsFromStruct(t *testing.T) {
+	t.Run("registers handler methods", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		if err := srv.ToolsFromStruct(&docService{prefix: "hit:"}); err != nil {
+			t.Fatalf("ToolsFromStruct() error = %v", err)
+		}
+
+		tools := srv.Tools()
+		var names []string
+		for _, info := range tools {
+			names = append(names, info.Name+"="+info.Description)
+		}
+		if strings.Join(names, ",") != "get_http_status=Report the status,search=Search the docs" {
+			t.Errorf("Tools() = %v", names)
+		}
+
+		tool, _ := srv.GetTool("search")
+		result, err := tool.Execute(context.Background(), []byte(`{"query":"mcp"}`))
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if got := result.([]string); len(got) != 1 || got[0] != "hit:mcp" {
+			t.Errorf("Execute() = %v, want the bound method result", got)
+		}
+	})
+
+	t.Run("docs and prefix options", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		err := srv.ToolsFromStruct(&docService{},
+			WithToolPrefix("docs."),
+			WithToolDocs(map[string]string{"Search": "Full-text search"}))
+		if err != nil {
+			t.Fatalf("ToolsFromStruct() error = %v", err)
+		}
+		tool, ok := srv.GetTool("docs.search")
+		if !ok || tool.description != "Full-text search" {
+			t.Errorf("docs.search = %v, %v, want the docs map description", tool, ok)
+		}
+		if tool, ok := srv.GetTool("docs.get_http_status"); !ok || tool.description != "Report the status" {
+			t.Error("docs.get_http_status missing or without the ToolDescriptions description")
+		}
+	})
+
+	t.Run("no handler methods", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		for _, service := range []any{noTools{}, nil, 42} {
+			if err := srv.ToolsFromStruct(service); err == nil {
+				t.Errorf("ToolsFromStruct(%T) error = nil, want error", service)
+			}
+		}
+	})
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Search":        "search",
+		"SearchDocs":    "search_docs",
+		"GetHTTPStatus": "get_http_status",
+		"HTTPGet":       "http_get",
+		"ID":            "id",
+		"ParseV2Input":  "parse_v2_input",
+	}
+	for in, want := range tests {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// bytesPerToken is the number of bytes counted as one token. Tokenizers
+// differ, but about four bytes per token holds for English text and code.
+const bytesPerToken = 4
+
+// TruncateStrategy selects which part of oversized content is kept.
+type TruncateStrategy int
+
+const (
+	// TruncateKeepStart keeps the beginning and cuts the end, for content
+	// read from the top such as documents. This is the default.
+	TruncateKeepStart TruncateStrategy = iota
+	// TruncateKeepEnd keeps the end and cuts the beginning, for content
+	// whose latest part matters most such as logs.
+	TruncateKeepEnd
+	// TruncateKeepBoth keeps the beginning and the end and cuts the middle.
+	TruncateKeepBoth
+)
+
+// EstimateTokens estimates the number of tokens text takes up in a model's
+// context. It is a rough estimate meant for budgeting, not billing.
+func EstimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// ContentBytes returns the payload size of content blocks in bytes: the
+// text, the base64 data of images and audio, and the text or blob of
+// embedded resources.
+func ContentBytes(content []protocol.Content) int {
+	n := 0
+	for _, c := range content {
+		n += blockBytes(c)
+	}
+	return n
+}
+
+// ContentTokens estimates the number of tokens content blocks take up in a
+// model's context. See EstimateTokens.
+func ContentTokens(content []protocol.Content) int {
+	n := 0
+	for _, c := range content {
+		n += blockTokens(c)
+	}
+	return n
+}
+
+// TruncateText shortens text to about maxTokens tokens, keeping the part
+// selected by strategy and marking the cut with the number of bytes
+// removed. Text within the budget is returned unchanged. Cuts fall on
+// UTF-8 character boundaries.
+//
+// Example:
+//
+//	content.Text = server.TruncateText(logs, 2000, server.TruncateKeepEnd)
+func TruncateText(text string, maxTokens int, strategy TruncateStrategy) string {
+	limit := max(maxTokens, 0) * bytesPerToken
+	if len(text) <= limit {
+		return text
+	}
+
+	// Reserve room for the marker with the widest count it can show
+	keep := limit - len(truncationMarker(len(text))) - 2
+	if keep <= 0 {
+		return text[:runeStart(text, limit)]
+	}
+
+	switch strategy {
+	case TruncateKeepEnd:
+		tail := text[runeEnd(text, len(text)-keep):]
+		return truncationMarker(len(text)-len(tail)) + "\n" + tail
+	case TruncateKeepBoth:
+		head := text[:runeStart(text, keep/2)]
+		tail := text[runeEnd(text, len(text)-(keep-len(head))):]
+		return head + "\n" + truncationMarker(len(text)-len(head)-len(tail)) + "\n" + tail
+	default:
+		head := text[:runeStart(text, keep)]
+		return head + "\n" + truncationMarker(len(text)-len(head))
+	}
+}
+
+// TruncateContent fits content blocks into about maxTokens tokens, so a
+// handler can respect the context limits of the host instead of returning
+// megabytes of text. Whole blocks are kept in the order selected by
+// strategy; the first text block that does not fit is shortened with
+// TruncateText, and the blocks left out are replaced by a single text
+// block saying how many were omitted. Images, audio and embedded
+// resources are kept whole or omitted. Content within the budget is
+// returned unchanged.
+//
+// Example:
+//
+//	content := server.TruncateContent(result.Content(), 4000, server.TruncateKeepStart)
+//
+// ToolResult.Truncate applies it to a tool result.
+func TruncateContent(content []protocol.Content, maxTokens int, strategy TruncateStrategy) []protocol.Content {
+	if ContentTokens(content) <= maxTokens {
+		return content
+	}
+
+	budget := maxTokens
+	if len(content) > 1 {
+		budget -= EstimateTokens(omittedMarker(len(content), len(content)))
+	}
+
+	switch strategy {
+	case TruncateKeepEnd:
+		j, used := fillBackward(content, budget)
+		rest := content[j:]
+		var partial []protocol.Content
+		if j > 0 {
+			if c, ok := truncateBlock(content[j-1], budget-used, TruncateKeepEnd); ok {
+				partial = append(partial, c)
+				j--
+			}
+		}
+		kept := appendOmitted(make([]protocol.Content, 0, len(rest)+2), j, len(content))
+		kept = append(kept, partial...)
+		return append(kept, rest...)
+	case TruncateKeepBoth:
+		i, headUsed := fillForward(content, budget/2)
+		rest := content[i:]
+		j, tailUsed := fillBackward(rest, budget-headUsed)
+		kept := append(make([]protocol.Content, 0, len(content)), content[:i]...)
+		middle := rest[:j]
+		if len(middle) > 0 {
+			cut := TruncateKeepStart
+			if len(middle) == 1 {
+				cut = TruncateKeepBoth
+			}
+			if c, ok := truncateBlock(middle[0], budget-headUsed-tailUsed, cut); ok {
+				kept = append(kept, c)
+				middle = middle[1:]
+			}
+		}
+		kept = appendOmitted(kept, len(middle), len(content))
+		return append(kept, rest[j:]...)
+	default:
+		i, used := fillForward(content, budget)
+		kept := append(make([]protocol.Content, 0, i+2), content[:i]...)
+		if i < len(content) {
+			if c, ok := truncateBlock(content[i], budget-used, TruncateKeepStart); ok {
+				kept = append(kept, c)
+				i++
+			}
+		}
+		return appendOmitted(kept, len(content)-i, len(content))
+	}
+}
+
+// fillForward returns how many leading blocks fit in budget and the tokens
+// they use.
+func fillForward(content []protocol.Content, budget int) (n, used int) {
+	for n < len(content) && used+blockTokens(content[n]) <= budget {
+		used += blockTokens(content[n])
+		n++
+	}
+	return n, used
+}
+
+// fillBackward returns the index of the first of the trailing blocks that
+// fit in budget and the tokens they use.
+func fillBackward(content []protocol.Content, budget int) (start, used int) {
+	start = len(content)
+	for start > 0 && used+blockTokens(content[start-1]) <= budget {
+		start--
+		used += blockTokens(content[start])
+	}
+	return start, used
+}
+
+// truncateBlock shortens a text block to maxTokens. It reports false for
+// other blocks, which cannot be shortened, and when nothing fits.
+func truncateBlock(c protocol.Content, maxTokens int, strategy TruncateStrategy) (protocol.Content, bool) {
+	if c.Type != ContentTypeText || maxTokens <= 0 {
+		return c, false
+	}
+	c.Text = TruncateText(c.Text, maxTokens, strategy)
+	return c, c.Text != ""
+}
+
+// appendOmitted appends a text block reporting omitted blocks, if any.
+func appendOmitted(content []protocol.Content, omitted, total int) []protocol.Content {
+	if omitted == 0 {
+		return content
+	}
+	return append(content, protocol.Content{Type: ContentTypeText, Text: omittedMarker(omitted, total)})
+}
+
+func omittedMarker(omitted, total int) string {
+	return fmt.Sprintf("[... %d of %d content blocks omitted ...]", omitted, total)
+}
+
+func truncationMarker(removed int) string {
+	return fmt.Sprintf("[... %d bytes truncated ...]", removed)
+}
+
+func blockBytes(c protocol.Content) int {
+	n := len(c.Text) + len(c.Data)
+	if c.Resource != nil {
+		n += len(c.Resource.Text) + len(c.Resource.Blob)
+	}
+	return n
+}
+
+func blockTokens(c protocol.Content) int {
+	return (blockBytes(c) + bytesPerToken - 1) / bytesPerToken
+}
+
+// runeStart moves i back to the start of the character it falls in.
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// runeEnd moves i forward to the start of the next character if it falls
+// inside one.
+func runeEnd(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400), 100},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%d bytes) = %d, want %d", len(tt.text), got, tt.want)
+		}
+	}
+}
+
+func TestContentSize(t *testing.T) {
+	content := []protocol.Content{
+		{Type: ContentTypeText, Text: "hello"},
+		{Type: ContentTypeImage, Data: "aGVsbG8=", MimeType: "image/png"},
+		{Type: ContentTypeResource, Resource: &protocol.ResourceContents{URI: "file:///a", Text: "abc"}},
+	}
+
+	if got := ContentBytes(content); got != 16 {
+		t.Errorf("ContentBytes() = %d, want 16", got)
+	}
+	if got := ContentTokens(content); got != 5 {
+		t.Errorf("ContentTokens() = %d, want 5", got)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	text := strings.Repeat("a", 200) + strings.Repeat("b", 200) + strings.Repeat("c", 200)
+
+	tests := []struct {
+		name      string
+		text      string
+		maxTokens int
+		strategy  TruncateStrategy
+		prefix    string
+		suffix    string
+	}{
+		{name: "fits", text: "short", maxTokens: 10, prefix: "short", suffix: "short"},
+		{name: "keep start", text: text, maxTokens: 50, prefix: "aaaa", suffix: " bytes truncated ...]"},
+		{name: "keep end", text: text, maxTokens: 50, strategy: TruncateKeepEnd, prefix: "[... ", suffix: "cccc"},
+		{name: "keep both", text: text, maxTokens: 50, strategy: TruncateKeepBoth, prefix: "aaaa", suffix: "cccc"},
+		{name: "no room for marker", text: text, maxTokens: 2, prefix: "aaaaaaaa", suffix: "aaaaaaaa"},
+		{name: "zero budget", text: text, maxTokens: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateText(tt.text, tt.maxTokens, tt.strategy)
+			if EstimateTokens(got) > tt.maxTokens {
+				t.Errorf("result has %d tokens, want at most %d", EstimateTokens(got), tt.maxTokens)
+			}
+			if !strings.HasPrefix(got, tt.prefix) || !strings.HasSuffix(got, tt.suffix) {
+				t.Errorf("result = %q, want prefix %q and suffix %q", got, tt.prefix, tt.suffix)
+			}
+		})
+	}
+
+	t.Run("reports removed bytes", func(t *testing.T) {
+		got := TruncateText(text, 50, TruncateKeepStart)
+		kept := strings.Index(got, "\n")
+		want := fmt.Sprintf("[... %d bytes truncated ...]", len(text)-kept)
+		if !strings.HasSuffix(got, want) {
+			t.Errorf("result = %q, want suffix %q", got, want)
+		}
+	})
+
+	t.Run("cuts on character boundaries", func(t *testing.T) {
+		text := strings.Repeat("é世", 100)
+		for _, strategy := range []TruncateStrategy{TruncateKeepStart, TruncateKeepEnd, TruncateKeepBoth} {
+			for maxTokens := 0; maxTokens < 30; maxTokens++ {
+				if got := TruncateText(text, maxTokens, strategy); !utf8.ValidString(got) {
+					t.Fatalf("TruncateText(%d, %d) = %q is not valid UTF-8", maxTokens, strategy, got)
+				}
+			}
+		}
+	})
+}
+
+func TestTruncateContent(t *testing.T) {
+	text := func(s string) protocol.Content { return protocol.Content{Type: ContentTypeText, Text: s} }
+	image := protocol.Content{Type: ContentTypeImage, Data: strings.Repeat("A", 400), MimeType: "image/png"}
+	long := strings.Repeat("x", 400)
+
+	tests := []struct {
+		name      string
+		content   []protocol.Content
+		maxTokens int
+		strategy  TruncateStrategy
+		want      []string
+	}{
+		{
+			name:      "fits",
+			content:   []protocol.Content{text("a"), text("b")},
+			maxTokens: 10,
+			want:      []string{"text:a", "text:b"},
+		},
+		{
+			name:      "single block",
+			content:   []protocol.Content{text(long)},
+			maxTokens: 20,
+			want:      []string{"text:truncated"},
+		},
+		{
+			name:      "keep start",
+			content:   []protocol.Content{text("first"), text(long), image},
+			maxTokens: 40,
+			want:      []string{"text:first", "text:truncated", "text:[... 1 of 3 content blocks omitted ...]"},
+		},
+		{
+			name:      "keep start stops at an image",
+			content:   []protocol.Content{text("first"), image, text("last")},
+			maxTokens: 40,
+			want:      []string{"text:first", "text:[... 2 of 3 content blocks omitted ...]"},
+		},
+		{
+			name:      "keep end",
+			content:   []protocol.Content{image, text(long), text("last")},
+			maxTokens: 40,
+			strategy:  TruncateKeepEnd,
+			want:      []string{"text:[... 1 of 3 content blocks omitted ...]", "text:truncated", "text:last"},
+		},
+		{
+			name:      "keep both",
+			content:   []protocol.Content{text("first"), text(long), image, text("last")},
+			maxTokens: 40,
+			strategy:  TruncateKeepBoth,
+			want:      []string{"text:first", "text:truncated", "text:[... 1 of 4 content blocks omitted ...]", "text:last"},
+		},
+		{
+			name:      "keep both cuts a single middle block",
+			content:   []protocol.Content{text("first"), text(long), text("last")},
+			maxTokens: 40,
+			strategy:  TruncateKeepBoth,
+			want:      []string{"text:first", "text:truncated", "text:last"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateContent(tt.content, tt.maxTokens, tt.strategy)
+			if tokens := ContentTokens(got); tokens > tt.maxTokens {
+				t.Errorf("result has %d tokens, want at most %d", tokens, tt.maxTokens)
+			}
+			if summary := summarizeBlocks(got); strings.Join(summary, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("blocks = %v, want %v", summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolResult_Truncate(t *testing.T) {
+	result := NewToolResult().Text(strings.Repeat("x", 4000)).Truncate(100, TruncateKeepStart)
+
+	if tokens := ContentTokens(result.Content()); tokens > 100 {
+		t.Errorf("result has %d tokens, want at most 100", tokens)
+	}
+}
+
+// summarizeBlocks describes blocks as type:text, with truncated text
+// reported as "truncated".
+func summarizeBlocks(content []protocol.Content) []string {
+	summary := make([]string, len(content))
+	for i, c := range content {
+		text := c.Text
+		if strings.Contains(text, "bytes truncated") {
+			text = "truncated"
+		}
+		summary[i] = c.Type + ":" + text
+	}
+	return summary
+}
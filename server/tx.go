@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Compensation undoes a side effect applied earlier in a tool handler.
+// It receives a context that is detached from the request's cancellation
+// so cleanup can still run after the request was canceled.
+type Compensation func(ctx context.Context) error
+
+// Transaction collects compensation callbacks for a multi-step tool handler.
+// If the handler returns an error, panics, or the request context is
+// canceled, the registered compensations run automatically in reverse
+// registration order.
+type Transaction struct {
+	mu            sync.Mutex
+	compensations []Compensation
+	done          bool
+}
+
+// OnRollback registers a compensation to run if the transaction is rolled back.
+// Register the compensation right after the side effect it undoes succeeds.
+func (tx *Transaction) OnRollback(fn Compensation) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return
+	}
+	tx.compensations = append(tx.compensations, fn)
+}
+
+// Commit marks the transaction as successful and discards all compensations.
+// After Commit, a later handler error no longer triggers a rollback.
+func (tx *Transaction) Commit() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+	tx.compensations = nil
+}
+
+// Rollback runs all registered compensations in reverse order.
+// Every compensation is attempted; their errors are joined.
+// Rollback is a no-op after Commit or a previous Rollback.
+func (tx *Transaction) Rollback(ctx context.Context) error {
+	tx.mu.Lock()
+	if tx.done {
+		tx.mu.Unlock()
+		return nil
+	}
+	tx.done = true
+	compensations := tx.compensations
+	tx.compensations = nil
+	tx.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(compensations) - 1; i >= 0; i-- {
+		if err := compensations[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finish completes the transaction after the handler returned.
+// It rolls back when the handler failed or the context was canceled,
+// and returns the error the tool call should report.
+func (tx *Transaction) finish(ctx context.Context, handlerErr error) error {
+	if handlerErr == nil {
+		handlerErr = ctx.Err()
+	}
+	if handlerErr == nil {
+		tx.Commit()
+		return nil
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		return errors.Join(handlerErr, err)
+	}
+	return handlerErr
+}
+
+// rollbackOnPanic rolls back the transaction when the handler panics and
+// then continues panicking. It must be deferred before calling the handler.
+func (tx *Transaction) rollbackOnPanic(ctx context.Context) {
+	if r := recover(); r != nil {
+		_ = tx.Rollback(ctx)
+		panic(r)
+	}
+}
+
+// txContextKey is the context key for the transaction.
+type txContextKey struct{}
+
+// ContextWithTx returns a context with the transaction attached.
+func ContextWithTx(ctx context.Context, tx *Transaction) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction for the current tool call.
+// Outside of a tool call a new, unmanaged transaction is returned; its
+// compensations only run when Rollback is called explicitly.
+func TxFromContext(ctx context.Context) *Transaction {
+	if tx, ok := ctx.Value(txContextKey{}).(*Transaction); ok {
+		return tx
+	}
+	return &Transaction{}
+}
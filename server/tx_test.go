@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTransaction_Rollback(t *testing.T) {
+	t.Run("runs compensations in reverse order", func(t *testing.T) {
+		tx := &Transaction{}
+		var order []int
+		tx.OnRollback(func(ctx context.Context) error { order = append(order, 1); return nil })
+		tx.OnRollback(func(ctx context.Context) error { order = append(order, 2); return nil })
+		tx.OnRollback(func(ctx context.Context) error { order = append(order, 3); return nil })
+
+		if err := tx.Rollback(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []int{3, 2, 1}
+		if len(order) != len(want) {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Errorf("order = %v, want %v", order, want)
+				break
+			}
+		}
+	})
+
+	t.Run("joins compensation errors and runs all", func(t *testing.T) {
+		tx := &Transaction{}
+		errA := errors.New("a failed")
+		errB := errors.New("b failed")
+		calls := 0
+		tx.OnRollback(func(ctx context.Context) error { calls++; return errA })
+		tx.OnRollback(func(ctx context.Context) error { calls++; return errB })
+
+		err := tx.Rollback(context.Background())
+		if calls != 2 {
+			t.Errorf("calls = %d, want 2", calls)
+		}
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("error = %v, want both compensation errors", err)
+		}
+	})
+
+	t.Run("compensations get a non-canceled context", func(t *testing.T) {
+		tx := &Transaction{}
+		var compErr error
+		tx.OnRollback(func(ctx context.Context) error {
+			compErr = ctx.Err()
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = tx.Rollback(ctx)
+
+		if compErr != nil {
+			t.Errorf("compensation context error = %v, want nil", compErr)
+		}
+	})
+
+	t.Run("is a no-op after commit", func(t *testing.T) {
+		tx := &Transaction{}
+		called := false
+		tx.OnRollback(func(ctx context.Context) error { called = true; return nil })
+		tx.Commit()
+
+		_ = tx.Rollback(context.Background())
+		if called {
+			t.Error("compensation should not run after commit")
+		}
+	})
+}
+
+func TestTool_ExecuteTransaction(t *testing.T) {
+	type Input struct {
+		Fail bool `json:"fail"`
+	}
+
+	t.Run("rolls back when handler fails", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		handlerErr := errors.New("step 2 failed")
+		rolledBack := false
+
+		srv.Tool("multi-step").Handler(func(ctx context.Context, input Input) (string, error) {
+			tx := TxFromContext(ctx)
+			tx.OnRollback(func(ctx context.Context) error {
+				rolledBack = true
+				return nil
+			})
+			if input.Fail {
+				return "", handlerErr
+			}
+			return "ok", nil
+		})
+
+		tool, _ := srv.GetTool("multi-step")
+		_, err := tool.Execute(context.Background(), []byte(`{"fail":true}`))
+		if !errors.Is(err, handlerErr) {
+			t.Errorf("error = %v, want %v", err, handlerErr)
+		}
+		if !rolledBack {
+			t.Error("expected compensation to run")
+		}
+	})
+
+	t.Run("does not roll back on success", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		rolledBack := false
+
+		srv.Tool("multi-step").Handler(func(ctx context.Context, input Input) (string, error) {
+			TxFromContext(ctx).OnRollback(func(ctx context.Context) error {
+				rolledBack = true
+				return nil
+			})
+			return "ok", nil
+		})
+
+		tool, _ := srv.GetTool("multi-step")
+		result, err := tool.Execute(context.Background(), []byte(`{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != "ok" {
+			t.Errorf("result = %v, want %q", result, "ok")
+		}
+		if rolledBack {
+			t.Error("compensation should not run on success")
+		}
+	})
+
+	t.Run("rolls back when context is canceled mid-way", func(t *testing.T) {
+		srv := New(Info{Name: "test", Version: "1.0.0"})
+		ctx, cancel := context.WithCancel(context.Background())
+		rolledBack := false
+
+		srv.Tool("multi-step").Handler(func(ctx context.Context, input Input) (string, error) {
+			TxFromContext(ctx).OnRollback(func(ctx context.Context) error {
+				rolledBack = true
+				return nil
+			})
+			cancel()
+			return "partial", nil
+		})
+
+		tool, _ := srv.GetTool("multi-step")
+		_, err := tool.Execute(ctx, []byte(`{}`))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+		if !rolledBack {
+			t.Error("expected compensation to run")
+		}
+	})
+}
+
+func TestTool_ExecuteTransactionPanic(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+	rolledBack := false
+
+	srv.Tool("multi-step").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		TxFromContext(ctx).OnRollback(func(ctx context.Context) error {
+			rolledBack = true
+			return nil
+		})
+		panic("step 2 crashed")
+	})
+
+	tool, _ := srv.GetTool("multi-step")
+	func() {
+		defer func() {
+			if r := recover(); r != "step 2 crashed" {
+				t.Errorf("recovered %v, want the handler's panic", r)
+			}
+		}()
+		_, _ = tool.Execute(context.Background(), []byte(`{}`))
+	}()
+
+	if !rolledBack {
+		t.Error("expected compensation to run")
+	}
+}
+
+func TestTxFromContext(t *testing.T) {
+	t.Run("returns attached transaction", func(t *testing.T) {
+		tx := &Transaction{}
+		ctx := ContextWithTx(context.Background(), tx)
+		if got := TxFromContext(ctx); got != tx {
+			t.Error("expected attached transaction")
+		}
+	})
+
+	t.Run("returns unmanaged transaction when absent", func(t *testing.T) {
+		if TxFromContext(context.Background()) == nil {
+			t.Error("expected non-nil transaction")
+		}
+	})
+}
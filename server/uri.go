@@ -0,0 +1,184 @@
+package server
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// TrailingSlashPolicy controls how a trailing slash in a resource URI is
+// treated when matching.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashStrip ignores trailing slashes, so "docs://guide/" and
+	// "docs://guide" are the same resource. This is the default.
+	TrailingSlashStrip TrailingSlashPolicy = iota
+	// TrailingSlashKeep treats a trailing slash as significant.
+	TrailingSlashKeep
+)
+
+// WithTrailingSlashPolicy sets how trailing slashes in resource URIs are
+// matched. Defaults to TrailingSlashStrip.
+func WithTrailingSlashPolicy(policy TrailingSlashPolicy) Option {
+	return func(s *Server) {
+		s.trailingSlash = policy
+	}
+}
+
+// paramRegex matches {param} placeholders in URI templates.
+var paramRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// CanonicalURI normalizes a resource URI before matching:
+//
+//   - the scheme is lowercased
+//   - percent-encoded characters are decoded, except reserved delimiters
+//     ("/", "?", "#" and "%"), whose escapes are uppercased
+//   - the path is cleaned: duplicate slashes and "." and ".." segments
+//     are removed
+//   - a trailing slash is removed, unless policy is TrailingSlashKeep
+//
+// The query and fragment, if any, are left unchanged.
+func CanonicalURI(uri string, policy TrailingSlashPolicy) string {
+	scheme, rest, hasScheme := strings.Cut(uri, "://")
+	if !hasScheme {
+		scheme, rest = "", uri
+	}
+
+	suffix := ""
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest, suffix = rest[:i], rest[i:]
+	}
+
+	rest = cleanURIPath(decodeUnreserved(rest), policy)
+
+	if !hasScheme {
+		return rest + suffix
+	}
+	return strings.ToLower(scheme) + "://" + rest + suffix
+}
+
+// cleanURIPath removes duplicate slashes and dot segments from p.
+func cleanURIPath(p string, policy TrailingSlashPolicy) string {
+	if p == "" || !strings.Contains(p, "/") && p != "." && p != ".." {
+		return p
+	}
+
+	trailing := strings.HasSuffix(p, "/")
+	cleaned := path.Clean(p)
+	if cleaned == "." {
+		cleaned = ""
+	}
+	if trailing && policy == TrailingSlashKeep && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// decodeUnreserved decodes percent-encoded octets in s, except those that
+// would change how the URI is split into parts.
+func decodeUnreserved(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		switch c {
+		case '/', '?', '#', '%':
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		default:
+			b.WriteByte(c)
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// uriPattern is a compiled URI template.
+type uriPattern struct {
+	template   string
+	regex      *regexp.Regexp
+	paramNames []string
+}
+
+// compileURIPattern compiles a URI template for matching canonical URIs.
+func compileURIPattern(template string, policy TrailingSlashPolicy) (*uriPattern, error) {
+	canonical := CanonicalURI(template, policy)
+
+	matches := paramRegex.FindAllStringSubmatch(canonical, -1)
+	paramNames := make([]string, 0, len(matches))
+	for _, match := range matches {
+		paramNames = append(paramNames, match[1])
+	}
+
+	// Escape special regex characters and replace {param} with capture groups
+	pattern := regexp.QuoteMeta(canonical)
+	pattern = strings.ReplaceAll(pattern, `\{`, "{")
+	pattern = strings.ReplaceAll(pattern, `\}`, "}")
+	pattern = paramRegex.ReplaceAllString(pattern, `([^/]+)`)
+	pattern = "^" + pattern + "$"
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &uriPattern{template: template, regex: re, paramNames: paramNames}, nil
+}
+
+// match matches a canonical URI and returns the percent-decoded parameters.
+// A parameter that decodes to a path separator or a dot segment does not
+// match, so handlers never see values such as "../../etc/passwd".
+func (p *uriPattern) match(uri string) (map[string]string, bool) {
+	uriMatches := p.regex.FindStringSubmatch(uri)
+	if uriMatches == nil {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(p.paramNames))
+	for i, name := range p.paramNames {
+		if i+1 < len(uriMatches) {
+			value := uriMatches[i+1]
+			if decoded, err := url.PathUnescape(value); err == nil {
+				value = decoded
+			}
+			if !isSegmentValue(value) {
+				return nil, false
+			}
+			params[name] = value
+		}
+	}
+	return params, true
+}
+
+// isSegmentValue reports whether a decoded parameter is safe to use as a
+// single path segment.
+func isSegmentValue(value string) bool {
+	if value == "." || value == ".." {
+		return false
+	}
+	return !strings.ContainsAny(value, `/\`)
+}
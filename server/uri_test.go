@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		name   string
+		uri    string
+		policy TrailingSlashPolicy
+		want   string
+	}{
+		{name: "unchanged", uri: "users://123/profile", want: "users://123/profile"},
+		{name: "lowercases scheme", uri: "Users://123", want: "users://123"},
+		{name: "decodes unreserved", uri: "docs://%7Euser/my%20page", want: "docs://~user/my page"},
+		{name: "keeps encoded slash", uri: "repos://a%2fb", want: "repos://a%2Fb"},
+		{name: "keeps encoded percent", uri: "q://100%25", want: "q://100%25"},
+		{name: "ignores invalid escapes", uri: "q://50%zz", want: "q://50%zz"},
+		{name: "removes duplicate slashes", uri: "file:///a//b", want: "file:///a/b"},
+		{name: "resolves dot segments", uri: "file:///a/./b/../c", want: "file:///a/c"},
+		{name: "strips trailing slash", uri: "docs://guide/", want: "docs://guide"},
+		{name: "keeps root", uri: "file:///", want: "file:///"},
+		{name: "keeps trailing slash", uri: "docs://guide/", policy: TrailingSlashKeep, want: "docs://guide/"},
+		{name: "keeps query and fragment", uri: "search://items/?q=a//b#top", want: "search://items?q=a//b#top"},
+		{name: "no scheme", uri: "notes/./today", want: "notes/today"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalURI(tt.uri, tt.policy); got != tt.want {
+				t.Errorf("CanonicalURI(%q) = %q, want %q", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceBuilder_Alias(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	srv.Resource("file:///docs/{path}").
+		Alias("docs://{path}").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			return &ResourceContent{URI: uri, Text: params["path"]}, nil
+		})
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantText string
+		wantOK   bool
+	}{
+		{name: "primary template", uri: "file:///docs/intro.md", wantText: "intro.md", wantOK: true},
+		{name: "alias", uri: "docs://intro.md", wantText: "intro.md", wantOK: true},
+		{name: "encoded alias", uri: "docs://getting%20started.md", wantText: "getting started.md", wantOK: true},
+		{name: "unrelated", uri: "other://intro.md", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, ok := srv.FindResourceForURI(tt.uri)
+			if ok != tt.wantOK {
+				t.Fatalf("FindResourceForURI(%q) ok = %v, want %v", tt.uri, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			content, err := resource.Read(context.Background(), tt.uri)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if content.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", content.Text, tt.wantText)
+			}
+		})
+	}
+
+	resources := srv.Resources()
+	if len(resources) != 1 || len(resources[0].Aliases) != 1 || resources[0].Aliases[0] != "docs://{path}" {
+		t.Errorf("Resources() = %+v, want one resource with alias docs://{path}", resources)
+	}
+}
+
+func TestResource_ReadCanonicalURI(t *testing.T) {
+	srv := New(Info{Name: "test", Version: "1.0.0"})
+
+	var gotURI string
+	srv.Resource("file:///docs/{page}").
+		Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+			gotURI = uri
+			return &ResourceContent{URI: uri}, nil
+		})
+
+	resource, _ := srv.getResource("file:///docs/{page}")
+	if _, err := resource.Read(context.Background(), "FILE:///docs/./intro/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotURI != "file:///docs/intro" {
+		t.Errorf("handler uri = %q, want %q", gotURI, "file:///docs/intro")
+	}
+}
+
+func TestWithTrailingSlashPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy TrailingSlashPolicy
+		uri    string
+		wantOK bool
+	}{
+		{name: "strip matches with slash", policy: TrailingSlashStrip, uri: "dirs://a/", wantOK: true},
+		{name: "strip matches without slash", policy: TrailingSlashStrip, uri: "dirs://a", wantOK: true},
+		{name: "keep matches with slash", policy: TrailingSlashKeep, uri: "dirs://a/", wantOK: true},
+		{name: "keep rejects without slash", policy: TrailingSlashKeep, uri: "dirs://a", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(Info{Name: "test", Version: "1.0.0"}, WithTrailingSlashPolicy(tt.policy))
+			srv.Resource("dirs://{name}/").
+				Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+					return &ResourceContent{URI: uri}, nil
+				})
+
+			if got := srv.TrailingSlashPolicy(); got != tt.policy {
+				t.Errorf("TrailingSlashPolicy() = %v, want %v", got, tt.policy)
+			}
+			if _, ok := srv.FindResourceForURI(tt.uri); ok != tt.wantOK {
+				t.Errorf("FindResourceForURI(%q) ok = %v, want %v", tt.uri, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMatchURI_RejectsTraversal(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+		ok   bool
+	}{
+		{uri: "docs://files/readme.md", want: "readme.md", ok: true},
+		{uri: "docs://files/hello%20world", want: "hello world", ok: true},
+		{uri: "docs://files/..%2F..%2Fetc%2Fpasswd", ok: false},
+		{uri: "docs://files/a%2Fb", ok: false},
+		{uri: "docs://files/..%5Cwindows", ok: false},
+		{uri: "docs://files/%2E%2E", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			params, ok := matchURI("docs://files/{name}", tt.uri)
+			if ok != tt.ok {
+				t.Fatalf("matchURI() ok = %v, want %v (params %v)", ok, tt.ok, params)
+			}
+			if ok && params["name"] != tt.want {
+				t.Errorf("name = %q, want %q", params["name"], tt.want)
+			}
+		})
+	}
+}
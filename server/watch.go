@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultPollInterval is how often NewPollingWatcher checks for changes
+// when no interval is given.
+const defaultPollInterval = time.Second
+
+// FileWatcher reports changes to files on disk. Watch calls onChange with
+// the path of every file created, modified or removed under paths, which
+// are absolute paths of files or directories watched recursively, until
+// ctx is done. It returns nil once ctx is done.
+//
+// The default watcher polls; implement FileWatcher to use native file
+// system events instead, for example with fsnotify:
+//
+//	type fsnotifyWatcher struct{}
+//
+//	func (fsnotifyWatcher) Watch(ctx context.Context, paths []string, onChange func(path string)) error {
+//	    w, err := fsnotify.NewWatcher()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer w.Close()
+//	    for _, p := range paths {
+//	        if err := w.Add(p); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    for {
+//	        select {
+//	        case <-ctx.Done():
+//	            return nil
+//	        case event := <-w.Events:
+//	            onChange(event.Name)
+//	        case err := <-w.Errors:
+//	            return err
+//	        }
+//	    }
+//	}
+type FileWatcher interface {
+	Watch(ctx context.Context, paths []string, onChange func(path string)) error
+}
+
+// FileWatcherFunc adapts a function to the FileWatcher interface.
+type FileWatcherFunc func(ctx context.Context, paths []string, onChange func(path string)) error
+
+// Watch calls f.
+func (f FileWatcherFunc) Watch(ctx context.Context, paths []string, onChange func(path string)) error {
+	return f(ctx, paths, onChange)
+}
+
+// WithFileWatcher sets the watcher used for the files of resources
+// registered with ResourceBuilder.WatchFile. Defaults to a watcher polling
+// every second.
+func WithFileWatcher(w FileWatcher) Option {
+	return func(s *Server) {
+		s.fileWatcher = w
+	}
+}
+
+// FileWatcher returns the watcher used for resource files.
+func (s *Server) FileWatcher() FileWatcher {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fileWatcher
+}
+
+// WatchFile ties the resource to files or directories on disk: while
+// WatchResources runs, a change to any of them reports the resource's URI,
+// so subscribed clients are notified without polling. Use it for resources
+// with a fixed URI whose handler reads the files.
+//
+// Example:
+//
+//	srv.Resource("config://app").
+//	    WatchFile("config.yaml").
+//	    Handler(loadConfig)
+//
+//	go srv.WatchResources(ctx, srv.NotifyResourceUpdated)
+func (b *ResourceBuilder) WatchFile(paths ...string) *ResourceBuilder {
+	if b.err != nil {
+		return b
+	}
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			b.err = err
+			return b
+		}
+		b.resource.watchFiles = append(b.resource.watchFiles, abs)
+	}
+	return b
+}
+
+// WatchFiles returns the absolute paths of the files the resource is tied
+// to with ResourceBuilder.WatchFile.
+func (r *Resource) WatchFiles() []string {
+	return r.watchFiles
+}
+
+// watchResourceFiles watches the files of the registered resources,
+// calling onChange with the URI of each resource whose files changed.
+func (s *Server) watchResourceFiles(ctx context.Context, onChange func(uri string)) error {
+	s.mu.RLock()
+	uris := make(map[string][]string)
+	for _, r := range s.resources {
+		for _, p := range r.watchFiles {
+			uris[p] = append(uris[p], r.uriTemplate)
+		}
+	}
+	s.mu.RUnlock()
+	if len(uris) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(uris))
+	for p := range uris {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	return s.FileWatcher().Watch(ctx, paths, func(changed string) {
+		var notified []string
+		for _, p := range paths {
+			if changed != p && !strings.HasPrefix(changed, p+string(filepath.Separator)) {
+				continue
+			}
+			for _, uri := range uris[p] {
+				if !slices.Contains(notified, uri) {
+					notified = append(notified, uri)
+					onChange(uri)
+				}
+			}
+		}
+	})
+}
+
+// PollingWatcher is a FileWatcher that periodically compares the
+// modification times and sizes of the watched files. It needs no native
+// file system events, so it works everywhere, including network mounts.
+type PollingWatcher struct {
+	interval time.Duration
+}
+
+// NewPollingWatcher creates a watcher checking for changes every interval.
+// An interval of zero or less defaults to one second.
+func NewPollingWatcher(interval time.Duration) *PollingWatcher {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &PollingWatcher{interval: interval}
+}
+
+// fileState identifies a version of a file.
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// Watch polls paths until ctx is done.
+func (w *PollingWatcher) Watch(ctx context.Context, paths []string, onChange func(path string)) error {
+	prev := scanFiles(paths)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cur := scanFiles(paths)
+		var changed []string
+		for p, state := range cur {
+			if old, ok := prev[p]; !ok || old != state {
+				changed = append(changed, p)
+			}
+		}
+		for p := range prev {
+			if _, ok := cur[p]; !ok {
+				changed = append(changed, p)
+			}
+		}
+		slices.Sort(changed)
+		for _, p := range changed {
+			onChange(p)
+		}
+		prev = cur
+	}
+}
+
+// scanFiles returns the state of every regular file under paths. Paths
+// that cannot be read are skipped, so files appearing later are reported
+// as created.
+func scanFiles(paths []string) map[string]fileState {
+	files := make(map[string]fileState)
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files[p] = fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+			return nil
+		})
+	}
+	return files
+}
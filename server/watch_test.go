@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the values passed to a change callback.
+type recorder struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+func (r *recorder) add(v string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, v)
+}
+
+// waitFor waits until want has been recorded.
+func (r *recorder) waitFor(t *testing.T, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		found := slices.Contains(r.seen, want)
+		r.mu.Unlock()
+		if found {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.Fatalf("%q not reported, got %v", want, r.seen)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollingWatcher(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	removed := filepath.Join(dir, "removed.txt")
+	writeFile(t, existing, "v1")
+	writeFile(t, removed, "v1")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var rec recorder
+	done := make(chan error, 1)
+	go func() {
+		done <- NewPollingWatcher(10*time.Millisecond).Watch(ctx, []string{dir}, rec.add)
+	}()
+	time.Sleep(30 * time.Millisecond)
+
+	writeFile(t, existing, "version 2")
+	rec.waitFor(t, existing)
+
+	created := filepath.Join(dir, "sub", "created.txt")
+	writeFile(t, created, "new")
+	rec.waitFor(t, created)
+
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	rec.waitFor(t, removed)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch() error = %v, want nil after cancel", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch() did not return after cancel")
+	}
+}
+
+func TestNewPollingWatcher_DefaultInterval(t *testing.T) {
+	if w := NewPollingWatcher(0); w.interval != defaultPollInterval {
+		t.Errorf("interval = %v, want %v", w.interval, defaultPollInterval)
+	}
+}
+
+func TestResourceBuilder_WatchFile(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	writeFile(t, config, "debug: false")
+
+	var watched []string
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithFileWatcher(FileWatcherFunc(
+		func(ctx context.Context, paths []string, onChange func(path string)) error {
+			watched = paths
+			onChange(config)
+			onChange(filepath.Join(dir, "templates", "page.html"))
+			onChange(filepath.Join(dir, "unrelated.txt"))
+			return nil
+		})))
+
+	handler := func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri}, nil
+	}
+	srv.Resource("config://app").WatchFile(config).Handler(handler)
+	srv.Resource("config://all").WatchFile(config, filepath.Join(dir, "templates")).Handler(handler)
+	srv.Resource("config://static").Handler(handler)
+
+	r, _ := srv.GetResource("config://app")
+	if got := r.WatchFiles(); len(got) != 1 || got[0] != config {
+		t.Errorf("WatchFiles() = %v, want [%s]", got, config)
+	}
+
+	var rec recorder
+	if err := srv.WatchResources(context.Background(), rec.add); err != nil {
+		t.Fatalf("WatchResources() error = %v", err)
+	}
+	if len(watched) != 2 {
+		t.Errorf("watched paths = %v, want the config file and templates directory", watched)
+	}
+	slices.Sort(rec.seen)
+	want := []string{"config://all", "config://all", "config://app"}
+	if !slices.Equal(rec.seen, want) {
+		t.Errorf("reported URIs = %v, want %v", rec.seen, want)
+	}
+}
+
+func TestWatchResources_NotifiesSubscribers(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	writeFile(t, config, "debug: false")
+
+	srv := New(Info{Name: "test", Version: "1.0.0"}, WithFileWatcher(NewPollingWatcher(10*time.Millisecond)))
+	srv.Resource("config://app").WatchFile(config).Handler(func(ctx context.Context, uri string, params map[string]string) (*ResourceContent, error) {
+		return &ResourceContent{URI: uri}, nil
+	})
+
+	var rec recorder
+	defer srv.OnResourceUpdated(rec.add)()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.WatchResources(ctx, srv.NotifyResourceUpdated)
+	time.Sleep(30 * time.Millisecond)
+
+	writeFile(t, config, "debug: true")
+	rec.waitFor(t, "config://app")
+}
+
+func TestFileSystemProvider_Watch(t *testing.T) {
+	dir := newWorkspace(t)
+
+	t.Run("reports file URIs", func(t *testing.T) {
+		p, err := NewFileSystemProvider(dir, WithFileSystemWatcher(NewPollingWatcher(10*time.Millisecond)))
+		if err != nil {
+			t.Fatalf("NewFileSystemProvider() error = %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var rec recorder
+		go p.Watch(ctx, rec.add)
+		time.Sleep(30 * time.Millisecond)
+
+		writeFile(t, filepath.Join(dir, "src", "util", "util.go"), "package util // changed")
+		rec.waitFor(t, fileURI(filepath.Join(dir, "src", "util", "util.go")))
+	})
+
+	t.Run("ignores paths outside the directory", func(t *testing.T) {
+		outside := filepath.Join(filepath.Dir(dir), "other.txt")
+		p, err := NewFileSystemProvider(dir, WithFileSystemWatcher(FileWatcherFunc(
+			func(ctx context.Context, paths []string, onChange func(path string)) error {
+				onChange(outside)
+				onChange(filepath.Join(dir, "README.md"))
+				return nil
+			})))
+		if err != nil {
+			t.Fatalf("NewFileSystemProvider() error = %v", err)
+		}
+		var rec recorder
+		if err := p.Watch(context.Background(), rec.add); err != nil {
+			t.Fatalf("Watch() error = %v", err)
+		}
+		if len(rec.seen) != 1 || rec.seen[0] != fileURI(filepath.Join(dir, "README.md")) {
+			t.Errorf("reported URIs = %v, want only README.md", rec.seen)
+		}
+	})
+
+	t.Run("custom file system without watcher", func(t *testing.T) {
+		p, err := NewFileSystemProvider(dir, WithFileSystemFS(os.DirFS(dir)))
+		if err != nil {
+			t.Fatalf("NewFileSystemProvider() error = %v", err)
+		}
+		if err := p.Watch(context.Background(), func(string) {}); err != nil {
+			t.Errorf("Watch() error = %v, want nil", err)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+)
+
+// sessionID is the ID of the session a TestClient opens.
+const sessionID = "testutil-session"
+
+// SamplingHandler answers sampling requests the server sends to a TestClient.
+type SamplingHandler func(ctx context.Context, req *server.CreateMessageRequest) (*server.CreateMessageResult, error)
+
+// Option configures a TestClient.
+type Option func(*clientConfig)
+
+// clientConfig is what a TestClient declares and answers as an MCP client.
+type clientConfig struct {
+	name     string
+	version  string
+	sampling bool
+	tools    bool
+	handler  SamplingHandler
+	roots    []server.Root
+	hasRoots bool
+}
+
+// WithClientInfo sets the client name and version sent on initialize.
+func WithClientInfo(name, version string) Option {
+	return func(c *clientConfig) {
+		c.name = name
+		c.version = version
+	}
+}
+
+// WithClientSampling declares the sampling capability. Sampling requests
+// fail unless a handler is set with WithSamplingHandler.
+func WithClientSampling() Option {
+	return func(c *clientConfig) {
+		c.sampling = true
+	}
+}
+
+// WithClientSamplingTools declares the sampling capability with support
+// for tools in sampling requests.
+func WithClientSamplingTools() Option {
+	return func(c *clientConfig) {
+		c.sampling = true
+		c.tools = true
+	}
+}
+
+// WithSamplingHandler declares the sampling capability and answers
+// sampling requests with fn.
+func WithSamplingHandler(fn SamplingHandler) Option {
+	return func(c *clientConfig) {
+		c.sampling = true
+		c.handler = fn
+	}
+}
+
+// WithClientRoots declares the roots capability and answers roots/list
+// with roots.
+func WithClientRoots(roots ...server.Root) Option {
+	return func(c *clientConfig) {
+		c.hasRoots = true
+		c.roots = roots
+	}
+}
+
+// capabilities returns the client capabilities sent on initialize.
+func (c *clientConfig) capabilities() protocol.ClientCapabilities {
+	var caps protocol.ClientCapabilities
+	if c.sampling {
+		caps.Sampling = &protocol.SamplingCapability{}
+		if c.tools {
+			caps.Sampling.Tools = &protocol.SamplingToolsCapability{}
+		}
+	}
+	if c.hasRoots {
+		caps.Roots = &protocol.RootsCapability{ListChanged: true}
+	}
+	return caps
+}
+
+// clientConn plays the client side of a TestClient's connection: it answers
+// requests the server sends and records its notifications.
+type clientConn struct {
+	config *clientConfig
+
+	mu            sync.Mutex
+	notifications []*protocol.Request
+}
+
+// newClientConn creates the client side of a connection configured by opts.
+func newClientConn(opts []Option) *clientConn {
+	config := &clientConfig{name: "test-client", version: "1.0.0"}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &clientConn{config: config}
+}
+
+// SendRequest answers a server-initiated request.
+func (c *clientConn) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	switch req.Method {
+	case protocol.MethodPing:
+		return protocol.NewResponse(req.ID, protocol.EmptyResult{}), nil
+	case protocol.MethodRootsList:
+		if !c.config.hasRoots {
+			return protocol.NewErrorResponse(req.ID, protocol.NewMethodNotFound(req.Method)), nil
+		}
+		roots := c.config.roots
+		if roots == nil {
+			roots = []server.Root{}
+		}
+		return protocol.NewResponse(req.ID, server.ListRootsResult{Roots: roots}), nil
+	case protocol.MethodSamplingCreateMessage:
+		if c.config.handler == nil {
+			return protocol.NewErrorResponse(req.ID, protocol.NewMethodNotFound(req.Method)), nil
+		}
+		var params server.CreateMessageRequest
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return protocol.NewErrorResponse(req.ID, protocol.NewInvalidParams(err.Error())), nil
+		}
+		result, err := c.config.handler(ctx, &params)
+		if err != nil {
+			return protocol.NewErrorResponse(req.ID, protocol.NewInternalError(err.Error())), nil
+		}
+		return protocol.NewResponse(req.ID, result), nil
+	default:
+		return protocol.NewErrorResponse(req.ID, protocol.NewMethodNotFound(req.Method)), nil
+	}
+}
+
+// SendNotification records a notification from the server.
+func (c *clientConn) SendNotification(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshal notification params: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications = append(c.notifications, &protocol.Request{
+		JSONRPC: protocol.JSONRPCVersion,
+		Method:  method,
+		Params:  data,
+	})
+	return nil
+}
+
+// Notifications returns the notifications received so far.
+func (c *clientConn) Notifications() []*protocol.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*protocol.Request(nil), c.notifications...)
+}
@@ -0,0 +1,313 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+// RunSubscriptionStoreContract verifies that a server.SubscriptionStore
+// implementation behaves like the built-in stores. newStore must return an
+// empty store each time it is called.
+//
+// Example:
+//
+//	func TestRedisSubscriptionStore(t *testing.T) {
+//	    testutil.RunSubscriptionStoreContract(t, func(t *testing.T) server.SubscriptionStore {
+//	        return newRedisStore(t)
+//	    })
+//	}
+func RunSubscriptionStoreContract(t *testing.T, newStore func(t *testing.T) server.SubscriptionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("empty", func(t *testing.T) {
+		store := newStore(t)
+		assertSubscribers(t, store, "file:///a", nil)
+		assertCount(t, store, 0)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.Subscribe(ctx, "s2", "file:///a"))
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///a"))
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///b"))
+
+		assertSubscribers(t, store, "file:///a", []string{"s1", "s2"})
+		assertSubscribers(t, store, "file:///b", []string{"s1"})
+		assertCount(t, store, 3)
+	})
+
+	t.Run("subscribe is idempotent", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///a"))
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///a"))
+
+		assertSubscribers(t, store, "file:///a", []string{"s1"})
+		assertCount(t, store, 1)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///a"))
+		mustStore(t, store.Subscribe(ctx, "s2", "file:///a"))
+		mustStore(t, store.Unsubscribe(ctx, "s1", "file:///a"))
+		mustStore(t, store.Unsubscribe(ctx, "s3", "file:///missing"))
+
+		assertSubscribers(t, store, "file:///a", []string{"s2"})
+		assertCount(t, store, 1)
+	})
+
+	t.Run("unsubscribe all", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///a"))
+		mustStore(t, store.Subscribe(ctx, "s1", "file:///b"))
+		mustStore(t, store.Subscribe(ctx, "s2", "file:///b"))
+		mustStore(t, store.UnsubscribeAll(ctx, "s1"))
+
+		assertSubscribers(t, store, "file:///a", nil)
+		assertSubscribers(t, store, "file:///b", []string{"s2"})
+		assertCount(t, store, 1)
+	})
+}
+
+// RunSessionStoreContract verifies that a server.SessionStore
+// implementation behaves like the built-in stores. newStore must return an
+// empty store each time it is called.
+func RunSessionStoreContract(t *testing.T, newStore func(t *testing.T) server.SessionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	state := server.SessionState{
+		ID:       "s1",
+		LogLevel: server.LogLevelWarning,
+		ClientCapabilities: server.ClientCapabilities{
+			Sampling: true,
+			Roots:    &server.RootsCapability{ListChanged: true},
+		},
+		Roots:      []server.Root{{URI: "file:///workspace", Name: "workspace"}},
+		ClientInfo: &server.ClientInfo{Name: "claude-desktop", Version: "0.9.0", ProtocolVersion: "2025-06-18"},
+	}
+
+	t.Run("load missing", func(t *testing.T) {
+		store := newStore(t)
+		if _, err := store.LoadSession(ctx, "missing"); !errors.Is(err, server.ErrSessionNotFound) {
+			t.Errorf("LoadSession() error = %v, want ErrSessionNotFound", err)
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveSession(ctx, state))
+
+		got, err := store.LoadSession(ctx, "s1")
+		mustStore(t, err)
+		assertSessionState(t, got, state)
+	})
+
+	t.Run("save replaces", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveSession(ctx, state))
+
+		updated := state
+		updated.LogLevel = server.LogLevelDebug
+		updated.Roots = nil
+		mustStore(t, store.SaveSession(ctx, updated))
+
+		got, err := store.LoadSession(ctx, "s1")
+		mustStore(t, err)
+		assertSessionState(t, got, updated)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveSession(ctx, state))
+		mustStore(t, store.DeleteSession(ctx, "s1"))
+		mustStore(t, store.DeleteSession(ctx, "missing"))
+
+		if _, err := store.LoadSession(ctx, "s1"); !errors.Is(err, server.ErrSessionNotFound) {
+			t.Errorf("LoadSession() after delete error = %v, want ErrSessionNotFound", err)
+		}
+	})
+}
+
+// RunHTTPSessionStoreContract verifies that a transport.SessionStore
+// implementation behaves like the built-in stores. newStore must return an
+// empty store each time it is called. Stores that also implement
+// server.SessionStore must keep the state of a session created twice.
+func RunHTTPSessionStoreContract(t *testing.T, newStore func(t *testing.T) transport.SessionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("missing", func(t *testing.T) {
+		store := newStore(t)
+		exists, err := store.SessionExists(ctx, "missing")
+		mustStore(t, err)
+		if exists {
+			t.Error("SessionExists() of a missing session = true, want false")
+		}
+	})
+
+	t.Run("create and delete", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.CreateSession(ctx, "s1"))
+		exists, err := store.SessionExists(ctx, "s1")
+		mustStore(t, err)
+		if !exists {
+			t.Error("SessionExists() after create = false, want true")
+		}
+
+		mustStore(t, store.DeleteSession(ctx, "s1"))
+		mustStore(t, store.DeleteSession(ctx, "missing"))
+		exists, err = store.SessionExists(ctx, "s1")
+		mustStore(t, err)
+		if exists {
+			t.Error("SessionExists() after delete = true, want false")
+		}
+	})
+
+	t.Run("create keeps state", func(t *testing.T) {
+		store := newStore(t)
+		states, ok := store.(server.SessionStore)
+		if !ok {
+			t.Skip("store does not persist session state")
+		}
+		state := server.SessionState{ID: "s1", LogLevel: server.LogLevelDebug}
+		mustStore(t, states.SaveSession(ctx, state))
+		mustStore(t, store.CreateSession(ctx, "s1"))
+
+		got, err := states.LoadSession(ctx, "s1")
+		mustStore(t, err)
+		assertSessionState(t, got, state)
+	})
+}
+
+// RunTaskStoreContract verifies that a server.TaskStore implementation
+// behaves like the built-in stores. newStore must return an empty store
+// each time it is called.
+func RunTaskStoreContract(t *testing.T, newStore func(t *testing.T) server.TaskStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	total := 4.0
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	task := server.TaskInfo{
+		ID:        "t1",
+		Tool:      "reindex",
+		Status:    server.TaskWorking,
+		Progress:  1,
+		Total:     &total,
+		Message:   "shard 1",
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+
+	t.Run("load missing", func(t *testing.T) {
+		store := newStore(t)
+		if _, err := store.LoadTask(ctx, "missing"); !errors.Is(err, server.ErrTaskNotFound) {
+			t.Errorf("LoadTask() error = %v, want ErrTaskNotFound", err)
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveTask(ctx, task))
+
+		got, err := store.LoadTask(ctx, "t1")
+		mustStore(t, err)
+		assertTask(t, got, task)
+	})
+
+	t.Run("save replaces", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveTask(ctx, task))
+
+		updated := task
+		updated.Status = server.TaskCompleted
+		updated.Result = &protocol.CallToolResult{Content: []protocol.Content{{Type: "text", Text: "done"}}}
+		updated.UpdatedAt = created.Add(time.Minute)
+		mustStore(t, store.SaveTask(ctx, updated))
+
+		got, err := store.LoadTask(ctx, "t1")
+		mustStore(t, err)
+		assertTask(t, got, updated)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		mustStore(t, store.SaveTask(ctx, task))
+		mustStore(t, store.DeleteTask(ctx, "t1"))
+		mustStore(t, store.DeleteTask(ctx, "missing"))
+
+		if _, err := store.LoadTask(ctx, "t1"); !errors.Is(err, server.ErrTaskNotFound) {
+			t.Errorf("LoadTask() after delete error = %v, want ErrTaskNotFound", err)
+		}
+	})
+}
+
+func mustStore(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("store error: %v", err)
+	}
+}
+
+func assertSubscribers(t *testing.T, store server.SubscriptionStore, uri string, want []string) {
+	t.Helper()
+	got, err := store.Subscribers(context.Background(), uri)
+	mustStore(t, err)
+	if len(got) != len(want) || !slices.Equal(got, want) {
+		t.Errorf("Subscribers(%q) = %v, want %v", uri, got, want)
+	}
+}
+
+func assertCount(t *testing.T, store server.SubscriptionStore, want int) {
+	t.Helper()
+	got, err := store.Count(context.Background())
+	mustStore(t, err)
+	if got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+}
+
+func assertSessionState(t *testing.T, got, want server.SessionState) {
+	t.Helper()
+	if got.ID != want.ID || got.LogLevel != want.LogLevel {
+		t.Errorf("state = %+v, want %+v", got, want)
+	}
+	if got.ClientCapabilities.Sampling != want.ClientCapabilities.Sampling ||
+		(got.ClientCapabilities.Roots == nil) != (want.ClientCapabilities.Roots == nil) {
+		t.Errorf("capabilities = %+v, want %+v", got.ClientCapabilities, want.ClientCapabilities)
+	}
+	if !slices.Equal(got.Roots, want.Roots) {
+		t.Errorf("roots = %v, want %v", got.Roots, want.Roots)
+	}
+	if (got.ClientInfo == nil) != (want.ClientInfo == nil) ||
+		got.ClientInfo != nil && (got.ClientInfo.Name != want.ClientInfo.Name || got.ClientInfo.Version != want.ClientInfo.Version) {
+		t.Errorf("client info = %+v, want %+v", got.ClientInfo, want.ClientInfo)
+	}
+}
+
+func assertTask(t *testing.T, got, want server.TaskInfo) {
+	t.Helper()
+	if got.ID != want.ID || got.Tool != want.Tool || got.Status != want.Status ||
+		got.Progress != want.Progress || got.Message != want.Message || got.Error != want.Error {
+		t.Errorf("task = %+v, want %+v", got, want)
+	}
+	if (got.Total == nil) != (want.Total == nil) || (got.Total != nil && *got.Total != *want.Total) {
+		t.Errorf("total = %v, want %v", got.Total, want.Total)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("times = %v, %v, want %v, %v", got.CreatedAt, got.UpdatedAt, want.CreatedAt, want.UpdatedAt)
+	}
+	if (got.Result == nil) != (want.Result == nil) ||
+		(got.Result != nil && (len(got.Result.Content) != len(want.Result.Content) || got.Result.Content[0].Text != want.Result.Content[0].Text)) {
+		t.Errorf("result = %+v, want %+v", got.Result, want.Result)
+	}
+}
@@ -0,0 +1,48 @@
+package testutil_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/testutil"
+	"github.com/felixgeelhaar/mcp-go/transport"
+)
+
+func TestMemoryStoreContract(t *testing.T) {
+	testutil.RunSubscriptionStoreContract(t, func(t *testing.T) server.SubscriptionStore {
+		return server.NewMemoryStore()
+	})
+	testutil.RunSessionStoreContract(t, func(t *testing.T) server.SessionStore {
+		return server.NewMemoryStore()
+	})
+	testutil.RunTaskStoreContract(t, func(t *testing.T) server.TaskStore {
+		return server.NewMemoryStore()
+	})
+	testutil.RunHTTPSessionStoreContract(t, func(t *testing.T) transport.SessionStore {
+		return server.NewMemoryStore()
+	})
+}
+
+func TestFileStoreContract(t *testing.T) {
+	newStore := func(t *testing.T) *server.FileStore {
+		store, err := server.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+		return store
+	}
+
+	testutil.RunSubscriptionStoreContract(t, func(t *testing.T) server.SubscriptionStore {
+		return newStore(t)
+	})
+	testutil.RunSessionStoreContract(t, func(t *testing.T) server.SessionStore {
+		return newStore(t)
+	})
+	testutil.RunTaskStoreContract(t, func(t *testing.T) server.TaskStore {
+		return newStore(t)
+	})
+	testutil.RunHTTPSessionStoreContract(t, func(t *testing.T) transport.SessionStore {
+		return newStore(t)
+	})
+}
@@ -23,6 +23,7 @@ package testutil
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/felixgeelhaar/mcp-go/protocol"
 	"github.com/felixgeelhaar/mcp-go/server"
+	"github.com/felixgeelhaar/mcp-go/server/handler"
 	"github.com/felixgeelhaar/mcp-go/transport"
 )
 
@@ -41,17 +43,36 @@ type TestClient struct {
 	handler transport.Handler
 	reqID   int64
 	mu      sync.Mutex
+
+	// Client side of the connection and the in-memory server handler
+	conn     *clientConn
+	sessions *handler.Handler
 }
 
-// NewTestClient creates a new test client for the given server.
-func NewTestClient(t testing.TB, srv *server.Server) *TestClient {
+// NewTestClient creates a new test client for the given server and
+// initializes it. Like a client on a real transport, it declares its
+// capabilities on initialize and gets a session that handlers can use to
+// send sampling and roots requests, which the options configure how to
+// answer.
+//
+// Example:
+//
+//	tc := testutil.NewTestClient(t, srv,
+//	    testutil.WithSamplingHandler(func(ctx context.Context, req *server.CreateMessageRequest) (*server.CreateMessageResult, error) {
+//	        return &server.CreateMessageResult{Role: server.RoleAssistant, Content: server.NewTextContent("summary"), Model: "test"}, nil
+//	    }),
+//	    testutil.WithClientRoots(server.Root{URI: "file:///workspace"}),
+//	)
+func NewTestClient(t testing.TB, srv *server.Server, opts ...Option) *TestClient {
 	t.Helper()
 
-	handler := &requestHandler{srv: srv}
+	h := handler.New(srv)
 	tc := &TestClient{
-		t:       t,
-		srv:     srv,
-		handler: handler,
+		t:        t,
+		srv:      srv,
+		handler:  h,
+		conn:     newClientConn(opts),
+		sessions: h,
 	}
 
 	// Initialize the server
@@ -65,12 +86,30 @@ func NewTestClient(t testing.TB, srv *server.Server) *TestClient {
 
 // NewTestClientWithHandler creates a test client with a custom handler.
 // This is useful for testing middleware.
-func NewTestClientWithHandler(t testing.TB, handler transport.Handler) *TestClient {
+// The options configure what Initialize declares and how server requests
+// are answered.
+func NewTestClientWithHandler(t testing.TB, handler transport.Handler, opts ...Option) *TestClient {
 	t.Helper()
 	return &TestClient{
 		t:       t,
 		handler: handler,
+		conn:    newClientConn(opts),
+	}
+}
+
+// Session returns the session the server created for the client on
+// initialize, or nil for clients created with NewTestClientWithHandler.
+func (tc *TestClient) Session() *server.Session {
+	if tc.sessions == nil {
+		return nil
 	}
+	return tc.sessions.Session(sessionID)
+}
+
+// Notifications returns the notifications the server sent to the client
+// outside of CallToolWithProgress, such as log messages and list changes.
+func (tc *TestClient) Notifications() []*protocol.Request {
+	return tc.conn.Notifications()
 }
 
 // Close closes the test client (no-op for now, but good for future cleanup).
@@ -89,6 +128,12 @@ func (tc *TestClient) nextID() json.RawMessage {
 // SendRequest sends a raw request and returns the response.
 func (tc *TestClient) SendRequest(method string, params any) (*protocol.Response, error) {
 	tc.t.Helper()
+	return tc.sendRequest(context.Background(), method, params)
+}
+
+// sendRequest sends a raw request with the given context.
+func (tc *TestClient) sendRequest(ctx context.Context, method string, params any) (*protocol.Response, error) {
+	tc.t.Helper()
 
 	var paramsData json.RawMessage
 	if params != nil {
@@ -106,23 +151,59 @@ func (tc *TestClient) SendRequest(method string, params any) (*protocol.Response
 		Params:  paramsData,
 	}
 
-	resp, err := tc.handler.HandleRequest(context.Background(), req)
+	resp, err := tc.handler.HandleRequest(tc.connContext(ctx), req)
 	if err != nil {
 		return nil, err
 	}
+	if resp != nil && resp.Result != nil {
+		// Hand out the result as a client sees it after decoding the JSON
+		data, err := json.Marshal(resp.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
+		}
+		var result any
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+		resp.Result = result
+	}
 
 	return resp, nil
 }
 
-// Initialize sends an initialize request to the server.
+// sendNotification sends a notification to the server. As on a real
+// transport, the client sees no response or error.
+func (tc *TestClient) sendNotification(method string) {
+	_, _ = tc.handler.HandleRequest(tc.connContext(context.Background()), &protocol.Request{
+		JSONRPC: protocol.JSONRPCVersion,
+		Method:  method,
+	})
+}
+
+// connContext attaches the client connection to ctx, as a transport does,
+// so the server can send requests and notifications back.
+func (tc *TestClient) connContext(ctx context.Context) context.Context {
+	if tc.sessions != nil {
+		ctx = transport.ContextWithConnectionID(ctx, sessionID)
+	}
+	ctx = transport.ContextWithRequestSender(ctx, tc.conn)
+	if transport.NotificationSenderFromContext(ctx) == nil {
+		ctx = transport.ContextWithNotificationSender(ctx, tc.conn)
+	}
+	return ctx
+}
+
+// Initialize sends an initialize request to the server, followed by the
+// notifications/initialized notification once it succeeds.
 func (tc *TestClient) Initialize() (map[string]any, error) {
 	tc.t.Helper()
 
-	resp, err := tc.SendRequest(protocol.MethodInitialize, map[string]any{
-		"protocolVersion": protocol.MCPVersion,
-		"clientInfo": map[string]any{
-			"name":    "test-client",
-			"version": "1.0.0",
+	resp, err := tc.SendRequest(protocol.MethodInitialize, protocol.InitializeParams{
+		ProtocolVersion: protocol.MCPVersion,
+		Capabilities:    tc.conn.config.capabilities(),
+		ClientInfo: protocol.Implementation{
+			Name:    tc.conn.config.name,
+			Version: tc.conn.config.version,
 		},
 	})
 	if err != nil {
@@ -136,6 +217,7 @@ func (tc *TestClient) Initialize() (map[string]any, error) {
 	if !ok {
 		return nil, fmt.Errorf("unexpected result type: %T", resp.Result)
 	}
+	tc.sendNotification(protocol.MethodInitialized)
 
 	return result, nil
 }
@@ -175,6 +257,7 @@ func (tc *TestClient) ListTools() ([]map[string]any, error) {
 }
 
 // CallTool calls a tool with the given arguments and returns the text result.
+// A result with isError set is returned as a *server.ToolError.
 func (tc *TestClient) CallTool(name string, args any) (string, error) {
 	tc.t.Helper()
 
@@ -185,6 +268,41 @@ func (tc *TestClient) CallTool(name string, args any) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return toolResultText(resp)
+}
+
+// CallToolWithProgress calls a tool with a progress token and returns the
+// text result along with the progress updates the handler reported through
+// server.ProgressFromContext, in the order they were sent.
+//
+// Example:
+//
+//	text, updates, err := tc.CallToolWithProgress("import", map[string]any{"rows": 3})
+//	require.NoError(t, err)
+//	assert.Len(t, updates, 3)
+//	assert.Equal(t, 3.0, updates[2].Progress)
+func (tc *TestClient) CallToolWithProgress(name string, args any) (string, []server.Progress, error) {
+	tc.t.Helper()
+
+	recorder := &progressRecorder{}
+	ctx := transport.ContextWithNotificationSender(context.Background(), recorder)
+	resp, err := tc.sendRequest(ctx, protocol.MethodToolsCall, map[string]any{
+		"name":      name,
+		"arguments": args,
+		"_meta": map[string]any{
+			"progressToken": "testutil-progress",
+		},
+	})
+	if err != nil {
+		return "", recorder.Updates(), err
+	}
+	text, err := toolResultText(resp)
+	return text, recorder.Updates(), err
+}
+
+// toolResultText returns the text of the first content block of a
+// tools/call response.
+func toolResultText(resp *protocol.Response) (string, error) {
 	if resp.Error != nil {
 		return "", resp.Error
 	}
@@ -216,9 +334,93 @@ func (tc *TestClient) CallTool(name string, args any) (string, error) {
 	}
 
 	text, _ := first["text"].(string)
+	if isError, _ := result["isError"].(bool); isError {
+		return "", server.NewToolError(text)
+	}
 	return text, nil
 }
 
+// CallToolContent calls a tool and returns all content blocks of the result,
+// including image and audio blocks. A result with isError set is returned
+// as a *server.ToolError.
+func (tc *TestClient) CallToolContent(name string, args any) ([]protocol.Content, error) {
+	tc.t.Helper()
+
+	resp, err := tc.CallToolRaw(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var result protocol.CallToolResult
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		var msg string
+		if len(result.Content) > 0 {
+			msg = result.Content[0].Text
+		}
+		return nil, server.NewToolError(msg)
+	}
+	return result.Content, nil
+}
+
+// AssertMediaContent checks that c is a media block of the given type
+// ("image" or "audio") and MIME type whose base64 data decodes to want.
+func AssertMediaContent(t testing.TB, c protocol.Content, contentType, mimeType string, want []byte) {
+	t.Helper()
+
+	if c.Type != contentType {
+		t.Errorf("content type = %q, want %q", c.Type, contentType)
+	}
+	if c.MimeType != mimeType {
+		t.Errorf("MIME type = %q, want %q", c.MimeType, mimeType)
+	}
+	got, err := base64.StdEncoding.DecodeString(c.Data)
+	if err != nil {
+		t.Errorf("content data is not base64: %v", err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("content data = %q, want %q", got, want)
+	}
+}
+
+// progressRecorder is a notification sender that records progress
+// notifications.
+type progressRecorder struct {
+	mu      sync.Mutex
+	updates []server.Progress
+}
+
+// SendNotification records params if method is a progress notification.
+func (r *progressRecorder) SendNotification(method string, params any) error {
+	if method != protocol.MethodProgress {
+		return nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	var update server.Progress
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, update)
+	return nil
+}
+
+// Updates returns the recorded progress updates.
+func (r *progressRecorder) Updates() []server.Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]server.Progress(nil), r.updates...)
+}
+
 // CallToolRaw calls a tool and returns the raw response.
 func (tc *TestClient) CallToolRaw(name string, args any) (*protocol.Response, error) {
 	tc.t.Helper()
@@ -263,6 +465,40 @@ func (tc *TestClient) ListResources() ([]map[string]any, error) {
 	return resourceMaps, nil
 }
 
+// ListResourceTemplates lists all available resource templates.
+func (tc *TestClient) ListResourceTemplates() ([]map[string]any, error) {
+	tc.t.Helper()
+
+	resp, err := tc.SendRequest(protocol.MethodResourcesTemplatesList, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	result, ok := resp.Result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", resp.Result)
+	}
+
+	// Handle both []any (from JSON) and []map[string]any (from direct call)
+	var templateMaps []map[string]any
+	switch v := result["resourceTemplates"].(type) {
+	case []any:
+		templateMaps = make([]map[string]any, len(v))
+		for i, r := range v {
+			templateMaps[i], _ = r.(map[string]any)
+		}
+	case []map[string]any:
+		templateMaps = v
+	default:
+		return nil, fmt.Errorf("unexpected resourceTemplates type: %T", result["resourceTemplates"])
+	}
+
+	return templateMaps, nil
+}
+
 // ReadResource reads a resource by URI.
 func (tc *TestClient) ReadResource(uri string) (string, error) {
 	tc.t.Helper()
@@ -379,215 +615,6 @@ func (tc *TestClient) Ping() error {
 	return nil
 }
 
-// requestHandler adapts Server to transport.Handler for in-memory testing.
-type requestHandler struct {
-	srv *server.Server
-}
-
-func (h *requestHandler) HandleRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	switch req.Method {
-	case protocol.MethodInitialize:
-		return h.handleInitialize(req)
-	case protocol.MethodToolsList:
-		return h.handleToolsList(req)
-	case protocol.MethodToolsCall:
-		return h.handleToolsCall(ctx, req)
-	case protocol.MethodResourcesList:
-		return h.handleResourcesList(req)
-	case protocol.MethodResourcesRead:
-		return h.handleResourcesRead(ctx, req)
-	case protocol.MethodPromptsList:
-		return h.handlePromptsList(req)
-	case protocol.MethodPromptsGet:
-		return h.handlePromptsGet(ctx, req)
-	case protocol.MethodPing:
-		return protocol.NewResponse(req.ID, map[string]any{}), nil
-	default:
-		return nil, protocol.NewMethodNotFound(req.Method)
-	}
-}
-
-func (h *requestHandler) handleInitialize(req *protocol.Request) (*protocol.Response, error) {
-	manifest := h.srv.Manifest()
-
-	capabilities := make(map[string]any)
-	if manifest.Capabilities.Tools {
-		capabilities["tools"] = map[string]any{}
-	}
-	if manifest.Capabilities.Resources {
-		capabilities["resources"] = map[string]any{}
-	}
-	if manifest.Capabilities.Prompts {
-		capabilities["prompts"] = map[string]any{}
-	}
-
-	result := map[string]any{
-		"protocolVersion": manifest.ProtocolVersion,
-		"serverInfo": map[string]any{
-			"name":    manifest.Name,
-			"version": manifest.Version,
-		},
-		"capabilities": capabilities,
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handleToolsList(req *protocol.Request) (*protocol.Response, error) {
-	tools := h.srv.Tools()
-
-	toolList := make([]map[string]any, 0, len(tools))
-	for _, t := range tools {
-		toolList = append(toolList, map[string]any{
-			"name":        t.Name,
-			"description": t.Description,
-			"inputSchema": t.InputSchema,
-		})
-	}
-
-	return protocol.NewResponse(req.ID, map[string]any{"tools": toolList}), nil
-}
-
-func (h *requestHandler) handleToolsCall(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	var params struct {
-		Name      string          `json:"name"`
-		Arguments json.RawMessage `json:"arguments"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	tool, ok := h.srv.GetTool(params.Name)
-	if !ok {
-		return nil, protocol.NewNotFound("tool not found: " + params.Name)
-	}
-
-	result, err := tool.Execute(ctx, params.Arguments)
-	if err != nil {
-		return nil, err
-	}
-
-	response := map[string]any{
-		"content": []map[string]any{
-			{"type": "text", "text": result},
-		},
-	}
-
-	return protocol.NewResponse(req.ID, response), nil
-}
-
-func (h *requestHandler) handleResourcesList(req *protocol.Request) (*protocol.Response, error) {
-	resources := h.srv.Resources()
-
-	resourceList := make([]map[string]any, 0, len(resources))
-	for _, r := range resources {
-		item := map[string]any{
-			"uri":  r.URITemplate,
-			"name": r.Name,
-		}
-		if r.Description != "" {
-			item["description"] = r.Description
-		}
-		if r.MimeType != "" {
-			item["mimeType"] = r.MimeType
-		}
-		resourceList = append(resourceList, item)
-	}
-
-	return protocol.NewResponse(req.ID, map[string]any{"resources": resourceList}), nil
-}
-
-func (h *requestHandler) handleResourcesRead(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	var params struct {
-		URI string `json:"uri"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	resource, ok := h.srv.FindResourceForURI(params.URI)
-	if !ok {
-		return nil, protocol.NewNotFound("resource not found: " + params.URI)
-	}
-
-	content, err := resource.Read(ctx, params.URI)
-	if err != nil {
-		return nil, err
-	}
-
-	result := map[string]any{
-		"contents": []map[string]any{
-			{
-				"uri":      content.URI,
-				"mimeType": content.MimeType,
-				"text":     content.Text,
-			},
-		},
-	}
-
-	return protocol.NewResponse(req.ID, result), nil
-}
-
-func (h *requestHandler) handlePromptsList(req *protocol.Request) (*protocol.Response, error) {
-	prompts := h.srv.Prompts()
-
-	promptList := make([]map[string]any, 0, len(prompts))
-	for _, p := range prompts {
-		item := map[string]any{
-			"name": p.Name,
-		}
-		if p.Description != "" {
-			item["description"] = p.Description
-		}
-		if len(p.Arguments) > 0 {
-			args := make([]map[string]any, 0, len(p.Arguments))
-			for _, arg := range p.Arguments {
-				argItem := map[string]any{
-					"name":     arg.Name,
-					"required": arg.Required,
-				}
-				if arg.Description != "" {
-					argItem["description"] = arg.Description
-				}
-				args = append(args, argItem)
-			}
-			item["arguments"] = args
-		}
-		promptList = append(promptList, item)
-	}
-
-	return protocol.NewResponse(req.ID, map[string]any{"prompts": promptList}), nil
-}
-
-func (h *requestHandler) handlePromptsGet(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
-	var params struct {
-		Name      string            `json:"name"`
-		Arguments map[string]string `json:"arguments"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return nil, protocol.NewInvalidParams(err.Error())
-	}
-
-	prompt, ok := h.srv.GetPrompt(params.Name)
-	if !ok {
-		return nil, protocol.NewNotFound("prompt not found: " + params.Name)
-	}
-
-	result, err := prompt.Get(ctx, params.Arguments)
-	if err != nil {
-		return nil, err
-	}
-
-	response := map[string]any{
-		"messages": result.Messages,
-	}
-	if result.Description != "" {
-		response["description"] = result.Description
-	}
-
-	return protocol.NewResponse(req.ID, response), nil
-}
-
 // MockTransport is a mock transport for testing.
 type MockTransport struct {
 	in  *bytes.Buffer
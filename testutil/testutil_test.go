@@ -2,12 +2,17 @@ package testutil_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/felixgeelhaar/mcp-go"
+	"github.com/felixgeelhaar/mcp-go/protocol"
 	"github.com/felixgeelhaar/mcp-go/server"
 	"github.com/felixgeelhaar/mcp-go/testutil"
+	"github.com/felixgeelhaar/mcp-go/transport"
 )
 
 func TestTestClient_Tools(t *testing.T) {
@@ -93,7 +98,8 @@ func TestTestClient_Tools(t *testing.T) {
 			t.Fatal("expected error")
 		}
 
-		if err.Error() != "intentional error" {
+		var protoErr *protocol.Error
+		if !errors.As(err, &protoErr) || protoErr.Message != "intentional error" {
 			t.Errorf("unexpected error message: %v", err)
 		}
 	})
@@ -113,6 +119,153 @@ func TestTestClient_Tools(t *testing.T) {
 	})
 }
 
+func TestTestClient_CallToolError(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("validate").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "", mcp.NewToolError("value out of range")
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	resp, err := client.CallToolRaw("validate", struct{}{})
+	if err != nil {
+		t.Fatalf("CallToolRaw failed: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("expected result, got protocol error %v", resp.Error)
+	}
+
+	_, err = client.CallTool("validate", struct{}{})
+	var toolErr *mcp.ToolError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("error = %v, want *mcp.ToolError", err)
+	}
+	if toolErr.Message != "value out of range" {
+		t.Errorf("Message = %q, want %q", toolErr.Message, "value out of range")
+	}
+}
+
+func TestTestClient_CallToolRichResult(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("report").Handler(func(ctx context.Context, input struct{}) (*mcp.ToolResult, error) {
+		return mcp.NewToolResult().
+			Text("summary").
+			ResourceLink("file:///report.pdf", "report", "application/pdf"), nil
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	text, err := client.CallTool("report", struct{}{})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text != "summary" {
+		t.Errorf("text = %q, want %q", text, "summary")
+	}
+
+	resp, err := client.CallToolRaw("report", struct{}{})
+	if err != nil {
+		t.Fatalf("CallToolRaw failed: %v", err)
+	}
+	result, _ := resp.Result.(map[string]any)
+	content, _ := result["content"].([]any)
+	if len(content) != 2 {
+		t.Fatalf("got %d content blocks, want 2", len(content))
+	}
+}
+
+func TestTestClient_CallToolContent(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G'}
+	wav := []byte("RIFF")
+
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("media").Handler(func(ctx context.Context, input struct{}) (*mcp.ToolResult, error) {
+		return mcp.NewToolResult().Image(png, "image/png").Audio(wav, "audio/wav"), nil
+	})
+	srv.Tool("bad-media").Handler(func(ctx context.Context, input struct{}) (*mcp.ToolResult, error) {
+		return mcp.NewToolResult().Image(png, "application/octet-stream"), nil
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	content, err := client.CallToolContent("media", struct{}{})
+	if err != nil {
+		t.Fatalf("CallToolContent failed: %v", err)
+	}
+	if len(content) != 2 {
+		t.Fatalf("got %d content blocks, want 2", len(content))
+	}
+	testutil.AssertMediaContent(t, content[0], "image", "image/png", png)
+	testutil.AssertMediaContent(t, content[1], "audio", "audio/wav", wav)
+
+	if _, err := client.CallToolContent("bad-media", struct{}{}); err == nil {
+		t.Error("expected error for invalid MIME type")
+	}
+}
+
+func TestTestClient_CallToolWithProgress(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("import").Handler(func(ctx context.Context, input struct {
+		Rows int `json:"rows"`
+	}) (string, error) {
+		progress := mcp.ProgressFromContext(ctx)
+		total := float64(input.Rows)
+		for i := 1; i <= input.Rows; i++ {
+			if err := progress.ReportWithMessage(float64(i), &total, "importing"); err != nil {
+				return "", err
+			}
+		}
+		return "imported", nil
+	})
+	srv.Tool("fail").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		_ = mcp.ProgressFromContext(ctx).Report(1, nil)
+		return "", mcp.NewToolError("failed halfway")
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	t.Run("captures updates", func(t *testing.T) {
+		text, updates, err := client.CallToolWithProgress("import", map[string]any{"rows": 3})
+		if err != nil {
+			t.Fatalf("CallToolWithProgress failed: %v", err)
+		}
+		if text != "imported" {
+			t.Errorf("text = %q, want %q", text, "imported")
+		}
+		if len(updates) != 3 {
+			t.Fatalf("got %d updates, want 3", len(updates))
+		}
+		for i, u := range updates {
+			if u.Progress != float64(i+1) {
+				t.Errorf("updates[%d].Progress = %v, want %v", i, u.Progress, i+1)
+			}
+			if u.Total == nil || *u.Total != 3 {
+				t.Errorf("updates[%d].Total = %v, want 3", i, u.Total)
+			}
+			if u.Message != "importing" {
+				t.Errorf("updates[%d].Message = %q, want %q", i, u.Message, "importing")
+			}
+		}
+	})
+
+	t.Run("returns updates with tool error", func(t *testing.T) {
+		_, updates, err := client.CallToolWithProgress("fail", struct{}{})
+		var toolErr *mcp.ToolError
+		if !errors.As(err, &toolErr) {
+			t.Fatalf("error = %v, want *mcp.ToolError", err)
+		}
+		if len(updates) != 1 || updates[0].Total != nil {
+			t.Errorf("updates = %+v, want one update without total", updates)
+		}
+	})
+
+	t.Run("CallTool reports nowhere", func(t *testing.T) {
+		if _, err := client.CallTool("import", map[string]any{"rows": 2}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	})
+}
+
 func TestTestClient_Resources(t *testing.T) {
 	srv := mcp.NewServer(mcp.ServerInfo{
 		Name:    "test-server",
@@ -143,6 +296,17 @@ func TestTestClient_Resources(t *testing.T) {
 		}
 	})
 
+	t.Run("ListResourceTemplates", func(t *testing.T) {
+		templates, err := client.ListResourceTemplates()
+		if err != nil {
+			t.Fatalf("ListResourceTemplates failed: %v", err)
+		}
+
+		if len(templates) != 1 || templates[0]["uriTemplate"] != "file:///{path}" {
+			t.Errorf("unexpected templates: %v", templates)
+		}
+	})
+
 	t.Run("ReadResource", func(t *testing.T) {
 		content, err := client.ReadResource("file:///test.txt")
 		if err != nil {
@@ -163,6 +327,190 @@ func TestTestClient_Resources(t *testing.T) {
 	})
 }
 
+func TestTestClient_UnknownMethod(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.OnUnknownMethod(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]any{"method": req.Method}), nil
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	resp, err := client.SendRequest("custom/status", nil)
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	result, _ := resp.Result.(map[string]any)
+	if result["method"] != "custom/status" {
+		t.Errorf("result = %v, want forwarded method", result)
+	}
+}
+
+func TestTestClient_CustomMethod(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Method("x-test/status", func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]any{"status": "ok"}), nil
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	resp, err := client.SendRequest("x-test/status", nil)
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	result, _ := resp.Result.(map[string]any)
+	if result["status"] != "ok" {
+		t.Errorf("result = %v, want status ok", result)
+	}
+}
+
+func TestTestClient_ResultMeta(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("search").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		mcp.WithResultMeta(ctx, "traceId", "abc")
+		return "found", nil
+	})
+
+	client := testutil.NewTestClient(t, srv)
+
+	resp, err := client.SendRequest("tools/call", map[string]any{"name": "search", "arguments": map[string]any{}})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	var result struct {
+		Meta map[string]any `json:"_meta"`
+	}
+	if err := protocol.DecodeResult(resp, &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if result.Meta["traceId"] != "abc" {
+		t.Errorf("_meta = %v, want traceId abc", result.Meta)
+	}
+}
+
+func TestTestClient_Session(t *testing.T) {
+	newServer := func() *mcp.Server {
+		srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+		srv.Tool("summarize").Handler(func(ctx context.Context, input struct{}) (string, error) {
+			session := mcp.SessionFromContext(ctx)
+			if session == nil {
+				return "", errors.New("no session")
+			}
+			result, err := session.CreateMessage(ctx, &server.CreateMessageRequest{
+				Messages:  []server.SamplingMessage{{Role: server.RoleUser, Content: server.NewTextContent("summarize")}},
+				MaxTokens: 100,
+			})
+			if err != nil {
+				return "", err
+			}
+			return result.Content.Text, nil
+		})
+		srv.Tool("roots").Handler(func(ctx context.Context, input struct{}) (string, error) {
+			result, err := mcp.SessionFromContext(ctx).ListRoots(ctx)
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(len(result.Roots)), nil
+		})
+		srv.Tool("log").Handler(func(ctx context.Context, input struct{}) (string, error) {
+			mcp.SessionFromContext(ctx).Info("tools", "called")
+			return "logged", nil
+		})
+		return srv
+	}
+
+	t.Run("declares no capabilities by default", func(t *testing.T) {
+		client := testutil.NewTestClient(t, newServer())
+
+		session := client.Session()
+		if session == nil {
+			t.Fatal("Session() = nil, want a session")
+		}
+		if session.SupportsFeature("sampling") || session.SupportsFeature("roots") {
+			t.Errorf("capabilities = %+v, want none", session.ClientCapabilities())
+		}
+		if _, err := client.CallTool("summarize", map[string]any{}); err == nil {
+			t.Error("sampling without the capability succeeded")
+		}
+	})
+
+	t.Run("answers sampling and roots requests", func(t *testing.T) {
+		var got *server.CreateMessageRequest
+		client := testutil.NewTestClient(t, newServer(),
+			testutil.WithSamplingHandler(func(ctx context.Context, req *server.CreateMessageRequest) (*server.CreateMessageResult, error) {
+				got = req
+				return &server.CreateMessageResult{Role: server.RoleAssistant, Content: server.NewTextContent("short"), Model: "test"}, nil
+			}),
+			testutil.WithClientRoots(server.Root{URI: "file:///a"}, server.Root{URI: "file:///b"}),
+		)
+
+		text, err := client.CallTool("summarize", map[string]any{})
+		if err != nil {
+			t.Fatalf("CallTool(summarize) error = %v", err)
+		}
+		if text != "short" {
+			t.Errorf("result = %q, want %q", text, "short")
+		}
+		if got == nil || got.MaxTokens != 100 {
+			t.Errorf("sampling request = %+v", got)
+		}
+
+		text, err = client.CallTool("roots", map[string]any{})
+		if err != nil {
+			t.Fatalf("CallTool(roots) error = %v", err)
+		}
+		if text != "2" {
+			t.Errorf("roots = %s, want 2", text)
+		}
+	})
+
+	t.Run("declares sampling without a handler", func(t *testing.T) {
+		client := testutil.NewTestClient(t, newServer(), testutil.WithClientSamplingTools())
+
+		if !client.Session().SupportsFeature("sampling.tools") {
+			t.Error("sampling.tools not supported")
+		}
+		_, err := client.CallTool("summarize", map[string]any{})
+		if !errors.Is(err, &protocol.Error{Code: protocol.CodeMethodNotFound}) {
+			t.Errorf("error = %v, want method not found from the client", err)
+		}
+	})
+
+	t.Run("records notifications", func(t *testing.T) {
+		client := testutil.NewTestClient(t, newServer())
+
+		if _, err := client.CallTool("log", map[string]any{}); err != nil {
+			t.Fatalf("CallTool(log) error = %v", err)
+		}
+		notifications := client.Notifications()
+		if len(notifications) != 1 || notifications[0].Method != protocol.MethodLoggingMessage {
+			t.Errorf("notifications = %v, want one log message", notifications)
+		}
+	})
+
+	t.Run("sends client info and capabilities on initialize", func(t *testing.T) {
+		var params protocol.InitializeParams
+		client := testutil.NewTestClientWithHandler(t, transport.HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, err
+			}
+			return protocol.NewResponse(req.ID, map[string]any{}), nil
+		}), testutil.WithClientInfo("claude-desktop", "0.9.0"), testutil.WithClientRoots())
+
+		if _, err := client.Initialize(); err != nil {
+			t.Fatalf("Initialize() error = %v", err)
+		}
+		if params.ClientInfo.Name != "claude-desktop" || params.ClientInfo.Version != "0.9.0" {
+			t.Errorf("clientInfo = %+v", params.ClientInfo)
+		}
+		if params.Capabilities.Roots == nil || params.Capabilities.Sampling != nil {
+			t.Errorf("capabilities = %+v, want roots only", params.Capabilities)
+		}
+		if client.Session() != nil {
+			t.Error("Session() with a custom handler should be nil")
+		}
+	})
+}
+
 func TestTestClient_Prompts(t *testing.T) {
 	srv := mcp.NewServer(mcp.ServerInfo{
 		Name:    "test-server",
@@ -214,7 +562,7 @@ func TestTestClient_Prompts(t *testing.T) {
 			t.Errorf("expected 'Summary prompt', got %v", result["description"])
 		}
 
-		messages, ok := result["messages"].([]server.PromptMessage)
+		messages, ok := result["messages"].([]any)
 		if !ok {
 			t.Fatal("expected messages in result")
 		}
@@ -222,6 +570,9 @@ func TestTestClient_Prompts(t *testing.T) {
 		if len(messages) != 1 {
 			t.Errorf("expected 1 message, got %d", len(messages))
 		}
+		if role := messages[0].(map[string]any)["role"]; role != "user" {
+			t.Errorf("expected user message, got %v", role)
+		}
 	})
 
 	t.Run("GetPrompt not found", func(t *testing.T) {
@@ -379,3 +730,35 @@ func TestAssertPromptExists(t *testing.T) {
 	// This should not fail
 	client.AssertPromptExists("test-prompt")
 }
+
+func TestTestClient_Hooks(t *testing.T) {
+	srv := mcp.NewServer(mcp.ServerInfo{Name: "test-server", Version: "1.0.0"})
+	srv.Tool("greet").Handler(func(ctx context.Context, input struct{}) (string, error) {
+		return "hi", nil
+	})
+
+	var events []string
+	srv.OnToolCall(func(ctx context.Context, call mcp.ToolCallEvent) error {
+		events = append(events, "call:"+call.Name)
+		return nil
+	})
+	srv.OnToolResult(func(ctx context.Context, result mcp.ToolResultEvent) {
+		events = append(events, "result:"+result.Name)
+	})
+	srv.OnError(func(ctx context.Context, failure mcp.ErrorEvent) {
+		events = append(events, "error:"+failure.Method)
+	})
+
+	client := testutil.NewTestClient(t, srv)
+	if _, err := client.CallTool("greet", map[string]any{}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if _, err := client.CallTool("missing", map[string]any{}); err == nil {
+		t.Fatal("CallTool(missing) succeeded")
+	}
+
+	want := []string{"call:greet", "result:greet", "error:tools/call"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+)
+
+// Transport names reported in ConnectionInfo.
+const (
+	TransportStdio     = "stdio"
+	TransportHTTP      = "http"
+	TransportWebSocket = "websocket"
+)
+
+// ConnectionInfo describes the connection a request arrived on.
+type ConnectionInfo struct {
+	// ID identifies the connection. It is random and unguessable, so it is
+	// safe to key persisted session state by it. It is empty for
+	// sessionless HTTP requests.
+	ID string
+	// Transport is the transport name, such as TransportStdio.
+	Transport string
+	// RemoteAddr is the network address of the client, if any.
+	RemoteAddr string
+	// TLS holds the TLS state for HTTP and WebSocket connections served
+	// over TLS, or nil otherwise.
+	TLS *tls.ConnectionState
+}
+
+// ConnContextFunc derives the base context for a connection. Use it to seed
+// values that middleware and handlers can read for every request on the
+// connection. For HTTP, it runs once per request.
+type ConnContextFunc func(ctx context.Context, info ConnectionInfo) context.Context
+
+// connectionInfoKey is the context key for the connection info.
+type connectionInfoKey struct{}
+
+// ContextWithConnectionInfo returns a context with the connection info attached.
+func ContextWithConnectionInfo(ctx context.Context, info ConnectionInfo) context.Context {
+	return context.WithValue(ctx, connectionInfoKey{}, info)
+}
+
+// ConnectionInfoFromContext returns the connection info from context.
+// The second return value reports whether any was attached.
+func ConnectionInfoFromContext(ctx context.Context) (ConnectionInfo, bool) {
+	info, ok := ctx.Value(connectionInfoKey{}).(ConnectionInfo)
+	return info, ok
+}
+
+// ContextWithConnectionID returns a context with the connection ID attached,
+// keeping any other connection info already present.
+func ContextWithConnectionID(ctx context.Context, id string) context.Context {
+	info, _ := ConnectionInfoFromContext(ctx)
+	info.ID = id
+	return ContextWithConnectionInfo(ctx, info)
+}
+
+// ConnectionIDFromContext returns the ID of the connection a request arrived
+// on, or an empty string if the transport has no connection concept.
+func ConnectionIDFromContext(ctx context.Context) string {
+	info, _ := ConnectionInfoFromContext(ctx)
+	return info.ID
+}
+
+// TransportFromContext returns the name of the transport a request arrived
+// on, such as TransportStdio, or an empty string if unknown.
+func TransportFromContext(ctx context.Context) string {
+	info, _ := ConnectionInfoFromContext(ctx)
+	return info.Transport
+}
+
+// connectionContext attaches info to ctx and applies the optional seed function.
+func connectionContext(ctx context.Context, info ConnectionInfo, seed ConnContextFunc) context.Context {
+	ctx = ContextWithConnectionInfo(ctx, info)
+	if seed != nil {
+		ctx = seed(ctx, info)
+	}
+	return ctx
+}
+
+// ConnectionObserver is optionally implemented by a Handler that keeps
+// per-connection state. Transports call ConnectionClosed when a connection
+// ends so the handler can release that state.
+type ConnectionObserver interface {
+	ConnectionClosed(id string)
+}
+
+// ConnectionCloser is optionally implemented by a RequestSender whose
+// connection the server may close, such as a WebSocket client. Closing it
+// ends the connection as if the client had disconnected.
+type ConnectionCloser interface {
+	CloseConnection() error
+}
+
+// newConnectionID returns an unguessable connection ID with the given
+// prefix. IDs must be random rather than sequential: session state is
+// persisted under them, and a sequence restarting in another process would
+// hand one client's stored session to another.
+func newConnectionID(prefix string) string {
+	return prefix + "-" + newSessionID()
+}
+
+// newSessionID returns an unguessable session ID for HTTP sessions.
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // never returns an error
+	return hex.EncodeToString(b)
+}
+
+// closeConnection notifies handler that the connection has ended.
+func closeConnection(handler Handler, id string) {
+	if observer, ok := handler.(ConnectionObserver); ok {
+		observer.ConnectionClosed(id)
+	}
+}
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestConnectionID(t *testing.T) {
+	t.Run("round-trips through context", func(t *testing.T) {
+		ctx := ContextWithConnectionID(context.Background(), "conn-1")
+		if got := ConnectionIDFromContext(ctx); got != "conn-1" {
+			t.Errorf("ConnectionIDFromContext() = %q, want %q", got, "conn-1")
+		}
+	})
+
+	t.Run("empty when absent", func(t *testing.T) {
+		if got := ConnectionIDFromContext(context.Background()); got != "" {
+			t.Errorf("ConnectionIDFromContext() = %q, want empty", got)
+		}
+	})
+
+	t.Run("generates unique random IDs", func(t *testing.T) {
+		a, b := newConnectionID("ws"), newConnectionID("ws")
+		if a == b || !strings.HasPrefix(a, "ws-") {
+			t.Errorf("got %q and %q", a, b)
+		}
+		// 128 bits of randomness, hex encoded
+		if len(a) != len("ws-")+32 {
+			t.Errorf("len(%q) = %d, want %d", a, len(a), len("ws-")+32)
+		}
+	})
+}
+
+// observingHandler records connection IDs seen by requests and closures.
+type observingHandler struct {
+	seen   []string
+	closed []string
+}
+
+func (h *observingHandler) HandleRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	h.seen = append(h.seen, ConnectionIDFromContext(ctx))
+	return protocol.NewResponse(req.ID, map[string]any{}), nil
+}
+
+func (h *observingHandler) ConnectionClosed(id string) {
+	h.closed = append(h.closed, id)
+}
+
+func TestStdio_ConnectionID(t *testing.T) {
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n")
+	transport := NewStdio(WithStdin(in), WithStdout(&strings.Builder{}))
+
+	handler := &observingHandler{}
+	if err := transport.Serve(context.Background(), handler); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	if len(handler.seen) != 2 || handler.seen[0] == "" || handler.seen[0] != handler.seen[1] {
+		t.Errorf("seen = %v, want one stable connection ID", handler.seen)
+	}
+	if len(handler.closed) != 1 || handler.closed[0] != handler.seen[0] {
+		t.Errorf("closed = %v, want [%s]", handler.closed, handler.seen[0])
+	}
+}
+
+func TestConnectionInfo(t *testing.T) {
+	t.Run("ContextWithConnectionID keeps other fields", func(t *testing.T) {
+		ctx := ContextWithConnectionInfo(context.Background(), ConnectionInfo{Transport: TransportHTTP})
+		ctx = ContextWithConnectionID(ctx, "session-1")
+
+		info, ok := ConnectionInfoFromContext(ctx)
+		if !ok {
+			t.Fatal("expected connection info")
+		}
+		if info.ID != "session-1" || info.Transport != TransportHTTP {
+			t.Errorf("info = %+v", info)
+		}
+	})
+
+	t.Run("TransportFromContext empty when absent", func(t *testing.T) {
+		if got := TransportFromContext(context.Background()); got != "" {
+			t.Errorf("TransportFromContext() = %q, want empty", got)
+		}
+	})
+}
+
+type seedKey struct{}
+
+func TestConnContext(t *testing.T) {
+	seed := func(ctx context.Context, info ConnectionInfo) context.Context {
+		return context.WithValue(ctx, seedKey{}, "seeded-"+info.Transport)
+	}
+
+	capture := func(got *[]string) HandlerFunc {
+		return func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			value, _ := ctx.Value(seedKey{}).(string)
+			*got = append(*got, TransportFromContext(ctx), value)
+			return protocol.NewResponse(req.ID, map[string]any{}), nil
+		}
+	}
+
+	t.Run("stdio", func(t *testing.T) {
+		var got []string
+		transport := NewStdio(
+			WithStdin(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n")),
+			WithStdout(&strings.Builder{}),
+			WithConnContext(seed),
+		)
+		if err := transport.Serve(context.Background(), capture(&got)); err != nil {
+			t.Fatalf("Serve() error = %v", err)
+		}
+		if len(got) != 2 || got[0] != TransportStdio || got[1] != "seeded-stdio" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		var got []string
+		httpHandler := NewHTTP(":0", WithHTTPConnContext(seed)).createHandler(capture(&got))
+
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		httpHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if len(got) != 2 || got[0] != TransportHTTP || got[1] != "seeded-http" {
+			t.Errorf("got %v", got)
+		}
+	})
+}
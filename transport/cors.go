@@ -36,8 +36,10 @@ func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
-		MaxAge:       86400,
+		AllowHeaders: []string{"Content-Type", "Authorization", "X-Request-ID", SessionIDHeader},
+		// Browsers must be able to read the session ID issued on initialize
+		ExposeHeaders: []string{SessionIDHeader},
+		MaxAge:        86400,
 	}
 }
 
@@ -24,9 +24,13 @@
 //
 // The HTTP transport exposes the following endpoints:
 //   - POST /mcp - Handle JSON-RPC requests
-//   - GET /sse - Establish SSE connection
+//   - GET /mcp/sse - Establish SSE connection
 //   - GET /health - Health check endpoint
 //
+// A client opening the SSE stream with its Mcp-Session-Id header receives
+// the notifications and requests the server sends to that session, and
+// posts its responses to /mcp.
+//
 // # Handler Interface
 //
 // All transports expect a Handler that processes requests:
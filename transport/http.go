@@ -3,7 +3,9 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"sync"
@@ -12,6 +14,61 @@ import (
 	"github.com/felixgeelhaar/mcp-go/protocol"
 )
 
+// SessionIDHeader carries the MCP session ID on HTTP requests. Requests
+// sharing a session ID are treated as one connection.
+const SessionIDHeader = "Mcp-Session-Id"
+
+// SessionStore records the sessions issued by the HTTP transport. With a
+// store shared by several server instances, such as Redis, a session
+// initialized on one instance is recognized by every other instance behind
+// the same load balancer.
+//
+// server.MemoryStore and server.FileStore implement SessionStore, so the
+// store passed to server.WithSessionStore can also be used here.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// CreateSession records a new session.
+	CreateSession(ctx context.Context, id string) error
+	// SessionExists reports whether a session has been created and not
+	// deleted since.
+	SessionExists(ctx context.Context, id string) (bool, error)
+	// DeleteSession removes a session. Deleting a missing session is not
+	// an error.
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// memorySessionStore is the default SessionStore, recording the sessions
+// issued by one transport in memory.
+type memorySessionStore struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{ids: make(map[string]struct{})}
+}
+
+func (m *memorySessionStore) CreateSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[id] = struct{}{}
+	return nil
+}
+
+func (m *memorySessionStore) SessionExists(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.ids[id]
+	return ok, nil
+}
+
+func (m *memorySessionStore) DeleteSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.ids, id)
+	return nil
+}
+
 // HTTP implements an HTTP transport with SSE support for MCP.
 type HTTP struct {
 	addr            string
@@ -28,6 +85,17 @@ type HTTP struct {
 	// SSE clients
 	sseClients   map[string]chan []byte
 	sseClientsMu sync.RWMutex
+
+	// Requests that can be cancelled via notifications/cancelled
+	inflight *inflightRequests
+
+	wire     *WireLogger
+	seed     ConnContextFunc
+	sessions SessionStore
+
+	// Connections of the sessions served by this instance
+	conns   map[string]*httpConnection
+	connsMu sync.Mutex
 }
 
 // HTTPOption configures the HTTP transport.
@@ -47,6 +115,34 @@ func WithWriteTimeout(d time.Duration) HTTPOption {
 	}
 }
 
+// WithHTTPWireLogger logs every raw JSON-RPC request body, response body
+// and SSE message to w.
+func WithHTTPWireLogger(w io.Writer, opts ...WireLogOption) HTTPOption {
+	return func(h *HTTP) {
+		h.wire = NewWireLogger(w, opts...)
+	}
+}
+
+// WithHTTPConnContext sets a function that seeds the base context of each
+// HTTP request.
+func WithHTTPConnContext(fn ConnContextFunc) HTTPOption {
+	return func(h *HTTP) {
+		h.seed = fn
+	}
+}
+
+// WithHTTPSessionStore sets the store that records the sessions issued on
+// initialize. Requests carrying a session ID the store does not know are
+// rejected with 404 Not Found, telling the client to initialize again, and
+// DELETE removes the session from the store. By default the transport
+// keeps the sessions it issued in memory; share a store between instances
+// to let any of them serve a session.
+func WithHTTPSessionStore(store SessionStore) HTTPOption {
+	return func(h *HTTP) {
+		h.sessions = store
+	}
+}
+
 // NewHTTP creates a new HTTP transport.
 func NewHTTP(addr string, opts ...HTTPOption) *HTTP {
 	h := &HTTP{
@@ -55,6 +151,9 @@ func NewHTTP(addr string, opts ...HTTPOption) *HTTP {
 		writeTimeout:    30 * time.Second,
 		shutdownTimeout: 30 * time.Second,
 		sseClients:      make(map[string]chan []byte),
+		inflight:        newInflightRequests(),
+		sessions:        newMemorySessionStore(),
+		conns:           make(map[string]*httpConnection),
 	}
 
 	for _, opt := range opts {
@@ -120,6 +219,14 @@ func (h *HTTP) Serve(ctx context.Context, handler Handler) error {
 	}
 }
 
+// Handler returns the http.Handler that serves MCP requests with handler,
+// for mounting the transport into an existing HTTP server or router instead
+// of calling Serve. It serves the MCP endpoint at /mcp, server-sent events
+// at /mcp/sse and a health check at /health.
+func (h *HTTP) Handler(handler Handler) http.Handler {
+	return h.createHandler(handler)
+}
+
 // createHandler creates the HTTP handler for MCP requests.
 func (h *HTTP) createHandler(handler Handler) http.Handler {
 	mux := http.NewServeMux()
@@ -133,7 +240,7 @@ func (h *HTTP) createHandler(handler Handler) http.Handler {
 
 	// SSE endpoint for server-to-client messages
 	mux.HandleFunc("/mcp/sse", func(w http.ResponseWriter, r *http.Request) {
-		h.handleSSE(w, r)
+		h.handleSSE(w, r, handler)
 	})
 
 	// Main MCP endpoint
@@ -151,6 +258,24 @@ func (h *HTTP) createHandler(handler Handler) http.Handler {
 
 // handleMCP handles JSON-RPC requests over HTTP.
 func (h *HTTP) handleMCP(w http.ResponseWriter, r *http.Request, handler Handler) {
+	// Clients end a session by sending DELETE with its session ID
+	if r.Method == http.MethodDelete {
+		sessionID := r.Header.Get(SessionIDHeader)
+		if sessionID == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !h.checkSession(w, r.Context(), sessionID) {
+			return
+		}
+		if err := h.endSession(r.Context(), sessionID, handler); err != nil {
+			http.Error(w, "delete session: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -158,31 +283,204 @@ func (h *HTTP) handleMCP(w http.ResponseWriter, r *http.Request, handler Handler
 
 	w.Header().Set("Content-Type", "application/json")
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	h.wire.Inbound("http", body)
+
 	var req protocol.Request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		resp := protocol.NewErrorResponse(nil, protocol.NewParseError("Invalid JSON"))
-		_ = json.NewEncoder(w).Encode(resp)
+	if err := json.Unmarshal(body, &req); err != nil {
+		resp := protocol.AcquireErrorResponse(nil, protocol.NewParseError("Invalid JSON"))
+		h.writeJSON(w, resp)
+		protocol.ReleaseResponse(resp)
 		return
 	}
 
-	resp, err := handler.HandleRequest(r.Context(), &req)
+	// Every initialize starts a new session with an ID issued here; the
+	// client echoes it on later requests. Session IDs this transport did
+	// not issue are rejected, and requests without one are sessionless.
+	ctx := r.Context()
+	sessionID := r.Header.Get(SessionIDHeader)
+	if req.Method == protocol.MethodInitialize {
+		sessionID = newSessionID()
+		if err := h.sessions.CreateSession(ctx, sessionID); err != nil {
+			http.Error(w, "create session: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(SessionIDHeader, sessionID)
+	} else if sessionID != "" && !h.checkSession(w, ctx, sessionID) {
+		return
+	}
+	ctx = connectionContext(ctx, ConnectionInfo{
+		ID:         sessionID,
+		Transport:  TransportHTTP,
+		RemoteAddr: r.RemoteAddr,
+		TLS:        r.TLS,
+	}, h.seed)
+	if sessionID != "" {
+		conn := h.connection(sessionID, handler)
+		// Clients post their responses to server-initiated requests
+		if conn.pending.deliver(body) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		ctx = ContextWithRequestSender(ctx, conn)
+		ctx = ContextWithNotificationSender(ctx, conn)
+	}
+	// In-flight requests are tracked per session so that one client cannot
+	// cancel another's. Sessionless requests can't be attributed to a client,
+	// so they are not cancellable.
+	if req.IsNotification() {
+		if req.Method == protocol.MethodCancelled && sessionID != "" {
+			h.cancelRequest(sessionID, req.Params)
+		}
+	} else if sessionID != "" {
+		var done func() bool
+		ctx, done = h.inflight.trackIn(ctx, sessionID, req.ID)
+		defer done()
+	}
+
+	resp, err := handler.HandleRequest(ctx, &req)
 	if err != nil {
-		resp = protocol.NewErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
+		var mcpErr *protocol.Error
+		if errors.As(err, &mcpErr) {
+			resp = protocol.AcquireErrorResponse(req.ID, mcpErr)
+		} else {
+			resp = protocol.AcquireErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
+		}
 	}
 
 	if resp != nil {
-		_ = json.NewEncoder(w).Encode(resp)
+		if result, ok := resp.Result.(protocol.StreamingResult); ok {
+			h.writeStream(w, resp.ID, result)
+		} else {
+			h.writeJSON(w, resp)
+		}
+		protocol.ReleaseResponse(resp)
+	}
+}
+
+// connection returns the connection of session id, creating it when the
+// first request of the session reaches this instance.
+func (h *HTTP) connection(id string, handler Handler) *httpConnection {
+	h.connsMu.Lock()
+	defer h.connsMu.Unlock()
+
+	conn, ok := h.conns[id]
+	if !ok {
+		conn = newHTTPConnection(func() error {
+			return h.endSession(context.Background(), id, handler)
+		})
+		h.conns[id] = conn
+	}
+	return conn
+}
+
+// endSession deletes session id from the store, closes its connection and
+// tells handler the connection has ended.
+func (h *HTTP) endSession(ctx context.Context, id string, handler Handler) error {
+	err := h.sessions.DeleteSession(ctx, id)
+
+	h.connsMu.Lock()
+	conn, ok := h.conns[id]
+	delete(h.conns, id)
+	h.connsMu.Unlock()
+	if ok {
+		conn.close()
 	}
+
+	closeConnection(handler, id)
+	return err
 }
 
-// handleSSE handles Server-Sent Events connections.
-func (h *HTTP) handleSSE(w http.ResponseWriter, r *http.Request) {
+// checkSession reports whether sessionID is a known session, writing
+// 404 Not Found, which tells the client to initialize again, if it is not.
+func (h *HTTP) checkSession(w http.ResponseWriter, ctx context.Context, sessionID string) bool {
+	exists, err := h.sessions.SessionExists(ctx, sessionID)
+	if err != nil {
+		http.Error(w, "load session: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if !exists {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// writeStream writes a response whose result is encoded while it is
+// written, flushing each chunk to the client. An error after the first
+// chunk leaves the body truncated, which the client sees as invalid JSON.
+func (h *HTTP) writeStream(w http.ResponseWriter, id json.RawMessage, result protocol.StreamingResult) {
+	envelope := `{"jsonrpc":"2.0","id":` + string(id) + `,"result":`
+	h.wire.Outbound("http", []byte(envelope+`"(streamed)"}`))
+
+	fw := &flushWriter{w: w, rc: http.NewResponseController(w)}
+	if _, err := io.WriteString(fw, envelope); err != nil {
+		return
+	}
+	if err := result.WriteJSON(fw); err != nil {
+		return
+	}
+	_, _ = io.WriteString(fw, "}\n")
+}
+
+// flushWriter flushes every write to the client.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		_ = f.rc.Flush()
+	}
+	return n, err
+}
+
+// writeJSON encodes v as the response body.
+func (h *HTTP) writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	h.wire.Outbound("http", data)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// cancelRequest cancels the in-flight request of sessionID referenced by a
+// notifications/cancelled message.
+func (h *HTTP) cancelRequest(sessionID string, params json.RawMessage) {
+	var notif struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if err := json.Unmarshal(params, &notif); err != nil || len(notif.RequestID) == 0 {
+		return
+	}
+	h.inflight.cancelIn(sessionID, notif.RequestID)
+}
+
+// handleSSE handles Server-Sent Events connections. A stream opened with a
+// session ID carries the notifications and requests the server sends to
+// that session, until the session ends.
+func (h *HTTP) handleSSE(w http.ResponseWriter, r *http.Request, handler Handler) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "SSE not supported", http.StatusInternalServerError)
 		return
 	}
 
+	var conn *httpConnection
+	if sessionID := r.Header.Get(SessionIDHeader); sessionID != "" {
+		if !h.checkSession(w, r.Context(), sessionID) {
+			return
+		}
+		conn = h.connection(sessionID, handler)
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -203,6 +501,14 @@ func (h *HTTP) handleSSE(w http.ResponseWriter, r *http.Request) {
 		h.sseClientsMu.Unlock()
 	}()
 
+	// Runs before the deferred close above, so the session stops writing
+	// to the channel before it is closed.
+	var ended <-chan struct{}
+	if conn != nil {
+		defer conn.attach(messageCh)()
+		ended = conn.done
+	}
+
 	// Send initial connection event
 	fmt.Fprintf(w, "event: connected\ndata: {\"clientId\":\"%s\"}\n\n", clientID)
 	flusher.Flush()
@@ -212,10 +518,13 @@ func (h *HTTP) handleSSE(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-ended:
+			return
 		case msg, ok := <-messageCh:
 			if !ok {
 				return
 			}
+			h.wire.Outbound("sse", msg)
 			fmt.Fprintf(w, "data: %s\n\n", msg)
 			flusher.Flush()
 		}
@@ -251,3 +560,93 @@ func (h *HTTP) SendTo(clientID string, data []byte) bool {
 	}
 	return false
 }
+
+// ErrNoStream is returned when the server sends to an HTTP session that has
+// no SSE stream open on this instance.
+var ErrNoStream = errors.New("transport: no SSE stream open for session")
+
+// httpConnection carries server-initiated messages to an HTTP session over
+// the SSE stream the client opened for it. The client posts its responses.
+type httpConnection struct {
+	pending *pendingRequests
+	end     func() error
+	done    chan struct{}
+	once    sync.Once
+
+	mu     sync.Mutex
+	stream chan []byte
+}
+
+func newHTTPConnection(end func() error) *httpConnection {
+	return &httpConnection{
+		pending: newPendingRequests(),
+		end:     end,
+		done:    make(chan struct{}),
+	}
+}
+
+// attach makes ch the session's stream and returns a function detaching it.
+func (c *httpConnection) attach(ch chan []byte) func() {
+	c.mu.Lock()
+	c.stream = ch
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		if c.stream == ch {
+			c.stream = nil
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *httpConnection) write(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stream == nil {
+		return ErrNoStream
+	}
+	select {
+	case c.stream <- data:
+		return nil
+	default:
+		return errors.New("transport: SSE stream is full")
+	}
+}
+
+// SendRequest sends a request on the session's stream and waits for the
+// client to post its response.
+func (c *httpConnection) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return c.pending.send(ctx, req, c.write)
+}
+
+// SendNotification sends a notification on the session's stream.
+func (c *httpConnection) SendNotification(method string, params any) error {
+	paramsData, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  paramsData,
+	})
+	if err != nil {
+		return err
+	}
+	return c.write(data)
+}
+
+// CloseConnection ends the session.
+func (c *httpConnection) CloseConnection() error {
+	return c.end()
+}
+
+// close fails pending requests and ends the session's stream.
+func (c *httpConnection) close() {
+	c.once.Do(func() {
+		c.pending.close()
+		close(c.done)
+	})
+}
@@ -1,13 +1,17 @@
 package transport
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -235,3 +239,463 @@ func TestHTTP_Serve(t *testing.T) {
 		cancel()
 	})
 }
+
+func TestHTTP_Sessions(t *testing.T) {
+	handler := &observingHandler{}
+	httpHandler := NewHTTP(":0").createHandler(handler)
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		httpHandler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("issues session ID on initialize", func(t *testing.T) {
+		rec := post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "")
+		sessionID := rec.Header().Get(SessionIDHeader)
+		if sessionID == "" {
+			t.Fatal("expected session ID header")
+		}
+		if got := handler.seen[len(handler.seen)-1]; got != sessionID {
+			t.Errorf("connection ID = %q, want %q", got, sessionID)
+		}
+
+		post(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, sessionID)
+		if got := handler.seen[len(handler.seen)-1]; got != sessionID {
+			t.Errorf("connection ID = %q, want %q", got, sessionID)
+		}
+	})
+
+	t.Run("requests without session are sessionless", func(t *testing.T) {
+		rec := post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, "")
+		if rec.Header().Get(SessionIDHeader) != "" {
+			t.Error("expected no session ID header")
+		}
+		if got := handler.seen[len(handler.seen)-1]; got != "" {
+			t.Errorf("connection ID = %q, want empty", got)
+		}
+	})
+
+	t.Run("initialize ignores a client-chosen session ID", func(t *testing.T) {
+		rec := post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "chosen-by-client")
+		if got := rec.Header().Get(SessionIDHeader); got == "" || got == "chosen-by-client" {
+			t.Errorf("session ID = %q, want a newly issued one", got)
+		}
+	})
+
+	t.Run("unknown session IDs are rejected", func(t *testing.T) {
+		if rec := post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, "unknown"); rec.Code != http.StatusNotFound {
+			t.Errorf("POST status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+
+		req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+		req.Header.Set(SessionIDHeader, "unknown")
+		rec := httptest.NewRecorder()
+		httpHandler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("DELETE ends the session", func(t *testing.T) {
+		sessionID := post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "").Header().Get(SessionIDHeader)
+		closed := len(handler.closed)
+
+		req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+		req.Header.Set(SessionIDHeader, sessionID)
+		rec := httptest.NewRecorder()
+		httpHandler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if len(handler.closed) != closed+1 || handler.closed[closed] != sessionID {
+			t.Errorf("closed = %v, want %s", handler.closed, sessionID)
+		}
+		if rec := post(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, sessionID); rec.Code != http.StatusNotFound {
+			t.Errorf("status after DELETE = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestHTTP_SessionStream(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		switch req.Method {
+		case "notify":
+			if err := NotificationSenderFromContext(ctx).SendNotification("notifications/message", map[string]string{"text": "hi"}); err != nil {
+				return nil, err
+			}
+		case "ask":
+			resp, err := RequestSenderFromContext(ctx).SendRequest(ctx, &protocol.Request{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage(`"server-1"`),
+				Method:  "roots/list",
+			})
+			if err != nil {
+				return nil, err
+			}
+			return protocol.NewResponse(req.ID, resp.Result), nil
+		}
+		return protocol.NewResponse(req.ID, "ok"), nil
+	})
+	ts := httptest.NewServer(NewHTTP("").Handler(handler))
+	defer ts.Close()
+
+	send := func(method, path, body, sessionID string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		req.Header.Set(SessionIDHeader, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "")
+	resp.Body.Close()
+	sessionID := resp.Header.Get(SessionIDHeader)
+
+	if resp := send(http.MethodGet, "/mcp/sse", "", "unknown"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("stream for unknown session: status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	stream := send(http.MethodGet, "/mcp/sse", "", sessionID)
+	defer stream.Body.Close()
+	events := bufio.NewReader(stream.Body)
+	next := func() string {
+		t.Helper()
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				return ""
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				return strings.TrimSpace(data)
+			}
+		}
+	}
+	next() // connected event
+
+	t.Run("notifications are sent on the stream", func(t *testing.T) {
+		send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":2,"method":"notify"}`, sessionID).Body.Close()
+		if data := next(); !strings.Contains(data, `"method":"notifications/message"`) {
+			t.Errorf("stream data = %s, want the notification", data)
+		}
+	})
+
+	t.Run("posted responses answer server requests", func(t *testing.T) {
+		result := make(chan *protocol.Response, 1)
+		go func() {
+			resp := send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":3,"method":"ask"}`, sessionID)
+			defer resp.Body.Close()
+			var rpc protocol.Response
+			_ = json.NewDecoder(resp.Body).Decode(&rpc)
+			result <- &rpc
+		}()
+
+		if data := next(); !strings.Contains(data, `"method":"roots/list"`) {
+			t.Fatalf("stream data = %s, want the server request", data)
+		}
+		reply := send(http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":"server-1","result":"roots"}`, sessionID)
+		reply.Body.Close()
+		if reply.StatusCode != http.StatusAccepted {
+			t.Errorf("response status = %d, want %d", reply.StatusCode, http.StatusAccepted)
+		}
+
+		if rpc := <-result; rpc.Error != nil || rpc.Result != "roots" {
+			t.Errorf("ask response = %+v, want result roots", rpc)
+		}
+	})
+
+	t.Run("ending the session closes the stream", func(t *testing.T) {
+		send(http.MethodDelete, "/mcp", "", sessionID).Body.Close()
+		if data := next(); data != "" {
+			t.Errorf("stream data after DELETE = %s, want end of stream", data)
+		}
+	})
+}
+
+// mapSessionStore is a SessionStore backed by a map.
+type mapSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]bool
+	err      error
+}
+
+func (s *mapSessionStore) CreateSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.sessions[id] = true
+	return nil
+}
+
+func (s *mapSessionStore) SessionExists(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id], s.err
+}
+
+func (s *mapSessionStore) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return s.err
+}
+
+func TestHTTP_SessionStore(t *testing.T) {
+	store := &mapSessionStore{sessions: make(map[string]bool)}
+	handler := &observingHandler{}
+	// Two instances behind a load balancer share the store.
+	first := NewHTTP(":0", WithHTTPSessionStore(store)).createHandler(handler)
+	second := NewHTTP(":0", WithHTTPSessionStore(store)).createHandler(handler)
+
+	send := func(h http.Handler, method, body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(first, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "")
+	sessionID := rec.Header().Get(SessionIDHeader)
+	if exists, _ := store.SessionExists(context.Background(), sessionID); !exists {
+		t.Fatalf("session %q not created in store", sessionID)
+	}
+
+	rec = send(second, http.MethodPost, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, sessionID)
+	if rec.Code != http.StatusOK {
+		t.Errorf("other instance status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := handler.seen[len(handler.seen)-1]; got != sessionID {
+		t.Errorf("connection ID = %q, want %q", got, sessionID)
+	}
+
+	seen := len(handler.seen)
+	rec = send(second, http.MethodPost, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, "unknown")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if len(handler.seen) != seen {
+		t.Error("request with unknown session reached the handler")
+	}
+
+	rec = send(second, http.MethodDelete, "", sessionID)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	rec = send(first, http.MethodPost, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`, sessionID)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("deleted session status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	store.err = errors.New("store down")
+	rec = send(first, http.MethodPost, `{"jsonrpc":"2.0","id":5,"method":"initialize"}`, "")
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get(SessionIDHeader) != "" {
+		t.Errorf("initialize with failing store: status = %d, session %q", rec.Code, rec.Header().Get(SessionIDHeader))
+	}
+}
+
+// chunkedResult is a streaming result written in fixed chunks.
+type chunkedResult struct {
+	chunks []string
+	err    error
+}
+
+func (r chunkedResult) MarshalJSON() ([]byte, error) {
+	return []byte(strings.Join(r.chunks, "")), nil
+}
+
+func (r chunkedResult) WriteJSON(w io.Writer) error {
+	for _, c := range r.chunks {
+		if _, err := io.WriteString(w, c); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// flushRecorder counts the flushes of a response.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes++
+	r.ResponseRecorder.Flush()
+}
+
+func TestHTTP_StreamingResult(t *testing.T) {
+	post := func(t *testing.T, result protocol.StreamingResult, wire io.Writer) *flushRecorder {
+		t.Helper()
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			return protocol.NewResponse(req.ID, result), nil
+		})
+		var opts []HTTPOption
+		if wire != nil {
+			opts = append(opts, WithHTTPWireLogger(wire))
+		}
+		httpHandler := NewHTTP(":0", opts...).createHandler(handler)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"resources/read"}`))
+		rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		httpHandler.ServeHTTP(rec, httpReq)
+		return rec
+	}
+
+	t.Run("writes and flushes each chunk", func(t *testing.T) {
+		var wire bytes.Buffer
+		rec := post(t, chunkedResult{chunks: []string{`{"contents":[{"uri":"logs://app",`, `"text":"line 1\n`, `line 2\n"}]}`}}, &wire)
+
+		var resp struct {
+			JSONRPC string `json:"jsonrpc"`
+			ID      int    `json:"id"`
+			Result  struct {
+				Contents []struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"contents"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+		}
+		if resp.ID != 7 || resp.Result.Contents[0].Text != "line 1\nline 2\n" {
+			t.Errorf("response = %+v", resp)
+		}
+		if rec.flushes < 3 {
+			t.Errorf("flushed %d times, want at least once per chunk", rec.flushes)
+		}
+		if !strings.Contains(wire.String(), `"result":"(streamed)"`) {
+			t.Errorf("wire log = %q, want a placeholder for the streamed result", wire.String())
+		}
+	})
+
+	t.Run("truncates on error", func(t *testing.T) {
+		rec := post(t, chunkedResult{chunks: []string{`{"contents":[`}, err: io.ErrUnexpectedEOF}, nil)
+		if json.Valid(rec.Body.Bytes()) {
+			t.Errorf("body = %q, want truncated JSON", rec.Body.String())
+		}
+	})
+}
+
+func TestHTTP_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		if req.Method != "slow" {
+			return nil, nil
+		}
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return protocol.NewResponse(req.ID, "done"), nil
+		}
+	})
+	httpHandler := NewHTTP(":0").createHandler(handler)
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		httpHandler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	call := func(sessionID, cancelFrom string) *protocol.Response {
+		result := make(chan *httptest.ResponseRecorder, 1)
+		go func() {
+			result <- post(`{"jsonrpc":"2.0","id":7,"method":"slow"}`, sessionID)
+		}()
+		<-started
+		post(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`, cancelFrom)
+
+		var resp protocol.Response
+		if err := json.Unmarshal((<-result).Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return &resp
+	}
+
+	initialize := func() string {
+		return post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "").Header().Get(SessionIDHeader)
+	}
+	sessionA, sessionB := initialize(), initialize()
+
+	t.Run("owning session can cancel", func(t *testing.T) {
+		if resp := call(sessionA, sessionA); resp.Error == nil {
+			t.Errorf("expected cancelled request to fail, got %v", resp.Result)
+		}
+	})
+
+	t.Run("other session cannot cancel", func(t *testing.T) {
+		if resp := call(sessionA, sessionB); resp.Error != nil {
+			t.Errorf("request cancelled by another session: %v", resp.Error)
+		}
+	})
+
+	t.Run("sessionless cancel is ignored", func(t *testing.T) {
+		if resp := call(sessionA, ""); resp.Error != nil {
+			t.Errorf("request cancelled by sessionless client: %v", resp.Error)
+		}
+	})
+}
+
+func TestHTTP_HandlerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{
+			name: "protocol error keeps its code",
+			err:  fmt.Errorf("mcp: server not initialized: %w", protocol.NewInvalidRequest("server not initialized")),
+			code: protocol.CodeInvalidRequest,
+		},
+		{
+			name: "custom code",
+			err:  &protocol.Error{Code: protocol.CodeForbidden, Message: "forbidden"},
+			code: protocol.CodeForbidden,
+		},
+		{
+			name: "other errors are internal",
+			err:  errors.New("boom"),
+			code: protocol.CodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+				return nil, tt.err
+			})
+			httpHandler := NewHTTP(":0").createHandler(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+			rec := httptest.NewRecorder()
+			httpHandler.ServeHTTP(rec, req)
+
+			var resp protocol.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Error == nil || resp.Error.Code != tt.code {
+				t.Errorf("error = %+v, want code %d", resp.Error, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// inflightRequests tracks the contexts of requests that are currently being
+// handled so that a notifications/cancelled message can abort them.
+type inflightRequests struct {
+	mu       sync.Mutex
+	requests map[string]*inflightRequest
+}
+
+// inflightRequest is a single tracked request.
+type inflightRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// newInflightRequests creates an empty request tracker.
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{
+		requests: make(map[string]*inflightRequest),
+	}
+}
+
+// track derives a cancelable context for the request with the given ID.
+// The returned done function must be called when handling finishes; it
+// reports whether the request was cancelled by the client.
+func (f *inflightRequests) track(ctx context.Context, id json.RawMessage) (context.Context, func() bool) {
+	return f.trackIn(ctx, "", id)
+}
+
+// trackIn is like track but files the request under scope, so that only a
+// cancel for the same scope can abort it. Transports that multiplex several
+// clients over one tracker use the session ID as scope.
+func (f *inflightRequests) trackIn(ctx context.Context, scope string, id json.RawMessage) (context.Context, func() bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := scopedRequestKey(scope, id)
+	entry := &inflightRequest{cancel: cancel}
+
+	f.mu.Lock()
+	f.requests[key] = entry
+	f.mu.Unlock()
+
+	return ctx, func() bool {
+		cancel()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.requests[key] == entry {
+			delete(f.requests, key)
+		}
+		return entry.cancelled
+	}
+}
+
+// cancel cancels the in-flight request with the given ID.
+// Returns true if the request was found.
+func (f *inflightRequests) cancel(id json.RawMessage) bool {
+	return f.cancelIn("", id)
+}
+
+// cancelIn cancels the in-flight request with the given ID in scope.
+// Returns true if the request was found.
+func (f *inflightRequests) cancelIn(scope string, id json.RawMessage) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, ok := f.requests[scopedRequestKey(scope, id)]
+	if !ok {
+		return false
+	}
+	entry.cancelled = true
+	entry.cancel()
+	return true
+}
+
+// len returns the number of tracked requests.
+func (f *inflightRequests) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+// requestKey normalizes a JSON-RPC ID for use as a map key.
+func requestKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// scopedRequestKey joins scope and a normalized JSON-RPC ID into a map key.
+// The separator cannot appear in a JSON-encoded ID.
+func scopedRequestKey(scope string, id json.RawMessage) string {
+	return scope + "\n" + requestKey(id)
+}
+
+// cancelledRequestID returns the request ID referenced by a
+// notifications/cancelled message, or nil if data is not one.
+func cancelledRequestID(data []byte) json.RawMessage {
+	// Cheap pre-check to avoid decoding every message twice
+	if !bytes.Contains(data, []byte(protocol.MethodCancelled)) {
+		return nil
+	}
+
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			RequestID json.RawMessage `json:"requestId"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil
+	}
+	if msg.Method != protocol.MethodCancelled || len(msg.ID) != 0 {
+		return nil
+	}
+	return msg.Params.RequestID
+}
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestInflightRequests(t *testing.T) {
+	t.Run("cancel aborts tracked context", func(t *testing.T) {
+		f := newInflightRequests()
+		ctx, done := f.track(context.Background(), json.RawMessage(`1`))
+
+		if !f.cancel(json.RawMessage(` 1 `)) {
+			t.Fatal("expected request to be found")
+		}
+
+		select {
+		case <-ctx.Done():
+		default:
+			t.Error("expected context to be cancelled")
+		}
+
+		if !done() {
+			t.Error("done should report cancellation")
+		}
+		if f.len() != 0 {
+			t.Errorf("len = %d, want 0", f.len())
+		}
+	})
+
+	t.Run("done reports false for completed request", func(t *testing.T) {
+		f := newInflightRequests()
+		_, done := f.track(context.Background(), json.RawMessage(`"abc"`))
+
+		if done() {
+			t.Error("done should not report cancellation")
+		}
+		if f.cancel(json.RawMessage(`"abc"`)) {
+			t.Error("completed request should no longer be tracked")
+		}
+	})
+
+	t.Run("scopes isolate equal IDs", func(t *testing.T) {
+		f := newInflightRequests()
+		ctxA, doneA := f.trackIn(context.Background(), "a", json.RawMessage(`1`))
+		ctxB, doneB := f.trackIn(context.Background(), "b", json.RawMessage(`1`))
+		defer doneA()
+		defer doneB()
+
+		if f.len() != 2 {
+			t.Fatalf("len = %d, want 2", f.len())
+		}
+		if f.cancelIn("c", json.RawMessage(`1`)) {
+			t.Error("cancel from another scope should not find the request")
+		}
+		if !f.cancelIn("b", json.RawMessage(`1`)) {
+			t.Fatal("expected request to be found")
+		}
+		if ctxA.Err() != nil {
+			t.Error("request in scope a should not be cancelled")
+		}
+		if ctxB.Err() == nil {
+			t.Error("request in scope b should be cancelled")
+		}
+	})
+}
+
+func TestCancelledRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "cancelled notification",
+			data: `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"req-1"}}`,
+			want: `"req-1"`,
+		},
+		{
+			name: "other notification",
+			data: `{"jsonrpc":"2.0","method":"notifications/progress","params":{"requestId":1}}`,
+			want: "",
+		},
+		{
+			name: "request with cancelled method",
+			data: `{"jsonrpc":"2.0","id":1,"method":"notifications/cancelled","params":{"requestId":2}}`,
+			want: "",
+		},
+		{
+			name: "invalid json",
+			data: `{notifications/cancelled`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(cancelledRequestID([]byte(tt.data))); got != tt.want {
+				t.Errorf("cancelledRequestID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
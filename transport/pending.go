@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+// ErrConnectionClosed is returned by SendRequest when the connection ends
+// before the client responds.
+var ErrConnectionClosed = errors.New("transport: connection closed")
+
+// RequestSender can send requests to the client and wait for responses.
+type RequestSender interface {
+	SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error)
+}
+
+// requestSenderKey is the context key for the request sender.
+type requestSenderKey struct{}
+
+// ContextWithRequestSender returns a context with the request sender attached.
+func ContextWithRequestSender(ctx context.Context, sender RequestSender) context.Context {
+	return context.WithValue(ctx, requestSenderKey{}, sender)
+}
+
+// RequestSenderFromContext returns the request sender from context, or nil
+// if the transport cannot send requests to the client.
+func RequestSenderFromContext(ctx context.Context) RequestSender {
+	sender, _ := ctx.Value(requestSenderKey{}).(RequestSender)
+	return sender
+}
+
+// pendingRequests correlates server-initiated requests with the client's
+// responses.
+type pendingRequests struct {
+	mu      sync.Mutex
+	waiters map[string]chan *protocol.Response
+	closed  bool
+}
+
+// newPendingRequests creates an empty tracker.
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{
+		waiters: make(map[string]chan *protocol.Response),
+	}
+}
+
+// send writes req using write and waits for the matching response.
+func (p *pendingRequests) send(ctx context.Context, req *protocol.Request, write func([]byte) error) (*protocol.Response, error) {
+	if req.IsNotification() {
+		return nil, errors.New("transport: request must have an ID")
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := requestKey(req.ID)
+	ch := make(chan *protocol.Response, 1)
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrConnectionClosed
+	}
+	p.waiters[key] = ch
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.waiters, key)
+		p.mu.Unlock()
+	}()
+
+	if err := write(data); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, ErrConnectionClosed
+		}
+		return resp, nil
+	}
+}
+
+// deliver routes data to a waiting request if it is a response.
+// It reports whether data was a response, whether or not anyone was waiting.
+func (p *pendingRequests) deliver(data []byte) bool {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Method != "" || len(msg.ID) == 0 {
+		return false
+	}
+
+	var resp protocol.Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ch, ok := p.waiters[requestKey(resp.ID)]; ok {
+		ch <- &resp
+		delete(p.waiters, requestKey(resp.ID))
+	}
+	return true
+}
+
+// close fails all waiting requests with ErrConnectionClosed.
+func (p *pendingRequests) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for key, ch := range p.waiters {
+		close(ch)
+		delete(p.waiters, key)
+	}
+}
@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestPendingRequests(t *testing.T) {
+	req := &protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`7`), Method: "roots/list"}
+
+	t.Run("correlates response by ID", func(t *testing.T) {
+		p := newPendingRequests()
+		written := make(chan []byte, 1)
+
+		go func() {
+			<-written
+			if !p.deliver([]byte(`{"jsonrpc":"2.0","id":7,"result":{"roots":[]}}`)) {
+				t.Error("expected response to be recognized")
+			}
+		}()
+
+		resp, err := p.send(context.Background(), req, func(data []byte) error {
+			written <- data
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("send() error = %v", err)
+		}
+		if string(resp.ID) != "7" {
+			t.Errorf("response ID = %s, want 7", resp.ID)
+		}
+	})
+
+	t.Run("does not treat requests as responses", func(t *testing.T) {
+		p := newPendingRequests()
+		if p.deliver([]byte(`{"jsonrpc":"2.0","id":7,"method":"ping"}`)) {
+			t.Error("request should not be delivered")
+		}
+		if p.deliver([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)) {
+			t.Error("notification should not be delivered")
+		}
+	})
+
+	t.Run("fails waiters when closed", func(t *testing.T) {
+		p := newPendingRequests()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			p.close()
+		}()
+
+		_, err := p.send(context.Background(), req, func([]byte) error { return nil })
+		if !errors.Is(err, ErrConnectionClosed) {
+			t.Errorf("send() error = %v, want ErrConnectionClosed", err)
+		}
+
+		if _, err := p.send(context.Background(), req, func([]byte) error { return nil }); !errors.Is(err, ErrConnectionClosed) {
+			t.Errorf("send() after close error = %v, want ErrConnectionClosed", err)
+		}
+	})
+
+	t.Run("honors context cancellation", func(t *testing.T) {
+		p := newPendingRequests()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := p.send(ctx, req, func([]byte) error { return nil })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("send() error = %v, want DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("rejects notifications", func(t *testing.T) {
+		p := newPendingRequests()
+		_, err := p.send(context.Background(), &protocol.Request{Method: "x"}, func([]byte) error { return nil })
+		if err == nil {
+			t.Error("expected error for request without ID")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// messageQueue is an unbounded FIFO of inbound messages. It decouples the
+// connection reader from request processing, so responses to
+// server-initiated requests can still be routed while a handler is blocked
+// waiting for one of them.
+type messageQueue struct {
+	mu     sync.Mutex
+	items  [][]byte
+	err    error
+	signal chan struct{}
+}
+
+// newMessageQueue creates an empty queue.
+func newMessageQueue() *messageQueue {
+	return &messageQueue{
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// push appends a message to the queue. It never blocks.
+func (q *messageQueue) push(msg []byte) {
+	q.mu.Lock()
+	q.items = append(q.items, msg)
+	q.mu.Unlock()
+	q.wake()
+}
+
+// close marks the end of input. Messages already queued are still
+// delivered before next returns err. Use io.EOF for a clean end of input.
+func (q *messageQueue) close(err error) {
+	q.mu.Lock()
+	if q.err == nil {
+		q.err = err
+	}
+	q.mu.Unlock()
+	q.wake()
+}
+
+// next returns the next message, blocking until one is available, the
+// queue is closed, or ctx is done.
+func (q *messageQueue) next(ctx context.Context) ([]byte, error) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			msg := q.items[0]
+			q.items[0] = nil
+			q.items = q.items[1:]
+			q.mu.Unlock()
+			return msg, nil
+		}
+		err := q.err
+		q.mu.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.signal:
+		}
+	}
+}
+
+func (q *messageQueue) wake() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
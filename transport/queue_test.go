@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMessageQueue(t *testing.T) {
+	t.Run("delivers messages in order", func(t *testing.T) {
+		q := newMessageQueue()
+		q.push([]byte("a"))
+		q.push([]byte("b"))
+
+		for _, want := range []string{"a", "b"} {
+			got, err := q.next(context.Background())
+			if err != nil {
+				t.Fatalf("next() error = %v", err)
+			}
+			if string(got) != want {
+				t.Errorf("next() = %q, want %q", got, want)
+			}
+		}
+	})
+
+	t.Run("drains before reporting close", func(t *testing.T) {
+		q := newMessageQueue()
+		q.push([]byte("a"))
+		q.close(io.EOF)
+
+		if got, err := q.next(context.Background()); err != nil || string(got) != "a" {
+			t.Errorf("next() = %q, %v; want %q, nil", got, err, "a")
+		}
+		if _, err := q.next(context.Background()); !errors.Is(err, io.EOF) {
+			t.Errorf("next() error = %v, want io.EOF", err)
+		}
+	})
+
+	t.Run("blocks until push", func(t *testing.T) {
+		q := newMessageQueue()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			q.push([]byte("late"))
+		}()
+
+		got, err := q.next(context.Background())
+		if err != nil || string(got) != "late" {
+			t.Errorf("next() = %q, %v; want %q, nil", got, err, "late")
+		}
+	})
+
+	t.Run("returns context error", func(t *testing.T) {
+		q := newMessageQueue()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := q.next(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("next() error = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package transport
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -18,7 +19,11 @@ type Stdio struct {
 	out    io.Writer
 	errOut io.Writer
 
-	mu sync.Mutex
+	mu       sync.Mutex
+	inflight *inflightRequests
+	pending  *pendingRequests
+	wire     *WireLogger
+	seed     ConnContextFunc
 }
 
 // StdioOption configures a Stdio transport.
@@ -45,12 +50,30 @@ func WithStderr(w io.Writer) StdioOption {
 	}
 }
 
+// WithWireLogger logs every raw frame read from stdin and written to
+// stdout to w. Use it to debug interoperability with a new host.
+func WithWireLogger(w io.Writer, opts ...WireLogOption) StdioOption {
+	return func(s *Stdio) {
+		s.wire = NewWireLogger(w, opts...)
+	}
+}
+
+// WithConnContext sets a function that seeds the base context of the
+// stdio connection. Values it adds are visible to all middleware and handlers.
+func WithConnContext(fn ConnContextFunc) StdioOption {
+	return func(s *Stdio) {
+		s.seed = fn
+	}
+}
+
 // NewStdio creates a new stdio transport.
 func NewStdio(opts ...StdioOption) *Stdio {
 	s := &Stdio{
-		in:     os.Stdin,
-		out:    os.Stdout,
-		errOut: os.Stderr,
+		in:       os.Stdin,
+		out:      os.Stdout,
+		errOut:   os.Stderr,
+		inflight: newInflightRequests(),
+		pending:  newPendingRequests(),
 	}
 
 	for _, opt := range opts {
@@ -69,44 +92,58 @@ func (s *Stdio) Addr() string {
 func (s *Stdio) Serve(ctx context.Context, handler Handler) error {
 	scanner := bufio.NewScanner(s.in)
 
-	// Channel for scanner results
-	lines := make(chan string)
-	scanErr := make(chan error, 1)
+	// A stdio transport serves a single connection
+	connID := newConnectionID("stdio")
+	ctx = connectionContext(ctx, ConnectionInfo{ID: connID, Transport: TransportStdio}, s.seed)
+	defer closeConnection(handler, connID)
 
+	queue := newMessageQueue()
+
+	// The reader handles cancellation notifications and responses to
+	// server-initiated requests immediately, so they can reach a request
+	// that is still being processed by the loop below.
 	go func() {
 		for scanner.Scan() {
-			select {
-			case lines <- scanner.Text():
-			case <-ctx.Done():
-				return
+			line := scanner.Bytes()
+			s.wire.Inbound("stdio", line)
+			if id := cancelledRequestID(line); id != nil {
+				s.inflight.cancel(id)
+			}
+			if s.pending.deliver(line) {
+				continue
 			}
+			queue.push(bytes.Clone(line))
 		}
+
+		// Nothing more can arrive, so fail requests awaiting a response
+		s.pending.close()
 		if err := scanner.Err(); err != nil {
-			scanErr <- err
+			queue.close(err)
+			return
 		}
-		close(lines)
+		queue.close(io.EOF)
 	}()
 
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case err := <-scanErr:
+		line, err := queue.next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
 			return err
-		case line, ok := <-lines:
-			if !ok {
-				return nil // EOF
-			}
-			s.handleLine(ctx, handler, line)
 		}
+		s.handleLine(ctx, handler, string(line))
 	}
 }
 
+// SendRequest sends a request to the client and waits for its response.
+// It is used for server-initiated requests such as sampling and roots.
+func (s *Stdio) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return s.pending.send(ctx, req, s.writeLine)
+}
+
 // SendNotification sends a JSON-RPC notification to the client.
 func (s *Stdio) SendNotification(method string, params any) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	paramsData, err := json.Marshal(params)
 	if err != nil {
 		return err
@@ -123,12 +160,7 @@ func (s *Stdio) SendNotification(method string, params any) error {
 		return err
 	}
 
-	_, err = s.out.Write(data)
-	if err != nil {
-		return err
-	}
-	_, err = s.out.Write([]byte("\n"))
-	return err
+	return s.writeLine(data)
 }
 
 func (s *Stdio) handleLine(ctx context.Context, handler Handler, line string) {
@@ -136,19 +168,29 @@ func (s *Stdio) handleLine(ctx context.Context, handler Handler, line string) {
 	var req protocol.Request
 	if err := json.Unmarshal([]byte(line), &req); err != nil {
 		// Send parse error
-		resp := protocol.NewErrorResponse(nil, protocol.NewParseError(err.Error()))
+		resp := protocol.AcquireErrorResponse(nil, protocol.NewParseError(err.Error()))
 		s.writeResponse(resp)
+		protocol.ReleaseResponse(resp)
 		return
 	}
 
-	// Attach notification sender to context for progress reporting
+	// Attach senders to context for progress reporting and
+	// server-initiated requests
 	ctx = ContextWithNotificationSender(ctx, s)
-
-	// Handle request
-	resp, err := handler.HandleRequest(ctx, &req)
+	ctx = ContextWithRequestSender(ctx, s)
 
 	// For notifications, don't send response
 	if req.IsNotification() {
+		_, _ = handler.HandleRequest(ctx, &req)
+		return
+	}
+
+	// Track the request so the client can cancel it
+	ctx, done := s.inflight.track(ctx, req.ID)
+	resp, err := handler.HandleRequest(ctx, &req)
+
+	// Cancelled requests get no response
+	if done() {
 		return
 	}
 
@@ -156,26 +198,36 @@ func (s *Stdio) handleLine(ctx context.Context, handler Handler, line string) {
 	if err != nil {
 		var mcpErr *protocol.Error
 		if errors.As(err, &mcpErr) {
-			resp = protocol.NewErrorResponse(req.ID, mcpErr)
+			resp = protocol.AcquireErrorResponse(req.ID, mcpErr)
 		} else {
-			resp = protocol.NewErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
+			resp = protocol.AcquireErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
 		}
 	}
 
 	if resp != nil {
 		s.writeResponse(resp)
+		protocol.ReleaseResponse(resp)
 	}
 }
 
 func (s *Stdio) writeResponse(resp *protocol.Response) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	data, err := json.Marshal(resp)
 	if err != nil {
 		return
 	}
 
-	_, _ = s.out.Write(data)
-	_, _ = s.out.Write([]byte("\n"))
+	_ = s.writeLine(data)
+}
+
+// writeLine writes a single newline-delimited message to stdout.
+func (s *Stdio) writeLine(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.wire.Outbound("stdio", data)
+	if _, err := s.out.Write(data); err != nil {
+		return err
+	}
+	_, err := s.out.Write([]byte("\n"))
+	return err
 }
@@ -1,10 +1,14 @@
 package transport
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -219,6 +223,84 @@ func TestStdio_Serve(t *testing.T) {
 	})
 }
 
+func TestStdio_Cancellation(t *testing.T) {
+	t.Run("cancels in-flight request on notifications/cancelled", func(t *testing.T) {
+		inR, inW := io.Pipe()
+		out := &syncBuffer{}
+
+		transport := NewStdio(
+			WithStdin(inR),
+			WithStdout(out),
+		)
+
+		started := make(chan struct{})
+		handlerErr := make(chan error, 1)
+		handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			if req.IsNotification() {
+				return nil, nil
+			}
+			close(started)
+			select {
+			case <-ctx.Done():
+				handlerErr <- ctx.Err()
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				handlerErr <- nil
+				return protocol.NewResponse(req.ID, "finished"), nil
+			}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- transport.Serve(ctx, handler)
+		}()
+
+		_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":7,"method":"slow"}` + "\n"))
+		<-started
+		_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user"}}` + "\n"))
+
+		select {
+		case err := <-handlerErr:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("handler ctx error = %v, want context.Canceled", err)
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("handler was not cancelled")
+		}
+
+		_ = inW.Close()
+		<-done
+
+		if strings.Contains(out.String(), `"id":7`) {
+			t.Errorf("expected no response for cancelled request, got %q", out.String())
+		}
+		if transport.inflight.len() != 0 {
+			t.Errorf("inflight = %d, want 0", transport.inflight.len())
+		}
+	})
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // blockingReader is a reader that blocks until context is done
 type blockingReader struct{}
 
@@ -226,3 +308,63 @@ func (r *blockingReader) Read(p []byte) (n int, err error) {
 	// Block forever (will be interrupted by context)
 	select {}
 }
+
+func TestStdio_SendRequest(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	transport := NewStdio(
+		WithStdin(inR),
+		WithStdout(outW),
+	)
+
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		sender := RequestSenderFromContext(ctx)
+		if sender == nil {
+			return nil, errors.New("no request sender")
+		}
+		resp, err := sender.SendRequest(ctx, &protocol.Request{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage(`100`),
+			Method:  protocol.MethodRootsList,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return protocol.NewResponse(req.ID, resp.Result), nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- transport.Serve(context.Background(), handler)
+	}()
+
+	scanner := bufio.NewScanner(outR)
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}` + "\n"))
+
+	// The server asks the client for roots while handling the request
+	if !scanner.Scan() {
+		t.Fatal("expected server-initiated request")
+	}
+	var serverReq protocol.Request
+	if err := json.Unmarshal(scanner.Bytes(), &serverReq); err != nil {
+		t.Fatalf("invalid request: %v", err)
+	}
+	if serverReq.Method != protocol.MethodRootsList || string(serverReq.ID) != "100" {
+		t.Fatalf("unexpected request: %s", scanner.Bytes())
+	}
+
+	_, _ = inW.Write([]byte(`{"jsonrpc":"2.0","id":100,"result":{"roots":[{"uri":"file:///work"}]}}` + "\n"))
+
+	if !scanner.Scan() {
+		t.Fatal("expected response")
+	}
+	if got := scanner.Text(); !strings.Contains(got, `"id":1`) || !strings.Contains(got, "file:///work") {
+		t.Errorf("response = %s", got)
+	}
+
+	_ = inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -24,12 +25,17 @@ type WebSocket struct {
 
 	mu      sync.RWMutex
 	clients map[*wsClient]struct{}
+
+	wire *WireLogger
+	seed ConnContextFunc
 }
 
 // wsClient represents a single WebSocket connection.
 type wsClient struct {
-	conn *websocket.Conn
-	mu   sync.Mutex
+	conn    *websocket.Conn
+	mu      sync.Mutex
+	wire    *WireLogger
+	pending *pendingRequests
 }
 
 // WebSocketOption configures a WebSocket transport.
@@ -56,6 +62,22 @@ func WithWebSocketCheckOrigin(fn func(r *http.Request) bool) WebSocketOption {
 	}
 }
 
+// WithWebSocketWireLogger logs every raw WebSocket frame exchanged with
+// clients to w.
+func WithWebSocketWireLogger(w io.Writer, opts ...WireLogOption) WebSocketOption {
+	return func(ws *WebSocket) {
+		ws.wire = NewWireLogger(w, opts...)
+	}
+}
+
+// WithWebSocketConnContext sets a function that seeds the base context of
+// each WebSocket connection.
+func WithWebSocketConnContext(fn ConnContextFunc) WebSocketOption {
+	return func(ws *WebSocket) {
+		ws.seed = fn
+	}
+}
+
 // NewWebSocket creates a new WebSocket transport.
 func NewWebSocket(addr string, opts ...WebSocketOption) *WebSocket {
 	ws := &WebSocket{
@@ -120,7 +142,7 @@ func (ws *WebSocket) handleConnection(ctx context.Context, w http.ResponseWriter
 		return
 	}
 
-	client := &wsClient{conn: conn}
+	client := &wsClient{conn: conn, wire: ws.wire, pending: newPendingRequests()}
 
 	ws.mu.Lock()
 	ws.clients[client] = struct{}{}
@@ -133,44 +155,82 @@ func (ws *WebSocket) handleConnection(ctx context.Context, w http.ResponseWriter
 		_ = conn.Close()
 	}()
 
+	connID := newConnectionID("ws")
+	defer closeConnection(handler, connID)
+
+	ctx = connectionContext(ctx, ConnectionInfo{
+		ID:         connID,
+		Transport:  TransportWebSocket,
+		RemoteAddr: r.RemoteAddr,
+		TLS:        r.TLS,
+	}, ws.seed)
+
 	// Create notification sender for this client
 	sender := &wsNotificationSender{client: client}
+	inflight := newInflightRequests()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
+	// Read messages in the background so cancellation notifications and
+	// responses to server-initiated requests reach a request that is
+	// still being processed.
+	queue := newMessageQueue()
+	go func() {
+		for {
+			if ws.readTimeout > 0 {
+				_ = conn.SetReadDeadline(time.Now().Add(ws.readTimeout))
+			}
+
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				// Expected close errors are normal (client disconnected)
+				// Unexpected errors could be logged if needed
+				client.pending.close()
+				queue.close(err)
+				return
+			}
+			ws.wire.Inbound("websocket", message)
 
-		// Read message
-		if ws.readTimeout > 0 {
-			_ = conn.SetReadDeadline(time.Now().Add(ws.readTimeout))
+			if id := cancelledRequestID(message); id != nil {
+				inflight.cancel(id)
+			}
+			if client.pending.deliver(message) {
+				continue
+			}
+
+			queue.push(message)
 		}
+	}()
 
-		_, message, err := conn.ReadMessage()
+	for {
+		message, err := queue.next(ctx)
 		if err != nil {
-			// Expected close errors are normal (client disconnected)
-			// Unexpected errors could be logged if needed
 			return
 		}
 
 		// Parse request
 		var req protocol.Request
 		if err := json.Unmarshal(message, &req); err != nil {
-			resp := protocol.NewErrorResponse(nil, protocol.NewParseError(err.Error()))
+			resp := protocol.AcquireErrorResponse(nil, protocol.NewParseError(err.Error()))
 			_ = client.writeJSON(resp)
+			protocol.ReleaseResponse(resp)
 			continue
 		}
 
 		// Attach notification sender to context
 		reqCtx := ContextWithNotificationSender(ctx, sender)
-
-		// Handle request
-		resp, err := handler.HandleRequest(reqCtx, &req)
+		reqCtx = ContextWithRequestSender(reqCtx, client)
 
 		// For notifications, don't send response
 		if req.IsNotification() {
+			_, _ = handler.HandleRequest(reqCtx, &req)
+			continue
+		}
+
+		// Track the request so the client can cancel it
+		reqCtx, done := inflight.track(reqCtx, req.ID)
+		resp, err := handler.HandleRequest(reqCtx, &req)
+
+		// Cancelled requests get no response
+		if done() {
 			continue
 		}
 
@@ -178,14 +238,15 @@ func (ws *WebSocket) handleConnection(ctx context.Context, w http.ResponseWriter
 		if err != nil {
 			var mcpErr *protocol.Error
 			if errors.As(err, &mcpErr) {
-				resp = protocol.NewErrorResponse(req.ID, mcpErr)
+				resp = protocol.AcquireErrorResponse(req.ID, mcpErr)
 			} else {
-				resp = protocol.NewErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
+				resp = protocol.AcquireErrorResponse(req.ID, protocol.NewInternalError(err.Error()))
 			}
 		}
 
 		if resp != nil {
 			_ = client.writeJSON(resp)
+			protocol.ReleaseResponse(resp)
 		}
 	}
 }
@@ -200,9 +261,23 @@ func (ws *WebSocket) closeAllClients() {
 }
 
 func (c *wsClient) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeMessage(data)
+}
+
+// SendRequest sends a request to the client and waits for its response.
+func (c *wsClient) SendRequest(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return c.pending.send(ctx, req, c.writeMessage)
+}
+
+func (c *wsClient) writeMessage(data []byte) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.conn.WriteJSON(v)
+	c.wire.Outbound("websocket", data)
+	return c.conn.WriteMessage(websocket.TextMessage, data)
 }
 
 func (c *wsClient) close() {
@@ -213,6 +288,12 @@ func (c *wsClient) close() {
 	_ = c.conn.Close()
 }
 
+// CloseConnection closes the connection to the client.
+func (c *wsClient) CloseConnection() error {
+	c.close()
+	return nil
+}
+
 // wsNotificationSender sends notifications to a WebSocket client.
 type wsNotificationSender struct {
 	client *wsClient
@@ -268,3 +268,60 @@ func TestWebSocket_Integration(t *testing.T) {
 		}
 	})
 }
+
+func TestWebSocket_CloseConnection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	closed := make(chan string, 1)
+	handler := &closeNotifyingHandler{
+		HandlerFunc: func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+			closer, ok := transport.RequestSenderFromContext(ctx).(transport.ConnectionCloser)
+			if !ok {
+				return nil, protocol.NewInternalError("sender can't close the connection")
+			}
+			return nil, closer.CloseConnection()
+		},
+		closed: closed,
+	}
+
+	ws := transport.NewWebSocket(":18768")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = ws.Serve(ctx, handler) }()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, httpResp, err := websocket.DefaultDialer.Dial("ws://localhost:18768/", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if httpResp != nil && httpResp.Body != nil {
+		_ = httpResp.Body.Close()
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(protocol.Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "close"}); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("read error = %v, want normal closure", err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("handler not told the connection closed")
+	}
+}
+
+// closeNotifyingHandler is a transport.HandlerFunc that reports closed connections.
+type closeNotifyingHandler struct {
+	transport.HandlerFunc
+	closed chan string
+}
+
+func (h *closeNotifyingHandler) ConnectionClosed(id string) {
+	h.closed <- id
+}
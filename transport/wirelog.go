@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Wire directions written by WireLogger.
+const (
+	wireInbound  = "-->"
+	wireOutbound = "<--"
+)
+
+// redactedValue replaces the value of redacted keys in wire logs.
+const redactedValue = "[REDACTED]"
+
+// WireLogger dumps raw JSON-RPC frames exchanged by a transport.
+// Each frame is written as a single line:
+//
+//	2024-11-05T10:00:00.000Z stdio --> {"jsonrpc":"2.0","id":1,"method":"ping"}
+//	2024-11-05T10:00:00.001Z stdio <-- {"jsonrpc":"2.0","id":1,"result":{}}
+//
+// Inbound frames (client to server) are marked with --> and outbound
+// frames (server to client) with <--.
+type WireLogger struct {
+	mu         sync.Mutex
+	w          io.Writer
+	redactKeys map[string]struct{}
+	redactFunc func([]byte) []byte
+	maxBytes   int
+}
+
+// WireLogOption configures a WireLogger.
+type WireLogOption func(*WireLogger)
+
+// WithWireRedactKeys replaces the values of the given JSON object keys
+// with "[REDACTED]" wherever they appear in a frame. Keys are matched
+// case-insensitively.
+func WithWireRedactKeys(keys ...string) WireLogOption {
+	return func(l *WireLogger) {
+		for _, k := range keys {
+			l.redactKeys[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// WithWireRedactFunc sets a function that rewrites each frame before it
+// is logged. It runs after key redaction and before truncation.
+func WithWireRedactFunc(fn func([]byte) []byte) WireLogOption {
+	return func(l *WireLogger) {
+		l.redactFunc = fn
+	}
+}
+
+// WithWireMaxBytes truncates logged frames to at most n bytes.
+// A value of zero or less disables truncation (the default).
+func WithWireMaxBytes(n int) WireLogOption {
+	return func(l *WireLogger) {
+		l.maxBytes = n
+	}
+}
+
+// NewWireLogger creates a wire logger writing to w.
+func NewWireLogger(w io.Writer, opts ...WireLogOption) *WireLogger {
+	l := &WireLogger{
+		w:          w,
+		redactKeys: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Inbound logs a frame received from the client.
+// It is safe to call on a nil WireLogger.
+func (l *WireLogger) Inbound(transport string, data []byte) {
+	l.log(transport, wireInbound, data)
+}
+
+// Outbound logs a frame sent to the client.
+// It is safe to call on a nil WireLogger.
+func (l *WireLogger) Outbound(transport string, data []byte) {
+	l.log(transport, wireOutbound, data)
+}
+
+func (l *WireLogger) log(transport, direction string, data []byte) {
+	if l == nil || l.w == nil {
+		return
+	}
+
+	frame := bytes.TrimSpace(data)
+	if len(l.redactKeys) > 0 {
+		frame = l.redact(frame)
+	}
+	if l.redactFunc != nil {
+		frame = l.redactFunc(frame)
+	}
+
+	suffix := ""
+	if l.maxBytes > 0 && len(frame) > l.maxBytes {
+		suffix = fmt.Sprintf("...(%d bytes truncated)", len(frame)-l.maxBytes)
+		frame = frame[:l.maxBytes]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintf(l.w, "%s %s %s %s%s\n",
+		time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		transport, direction, frame, suffix)
+}
+
+// redact replaces the values of configured keys. Frames that are not
+// valid JSON are returned unchanged.
+func (l *WireLogger) redact(frame []byte) []byte {
+	var v any
+	if err := json.Unmarshal(frame, &v); err != nil {
+		return frame
+	}
+	if !l.redactValue(v) {
+		return frame
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return frame
+	}
+	return out
+}
+
+// redactValue walks v in place and reports whether anything was redacted.
+func (l *WireLogger) redactValue(v any) bool {
+	changed := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if _, ok := l.redactKeys[strings.ToLower(k)]; ok {
+				val[k] = redactedValue
+				changed = true
+				continue
+			}
+			if l.redactValue(child) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, child := range val {
+			if l.redactValue(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
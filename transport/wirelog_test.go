@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/mcp-go/protocol"
+)
+
+func TestWireLogger(t *testing.T) {
+	t.Run("writes direction and frame", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWireLogger(&buf)
+
+		l.Inbound("stdio", []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"))
+		l.Outbound("stdio", []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+		}
+		if !strings.HasSuffix(lines[0], `stdio --> {"jsonrpc":"2.0","id":1,"method":"ping"}`) {
+			t.Errorf("inbound line = %q", lines[0])
+		}
+		if !strings.HasSuffix(lines[1], `stdio <-- {"jsonrpc":"2.0","id":1,"result":{}}`) {
+			t.Errorf("outbound line = %q", lines[1])
+		}
+	})
+
+	t.Run("redacts keys at any depth", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWireLogger(&buf, WithWireRedactKeys("Token", "password"))
+
+		l.Inbound("http", []byte(`{"params":{"arguments":{"token":"s3cr3t","items":[{"password":"hunter2"}],"name":"x"}}}`))
+
+		out := buf.String()
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "hunter2") {
+			t.Errorf("secret leaked: %q", out)
+		}
+		if strings.Count(out, redactedValue) != 2 {
+			t.Errorf("expected 2 redactions, got %q", out)
+		}
+		if !strings.Contains(out, `"name":"x"`) {
+			t.Errorf("expected unredacted field to remain, got %q", out)
+		}
+	})
+
+	t.Run("leaves invalid JSON untouched", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWireLogger(&buf, WithWireRedactKeys("token"))
+
+		l.Inbound("stdio", []byte(`{invalid`))
+
+		if !strings.Contains(buf.String(), "--> {invalid") {
+			t.Errorf("got %q", buf.String())
+		}
+	})
+
+	t.Run("applies redact func", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWireLogger(&buf, WithWireRedactFunc(func(b []byte) []byte {
+			return bytes.ReplaceAll(b, []byte("secret"), []byte("***"))
+		}))
+
+		l.Outbound("stdio", []byte(`"secret"`))
+
+		if !strings.Contains(buf.String(), `"***"`) {
+			t.Errorf("got %q", buf.String())
+		}
+	})
+
+	t.Run("truncates large frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWireLogger(&buf, WithWireMaxBytes(10))
+
+		l.Outbound("stdio", []byte(strings.Repeat("a", 25)))
+
+		out := buf.String()
+		if !strings.Contains(out, strings.Repeat("a", 10)+"...(15 bytes truncated)") {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("nil logger is a no-op", func(t *testing.T) {
+		var l *WireLogger
+		l.Inbound("stdio", []byte("{}"))
+		l.Outbound("stdio", []byte("{}"))
+	})
+}
+
+func TestStdio_WireLogger(t *testing.T) {
+	var wire bytes.Buffer
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n")
+	out := &bytes.Buffer{}
+
+	transport := NewStdio(
+		WithStdin(in),
+		WithStdout(out),
+		WithWireLogger(&wire),
+	)
+
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]any{}), nil
+	})
+
+	if err := transport.Serve(context.Background(), handler); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	log := wire.String()
+	if !strings.Contains(log, `stdio --> {"jsonrpc":"2.0","id":1,"method":"ping"}`) {
+		t.Errorf("missing inbound frame: %q", log)
+	}
+	if !strings.Contains(log, `stdio <-- {"jsonrpc":"2.0","id":1,"result":{}}`) {
+		t.Errorf("missing outbound frame: %q", log)
+	}
+}
+
+func TestHTTP_WireLogger(t *testing.T) {
+	var wire bytes.Buffer
+	transport := NewHTTP(":0", WithHTTPWireLogger(&wire))
+	handler := HandlerFunc(func(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+		return protocol.NewResponse(req.ID, map[string]string{"status": "ok"}), nil
+	})
+	httpHandler := transport.createHandler(handler)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	httpReq := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, httpReq)
+
+	log := wire.String()
+	if !strings.Contains(log, "http --> "+body) {
+		t.Errorf("missing inbound frame: %q", log)
+	}
+	if !strings.Contains(log, `http <-- {"jsonrpc":"2.0","id":1,"result":{"status":"ok"}}`) {
+		t.Errorf("missing outbound frame: %q", log)
+	}
+}